@@ -9,6 +9,7 @@ import (
 	"github.com/yourusername/usr/ai/ollama"
 	"github.com/yourusername/usr/ai/prompts"
 	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/sources"
 	"go.uber.org/zap"
 )
 
@@ -19,9 +20,7 @@ type Engine struct {
 	logger    *zap.Logger
 	
 	// Recursion safety
-	recursionDepth    int
 	maxRecursionDepth int
-	recursionMu       sync.Mutex
 	
 	// Cache to prevent duplicate AI calls
 	cache   map[string][]string
@@ -165,23 +164,18 @@ func (e *Engine) GenerateMutations(ctx context.Context, subdomain string) ([]str
 	return mutations, nil
 }
 
-// RecursiveDiscovery generates related subdomains based on discovered one
-func (e *Engine) RecursiveDiscovery(ctx context.Context, subdomain string, purpose string) ([]string, error) {
+// RecursiveDiscovery generates related subdomains based on discovered one.
+// depth is the position of subdomain in its own discovery chain (0 = seed), so
+// concurrent chains are limited independently of each other.
+func (e *Engine) RecursiveDiscovery(ctx context.Context, subdomain string, purpose string, depth int) ([]string, error) {
 	// Check recursion depth
-	e.recursionMu.Lock()
-	if e.recursionDepth >= e.maxRecursionDepth {
-		e.recursionMu.Unlock()
-		e.logger.Warn("Max recursion depth reached", zap.Int("depth", e.recursionDepth))
+	if depth >= e.maxRecursionDepth {
+		e.logger.Warn("Max recursion depth reached",
+			zap.String("subdomain", subdomain),
+			zap.Int("depth", depth),
+		)
 		return nil, fmt.Errorf("max recursion depth reached")
 	}
-	e.recursionDepth++
-	e.recursionMu.Unlock()
-	
-	defer func() {
-		e.recursionMu.Lock()
-		e.recursionDepth--
-		e.recursionMu.Unlock()
-	}()
 	
 	cacheKey := fmt.Sprintf("recursive:%s:%s", subdomain, purpose)
 	
@@ -192,7 +186,7 @@ func (e *Engine) RecursiveDiscovery(ctx context.Context, subdomain string, purpo
 	e.logger.Info("Recursive discovery",
 		zap.String("subdomain", subdomain),
 		zap.String("purpose", purpose),
-		zap.Int("depth", e.recursionDepth),
+		zap.Int("depth", depth),
 	)
 	
 	vars := map[string]interface{}{
@@ -217,6 +211,43 @@ func (e *Engine) RecursiveDiscovery(ctx context.Context, subdomain string, purpo
 	return suggestions, nil
 }
 
+// MaxRecursionDepth returns the deepest chain RecursiveDiscovery will extend
+func (e *Engine) MaxRecursionDepth() int {
+	return e.maxRecursionDepth
+}
+
+// InferPurpose guesses what a subdomain is used for from its first label
+func (e *Engine) InferPurpose(subdomain string) string {
+	label := strings.ToLower(strings.Split(subdomain, ".")[0])
+	
+	purposes := []struct {
+		keywords []string
+		purpose  string
+	}{
+		{[]string{"api", "rest", "graphql", "gateway", "gw"}, "an API service"},
+		{[]string{"dev", "staging", "stage", "stg", "test", "qa", "uat", "sandbox"}, "a pre-production environment"},
+		{[]string{"admin", "portal", "dashboard", "console", "manage", "panel"}, "an administrative interface"},
+		{[]string{"mail", "smtp", "imap", "pop", "mx", "webmail", "exchange"}, "mail infrastructure"},
+		{[]string{"vpn", "remote", "citrix", "rdp", "ssh", "bastion"}, "remote access infrastructure"},
+		{[]string{"cdn", "static", "assets", "img", "media", "files"}, "static content delivery"},
+		{[]string{"auth", "login", "sso", "id", "oauth", "accounts"}, "an authentication service"},
+		{[]string{"jenkins", "gitlab", "git", "ci", "build", "jira", "confluence", "wiki"}, "internal development tooling"},
+		{[]string{"db", "sql", "mysql", "postgres", "redis", "mongo", "elastic"}, "a data store"},
+		{[]string{"shop", "store", "pay", "checkout", "billing"}, "an e-commerce or payments service"},
+	}
+	
+	for _, p := range purposes {
+		for _, keyword := range p.keywords {
+			if label == keyword || strings.HasPrefix(label, keyword+"-") ||
+				strings.HasSuffix(label, "-"+keyword) {
+				return p.purpose
+			}
+		}
+	}
+	
+	return "a general web service"
+}
+
 // AnalyzeConfidence uses AI to assess subdomain confidence
 func (e *Engine) AnalyzeConfidence(ctx context.Context, subdomain string, metadata map[string]interface{}) (int, string, error) {
 	vars := map[string]interface{}{
@@ -298,7 +329,7 @@ func (e *Engine) parseWordlist(response string) []string {
 			word = strings.ToLower(word)
 			
 			// Validate subdomain format
-			if isValidSubdomain(word) && !seen[word] {
+			if sources.ValidLabel(word) && !seen[word] {
 				wordlist = append(wordlist, word)
 				seen[word] = true
 			}
@@ -367,21 +398,6 @@ func (e *Engine) setCache(key string, value []string) {
 	e.cache[key] = value
 }
 
-// isValidSubdomain checks if a string is a valid subdomain component
-func isValidSubdomain(s string) bool {
-	if len(s) == 0 || len(s) > 63 {
-		return false
-	}
-	
-	for _, c := range s {
-		if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-') {
-			return false
-		}
-	}
-	
-	return !strings.HasPrefix(s, "-") && !strings.HasSuffix(s, "-")
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/storage/db"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Clear caches, the scan database and logs",
+	Long: `Clean resets local state between engagements. Select what to clear with
+--cache (contents of storage.cache_dir), --db (drops and recreates the scan
+database at storage.path) and --logs (truncates log_file); with no selection
+all three are cleared. Each step asks for confirmation unless --force is set.
+A cache_dir that is or contains /, the home directory or the working
+directory is refused.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cache, _ := cmd.Flags().GetBool("cache")
+		database, _ := cmd.Flags().GetBool("db")
+		logs, _ := cmd.Flags().GetBool("logs")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !cache && !database && !logs {
+			cache, database, logs = true, true, true
+		}
+
+		stdin := bufio.NewReader(os.Stdin)
+		confirm := func(prompt string) bool {
+			if force {
+				return true
+			}
+			fmt.Printf("[?] %s [y/N] ", prompt)
+			answer, _ := stdin.ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			return answer == "y" || answer == "yes"
+		}
+
+		failed := false
+
+		if cache && cfg.Storage.CacheDir != "" {
+			dir, err := filepath.Abs(cfg.Storage.CacheDir)
+			if err == nil {
+				err = checkClearable(dir)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[-] Cache: %v\n", err)
+				failed = true
+			} else if confirm(fmt.Sprintf("Remove everything in %s?", dir)) {
+				removed, err := clearDir(dir)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "[-] Failed to clear cache: %v\n", err)
+					failed = true
+				} else {
+					fmt.Printf("[+] Removed %d cache entries from %s\n", removed, dir)
+				}
+			}
+		}
+
+		if database && cfg.Storage.Path != "" {
+			if confirm(fmt.Sprintf("Drop and recreate the database %s?", cfg.Storage.Path)) {
+				if err := resetDatabase(cfg.Storage.Path); err != nil {
+					fmt.Fprintf(os.Stderr, "[-] Failed to reset database: %v\n", err)
+					failed = true
+				} else {
+					fmt.Printf("[+] Recreated empty database %s\n", cfg.Storage.Path)
+				}
+			}
+		}
+
+		if logs && cfg.LogFile != "" {
+			if confirm(fmt.Sprintf("Truncate log file %s?", cfg.LogFile)) {
+				if err := os.Truncate(cfg.LogFile, 0); err != nil && !os.IsNotExist(err) {
+					fmt.Fprintf(os.Stderr, "[-] Failed to truncate log: %v\n", err)
+					failed = true
+				} else {
+					fmt.Printf("[+] Truncated %s\n", cfg.LogFile)
+				}
+			}
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// clearDir removes the contents of a directory, keeping the directory
+// itself. Directories checkClearable refuses are left alone.
+func clearDir(dir string) (int, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkClearable(dir); err != nil {
+		return 0, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// checkClearable refuses to clear an absolute directory that is, or
+// contains, the filesystem root, the home directory or the working
+// directory: a cache_dir misconfigured as "/", "~" or "." must not wipe them
+func checkClearable(dir string) error {
+	type protectedDir struct{ path, name string }
+	protected := []protectedDir{{string(filepath.Separator), "the filesystem root"}}
+	if home, err := os.UserHomeDir(); err == nil {
+		protected = append(protected, protectedDir{home, "the home directory"})
+	}
+	if wd, err := os.Getwd(); err == nil {
+		protected = append(protected, protectedDir{wd, "the working directory"})
+	}
+
+	resolved := resolveLinks(dir)
+	for _, p := range protected {
+		rel, err := filepath.Rel(resolved, resolveLinks(p.path))
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("refusing to clear %s: it is or contains %s", dir, p.name)
+		}
+	}
+	return nil
+}
+
+// resolveLinks returns path with symlinks resolved, or as-is when it
+// doesn't exist
+func resolveLinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// resetDatabase deletes the SQLite database, including WAL side files, and
+// recreates an empty schema in its place
+func resetDatabase(path string) error {
+	for _, file := range []string{path, path + "-wal", path + "-shm"} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	database, err := db.InitDB(path)
+	if err != nil {
+		return err
+	}
+	return database.Close()
+}
+
+func init() {
+	cleanCmd.Flags().Bool("cache", false, "clear storage.cache_dir")
+	cleanCmd.Flags().Bool("db", false, "drop and recreate the scan database")
+	cleanCmd.Flags().Bool("logs", false, "truncate the log file")
+	cleanCmd.Flags().Bool("force", false, "don't ask for confirmation")
+
+	rootCmd.AddCommand(cleanCmd)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/usr/storage/db"
+)
+
+// chdir changes the working directory for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestClearDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"a.json", "nested/b.json", "nested/deeper/c.json"} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := clearDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("removed %d entries, want 2", removed)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("cache directory itself removed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("%d entries left", len(entries))
+	}
+
+	// A cache that was never created is already clear
+	if removed, err := clearDir(filepath.Join(dir, "missing")); err != nil || removed != 0 {
+		t.Errorf("missing dir: removed %d, err %v", removed, err)
+	}
+}
+
+func TestClearDirRefusesProtectedDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	work := filepath.Join(t.TempDir(), "project", "run")
+	if err := os.MkdirAll(work, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, work)
+
+	keep := filepath.Join(work, "keep.txt")
+	if err := os.WriteFile(keep, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{name: "root", dir: "/", want: "the filesystem root"},
+		{name: "home", dir: home, want: "the home directory"},
+		{name: "working directory", dir: ".", want: "the working directory"},
+		{name: "working directory, relative", dir: "../run/", want: "the working directory"},
+		{name: "parent of the working directory", dir: "..", want: "the working directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := clearDir(tt.dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want refusal naming %s", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := os.Stat(keep); err != nil {
+		t.Fatalf("protected file removed: %v", err)
+	}
+
+	// A cache below the working directory, like the default ./cache, is fine
+	cache := filepath.Join(work, "cache")
+	if err := os.MkdirAll(filepath.Join(cache, "entry"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := clearDir("cache"); err != nil || removed != 1 {
+		t.Errorf("./cache: removed %d, err %v", removed, err)
+	}
+}
+
+func TestResetDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usr.db")
+
+	database, err := db.InitDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec(`CREATE TABLE leftover (id INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	database.Close()
+	if err := os.WriteFile(path+"-wal", []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resetDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	var leftover, tables int
+	if err := reopened.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'leftover'`).Scan(&leftover); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if leftover != 0 {
+		t.Error("old table survived the reset")
+	}
+	if tables == 0 {
+		t.Error("no schema recreated")
+	}
+	if data, err := os.ReadFile(path + "-wal"); err == nil && string(data) == "stale" {
+		t.Error("stale WAL file kept")
+	}
+}
+
+func TestResetDatabaseCreatesMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new", "usr.db")
+	if err := resetDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("database not created: %v", err)
+	}
+}
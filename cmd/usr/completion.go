@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/output"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate the shell completion script",
+	Long: `Completion prints a completion script for your shell. Load it into the
+current session or install it permanently:
+
+  bash:       source <(usr completion bash)
+              usr completion bash > /etc/bash_completion.d/usr
+  zsh:        usr completion zsh > "${fpath[1]}/_usr"
+  fish:       usr completion fish > ~/.config/fish/completions/usr.fish
+  powershell: usr completion powershell | Out-String | Invoke-Expression
+
+Besides commands and flags, scan completes --format, --mode and source
+names for --only/--exclude.`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		switch args[0] {
+		case "bash":
+			err = rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			err = rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			err = rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			err = rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to generate completion: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// completeFormats completes --format from the exporter's formats
+func completeFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return output.SupportedFormats(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeModes completes --mode with the scan modes
+func completeModes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	modes := []string{
+		string(types.ModePassive),
+		string(types.ModeActive),
+		string(types.ModeAggressive),
+		string(types.ModeStealth),
+	}
+	return modes, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSources completes a comma-separated list of source names from
+// the registry, offering only names not already in the list
+func completeSources(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if cfg == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	prefix := ""
+	chosen := make(map[string]bool)
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		for _, name := range strings.Split(toComplete[:i], ",") {
+			chosen[strings.TrimSpace(name)] = true
+		}
+	}
+
+	var names []string
+	for _, name := range newSourceRegistry(cfg, log).Names() {
+		if !chosen[name] {
+			names = append(names, prefix+name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// registerScanCompletions attaches the dynamic completions to scan's
+// flags; call it after the flags are defined
+func registerScanCompletions() {
+	scanCmd.RegisterFlagCompletionFunc("format", completeFormats)
+	scanCmd.RegisterFlagCompletionFunc("mode", completeModes)
+	scanCmd.RegisterFlagCompletionFunc("only", completeSources)
+	scanCmd.RegisterFlagCompletionFunc("exclude", completeSources)
+}
+
+func init() {
+	// completionCmd replaces cobra's default so the help explains installing
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.AddCommand(completionCmd)
+}
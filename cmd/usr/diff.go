@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/storage"
+	"github.com/yourusername/usr/storage/diff"
+	"go.uber.org/zap"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [domain]",
+	Short: "Compare two stored scans of a domain",
+	Long: `Diff compares two completed scans of a domain from the scan database:
+subdomains added, removed and unchanged, and HTTP status, title and server
+changes on hosts seen in both. Without --old/--new it compares the two most
+recent scans. --json prints the result for monitoring pipelines.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		domain := strings.ToLower(args[0])
+		oldID, _ := cmd.Flags().GetInt64("old")
+		newID, _ := cmd.Flags().GetInt64("new")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		manager, err := storage.NewManager(cfg.Storage.Path, log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to open database: %v\n", err)
+			os.Exit(1)
+		}
+		defer manager.Close()
+
+		if oldID == 0 || newID == 0 {
+			scanIDs, err := manager.GetCompletedScans(ctx, domain, 2)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[-] %v\n", err)
+				os.Exit(1)
+			}
+			if len(scanIDs) < 2 {
+				fmt.Fprintf(os.Stderr, "[-] %v: %s needs two completed scans\n", diff.ErrNoPreviousScan, domain)
+				os.Exit(1)
+			}
+			if newID == 0 {
+				newID = scanIDs[0]
+			}
+			if oldID == 0 {
+				oldID = scanIDs[1]
+			}
+		}
+
+		// Logs go to stdout; keep them out of the JSON
+		logger := log
+		if asJSON {
+			logger = log.WithOptions(zap.IncreaseLevel(zap.WarnLevel))
+		}
+
+		differ := diff.NewDiffer(manager, logger)
+		result, err := differ.Compare(ctx, domain, oldID, newID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to compare scans: %v\n", err)
+			os.Exit(1)
+		}
+
+		if asJSON {
+			data, err := differ.GenerateJSON(result)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[-] Failed to encode diff: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		fmt.Print(differ.GenerateReport(result))
+	},
+}
+
+func init() {
+	diffCmd.Flags().Int64("old", 0, "older scan ID (default: second most recent scan)")
+	diffCmd.Flags().Int64("new", 0, "newer scan ID (default: most recent scan)")
+	diffCmd.Flags().Bool("json", false, "print the diff as JSON")
+
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/internal/dns"
+	"github.com/yourusername/usr/internal/sources/ai"
+	"go.uber.org/zap"
+)
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover [subdomain]",
+	Short: "Recursively expand a subdomain using AI suggestions",
+	Long: `Discover deep-dives a single branch of the target: the seed subdomain's
+purpose is inferred, the AI engine suggests related names, and only names that
+resolve are reported and expanded further, up to the requested depth.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		seed := strings.ToLower(args[0])
+		depth, _ := cmd.Flags().GetInt("depth")
+
+		// The command is an explicit request for AI, regardless of config
+		cfg.AI.Enabled = true
+
+		log.Info("Starting recursive AI discovery",
+			zap.String("seed", seed),
+			zap.Int("depth", depth),
+		)
+
+		fmt.Printf("[*] Seed: %s\n", seed)
+		fmt.Printf("[*] Max depth: %d\n\n", depth)
+
+		source := ai.NewAISource(cfg, log)
+		resolver := dns.NewEngine(&cfg.DNS, log)
+
+		// Ctrl-C stops discovery and prints what was found so far
+		ctx, stop := scanContext(0)
+		defer stop()
+
+		discoveries, err := source.DiscoverRecursive(ctx, seed, resolver, depth, cfg.Sources.Active.Workers)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "[-] Discovery failed: %v\n", err)
+			os.Exit(1)
+		}
+		if ctx.Err() != nil {
+			fmt.Println("[!] Interrupted, showing discoveries so far")
+		}
+
+		for _, d := range discoveries {
+			lineage := strings.Join(d.Lineage, " -> ") + " -> " + d.Domain
+			fmt.Printf("[+] %s [%s] (depth %d)\n    %s\n", d.Domain, strings.Join(d.IP, ", "), d.Depth, lineage)
+		}
+
+		fmt.Printf("\n[*] %d validated discoveries\n", len(discoveries))
+	},
+}
+
+func init() {
+	discoverCmd.Flags().Int("depth", 2, "maximum recursion depth")
+
+	rootCmd.AddCommand(discoverCmd)
+}
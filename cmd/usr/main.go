@@ -1,11 +1,15 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
+	"runtime/debug"
 
 	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/core/orchestrator"
 	"github.com/yourusername/usr/internal/config"
 	"github.com/yourusername/usr/internal/logger"
 	"go.uber.org/zap"
@@ -26,6 +30,13 @@ The most advanced subdomain enumeration framework
 `
 )
 
+// Set at build time:
+// go build -ldflags "-X main.commit=$(git rev-parse HEAD) -X main.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	commit = ""
+	date   = ""
+)
+
 var (
 	cfgFile string
 	cfg     *config.Config
@@ -41,6 +52,11 @@ to achieve superior coverage over all existing tools.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		var err error
 		
+		// Containers mount their config and point USR_CONFIG at it
+		if cfgFile == "" {
+			cfgFile = os.Getenv("USR_CONFIG")
+		}
+		
 		// Initialize config
 		cfg, err = config.Load(cfgFile)
 		if err != nil {
@@ -66,14 +82,76 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
 	Run: func(cmd *cobra.Command, args []string) {
+		info := getBuildInfo()
+		
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(info); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding version: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		
 		fmt.Printf(banner, version)
-		fmt.Printf("\nVersion:      %s\n", version)
-		fmt.Printf("Go Version:   %s\n", runtime.Version())
-		fmt.Printf("OS/Arch:      %s/%s\n", runtime.GOOS, runtime.GOARCH)
-		fmt.Printf("Environment:  %s\n", detectEnvironment())
+		fmt.Printf("\nVersion:      %s\n", info.Version)
+		if info.Commit != "" {
+			fmt.Printf("Commit:       %s\n", info.Commit)
+		}
+		if info.Date != "" {
+			fmt.Printf("Built:        %s\n", info.Date)
+		}
+		fmt.Printf("Go Version:   %s\n", info.GoVersion)
+		fmt.Printf("OS/Arch:      %s/%s\n", info.OS, info.Arch)
+		fmt.Printf("Environment:  %s\n", info.Environment)
 	},
 }
 
+// buildInfo identifies exactly which build produced a set of results
+type buildInfo struct {
+	Version     string `json:"version"`
+	Commit      string `json:"commit,omitempty"`
+	Date        string `json:"date,omitempty"`
+	Modified    bool   `json:"modified,omitempty"`
+	GoVersion   string `json:"go_version"`
+	OS          string `json:"os"`
+	Arch        string `json:"arch"`
+	Environment string `json:"environment"`
+}
+
+// getBuildInfo combines ldflags values with VCS data embedded by the Go toolchain
+func getBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:     version,
+		Commit:      commit,
+		Date:        date,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Environment: detectEnvironment(),
+	}
+	
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	
+	return info
+}
+
 var scanCmd = &cobra.Command{
 	Use:   "scan [domain]",
 	Short: "Perform subdomain reconnaissance on target domain",
@@ -82,10 +160,29 @@ var scanCmd = &cobra.Command{
 - Active DNS discovery
 - AI-enhanced pattern prediction
 - Web intelligence and JS parsing
-- Historical data comparison`,
-	Args: cobra.ExactArgs(1),
+- Historical data comparison
+
+The target is the domain argument, else the USR_TARGET environment
+variable, else the first line of stdin when it is piped.
+
+When --timeout passes, or on Ctrl-C (SIGINT) or SIGTERM, running sources
+and DNS batches stop and whatever was found so far is still exported. A
+second Ctrl-C quits at once. Such a scan stays marked running in storage;
+--resume continues it, skipping the sources that had already completed.`,
+	Example: `  usr scan example.com
+  USR_TARGET=example.com usr scan
+  echo example.com | usr scan`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		domain := args[0]
+		domain, err := scanTarget(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] %v\n", err)
+			os.Exit(exitConfig)
+		}
+		if err := applyScanFlags(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "[-] %v\n", err)
+			os.Exit(exitConfig)
+		}
 		
 		log.Info("Starting subdomain reconnaissance",
 			zap.String("domain", domain),
@@ -98,9 +195,9 @@ var scanCmd = &cobra.Command{
 		fmt.Printf("[*] Environment: %s\n", detectEnvironment())
 		fmt.Printf("\n[*] Initializing reconnaissance engine...\n\n")
 		
-		// Orchestrator will be implemented in Phase 2
-		log.Info("Scan initiated successfully")
-		fmt.Println("[+] Ready for orchestrator integration (Phase 2)")
+		if err := runScan(cmd, newSourceRegistry(cfg, log), domain); err != nil {
+			exitScanError(err)
+		}
 	},
 }
 
@@ -119,6 +216,57 @@ var updateCmd = &cobra.Command{
 	},
 }
 
+// Scan exit codes, so scripts can tell a configuration problem from a
+// failed run; a scan that ran and found nothing exits 0
+const (
+	exitError         = 1
+	exitConfig        = 2
+	exitSourcesFailed = 3
+)
+
+// scanFailure is a scan error that ends the run with a given exit code
+type scanFailure struct {
+	code int
+	err  error
+}
+
+func (f *scanFailure) Error() string { return f.err.Error() }
+func (f *scanFailure) Unwrap() error { return f.err }
+
+// failWith makes err end the scan with code
+func failWith(code int, err error) error {
+	return &scanFailure{code: code, err: err}
+}
+
+// exitScanError explains why a scan failed and exits with the matching
+// code. It runs once runScan has returned and its cleanup is done.
+func exitScanError(err error) {
+	code, lines := explainScanError(err)
+	for _, line := range lines {
+		fmt.Fprintf(os.Stderr, "[-] %s\n", line)
+	}
+	os.Exit(code)
+}
+
+// explainScanError returns the exit code for a scan error and what to tell
+// the user about it
+func explainScanError(err error) (int, []string) {
+	var failure *scanFailure
+	switch {
+	case errors.Is(err, orchestrator.ErrNoSources):
+		return exitConfig, []string{"No sources are enabled: enable some under \"sources\" in the config or relax --only/--exclude"}
+	case errors.Is(err, orchestrator.ErrAllSourcesFailed):
+		return exitSourcesFailed, []string{
+			err.Error(),
+			"This is not an empty result: check network access, proxies and API keys (--errors-report has details)",
+		}
+	case errors.As(err, &failure):
+		return failure.code, []string{err.Error()}
+	default:
+		return exitError, []string{fmt.Sprintf("Scan failed: %v", err)}
+	}
+}
+
 func detectEnvironment() string {
 	// Check if running on Kali Linux
 	if _, err := os.Stat("/etc/os-release"); err == nil {
@@ -150,15 +298,33 @@ func findSubstring(s, substr string) bool {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: $HOME/.usr/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: $USR_CONFIG, then $HOME/.usr/config.yaml)")
+	
+	versionCmd.Flags().Bool("json", false, "print build information as JSON")
 	
 	// Scan command flags
 	scanCmd.Flags().String("mode", "passive", "scan mode: passive, active, aggressive, stealth")
 	scanCmd.Flags().String("output", "", "output file path")
-	scanCmd.Flags().String("format", "json", "output format: json, csv, html, nuclei")
+	scanCmd.Flags().String("format", "json", "output format: json, jsonl, csv, html, nuclei, template, all (bundle directory under --output)")
+	scanCmd.Flags().String("fields", "", "comma-separated fields for json/jsonl/csv, e.g. domain,ip,http.status_code")
+	scanCmd.Flags().String("tags", "", "only export hosts with any of these comma-separated tags, e.g. environment:staging,service")
+	scanCmd.Flags().IntSlice("status", nil, "only export hosts whose HTTP status is one of these, e.g. 200,401,403 (overrides output.status_codes)")
+	scanCmd.Flags().String("template", "", "Go template file for custom output (implies --format template)")
+	scanCmd.Flags().String("known-file", "", "subdomains known to exist, one per line: boosts their confidence and reports any not found")
+	scanCmd.Flags().String("errors-report", "", "write scan failures (source, phase, kind) as JSON to this file")
+	scanCmd.Flags().String("only", "", "comma-separated sources to run, skipping all others")
+	scanCmd.Flags().String("exclude", "", "comma-separated sources to skip")
 	scanCmd.Flags().Bool("ai", false, "enable AI-enhanced discovery")
+	scanCmd.Flags().Bool("new-only", false, "output only subdomains not in the previous stored scan (requires storage)")
+	scanCmd.Flags().Bool("offline", false, "use only stored results and local sources; no network access")
 	scanCmd.Flags().Bool("recursive", false, "enable recursive enumeration")
 	scanCmd.Flags().Int("threads", 50, "number of concurrent threads")
+	scanCmd.Flags().String("user-agent", "", "User-Agent for HTTP probes (overrides http.user_agent)")
+	scanCmd.Flags().StringArrayP("header", "H", nil, "extra HTTP probe header as \"Name: value\", repeatable (added to http.headers)")
+	scanCmd.Flags().Duration("timeout", 0, "stop the scan after this long and export what was found, e.g. 30m (0 = no limit)")
+	scanCmd.Flags().Bool("live", false, "print hosts as they validate, before the scan finishes")
+	scanCmd.Flags().Bool("resume", false, "continue the domain's last interrupted scan, skipping sources it completed (requires storage)")
+	registerScanCompletions()
 	
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(scanCmd)
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/core/orchestrator"
+	"github.com/yourusername/usr/output"
+	"github.com/yourusername/usr/storage"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Re-run scoring, dedup and classification on a stored scan",
+	Long: `Replay feeds the raw names every source reported during a stored scan
+back through the pipeline after enumeration: tagging, scoring and
+deduplication, plus DNS/HTTP validation with --validate. No source is
+queried again, so changes to the intelligence settings can be tried in
+seconds. Without --validate no network access is needed.`,
+	Example: `  usr replay --scan-id 42
+  usr replay --scan-id 42 --validate --format html --output report.html`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		scanID, _ := cmd.Flags().GetInt64("scan-id")
+		validate, _ := cmd.Flags().GetBool("validate")
+		format, _ := cmd.Flags().GetString("format")
+		outputPath, _ := cmd.Flags().GetString("output")
+		statusCodes := cfg.Output.StatusCodes
+		if cmd.Flags().Changed("status") {
+			statusCodes, _ = cmd.Flags().GetIntSlice("status")
+		}
+
+		if scanID <= 0 {
+			fmt.Fprintln(os.Stderr, "[-] --scan-id is required")
+			os.Exit(exitConfig)
+		}
+
+		manager, err := storage.NewManager(cfg.Storage.Path, log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to open database: %v\n", err)
+			os.Exit(1)
+		}
+		defer manager.Close()
+
+		fmt.Printf("[*] Replaying scan %d\n", scanID)
+
+		orch := orchestrator.NewOrchestrator(cfg, log)
+		orch.SetWildcardStore(manager)
+		results, err := orch.Replay(ctx, manager, scanID, validate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Replay failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		if outputPath == "" {
+			outputPath = filepath.Join(cfg.OutputDir, fmt.Sprintf("replay-%d.%s", scanID, format))
+			if format == "all" {
+				outputPath = cfg.OutputDir
+			}
+		}
+		if format != "all" {
+			if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+				fmt.Fprintf(os.Stderr, "[-] Failed to create output directory: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		exporter := output.NewExporter(log)
+		exporter.SetStatusFilter(statusCodes)
+		if err := exporter.Export(ctx, results, format, outputPath); err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Export failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		stats := orch.GetStatistics()
+		fmt.Printf("[+] %d subdomains (%d validated) written to %s\n", len(results), stats.ValidatedSubdomains, outputPath)
+	},
+}
+
+func init() {
+	replayCmd.Flags().Int64("scan-id", 0, "stored scan whose source results to replay")
+	replayCmd.Flags().Bool("validate", false, "re-run DNS/HTTP validation (needs network access)")
+	replayCmd.Flags().String("format", "json", "output format: json, jsonl, csv, txt, html, nuclei, burp, all")
+	replayCmd.Flags().String("output", "", "output file path (default: replay-<scan-id>.<format> under output_dir)")
+	replayCmd.Flags().IntSlice("status", nil, "only export hosts whose HTTP status is one of these, e.g. 200,401,403 (overrides output.status_codes)")
+	replayCmd.RegisterFlagCompletionFunc("format", completeFormats)
+
+	rootCmd.AddCommand(replayCmd)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/internal/dns"
+	"go.uber.org/zap"
+)
+
+var resolversCmd = &cobra.Command{
+	Use:   "resolvers",
+	Short: "Manage and evaluate DNS resolvers",
+}
+
+var resolversBenchmarkCmd = &cobra.Command{
+	Use:   "benchmark",
+	Short: "Rank resolvers by speed and accuracy",
+	Long: `Benchmark resolves known-good names and random garbage names through each
+resolver, measuring latency and correctness: known names must resolve
+consistently and garbage names must return NXDOMAIN. Reliable resolvers are
+ranked by latency and can be written out for use as dns.resolvers_file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		output, _ := cmd.Flags().GetString("output")
+		top, _ := cmd.Flags().GetInt("top")
+		workers, _ := cmd.Flags().GetInt("workers")
+
+		resolvers := cfg.DNS.Resolvers
+		if file != "" {
+			loaded, err := dns.LoadResolvers(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[-] %v\n", err)
+				os.Exit(1)
+			}
+			resolvers = loaded
+		}
+
+		if len(resolvers) == 0 {
+			fmt.Fprintln(os.Stderr, "[-] No resolvers to benchmark")
+			os.Exit(1)
+		}
+
+		log.Info("Benchmarking resolvers",
+			zap.Int("count", len(resolvers)),
+			zap.Int("workers", workers),
+		)
+
+		fmt.Printf("[*] Benchmarking %d resolvers...\n\n", len(resolvers))
+
+		engine := dns.NewEngine(&cfg.DNS, log)
+		scores := engine.BenchmarkResolvers(context.Background(), resolvers, workers)
+
+		fmt.Printf("%-4s %-22s %-10s %-9s %-9s %-9s %s\n", "RANK", "RESOLVER", "LATENCY", "ANSWERED", "NXDOMAIN", "HIJACKED", "STATUS")
+
+		var ranked []string
+		for i, score := range scores {
+			status := "reliable"
+			if !score.Reliable {
+				status = "rejected"
+				if !score.Consistent {
+					status += " (inconsistent)"
+				}
+			} else if top <= 0 || len(ranked) < top {
+				ranked = append(ranked, score.Resolver)
+			}
+
+			fmt.Printf("%-4d %-22s %-10s %-9d %-9d %-9d %s\n",
+				i+1,
+				score.Resolver,
+				score.Latency.Round(time.Millisecond/10).String(),
+				score.Answered,
+				score.NXDomain,
+				score.Hijacked,
+				status,
+			)
+		}
+
+		fmt.Printf("\n[*] %d of %d resolvers are reliable\n", countReliable(scores), len(scores))
+
+		if output != "" {
+			content := strings.Join(ranked, "\n") + "\n"
+			if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "[-] Failed to write %s: %v\n", output, err)
+				os.Exit(1)
+			}
+			fmt.Printf("[+] Wrote %d resolvers to %s (use as dns.resolvers_file)\n", len(ranked), output)
+		}
+	},
+}
+
+// countReliable counts resolvers that passed every check
+func countReliable(scores []*dns.ResolverScore) int {
+	count := 0
+	for _, score := range scores {
+		if score.Reliable {
+			count++
+		}
+	}
+	return count
+}
+
+func init() {
+	resolversBenchmarkCmd.Flags().String("file", "", "resolvers file, one per line (default: configured resolvers)")
+	resolversBenchmarkCmd.Flags().String("output", "", "write reliable resolvers, fastest first, to this file")
+	resolversBenchmarkCmd.Flags().Int("top", 0, "keep only the N fastest reliable resolvers (0 = all)")
+	resolversBenchmarkCmd.Flags().Int("workers", 10, "resolvers benchmarked concurrently")
+
+	resolversCmd.AddCommand(resolversBenchmarkCmd)
+	rootCmd.AddCommand(resolversCmd)
+}
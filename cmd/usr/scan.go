@@ -0,0 +1,473 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/core/orchestrator"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/modules/web/cloud"
+	"github.com/yourusername/usr/output"
+	"github.com/yourusername/usr/storage"
+	"github.com/yourusername/usr/storage/diff"
+	"go.uber.org/zap"
+)
+
+// highConfidence is the score from which the summary counts a host as
+// high confidence, matching the HTML report's highlighting
+const highConfidence = 70
+
+// applyScanFlags lets explicitly set scan flags override the config; it
+// must run before the orchestrator and sources are built from it
+func applyScanFlags(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	if flags.Changed("mode") {
+		cfg.ScanMode, _ = flags.GetString("mode")
+	}
+	if flags.Changed("threads") {
+		cfg.MaxThreads, _ = flags.GetInt("threads")
+	}
+	if ai, _ := flags.GetBool("ai"); ai {
+		cfg.AI.Enabled = true
+	}
+	if recursive, _ := flags.GetBool("recursive"); recursive {
+		cfg.Sources.Active.Recursive = true
+	}
+	if offline, _ := flags.GetBool("offline"); offline {
+		cfg.Offline = true
+	}
+	if knownFile, _ := flags.GetString("known-file"); knownFile != "" {
+		cfg.Validation.KnownFile = knownFile
+	}
+	if userAgent, _ := flags.GetString("user-agent"); userAgent != "" {
+		cfg.HTTP.UserAgent = userAgent
+	}
+	
+	headers, _ := flags.GetStringArray("header")
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("invalid --header %q: want \"Name: value\"", header)
+		}
+		if cfg.HTTP.Headers == nil {
+			cfg.HTTP.Headers = make(map[string]string)
+		}
+		// Config keys come lowercased; matching them lets a flag replace one
+		cfg.HTTP.Headers[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	return nil
+}
+
+// selectSources returns the registry's sources narrowed by --only and
+// --exclude, failing on names the registry doesn't know
+func selectSources(registry *sources.Registry, only, exclude string) ([]sources.Source, error) {
+	onlySet, err := sourceNames(registry, only)
+	if err != nil {
+		return nil, err
+	}
+	excludeSet, err := sourceNames(registry, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []sources.Source
+	for _, name := range registry.Names() {
+		if (len(onlySet) > 0 && !onlySet[name]) || excludeSet[name] {
+			continue
+		}
+		source, _ := registry.Get(name)
+		selected = append(selected, source)
+	}
+	return selected, nil
+}
+
+// sourceNames parses a comma-separated list of source names
+func sourceNames(registry *sources.Registry, list string) (map[string]bool, error) {
+	names := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := registry.Get(name); !ok {
+			return nil, fmt.Errorf("unknown source %q (available: %s)", name, strings.Join(registry.Names(), ", "))
+		}
+		names[name] = true
+	}
+	return names, nil
+}
+
+// openScanStorage opens the scan database. Storage is optional for a
+// plain scan, so failing to open it only costs history, unless the scan
+// needs it.
+func openScanStorage(required bool) (*storage.Manager, error) {
+	if cfg.Storage.Engine == "memory" || cfg.Storage.Path == "" {
+		if required {
+			return nil, failWith(exitConfig, errors.New("--new-only, --offline and --resume need storage: set storage.path"))
+		}
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Storage.Path), 0755); err != nil {
+		log.Warn("Failed to create storage directory", zap.Error(err))
+	}
+	manager, err := storage.NewManager(cfg.Storage.Path, log)
+	if err != nil {
+		if required {
+			return nil, failWith(exitConfig, fmt.Errorf("failed to open database: %w", err))
+		}
+		log.Warn("Scan history disabled: failed to open database", zap.Error(err))
+		return nil, nil
+	}
+	return manager, nil
+}
+
+// runScan runs the registry's sources against domain and exports the
+// results according to the scan flags. Failures are returned rather than
+// exiting, so storage is closed and signal handling stopped on every path.
+func runScan(cmd *cobra.Command, registry *sources.Registry, domain string) error {
+	flags := cmd.Flags()
+	format, _ := flags.GetString("format")
+	outputPath, _ := flags.GetString("output")
+	fieldSpec, _ := flags.GetString("fields")
+	tagSpec, _ := flags.GetString("tags")
+	templatePath, _ := flags.GetString("template")
+	errorsReport, _ := flags.GetString("errors-report")
+	only, _ := flags.GetString("only")
+	exclude, _ := flags.GetString("exclude")
+	newOnly, _ := flags.GetBool("new-only")
+	timeout, _ := flags.GetDuration("timeout")
+	resume, _ := flags.GetBool("resume")
+	live, _ := flags.GetBool("live")
+	statusCodes := cfg.Output.StatusCodes
+	if flags.Changed("status") {
+		statusCodes, _ = flags.GetIntSlice("status")
+	}
+
+	if templatePath != "" {
+		format = "template"
+	}
+
+	var fields []string
+	if fieldSpec != "" {
+		parsed, err := output.ParseFields(fieldSpec)
+		if err != nil {
+			return failWith(exitConfig, err)
+		}
+		fields = parsed
+	}
+
+	selected, err := selectSources(registry, only, exclude)
+	if err != nil {
+		return failWith(exitConfig, err)
+	}
+
+	if resume && cfg.Offline {
+		return failWith(exitConfig, errors.New("--resume cannot be combined with --offline"))
+	}
+
+	manager, err := openScanStorage(newOnly || cfg.Offline || resume)
+	if err != nil {
+		return err
+	}
+	if manager != nil {
+		defer manager.Close()
+	}
+
+	orch := orchestrator.NewOrchestrator(cfg, log)
+	var names []string
+	for _, source := range selected {
+		orch.RegisterSource(source)
+		if source.IsEnabled() {
+			names = append(names, source.Name())
+		}
+	}
+	if manager != nil {
+		orch.SetHistory(manager)
+		orch.SetScoreHistory(manager)
+		orch.SetHTTPCache(manager)
+		orch.SetWildcardStore(manager)
+	}
+
+	// Storage and export run after the scan context is canceled, so they
+	// use their own
+	bg := context.Background()
+
+	// Offline scans only re-read stored results; there is nothing to store
+	var scanID int64
+	if manager != nil && !cfg.Offline {
+		if resume {
+			scanID = resumeScan(bg, manager, selected, domain)
+		}
+		if scanID == 0 {
+			scanID, err = manager.CreateScan(bg, domain, cfg.ScanMode, names)
+			if err != nil {
+				log.Warn("Failed to record scan", zap.Error(err))
+			}
+		}
+		if scanID > 0 {
+			orch.SetSourceLog(manager, scanID, resume)
+		}
+	}
+
+	ctx, stop := scanContext(timeout)
+	defer stop()
+
+	var results []*types.Subdomain
+	if live {
+		results, err = runLive(ctx, orch, domain)
+	} else {
+		results, err = orch.Run(ctx, domain)
+	}
+	if err != nil {
+		return err
+	}
+	stopped := ctx.Err()
+	if stopped != nil {
+		fmt.Printf("[*] Scan stopped early (%v): exporting partial results\n", stopped)
+	}
+
+	stats := orch.GetStatistics()
+
+	exporter := output.NewExporter(log)
+	exporter.SetFields(fields)
+	exporter.SetStatusFilter(statusCodes)
+	if tagSpec != "" {
+		exporter.SetTagFilter(strings.Split(tagSpec, ","))
+	}
+	exporter.SetTemplate(templatePath)
+	exporter.SetStatistics(stats)
+	exporter.SetRegistration(orch.Registration())
+	exporter.SetBaseline(orch.Baseline())
+	exporter.SetLeads(orch.Leads())
+	exporter.SetCloudAssets(orch.CloudAssets())
+
+	found := len(results)
+	if manager != nil && scanID > 0 {
+		results, err = persistScan(bg, manager, exporter, orch, scanID, domain, results, newOnly, stopped == nil)
+		if err != nil {
+			return err
+		}
+	} else if newOnly {
+		return failWith(exitError, errors.New("--new-only: this scan was not stored, so there is nothing to compare it with"))
+	}
+
+	if errorsReport != "" {
+		if err := exporter.ExportErrors(bg, stats.Errors, errorsReport); err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to write errors report: %v\n", err)
+		}
+	}
+
+	if len(stats.MissingKnown) > 0 {
+		fmt.Printf("[-] Expected but missing: %d\n", len(stats.MissingKnown))
+		for _, name := range stats.MissingKnown {
+			fmt.Printf("    %s\n", name)
+		}
+	}
+
+	if len(results) == 0 {
+		if newOnly && found > 0 {
+			fmt.Printf("[*] No new subdomains since the previous scan (%d found)\n", found)
+			return nil
+		}
+		if stats.TotalSubdomains > 0 {
+			fmt.Printf("[*] None of %d subdomains passed validation.min_confidence (%d)\n", stats.TotalSubdomains, cfg.Validation.MinConfidence)
+			return nil
+		}
+		fmt.Printf("[*] No subdomains found (%d of %d sources completed)\n", stats.CompletedSources, stats.TotalSources)
+		return nil
+	}
+
+	build := getBuildInfo()
+	manifest := output.NewManifest(build.Version, domain, cfg, stats, len(results))
+	manifest.Commit = build.Commit
+	manifest.BuildDate = build.Date
+	exporter.SetManifest(manifest)
+
+	if format == "all" {
+		parent := outputPath
+		if parent == "" {
+			parent = cfg.OutputDir
+		}
+		dir, err := exporter.ExportAll(bg, results, domain, parent)
+		if err != nil {
+			return failWith(exitError, fmt.Errorf("export failed: %w", err))
+		}
+		printScanSummary(results)
+		fmt.Printf("[+] Bundle written to %s\n", dir)
+		return nil
+	}
+
+	if outputPath == "" {
+		outputPath = filepath.Join(cfg.OutputDir, fmt.Sprintf("%s.%s", domain, format))
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return failWith(exitError, fmt.Errorf("failed to create output directory: %w", err))
+	}
+	if err := exporter.Export(bg, results, format, outputPath); err != nil {
+		return failWith(exitError, fmt.Errorf("export failed: %w", err))
+	}
+
+	printScanSummary(results)
+	fmt.Printf("[+] Results written to %s\n", outputPath)
+	return nil
+}
+
+// runLive runs the scan through RunStream, printing each host as soon as it
+// validates, and returns the final results
+func runLive(ctx context.Context, orch *orchestrator.Orchestrator, domain string) ([]*types.Subdomain, error) {
+	stream, errs := orch.RunStream(ctx, domain)
+
+	var results []*types.Subdomain
+	for sub := range stream {
+		if sub.Provisional {
+			fmt.Printf("[+] %s %s\n", sub.Domain, strings.Join(sub.IP, ","))
+			continue
+		}
+		results = append(results, sub)
+	}
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// resumeScan finds the domain's last interrupted scan and prepares to
+// continue it: its partially stored results are cleared, as the resumed run
+// stores the full set, and sources that checkpoint their own progress pick
+// up where they stopped. It returns 0 when there is nothing to resume.
+func resumeScan(ctx context.Context, manager *storage.Manager, selected []sources.Source, domain string) int64 {
+	scanID, err := manager.GetRunningScan(ctx, domain)
+	if err != nil {
+		log.Warn("Failed to find interrupted scan", zap.Error(err))
+		return 0
+	}
+	if scanID == 0 {
+		fmt.Printf("[*] No interrupted scan of %s to resume: starting a new one\n", domain)
+		return 0
+	}
+
+	if err := manager.ClearSubdomains(ctx, scanID); err != nil {
+		log.Warn("Failed to clear interrupted scan results", zap.Error(err))
+		return 0
+	}
+
+	for _, source := range selected {
+		if resumable, ok := source.(sources.Resumable); ok {
+			resumable.SetResume(true)
+		}
+	}
+
+	fmt.Printf("[*] Resuming scan %d of %s\n", scanID, domain)
+	return scanID
+}
+
+// persistScan stores a scan's results, raw source output and cloud assets,
+// records the changes since the previous scan and returns the results to
+// export: only the new ones with newOnly. An interrupted scan (complete
+// unset) stays marked running so it is never taken as the previous scan.
+// With newOnly it fails, once the scan is stored, if there is no previous
+// scan to compare with.
+func persistScan(ctx context.Context, manager *storage.Manager, exporter *output.Exporter, orch *orchestrator.Orchestrator, scanID int64, domain string, results []*types.Subdomain, newOnly, complete bool) ([]*types.Subdomain, error) {
+	total, validated := len(results), 0
+	for _, sub := range results {
+		if err := manager.SaveSubdomain(ctx, scanID, sub); err != nil {
+			log.Warn("Failed to store subdomain", zap.String("subdomain", sub.Domain), zap.Error(err))
+		}
+		if sub.Validated {
+			validated++
+		}
+	}
+	if err := manager.SaveSourceResults(ctx, scanID, orch.RawResults()); err != nil {
+		log.Warn("Failed to store source results", zap.Error(err))
+	}
+	for _, asset := range orch.CloudAssets() {
+		if err := manager.SaveCloudAsset(ctx, scanID, storedCloudAsset(asset)); err != nil {
+			log.Warn("Failed to store cloud asset", zap.String("bucket", asset.Bucket), zap.Error(err))
+		}
+	}
+
+	// The scan must not be completed yet, or it is its own previous scan
+	differ := diff.NewDiffer(manager, log)
+	var changes *diff.DiffResult
+	var err error
+	if newOnly {
+		var fresh []*types.Subdomain
+		fresh, changes, err = differ.NewOnly(ctx, domain, scanID, results)
+		if err == nil {
+			results = fresh
+		}
+	} else {
+		changes, err = differ.CompareLatest(ctx, domain, scanID)
+	}
+
+	switch {
+	case errors.Is(err, diff.ErrNoPreviousScan):
+		// A first scan has no changes to record
+	case err != nil:
+		log.Warn("Failed to compare with previous scan", zap.Error(err))
+	default:
+		exporter.SetChanges(changes)
+		if complete {
+			if err := differ.SaveChanges(ctx, changes); err != nil {
+				log.Warn("Failed to store changes", zap.Error(err))
+			}
+		}
+	}
+
+	if trends, err := differ.DetectTrends(ctx, domain, 1000); err == nil {
+		exporter.SetTrends(trends)
+	}
+
+	if complete {
+		if err := manager.CompleteScan(ctx, scanID, total, validated); err != nil {
+			log.Warn("Failed to complete scan record", zap.Error(err))
+		}
+	}
+
+	// Exporting everything would pass every host off as new
+	if newOnly && errors.Is(err, diff.ErrNoPreviousScan) {
+		return nil, failWith(exitError, fmt.Errorf("--new-only: no previous scan of %s to compare with; this one is stored for the next run", domain))
+	}
+	if newOnly && err != nil {
+		return nil, failWith(exitError, fmt.Errorf("--new-only: failed to compare with the previous scan: %w", err))
+	}
+	return results, nil
+}
+
+// storedCloudAsset converts a probed bucket into its storage record
+func storedCloudAsset(asset cloud.CloudAsset) storage.CloudAsset {
+	return storage.CloudAsset{
+		Provider:   asset.Provider,
+		Bucket:     asset.Bucket,
+		Region:     asset.Region,
+		URL:        asset.URL,
+		Type:       asset.Type,
+		Access:     asset.Access,
+		Exists:     asset.Exists,
+		Accessible: asset.Accessible,
+	}
+}
+
+// printScanSummary prints the headline counts of the exported results
+func printScanSummary(results []*types.Subdomain) {
+	validated, high := 0, 0
+	for _, sub := range results {
+		if sub.Validated {
+			validated++
+		}
+		if sub.Confidence >= highConfidence {
+			high++
+		}
+	}
+
+	fmt.Printf("\n[+] %d subdomains: %d validated, %d high confidence\n", len(results), validated, high)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// stubSource returns fixed names, or fails with err
+type stubSource struct {
+	name  string
+	names []string
+	err   error
+}
+
+func (s *stubSource) Name() string             { return s.name }
+func (s *stubSource) Type() sources.SourceType { return sources.TypePassive }
+func (s *stubSource) IsEnabled() bool          { return true }
+func (s *stubSource) RateLimit() int           { return 0 }
+func (s *stubSource) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	return &types.SourceResult{Source: s.name, Subdomains: s.names, Error: s.err}, s.err
+}
+
+// stubRegistry registers the given sources only
+func stubRegistry(stubs ...*stubSource) *sources.Registry {
+	registry := sources.NewRegistry()
+	for _, stub := range stubs {
+		registry.Register(stub)
+	}
+	return registry
+}
+
+// setupScan points the package config at a temp database and output dir,
+// with DNS answered by a local server and no network enrichment
+func setupScan(t *testing.T, extra string) {
+	t.Helper()
+
+	server := dnstest.NewServer(t)
+	dir := t.TempDir()
+
+	yaml := fmt.Sprintf(`log_level: error
+output_dir: %q
+dns:
+  resolvers: [%q]
+  protocol: udp
+  timeout: 1
+  retries: 0
+validation:
+  dns_validation: false
+  http_validation: false
+  tls_validation: false
+  min_confidence: 0
+whois:
+  enabled: false
+storage:
+  path: %q
+  cache_dir: %q
+%s`, filepath.Join(dir, "out"), server.Addr, filepath.Join(dir, "usr.db"), filepath.Join(dir, "cache"), extra)
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	loaded, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	prevCfg, prevLog := cfg, log
+	cfg, log = loaded, zap.NewNop()
+	t.Cleanup(func() { cfg, log = prevCfg, prevLog })
+}
+
+// setScanFlags sets scan flags for one test, restoring them afterwards
+func setScanFlags(t *testing.T, values map[string]string) {
+	t.Helper()
+
+	flags := scanCmd.Flags()
+	for name, value := range values {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			t.Fatalf("no scan flag %q", name)
+		}
+		previous, changed := flag.Value.String(), flag.Changed
+		if err := flags.Set(name, value); err != nil {
+			t.Fatalf("set --%s: %v", name, err)
+		}
+		t.Cleanup(func() {
+			flag.Value.Set(previous)
+			flag.Changed = changed
+		})
+	}
+}
+
+// exportedDomains reads the domains of a JSON export
+func exportedDomains(t *testing.T, path string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	var exported struct {
+		Subdomains []types.Subdomain `json:"subdomains"`
+	}
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("decode export: %v\n%s", err, data)
+	}
+
+	var domains []string
+	for _, sub := range exported.Subdomains {
+		domains = append(domains, sub.Domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+func TestScanExportsSourceResults(t *testing.T) {
+	setupScan(t, "")
+	outputPath := filepath.Join(t.TempDir(), "results.json")
+	setScanFlags(t, map[string]string{"output": outputPath, "format": "json"})
+
+	registry := stubRegistry(
+		&stubSource{name: "stub_a", names: []string{"www.example.com", "api.example.com"}},
+		&stubSource{name: "stub_b", names: []string{"api.example.com", "mail.example.com", "www.other.org"}},
+	)
+
+	if err := runScan(scanCmd, registry, "example.com"); err != nil {
+		t.Fatalf("runScan: %v", err)
+	}
+
+	want := []string{"api.example.com", "mail.example.com", "www.example.com"}
+	if got := exportedDomains(t, outputPath); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("exported %v, want %v", got, want)
+	}
+}
+
+func TestScanFailuresReturnExitCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry *sources.Registry
+		flags    map[string]string
+		code     int
+	}{
+		{
+			name:     "unknown source",
+			registry: stubRegistry(&stubSource{name: "stub_a"}),
+			flags:    map[string]string{"only": "nope"},
+			code:     exitConfig,
+		},
+		{
+			name:     "bad fields",
+			registry: stubRegistry(&stubSource{name: "stub_a"}),
+			flags:    map[string]string{"fields": "no.such.field"},
+			code:     exitConfig,
+		},
+		{
+			name:     "unwritable output",
+			registry: stubRegistry(&stubSource{name: "stub_a", names: []string{"www.example.com"}}),
+			flags:    map[string]string{"output": filepath.Join(os.DevNull, "results.json")},
+			code:     exitError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupScan(t, "")
+			setScanFlags(t, tt.flags)
+
+			err := runScan(scanCmd, tt.registry, "example.com")
+			var failure *scanFailure
+			if !errors.As(err, &failure) || failure.code != tt.code {
+				t.Errorf("runScan error = %v, want exit code %d", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestNewOnlyWithoutPreviousScanFails(t *testing.T) {
+	setupScan(t, "")
+	outputPath := filepath.Join(t.TempDir(), "results.json")
+	setScanFlags(t, map[string]string{"output": outputPath, "new-only": "true"})
+
+	first := stubRegistry(&stubSource{name: "stub_a", names: []string{"www.example.com"}})
+	err := runScan(scanCmd, first, "example.com")
+	var failure *scanFailure
+	if !errors.As(err, &failure) || failure.code != exitError {
+		t.Fatalf("first --new-only scan error = %v, want exit code %d", err, exitError)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("first --new-only scan exported results (stat error %v)", err)
+	}
+
+	// The failed run still stored its scan, so the next one has a baseline
+	second := stubRegistry(&stubSource{name: "stub_a", names: []string{"www.example.com", "new.example.com"}})
+	if err := runScan(scanCmd, second, "example.com"); err != nil {
+		t.Fatalf("second --new-only scan: %v", err)
+	}
+	if got := exportedDomains(t, outputPath); strings.Join(got, " ") != "new.example.com" {
+		t.Errorf("second scan exported %v, want only new.example.com", got)
+	}
+}
+
+func TestEmptyScanOutcomesAreTold(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry *sources.Registry
+		code     int    // 0: the scan succeeds
+		message  string // expected in what the user is told
+	}{
+		{
+			name:     "no sources enabled",
+			registry: stubRegistry(),
+			code:     exitConfig,
+			message:  "No sources are enabled",
+		},
+		{
+			name: "all sources failed",
+			registry: stubRegistry(
+				&stubSource{name: "stub_a", err: errors.New("dial tcp: connection refused")},
+				&stubSource{name: "stub_b", err: errors.New("401 unauthorized")},
+			),
+			code:    exitSourcesFailed,
+			message: "not an empty result",
+		},
+		{
+			name:     "sources found nothing",
+			registry: stubRegistry(&stubSource{name: "stub_a"}, &stubSource{name: "stub_b", names: []string{"www.other.org"}}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupScan(t, "")
+			outputPath := filepath.Join(t.TempDir(), "results.json")
+			setScanFlags(t, map[string]string{"output": outputPath, "format": "json"})
+
+			err := runScan(scanCmd, tt.registry, "example.com")
+			if tt.code == 0 {
+				if err != nil {
+					t.Errorf("scan that found nothing failed: %v", err)
+				}
+			} else {
+				code, lines := explainScanError(err)
+				if code != tt.code {
+					t.Errorf("exit code %d for %v, want %d", code, err, tt.code)
+				}
+				if told := strings.Join(lines, "\n"); !strings.Contains(told, tt.message) {
+					t.Errorf("told %q, want it to say %q", told, tt.message)
+				}
+			}
+
+			// None of them has anything to export
+			if _, statErr := os.Stat(outputPath); !os.IsNotExist(statErr) {
+				t.Errorf("scan without results exported a file (stat error %v)", statErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/output"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [results|subdomain|manifest]",
+	Short: "Print the JSON Schema of USR's JSON output",
+	Long: `Schema prints a JSON Schema (draft 2020-12) describing USR's JSON output,
+so downstream tools can validate what they consume. The schema is generated
+from the result types themselves and always matches the running version.
+
+  results    the document written by --format json (default)
+  subdomain  a single result, as written per line by --format jsonl
+  manifest   manifest.json written alongside multi-format exports`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: output.SchemaTypes(),
+	Run: func(cmd *cobra.Command, args []string) {
+		kind := "results"
+		if len(args) > 0 {
+			kind = strings.ToLower(args[0])
+		}
+		path, _ := cmd.Flags().GetString("output")
+
+		schema, err := output.Schema(kind)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] %v\n", err)
+			os.Exit(1)
+		}
+
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to encode schema: %v\n", err)
+			os.Exit(1)
+		}
+		data = append(data, '\n')
+
+		if path == "" {
+			os.Stdout.Write(data)
+			return
+		}
+
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to write %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("[+] Wrote %s schema to %s\n", kind, path)
+	},
+}
+
+func init() {
+	schemaCmd.Flags().String("output", "", "write the schema to a file instead of stdout")
+
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/intelligence/scorer"
+	"github.com/yourusername/usr/internal/dns"
+	"github.com/yourusername/usr/internal/sources/passive"
+	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/modules/web/prober"
+	"github.com/yourusername/usr/output"
+	"github.com/yourusername/usr/storage"
+)
+
+// selftestDomain is the offline self-test target; .test is reserved and
+// never resolves on the real internet
+const selftestDomain = "usr-selftest.test"
+
+// selftestHosts caps how many discovered names are resolved and probed
+const selftestHosts = 5
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check the install with a small end-to-end scan",
+	Long: `Selftest runs a miniature scan and reports pass/fail for each stage:
+config load, one passive source (crt.sh), DNS resolution, HTTP probing,
+scoring, a storage write and read-back, and a JSON export. Run it before an
+engagement, or in CI, to confirm network access, resolvers and the database
+work together.
+
+Online it scans --domain through the configured resolvers. With --offline,
+crt.sh, the resolver and the probed hosts are replaced by local fakes, so
+only the wiring of the pipeline is tested. Storage and export use a
+temporary directory; no stored scan is touched. Any failed stage exits 1.`,
+	Example: `  usr selftest
+  usr selftest --domain example.org
+  usr selftest --offline`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		offline, _ := cmd.Flags().GetBool("offline")
+		domain, _ := cmd.Flags().GetString("domain")
+		timeout, _ := cmd.Flags().GetInt("timeout")
+
+		dir, err := os.MkdirTemp("", "usr-selftest-")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to create temporary directory: %v\n", err)
+			os.Exit(exitError)
+		}
+
+		test := &selftest{domain: domain, dir: dir}
+		mode := "online"
+		if offline {
+			mode = "offline"
+			test.domain = selftestDomain
+			if err := test.startFakes(); err != nil {
+				os.RemoveAll(dir)
+				fmt.Fprintf(os.Stderr, "[-] Failed to start local fakes: %v\n", err)
+				os.Exit(exitError)
+			}
+		}
+
+		fmt.Printf("[*] Self-test against %s (%s)\n\n", test.domain, mode)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+		passed := test.run(ctx)
+		cancel()
+		test.close()
+		os.RemoveAll(dir)
+
+		if !passed {
+			fmt.Fprintln(os.Stderr, "\n[-] Self-test failed")
+			os.Exit(exitError)
+		}
+		fmt.Println("\n[+] All stages passed")
+	},
+}
+
+// selftest carries state from one stage to the next
+type selftest struct {
+	domain string
+	dir    string
+
+	// Offline fakes: web serves crt.sh and every probed host, resolver
+	// answers A queries for the target's names. Both nil online.
+	web      *httptest.Server
+	resolver *mdns.Server
+	dnsAddr  string
+
+	names      []string
+	subdomains []*types.Subdomain
+	manager    *storage.Manager
+}
+
+// selftestStage is one pass/fail check; it returns a short detail line
+type selftestStage struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// run executes the stages in order, skipping the rest after a failure,
+// and reports whether all passed
+func (t *selftest) run(ctx context.Context) bool {
+	stages := []selftestStage{
+		{"config", t.checkConfig},
+		{"passive source", t.checkPassive},
+		{"dns resolution", t.checkDNS},
+		{"http probing", t.checkProbe},
+		{"scoring", t.checkScoring},
+		{"storage", t.checkStorage},
+		{"export", t.checkExport},
+	}
+
+	passed := true
+	for _, stage := range stages {
+		if !passed {
+			fmt.Printf("[*] %-16s skipped\n", stage.name)
+			continue
+		}
+
+		detail, err := stage.run(ctx)
+		if err != nil {
+			fmt.Printf("[-] %-16s FAIL: %v\n", stage.name, err)
+			passed = false
+			continue
+		}
+		fmt.Printf("[+] %-16s ok (%s)\n", stage.name, detail)
+	}
+	return passed
+}
+
+func (t *selftest) checkConfig(ctx context.Context) (string, error) {
+	if cfg == nil {
+		return "", errors.New("no configuration loaded")
+	}
+	if t.web == nil && len(cfg.DNS.Resolvers) == 0 && cfg.DNS.ResolversFile == "" {
+		return "", errors.New("no DNS resolvers configured (dns.resolvers or dns.resolvers_file)")
+	}
+	return fmt.Sprintf("scan mode %s, %d resolvers", cfg.ScanMode, len(cfg.DNS.Resolvers)), nil
+}
+
+func (t *selftest) checkPassive(ctx context.Context) (string, error) {
+	source := passive.NewCrtSh(true)
+	if t.web != nil {
+		source = passive.NewCrtShWithClient(true, t.client())
+	}
+
+	result, err := source.Enumerate(ctx, t.domain)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", source.Name(), err)
+	}
+	if len(result.Subdomains) == 0 {
+		return "", fmt.Errorf("%s returned no names for %s", source.Name(), t.domain)
+	}
+
+	t.names = result.Subdomains
+	sort.Strings(t.names)
+	return fmt.Sprintf("%s returned %d names in %s", source.Name(), len(t.names), result.Duration.Round(time.Millisecond)), nil
+}
+
+func (t *selftest) checkDNS(ctx context.Context) (string, error) {
+	dnsCfg := cfg.DNS
+	if t.resolver != nil {
+		dnsCfg.Resolvers = []string{t.dnsAddr}
+		dnsCfg.ResolversFile = ""
+		dnsCfg.ResolversV4 = nil
+		dnsCfg.ResolversV6 = nil
+		dnsCfg.Protocol = dns.ProtocolUDP
+	}
+	engine := dns.NewEngine(&dnsCfg, log)
+
+	names := t.names
+	if len(names) > selftestHosts {
+		names = names[:selftestHosts]
+	}
+	resolved := engine.ResolveBatch(ctx, names, selftestHosts)
+
+	now := time.Now()
+	for _, name := range names {
+		sub := &types.Subdomain{
+			Domain:    name,
+			Sources:   []string{"crtsh"},
+			FirstSeen: now,
+			LastSeen:  now,
+			Metadata:  make(map[string]interface{}),
+		}
+		if ips := resolved[name]; len(ips) > 0 {
+			sub.IP = ips
+			sub.Validated = true
+		}
+		t.subdomains = append(t.subdomains, sub)
+	}
+
+	if len(resolved) == 0 {
+		return "", fmt.Errorf("none of %d names resolved", len(names))
+	}
+	return fmt.Sprintf("%d of %d names resolved", len(resolved), len(names)), nil
+}
+
+func (t *selftest) checkProbe(ctx context.Context) (string, error) {
+	probe := prober.NewHTTPProber(&cfg.HTTP, log, selftestHosts)
+	if t.web != nil {
+		probe = prober.NewHTTPProberWithClient(t.client(), log, selftestHosts)
+	}
+
+	probed, answered := 0, 0
+	example := ""
+	for _, sub := range t.subdomains {
+		if !sub.Validated {
+			continue
+		}
+		probed++
+
+		info, tlsInfo := probe.ProbeWithTLS(ctx, sub.Domain)
+		if info == nil {
+			continue
+		}
+		prober.Apply(sub, info, tlsInfo)
+		answered++
+		if example == "" {
+			example = fmt.Sprintf("%s %d", sub.Domain, info.StatusCode)
+		}
+	}
+
+	if answered == 0 {
+		return "", fmt.Errorf("none of %d resolved hosts answered over HTTP(S)", probed)
+	}
+	return fmt.Sprintf("%d of %d hosts answered, e.g. %s", answered, probed, example), nil
+}
+
+func (t *selftest) checkScoring(ctx context.Context) (string, error) {
+	sc := scorer.NewScorer(&cfg.Scoring, log)
+	sc.BatchScore(ctx, t.subdomains)
+
+	top := 0
+	for _, sub := range t.subdomains {
+		top = max(top, sub.Confidence)
+	}
+	if top == 0 {
+		return "", errors.New("every host scored 0; check the scoring section of the config")
+	}
+	return fmt.Sprintf("top confidence %d", top), nil
+}
+
+func (t *selftest) checkStorage(ctx context.Context) (string, error) {
+	manager, err := storage.NewManager(filepath.Join(t.dir, "selftest.db"), log)
+	if err != nil {
+		return "", fmt.Errorf("failed to open database: %w", err)
+	}
+	t.manager = manager
+
+	scanID, err := manager.CreateScan(ctx, t.domain, "selftest", []string{"crtsh"})
+	if err != nil {
+		return "", fmt.Errorf("failed to create scan: %w", err)
+	}
+
+	validated := 0
+	for _, sub := range t.subdomains {
+		if err := manager.SaveSubdomain(ctx, scanID, sub); err != nil {
+			return "", fmt.Errorf("failed to save %s: %w", sub.Domain, err)
+		}
+		if sub.Validated {
+			validated++
+		}
+	}
+	if err := manager.SaveSourceResults(ctx, scanID, map[string][]string{"crtsh": t.names}); err != nil {
+		return "", err
+	}
+	if err := manager.CompleteScan(ctx, scanID, len(t.subdomains), validated); err != nil {
+		return "", fmt.Errorf("failed to complete scan: %w", err)
+	}
+
+	stored, err := manager.GetScanResults(ctx, scanID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read results back: %w", err)
+	}
+	if len(stored) != len(t.subdomains) {
+		return "", fmt.Errorf("saved %d hosts but read back %d", len(t.subdomains), len(stored))
+	}
+	return fmt.Sprintf("%d hosts written and read back", len(stored)), nil
+}
+
+func (t *selftest) checkExport(ctx context.Context) (string, error) {
+	path := filepath.Join(t.dir, "selftest.json")
+
+	exporter := output.NewExporter(log)
+	if err := exporter.Export(ctx, t.subdomains, "json", path); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var report struct {
+		TotalCount int `json:"total_count"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return "", fmt.Errorf("export is not valid JSON: %w", err)
+	}
+	if report.TotalCount != len(t.subdomains) {
+		return "", fmt.Errorf("exported %d hosts, expected %d", report.TotalCount, len(t.subdomains))
+	}
+	return fmt.Sprintf("%d hosts, %d bytes of JSON", report.TotalCount, len(data)), nil
+}
+
+// startFakes serves crt.sh and the target's hosts from one local HTTP
+// server and answers DNS for the target from a local resolver
+func (t *selftest) startFakes() error {
+	domain := t.domain
+	t.web = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host == "crt.sh" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `[{"name_value":"www.%[1]s\napi.%[1]s"},{"name_value":"*.dev.%[1]s"},{"name_value":"unrelated.example"}]`, domain)
+			return
+		}
+		w.Header().Set("Server", "usr-selftest")
+		fmt.Fprintf(w, "<html><head><title>USR self-test: %s</title></head><body>ok</body></html>", r.Host)
+	}))
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.web.Close()
+		return err
+	}
+	t.dnsAddr = conn.LocalAddr().String()
+
+	started := make(chan struct{})
+	t.resolver = &mdns.Server{
+		PacketConn:        conn,
+		NotifyStartedFunc: func() { close(started) },
+		Handler: mdns.HandlerFunc(func(w mdns.ResponseWriter, r *mdns.Msg) {
+			reply := new(mdns.Msg)
+			reply.SetReply(r)
+			for _, q := range r.Question {
+				if !mdns.IsSubDomain(mdns.Fqdn(domain), q.Name) {
+					reply.Rcode = mdns.RcodeNameError
+					continue
+				}
+				if q.Qtype == mdns.TypeA {
+					reply.Answer = append(reply.Answer, &mdns.A{
+						Hdr: mdns.RR_Header{Name: q.Name, Rrtype: mdns.TypeA, Class: mdns.ClassINET, Ttl: 60},
+						A:   net.IPv4(127, 0, 0, 1),
+					})
+				}
+			}
+			w.WriteMsg(reply)
+		}),
+	}
+	go t.resolver.ActivateAndServe()
+	<-started
+
+	return nil
+}
+
+// client returns an HTTP client that sends every request, whatever its
+// scheme and host, to the fake web server
+func (t *selftest) client() *http.Client {
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &selftestTransport{addr: t.web.Listener.Addr().String()},
+	}
+}
+
+// close releases the database and stops the fakes
+func (t *selftest) close() {
+	if t.manager != nil {
+		t.manager.Close()
+	}
+	if t.resolver != nil {
+		t.resolver.Shutdown()
+	}
+	if t.web != nil {
+		t.web.Close()
+	}
+}
+
+// selftestTransport rewrites requests to a fixed plain-HTTP address,
+// keeping the original Host header
+type selftestTransport struct {
+	addr string
+}
+
+func (s *selftestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	routed := req.Clone(req.Context())
+	routed.URL.Scheme = "http"
+	routed.URL.Host = s.addr
+	routed.Host = strings.TrimSuffix(req.URL.Hostname(), ".")
+	return http.DefaultTransport.RoundTrip(routed)
+}
+
+func init() {
+	selftestCmd.Flags().Bool("offline", false, "replace crt.sh, DNS and probed hosts with local fakes")
+	selftestCmd.Flags().String("domain", "example.com", "domain to scan online")
+	selftestCmd.Flags().Int("timeout", 120, "seconds before the whole self-test is abandoned")
+
+	rootCmd.AddCommand(selftestCmd)
+}
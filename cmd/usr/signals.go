@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// exitInterrupted is the conventional exit code after SIGINT (128 + 2)
+const exitInterrupted = 130
+
+// scanContext returns a context that is canceled after timeout (0 = no
+// limit) or on the first SIGINT/SIGTERM, so sources and DNS batches stop
+// and whatever was found so far can still be exported. A second signal
+// exits at once. stop releases the timer and the signal handler.
+func scanContext(timeout time.Duration) (ctx context.Context, stop func()) {
+	base, cancel := context.WithCancel(context.Background())
+	ctx, cancelTimeout := base, context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(base, timeout)
+	}
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go cancelOnSignal(signals, done, cancel, func(sig os.Signal, first bool) {
+		if first {
+			fmt.Fprintf(os.Stderr, "\n[*] %v received: stopping and exporting partial results (again to quit)\n", sig)
+			return
+		}
+		os.Exit(exitInterrupted)
+	})
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancelTimeout()
+		cancel()
+	}
+}
+
+// cancelOnSignal calls cancel on the first signal received and notify for
+// every signal, telling it whether it was the first, until done is closed
+func cancelOnSignal(signals <-chan os.Signal, done <-chan struct{}, cancel context.CancelFunc, notify func(sig os.Signal, first bool)) {
+	first := true
+	for {
+		select {
+		case sig := <-signals:
+			notify(sig, first)
+			if first {
+				cancel()
+				first = false
+			}
+		case <-done:
+			return
+		}
+	}
+}
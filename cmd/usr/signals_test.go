@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// notification is one call of cancelOnSignal's notify
+type notification struct {
+	sig   os.Signal
+	first bool
+}
+
+func TestCancelOnSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal)
+	done := make(chan struct{})
+	notified := make(chan notification, 2)
+	exited := make(chan struct{})
+
+	go func() {
+		cancelOnSignal(signals, done, cancel, func(sig os.Signal, first bool) {
+			notified <- notification{sig, first}
+		})
+		close(exited)
+	}()
+
+	if ctx.Err() != nil {
+		t.Fatal("canceled before any signal")
+	}
+
+	// The first signal cancels the scan
+	signals <- os.Interrupt
+	if got := <-notified; got != (notification{os.Interrupt, true}) {
+		t.Errorf("first notification = %+v, want interrupt marked first", got)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("first signal did not cancel the context")
+	}
+
+	// The second is only reported, for the caller to exit on
+	signals <- syscall.SIGTERM
+	if got := <-notified; got != (notification{syscall.SIGTERM, false}) {
+		t.Errorf("second notification = %+v, want SIGTERM not marked first", got)
+	}
+
+	close(done)
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler kept running after done was closed")
+	}
+}
+
+func TestCancelOnSignalStopsWithoutSignal(t *testing.T) {
+	canceled := false
+	done := make(chan struct{})
+	close(done)
+
+	cancelOnSignal(make(chan os.Signal), done, func() { canceled = true }, func(os.Signal, bool) {
+		t.Error("notified without a signal")
+	})
+
+	if canceled {
+		t.Error("scan canceled without a signal")
+	}
+}
+
+func TestScanContextTimeout(t *testing.T) {
+	ctx, stop := scanContext(50 * time.Millisecond)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("scan context outlived its timeout")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("err = %v, want the deadline", ctx.Err())
+	}
+}
+
+func TestScanContextStop(t *testing.T) {
+	ctx, stop := scanContext(0)
+	if ctx.Err() != nil {
+		t.Fatal("scan context without a timeout already done")
+	}
+
+	stop()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("err after stop = %v, want canceled", ctx.Err())
+	}
+}
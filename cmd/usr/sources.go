@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/sources/active"
+	"github.com/yourusername/usr/internal/sources/ai"
+	"github.com/yourusername/usr/internal/sources/passive"
+	"go.uber.org/zap"
+)
+
+// newSourceRegistry registers every enumeration source the CLI knows about,
+// enabled or not according to the config
+func newSourceRegistry(cfg *config.Config, logger *zap.Logger) *sources.Registry {
+	registry := sources.NewRegistry()
+	registry.Register(passive.NewCrtSh(cfg.Sources.Passive.CertificateTransparency))
+	registry.Register(passive.NewCommonCrawl(cfg.Sources.Passive.CommonCrawl, cfg.Sources.Passive.CommonCrawlIndexes,
+		time.Duration(cfg.Sources.Passive.CommonCrawlTimeout)*time.Second))
+	registry.Register(passive.NewShodan(cfg.Sources.Passive.Shodan, cfg.Sources.Passive.ShodanAPIKey))
+	registry.Register(passive.NewSPFDMARC(cfg.Sources.Passive.SPFDMARC, cfg.Sources.Passive.SPFDepth))
+	bruteForce := active.NewBruteForce(&cfg.Sources.Active, cfg.Storage.CacheDir, logger)
+	bruteForce.SetQueryTypes(cfg.DNS.QueryTypes.Bruteforce)
+	registry.Register(bruteForce)
+	registry.Register(active.NewPermutations(&cfg.Sources.Active, logger))
+	registry.Register(ai.NewAISource(cfg, logger))
+	return registry
+}
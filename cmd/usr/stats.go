@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/intelligence/scorer"
+	"github.com/yourusername/usr/storage"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [domain]",
+	Short: "Show stored scan statistics",
+	Long: `Stats summarizes the scan database. With a domain, it also shows the
+confidence distribution of that domain's latest completed scan and a
+suggested min_confidence taken from the knee of the distribution.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		manager, err := storage.NewManager(cfg.Storage.Path, log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to open database: %v\n", err)
+			os.Exit(1)
+		}
+		defer manager.Close()
+
+		stats, err := manager.GetStatistics(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to read statistics: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("[*] Scans:      %d\n", stats.TotalScans)
+		fmt.Printf("[*] Subdomains: %d\n", stats.TotalSubdomains)
+		fmt.Printf("[*] Changes:    %d\n", stats.TotalChanges)
+
+		if len(args) == 0 {
+			return
+		}
+
+		domain := strings.ToLower(args[0])
+		scanID, err := manager.GetLatestScan(ctx, domain)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to find scan: %v\n", err)
+			os.Exit(1)
+		}
+		if scanID == 0 {
+			fmt.Printf("\n[-] No completed scans for %s\n", domain)
+			return
+		}
+
+		scores, err := manager.GetScanConfidences(ctx, scanID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\n[*] Confidence distribution for %s (scan %d)\n\n", domain, scanID)
+		printHistogram(scorer.NewHistogram(scores))
+	},
+}
+
+// printHistogram renders a confidence histogram as text bars
+func printHistogram(histogram *scorer.Histogram) {
+	const width = 40
+
+	for _, bar := range histogram.Bars() {
+		fmt.Printf("%-7s %-*s %d\n", bar.Label, width, strings.Repeat("#", int(bar.Percent*width/100)), bar.Count)
+	}
+
+	fmt.Printf("\n[*] Suggested min_confidence: %d (configured: %d)\n",
+		histogram.SuggestedThreshold,
+		cfg.Validation.MinConfidence,
+	)
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// errNoTarget is returned when no source names a scan target
+var errNoTarget = errors.New("no target: pass a domain, set USR_TARGET or pipe the domain on stdin")
+
+// scanTarget determines the domain to scan: the argument, then USR_TARGET,
+// then the first non-empty line of stdin if stdin is not a terminal
+func scanTarget(args []string) (string, error) {
+	var target, origin string
+	switch {
+	case len(args) > 0:
+		target, origin = args[0], "argument"
+	case os.Getenv("USR_TARGET") != "":
+		target, origin = os.Getenv("USR_TARGET"), "USR_TARGET"
+	default:
+		line, err := readTargetLine()
+		if err != nil {
+			return "", err
+		}
+		target, origin = line, "stdin"
+	}
+
+	domain, err := normalizeTarget(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid target from %s: %w", origin, err)
+	}
+	return domain, nil
+}
+
+// readTargetLine reads the first non-empty line of piped stdin
+func readTargetLine() (string, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return "", errNoTarget
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read target from stdin: %w", err)
+	}
+	return "", errNoTarget
+}
+
+// normalizeTarget lowercases a domain and rejects values that can't be one
+// (URLs, paths, spaces, single labels)
+func normalizeTarget(target string) (string, error) {
+	domain := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(target), "."))
+
+	switch {
+	case domain == "":
+		return "", errors.New("empty domain")
+	case strings.Contains(domain, "://"), strings.ContainsAny(domain, "/ \t"):
+		return "", fmt.Errorf("%q is not a bare domain (drop the scheme and path)", target)
+	case !strings.Contains(domain, "."):
+		return "", fmt.Errorf("%q is not a fully qualified domain", target)
+	}
+
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 {
+			return "", fmt.Errorf("%q has an empty or over-long label", target)
+		}
+	}
+	return domain, nil
+}
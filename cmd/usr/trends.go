@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/usr/storage"
+	"github.com/yourusername/usr/storage/diff"
+	"go.uber.org/zap"
+)
+
+var trendsCmd = &cobra.Command{
+	Use:   "trends [domain]",
+	Short: "Show how a domain's attack surface changes over time",
+	Long: `Trends analyzes the changes recorded between stored scans of a domain:
+the overall trend, subdomains added and removed per day and per week, and
+the branches (parent domains) growing fastest.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		domain := strings.ToLower(args[0])
+		limit, _ := cmd.Flags().GetInt("limit")
+		weekly, _ := cmd.Flags().GetBool("weekly")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		manager, err := storage.NewManager(cfg.Storage.Path, log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to open database: %v\n", err)
+			os.Exit(1)
+		}
+		defer manager.Close()
+
+		// Logs go to stdout; keep them out of the JSON
+		logger := log
+		if asJSON {
+			logger = log.WithOptions(zap.IncreaseLevel(zap.WarnLevel))
+		}
+
+		analysis, err := diff.NewDiffer(manager, logger).DetectTrends(ctx, domain, limit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[-] Failed to analyze trends: %v\n", err)
+			os.Exit(1)
+		}
+
+		if asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(analysis); err != nil {
+				fmt.Fprintf(os.Stderr, "[-] Failed to encode trends: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if analysis.TotalChanges == 0 {
+			fmt.Printf("[-] No recorded changes for %s\n", domain)
+			return
+		}
+
+		fmt.Printf("[*] Trend for %s: %s\n", domain, analysis.Trend)
+		fmt.Printf("[*] Changes: %d (+%d added, -%d removed)\n\n", analysis.TotalChanges, analysis.AddedCount, analysis.RemovedCount)
+
+		buckets, period, layout := analysis.Daily, "DAY", "2006-01-02"
+		if weekly {
+			buckets, period = analysis.Weekly, "WEEK OF"
+		}
+		fmt.Printf("%-12s %8s %8s\n", period, "ADDED", "REMOVED")
+		for _, bucket := range buckets {
+			fmt.Printf("%-12s %8d %8d\n", bucket.Start.Format(layout), bucket.Added, bucket.Removed)
+		}
+
+		if len(analysis.Branches) > 0 {
+			fmt.Printf("\n[*] Fastest-growing branches\n")
+			for _, branch := range analysis.Branches {
+				fmt.Printf("    %-40s +%d (net %+d)\n", branch.Branch, branch.Added, branch.Net)
+			}
+		}
+	},
+}
+
+func init() {
+	trendsCmd.Flags().Int("limit", 1000, "number of most recent changes to analyze")
+	trendsCmd.Flags().Bool("weekly", false, "group changes per week instead of per day")
+	trendsCmd.Flags().Bool("json", false, "print the analysis as JSON")
+
+	rootCmd.AddCommand(trendsCmd)
+}
@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	mdns "github.com/miekg/dns"
+	"github.com/yourusername/usr/intelligence/scorer"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+	"github.com/yourusername/usr/internal/types"
+)
+
+func TestRegistrationLookupUsesApex(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+	rdap := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.Write([]byte(`{"nameservers":[{"ldhName":"ns1.example.com"},{"ldhName":"ns.other.net"}]}`))
+	}))
+	defer rdap.Close()
+
+	o := newTestOrchestrator(t, "whois:\n  enabled: true\n  rdap_url: "+rdap.URL+"\n")
+	scan := o.newScanContext("shop.eu.example.com")
+
+	o.lookupRegistration(context.Background(), scan)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requested) != 1 || requested[0] != "/domain/example.com" {
+		t.Errorf("RDAP requests = %v, want [/domain/example.com]", requested)
+	}
+	if o.registration == nil || o.registration.Domain != "example.com" {
+		t.Fatalf("registration = %+v, want example.com's", o.registration)
+	}
+}
+
+func TestBaselineCapturedAtApex(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.Add("example.com", mdns.TypeA, "192.0.2.1")
+	server.Add("example.com", mdns.TypeNS, "ns1.example.com")
+	server.Add("shop.eu.example.com", mdns.TypeA, "192.0.2.50")
+
+	o := newTestOrchestrator(t, dnsYAML(server, ""))
+	scan := o.newScanContext("shop.eu.example.com")
+
+	o.captureBaseline(context.Background(), scan)
+
+	if scan.Baseline == nil {
+		t.Fatal("no baseline captured")
+	}
+	if got := scan.Baseline.A; len(got) != 1 || got[0] != "192.0.2.1" {
+		t.Errorf("baseline A = %v, want the apex's [192.0.2.1]", got)
+	}
+	if server.Queries("shop.eu.example.com") != 0 {
+		t.Error("baseline queried the target instead of the apex")
+	}
+
+	// A host on the apex's address is its default vhost
+	o.results["www.eu.example.com"] = &types.Subdomain{Domain: "www.eu.example.com", IP: []string{"192.0.2.1"}, Validated: true}
+	o.calculateConfidence(context.Background(), scan)
+	if same, _ := o.results["www.eu.example.com"].Metadata[scorer.SameAsApexKey].(bool); !same {
+		t.Error("host on the apex's address not marked same_as_apex")
+	}
+}
@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/modules/web/cloud"
+	"github.com/yourusername/usr/modules/web/jsparser"
+)
+
+// collectCloudAssets extracts the storage buckets the scan's hosts point
+// at - CNAME targets, redirects, response header hosts and JS endpoints -
+// and probes each for anonymous access. A CNAME to a bucket that no longer
+// exists shows up as not found. With sources.active.permutations, bucket
+// names guessed from the apex (prod-example, example-backup...) are added
+// when they exist, up to sources.active.max_candidates names.
+func (o *Orchestrator) collectCloudAssets(ctx context.Context, scan *types.ScanContext) {
+	var refs []string
+	for _, sub := range scan.Results.Snapshot() {
+		if sub.DNSRecords != nil {
+			refs = append(refs, sub.DNSRecords.CNAME...)
+		}
+		if sub.HTTP != nil {
+			refs = append(refs, sub.HTTP.FinalURL)
+			refs = append(refs, sub.HTTP.RedirectChain...)
+			refs = append(refs, sub.HTTP.HeaderHosts...)
+		}
+		if endpoints, ok := sub.Metadata["js_endpoints"].([]jsparser.Endpoint); ok {
+			for _, endpoint := range endpoints {
+				refs = append(refs, endpoint.URL)
+			}
+		}
+	}
+
+	assets := o.cloudExtractor.ExtractFromContent(ctx, strings.Join(refs, "\n"), scan.Domain)
+	if o.config.Sources.Active.Permutations {
+		assets = append(assets, o.guessCloudAssets(ctx, scan, assets)...)
+	}
+	if len(assets) == 0 {
+		return
+	}
+
+	o.cloudAssets = o.cloudChecker.Probe(ctx, assets)
+}
+
+// guessCloudAssets returns the permutations of the apex that exist as
+// buckets, leaving out those already referenced by the scan's hosts
+func (o *Orchestrator) guessCloudAssets(ctx context.Context, scan *types.ScanContext, referenced []cloud.CloudAsset) []cloud.CloudAsset {
+	known := make(map[string]bool, len(referenced))
+	for _, asset := range referenced {
+		known[asset.Type+":"+asset.Bucket] = true
+	}
+
+	var candidates []cloud.CloudAsset
+	for _, asset := range o.cloudExtractor.CandidateAssets(o.cloudExtractor.GeneratePermutations(scan.Apex)) {
+		if !known[asset.Type+":"+asset.Bucket] {
+			candidates = append(candidates, asset)
+		}
+	}
+
+	return o.cloudChecker.Check(ctx, candidates)
+}
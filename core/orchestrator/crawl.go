@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// CrawlSource attributes hosts found by following links
+const CrawlSource = "link_crawling"
+
+// discoverByCrawling crawls from every host that answered HTTP and adds
+// the in-scope hosts its pages and scripts link to, catching names only
+// referenced in navigation. Like header discovery it runs one round: the
+// hosts it adds are not crawled.
+func (o *Orchestrator) discoverByCrawling(ctx context.Context, scan *types.ScanContext) {
+	var alive []*types.Subdomain
+	for _, sub := range scan.Results.Snapshot() {
+		if sub.HTTP != nil && sub.HTTP.URL != "" {
+			alive = append(alive, sub)
+		}
+	}
+	if len(alive) == 0 {
+		return
+	}
+
+	var (
+		mu    sync.Mutex
+		found []string
+		pages int
+	)
+	seen := make(map[string]bool)
+
+	err := pool.Run(ctx, alive, o.config.HTTPWorkers, func(ctx context.Context, sub *types.Subdomain) {
+		result, err := o.crawler.Crawl(ctx, sub.HTTP.URL, scan.InScope)
+		if err != nil {
+			o.logger.Debug("Crawl stopped", zap.String("url", sub.HTTP.URL), zap.Error(err))
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		pages += result.Pages
+		for _, host := range result.Hosts {
+			if !seen[host] {
+				seen[host] = true
+				found = append(found, host)
+			}
+		}
+	})
+	if err != nil {
+		o.recordPanics(PhaseValidation, CrawlSource, err)
+	}
+
+	o.logger.Info("Link crawling complete",
+		zap.Int("hosts", len(alive)),
+		zap.Int("pages", pages),
+	)
+
+	added := o.unknownHosts(found)
+	if len(added) == 0 {
+		return
+	}
+
+	o.logger.Info("Subdomains found by link crawling",
+		zap.Int("count", len(added)),
+	)
+
+	o.addDiscovered(ctx, scan, CrawlSource, added)
+}
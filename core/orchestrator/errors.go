@@ -0,0 +1,168 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/internal/pool"
+	"go.uber.org/zap"
+)
+
+// Scan phases that can record errors
+const (
+	PhaseBaseline     = "baseline"
+	PhaseWildcard     = "wildcard"
+	PhaseRegistration = "registration"
+	PhaseSources      = "sources"
+	PhaseValidation   = "validation"
+)
+
+// ErrNoSources means no source is enabled: a configuration problem
+var ErrNoSources = errors.New("no enabled sources")
+
+// ErrAllSourcesFailed means every source that ran failed, so an empty
+// result says nothing about the target (usually network or credentials)
+var ErrAllSourcesFailed = errors.New("all sources failed")
+
+// ErrSourceTimeout means a source ran past sources.timeout and was
+// abandoned; names it streamed before that are kept
+var ErrSourceTimeout = errors.New("source timed out")
+
+// Error kinds, so users can tell configuration problems from transient ones
+const (
+	ErrorKindTimeout     = "timeout"
+	ErrorKindRateLimited = "rate_limited"
+	ErrorKindAuth        = "auth"
+	ErrorKindCanceled    = "canceled"
+	ErrorKindPanic       = "panic"
+	ErrorKindOther       = "other"
+)
+
+// ScanError is a failure recorded during a scan, with where it happened
+type ScanError struct {
+	Source string    `json:"source,omitempty"`
+	Phase  string    `json:"phase"`
+	Kind   string    `json:"kind"`
+	Err    error     `json:"-"`
+	Time   time.Time `json:"time"`
+}
+
+// Error implements error
+func (e ScanError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("%s/%s: %v", e.Phase, e.Source, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Phase, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e ScanError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON includes the error message, which error values can't carry
+func (e ScanError) MarshalJSON() ([]byte, error) {
+	type plain ScanError
+	message := ""
+	if e.Err != nil {
+		message = e.Err.Error()
+	}
+	return json.Marshal(struct {
+		plain
+		Message string `json:"error"`
+	}{plain(e), message})
+}
+
+// classifyError guesses why an operation failed from the error chain and
+// message, covering the failures users most often need to act on
+func classifyError(err error) string {
+	var panicErr *pool.PanicError
+	if errors.As(err, &panicErr) {
+		return ErrorKindPanic
+	}
+	
+	if errors.Is(err, context.Canceled) {
+		return ErrorKindCanceled
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorKindTimeout
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "429"), strings.Contains(message, "rate limit"), strings.Contains(message, "too many requests"):
+		return ErrorKindRateLimited
+	case strings.Contains(message, "401"), strings.Contains(message, "403"),
+		strings.Contains(message, "api key"), strings.Contains(message, "unauthorized"), strings.Contains(message, "forbidden"):
+		return ErrorKindAuth
+	case strings.Contains(message, "timeout"), strings.Contains(message, "timed out"):
+		return ErrorKindTimeout
+	default:
+		return ErrorKindOther
+	}
+}
+
+// addError records a failure with its phase and, if any, source
+func (o *Orchestrator) addError(phase, source string, err error) {
+	o.statsMu.Lock()
+	defer o.statsMu.Unlock()
+	o.stats.Errors = append(o.stats.Errors, ScanError{
+		Source: source,
+		Phase:  phase,
+		Kind:   classifyError(err),
+		Err:    err,
+		Time:   time.Now(),
+	})
+}
+
+// recordPanics logs and records each panic recovered by a worker pool, so
+// one misbehaving source or probe is reported instead of ending the scan
+func (o *Orchestrator) recordPanics(phase, source string, err error) {
+	for _, p := range pool.Panics(err) {
+		o.logger.Error("Recovered from panic",
+			zap.String("phase", phase),
+			zap.String("source", source),
+			zap.Any("panic", p.Value),
+			zap.ByteString("stack", p.Stack),
+		)
+		o.addError(phase, source, p)
+	}
+}
+
+// errorKinds summarizes a phase's errors by kind, e.g. "2 timeout, 1 auth"
+func (o *Orchestrator) errorKinds(phase string) string {
+	o.statsMu.Lock()
+	defer o.statsMu.Unlock()
+	
+	counts := make(map[string]int)
+	var kinds []string
+	for _, scanErr := range o.stats.Errors {
+		if scanErr.Phase != phase {
+			continue
+		}
+		if counts[scanErr.Kind] == 0 {
+			kinds = append(kinds, scanErr.Kind)
+		}
+		counts[scanErr.Kind]++
+	}
+	
+	parts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		parts[i] = fmt.Sprintf("%d %s", counts[kind], kind)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GetErrors returns the failures recorded so far
+func (o *Orchestrator) GetErrors() []ScanError {
+	o.statsMu.Lock()
+	defer o.statsMu.Unlock()
+	return append([]ScanError(nil), o.stats.Errors...)
+}
@@ -0,0 +1,90 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// HeaderSource attributes hosts found in HTTP response headers
+const HeaderSource = "http_headers"
+
+// discoverFromHeaders adds the in-scope hosts named by probed hosts'
+// response headers (redirects, CSP, CORS, Link) and validates them. It runs
+// one round only: headers of the hosts it adds are not followed.
+func (o *Orchestrator) discoverFromHeaders(ctx context.Context, scan *types.ScanContext) {
+	seen := make(map[string]bool)
+	var found []string
+
+	o.resultsMu.RLock()
+	for _, sub := range o.results {
+		if sub.HTTP == nil {
+			continue
+		}
+		for _, host := range sub.HTTP.HeaderHosts {
+			if seen[host] || !scan.InScope(host) {
+				continue
+			}
+			seen[host] = true
+			if _, exists := o.results[host]; !exists {
+				found = append(found, host)
+			}
+		}
+	}
+	o.resultsMu.RUnlock()
+
+	if len(found) == 0 {
+		return
+	}
+
+	o.logger.Info("Subdomains found in HTTP headers",
+		zap.Int("count", len(found)),
+	)
+
+	o.addDiscovered(ctx, scan, HeaderSource, found)
+}
+
+// unknownHosts returns the names not yet in the results
+func (o *Orchestrator) unknownHosts(names []string) []string {
+	o.resultsMu.RLock()
+	defer o.resultsMu.RUnlock()
+
+	var unknown []string
+	for _, name := range names {
+		if _, exists := o.results[name]; !exists {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// addDiscovered adds names found after enumeration to the results under a
+// source, validates them and filters any that are wildcard answers
+func (o *Orchestrator) addDiscovered(ctx context.Context, scan *types.ScanContext, source string, found []string) {
+	o.processSourceResult(&types.SourceResult{
+		Source:     source,
+		Subdomains: found,
+	})
+
+	o.resultsMu.RLock()
+	added := make([]*types.Subdomain, 0, len(found))
+	for _, host := range found {
+		if sub, exists := o.results[host]; exists {
+			added = append(added, sub)
+		}
+	}
+	o.resultsMu.RUnlock()
+
+	err := pool.Run(ctx, added, scan.Budget.MaxThreads, func(ctx context.Context, sub *types.Subdomain) {
+		o.validateHost(ctx, scan, sub)
+	})
+	if err != nil {
+		o.recordPanics(PhaseValidation, source, err)
+	}
+
+	if scan.HasWildcards() {
+		o.filterWildcardResults(ctx, scan)
+	}
+}
@@ -0,0 +1,43 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+func TestHeaderHostsAddedInScopeOnly(t *testing.T) {
+	o := newTestOrchestrator(t, "validation:\n  dns_validation: false\n  http_validation: false\n  tls_validation: false\n")
+	scan := o.newScanContext("example.com")
+
+	o.results["www.example.com"] = &types.Subdomain{
+		Domain:  "www.example.com",
+		Sources: []string{"crtsh"},
+		HTTP: &types.HTTPInfo{
+			StatusCode:  200,
+			HeaderHosts: []string{"cdn.example.com", "sso.example.com", "www.example.com", "fonts.googleapis.com"},
+		},
+	}
+	o.results["sso.example.com"] = &types.Subdomain{Domain: "sso.example.com", Sources: []string{"crtsh"}}
+
+	o.discoverFromHeaders(context.Background(), scan)
+
+	cdn, ok := o.results["cdn.example.com"]
+	if !ok {
+		t.Fatal("in-scope header host not added")
+	}
+	if len(cdn.Sources) != 1 || cdn.Sources[0] != HeaderSource {
+		t.Errorf("cdn.example.com sources = %v, want [%s]", cdn.Sources, HeaderSource)
+	}
+	if _, ok := o.results["fonts.googleapis.com"]; ok {
+		t.Error("out-of-scope header host added")
+	}
+	// Known hosts aren't credited to the headers
+	if got := o.results["sso.example.com"].Sources; len(got) != 1 {
+		t.Errorf("sso.example.com sources = %v, want crtsh only", got)
+	}
+	if len(o.results) != 3 {
+		t.Errorf("got %d results, want 3", len(o.results))
+	}
+}
@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// stubSource returns fixed names, or fails with err, and counts its runs
+type stubSource struct {
+	name  string
+	names []string
+	err   error
+	runs  atomic.Int32
+}
+
+func (s *stubSource) Name() string             { return s.name }
+func (s *stubSource) Type() sources.SourceType { return sources.TypePassive }
+func (s *stubSource) IsEnabled() bool          { return true }
+func (s *stubSource) RateLimit() int           { return 0 }
+func (s *stubSource) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	s.runs.Add(1)
+	return &types.SourceResult{Source: s.name, Subdomains: s.names, Error: s.err}, s.err
+}
+
+// testConfig loads the default configuration with yaml layered over it
+func testConfig(t testing.TB, yaml string) *config.Config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("log_level: error\n"+yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Storage.CacheDir = t.TempDir()
+	cfg.OutputDir = t.TempDir()
+	return cfg
+}
+
+// newTestOrchestrator builds an orchestrator over testConfig(yaml)
+func newTestOrchestrator(t *testing.T, yaml string) *Orchestrator {
+	t.Helper()
+	return NewOrchestrator(testConfig(t, yaml), zap.NewNop())
+}
+
+// dnsYAML points the resolvers at server, with quick timeouts and no
+// retries; extra is appended inside the dns section
+func dnsYAML(server *dnstest.Server, extra string) string {
+	return fmt.Sprintf("dns:\n  resolvers: [%q]\n  protocol: udp\n  timeout: 1\n  retries: 0\n  wildcard_tests: 3\n%s", server.Addr, extra)
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// fakeHTTPCache answers every lookup with the same stored probe
+type fakeHTTPCache struct {
+	info    *types.HTTPInfo
+	tlsInfo *types.TLSInfo
+}
+
+func (f *fakeHTTPCache) GetRecentProbe(ctx context.Context, domain string, within time.Duration) (*types.HTTPInfo, *types.TLSInfo, error) {
+	return f.info, f.tlsInfo, nil
+}
+
+func TestCachedProbeStandsInForFreshOne(t *testing.T) {
+	o := newTestOrchestrator(t, "http:\n  recheck_ttl: 3600\n")
+	o.SetHTTPCache(&fakeHTTPCache{
+		info: &types.HTTPInfo{
+			URL:           "https://parked.example.com",
+			StatusCode:    200,
+			Parked:        true,
+			FinalURL:      "https://parked.example.com/lander",
+			RedirectChain: []string{"https://parked.example.com", "https://parked.example.com/lander"},
+			BodyHash:      "abc123",
+			FaviconHash:   1234,
+		},
+		tlsInfo: &types.TLSInfo{Subject: "parked.example.com", SANs: []string{"parked.example.com"}},
+	})
+
+	scan := o.newScanContext("example.com")
+	sub := &types.Subdomain{Domain: "parked.example.com", Validated: true, IP: []string{"192.0.2.1"}}
+	o.results[sub.Domain] = sub
+
+	o.probeHTTP(context.Background(), scan)
+
+	if sub.HTTP == nil || sub.HTTP.URL != "https://parked.example.com" || sub.HTTP.BodyHash != "abc123" {
+		t.Fatalf("cached result not applied in full: %+v", sub.HTTP)
+	}
+	for _, key := range []string{"http_cached", "parked", "favicon_hash"} {
+		if _, ok := sub.Metadata[key]; !ok {
+			t.Errorf("metadata %q missing from a cached probe: %v", key, sub.Metadata)
+		}
+	}
+
+	// The stored certificate spares the TLS check
+	if sub.TLS == nil || sub.TLS.Subject != "parked.example.com" {
+		t.Errorf("stored certificate not applied: %+v", sub.TLS)
+	}
+}
@@ -0,0 +1,110 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/modules/web/jsparser"
+	"go.uber.org/zap"
+)
+
+// JSSource attributes hosts found in JavaScript files
+const JSSource = "js_parsing"
+
+// SecretFindingType marks findings for credentials exposed in JavaScript
+const SecretFindingType = "js_secret"
+
+// analyzeJavaScript reads the JS files loaded by hosts that answered HTTP.
+// Endpoints and secrets are stored in the host's metadata (js_endpoints,
+// js_secrets), secrets also as findings, and in-scope hosts the files name
+// are added and validated. Like header discovery it runs one round.
+func (o *Orchestrator) analyzeJavaScript(ctx context.Context, scan *types.ScanContext) {
+	var alive []*types.Subdomain
+	for _, sub := range scan.Results.Snapshot() {
+		if sub.HTTP != nil && sub.HTTP.StatusCode > 0 {
+			alive = append(alive, sub)
+		}
+	}
+	if len(alive) == 0 {
+		return
+	}
+
+	var (
+		mu      sync.Mutex
+		found   []string
+		secrets int
+	)
+	seen := make(map[string]bool)
+
+	err := pool.Run(ctx, alive, o.config.HTTPWorkers, func(ctx context.Context, sub *types.Subdomain) {
+		// An interrupted analysis still reports what it read
+		result, err := o.jsParser.Analyze(ctx, sub.Domain)
+		if err != nil {
+			o.logger.Debug("JavaScript analysis interrupted", zap.String("domain", sub.Domain), zap.Error(err))
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, host := range result.Subdomains {
+			if !seen[host] && scan.InScope(host) {
+				seen[host] = true
+				found = append(found, host)
+			}
+		}
+
+		if len(result.Endpoints) == 0 && len(result.Secrets) == 0 {
+			return
+		}
+		if sub.Metadata == nil {
+			sub.Metadata = make(map[string]interface{})
+		}
+		if len(result.Endpoints) > 0 {
+			sub.Metadata["js_endpoints"] = result.Endpoints
+		}
+		if len(result.Secrets) > 0 {
+			sub.Metadata["js_secrets"] = result.Secrets
+			sub.Findings = append(sub.Findings, secretFindings(result.Secrets)...)
+			secrets += len(result.Secrets)
+		}
+	})
+	if err != nil {
+		o.recordPanics(PhaseValidation, JSSource, err)
+	}
+
+	o.logger.Info("JavaScript analysis complete",
+		zap.Int("hosts", len(alive)),
+		zap.Int("secrets", secrets),
+	)
+
+	added := o.unknownHosts(found)
+	if len(added) == 0 {
+		return
+	}
+
+	o.logger.Info("Subdomains found in JavaScript",
+		zap.Int("count", len(added)),
+	)
+
+	o.addDiscovered(ctx, scan, JSSource, added)
+}
+
+// secretFindings reports exposed secrets as findings, with the redacted
+// preview as evidence
+func secretFindings(secrets []jsparser.Secret) []types.Finding {
+	findings := make([]types.Finding, 0, len(secrets))
+	for _, secret := range secrets {
+		findings = append(findings, types.Finding{
+			Type:     SecretFindingType,
+			Title:    secret.Title + " in JavaScript",
+			Severity: secret.Severity,
+			URL:      secret.Source,
+			Evidence: secret.Preview,
+			Time:     time.Now(),
+		})
+	}
+	return findings
+}
@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"github.com/yourusername/usr/intelligence/known"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// loadKnown reads validation.known_file, if set
+func (o *Orchestrator) loadKnown() error {
+	if o.config.Validation.KnownFile == "" {
+		return nil
+	}
+
+	list, err := known.Load(o.config.Validation.KnownFile)
+	if err != nil {
+		return err
+	}
+	o.known = list
+
+	o.logger.Info("Loaded known subdomains",
+		zap.String("file", o.config.Validation.KnownFile),
+		zap.Int("count", list.Len()),
+	)
+	return nil
+}
+
+// applyKnown tags known hosts so the scorer boosts their confidence. It
+// runs after classification, which replaces the tags, and before scoring
+// and min_confidence filtering, so a known host the scan could not confirm
+// can still make the cut.
+func (o *Orchestrator) applyKnown(scan *types.ScanContext) {
+	if o.known == nil {
+		return
+	}
+
+	matched := o.known.Apply(scan.Results.Snapshot())
+	o.logger.Info("Known subdomains matched",
+		zap.Int("known", o.known.Len()),
+		zap.Int("matched", matched),
+	)
+}
+
+// reportMissingKnown records in-scope known subdomains absent from the
+// final results
+func (o *Orchestrator) reportMissingKnown(scan *types.ScanContext, results []*types.Subdomain) {
+	if o.known == nil {
+		return
+	}
+
+	missing := o.known.Missing(results, scan.InScope)
+
+	o.statsMu.Lock()
+	o.stats.MissingKnown = missing
+	o.statsMu.Unlock()
+
+	if len(missing) > 0 {
+		o.logger.Warn("Known subdomains missing from results",
+			zap.Int("count", len(missing)),
+			zap.Strings("domains", missing),
+		)
+	}
+}
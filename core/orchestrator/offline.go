@@ -0,0 +1,81 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// ResultHistory reads results stored by earlier scans
+type ResultHistory interface {
+	GetLatestScan(ctx context.Context, domain string) (int64, error)
+	GetScanResults(ctx context.Context, scanID int64) ([]*types.Subdomain, error)
+}
+
+// SetHistory gives offline scans the stored results to work from
+func (o *Orchestrator) SetHistory(history ResultHistory) {
+	o.history = history
+}
+
+// loadStored seeds the results with the latest completed scan of the domain
+func (o *Orchestrator) loadStored(ctx context.Context, scan *types.ScanContext) error {
+	if o.history == nil {
+		return fmt.Errorf("offline mode needs stored results, but no storage is configured")
+	}
+
+	scanID, err := o.history.GetLatestScan(ctx, scan.Domain)
+	if err != nil {
+		return fmt.Errorf("failed to find stored scan: %w", err)
+	}
+	if scanID == 0 {
+		return fmt.Errorf("no stored scan for %s", scan.Domain)
+	}
+
+	stored, err := o.history.GetScanResults(ctx, scanID)
+	if err != nil {
+		return fmt.Errorf("failed to load stored scan: %w", err)
+	}
+
+	o.resultsMu.Lock()
+	for _, sub := range stored {
+		if sub.Metadata == nil {
+			sub.Metadata = make(map[string]interface{})
+		}
+		o.results[sub.Domain] = sub
+	}
+	total := len(o.results)
+	o.resultsMu.Unlock()
+
+	o.statsMu.Lock()
+	o.stats.TotalSubdomains = total
+	o.statsMu.Unlock()
+
+	o.logger.Info("Loaded stored results",
+		zap.Int64("scan_id", scanID),
+		zap.Int("subdomains", len(stored)),
+	)
+	return nil
+}
+
+// offlineSources drops sources that need the network, logging which
+func (o *Orchestrator) offlineSources(all []sources.Source) []sources.Source {
+	var (
+		local   []sources.Source
+		skipped []string
+	)
+	for _, src := range all {
+		if sources.RequiresNetwork(src) {
+			skipped = append(skipped, src.Name())
+			continue
+		}
+		local = append(local, src)
+	}
+
+	if len(skipped) > 0 {
+		o.logger.Info("Offline mode: skipping network sources", zap.Strings("sources", skipped))
+	}
+	return local
+}
@@ -2,14 +2,36 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/yourusername/usr/intelligence/cdn"
+	"github.com/yourusername/usr/intelligence/classify"
+	"github.com/yourusername/usr/intelligence/dedup"
+	"github.com/yourusername/usr/intelligence/known"
+	"github.com/yourusername/usr/intelligence/ipclass"
+	"github.com/yourusername/usr/intelligence/pivot"
+	"github.com/yourusername/usr/intelligence/scorer"
+	"github.com/yourusername/usr/intelligence/whois"
 	"github.com/yourusername/usr/internal/config"
 	"github.com/yourusername/usr/internal/dns"
+	usrlog "github.com/yourusername/usr/internal/logger"
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/ratelimit"
 	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/stealth"
 	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/modules/web/cloud"
+	"github.com/yourusername/usr/modules/web/crawler"
+	"github.com/yourusername/usr/modules/web/jsparser"
+	"github.com/yourusername/usr/modules/web/paths"
+	"github.com/yourusername/usr/modules/web/prober"
+	"github.com/yourusername/usr/modules/web/screenshot"
+	"github.com/yourusername/usr/modules/web/sitemap"
 	"go.uber.org/zap"
 )
 
@@ -18,13 +40,67 @@ type Orchestrator struct {
 	config   *config.Config
 	logger   *zap.Logger
 	
-	dnsEngine *dns.Engine
-	registry  *sources.Registry
+	// Sampled logger for per-subdomain debug lines
+	trace *zap.Logger
+	
+	dnsEngine   *dns.Engine
+	registry    *sources.Registry
+	attempted   *sources.Attempted
+	limiters    map[string]*ratelimit.Limiter // per source name, nil = unlimited
+	httpProber  *prober.HTTPProber
+	tlsProber   *prober.TLSProber
+	pathProber  *paths.Prober // nil unless http.paths.enabled
+	capturer    *screenshot.Capturer // nil unless http.screenshots.enabled
+	jsParser    *jsparser.Parser // nil unless sources.web.js_parsing
+	crawler     *crawler.Crawler // nil unless sources.web.link_crawling
+	sitemaps    *sitemap.Fetcher // nil unless sources.web.sitemap_robots
+	cloudExtractor *cloud.Extractor // nil unless sources.web.cloud_assets
+	cloudChecker   *cloud.Checker
+	cdnDetector *cdn.Detector
+	classifier  *classify.Classifier
+	whoisClient *whois.Client
+	deduplicator *dedup.Deduplicator
+	scorer      *scorer.Scorer
+	
+	// Apex records captured before enumeration
+	baseline *types.DNSRecords
+	
+	// Apex registration data (WHOIS/RDAP)
+	registration *types.Registration
+	
+	// Related organizations/domains revealed by certificates
+	leads *pivot.Leads
+	
+	// Storage buckets the scan's hosts reference, probed for access
+	cloudAssets []cloud.CloudAsset
+	
+	// Stored HTTP results reused within http.recheck_ttl (optional)
+	httpCache HTTPCache
+	
+	// Stored scans offline mode works from (optional)
+	history ResultHistory
+	
+	// Wildcard detections reused across scans within dns.wildcard_ttl (optional)
+	wildcardStore WildcardStore
+	
+	// Per-source completion of the stored scan, for --resume (optional)
+	sourceLog SourceLog
+	scanID    int64
+	resume    bool
+	
+	// Analyst's known subdomains (validation.known_file, optional)
+	known *known.List
+	
+	// Live validation of a streamed scan (RunStream)
+	live *liveValidator
 	
 	// Results management
 	results      map[string]*types.Subdomain
 	resultsMu    sync.RWMutex
 	
+	// Names as each source reported them, for replay (guarded by resultsMu)
+	raw map[string][]string
+	
 	// Statistics
 	stats        *Statistics
 	statsMu      sync.Mutex
@@ -39,159 +115,845 @@ type Statistics struct {
 	TotalSubdomains int
 	ValidatedSubdomains int
 	FailedValidations int
-	Errors          []error
+	AttemptedCandidates int
+	ConfidenceHistogram *scorer.Histogram
+	MissingKnown    []string
+	Sources         []SourceStat
+	Errors          []ScanError
+	
+	// Progress of long-running sources (brute force), by source name;
+	// filled in live by GetStatistics
+	Progress        map[string]sources.Progress
+}
+
+// HTTPCache looks up HTTP results from earlier scans, with the certificate
+// stored alongside (nil if the host had none)
+type HTTPCache interface {
+	GetRecentProbe(ctx context.Context, domain string, within time.Duration) (*types.HTTPInfo, *types.TLSInfo, error)
+}
+
+// SourceStat records the outcome of a single source run
+type SourceStat struct {
+	Name     string        `json:"name"`
+	Type     string        `json:"type"`
+	Found    int           `json:"found"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+	
+	// Resumed is set when the source's results were loaded from the
+	// interrupted scan instead of running it again
+	Resumed bool `json:"resumed,omitempty"`
 }
 
 // NewOrchestrator creates a new orchestrator instance
 func NewOrchestrator(cfg *config.Config, logger *zap.Logger) *Orchestrator {
-	return &Orchestrator{
+	o := &Orchestrator{
 		config:    cfg,
 		logger:    logger,
-		dnsEngine: dns.NewEngine(&cfg.DNS, logger),
-		registry:  sources.NewRegistry(),
-		results:   make(map[string]*types.Subdomain),
+		trace:     usrlog.Sampled(logger, cfg.LogSampleFirst, cfg.LogSampleThereafter),
+		dnsEngine:   dns.NewEngine(&cfg.DNS, logger),
+		registry:    sources.NewRegistry(),
+		attempted:   sources.NewAttempted(),
+		limiters:    make(map[string]*ratelimit.Limiter),
+		httpProber:  prober.NewHTTPProber(&cfg.HTTP, logger, cfg.HTTPWorkers),
+		tlsProber:   prober.NewTLSProber(&cfg.HTTP, logger, cfg.HTTPWorkers),
+		cdnDetector: cdn.NewDetector(logger),
+		classifier:  classify.NewClassifier(logger),
+		whoisClient: whois.NewClient(cfg.Whois.RDAPURL, time.Duration(cfg.Whois.Timeout)*time.Second, logger),
+		deduplicator: dedup.NewDeduplicator(logger),
+		scorer:      scorer.NewScorer(&cfg.Scoring, logger),
+		results:     make(map[string]*types.Subdomain),
+		raw:         make(map[string][]string),
 		stats: &Statistics{
 			StartTime: time.Now(),
 		},
 	}
+	
+	o.deduplicator.SetHTTPMerge(cfg.Dedup.HTTPMerge)
+	o.scorer.SetKnownWeight(cfg.Validation.KnownWeight)
+	
+	if mode := cfg.Scope.KeyMode; mode != "" && mode != KeyModeHost && mode != KeyModeRegistrable {
+		logger.Warn("Unknown scope.key_mode, using host", zap.String("key_mode", mode))
+	}
+	
+	// Stealth mode trades speed for a low, irregular request profile. The
+	// HTTP prober's client carries the jitter to everything sharing it
+	// (paths, JS, crawling, sitemaps); HTTPWorkers bounds their pools.
+	var profile *stealth.Profile
+	if cfg.ScanMode == string(types.ModeStealth) {
+		profile = stealth.NewProfile(&cfg.Stealth)
+		o.dnsEngine.SetStealth(profile)
+		o.httpProber.SetStealth(profile)
+		o.tlsProber.SetStealth(profile)
+		
+		cfg.MaxThreads = profile.Concurrency(cfg.MaxThreads)
+		cfg.DNSWorkers = profile.Concurrency(cfg.DNSWorkers)
+		cfg.HTTPWorkers = profile.Concurrency(cfg.HTTPWorkers)
+		cfg.Sources.Web.JSWorkers = profile.Concurrency(cfg.Sources.Web.JSWorkers)
+		
+		logger.Info("Stealth mode enabled",
+			zap.Int("max_concurrency", cfg.Stealth.MaxConcurrency),
+			zap.Int("jitter_min_ms", cfg.Stealth.JitterMin),
+			zap.Int("jitter_max_ms", cfg.Stealth.JitterMax),
+		)
+	}
+	
+	if cfg.HTTP.Paths.Enabled {
+		o.pathProber = paths.NewProber(o.httpProber.Client(), &cfg.HTTP.Paths, o.httpProber.PrepareRequest, logger)
+	}
+	
+	// JS files are fetched through the prober's client and its timeouts
+	if cfg.Sources.Web.JSParsing {
+		o.jsParser = jsparser.NewParser(logger)
+		o.jsParser.SetLimits(cfg.Sources.Web.JSWorkers, cfg.Sources.Web.JSMaxBytes)
+		o.jsParser.SetClient(o.httpProber.Client())
+	}
+	
+	if cfg.Sources.Web.LinkCrawling {
+		o.crawler = crawler.NewCrawler(o.httpProber.Client(), &cfg.Sources.Web, o.httpProber.PrepareRequest, logger)
+	}
+	if cfg.Sources.Web.CloudAssets {
+		o.cloudExtractor = cloud.NewExtractor(logger)
+		o.cloudExtractor.SetMaxCandidates(cfg.Sources.Active.MaxCandidates)
+		o.cloudChecker = cloud.NewChecker(&cfg.Cloud, cfg.Storage.CacheDir, logger)
+		if profile != nil {
+			o.cloudChecker.SetStealth(profile)
+		}
+	}
+	if cfg.Sources.Web.SitemapRobots {
+		o.sitemaps = sitemap.NewFetcher(o.httpProber.Client(), &cfg.Sources.Web, o.httpProber.PrepareRequest, logger)
+	}
+	
+	// Screenshots need a browser; without one the scan goes on without them
+	if cfg.HTTP.Screenshots.Enabled {
+		dir := cfg.HTTP.Screenshots.Dir
+		if dir == "" {
+			dir = filepath.Join(cfg.OutputDir, "screenshots")
+		}
+		capturer, err := screenshot.NewCapturer(&cfg.HTTP.Screenshots, dir, logger)
+		if err != nil {
+			logger.Warn("Screenshots disabled", zap.Error(err))
+		} else {
+			o.capturer = capturer
+		}
+	}
+	
+	return o
+}
+
+// SetHTTPCache lets HTTP validation reuse stored results newer than
+// http.recheck_ttl instead of probing again
+func (o *Orchestrator) SetHTTPCache(cache HTTPCache) {
+	o.httpCache = cache
+}
+
+// SetScoreHistory enables the scorer's recency component, which counts
+// the stored scans that found each host
+func (o *Orchestrator) SetScoreHistory(history scorer.ScanCounter) {
+	o.scorer.SetHistory(history)
 }
 
 // RegisterSource adds a source to the orchestrator
 func (o *Orchestrator) RegisterSource(source sources.Source) {
 	o.registry.Register(source)
+	
+	// Candidate-generating sources share one attempted set per scan
+	if tracker, ok := source.(sources.AttemptTracker); ok {
+		tracker.SetAttempted(o.attempted)
+	}
+	
+	// Sources pacing their own requests get the limiter; the rest are
+	// paced in runSource
+	limiter := ratelimit.New(sources.Rate(source))
+	o.limiters[source.Name()] = limiter
+	if limited, ok := source.(sources.RateLimited); ok {
+		limited.SetRateLimiter(limiter)
+	}
+	
 	o.logger.Debug("Source registered",
 		zap.String("name", source.Name()),
 		zap.String("type", string(source.Type())),
 	)
 }
 
-// Run executes the complete reconnaissance workflow
+// Run executes the complete reconnaissance workflow, returning the results
+// once every phase has finished. See RunStream for results as they come.
 func (o *Orchestrator) Run(ctx context.Context, domain string) ([]*types.Subdomain, error) {
+	stream, errs := o.stream(ctx, domain, false)
+	
+	var results []*types.Subdomain
+	for sub := range stream {
+		results = append(results, sub)
+	}
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	
+	return results, nil
+}
+
+// run executes the workflow. With emit set, names are validated while the
+// sources still run and the hosts that validate are sent on it.
+func (o *Orchestrator) run(ctx context.Context, domain string, emit chan<- *types.Subdomain) ([]*types.Subdomain, error) {
 	o.logger.Info("Starting orchestrated reconnaissance",
 		zap.String("domain", domain),
 		zap.String("mode", o.config.ScanMode),
 	)
 	
-	// Phase 1: Wildcard Detection
-	o.logger.Info("Phase 1: Wildcard detection")
-	wildcardInfo, err := o.dnsEngine.IsWildcard(ctx, domain)
-	if err != nil {
-		o.logger.Warn("Wildcard detection failed", zap.Error(err))
-	} else if wildcardInfo.IsWildcard {
-		o.logger.Warn("Wildcard DNS detected - filtering will be applied",
-			zap.Strings("patterns", wildcardInfo.Patterns),
-		)
+	scan := o.newScanContext(domain)
+	offline := o.config.Offline
+	
+	if err := o.loadKnown(); err != nil {
+		return nil, err
+	}
+	
+	if offline {
+		// Offline: stored results stand in for the network phases
+		o.logger.Info("Offline mode: using stored results only")
+		if err := o.loadStored(ctx, scan); err != nil {
+			return nil, err
+		}
+	} else {
+		// Phase 1: Apex Baseline
+		o.logger.Info("Phase 1: Apex baseline")
+		o.captureBaseline(ctx, scan)
+		
+		// Phase 2: Wildcard Detection
+		o.logger.Info("Phase 2: Wildcard detection")
+		o.detectWildcard(ctx, scan)
+		
+		// Phase 3: Registration Lookup
+		if o.config.Whois.Enabled {
+			o.logger.Info("Phase 3: Registration lookup")
+			o.lookupRegistration(ctx, scan)
+		}
 	}
 	
-	// Phase 2: Source Enumeration
-	o.logger.Info("Phase 2: Source enumeration")
-	if err := o.runSources(ctx, domain); err != nil {
+	// Phase 4: Source Enumeration
+	o.logger.Info("Phase 4: Source enumeration")
+	if emit != nil && !offline {
+		o.live = o.startLive(ctx, scan, emit)
+	}
+	err := o.runSources(ctx, scan)
+	if o.live != nil {
+		o.live.stop()
+	}
+	if err != nil {
 		return nil, fmt.Errorf("source enumeration failed: %w", err)
 	}
 	
-	// Phase 3: DNS Validation
-	if o.config.Validation.DNSValidation {
-		o.logger.Info("Phase 3: DNS validation")
-		if err := o.validateDNS(ctx); err != nil {
-			o.logger.Error("DNS validation failed", zap.Error(err))
+	return o.analyze(ctx, scan, !offline)
+}
+
+// analyze runs everything after enumeration over the collected names:
+// validation and path checks (when validate is set), tagging, scoring and
+// deduplication
+func (o *Orchestrator) analyze(ctx context.Context, scan *types.ScanContext, validate bool) ([]*types.Subdomain, error) {
+	if !validate {
+		o.logger.Info("Skipping validation and path checks")
+	} else if o.config.Validation.Pipelined || o.live != nil {
+		// Phases 5-7: DNS, wildcard and HTTP/TLS checks per host in one pass
+		o.logger.Info("Phases 5-7: Pipelined validation")
+		o.validatePipelined(ctx, scan)
+		o.detectNestedWildcards(ctx, scan)
+		
+		if scan.HasWildcards() {
+			o.filterWildcardResults(ctx, scan)
 		}
+	} else {
+		// Phase 5: DNS Validation
+		if o.config.Validation.DNSValidation {
+			o.logger.Info("Phase 5: DNS validation")
+			if err := o.validateDNS(ctx, scan); err != nil {
+				o.logger.Error("DNS validation failed", zap.Error(err))
+				o.addError(PhaseValidation, "dns", err)
+			}
+		}
+		
+		// Phase 6: Wildcard Filtering
+		o.detectNestedWildcards(ctx, scan)
+		if scan.HasWildcards() {
+			o.logger.Info("Phase 6: Wildcard filtering")
+			o.filterWildcardResults(ctx, scan)
+		}
+		
+		// Phase 7: HTTP Validation
+		if o.config.Validation.HTTPValidation {
+			o.logger.Info("Phase 7: HTTP validation")
+			o.probeHTTP(ctx, scan)
+		}
+		
+		// Certificates of hosts HTTPS probing didn't reach
+		if o.config.Validation.TLSValidation {
+			o.logger.Info("Phase 7: TLS certificate checks")
+			if err := o.tlsProber.ProbeBatch(ctx, scan.Results.Snapshot()); err != nil {
+				o.recordPanics(PhaseValidation, "tls", err)
+			}
+		}
+	}
+	
+	// MX/NS/TXT and other records only wanted for hosts that exist
+	if validate {
+		o.enrichRecords(ctx, scan)
+	}
+	
+	// Hosts named in response headers are validated like any other
+	if o.config.HTTP.HeaderDiscovery && o.config.Validation.HTTPValidation && validate {
+		o.discoverFromHeaders(ctx, scan)
+	}
+	
+	// Multi-domain certificates name siblings CT logs may not show
+	if o.config.Validation.SANDiscovery && validate {
+		o.discoverFromSANs(ctx, scan)
+	}
+	
+	// Subdomains, endpoints and secrets in the JS the pages load
+	if o.jsParser != nil && validate {
+		o.logger.Info("Phase 8: JavaScript analysis")
+		o.analyzeJavaScript(ctx, scan)
 	}
 	
-	// Phase 4: Wildcard Filtering
-	if wildcardInfo != nil && wildcardInfo.IsWildcard {
-		o.logger.Info("Phase 4: Wildcard filtering")
-		o.filterWildcardResults(ctx, domain, wildcardInfo)
+	// Hosts listed in robots.txt and sitemaps
+	if o.sitemaps != nil && validate {
+		o.logger.Info("Phase 8: robots.txt and sitemaps")
+		o.discoverFromSitemaps(ctx, scan)
 	}
 	
-	// Phase 5: Confidence Scoring
-	o.logger.Info("Phase 5: Confidence scoring")
-	o.calculateConfidence()
+	// Hosts only linked to from other hosts' pages
+	if o.crawler != nil && validate {
+		o.logger.Info("Phase 8: Link crawling")
+		o.discoverByCrawling(ctx, scan)
+	}
+	
+	// Buckets named by CNAMEs, redirects and scripts
+	if o.cloudExtractor != nil && validate {
+		o.logger.Info("Phase 8: Cloud storage checks")
+		o.collectCloudAssets(ctx, scan)
+	}
+	
+	// Phase 8: Exposed Path Checks
+	if o.pathProber != nil && validate {
+		o.logger.Info("Phase 8: Exposed path checks")
+		if _, err := o.pathProber.ProbeBatch(ctx, scan.Results.Snapshot()); err != nil {
+			o.recordPanics(PhaseValidation, "paths", err)
+		}
+	}
+	
+	// Screenshots of the pages that loaded
+	if o.capturer != nil && validate {
+		o.logger.Info("Phase 8: Screenshots")
+		if _, err := o.capturer.CaptureBatch(ctx, scan.Results.Snapshot()); err != nil {
+			o.recordPanics(PhaseValidation, "screenshots", err)
+		}
+	}
+	
+	// Phase 9: CDN/WAF Detection and Tagging
+	o.logger.Info("Phase 9: CDN/WAF detection and tagging")
+	o.cdnDetector.DetectBatch(ctx, scan.Results.Snapshot())
+	o.classifier.ClassifyBatch(ctx, scan.Results.Snapshot())
+	
+	// Phase 10: Confidence Scoring
+	o.logger.Info("Phase 10: Confidence scoring")
+	o.applyKnown(scan)
+	o.calculateConfidence(ctx, scan)
+	o.scoreDistribution(scan)
 	
 	// Compile final results
-	results := o.getFinalResults()
+	results := o.getFinalResults(scan)
 	
+	// Phase 11: Deduplication
+	o.logger.Info("Phase 11: Deduplication")
+	results = o.deduplicator.Deduplicate(ctx, results)
+	if o.config.Dedup.CollapseWWW {
+		results = o.deduplicator.CollapseWWW(ctx, results)
+	}
+	if o.config.Dedup.RemoveSimilar {
+		results = o.deduplicator.RemoveSimilar(ctx, results, o.config.Dedup.SimilarityThreshold)
+	}
+	
+	o.reportMissingKnown(scan, results)
+	
+	// Certificate pivots are reported as leads, never scanned
+	o.leads = pivot.Collect(scan.Apex, scan.Results.Snapshot())
+	if o.leads.Count() > 0 {
+		o.logger.Info("Certificate pivots found",
+			zap.Int("organizations", len(o.leads.Organizations)),
+			zap.Int("domains", len(o.leads.Domains)),
+		)
+	}
+	
+	o.statsMu.Lock()
+	o.stats.AttemptedCandidates = scan.Attempted.Len()
 	o.stats.EndTime = time.Now()
+	o.statsMu.Unlock()
+	
 	o.logStatistics()
 	
 	return results, nil
 }
 
+// Scope key modes (scope.key_mode)
+const (
+	KeyModeHost        = "host"        // the target and names below it
+	KeyModeRegistrable = "registrable" // everything under the target's registrable domain
+)
+
+// newScanContext builds the per-scan state handed to every phase and source
+func (o *Orchestrator) newScanContext(domain string) *types.ScanContext {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	apex := pivot.Apex(domain)
+	if apex == "" {
+		// A single-label target is its own registrable domain
+		apex = domain
+	}
+	
+	// Sources enumerate the root and results outside it are dropped
+	root := domain
+	if o.config.Scope.KeyMode == KeyModeRegistrable && apex != domain {
+		root = apex
+		o.logger.Info("Scope widened to registrable domain",
+			zap.String("target", domain),
+			zap.String("root", root),
+		)
+	}
+	
+	return &types.ScanContext{
+		Domain: domain,
+		Apex:   apex,
+		Root:   root,
+		Mode:   types.ScanMode(o.config.ScanMode),
+		Config: o.config,
+		Scope: func(name string) bool {
+			name = strings.ToLower(strings.TrimSuffix(name, "."))
+			return name == root || strings.HasSuffix(name, "."+root)
+		},
+		Budget: types.ScanBudget{
+			MaxThreads:   o.config.MaxThreads,
+			DNSWorkers:   o.config.DNSWorkers,
+			HTTPWorkers:  o.config.HTTPWorkers,
+			DNSRateLimit: o.config.DNS.RateLimit,
+			ActiveWorkers: o.config.Sources.Active.Workers,
+		},
+		Resolver:  o.dnsEngine,
+		Attempted: o.attempted,
+		Results:   resultStore{o},
+	}
+}
+
+// resultStore exposes the orchestrator's results to sources via the scan context
+type resultStore struct {
+	o *Orchestrator
+}
+
+// Add records subdomains reported by a source
+func (r resultStore) Add(source string, subdomains []string) {
+	r.o.processSourceResult(&types.SourceResult{
+		Source:     source,
+		Subdomains: subdomains,
+	})
+}
+
+// Snapshot returns the subdomains discovered so far
+func (r resultStore) Snapshot() []*types.Subdomain {
+	return r.o.snapshot()
+}
+
+// captureBaseline records the apex's own A/AAAA/MX/NS records so hosts that
+// merely share the apex's infrastructure (default vhost) can be told apart.
+// The apex is the registrable domain even when the target is below it.
+func (o *Orchestrator) captureBaseline(ctx context.Context, scan *types.ScanContext) {
+	records, err := o.dnsEngine.ResolveRecords(ctx, scan.Apex, []string{"A", "AAAA", "MX", "NS"})
+	if err != nil {
+		o.logger.Warn("Apex baseline capture failed", zap.Error(err))
+		o.addError(PhaseBaseline, "", err)
+		return
+	}
+	
+	o.baseline = records
+	scan.Baseline = records
+	
+	o.logger.Info("Apex baseline captured",
+		zap.Strings("a", records.A),
+		zap.Strings("aaaa", records.AAAA),
+		zap.Strings("mx", records.MX),
+		zap.Strings("ns", records.NS),
+	)
+}
+
+// detectWildcard probes the scan root - the zone sources and brute force
+// enumerate, wider than the target under scope.key_mode=registrable - for
+// wildcard DNS and records the answers, reusing a recent stored detection
+// when there is one. A target below the root is covered by the nested
+// detection like any other parent.
+func (o *Orchestrator) detectWildcard(ctx context.Context, scan *types.ScanContext) {
+	if o.reuseWildcard(ctx, scan) {
+		return
+	}
+	
+	wildcardInfo, err := o.dnsEngine.IsWildcard(ctx, scan.Root)
+	if err != nil {
+		o.logger.Warn("Wildcard detection failed", zap.Error(err))
+		o.addError(PhaseWildcard, "", err)
+		return
+	}
+	
+	scan.Wildcard = wildcardInfo
+	o.saveWildcard(ctx, scan)
+	
+	if wildcardInfo.IsWildcard {
+		o.logger.Warn("Wildcard DNS detected - filtering will be applied",
+			zap.Strings("patterns", wildcardInfo.Patterns),
+			zap.Strings("patterns_v6", wildcardInfo.PatternsV6),
+		)
+	}
+}
+
+// sameAsApex reports whether every IP of a subdomain belongs to the apex
+func (o *Orchestrator) sameAsApex(scan *types.ScanContext, sub *types.Subdomain) bool {
+	if scan.Baseline == nil || len(sub.IP) == 0 {
+		return false
+	}
+	
+	apexIPs := make(map[string]bool)
+	for _, ip := range dns.RecordIPs(scan.Baseline) {
+		apexIPs[ip] = true
+	}
+	
+	for _, ip := range sub.IP {
+		if !apexIPs[ip] {
+			return false
+		}
+	}
+	
+	return true
+}
+
+// lookupRegistration fetches WHOIS/RDAP data for the registrable domain,
+// which registries hold records for even when the target is below it, and
+// seeds in-scope nameservers as discovered subdomains
+func (o *Orchestrator) lookupRegistration(ctx context.Context, scan *types.ScanContext) {
+	reg, err := o.whoisClient.Lookup(ctx, scan.Apex)
+	if err != nil {
+		o.logger.Warn("Registration lookup failed", zap.Error(err))
+		o.addError(PhaseRegistration, "whois", err)
+		return
+	}
+	
+	o.registration = reg
+	
+	o.logger.Info("Registration data retrieved",
+		zap.String("registrar", reg.Registrar),
+		zap.String("registrant", reg.Registrant),
+		zap.Strings("nameservers", reg.Nameservers),
+		zap.String("source", reg.Source),
+	)
+	
+	var inScope []string
+	for _, ns := range reg.Nameservers {
+		if scan.InScope(ns) {
+			inScope = append(inScope, ns)
+		}
+	}
+	
+	if len(inScope) > 0 {
+		scan.Results.Add("whois", inScope)
+	}
+}
+
 // runSources executes all enabled sources
-func (o *Orchestrator) runSources(ctx context.Context, domain string) error {
+func (o *Orchestrator) runSources(ctx context.Context, scan *types.ScanContext) error {
 	enabledSources := o.registry.GetAll()
+	if o.config.Offline {
+		enabledSources = o.offlineSources(enabledSources)
+	}
 	o.stats.TotalSources = len(enabledSources)
 	
 	if len(enabledSources) == 0 {
-		return fmt.Errorf("no enabled sources found")
+		// Offline scans may have nothing to run beyond the stored results
+		if o.config.Offline {
+			return nil
+		}
+		return ErrNoSources
+	}
+	
+	if o.resume && o.sourceLog != nil {
+		enabledSources = o.skipCompleted(ctx, scan, enabledSources)
 	}
 	
 	o.logger.Info("Running enumeration sources",
 		zap.Int("source_count", len(enabledSources)),
 	)
 	
-	var wg sync.WaitGroup
-	resultsChan := make(chan *types.SourceResult, len(enabledSources))
+	// Sources expanding on others' results run once those are in
+	var first, expanders []sources.Source
+	for _, src := range enabledSources {
+		if sources.Expands(src) {
+			expanders = append(expanders, src)
+		} else {
+			first = append(first, src)
+		}
+	}
+	o.runSourceRound(ctx, scan, first)
+	if len(expanders) > 0 && ctx.Err() == nil {
+		o.logger.Info("Running expanding sources",
+			zap.Int("source_count", len(expanders)),
+		)
+		o.runSourceRound(ctx, scan, expanders)
+	}
+	
+	// An empty result only means something if at least one source worked;
+	// offline scans still have the stored results to go on, and canceled
+	// scans keep whatever was found
+	o.statsMu.Lock()
+	completed := o.stats.CompletedSources
+	o.statsMu.Unlock()
+	if completed == 0 && !o.config.Offline && ctx.Err() == nil {
+		return fmt.Errorf("%w (%s)", ErrAllSourcesFailed, o.errorKinds(PhaseSources))
+	}
+	
+	return nil
+}
+
+// runSourceRound runs sources concurrently and processes their results as
+// they arrive, returning once every source has finished
+func (o *Orchestrator) runSourceRound(ctx context.Context, scan *types.ScanContext, round []sources.Source) {
+	if len(round) == 0 {
+		return
+	}
 	
-	// Launch sources concurrently
-	for _, source := range enabledSources {
-		wg.Add(1)
-		go func(src sources.Source) {
-			defer wg.Done()
-			
-			o.logger.Debug("Starting source",
+	workers := scan.Budget.MaxThreads
+	if workers <= 0 {
+		workers = len(round)
+	}
+	
+	resultsChan := make(chan *types.SourceResult, len(round))
+	
+	go func() {
+		defer close(resultsChan)
+		
+		// runSource recovers its own panics to attribute them to the source
+		pool.Run(ctx, round, workers, func(ctx context.Context, src sources.Source) {
+			o.runSource(ctx, scan, src, resultsChan)
+		})
+	}()
+	
+	for result := range resultsChan {
+		o.processSourceResult(result)
+	}
+}
+
+// runSource enumerates one source, recording its outcome and sending
+// non-streamed results on resultsChan
+func (o *Orchestrator) runSource(ctx context.Context, scan *types.ScanContext, src sources.Source, resultsChan chan<- *types.SourceResult) {
+	o.logger.Debug("Starting source",
+		zap.String("source", src.Name()),
+		zap.String("type", string(src.Type())),
+	)
+	
+	startTime := time.Now()
+	
+	// A panicking source is recorded as failed; the scan carries on
+	defer pool.Recover(func(p *pool.PanicError) {
+		o.recordPanics(PhaseSources, src.Name(), p)
+		o.addSourceStat(SourceStat{
+			Name:     src.Name(),
+			Type:     string(src.Type()),
+			Duration: time.Since(startTime),
+			Error:    p.Error(),
+		})
+	})
+	
+	// One hung source must not stall the scan: past its timeout it is
+	// abandoned, and it is up to the source to notice ctx and stop
+	timeout := sources.Timeout(src, time.Duration(o.config.Sources.Timeout)*time.Second)
+	sourceCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		sourceCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+	
+	var result *types.SourceResult
+	var err error
+	if _, paced := src.(sources.RateLimited); !paced {
+		err = o.limiters[src.Name()].Wait(sourceCtx)
+	}
+	streaming, isStreaming := src.(sources.StreamingSource)
+	switch {
+	case err != nil:
+		// Stopped while waiting for the rate limit
+	case isStreaming:
+		result, err = o.consumeStream(sourceCtx, src.Name(), streaming, scan)
+	default:
+		result, err = o.enumerate(sourceCtx, src, scan)
+	}
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(sourceCtx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("%w after %v", ErrSourceTimeout, timeout)
+		}
+		
+		var panicErr *pool.PanicError
+		if errors.As(err, &panicErr) {
+			o.recordPanics(PhaseSources, src.Name(), panicErr)
+		} else {
+			o.logger.Error("Source enumeration failed",
 				zap.String("source", src.Name()),
-				zap.String("type", string(src.Type())),
+				zap.Error(err),
 			)
-			
-			result, err := src.Enumerate(ctx, domain)
-			if err != nil {
-				o.logger.Error("Source enumeration failed",
-					zap.String("source", src.Name()),
-					zap.Error(err),
-				)
-				o.addError(err)
-				return
+			o.addError(PhaseSources, src.Name(), err)
+		}
+		
+		// Streamed names are already in the store, and a timed out source
+		// that did return keeps what it found
+		found := 0
+		if result != nil {
+			found = len(result.Subdomains)
+			if !isStreaming && found > 0 && errors.Is(err, ErrSourceTimeout) {
+				resultsChan <- result
 			}
-			
-			resultsChan <- result
-			
-			o.statsMu.Lock()
-			o.stats.CompletedSources++
-			o.statsMu.Unlock()
-			
-			o.logger.Info("Source completed",
-				zap.String("source", src.Name()),
-				zap.Int("subdomains_found", len(result.Subdomains)),
-				zap.Duration("duration", result.Duration),
-			)
-		}(source)
+		}
+		o.addSourceStat(SourceStat{
+			Name:     src.Name(),
+			Type:     string(src.Type()),
+			Found:    found,
+			Duration: time.Since(startTime),
+			Error:    err.Error(),
+		})
+		return
+	}
+	
+	// Streamed names are already in the store
+	if !isStreaming {
+		resultsChan <- result
+	}
+	
+	// A source that returned because the scan was stopped may not have
+	// finished; only record it as completed when the scan is still running
+	if ctx.Err() == nil {
+		o.recordSourceCompletion(ctx, src.Name(), result.Subdomains)
+	}
+	
+	o.statsMu.Lock()
+	o.stats.CompletedSources++
+	o.statsMu.Unlock()
+	
+	o.addSourceStat(SourceStat{
+		Name:     src.Name(),
+		Type:     string(src.Type()),
+		Found:    len(result.Subdomains),
+		Duration: result.Duration,
+	})
+	
+	o.logger.Info("Source completed",
+		zap.String("source", src.Name()),
+		zap.Int("subdomains_found", len(result.Subdomains)),
+		zap.Duration("duration", result.Duration),
+	)
+}
+
+// enumerate runs a non-streaming source, giving up on it once ctx is done
+// even if the source itself doesn't notice. Such a source's goroutine lives
+// on until it returns, so sources must still honor ctx.
+func (o *Orchestrator) enumerate(ctx context.Context, src sources.Source, scan *types.ScanContext) (*types.SourceResult, error) {
+	type outcome struct {
+		result *types.SourceResult
+		err    error
 	}
+	done := make(chan outcome, 1)
 	
-	// Wait for all sources to complete
 	go func() {
-		wg.Wait()
-		close(resultsChan)
+		defer pool.Recover(func(p *pool.PanicError) {
+			done <- outcome{err: p}
+		})
+		result, err := sources.Run(ctx, src, scan)
+		done <- outcome{result, err}
 	}()
 	
-	// Process results as they arrive
-	for result := range resultsChan {
-		o.processSourceResult(result)
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		// Prefer a result that arrived together with the deadline
+		select {
+		case out := <-done:
+			return out.result, out.err
+		default:
+			return nil, ctx.Err()
+		}
 	}
+}
+
+// consumeStream feeds a streaming source's names into the results store as
+// they arrive, returning a summary result once the source finishes
+func (o *Orchestrator) consumeStream(ctx context.Context, name string, src sources.StreamingSource, scan *types.ScanContext) (*types.SourceResult, error) {
+	startTime := time.Now()
+	names, errs := sources.Stream(ctx, src, scan)
 	
-	return nil
+	result := &types.SourceResult{Source: name}
+	for {
+		var subdomain string
+		var ok bool
+		select {
+		case subdomain, ok = <-names:
+		case <-ctx.Done():
+			// Stop waiting on a source that doesn't notice ctx itself
+			result.Duration = time.Since(startTime)
+			result.Error = ctx.Err()
+			return result, ctx.Err()
+		}
+		if !ok {
+			break
+		}
+		
+		scan.Results.Add(name, []string{subdomain})
+		result.Subdomains = append(result.Subdomains, subdomain)
+		
+		if len(result.Subdomains) == 1 {
+			o.logger.Debug("First streamed result",
+				zap.String("source", name),
+				zap.String("subdomain", subdomain),
+				zap.Duration("after", time.Since(startTime)),
+			)
+		}
+	}
+	
+	result.Duration = time.Since(startTime)
+	
+	if err := <-errs; err != nil {
+		result.Error = err
+		return result, err
+	}
+	
+	return result, nil
 }
 
 // processSourceResult processes results from a single source
 func (o *Orchestrator) processSourceResult(result *types.SourceResult) {
 	o.resultsMu.Lock()
-	defer o.resultsMu.Unlock()
+	
+	// New names are validated right away in a streamed scan
+	var fresh []*types.Subdomain
+	
+	// Per-name tracing is only worth its cost at debug level
+	trace := o.trace.Core().Enabled(zap.DebugLevel)
 	
 	for _, subdomain := range result.Subdomains {
 		if existing, exists := o.results[subdomain]; exists {
-			// Update existing subdomain
-			existing.Sources = append(existing.Sources, result.Source)
+			// Update existing subdomain; a source reporting the same name
+			// twice must not inflate its multiplicity in scoring
+			outcome := "duplicate"
+			if !hasSource(existing, result.Source) {
+				existing.Sources = append(existing.Sources, result.Source)
+				o.raw[result.Source] = append(o.raw[result.Source], subdomain)
+				outcome = "merged"
+			}
 			existing.LastSeen = time.Now()
+			
+			if trace {
+				o.traceSubdomain(subdomain, result.Source, outcome)
+			}
 		} else {
+			o.raw[result.Source] = append(o.raw[result.Source], subdomain)
+			
 			// Create new subdomain entry
 			o.results[subdomain] = &types.Subdomain{
 				Domain:    subdomain,
@@ -201,16 +963,61 @@ func (o *Orchestrator) processSourceResult(result *types.SourceResult) {
 				Validated: false,
 				Metadata:  make(map[string]interface{}),
 			}
+			fresh = append(fresh, o.results[subdomain])
+			
+			if trace {
+				o.traceSubdomain(subdomain, result.Source, "new")
+			}
+		}
+		
+		if meta := result.Metadata[subdomain]; len(meta) > 0 {
+			sub := o.results[subdomain]
+			if sub.Metadata == nil {
+				sub.Metadata = make(map[string]interface{})
+			}
+			for key, value := range meta {
+				sub.Metadata[key] = value
+			}
 		}
 	}
 	
+	total := len(o.results)
+	o.resultsMu.Unlock()
+	
 	o.statsMu.Lock()
-	o.stats.TotalSubdomains = len(o.results)
+	o.stats.TotalSubdomains = total
 	o.statsMu.Unlock()
+	
+	// Queued after unlocking: the validating workers need resultsMu
+	if o.live != nil && len(fresh) > 0 {
+		o.live.enqueue(fresh)
+	}
+}
+
+// traceSubdomain logs one reported name at debug level: new to the scan,
+// merged into an existing entry, or a duplicate from the same source. The
+// caller holds resultsMu.
+func (o *Orchestrator) traceSubdomain(subdomain, source, outcome string) {
+	o.trace.Debug("Subdomain reported",
+		zap.String("subdomain", subdomain),
+		zap.String("source", source),
+		zap.String("outcome", outcome),
+		zap.Int("total", len(o.results)),
+	)
+}
+
+// hasSource reports whether a subdomain was already attributed to a source
+func hasSource(sub *types.Subdomain, source string) bool {
+	for _, s := range sub.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
 }
 
 // validateDNS validates all discovered subdomains via DNS
-func (o *Orchestrator) validateDNS(ctx context.Context) error {
+func (o *Orchestrator) validateDNS(ctx context.Context, scan *types.ScanContext) error {
 	o.resultsMu.RLock()
 	domains := make([]string, 0, len(o.results))
 	for domain := range o.results {
@@ -218,47 +1025,254 @@ func (o *Orchestrator) validateDNS(ctx context.Context) error {
 	}
 	o.resultsMu.RUnlock()
 	
+	// Validated names count as attempted so later candidate generation skips them
+	for _, domain := range domains {
+		scan.Attempted.Claim(domain)
+	}
+	
 	o.logger.Info("Validating subdomains via DNS",
 		zap.Int("count", len(domains)),
 	)
 	
 	// Batch resolution
-	resolved := o.dnsEngine.ResolveBatch(ctx, domains, o.config.DNSWorkers)
+	resolved := o.dnsEngine.ResolveBatchRecords(ctx, domains, scan.Budget.DNSWorkers, o.config.DNS.QueryTypes.Validation)
 	
 	// Update results
 	o.resultsMu.Lock()
-	defer o.resultsMu.Unlock()
 	
-	for domain, ips := range resolved {
+	for domain, records := range resolved {
 		if sub, exists := o.results[domain]; exists {
-			sub.Validated = true
-			sub.IP = ips
+			sub.DNSRecords = records
 			
-			// Create DNS records
-			sub.DNSRecords = &types.DNSRecords{
-				A: ips,
+			ips := dns.RecordIPs(records)
+			if len(ips) == 0 {
+				// Query types may already include MX/NS/TXT
+				if kind := recordOnlyKind(records); kind != "" && o.config.Validation.RecordOnly {
+					markRecordOnly(sub, kind)
+					o.statsMu.Lock()
+					o.stats.ValidatedSubdomains++
+					o.statsMu.Unlock()
+				}
+				continue
 			}
 			
+			sub.Validated = true
+			sub.IP = ips
+			o.tagIPClasses(sub)
+			
 			o.statsMu.Lock()
 			o.stats.ValidatedSubdomains++
 			o.statsMu.Unlock()
 		}
 	}
 	
-	// Mark unresolved as failed
+	var unresolved []string
 	for domain, sub := range o.results {
 		if !sub.Validated {
+			unresolved = append(unresolved, domain)
+		}
+	}
+	o.resultsMu.Unlock()
+	
+	// Mail gateways and delegated zones exist without an address
+	recordOnly := o.resolveRecordOnly(ctx, scan, unresolved)
+	
+	// Mark unresolved as failed
+	o.statsMu.Lock()
+	o.stats.FailedValidations += len(unresolved) - recordOnly
+	o.statsMu.Unlock()
+	
+	return nil
+}
+
+// validatePipelined runs DNS -> HTTP -> TLS for each host in a single
+// worker, so resolved hosts are probed immediately instead of waiting for
+// the whole batch to resolve. Each check honors its validation toggle.
+func (o *Orchestrator) validatePipelined(ctx context.Context, scan *types.ScanContext) {
+	subdomains := scan.Results.Snapshot()
+	
+	// Names a streamed scan validated live are done
+	if o.live != nil {
+		remaining := subdomains[:0]
+		for _, sub := range subdomains {
+			if !o.live.wasChecked(sub.Domain) {
+				remaining = append(remaining, sub)
+			}
+		}
+		subdomains = remaining
+	}
+	
+	workers := scan.Budget.MaxThreads
+	if workers <= 0 {
+		workers = 1
+	}
+	
+	o.logger.Info("Validating subdomains via pipeline",
+		zap.Int("count", len(subdomains)),
+		zap.Int("workers", workers),
+	)
+	
+	err := pool.Run(ctx, subdomains, workers, func(ctx context.Context, sub *types.Subdomain) {
+		o.validateHost(ctx, scan, sub)
+	})
+	if err != nil {
+		o.recordPanics(PhaseValidation, "pipeline", err)
+	}
+}
+
+// validateHost runs the enabled checks for a single host, stopping as soon
+// as one rules it out
+func (o *Orchestrator) validateHost(ctx context.Context, scan *types.ScanContext, sub *types.Subdomain) {
+	if o.config.Validation.DNSValidation {
+		scan.Attempted.Claim(sub.Domain)
+		
+		records, err := o.dnsEngine.ResolveRecords(ctx, sub.Domain, o.config.DNS.QueryTypes.Validation)
+		ips := dns.RecordIPs(records)
+		
+		o.resultsMu.Lock()
+		if err == nil {
+			sub.DNSRecords = records
+		}
+		if len(ips) > 0 {
+			sub.Validated = true
+			sub.IP = ips
+			o.tagIPClasses(sub)
+		} else if kind := recordOnlyKind(records); kind != "" && o.config.Validation.RecordOnly {
+			markRecordOnly(sub, kind)
+		}
+		validated := sub.Validated
+		o.resultsMu.Unlock()
+		
+		o.statsMu.Lock()
+		if validated {
+			o.stats.ValidatedSubdomains++
+		}
+		o.statsMu.Unlock()
+		
+		// Mail gateways and delegated zones exist without an address;
+		// resolveRecordOnly counts the ones it validates
+		if !validated && !errors.Is(err, dns.ErrNXDomain) && ctx.Err() == nil {
+			validated = o.resolveRecordOnly(ctx, scan, []string{sub.Domain}) > 0
+		}
+		
+		if !validated {
 			o.statsMu.Lock()
 			o.stats.FailedValidations++
 			o.statsMu.Unlock()
 		}
+		
+		// Nothing to probe without an address
+		if len(ips) == 0 {
+			return
+		}
 	}
 	
-	return nil
+	// Wildcard hits are filtered afterwards; don't spend probes on them
+	if onWildcard(scan, sub) {
+		return
+	}
+	
+	var httpInfo *types.HTTPInfo
+	var tlsInfo *types.TLSInfo
+	
+	if o.config.Validation.HTTPValidation {
+		if cached, cachedTLS := o.cachedHTTP(ctx, sub.Domain); cached != nil {
+			o.resultsMu.Lock()
+			applyCachedHTTP(sub, cached, cachedTLS)
+			o.resultsMu.Unlock()
+			tlsInfo = cachedTLS
+		} else {
+			httpInfo, tlsInfo = o.httpProber.ProbeWithTLS(ctx, sub.Domain)
+		}
+	}
+	
+	// A TLS handshake is only needed when HTTPS probing didn't provide a cert
+	if o.config.Validation.TLSValidation && tlsInfo == nil {
+		tlsInfo = o.tlsProber.Probe(ctx, sub.Domain)
+	}
+	
+	o.resultsMu.Lock()
+	prober.Apply(sub, httpInfo, tlsInfo)
+	o.resultsMu.Unlock()
+}
+
+// probeHTTP probes all hosts over HTTP, reusing fresh stored results
+func (o *Orchestrator) probeHTTP(ctx context.Context, scan *types.ScanContext) {
+	var pending []*types.Subdomain
+	reused := 0
+	
+	for _, sub := range scan.Results.Snapshot() {
+		if !sub.Validated {
+			continue
+		}
+		if cached, cachedTLS := o.cachedHTTP(ctx, sub.Domain); cached != nil {
+			o.resultsMu.Lock()
+			applyCachedHTTP(sub, cached, cachedTLS)
+			o.resultsMu.Unlock()
+			reused++
+			continue
+		}
+		pending = append(pending, sub)
+	}
+	
+	if reused > 0 {
+		o.logger.Info("Reusing recent HTTP results",
+			zap.Int("reused", reused),
+			zap.Int("to_probe", len(pending)),
+			zap.Int("recheck_ttl_seconds", o.config.HTTP.RecheckTTL),
+		)
+	}
+	
+	if err := o.httpProber.ProbeBatch(ctx, pending); err != nil {
+		o.recordPanics(PhaseValidation, "http", err)
+	}
+}
+
+// cachedHTTP returns a stored HTTP result within http.recheck_ttl and its
+// certificate, if any
+func (o *Orchestrator) cachedHTTP(ctx context.Context, domain string) (*types.HTTPInfo, *types.TLSInfo) {
+	if o.httpCache == nil || o.config.HTTP.RecheckTTL <= 0 {
+		return nil, nil
+	}
+	
+	info, tlsInfo, err := o.httpCache.GetRecentProbe(ctx, domain, time.Duration(o.config.HTTP.RecheckTTL)*time.Second)
+	if err != nil {
+		o.logger.Debug("HTTP cache lookup failed",
+			zap.String("domain", domain),
+			zap.Error(err),
+		)
+		return nil, nil
+	}
+	
+	return info, tlsInfo
+}
+
+// applyCachedHTTP stores a reused HTTP result and certificate on a
+// subdomain as a fresh probe would. The stored result is complete (URL,
+// body hash, headers, redirect chain), so the crawl, screenshots, header
+// discovery, dedup and parked filtering treat it like a new one.
+func applyCachedHTTP(sub *types.Subdomain, info *types.HTTPInfo, tlsInfo *types.TLSInfo) {
+	prober.Apply(sub, info, tlsInfo)
+	if sub.Metadata == nil {
+		sub.Metadata = make(map[string]interface{})
+	}
+	sub.Metadata["http_cached"] = true
+}
+
+// tagIPClasses marks subdomains resolving to private/reserved addresses,
+// which often reveal internal hosts or split-horizon DNS leaking out
+func (o *Orchestrator) tagIPClasses(sub *types.Subdomain) {
+	if ipclass.Tag(sub) {
+		o.logger.Info("Subdomain resolves to internal address",
+			zap.String("domain", sub.Domain),
+			zap.Strings("ips", sub.IP),
+			zap.String("severity", "low"),
+		)
+	}
 }
 
 // filterWildcardResults removes wildcard matches
-func (o *Orchestrator) filterWildcardResults(ctx context.Context, domain string, wildcardInfo *types.WildcardInfo) {
+func (o *Orchestrator) filterWildcardResults(ctx context.Context, scan *types.ScanContext) {
 	o.resultsMu.Lock()
 	defer o.resultsMu.Unlock()
 	
@@ -270,16 +1284,12 @@ func (o *Orchestrator) filterWildcardResults(ctx context.Context, domain string,
 			continue
 		}
 		
-		// Check if IPs match wildcard patterns
+		// Check if IPs match the patterns of the wildcard covering the name
+		wildcard := scan.WildcardFor(subdomain)
 		isWildcard := false
 		for _, ip := range sub.IP {
-			for _, pattern := range wildcardInfo.Patterns {
-				if ip == pattern {
-					isWildcard = true
-					break
-				}
-			}
-			if isWildcard {
+			if wildcard.Matches(ip) {
+				isWildcard = true
 				break
 			}
 		}
@@ -298,63 +1308,130 @@ func (o *Orchestrator) filterWildcardResults(ctx context.Context, domain string,
 	)
 }
 
-// calculateConfidence assigns confidence scores based on multiple factors
-func (o *Orchestrator) calculateConfidence() {
+// calculateConfidence scores every host with the configured scorer
+func (o *Orchestrator) calculateConfidence(ctx context.Context, scan *types.ScanContext) {
 	o.resultsMu.Lock()
 	defer o.resultsMu.Unlock()
 	
 	for _, sub := range o.results {
-		score := 0
-		
-		// Multiple sources increase confidence
-		score += len(sub.Sources) * 10
-		
-		// DNS validation adds confidence
-		if sub.Validated {
-			score += 30
-		}
-		
-		// HTTP validation adds more confidence
-		if sub.HTTP != nil {
-			score += 20
-		}
-		
-		// TLS validation adds confidence
-		if sub.TLS != nil && sub.TLS.Valid {
-			score += 10
+		sub.Confidence = o.score(ctx, scan, sub)
+	}
+}
+
+// score tags hosts sitting on the apex's own IPs, which the scorer counts
+// against them, and rates the host. The caller holds resultsMu.
+func (o *Orchestrator) score(ctx context.Context, scan *types.ScanContext, sub *types.Subdomain) int {
+	if o.sameAsApex(scan, sub) {
+		if sub.Metadata == nil {
+			sub.Metadata = make(map[string]interface{})
 		}
-		
-		// Cap at 100
-		if score > 100 {
-			score = 100
+		sub.Metadata[scorer.SameAsApexKey] = true
+	}
+	
+	return o.scorer.Score(ctx, sub)
+}
+
+// scoreDistribution records the confidence histogram of all scored hosts,
+// before min_confidence is applied, so the threshold can be tuned from it
+func (o *Orchestrator) scoreDistribution(scan *types.ScanContext) {
+	var scores []int
+	for _, sub := range scan.Results.Snapshot() {
+		if scan.InScope(sub.Domain) {
+			scores = append(scores, sub.Confidence)
 		}
-		
-		sub.Confidence = score
 	}
+	
+	histogram := scorer.NewHistogram(scores)
+	
+	o.statsMu.Lock()
+	o.stats.ConfidenceHistogram = histogram
+	o.statsMu.Unlock()
+	
+	o.logger.Info("Confidence distribution",
+		zap.Ints("buckets", histogram.Buckets[:]),
+		zap.Int("suggested_min_confidence", histogram.SuggestedThreshold),
+		zap.Int("configured_min_confidence", o.config.Validation.MinConfidence),
+	)
+}
+
+// snapshot returns the current results as a slice
+func (o *Orchestrator) snapshot() []*types.Subdomain {
+	o.resultsMu.RLock()
+	defer o.resultsMu.RUnlock()
+	
+	subdomains := make([]*types.Subdomain, 0, len(o.results))
+	for _, sub := range o.results {
+		subdomains = append(subdomains, sub)
+	}
+	
+	return subdomains
 }
 
 // getFinalResults returns filtered results based on configuration
-func (o *Orchestrator) getFinalResults() []*types.Subdomain {
+func (o *Orchestrator) getFinalResults(scan *types.ScanContext) []*types.Subdomain {
 	o.resultsMu.RLock()
 	defer o.resultsMu.RUnlock()
 	
 	var results []*types.Subdomain
 	
 	for _, sub := range o.results {
-		// Apply confidence threshold
-		if sub.Confidence >= o.config.Validation.MinConfidence {
-			results = append(results, sub)
+		// Sources may report stray names; only the target's tree is kept
+		if !scan.InScope(sub.Domain) {
+			continue
+		}
+		
+		// Apply confidence threshold, or keep and tag the host when the
+		// user wants the unfiltered picture
+		if status := resultStatus(sub, o.config.Validation.MinConfidence); status != "" {
+			if !o.config.Validation.KeepUnvalidated {
+				continue
+			}
+			if sub.Metadata == nil {
+				sub.Metadata = make(map[string]interface{})
+			}
+			sub.Metadata["status"] = status
+		}
+		
+		// Parking pages are noise unless the user wants them
+		if o.config.Validation.ExcludeParked && sub.Metadata["parked"] == true {
+			continue
 		}
+		
+		// Apply private IP filter (include, exclude, only)
+		switch o.config.Validation.PrivateIPs {
+		case "exclude":
+			if ipclass.IsPrivate(sub) {
+				continue
+			}
+		case "only":
+			if !ipclass.IsPrivate(sub) {
+				continue
+			}
+		}
+		
+		results = append(results, sub)
 	}
 	
 	return results
 }
 
-// addError adds an error to statistics
-func (o *Orchestrator) addError(err error) {
+// resultStatus explains why a host falls below the reporting threshold,
+// or returns "" if it passes
+func resultStatus(sub *types.Subdomain, minConfidence int) string {
+	if sub.Confidence >= minConfidence {
+		return ""
+	}
+	if !sub.Validated {
+		return "unresolved"
+	}
+	return "below_threshold"
+}
+
+// addSourceStat records a source outcome in statistics
+func (o *Orchestrator) addSourceStat(stat SourceStat) {
 	o.statsMu.Lock()
 	defer o.statsMu.Unlock()
-	o.stats.Errors = append(o.stats.Errors, err)
+	o.stats.Sources = append(o.stats.Sources, stat)
 }
 
 // logStatistics logs final scan statistics
@@ -368,13 +1445,71 @@ func (o *Orchestrator) logStatistics() {
 		zap.Int("subdomains_total", o.stats.TotalSubdomains),
 		zap.Int("subdomains_validated", o.stats.ValidatedSubdomains),
 		zap.Int("validation_failures", o.stats.FailedValidations),
+		zap.Int("candidates_attempted", o.stats.AttemptedCandidates),
+		zap.String("found_ratio", o.foundRatio()),
 		zap.Int("errors", len(o.stats.Errors)),
 	)
+	
+	// Flaky resolvers slow every phase; name them
+	for _, stats := range o.dnsEngine.ResolverStats() {
+		if stats.Failures == 0 {
+			continue
+		}
+		o.logger.Info("Resolver failures",
+			zap.String("resolver", stats.Resolver),
+			zap.Int("queries", stats.Queries),
+			zap.Int("failures", stats.Failures),
+			zap.Int("ejections", stats.Ejections),
+		)
+	}
+}
+
+// Baseline returns the apex records captured before enumeration
+func (o *Orchestrator) Baseline() *types.DNSRecords {
+	return o.baseline
+}
+
+// Registration returns the apex registration data, or nil if unavailable
+func (o *Orchestrator) Registration() *types.Registration {
+	return o.registration
+}
+
+// Leads returns related organizations/domains found in certificates
+func (o *Orchestrator) Leads() *pivot.Leads {
+	return o.leads
+}
+
+// CloudAssets returns the storage buckets the scan's hosts reference, with
+// their access probed
+func (o *Orchestrator) CloudAssets() []cloud.CloudAsset {
+	return o.cloudAssets
+}
+
+// foundRatio describes how many attempted candidates turned into results
+func (o *Orchestrator) foundRatio() string {
+	if o.stats.AttemptedCandidates == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f%%", float64(o.stats.ValidatedSubdomains)/float64(o.stats.AttemptedCandidates)*100)
 }
 
 // GetStatistics returns current statistics
 func (o *Orchestrator) GetStatistics() Statistics {
 	o.statsMu.Lock()
-	defer o.statsMu.Unlock()
-	return *o.stats
+	stats := *o.stats
+	o.statsMu.Unlock()
+	
+	for _, src := range o.registry.GetAll() {
+		reporter, ok := src.(sources.ProgressReporter)
+		if !ok {
+			continue
+		}
+		if progress := reporter.Progress(); progress.Total > 0 {
+			if stats.Progress == nil {
+				stats.Progress = make(map[string]sources.Progress)
+			}
+			stats.Progress[src.Name()] = progress
+		}
+	}
+	return stats
 }
\ No newline at end of file
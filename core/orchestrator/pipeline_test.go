@@ -0,0 +1,104 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	mdns "github.com/miekg/dns"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// benchmarkHosts is how many names each validation run covers
+const benchmarkHosts = 200
+
+// BenchmarkPipelinedVsPhased validates the same names per host in one pass
+// and in separate DNS and HTTP phases. A local DNS server stands in for the
+// resolvers and a cache for the web servers, so only the scheduling differs.
+func BenchmarkPipelinedVsPhased(b *testing.B) {
+	server := dnstest.NewServer(b)
+	names := make([]string, benchmarkHosts)
+	for i := range names {
+		names[i] = fmt.Sprintf("host%d.example.com", i)
+		// Every other name is missing, as most brute-forced ones are
+		if i%2 == 0 {
+			server.Add(names[i], mdns.TypeA, fmt.Sprintf("192.0.2.%d", i%250+1))
+		}
+	}
+
+	modes := []struct {
+		name     string
+		validate func(o *Orchestrator, ctx context.Context, scan *types.ScanContext)
+	}{
+		{"pipelined", func(o *Orchestrator, ctx context.Context, scan *types.ScanContext) {
+			o.validatePipelined(ctx, scan)
+		}},
+		{"phased", func(o *Orchestrator, ctx context.Context, scan *types.ScanContext) {
+			o.validateDNS(ctx, scan)
+			o.probeHTTP(ctx, scan)
+		}},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			ctx := context.Background()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				o := NewOrchestrator(testConfig(b, validationYAML(server)), zap.NewNop())
+				o.SetHTTPCache(&fakeHTTPCache{info: &types.HTTPInfo{StatusCode: 200}})
+				scan := o.newScanContext("example.com")
+				scan.Results.Add("bench", names)
+				b.StartTimer()
+
+				mode.validate(o, ctx, scan)
+			}
+		})
+	}
+}
+
+func TestPipelinedAndPhasedAgree(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.Add("www.example.com", mdns.TypeA, "192.0.2.1")
+	server.Add("api.example.com", mdns.TypeA, "192.0.2.2")
+	names := []string{"www.example.com", "api.example.com", "gone.example.com"}
+
+	validated := func(pipelined bool) map[string]int {
+		o := newTestOrchestrator(t, validationYAML(server))
+		o.SetHTTPCache(&fakeHTTPCache{info: &types.HTTPInfo{StatusCode: 200}})
+		scan := o.newScanContext("example.com")
+		scan.Results.Add("test", names)
+
+		if pipelined {
+			o.validatePipelined(context.Background(), scan)
+		} else {
+			o.validateDNS(context.Background(), scan)
+			o.probeHTTP(context.Background(), scan)
+		}
+
+		statuses := make(map[string]int)
+		for name, sub := range o.results {
+			if sub.Validated && sub.HTTP != nil {
+				statuses[name] = sub.HTTP.StatusCode
+			}
+		}
+		return statuses
+	}
+
+	pipelined, phased := validated(true), validated(false)
+	if len(pipelined) != 2 || len(phased) != 2 {
+		t.Fatalf("pipelined %v, phased %v, want www and api probed in both", pipelined, phased)
+	}
+	for name, status := range phased {
+		if pipelined[name] != status {
+			t.Errorf("%s: pipelined status %d, phased %d", name, pipelined[name], status)
+		}
+	}
+}
+
+// validationYAML enables DNS and (cached) HTTP validation against server
+func validationYAML(server *dnstest.Server) string {
+	return "validation:\n  dns_validation: true\n  http_validation: true\n  tls_validation: false\n" +
+		"http:\n  recheck_ttl: 3600\n" + dnsYAML(server, "")
+}
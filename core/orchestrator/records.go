@@ -0,0 +1,156 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// recordOnlyTypes are looked up for hosts without addresses: mail
+// gateways, delegated zones and SPF targets exist without an A record
+var recordOnlyTypes = []string{"MX", "NS", "TXT"}
+
+// Record-only host kinds, stored in Metadata["record_only"]
+const (
+	RecordOnlyMail = "mail" // MX records, or an SPF policy
+	RecordOnlyNS   = "ns"   // a delegated zone
+	RecordOnlyTXT  = "txt"  // other TXT records only
+)
+
+// recordOnlyKind classifies a host that has records but no addresses
+func recordOnlyKind(records *types.DNSRecords) string {
+	if records == nil {
+		return ""
+	}
+
+	switch {
+	case len(records.MX) > 0:
+		return RecordOnlyMail
+	case len(records.NS) > 0:
+		return RecordOnlyNS
+	}
+
+	for _, txt := range records.TXT {
+		if strings.HasPrefix(strings.ToLower(txt), "v=spf1") {
+			return RecordOnlyMail
+		}
+	}
+	if len(records.TXT) > 0 {
+		return RecordOnlyTXT
+	}
+	return ""
+}
+
+// markRecordOnly validates a host that resolves MX/NS/TXT but not A/AAAA.
+// The caller holds resultsMu.
+func markRecordOnly(sub *types.Subdomain, kind string) {
+	sub.Validated = true
+	if sub.Metadata == nil {
+		sub.Metadata = make(map[string]interface{})
+	}
+	sub.Metadata["record_only"] = kind
+}
+
+// mergeRecords adds answers to a host's records for the types it has none
+// of yet
+func mergeRecords(sub *types.Subdomain, records *types.DNSRecords) {
+	if sub.DNSRecords == nil {
+		sub.DNSRecords = &types.DNSRecords{}
+	}
+	fill := func(dst *[]string, src []string) {
+		if len(*dst) == 0 {
+			*dst = src
+		}
+	}
+	fill(&sub.DNSRecords.A, records.A)
+	fill(&sub.DNSRecords.AAAA, records.AAAA)
+	fill(&sub.DNSRecords.CNAME, records.CNAME)
+	fill(&sub.DNSRecords.MX, records.MX)
+	fill(&sub.DNSRecords.NS, records.NS)
+	fill(&sub.DNSRecords.TXT, records.TXT)
+}
+
+// enrichRecords looks up the dns.query_types.enrichment record types for
+// validated hosts, skipping types validation already queried
+func (o *Orchestrator) enrichRecords(ctx context.Context, scan *types.ScanContext) {
+	queried := make(map[string]bool)
+	for _, qtype := range o.config.DNS.QueryTypes.Validation {
+		queried[strings.ToUpper(qtype)] = true
+	}
+	var qtypes []string
+	for _, qtype := range o.config.DNS.QueryTypes.Enrichment {
+		qtype = strings.ToUpper(qtype)
+		if !queried[qtype] {
+			queried[qtype] = true
+			qtypes = append(qtypes, qtype)
+		}
+	}
+	if len(qtypes) == 0 {
+		return
+	}
+
+	var domains []string
+	o.resultsMu.RLock()
+	for domain, sub := range o.results {
+		if sub.Validated && scan.InScope(domain) && !onWildcard(scan, sub) {
+			domains = append(domains, domain)
+		}
+	}
+	o.resultsMu.RUnlock()
+
+	o.logger.Info("Collecting additional DNS records",
+		zap.Strings("types", qtypes),
+		zap.Int("count", len(domains)),
+	)
+
+	resolved := o.dnsEngine.ResolveBatchRecords(ctx, domains, scan.Budget.DNSWorkers, qtypes)
+
+	o.resultsMu.Lock()
+	for domain, records := range resolved {
+		if sub, exists := o.results[domain]; exists && records != nil {
+			mergeRecords(sub, records)
+		}
+	}
+	o.resultsMu.Unlock()
+}
+
+// resolveRecordOnly looks up MX/NS/TXT for hosts that did not resolve to
+// an address and validates those that have them. It returns how many were
+// validated.
+func (o *Orchestrator) resolveRecordOnly(ctx context.Context, scan *types.ScanContext, domains []string) int {
+	if !o.config.Validation.RecordOnly || len(domains) == 0 {
+		return 0
+	}
+
+	resolved := o.dnsEngine.ResolveBatchRecords(ctx, domains, scan.Budget.DNSWorkers, recordOnlyTypes)
+
+	o.resultsMu.Lock()
+	validated := 0
+	for domain, records := range resolved {
+		sub, exists := o.results[domain]
+		if !exists || sub.Validated {
+			continue
+		}
+		kind := recordOnlyKind(records)
+		if kind == "" {
+			continue
+		}
+		mergeRecords(sub, records)
+		markRecordOnly(sub, kind)
+		validated++
+	}
+	o.resultsMu.Unlock()
+
+	if validated > 0 {
+		o.statsMu.Lock()
+		o.stats.ValidatedSubdomains += validated
+		o.statsMu.Unlock()
+
+		o.logger.Info("Validated hosts without addresses by MX/NS/TXT records",
+			zap.Int("count", validated),
+		)
+	}
+	return validated
+}
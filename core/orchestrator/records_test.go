@@ -0,0 +1,113 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mdns "github.com/miekg/dns"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+	"github.com/yourusername/usr/internal/types"
+)
+
+func TestEnrichRecordsCollectsConfiguredTypes(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.Add("example.com", mdns.TypeMX, "10 mx.example.com")
+	server.Add("example.com", mdns.TypeNS, "ns1.example.net")
+	server.Add("example.com", mdns.TypeTXT, "v=spf1 include:_spf.example.net ~all")
+	server.Add("www.example.com", mdns.TypeTXT, "google-site-verification=abc")
+	server.Add("junk.example.com", mdns.TypeTXT, "wildcard")
+	server.Add("stale.example.com", mdns.TypeTXT, "unvalidated")
+
+	o := newTestOrchestrator(t, dnsYAML(server, ""))
+	o.config.DNS.QueryTypes.Enrichment = []string{"mx", "NS", "TXT", "A"}
+	scan := o.newScanContext("example.com")
+	scan.Wildcard = &types.WildcardInfo{IsWildcard: true, Patterns: []string{"192.0.2.99"}}
+
+	addValidated(o, "example.com", "192.0.2.1")
+	addValidated(o, "www.example.com", "192.0.2.2")
+	addValidated(o, "junk.example.com", "192.0.2.99")
+	o.results["stale.example.com"] = &types.Subdomain{Domain: "stale.example.com"}
+
+	o.enrichRecords(context.Background(), scan)
+
+	root := o.results["example.com"].DNSRecords
+	if root == nil {
+		t.Fatal("no records collected for the root")
+	}
+	if strings.Join(root.MX, " ") != "mx.example.com" || strings.Join(root.NS, " ") != "ns1.example.net" {
+		t.Errorf("root MX %v NS %v", root.MX, root.NS)
+	}
+	if len(root.TXT) != 1 || !strings.HasPrefix(root.TXT[0], "v=spf1") {
+		t.Errorf("root TXT = %v, want the SPF policy", root.TXT)
+	}
+	if www := o.results["www.example.com"].DNSRecords; www == nil || len(www.TXT) != 1 {
+		t.Errorf("www records = %+v, want its TXT", www)
+	}
+
+	// Wildcard answers and unvalidated names cost no queries, and A was
+	// already queried during validation
+	for _, name := range []string{"junk.example.com", "stale.example.com"} {
+		if got := server.Queries(name); got != 0 {
+			t.Errorf("%s queried %d times", name, got)
+		}
+	}
+	if root.A != nil {
+		t.Errorf("A queried again during enrichment: %v", root.A)
+	}
+}
+
+func TestEnrichRecordsOffByDefault(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.Add("example.com", mdns.TypeMX, "10 mx.example.com")
+
+	o := newTestOrchestrator(t, dnsYAML(server, ""))
+	addValidated(o, "example.com", "192.0.2.1")
+	o.enrichRecords(context.Background(), o.newScanContext("example.com"))
+
+	if server.TotalQueries() != 0 {
+		t.Errorf("%d enrichment queries without dns.query_types.enrichment", server.TotalQueries())
+	}
+}
+
+func TestResolveRecordOnly(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.Add("mail.example.com", mdns.TypeMX, "10 mx.example.net")
+	server.Add("zone.example.com", mdns.TypeNS, "ns1.example.net")
+	server.Add("bounce.example.com", mdns.TypeTXT, "v=spf1 -all")
+	server.Add("_dmarc.example.com", mdns.TypeTXT, "v=DMARC1; p=none")
+	server.Add("empty.example.com", mdns.TypeCNAME, "elsewhere.example.net")
+
+	o := newTestOrchestrator(t, dnsYAML(server, ""))
+	scan := o.newScanContext("example.com")
+
+	names := []string{"mail.example.com", "zone.example.com", "bounce.example.com", "_dmarc.example.com", "empty.example.com", "gone.example.com"}
+	scan.Results.Add("test", names)
+
+	if got := o.resolveRecordOnly(context.Background(), scan, names); got != 4 {
+		t.Errorf("validated %d hosts, want 4", got)
+	}
+
+	want := map[string]string{
+		"mail.example.com":   RecordOnlyMail,
+		"zone.example.com":   RecordOnlyNS,
+		"bounce.example.com": RecordOnlyMail,
+		"_dmarc.example.com": RecordOnlyTXT,
+	}
+	for _, name := range names {
+		sub := o.results[name]
+		kind, ok := want[name]
+		if !ok {
+			if sub.Validated {
+				t.Errorf("%s validated without MX, NS or TXT records", name)
+			}
+			continue
+		}
+		if !sub.Validated || sub.Metadata["record_only"] != kind {
+			t.Errorf("%s validated %v as %v, want %q", name, sub.Validated, sub.Metadata["record_only"], kind)
+		}
+	}
+	if mx := o.results["mail.example.com"].DNSRecords; mx == nil || strings.Join(mx.MX, " ") != "mx.example.net" {
+		t.Errorf("mail records = %+v, want the MX kept", mx)
+	}
+}
@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/modules/web/prober"
+	"go.uber.org/zap"
+)
+
+// ReplayStore reads what an earlier scan stored: its target, the raw names
+// each source reported and the validated results
+type ReplayStore interface {
+	GetScanDomain(ctx context.Context, scanID int64) (string, error)
+	GetSourceResults(ctx context.Context, scanID int64) (map[string][]string, error)
+	GetScanResults(ctx context.Context, scanID int64) ([]*types.Subdomain, error)
+}
+
+// RawResults returns the names each source reported, as they arrived and
+// before validation or filtering. Storing them lets a scan be replayed.
+func (o *Orchestrator) RawResults() map[string][]string {
+	o.resultsMu.RLock()
+	defer o.resultsMu.RUnlock()
+
+	raw := make(map[string][]string, len(o.raw))
+	for source, subdomains := range o.raw {
+		raw[source] = append([]string(nil), subdomains...)
+	}
+	return raw
+}
+
+// Replay re-runs the pipeline after enumeration (tagging, scoring and
+// deduplication) over the raw source results of a stored scan, so the
+// intelligence layers can be tuned without querying sources again. With
+// validate the names are resolved and probed afresh; without it the
+// scan's stored DNS/HTTP/TLS results are reused and nothing touches the
+// network.
+func (o *Orchestrator) Replay(ctx context.Context, store ReplayStore, scanID int64, validate bool) ([]*types.Subdomain, error) {
+	domain, err := store.GetScanDomain(ctx, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find scan: %w", err)
+	}
+	if domain == "" {
+		return nil, fmt.Errorf("no scan with ID %d", scanID)
+	}
+
+	raw, err := store.GetSourceResults(ctx, scanID)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("scan %d has no stored source results to replay", scanID)
+	}
+
+	o.logger.Info("Replaying stored source results",
+		zap.Int64("scan_id", scanID),
+		zap.String("domain", domain),
+		zap.Int("sources", len(raw)),
+		zap.Bool("validate", validate),
+	)
+
+	scan := o.newScanContext(domain)
+
+	if err := o.loadKnown(); err != nil {
+		return nil, err
+	}
+
+	// Wildcard filtering and apex comparisons need the live baseline
+	if validate {
+		o.captureBaseline(ctx, scan)
+		o.detectWildcard(ctx, scan)
+	}
+
+	names := make([]string, 0, len(raw))
+	for source := range raw {
+		names = append(names, source)
+	}
+	sort.Strings(names)
+
+	for _, source := range names {
+		o.processSourceResult(&types.SourceResult{
+			Source:     source,
+			Subdomains: raw[source],
+		})
+		o.addSourceStat(SourceStat{
+			Name:  source,
+			Found: len(raw[source]),
+		})
+	}
+
+	o.statsMu.Lock()
+	o.stats.TotalSources = len(raw)
+	o.stats.CompletedSources = len(raw)
+	o.statsMu.Unlock()
+
+	if !validate {
+		stored, err := store.GetScanResults(ctx, scanID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load stored results: %w", err)
+		}
+		o.applyStoredChecks(stored)
+	}
+
+	return o.analyze(ctx, scan, validate)
+}
+
+// applyStoredChecks copies the DNS, HTTP and TLS observations of stored
+// results onto the replayed names; scores and tags are left to be
+// recomputed
+func (o *Orchestrator) applyStoredChecks(stored []*types.Subdomain) {
+	o.resultsMu.Lock()
+	defer o.resultsMu.Unlock()
+
+	validated := 0
+	for _, prev := range stored {
+		sub, exists := o.results[prev.Domain]
+		if !exists {
+			continue
+		}
+
+		sub.Validated = prev.Validated
+		sub.IP = prev.IP
+		sub.DNSRecords = prev.DNSRecords
+		sub.Findings = prev.Findings
+		if kind, ok := prev.Metadata["record_only"]; ok {
+			sub.Metadata["record_only"] = kind
+		}
+		if len(sub.IP) > 0 {
+			o.tagIPClasses(sub)
+		}
+		prober.Apply(sub, prev.HTTP, prev.TLS)
+
+		if sub.Validated {
+			validated++
+		}
+	}
+
+	o.statsMu.Lock()
+	o.stats.ValidatedSubdomains = validated
+	o.statsMu.Unlock()
+}
@@ -0,0 +1,159 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// replayStore serves one stored scan, or fails where told to
+type replayStore struct {
+	domain  string
+	raw     map[string][]string
+	results []*types.Subdomain
+
+	domainErr  error
+	resultsErr error
+}
+
+func (s *replayStore) GetScanDomain(ctx context.Context, scanID int64) (string, error) {
+	if scanID != 7 {
+		return "", s.domainErr
+	}
+	return s.domain, s.domainErr
+}
+
+func (s *replayStore) GetSourceResults(ctx context.Context, scanID int64) (map[string][]string, error) {
+	return s.raw, nil
+}
+
+func (s *replayStore) GetScanResults(ctx context.Context, scanID int64) ([]*types.Subdomain, error) {
+	return s.results, s.resultsErr
+}
+
+func TestReplayErrors(t *testing.T) {
+	raw := map[string][]string{"crtsh": {"www.example.com"}}
+
+	tests := []struct {
+		name   string
+		store  *replayStore
+		scanID int64
+		want   string
+	}{
+		{name: "unknown scan", store: &replayStore{domain: "example.com", raw: raw}, scanID: 8, want: "no scan with ID 8"},
+		{name: "lookup failure", store: &replayStore{domainErr: errors.New("disk I/O error")}, scanID: 7, want: "failed to find scan: disk I/O error"},
+		{name: "nothing to replay", store: &replayStore{domain: "example.com"}, scanID: 7, want: "scan 7 has no stored source results"},
+		{
+			name:   "stored results unreadable",
+			store:  &replayStore{domain: "example.com", raw: raw, resultsErr: errors.New("corrupt row")},
+			scanID: 7,
+			want:   "failed to load stored results: corrupt row",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t, "")
+			_, err := o.Replay(context.Background(), tt.store, tt.scanID, false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayReusesStoredChecks(t *testing.T) {
+	store := &replayStore{
+		domain: "example.com",
+		raw: map[string][]string{
+			"crtsh":      {"www.example.com", "api.example.com"},
+			"bruteforce": {"api.example.com", "ghost.example.com"},
+		},
+		results: []*types.Subdomain{
+			{
+				Domain:    "www.example.com",
+				IP:        []string{"192.0.2.10"},
+				Validated: true,
+				HTTP:      &types.HTTPInfo{StatusCode: 200, Title: "Example"},
+				// Scores and tags are recomputed, not copied
+				Confidence: 1,
+				Tags:       []string{"stale:tag"},
+			},
+			{
+				Domain:    "api.example.com",
+				IP:        []string{"10.0.0.5"},
+				Validated: true,
+			},
+			{Domain: "elsewhere.example.com", Validated: true},
+		},
+	}
+
+	o := newTestOrchestrator(t, "validation:\n  keep_unvalidated: true\n")
+	results, err := o.Replay(context.Background(), store, 7, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]*types.Subdomain)
+	for _, sub := range results {
+		byName[sub.Domain] = sub
+	}
+	if _, ok := byName["elsewhere.example.com"]; ok {
+		t.Error("stored result no source reported was replayed")
+	}
+
+	www := byName["www.example.com"]
+	if www == nil || !www.Validated || www.HTTP == nil || www.HTTP.Title != "Example" {
+		t.Fatalf("www = %+v, want the stored validation and HTTP result", www)
+	}
+	if www.Confidence == 1 {
+		t.Error("stored confidence copied instead of rescored")
+	}
+	for _, tag := range www.Tags {
+		if tag == "stale:tag" {
+			t.Error("stored tags copied instead of reclassified")
+		}
+	}
+
+	api := byName["api.example.com"]
+	if api == nil {
+		t.Fatal("api.example.com missing")
+	}
+	sources := append([]string(nil), api.Sources...)
+	sort.Strings(sources)
+	if !reflect.DeepEqual(sources, []string{"bruteforce", "crtsh"}) {
+		t.Errorf("api sources = %v, want both sources", sources)
+	}
+	if private, _ := api.Metadata["private_ip"].(bool); !private {
+		t.Error("stored private address not reclassified")
+	}
+
+	if ghost := byName["ghost.example.com"]; ghost == nil || ghost.Validated {
+		t.Errorf("ghost = %+v, want it kept unvalidated", ghost)
+	}
+
+	stats := o.GetStatistics()
+	if stats.ValidatedSubdomains != 2 || stats.TotalSources != 2 || stats.CompletedSources != 2 {
+		t.Errorf("stats: %d validated, %d/%d sources; want 2, 2/2",
+			stats.ValidatedSubdomains, stats.CompletedSources, stats.TotalSources)
+	}
+}
+
+func TestRawResultsIsACopy(t *testing.T) {
+	o := newTestOrchestrator(t, "")
+	o.processSourceResult(&types.SourceResult{Source: "crtsh", Subdomains: []string{"www.example.com"}})
+
+	raw := o.RawResults()
+	raw["crtsh"][0] = "changed.example.com"
+	raw["other"] = []string{"x.example.com"}
+
+	again := o.RawResults()
+	if !reflect.DeepEqual(again, map[string][]string{"crtsh": {"www.example.com"}}) {
+		t.Errorf("RawResults = %v after changing an earlier copy", again)
+	}
+}
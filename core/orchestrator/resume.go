@@ -0,0 +1,101 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// SourceLog records which sources finished during a scan and what they
+// reported, so an interrupted scan can be resumed.
+//
+// A source counts as completed only once it returned without error while
+// the scan was still running; for a streaming source that means its stream
+// ended cleanly. A source cut off mid-stream is not completed and runs
+// again in full on resume: the names it streamed before the interruption
+// are not kept, and the brute force continues from its own wordlist
+// checkpoint instead.
+type SourceLog interface {
+	CompleteSource(ctx context.Context, scanID int64, source string, subdomains []string) error
+	GetCompletedSources(ctx context.Context, scanID int64) ([]string, error)
+	GetSourceResults(ctx context.Context, scanID int64) (map[string][]string, error)
+}
+
+// SetSourceLog records each source's completion under scanID. With resume
+// set, sources the log already shows as completed for scanID are skipped
+// and their stored names are loaded back into the results instead.
+func (o *Orchestrator) SetSourceLog(log SourceLog, scanID int64, resume bool) {
+	o.sourceLog = log
+	o.scanID = scanID
+	o.resume = resume
+}
+
+// recordSourceCompletion stores that a source finished; failing to do so
+// only means a resumed scan runs the source again
+func (o *Orchestrator) recordSourceCompletion(ctx context.Context, source string, subdomains []string) {
+	if o.sourceLog == nil {
+		return
+	}
+	if err := o.sourceLog.CompleteSource(ctx, o.scanID, source, subdomains); err != nil {
+		o.logger.Warn("Failed to record source completion",
+			zap.String("source", source),
+			zap.Error(err),
+		)
+	}
+}
+
+// skipCompleted drops the sources an interrupted run of the scan already
+// completed, loading what they found, and returns the sources left to run
+func (o *Orchestrator) skipCompleted(ctx context.Context, scan *types.ScanContext, enabled []sources.Source) []sources.Source {
+	completed, err := o.sourceLog.GetCompletedSources(ctx, o.scanID)
+	if err != nil {
+		o.logger.Warn("Failed to read completed sources, running all", zap.Error(err))
+		return enabled
+	}
+	if len(completed) == 0 {
+		return enabled
+	}
+
+	stored, err := o.sourceLog.GetSourceResults(ctx, o.scanID)
+	if err != nil {
+		o.logger.Warn("Failed to load stored source results, running all", zap.Error(err))
+		return enabled
+	}
+
+	done := make(map[string]bool, len(completed))
+	for _, name := range completed {
+		done[name] = true
+	}
+
+	var remaining []sources.Source
+	for _, src := range enabled {
+		if !done[src.Name()] {
+			remaining = append(remaining, src)
+			continue
+		}
+
+		found := stored[src.Name()]
+		scan.Results.Add(src.Name(), found)
+
+		o.statsMu.Lock()
+		o.stats.CompletedSources++
+		o.statsMu.Unlock()
+
+		o.addSourceStat(SourceStat{
+			Name:    src.Name(),
+			Type:    string(src.Type()),
+			Found:   len(found),
+			Resumed: true,
+		})
+	}
+
+	o.logger.Info("Resuming interrupted scan",
+		zap.Int64("scan_id", o.scanID),
+		zap.Int("completed_sources", len(enabled)-len(remaining)),
+		zap.Int("remaining_sources", len(remaining)),
+	)
+
+	return remaining
+}
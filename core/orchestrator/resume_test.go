@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// memorySourceLog is a SourceLog over maps
+type memorySourceLog struct {
+	mu        sync.Mutex
+	completed []string
+	results   map[string][]string
+}
+
+func (m *memorySourceLog) CompleteSource(ctx context.Context, scanID int64, source string, subdomains []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.completed = append(m.completed, source)
+	m.results[source] = subdomains
+	return nil
+}
+
+func (m *memorySourceLog) GetCompletedSources(ctx context.Context, scanID int64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.completed...), nil
+}
+
+func (m *memorySourceLog) GetSourceResults(ctx context.Context, scanID int64) (map[string][]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := make(map[string][]string, len(m.results))
+	for source, names := range m.results {
+		results[source] = names
+	}
+	return results, nil
+}
+
+func TestResumeSkipsCompletedSources(t *testing.T) {
+	log := &memorySourceLog{
+		completed: []string{"finished"},
+		results:   map[string][]string{"finished": {"old.example.com"}},
+	}
+	finished := &stubSource{name: "finished", names: []string{"never.example.com"}}
+	pending := &stubSource{name: "pending", names: []string{"new.example.com"}}
+
+	o := newTestOrchestrator(t, "")
+	o.RegisterSource(finished)
+	o.RegisterSource(pending)
+	o.SetSourceLog(log, 1, true)
+
+	scan := o.newScanContext("example.com")
+	if err := o.runSources(context.Background(), scan); err != nil {
+		t.Fatalf("runSources: %v", err)
+	}
+
+	if runs := finished.runs.Load(); runs != 0 {
+		t.Errorf("completed source ran %d times on resume", runs)
+	}
+	if runs := pending.runs.Load(); runs != 1 {
+		t.Errorf("pending source ran %d times, want once", runs)
+	}
+
+	// The completed source's names come from the log, not a new run
+	for _, name := range []string{"old.example.com", "new.example.com"} {
+		if _, ok := o.results[name]; !ok {
+			t.Errorf("%s missing from resumed results", name)
+		}
+	}
+	if _, ok := o.results["never.example.com"]; ok {
+		t.Error("completed source was enumerated again")
+	}
+
+	if got := log.completed; len(got) != 2 || got[1] != "pending" {
+		t.Errorf("completed sources = %v, want pending recorded after finished", got)
+	}
+
+	resumed := map[string]bool{}
+	for _, stat := range o.GetStatistics().Sources {
+		resumed[stat.Name] = stat.Resumed
+	}
+	if !resumed["finished"] || resumed["pending"] {
+		t.Errorf("resumed flags = %v, want finished only", resumed)
+	}
+}
+
+func TestWithoutResumeEverySourceRuns(t *testing.T) {
+	log := &memorySourceLog{
+		completed: []string{"finished"},
+		results:   map[string][]string{"finished": {"old.example.com"}},
+	}
+	finished := &stubSource{name: "finished", names: []string{"www.example.com"}}
+
+	o := newTestOrchestrator(t, "")
+	o.RegisterSource(finished)
+	o.SetSourceLog(log, 1, false)
+
+	if err := o.runSources(context.Background(), o.newScanContext("example.com")); err != nil {
+		t.Fatalf("runSources: %v", err)
+	}
+	if runs := finished.runs.Load(); runs != 1 {
+		t.Errorf("source ran %d times without resume, want once", runs)
+	}
+	if _, ok := o.results["old.example.com"]; ok {
+		t.Error("stored names loaded without resume")
+	}
+}
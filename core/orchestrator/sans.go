@@ -0,0 +1,53 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// SANSource attributes hosts found in TLS certificate SANs
+const SANSource = "tls_san"
+
+// discoverFromSANs adds the in-scope names listed in resolved hosts'
+// certificates and validates them. Hosts HTTPS probing left without a
+// certificate get a TLS handshake first. Like header discovery it runs one
+// round: certificates of the hosts it adds are not read.
+func (o *Orchestrator) discoverFromSANs(ctx context.Context, scan *types.ScanContext) {
+	if err := o.tlsProber.ProbeBatch(ctx, scan.Results.Snapshot()); err != nil {
+		o.recordPanics(PhaseValidation, SANSource, err)
+	}
+
+	seen := make(map[string]bool)
+	var found []string
+
+	o.resultsMu.RLock()
+	for _, sub := range o.results {
+		if sub.TLS == nil {
+			continue
+		}
+		for _, san := range sub.TLS.SANs {
+			name := strings.ToLower(strings.TrimPrefix(san, "*."))
+			if seen[name] || !scan.InScope(name) {
+				continue
+			}
+			seen[name] = true
+			if _, exists := o.results[name]; !exists {
+				found = append(found, name)
+			}
+		}
+	}
+	o.resultsMu.RUnlock()
+
+	if len(found) == 0 {
+		return
+	}
+
+	o.logger.Info("Subdomains found in certificate SANs",
+		zap.Int("count", len(found)),
+	)
+
+	o.addDiscovered(ctx, scan, SANSource, found)
+}
@@ -0,0 +1,133 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/usr/intelligence/known"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// confidenceOf scores sub alone with an orchestrator built over yaml
+func confidenceOf(t *testing.T, yaml string, sub *types.Subdomain) int {
+	t.Helper()
+
+	o := newTestOrchestrator(t, yaml)
+	scan := o.newScanContext("example.com")
+	o.results[sub.Domain] = sub
+
+	o.calculateConfidence(context.Background(), scan)
+	return sub.Confidence
+}
+
+func TestSANOnlyHostFollowsTLSSANWeight(t *testing.T) {
+	sanOnly := func() *types.Subdomain {
+		return &types.Subdomain{
+			Domain:    "internal-lb.example.com",
+			Sources:   []string{SANSource},
+			Validated: true,
+			IP:        []string{"192.0.2.10"},
+		}
+	}
+
+	builtin := confidenceOf(t, "", sanOnly())
+	raised := confidenceOf(t, "scoring:\n  source_weights:\n    tls_san: 30\n", sanOnly())
+	dropped := confidenceOf(t, "scoring:\n  source_weights:\n    tls_san: 0\n", sanOnly())
+
+	if !(dropped < builtin && builtin < raised) {
+		t.Errorf("confidence with tls_san weight 0/built-in/30 = %d/%d/%d, want increasing", dropped, builtin, raised)
+	}
+	if diff := builtin - dropped; diff != 12 {
+		t.Errorf("built-in tls_san weight added %d points, want 12", diff)
+	}
+}
+
+func TestRepeatedSourceCountsOnce(t *testing.T) {
+	o := newTestOrchestrator(t, "")
+	scan := o.newScanContext("example.com")
+
+	confidence := func() int {
+		o.calculateConfidence(context.Background(), scan)
+		return o.results["www.example.com"].Confidence
+	}
+
+	// crt.sh lists a name once per certificate
+	o.processSourceResult(&types.SourceResult{Source: "crtsh", Subdomains: []string{"www.example.com", "www.example.com"}})
+	once := confidence()
+
+	o.processSourceResult(&types.SourceResult{Source: "crtsh", Subdomains: []string{"www.example.com"}})
+	if got := o.results["www.example.com"].Sources; len(got) != 1 || got[0] != "crtsh" {
+		t.Fatalf("sources after repeats = %v, want [crtsh]", got)
+	}
+	if again := confidence(); again != once {
+		t.Errorf("confidence moved from %d to %d on a repeated source", once, again)
+	}
+	if raw := o.raw["crtsh"]; len(raw) != 1 {
+		t.Errorf("raw crtsh names = %v, want one entry", raw)
+	}
+
+	// A second source is a real corroboration
+	o.processSourceResult(&types.SourceResult{Source: "certspotter", Subdomains: []string{"www.example.com"}})
+	if got := o.results["www.example.com"].Sources; len(got) != 2 {
+		t.Errorf("sources = %v, want crtsh and certspotter", got)
+	}
+	if corroborated := confidence(); corroborated <= once {
+		t.Errorf("second source left confidence at %d (was %d)", corroborated, once)
+	}
+}
+
+func TestKnownHostGetsKnownWeight(t *testing.T) {
+	knownFile := filepath.Join(t.TempDir(), "known.txt")
+	if err := os.WriteFile(knownFile, []byte("# inventory\nnode1.example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yaml := "validation:\n  known_file: " + knownFile + "\n  known_weight: 15\n"
+
+	host := func(name string) *types.Subdomain {
+		return &types.Subdomain{Domain: name, Sources: []string{"crtsh"}}
+	}
+
+	o := newTestOrchestrator(t, yaml)
+	if err := o.loadKnown(); err != nil {
+		t.Fatalf("loadKnown: %v", err)
+	}
+	scan := o.newScanContext("example.com")
+	listed, unlisted := host("node1.example.com"), host("node2.example.com")
+	o.results[listed.Domain] = listed
+	o.results[unlisted.Domain] = unlisted
+
+	o.applyKnown(scan)
+	o.calculateConfidence(context.Background(), scan)
+
+	if !known.IsKnown(listed) {
+		t.Errorf("listed host not tagged %s: %v", known.Tag, listed.Tags)
+	}
+	// Same sources, same shape; only the known weight differs
+	if diff := listed.Confidence - unlisted.Confidence; diff != 15 {
+		t.Errorf("known host scored %d, unlisted %d; want 15 points apart", listed.Confidence, unlisted.Confidence)
+	}
+}
+
+func TestRecordOnlyHostScoresAsValidated(t *testing.T) {
+	mailOnly := &types.Subdomain{
+		Domain:     "mx1.example.com",
+		Sources:    []string{"crtsh"},
+		DNSRecords: &types.DNSRecords{MX: []string{"10 mx1.example.com"}},
+	}
+	markRecordOnly(mailOnly, recordOnlyKind(mailOnly.DNSRecords))
+
+	unresolved := &types.Subdomain{
+		Domain:  "mx1.example.com",
+		Sources: []string{"crtsh"},
+	}
+
+	withRecords := confidenceOf(t, "", mailOnly)
+	without := confidenceOf(t, "", unresolved)
+
+	// Record-only hosts can't earn HTTP points; their records stand in
+	if diff := withRecords - without; diff != 25 {
+		t.Errorf("record-only host scored %d, unresolved host %d; want 25 points apart", withRecords, without)
+	}
+}
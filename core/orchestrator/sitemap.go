@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"context"
+	neturl "net/url"
+	"sync"
+
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// SitemapSource attributes hosts found in robots.txt and sitemaps
+const SitemapSource = "sitemap_robots"
+
+// discoverFromSitemaps reads robots.txt and the sitemaps of every host that
+// answered HTTP, storing robots.txt's paths in the host's metadata
+// (robots_endpoints) and adding the in-scope hosts referenced. Like header
+// discovery it runs one round.
+func (o *Orchestrator) discoverFromSitemaps(ctx context.Context, scan *types.ScanContext) {
+	var alive []*types.Subdomain
+	for _, sub := range scan.Results.Snapshot() {
+		if sub.HTTP != nil && sub.HTTP.URL != "" {
+			alive = append(alive, sub)
+		}
+	}
+	if len(alive) == 0 {
+		return
+	}
+
+	var (
+		mu       sync.Mutex
+		found    []string
+		sitemaps int
+	)
+	seen := make(map[string]bool)
+
+	err := pool.Run(ctx, alive, o.config.HTTPWorkers, func(ctx context.Context, sub *types.Subdomain) {
+		probed, err := neturl.Parse(sub.HTTP.URL)
+		if err != nil {
+			return
+		}
+
+		result, err := o.sitemaps.Fetch(ctx, probed.Scheme+"://"+probed.Host, scan.InScope)
+		if err != nil {
+			o.logger.Debug("Sitemap reading stopped", zap.String("domain", sub.Domain), zap.Error(err))
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		sitemaps += result.Sitemaps
+		for _, host := range result.Hosts {
+			if !seen[host] {
+				seen[host] = true
+				found = append(found, host)
+			}
+		}
+		if len(result.Paths) > 0 {
+			if sub.Metadata == nil {
+				sub.Metadata = make(map[string]interface{})
+			}
+			sub.Metadata["robots_endpoints"] = result.Paths
+		}
+	})
+	if err != nil {
+		o.recordPanics(PhaseValidation, SitemapSource, err)
+	}
+
+	o.logger.Info("robots.txt and sitemaps read",
+		zap.Int("hosts", len(alive)),
+		zap.Int("sitemaps", sitemaps),
+	)
+
+	added := o.unknownHosts(found)
+	if len(added) == 0 {
+		return
+	}
+
+	o.logger.Info("Subdomains found in robots.txt and sitemaps",
+		zap.Int("count", len(added)),
+	)
+
+	o.addDiscovered(ctx, scan, SitemapSource, added)
+}
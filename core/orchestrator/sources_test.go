@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// panicSource panics instead of enumerating
+type panicSource struct {
+	name string
+}
+
+func (s *panicSource) Name() string             { return s.name }
+func (s *panicSource) Type() sources.SourceType { return sources.TypePassive }
+func (s *panicSource) IsEnabled() bool          { return true }
+func (s *panicSource) RateLimit() int           { return 0 }
+func (s *panicSource) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	panic("index out of range")
+}
+
+// panicStreamSource panics when its stream is started
+type panicStreamSource struct {
+	panicSource
+}
+
+func (s *panicStreamSource) EnumerateStream(ctx context.Context, domain string) (<-chan string, <-chan error) {
+	panic("nil map")
+}
+
+func TestPanickingSourceIsRecorded(t *testing.T) {
+	healthy := &stubSource{name: "healthy", names: []string{"www.example.com"}}
+
+	o := newTestOrchestrator(t, "")
+	o.RegisterSource(&panicSource{name: "broken"})
+	o.RegisterSource(&panicStreamSource{panicSource{name: "broken_stream"}})
+	o.RegisterSource(healthy)
+
+	if err := o.runSources(context.Background(), o.newScanContext("example.com")); err != nil {
+		t.Fatalf("runSources: %v", err)
+	}
+	if _, ok := o.results["www.example.com"]; !ok {
+		t.Error("healthy source's results lost to a panicking one")
+	}
+
+	errs := o.GetErrors()
+	if len(errs) != 2 {
+		t.Errorf("recorded %d errors, want one per panicking source: %v", len(errs), errs)
+	}
+	recorded := map[string]ScanError{}
+	for _, scanErr := range errs {
+		recorded[scanErr.Source] = scanErr
+	}
+	for _, name := range []string{"broken", "broken_stream"} {
+		scanErr, ok := recorded[name]
+		if !ok {
+			t.Errorf("no error recorded for panicking source %s: %v", name, o.GetErrors())
+			continue
+		}
+		if scanErr.Phase != PhaseSources || scanErr.Kind != ErrorKindPanic {
+			t.Errorf("%s recorded as %s/%s, want %s/%s", name, scanErr.Phase, scanErr.Kind, PhaseSources, ErrorKindPanic)
+		}
+	}
+
+	failed := map[string]string{}
+	for _, stat := range o.GetStatistics().Sources {
+		failed[stat.Name] = stat.Error
+	}
+	if failed["broken"] == "" || failed["broken_stream"] == "" || failed["healthy"] != "" {
+		t.Errorf("source stat errors = %v, want the panicking sources only", failed)
+	}
+}
+
+func TestSourceMetadataSurvivesMerge(t *testing.T) {
+	o := newTestOrchestrator(t, "")
+
+	crtsh := &types.SourceResult{Source: "crtsh", Subdomains: []string{"www.example.com", "api.example.com"}}
+	crtsh.Annotate("www.example.com", "crtsh_issuers", []string{"R3"})
+	crtsh.Annotate("gone.example.com", "crtsh_issuers", []string{"R3"})
+
+	shodan := &types.SourceResult{Source: "shodan", Subdomains: []string{"www.example.com"}}
+	shodan.Annotate("www.example.com", "shodan_ports", []int{80, 443})
+
+	o.processSourceResult(crtsh)
+	o.processSourceResult(shodan)
+
+	www := o.results["www.example.com"]
+	if len(www.Sources) != 2 {
+		t.Errorf("sources = %v, want crtsh and shodan", www.Sources)
+	}
+	if issuers, ok := www.Metadata["crtsh_issuers"].([]string); !ok || len(issuers) != 1 || issuers[0] != "R3" {
+		t.Errorf("crt.sh issuers lost in the merge: %v", www.Metadata)
+	}
+	if ports, ok := www.Metadata["shodan_ports"].([]int); !ok || len(ports) != 2 {
+		t.Errorf("Shodan ports lost in the merge: %v", www.Metadata)
+	}
+	if len(o.results["api.example.com"].Metadata) != 0 {
+		t.Errorf("metadata attached to a name without any: %v", o.results["api.example.com"].Metadata)
+	}
+
+	// Metadata of names the source didn't report adds nothing
+	if _, ok := o.results["gone.example.com"]; ok {
+		t.Error("annotated name outside the result's subdomains added")
+	}
+}
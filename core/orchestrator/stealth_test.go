@@ -0,0 +1,17 @@
+package orchestrator
+
+import "testing"
+
+func TestStealthCapsHTTPWorkers(t *testing.T) {
+	o := newTestOrchestrator(t, "scan_mode: stealth\nhttp_workers: 50\nstealth:\n  max_concurrency: 2\n  jitter_min: 0\n  jitter_max: 0\n")
+
+	if got := o.config.HTTPWorkers; got != 2 {
+		t.Errorf("http_workers = %d, want the stealth cap 2", got)
+	}
+	if got := o.config.Sources.Web.JSWorkers; got > 2 {
+		t.Errorf("js_workers = %d, want at most 2", got)
+	}
+	if got := o.newScanContext("example.com").Budget.HTTPWorkers; got != 2 {
+		t.Errorf("scan budget http workers = %d, want 2", got)
+	}
+}
@@ -0,0 +1,174 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// RunStream runs the reconnaissance workflow like Run, but reports hosts
+// while the scan is still going on. Each new name is validated as soon as
+// a source reports it, and a host that validates is sent right away as a
+// copy marked Provisional: its confidence only reflects the sources and
+// checks seen so far, and later phases may still filter it out. Once the
+// scan completes the final results follow, scored, filtered and
+// deduplicated like Run's and not marked provisional.
+//
+// The results channel must be drained. The error channel receives the
+// scan's error, if any, once the results channel is closed.
+func (o *Orchestrator) RunStream(ctx context.Context, domain string) (<-chan *types.Subdomain, <-chan error) {
+	return o.stream(ctx, domain, true)
+}
+
+// stream runs the workflow, sending provisional hosts on the returned
+// channel when live is set, then the final results
+func (o *Orchestrator) stream(ctx context.Context, domain string, live bool) (<-chan *types.Subdomain, <-chan error) {
+	out := make(chan *types.Subdomain)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+
+		var emit chan<- *types.Subdomain
+		if live {
+			emit = out
+		}
+
+		results, err := o.run(ctx, domain, emit)
+		for _, sub := range results {
+			out <- sub
+		}
+		close(out)
+
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return out, errs
+}
+
+// liveValidator validates names as sources report them during a streamed
+// scan, sending each host that validates to the stream
+type liveValidator struct {
+	queue chan *types.Subdomain
+	wg    sync.WaitGroup
+
+	// mu guards closing the queue against concurrent enqueues
+	mu     sync.RWMutex
+	closed bool
+
+	checkedMu sync.Mutex
+	checked   map[string]bool
+}
+
+// startLive starts workers validating queued names with validateHost and
+// sending provisional copies of the hosts that validate to emit
+func (o *Orchestrator) startLive(ctx context.Context, scan *types.ScanContext, emit chan<- *types.Subdomain) *liveValidator {
+	workers := scan.Budget.MaxThreads
+	if workers <= 0 {
+		workers = 1
+	}
+
+	live := &liveValidator{
+		queue:   make(chan *types.Subdomain, workers),
+		checked: make(map[string]bool),
+	}
+
+	for i := 0; i < workers; i++ {
+		live.wg.Add(1)
+		go func() {
+			defer live.wg.Done()
+			for sub := range live.queue {
+				o.validateLive(ctx, scan, live, sub, emit)
+			}
+		}()
+	}
+
+	return live
+}
+
+// validateLive validates one host and, if it resolved, sends a provisional
+// copy of it
+func (o *Orchestrator) validateLive(ctx context.Context, scan *types.ScanContext, live *liveValidator, sub *types.Subdomain, emit chan<- *types.Subdomain) {
+	live.checkedMu.Lock()
+	live.checked[sub.Domain] = true
+	live.checkedMu.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	o.validateHost(ctx, scan, sub)
+
+	o.resultsMu.Lock()
+	var found *types.Subdomain
+	if sub.Validated && scan.InScope(sub.Domain) && !onWildcard(scan, sub) {
+		found = provisionalCopy(sub)
+		found.Confidence = o.score(ctx, scan, found)
+	}
+	o.resultsMu.Unlock()
+
+	if found != nil {
+		emit <- found
+	}
+}
+
+// enqueue queues new names for validation; names reported after the live
+// phase ended are left to the regular validation
+func (l *liveValidator) enqueue(subs []*types.Subdomain) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		return
+	}
+	for _, sub := range subs {
+		l.queue <- sub
+	}
+}
+
+// stop ends the live phase, waiting for queued names to be validated
+func (l *liveValidator) stop() {
+	l.mu.Lock()
+	l.closed = true
+	close(l.queue)
+	l.mu.Unlock()
+
+	l.wg.Wait()
+}
+
+// wasChecked reports whether a name was already validated live, so the
+// regular validation can skip it
+func (l *liveValidator) wasChecked(domain string) bool {
+	l.checkedMu.Lock()
+	defer l.checkedMu.Unlock()
+	return l.checked[domain]
+}
+
+// onWildcard reports whether a host resolves to a wildcard address
+func onWildcard(scan *types.ScanContext, sub *types.Subdomain) bool {
+	for _, ip := range sub.IP {
+		if scan.IsWildcardIP(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// provisionalCopy copies a host for the stream, so later phases updating
+// the original don't race with the receiver. The caller holds resultsMu.
+func provisionalCopy(sub *types.Subdomain) *types.Subdomain {
+	copied := *sub
+	copied.Provisional = true
+	copied.IP = append([]string(nil), sub.IP...)
+	copied.Sources = append([]string(nil), sub.Sources...)
+	copied.Tags = append([]string(nil), sub.Tags...)
+	copied.Findings = append([]types.Finding(nil), sub.Findings...)
+	copied.Metadata = make(map[string]interface{}, len(sub.Metadata))
+	for key, value := range sub.Metadata {
+		copied.Metadata[key] = value
+	}
+	return &copied
+}
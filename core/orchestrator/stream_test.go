@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// gatedSource reports its names only once release is closed
+type gatedSource struct {
+	stubSource
+	release chan struct{}
+}
+
+func (s *gatedSource) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	select {
+	case <-s.release:
+		return s.stubSource.Enumerate(ctx, domain)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestRunStreamSendsHostsBeforeScanEnds(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.Add("www.example.com", mdns.TypeA, "192.0.2.1")
+	server.Add("api.example.com", mdns.TypeA, "192.0.2.2")
+
+	o := newTestOrchestrator(t, validationYAML(server))
+	o.SetHTTPCache(&fakeHTTPCache{info: &types.HTTPInfo{StatusCode: 200}})
+	o.config.Validation.SANDiscovery = false
+	o.config.Validation.MinConfidence = 0
+
+	gated := &gatedSource{
+		stubSource: stubSource{name: "gated", names: []string{"api.example.com"}},
+		release:    make(chan struct{}),
+	}
+	o.RegisterSource(&stubSource{name: "fast", names: []string{"www.example.com", "gone.example.com"}})
+	o.RegisterSource(gated)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	stream, errs := o.RunStream(ctx, "example.com")
+
+	// The fast source's host arrives while the gated one still holds the
+	// scan in enumeration
+	first := <-stream
+	if first.Domain != "www.example.com" || !first.Provisional {
+		t.Fatalf("first result = %s (provisional %v), want provisional www.example.com", first.Domain, first.Provisional)
+	}
+	select {
+	case err := <-errs:
+		t.Fatalf("scan ended (%v) before every source reported", err)
+	default:
+	}
+	close(gated.release)
+
+	provisional := map[string]bool{first.Domain: true}
+	final := make(map[string]*types.Subdomain)
+	for sub := range stream {
+		if sub.Provisional {
+			if len(final) > 0 {
+				t.Errorf("provisional %s after final results", sub.Domain)
+			}
+			provisional[sub.Domain] = true
+			continue
+		}
+		final[sub.Domain] = sub
+	}
+
+	// The error channel is closed once the results channel is
+	if err, ok := <-errs; err != nil || ok {
+		t.Errorf("errs = %v (open %v), want closed without an error", err, ok)
+	}
+
+	if !provisional["api.example.com"] {
+		t.Errorf("provisional hosts = %v, want api.example.com streamed too", provisional)
+	}
+	if provisional["gone.example.com"] {
+		t.Error("unresolved host streamed")
+	}
+	for _, name := range []string{"www.example.com", "api.example.com"} {
+		sub, ok := final[name]
+		if !ok {
+			t.Errorf("%s missing from the final results", name)
+			continue
+		}
+		if !sub.Validated || sub.Confidence == 0 {
+			t.Errorf("final %s not validated and scored: %+v", name, sub)
+		}
+	}
+}
+
+func TestRunStreamClosesOnCancel(t *testing.T) {
+	o := newTestOrchestrator(t, "")
+	gated := &gatedSource{stubSource: stubSource{name: "gated"}, release: make(chan struct{})}
+	defer close(gated.release)
+	o.RegisterSource(gated)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, errs := o.RunStream(ctx, "example.com")
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		for range stream {
+		}
+		<-errs
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("stream not closed after the scan was canceled")
+	}
+}
@@ -0,0 +1,111 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// hangingSource ignores ctx and blocks until release is closed
+type hangingSource struct {
+	name    string
+	release chan struct{}
+}
+
+func (s *hangingSource) Name() string             { return s.name }
+func (s *hangingSource) Type() sources.SourceType { return sources.TypePassive }
+func (s *hangingSource) IsEnabled() bool          { return true }
+func (s *hangingSource) RateLimit() int           { return 0 }
+func (s *hangingSource) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	<-s.release
+	return &types.SourceResult{Source: s.name}, nil
+}
+
+// newHangingSource returns a source released when the test ends
+func newHangingSource(t *testing.T, name string) *hangingSource {
+	s := &hangingSource{name: name, release: make(chan struct{})}
+	t.Cleanup(func() { close(s.release) })
+	return s
+}
+
+// slowSource returns names after delay, asking for hint to run
+type slowSource struct {
+	stubSource
+	delay time.Duration
+	hint  time.Duration
+}
+
+func (s *slowSource) SourceTimeout() time.Duration { return s.hint }
+func (s *slowSource) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	select {
+	case <-time.After(s.delay):
+		return s.stubSource.Enumerate(ctx, domain)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestHangingSourceIsCutOff(t *testing.T) {
+	o := newTestOrchestrator(t, "sources:\n  timeout: 1\n")
+	o.RegisterSource(newHangingSource(t, "stuck"))
+	o.RegisterSource(&stubSource{name: "healthy", names: []string{"www.example.com"}})
+
+	start := time.Now()
+	if err := o.runSources(context.Background(), o.newScanContext("example.com")); err != nil {
+		t.Fatalf("runSources: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("scan waited %v on a source with a 1s timeout", elapsed)
+	}
+
+	if _, ok := o.results["www.example.com"]; !ok {
+		t.Error("healthy source's results lost")
+	}
+
+	errs := o.GetErrors()
+	if len(errs) != 1 || errs[0].Source != "stuck" || !errors.Is(errs[0].Err, ErrSourceTimeout) {
+		t.Fatalf("errors = %v, want a timeout of the stuck source", errs)
+	}
+	if errs[0].Kind != ErrorKindTimeout {
+		t.Errorf("recorded as %s, want %s", errs[0].Kind, ErrorKindTimeout)
+	}
+
+	stats := map[string]SourceStat{}
+	for _, stat := range o.GetStatistics().Sources {
+		stats[stat.Name] = stat
+	}
+	if stat := stats["stuck"]; !strings.Contains(stat.Error, "timed out") {
+		t.Errorf("stuck source stat = %+v, want the timeout recorded", stat)
+	}
+	if stat := stats["healthy"]; stat.Error != "" || stat.Found != 1 {
+		t.Errorf("healthy source stat = %+v", stat)
+	}
+}
+
+func TestSourceTimeoutHintOutlastsConfig(t *testing.T) {
+	// A source with its own, longer deadline (CommonCrawl) is given that
+	// long rather than being cut off at sources.timeout
+	slow := &slowSource{
+		stubSource: stubSource{name: "slow", names: []string{"api.example.com"}},
+		delay:      1500 * time.Millisecond,
+		hint:       10 * time.Second,
+	}
+
+	o := newTestOrchestrator(t, "sources:\n  timeout: 1\n")
+	o.RegisterSource(slow)
+
+	if err := o.runSources(context.Background(), o.newScanContext("example.com")); err != nil {
+		t.Fatalf("runSources: %v", err)
+	}
+	if _, ok := o.results["api.example.com"]; !ok {
+		t.Error("source with a longer hint cut off at sources.timeout")
+	}
+	if errs := o.GetErrors(); len(errs) != 0 {
+		t.Errorf("errors = %v, want none", errs)
+	}
+}
@@ -0,0 +1,159 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// WildcardStore keeps wildcard detections between scans
+type WildcardStore interface {
+	GetWildcardInfo(ctx context.Context, domain, fingerprint string, within time.Duration) (*types.WildcardInfo, error)
+	SaveWildcardInfo(ctx context.Context, domain, fingerprint string, info *types.WildcardInfo) error
+}
+
+// SetWildcardStore lets wildcard detection reuse stored results newer than
+// dns.wildcard_ttl instead of probing the target again
+func (o *Orchestrator) SetWildcardStore(store WildcardStore) {
+	o.wildcardStore = store
+}
+
+// reuseWildcard applies a stored detection still valid for this scan and
+// reports whether it did
+func (o *Orchestrator) reuseWildcard(ctx context.Context, scan *types.ScanContext) bool {
+	if o.wildcardStore == nil || o.config.DNS.WildcardTTL <= 0 {
+		return false
+	}
+
+	ttl := time.Duration(o.config.DNS.WildcardTTL) * time.Hour
+	info, err := o.wildcardStore.GetWildcardInfo(ctx, scan.Root, o.wildcardFingerprint(scan), ttl)
+	if err != nil {
+		o.logger.Debug("Stored wildcard lookup failed", zap.Error(err))
+		return false
+	}
+	if info == nil {
+		return false
+	}
+
+	o.dnsEngine.SetWildcardInfo(scan.Root, info)
+	scan.Wildcard = info
+
+	o.logger.Info("Reusing stored wildcard detection",
+		zap.Bool("wildcard", info.IsWildcard),
+		zap.Strings("patterns", info.Patterns),
+		zap.Strings("patterns_v6", info.PatternsV6),
+		zap.Time("detected_at", info.DetectedAt),
+	)
+	return true
+}
+
+// saveWildcard stores the scan's wildcard detection for later scans
+func (o *Orchestrator) saveWildcard(ctx context.Context, scan *types.ScanContext) {
+	if o.wildcardStore == nil || o.config.DNS.WildcardTTL <= 0 || scan.Wildcard == nil {
+		return
+	}
+
+	if err := o.wildcardStore.SaveWildcardInfo(ctx, scan.Root, o.wildcardFingerprint(scan), scan.Wildcard); err != nil {
+		o.logger.Warn("Failed to store wildcard detection", zap.Error(err))
+	}
+}
+
+// wildcardFingerprint identifies what a wildcard detection depends on: the
+// resolvers asked and the apex's own records. A stored detection made
+// under a different fingerprint is not reused.
+func (o *Orchestrator) wildcardFingerprint(scan *types.ScanContext) string {
+	resolvers := append([]string(nil), o.dnsEngine.Resolvers()...)
+	sort.Strings(resolvers)
+
+	var apex []string
+	if scan.Baseline != nil {
+		for _, group := range [][]string{scan.Baseline.A, scan.Baseline.AAAA, scan.Baseline.NS} {
+			apex = append(apex, group...)
+		}
+	}
+	sort.Strings(apex)
+
+	sum := sha256.Sum256([]byte(strings.Join(resolvers, ",") + "|" + strings.Join(apex, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+// detectNestedWildcards tests the parents of resolved names below the scan
+// root (dev.example.com for foo.dev.example.com) for wildcards of their
+// own, which the root's detection cannot see. Parents with the most
+// resolved children are tested first, up to dns.wildcard_parents.
+func (o *Orchestrator) detectNestedWildcards(ctx context.Context, scan *types.ScanContext) {
+	limit := o.config.DNS.WildcardParents
+	if limit <= 0 {
+		return
+	}
+
+	children := make(map[string]int)
+	o.resultsMu.RLock()
+	for name, sub := range o.results {
+		if !sub.Validated {
+			continue
+		}
+		_, parent, found := strings.Cut(name, ".")
+		if !found || parent == scan.Root || !strings.HasSuffix(parent, "."+scan.Root) {
+			continue
+		}
+		children[parent]++
+	}
+	o.resultsMu.RUnlock()
+
+	parents := make([]string, 0, len(children))
+	for parent := range children {
+		parents = append(parents, parent)
+	}
+	sort.Slice(parents, func(i, j int) bool {
+		if children[parents[i]] != children[parents[j]] {
+			return children[parents[i]] > children[parents[j]]
+		}
+		return parents[i] < parents[j]
+	})
+	if len(parents) > limit {
+		parents = parents[:limit]
+	}
+
+	var mu sync.Mutex
+	detected := make(map[string]*types.WildcardInfo, len(parents))
+	err := pool.Run(ctx, parents, scan.Budget.MaxThreads, func(ctx context.Context, parent string) {
+		info, err := o.dnsEngine.IsWildcard(ctx, parent)
+		if err != nil {
+			o.logger.Debug("Nested wildcard detection failed",
+				zap.String("parent", parent),
+				zap.Error(err),
+			)
+			return
+		}
+		mu.Lock()
+		detected[parent] = info
+		mu.Unlock()
+	})
+	if err != nil {
+		o.recordPanics(PhaseWildcard, "nested", err)
+	}
+
+	scan.NestedWildcards = detected
+
+	var wildcards []string
+	for parent, info := range detected {
+		if info.IsWildcard {
+			wildcards = append(wildcards, parent)
+		}
+	}
+	if len(wildcards) > 0 {
+		sort.Strings(wildcards)
+		o.logger.Warn("Nested wildcard DNS detected - filtering will be applied",
+			zap.Strings("parents", wildcards),
+		)
+	}
+}
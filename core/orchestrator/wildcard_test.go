@@ -0,0 +1,216 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// memoryWildcardStore records the domains detections are stored under
+type memoryWildcardStore struct {
+	saved map[string]*types.WildcardInfo
+}
+
+func (m *memoryWildcardStore) GetWildcardInfo(ctx context.Context, domain, fingerprint string, within time.Duration) (*types.WildcardInfo, error) {
+	return m.saved[domain], nil
+}
+
+func (m *memoryWildcardStore) SaveWildcardInfo(ctx context.Context, domain, fingerprint string, info *types.WildcardInfo) error {
+	m.saved[domain] = info
+	return nil
+}
+
+// addValidated adds a resolved host to the results
+func addValidated(o *Orchestrator, name string, ips ...string) {
+	o.results[name] = &types.Subdomain{Domain: name, IP: ips, Validated: true}
+}
+
+func TestRegistrableModeProbesRootForWildcard(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.AddWildcard("example.com", mdns.TypeA, "192.0.2.1")
+	server.Add("www.example.com", mdns.TypeA, "192.0.2.10")
+
+	o := newTestOrchestrator(t, "scope:\n  key_mode: registrable\n"+dnsYAML(server, "  wildcard_ttl: 24\n"))
+	store := &memoryWildcardStore{saved: make(map[string]*types.WildcardInfo)}
+	o.SetWildcardStore(store)
+
+	ctx := context.Background()
+	scan := o.newScanContext("dev.example.com")
+	if scan.Root != "example.com" {
+		t.Fatalf("root = %q, want example.com", scan.Root)
+	}
+
+	o.detectWildcard(ctx, scan)
+
+	if scan.Wildcard == nil || !scan.Wildcard.IsWildcard {
+		t.Fatalf("wildcard at the registrable root not detected: %+v", scan.Wildcard)
+	}
+	if got := scan.Wildcard.Patterns; len(got) != 1 || got[0] != "192.0.2.1" {
+		t.Errorf("patterns = %v, want [192.0.2.1]", got)
+	}
+	if _, ok := store.saved["example.com"]; !ok || len(store.saved) != 1 {
+		t.Errorf("detection stored under %v, want the root only", keys(store.saved))
+	}
+
+	// Brute force hits under the root resolve to the wildcard and go
+	addValidated(o, "junk.example.com", "192.0.2.1")
+	addValidated(o, "www.example.com", "192.0.2.10")
+
+	o.detectNestedWildcards(ctx, scan)
+	o.filterWildcardResults(ctx, scan)
+
+	if _, ok := o.results["junk.example.com"]; ok {
+		t.Error("wildcard answer under the root kept")
+	}
+	if _, ok := o.results["www.example.com"]; !ok {
+		t.Error("real host under the root removed")
+	}
+}
+
+func TestRegistrableModeTargetWildcardIsNested(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.AddWildcard("dev.example.com", mdns.TypeA, "192.0.2.2")
+	server.Add("real.dev.example.com", mdns.TypeA, "192.0.2.20")
+	server.Add("www.example.com", mdns.TypeA, "192.0.2.10")
+
+	o := newTestOrchestrator(t, "scope:\n  key_mode: registrable\n"+dnsYAML(server, ""))
+	ctx := context.Background()
+	scan := o.newScanContext("dev.example.com")
+
+	o.detectWildcard(ctx, scan)
+	if scan.Wildcard == nil || scan.Wildcard.IsWildcard {
+		t.Fatalf("root has no wildcard, got %+v", scan.Wildcard)
+	}
+
+	addValidated(o, "a.dev.example.com", "192.0.2.2")
+	addValidated(o, "b.dev.example.com", "192.0.2.2")
+	addValidated(o, "real.dev.example.com", "192.0.2.20")
+	addValidated(o, "www.example.com", "192.0.2.10")
+
+	o.detectNestedWildcards(ctx, scan)
+	if info := scan.NestedWildcards["dev.example.com"]; info == nil || !info.IsWildcard {
+		t.Fatalf("wildcard at the target not detected: %+v", scan.NestedWildcards)
+	}
+
+	o.filterWildcardResults(ctx, scan)
+	for _, name := range []string{"a.dev.example.com", "b.dev.example.com"} {
+		if _, ok := o.results[name]; ok {
+			t.Errorf("%s on the target's wildcard kept", name)
+		}
+	}
+	for _, name := range []string{"real.dev.example.com", "www.example.com"} {
+		if _, ok := o.results[name]; !ok {
+			t.Errorf("%s removed", name)
+		}
+	}
+}
+
+func TestIPv6OnlyWildcard(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.AddWildcard("example.com", mdns.TypeAAAA, "2001:db8::1")
+	server.Add("www.example.com", mdns.TypeAAAA, "2001:db8::10")
+
+	o := newTestOrchestrator(t, dnsYAML(server, ""))
+	ctx := context.Background()
+	scan := o.newScanContext("example.com")
+
+	o.detectWildcard(ctx, scan)
+
+	info := scan.Wildcard
+	if info == nil || !info.IsWildcard {
+		t.Fatalf("AAAA-only wildcard not detected: %+v", info)
+	}
+	if len(info.Patterns) != 0 {
+		t.Errorf("A patterns = %v, want none", info.Patterns)
+	}
+	if got := info.PatternsV6; len(got) != 1 || got[0] != "2001:db8::1" {
+		t.Errorf("AAAA patterns = %v, want [2001:db8::1]", got)
+	}
+
+	addValidated(o, "junk.example.com", "2001:db8::1")
+	addValidated(o, "www.example.com", "2001:db8::10")
+
+	o.filterWildcardResults(ctx, scan)
+
+	if _, ok := o.results["junk.example.com"]; ok {
+		t.Error("AAAA wildcard answer kept")
+	}
+	if _, ok := o.results["www.example.com"]; !ok {
+		t.Error("real IPv6 host removed")
+	}
+}
+
+func TestSingleWildcardTestNeedsAnAnswer(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.Add("www.example.com", mdns.TypeA, "192.0.2.10")
+
+	o := newTestOrchestrator(t, dnsYAML(server, ""))
+	o.config.DNS.WildcardTests = 1
+	scan := o.newScanContext("example.com")
+
+	o.detectWildcard(context.Background(), scan)
+
+	if scan.Wildcard != nil && scan.Wildcard.IsWildcard {
+		t.Errorf("domain without a wildcard flagged after one unanswered test: %+v", scan.Wildcard)
+	}
+}
+
+// keys returns a map's keys, for messages
+func keys(m map[string]*types.WildcardInfo) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestNestedWildcardFiltersByNearestParent(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.AddWildcard("example.com", mdns.TypeA, "192.0.2.1")
+	server.AddWildcard("dev.example.com", mdns.TypeA, "192.0.2.2")
+	server.Add("staging.example.com", mdns.TypeA, "192.0.2.30")
+
+	o := newTestOrchestrator(t, dnsYAML(server, ""))
+	ctx := context.Background()
+	scan := o.newScanContext("example.com")
+
+	o.detectWildcard(ctx, scan)
+	if scan.Wildcard == nil || !scan.Wildcard.IsWildcard {
+		t.Fatalf("root wildcard not detected: %+v", scan.Wildcard)
+	}
+
+	addValidated(o, "a.dev.example.com", "192.0.2.2")
+	addValidated(o, "b.dev.example.com", "192.0.2.2")
+	addValidated(o, "real.dev.example.com", "192.0.2.1")
+	addValidated(o, "junk.example.com", "192.0.2.1")
+	addValidated(o, "staging.example.com", "192.0.2.30")
+	addValidated(o, "api.staging.example.com", "192.0.2.1")
+	addValidated(o, "web.staging.example.com", "192.0.2.31")
+
+	o.detectNestedWildcards(ctx, scan)
+	if info := scan.NestedWildcards["dev.example.com"]; info == nil || !info.IsWildcard {
+		t.Fatalf("wildcard under dev not detected: %+v", scan.NestedWildcards)
+	}
+	if info := scan.NestedWildcards["staging.example.com"]; info == nil || info.IsWildcard {
+		t.Fatalf("staging shadows the root wildcard, got %+v", info)
+	}
+
+	o.filterWildcardResults(ctx, scan)
+
+	// Each name is judged by its nearest parent's patterns only: the root's
+	// answer is a real host under dev and under staging
+	for _, name := range []string{"a.dev.example.com", "b.dev.example.com", "junk.example.com"} {
+		if _, ok := o.results[name]; ok {
+			t.Errorf("%s on its nearest wildcard kept", name)
+		}
+	}
+	for _, name := range []string{"real.dev.example.com", "staging.example.com", "api.staging.example.com", "web.staging.example.com"} {
+		if _, ok := o.results[name]; !ok {
+			t.Errorf("%s removed", name)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package cdn
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/yourusername/usr/internal/iprange"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// Detector identifies CDN and WAF providers sitting in front of subdomains
+type Detector struct {
+	logger    *zap.Logger
+	providers []*provider
+}
+
+// provider describes the fingerprints of a single CDN/WAF vendor
+type provider struct {
+	name string
+
+	// CNAME target suffixes owned by the provider
+	cnames []string
+
+	// Response headers set by the provider, checked in order
+	headers []headerRule
+
+	// Published edge IP ranges
+	ranges iprange.List
+}
+
+// headerRule matches a response header by name and, unless contains is
+// empty, a lowercase substring of its value
+type headerRule struct {
+	name     string
+	contains string
+}
+
+// Detection describes why a subdomain was attributed to a provider
+type Detection struct {
+	Provider string
+	Evidence string // cname, header, ip
+	Detail   string
+}
+
+// NewDetector creates a new CDN/WAF detector
+func NewDetector(logger *zap.Logger) *Detector {
+	return &Detector{
+		logger: logger,
+		providers: []*provider{
+			{
+				name:   "Cloudflare",
+				cnames: []string{"cdn.cloudflare.net", "cloudflare.net", "cloudflare.com"},
+				headers: []headerRule{
+					{"CF-RAY", ""},
+					{"Server", "cloudflare"},
+				},
+				ranges: iprange.MustParse(
+					"173.245.48.0/20", "103.21.244.0/22", "103.22.200.0/22", "103.31.4.0/22",
+					"141.101.64.0/18", "108.162.192.0/18", "190.93.240.0/20", "188.114.96.0/20",
+					"197.234.240.0/22", "198.41.128.0/17", "162.158.0.0/15", "104.16.0.0/13",
+					"104.24.0.0/14", "172.64.0.0/13", "131.0.72.0/22",
+				),
+			},
+			{
+				name: "Akamai",
+				cnames: []string{
+					"akamai.net", "akamaiedge.net", "akamaitechnologies.com",
+					"edgekey.net", "edgesuite.net", "akamaized.net", "akamaihd.net",
+				},
+				headers: []headerRule{
+					{"Server", "akamaighost"},
+					{"X-Akamai-Transformed", ""},
+				},
+				ranges: iprange.MustParse("23.32.0.0/11", "23.192.0.0/11", "2.16.0.0/13", "104.64.0.0/10"),
+			},
+			{
+				name:   "Fastly",
+				cnames: []string{"fastly.net", "fastlylb.net"},
+				headers: []headerRule{
+					{"X-Fastly-Request-ID", ""},
+					{"X-Served-By", "cache-"},
+				},
+				ranges: iprange.MustParse("151.101.0.0/16", "199.232.0.0/16", "146.75.0.0/17"),
+			},
+			{
+				name:   "CloudFront",
+				cnames: []string{"cloudfront.net"},
+				headers: []headerRule{
+					{"X-Amz-Cf-Id", ""},
+					{"X-Amz-Cf-Pop", ""},
+					{"Via", "cloudfront"},
+					{"Server", "cloudfront"},
+				},
+				ranges: iprange.MustParse(
+					"13.32.0.0/15", "13.224.0.0/14", "52.84.0.0/15", "54.182.0.0/16",
+					"54.192.0.0/16", "54.230.0.0/16", "54.239.128.0/18", "99.84.0.0/16",
+					"205.251.192.0/19",
+				),
+			},
+			{
+				name:   "Imperva",
+				cnames: []string{"incapdns.net", "impervadns.net"},
+				headers: []headerRule{
+					{"X-Iinfo", ""},
+					{"X-CDN", "imperva"},
+				},
+				ranges: iprange.MustParse(
+					"45.60.0.0/16", "45.64.64.0/22", "107.154.0.0/16",
+					"192.230.64.0/18", "199.83.128.0/21", "198.143.32.0/19",
+				),
+			},
+			{
+				name:   "Sucuri",
+				cnames: []string{"sucuri.net"},
+				headers: []headerRule{
+					{"X-Sucuri-ID", ""},
+					{"Server", "sucuri"},
+				},
+				ranges: iprange.MustParse("192.124.249.0/24", "185.93.228.0/22"),
+			},
+			{
+				name:   "Azure Front Door",
+				cnames: []string{"azurefd.net", "azureedge.net", "trafficmanager.net"},
+				headers: []headerRule{
+					{"X-Azure-Ref", ""},
+				},
+			},
+			{
+				name:   "Google Cloud CDN",
+				cnames: []string{"googlehosted.com", "ghs.googlehosted.com"},
+				headers: []headerRule{
+					{"Via", "google"},
+				},
+			},
+		},
+	}
+}
+
+// Detect attributes a subdomain to a CDN/WAF provider using collected evidence.
+// CNAMEs are checked first, then response headers, then edge IP ranges.
+func (d *Detector) Detect(sub *types.Subdomain) *Detection {
+	if sub.DNSRecords != nil {
+		for _, cname := range sub.DNSRecords.CNAME {
+			cname = strings.TrimSuffix(strings.ToLower(cname), ".")
+			for _, p := range d.providers {
+				for _, suffix := range p.cnames {
+					if cname == suffix || strings.HasSuffix(cname, "."+suffix) {
+						return &Detection{Provider: p.name, Evidence: "cname", Detail: cname}
+					}
+				}
+			}
+		}
+	}
+
+	if sub.HTTP != nil && len(sub.HTTP.Headers) > 0 {
+		for _, p := range d.providers {
+			for _, rule := range p.headers {
+				value, ok := headerValue(sub.HTTP.Headers, rule.name)
+				if !ok {
+					continue
+				}
+				if rule.contains == "" || strings.Contains(strings.ToLower(value), rule.contains) {
+					return &Detection{Provider: p.name, Evidence: "header", Detail: rule.name}
+				}
+			}
+		}
+	}
+
+	for _, raw := range sub.IP {
+		ip := net.ParseIP(raw)
+		if ip == nil {
+			continue
+		}
+		for _, p := range d.providers {
+			if p.ranges.Contains(ip) {
+				return &Detection{Provider: p.name, Evidence: "ip", Detail: raw}
+			}
+		}
+	}
+
+	return nil
+}
+
+// DetectBatch tags each subdomain with its CDN/WAF provider in metadata and
+// returns the number of subdomains found behind one
+func (d *Detector) DetectBatch(ctx context.Context, subdomains []*types.Subdomain) int {
+	detected := 0
+
+	for _, sub := range subdomains {
+		select {
+		case <-ctx.Done():
+			return detected
+		default:
+		}
+
+		detection := d.Detect(sub)
+		if detection == nil {
+			continue
+		}
+
+		if sub.Metadata == nil {
+			sub.Metadata = make(map[string]interface{})
+		}
+		sub.Metadata["cdn"] = detection.Provider
+		sub.Metadata["cdn_evidence"] = detection.Evidence
+		detected++
+
+		d.logger.Debug("CDN/WAF detected",
+			zap.String("domain", sub.Domain),
+			zap.String("provider", detection.Provider),
+			zap.String("evidence", detection.Evidence),
+			zap.String("detail", detection.Detail),
+		)
+	}
+
+	d.logger.Info("CDN/WAF detection complete",
+		zap.Int("count", len(subdomains)),
+		zap.Int("detected", detected),
+	)
+
+	return detected
+}
+
+// headerValue looks up a header case-insensitively
+func headerValue(headers map[string]string, name string) (string, bool) {
+	if value, ok := headers[name]; ok {
+		return value, true
+	}
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
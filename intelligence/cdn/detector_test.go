@@ -0,0 +1,136 @@
+package cdn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+func TestDetect(t *testing.T) {
+	d := NewDetector(zap.NewNop())
+
+	tests := []struct {
+		name string
+		sub  *types.Subdomain
+		want *Detection // nil = no provider
+	}{
+		{
+			name: "cname suffix",
+			sub:  &types.Subdomain{DNSRecords: &types.DNSRecords{CNAME: []string{"d111111abcdef8.CloudFront.net."}}},
+			want: &Detection{Provider: "CloudFront", Evidence: "cname", Detail: "d111111abcdef8.cloudfront.net"},
+		},
+		{
+			name: "cname equal to the suffix",
+			sub:  &types.Subdomain{DNSRecords: &types.DNSRecords{CNAME: []string{"fastly.net"}}},
+			want: &Detection{Provider: "Fastly", Evidence: "cname", Detail: "fastly.net"},
+		},
+		{
+			name: "cname only matches whole labels",
+			sub:  &types.Subdomain{DNSRecords: &types.DNSRecords{CNAME: []string{"notfastly.net"}}},
+		},
+		{
+			name: "later cname in the chain",
+			sub:  &types.Subdomain{DNSRecords: &types.DNSRecords{CNAME: []string{"www.example.com", "www.example.com.edgekey.net"}}},
+			want: &Detection{Provider: "Akamai", Evidence: "cname", Detail: "www.example.com.edgekey.net"},
+		},
+		{
+			name: "presence header, any case",
+			sub:  &types.Subdomain{HTTP: &types.HTTPInfo{Headers: map[string]string{"cf-ray": "7d1c2b3a4e5f-AMS"}}},
+			want: &Detection{Provider: "Cloudflare", Evidence: "header", Detail: "CF-RAY"},
+		},
+		{
+			name: "header value substring",
+			sub:  &types.Subdomain{HTTP: &types.HTTPInfo{Headers: map[string]string{"Server": "AkamaiGHost"}}},
+			want: &Detection{Provider: "Akamai", Evidence: "header", Detail: "Server"},
+		},
+		{
+			name: "header value not matching",
+			sub:  &types.Subdomain{HTTP: &types.HTTPInfo{Headers: map[string]string{"Server": "nginx", "X-Served-By": "web-01"}}},
+		},
+		{
+			name: "first matching header rule wins",
+			sub: &types.Subdomain{HTTP: &types.HTTPInfo{Headers: map[string]string{
+				"Server": "CloudFront", "Via": "1.1 abc.cloudfront.net (CloudFront)", "X-Amz-Cf-Pop": "FRA56-C1", "X-Amz-Cf-Id": "abc==",
+			}}},
+			want: &Detection{Provider: "CloudFront", Evidence: "header", Detail: "X-Amz-Cf-Id"},
+		},
+		{
+			name: "ipv4 edge range",
+			sub:  &types.Subdomain{IP: []string{"192.0.2.1", "104.16.132.229"}},
+			want: &Detection{Provider: "Cloudflare", Evidence: "ip", Detail: "104.16.132.229"},
+		},
+		{
+			name: "range boundaries",
+			sub:  &types.Subdomain{IP: []string{"151.102.0.0", "151.101.255.255"}},
+			want: &Detection{Provider: "Fastly", Evidence: "ip", Detail: "151.101.255.255"},
+		},
+		{
+			name: "unparseable and unlisted addresses",
+			sub:  &types.Subdomain{IP: []string{"not-an-ip", "2001:db8::1", "8.8.8.8"}},
+		},
+		{
+			name: "cname before header before ip",
+			sub: &types.Subdomain{
+				IP:         []string{"104.16.0.1"},
+				HTTP:       &types.HTTPInfo{Headers: map[string]string{"X-Sucuri-ID": "1"}},
+				DNSRecords: &types.DNSRecords{CNAME: []string{"shop.example.com.incapdns.net"}},
+			},
+			want: &Detection{Provider: "Imperva", Evidence: "cname", Detail: "shop.example.com.incapdns.net"},
+		},
+		{
+			name: "header before ip",
+			sub: &types.Subdomain{
+				IP:   []string{"104.16.0.1"},
+				HTTP: &types.HTTPInfo{Headers: map[string]string{"X-Sucuri-ID": "1"}},
+			},
+			want: &Detection{Provider: "Sucuri", Evidence: "header", Detail: "X-Sucuri-ID"},
+		},
+		{
+			name: "no evidence",
+			sub:  &types.Subdomain{Domain: "bare.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := d.Detect(tt.sub)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("Detect = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderRanges(t *testing.T) {
+	// Every listed range must parse; a dropped one would leave a silent hole
+	want := map[string]int{
+		"Cloudflare": 15, "Akamai": 4, "Fastly": 3, "CloudFront": 9, "Imperva": 6, "Sucuri": 2,
+	}
+
+	for _, p := range NewDetector(zap.NewNop()).providers {
+		if len(p.ranges) != want[p.name] {
+			t.Errorf("%s: %d ranges, want %d", p.name, len(p.ranges), want[p.name])
+		}
+	}
+}
+
+func TestDetectBatch(t *testing.T) {
+	subdomains := []*types.Subdomain{
+		{Domain: "a.example.com", IP: []string{"151.101.1.1"}},
+		{Domain: "b.example.com", IP: []string{"192.0.2.1"}},
+	}
+
+	if n := NewDetector(zap.NewNop()).DetectBatch(context.Background(), subdomains); n != 1 {
+		t.Errorf("detected %d, want 1", n)
+	}
+	if subdomains[0].Metadata["cdn"] != "Fastly" || subdomains[0].Metadata["cdn_evidence"] != "ip" {
+		t.Errorf("metadata = %v", subdomains[0].Metadata)
+	}
+	if subdomains[1].Metadata != nil {
+		t.Errorf("undetected host tagged: %v", subdomains[1].Metadata)
+	}
+}
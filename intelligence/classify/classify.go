@@ -0,0 +1,238 @@
+package classify
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/usr/intelligence/pivot"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// Tag namespaces, the part of a tag before the colon
+const (
+	NamespaceEnvironment = "environment"
+	NamespaceService     = "service"
+	NamespaceInfra       = "infra"
+	NamespaceGeo         = "geo"
+	NamespaceCDN         = "cdn"
+	NamespaceHosting     = "hosting"
+	NamespaceStatus      = "status"
+	NamespaceNetwork     = "network"
+	NamespaceType        = "type"
+)
+
+// labelTags maps host label tokens to the tag they imply. Labels are split
+// on dots and hyphens, so "api-staging.eu" yields three tokens.
+var labelTags = map[string]string{
+	"dev": "environment:dev", "develop": "environment:dev", "development": "environment:dev",
+	"staging": "environment:staging", "stage": "environment:staging", "stg": "environment:staging",
+	"test": "environment:test", "qa": "environment:test", "uat": "environment:test",
+	"preprod": "environment:preprod", "sandbox": "environment:sandbox", "demo": "environment:demo",
+	"prod": "environment:production", "production": "environment:production",
+
+	"mail": "service:mail", "smtp": "service:mail", "pop": "service:mail", "pop3": "service:mail",
+	"imap": "service:mail", "webmail": "service:mail", "mx": "service:mail", "owa": "service:mail",
+	"exchange": "service:mail", "autodiscover": "service:mail",
+	"api": "service:api", "graphql": "service:api", "rest": "service:api",
+	"admin": "service:admin", "dashboard": "service:admin", "portal": "service:admin",
+	"panel": "service:admin", "cpanel": "service:admin",
+	"login": "service:auth", "auth": "service:auth", "sso": "service:auth", "secure": "service:auth",
+	"id": "service:auth", "oauth": "service:auth", "accounts": "service:auth",
+	"www": "service:web", "app": "service:web", "mobile": "service:web", "m": "service:web",
+	"blog": "service:web", "shop": "service:web", "store": "service:web",
+	"cdn": "service:static", "static": "service:static", "assets": "service:static",
+	"img": "service:static", "images": "service:static", "media": "service:static",
+	"ftp": "service:ftp", "sftp": "service:ftp",
+	"git": "service:devtools", "gitlab": "service:devtools", "jenkins": "service:devtools",
+	"ci": "service:devtools", "jira": "service:devtools", "confluence": "service:devtools",
+	"grafana": "service:monitoring", "kibana": "service:monitoring", "prometheus": "service:monitoring",
+	"status": "service:monitoring", "monitor": "service:monitoring",
+
+	"vpn": "infra:vpn", "remote": "infra:vpn", "citrix": "infra:vpn", "rdp": "infra:vpn",
+	"gateway": "infra:vpn",
+	"ns": "infra:dns", "ns1": "infra:dns", "ns2": "infra:dns", "dns": "infra:dns",
+	"db": "infra:database", "mysql": "infra:database", "postgres": "infra:database",
+	"redis": "infra:database", "mongo": "infra:database",
+	"k8s": "infra:kubernetes", "kube": "infra:kubernetes",
+	"lb": "infra:loadbalancer",
+
+	"us": "geo:us", "eu": "geo:eu", "asia": "geo:asia", "uk": "geo:uk", "ca": "geo:ca", "ap": "geo:ap",
+}
+
+// cnameTags maps CNAME target suffixes to the platform or service behind them
+var cnameTags = map[string]string{
+	"mail.protection.outlook.com": "service:mail",
+	"mx.cloudflare.net":           "service:mail",
+	"herokuapp.com":               "hosting:heroku",
+	"herokudns.com":               "hosting:heroku",
+	"github.io":                   "hosting:github-pages",
+	"netlify.app":                 "hosting:netlify",
+	"vercel-dns.com":              "hosting:vercel",
+	"azurewebsites.net":           "hosting:azure",
+	"cloudapp.net":                "hosting:azure",
+	"amazonaws.com":               "hosting:aws",
+	"elasticbeanstalk.com":        "hosting:aws",
+	"appspot.com":                 "hosting:gcp",
+	"run.app":                     "hosting:gcp",
+	"myshopify.com":               "hosting:shopify",
+	"zendesk.com":                 "hosting:zendesk",
+}
+
+// Classifier assigns semantic tags to subdomains
+type Classifier struct {
+	logger *zap.Logger
+}
+
+// NewClassifier creates a new classifier
+func NewClassifier(logger *zap.Logger) *Classifier {
+	return &Classifier{
+		logger: logger,
+	}
+}
+
+// ClassifyBatch tags every subdomain in place
+func (c *Classifier) ClassifyBatch(ctx context.Context, subdomains []*types.Subdomain) {
+	tagged := 0
+	for _, sub := range subdomains {
+		if ctx.Err() != nil {
+			return
+		}
+		sub.Tags = Classify(sub)
+		if len(sub.Tags) > 0 {
+			tagged++
+		}
+	}
+
+	c.logger.Info("Classification complete",
+		zap.Int("total", len(subdomains)),
+		zap.Int("tagged", tagged),
+	)
+}
+
+// Classify returns the sorted tags for a subdomain from its labels and the
+// signals collected so far (CNAMEs, MX records, HTTP, CDN detection)
+func Classify(sub *types.Subdomain) []string {
+	tags := make(map[string]bool)
+
+	for _, tag := range LabelTags(sub.Domain) {
+		tags[tag] = true
+	}
+
+	if sub.DNSRecords != nil {
+		for _, cname := range sub.DNSRecords.CNAME {
+			cname = strings.ToLower(strings.TrimSuffix(cname, "."))
+			for suffix, tag := range cnameTags {
+				if cname == suffix || strings.HasSuffix(cname, "."+suffix) {
+					tags[tag] = true
+				}
+			}
+		}
+		if len(sub.DNSRecords.MX) > 0 {
+			tags["service:mail"] = true
+		}
+	}
+
+	if sub.HTTP != nil {
+		if sub.HTTP.API {
+			tags["service:api"] = true
+		}
+		if sub.HTTP.Parked {
+			tags["status:parked"] = true
+		}
+		if host, ok := sub.Metadata["redirects_to"].(string); ok && host != "" {
+			tags["status:redirect"] = true
+		}
+		if strings.Contains(strings.ToLower(sub.HTTP.Headers["WWW-Authenticate"]), "basic") {
+			tags["service:admin"] = true
+		}
+	}
+
+	if provider, ok := sub.Metadata["cdn"].(string); ok && provider != "" {
+		tags[NamespaceCDN+":"+slug(provider)] = true
+	}
+	if private, ok := sub.Metadata["private_ip"].(bool); ok && private {
+		tags["network:private"] = true
+	}
+	
+	// Hosts validated by MX/NS/TXT records alone
+	if kind, ok := sub.Metadata["record_only"].(string); ok && kind != "" {
+		tags[NamespaceType+":"+kind] = true
+		if kind == "mail" {
+			tags["service:mail"] = true
+		}
+	}
+
+	return sortedTags(tags)
+}
+
+// LabelTags returns the sorted tags implied by the host labels of a domain
+// alone (everything left of the registrable domain)
+func LabelTags(domain string) []string {
+	tags := make(map[string]bool)
+	for _, token := range Tokens(domain) {
+		if tag, ok := labelTags[token]; ok {
+			tags[tag] = true
+		}
+	}
+	return sortedTags(tags)
+}
+
+// Tokens splits the host labels of a domain on dots and hyphens, leaving
+// out the registrable domain, so "api-v2.eu.example.com" gives api, v2, eu
+func Tokens(domain string) []string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	host := domain
+	if apex := pivot.Apex(domain); apex != "" {
+		host = strings.TrimSuffix(strings.TrimSuffix(domain, apex), ".")
+	}
+	if host == "" {
+		return nil
+	}
+
+	var tokens []string
+	for _, label := range strings.Split(host, ".") {
+		tokens = append(tokens, strings.Split(label, "-")...)
+	}
+	return tokens
+}
+
+// Namespace returns the part of a tag before the colon
+func Namespace(tag string) string {
+	namespace, _, _ := strings.Cut(tag, ":")
+	return namespace
+}
+
+// Match reports whether a subdomain carries any of the given tags. A bare
+// namespace ("environment") matches every tag within it.
+func Match(sub *types.Subdomain, filters []string) bool {
+	for _, filter := range filters {
+		filter = strings.ToLower(strings.TrimSpace(filter))
+		for _, tag := range sub.Tags {
+			if tag == filter || (!strings.Contains(filter, ":") && Namespace(tag) == filter) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// slug lowercases a provider name and replaces spaces with hyphens
+func slug(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+}
+
+// sortedTags returns the keys of a tag set in order
+func sortedTags(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
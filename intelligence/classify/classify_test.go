@@ -0,0 +1,194 @@
+package classify
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   []string
+	}{
+		{"api-v2.eu.example.com", []string{"api", "v2", "eu"}},
+		{"WWW.Example.COM.", []string{"www"}},
+		{"mail.example.co.uk", []string{"mail"}},
+		{"example.com", nil},
+		{"a-b-c.example.com", []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		if got := Tokens(tt.domain); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokens(%q) = %q, want %q", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestLabelTags(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   []string
+	}{
+		{"api-staging.eu.example.com", []string{"environment:staging", "geo:eu", "service:api"}},
+		{"dev.example.com", []string{"environment:dev"}},
+		{"prod-db.example.com", []string{"environment:production", "infra:database"}},
+		{"vpn.us.example.com", []string{"geo:us", "infra:vpn"}},
+		{"grafana.example.com", []string{"service:monitoring"}},
+		{"webmail.example.com", []string{"service:mail"}},
+		{"ns1.example.com", []string{"infra:dns"}},
+		{"k8s-lb.example.com", []string{"infra:kubernetes", "infra:loadbalancer"}},
+		// Synonyms collapse to one tag
+		{"qa-uat-test.example.com", []string{"environment:test"}},
+		// Whole tokens only: "developer" is not "dev", "apis" not "api"
+		{"developer.example.com", nil},
+		{"apis.example.com", nil},
+		// The registrable domain itself carries no labels
+		{"staging.com", nil},
+		{"api.example.co.uk", []string{"service:api"}},
+	}
+
+	for _, tt := range tests {
+		if got := LabelTags(tt.domain); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LabelTags(%q) = %q, want %q", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  *types.Subdomain
+		want []string
+	}{
+		{
+			name: "labels only",
+			sub:  &types.Subdomain{Domain: "admin.staging.example.com"},
+			want: []string{"environment:staging", "service:admin"},
+		},
+		{
+			name: "cname platform",
+			sub:  &types.Subdomain{Domain: "docs.example.com", DNSRecords: &types.DNSRecords{CNAME: []string{"example.github.io."}}},
+			want: []string{"hosting:github-pages"},
+		},
+		{
+			name: "cname suffix matches whole labels",
+			sub:  &types.Subdomain{Domain: "x.example.com", DNSRecords: &types.DNSRecords{CNAME: []string{"myherokuapp.com"}}},
+		},
+		{
+			name: "nested cname suffix",
+			sub:  &types.Subdomain{Domain: "files.example.com", DNSRecords: &types.DNSRecords{CNAME: []string{"bucket.s3.AMAZONAWS.com"}}},
+			want: []string{"hosting:aws"},
+		},
+		{
+			name: "mail from mx and cname",
+			sub: &types.Subdomain{Domain: "corp.example.com", DNSRecords: &types.DNSRecords{
+				MX:    []string{"10 corp-example-com.mail.protection.outlook.com"},
+				CNAME: []string{"corp-example-com.mail.protection.outlook.com"},
+			}},
+			want: []string{"service:mail"},
+		},
+		{
+			name: "http signals",
+			sub: &types.Subdomain{
+				Domain:   "x.example.com",
+				HTTP:     &types.HTTPInfo{API: true, Parked: true, Headers: map[string]string{"WWW-Authenticate": `Basic realm="staff"`}},
+				Metadata: map[string]interface{}{"redirects_to": "www.example.com"},
+			},
+			want: []string{"service:admin", "service:api", "status:parked", "status:redirect"},
+		},
+		{
+			name: "bearer auth is not an admin panel",
+			sub: &types.Subdomain{
+				Domain: "x.example.com",
+				HTTP:   &types.HTTPInfo{Headers: map[string]string{"WWW-Authenticate": "Bearer"}},
+			},
+		},
+		{
+			name: "empty redirect target",
+			sub: &types.Subdomain{
+				Domain:   "x.example.com",
+				HTTP:     &types.HTTPInfo{},
+				Metadata: map[string]interface{}{"redirects_to": ""},
+			},
+		},
+		{
+			name: "cdn and private network metadata",
+			sub: &types.Subdomain{
+				Domain:   "x.example.com",
+				Metadata: map[string]interface{}{"cdn": "Azure Front Door", "private_ip": true},
+			},
+			want: []string{"cdn:azure-front-door", "network:private"},
+		},
+		{
+			name: "public network is untagged",
+			sub:  &types.Subdomain{Domain: "x.example.com", Metadata: map[string]interface{}{"private_ip": false}},
+		},
+		{
+			name: "record-only mail host",
+			sub:  &types.Subdomain{Domain: "x.example.com", Metadata: map[string]interface{}{"record_only": "mail"}},
+			want: []string{"service:mail", "type:mail"},
+		},
+		{
+			name: "record-only nameserver",
+			sub:  &types.Subdomain{Domain: "x.example.com", Metadata: map[string]interface{}{"record_only": "ns"}},
+			want: []string{"type:ns"},
+		},
+		{
+			name: "no signals",
+			sub:  &types.Subdomain{Domain: "x7.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.sub); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Classify = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	sub := &types.Subdomain{Tags: []string{"environment:staging", "service:api"}}
+
+	tests := []struct {
+		filters []string
+		want    bool
+	}{
+		{[]string{"service:api"}, true},
+		{[]string{" Service:API "}, true},
+		{[]string{"environment"}, true},
+		{[]string{"service:admin", "environment:staging"}, true},
+		{[]string{"service:admin"}, false},
+		{[]string{"environment:prod"}, false},
+		{[]string{"staging"}, false},
+		{[]string{"hosting"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(sub, tt.filters); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.filters, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyBatch(t *testing.T) {
+	subdomains := []*types.Subdomain{
+		{Domain: "api.example.com", Tags: []string{"stale:tag"}},
+		{Domain: "x7.example.com", Tags: []string{"stale:tag"}},
+	}
+
+	NewClassifier(zap.NewNop()).ClassifyBatch(context.Background(), subdomains)
+
+	if !reflect.DeepEqual(subdomains[0].Tags, []string{"service:api"}) {
+		t.Errorf("api tags = %q", subdomains[0].Tags)
+	}
+	if subdomains[1].Tags != nil {
+		t.Errorf("untagged host kept %q", subdomains[1].Tags)
+	}
+}
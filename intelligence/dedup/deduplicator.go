@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"math/bits"
 	"sort"
 	"strings"
 
@@ -11,15 +12,30 @@ import (
 	"go.uber.org/zap"
 )
 
+// HTTP merge strategies for duplicate entries
+const (
+	HTTPMergeBest  = "best"  // rank by status class, content, then speed
+	HTTPMergeFirst = "first" // keep the first entry's result
+)
+
 // Deduplicator removes duplicate and similar subdomains
 type Deduplicator struct {
-	logger *zap.Logger
+	logger    *zap.Logger
+	httpMerge string
 }
 
 // NewDeduplicator creates a new deduplication engine
 func NewDeduplicator(logger *zap.Logger) *Deduplicator {
 	return &Deduplicator{
-		logger: logger,
+		logger:    logger,
+		httpMerge: HTTPMergeBest,
+	}
+}
+
+// SetHTTPMerge selects how HTTP results of duplicate entries are combined
+func (d *Deduplicator) SetHTTPMerge(strategy string) {
+	if strategy != "" {
+		d.httpMerge = strategy
 	}
 }
 
@@ -105,11 +121,11 @@ func (d *Deduplicator) merge(target, source *types.Subdomain) {
 		target.Validated = true
 	}
 	
-	// Keep best HTTP info (highest status code)
+	// Keep the more useful HTTP info
 	if source.HTTP != nil {
 		if target.HTTP == nil {
 			target.HTTP = source.HTTP
-		} else if source.HTTP.StatusCode > target.HTTP.StatusCode {
+		} else if d.httpMerge != HTTPMergeFirst && betterHTTP(source.HTTP, target.HTTP) {
 			target.HTTP = source.HTTP
 		}
 	}
@@ -130,6 +146,12 @@ func (d *Deduplicator) merge(target, source *types.Subdomain) {
 		}
 	}
 	
+	// Merge tags
+	if len(source.Tags) > 0 {
+		target.Tags = d.mergeStringSlice(target.Tags, source.Tags)
+		sort.Strings(target.Tags)
+	}
+	
 	// Merge metadata
 	if target.Metadata == nil {
 		target.Metadata = make(map[string]interface{})
@@ -141,6 +163,37 @@ func (d *Deduplicator) merge(target, source *types.Subdomain) {
 	}
 }
 
+// betterHTTP reports whether a is a more useful HTTP result than b: a
+// better status class first (2xx > 3xx > 4xx > 5xx), then one with a title
+// or detected technologies, then the faster response
+func betterHTTP(a, b *types.HTTPInfo) bool {
+	if ra, rb := statusRank(a.StatusCode), statusRank(b.StatusCode); ra != rb {
+		return ra < rb
+	}
+	
+	if ca, cb := hasContent(a), hasContent(b); ca != cb {
+		return ca
+	}
+	
+	if a.ResponseTime > 0 && b.ResponseTime > 0 {
+		return a.ResponseTime < b.ResponseTime
+	}
+	return false
+}
+
+// statusRank orders status codes by class, lower is better
+func statusRank(code int) int {
+	if code >= 200 && code < 600 {
+		return code/100 - 2
+	}
+	return 4 // unknown or missing
+}
+
+// hasContent reports whether a result carries a title or technologies
+func hasContent(info *types.HTTPInfo) bool {
+	return info.Title != "" || len(info.Technologies) > 0
+}
+
 // mergeDNSRecords merges DNS records
 func (d *Deduplicator) mergeDNSRecords(target, source *types.DNSRecords) {
 	target.A = d.mergeStringSlice(target.A, source.A)
@@ -173,7 +226,89 @@ func (d *Deduplicator) mergeStringSlice(a, b []string) []string {
 	return result
 }
 
-// RemoveSimilar removes subdomains that are too similar (fuzzy dedup)
+// CollapseWWW merges each www.X into X when both are present and resolve
+// to the same, non-empty set of IPs: they are the same asset under two
+// names. Only a first label of exactly "www" qualifies, so wwwapp.X or
+// www2.X are left alone. The merged name is noted in Metadata["www_alias"].
+func (d *Deduplicator) CollapseWWW(ctx context.Context, subdomains []*types.Subdomain) []*types.Subdomain {
+	byName := make(map[string]*types.Subdomain, len(subdomains))
+	for _, sub := range subdomains {
+		byName[strings.ToLower(sub.Domain)] = sub
+	}
+	
+	merged := make(map[*types.Subdomain]bool)
+	for _, sub := range subdomains {
+		label, parent, ok := strings.Cut(strings.ToLower(sub.Domain), ".")
+		if !ok || label != "www" {
+			continue
+		}
+		
+		target, exists := byName[parent]
+		if !exists || !sameIPs(sub.IP, target.IP) {
+			continue
+		}
+		
+		d.merge(target, sub)
+		if target.Metadata == nil {
+			target.Metadata = make(map[string]interface{})
+		}
+		target.Metadata["www_alias"] = sub.Domain
+		merged[sub] = true
+	}
+	
+	if len(merged) == 0 {
+		return subdomains
+	}
+	
+	result := make([]*types.Subdomain, 0, len(subdomains)-len(merged))
+	for _, sub := range subdomains {
+		if !merged[sub] {
+			result = append(result, sub)
+		}
+	}
+	
+	d.logger.Info("Collapsed www aliases",
+		zap.Int("merged", len(merged)),
+		zap.Int("remaining", len(result)),
+	)
+	
+	return result
+}
+
+// sameIPs reports whether two non-empty IP lists hold the same addresses
+func sameIPs(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	
+	setA := make(map[string]bool, len(a))
+	for _, ip := range a {
+		setA[ip] = true
+	}
+	setB := make(map[string]bool, len(b))
+	for _, ip := range b {
+		if !setA[ip] {
+			return false
+		}
+		setB[ip] = true
+	}
+	return len(setA) == len(setB)
+}
+
+// maxSimhashDistance is how many bits the body simhashes of two pages of
+// about the same length may differ by for them to count as the same page
+const maxSimhashDistance = 3
+
+// RemoveSimilar removes subdomains that are too similar (fuzzy dedup).
+// Hosts serving the same page are collapsed first, whatever their names:
+// same status code and the same normalized body, or bodies of about the
+// same length whose simhashes are within maxSimhashDistance bits. Then
+// hosts sharing a naming pattern (same labels once digits and common
+// environment affixes are stripped) are clustered when the Levenshtein
+// similarity of their first labels is at least threshold; the highest
+// confidence host of each cluster is kept as its representative and lists
+// the collapsed hosts in Metadata["similar_hosts"]. web01 and web50 score
+// 0.6, so a low threshold collapses numbered fleets while 1.0 keeps all.
 func (d *Deduplicator) RemoveSimilar(ctx context.Context, subdomains []*types.Subdomain, threshold float64) []*types.Subdomain {
 	if len(subdomains) == 0 || threshold >= 1.0 {
 		return subdomains
@@ -184,6 +319,9 @@ func (d *Deduplicator) RemoveSimilar(ctx context.Context, subdomains []*types.Su
 		zap.Float64("threshold", threshold),
 	)
 	
+	// Wildcard and parking hosts often return one page under many names
+	subdomains, removedCount := collapseSameContent(subdomains)
+	
 	// Group by fingerprint
 	groups := make(map[string][]*types.Subdomain)
 	
@@ -192,9 +330,8 @@ func (d *Deduplicator) RemoveSimilar(ctx context.Context, subdomains []*types.Su
 		groups[fingerprint] = append(groups[fingerprint], sub)
 	}
 	
-	// Keep best from each group
+	// Keep best from each cluster
 	var result []*types.Subdomain
-	removedCount := 0
 	
 	for _, group := range groups {
 		if len(group) == 1 {
@@ -202,14 +339,31 @@ func (d *Deduplicator) RemoveSimilar(ctx context.Context, subdomains []*types.Su
 			continue
 		}
 		
-		// Sort by confidence
-		sort.Slice(group, func(i, j int) bool {
+		// Sort by confidence so representatives are the strongest hosts
+		sort.SliceStable(group, func(i, j int) bool {
 			return group[i].Confidence > group[j].Confidence
 		})
 		
-		// Keep the highest confidence one
-		result = append(result, group[0])
-		removedCount += len(group) - 1
+		var representatives []*types.Subdomain
+		for _, sub := range group {
+			var rep *types.Subdomain
+			for _, candidate := range representatives {
+				if similarity(firstLabel(candidate.Domain), firstLabel(sub.Domain)) >= threshold {
+					rep = candidate
+					break
+				}
+			}
+			
+			if rep == nil {
+				representatives = append(representatives, sub)
+				continue
+			}
+			
+			addSimilar(rep, sub)
+			removedCount++
+		}
+		
+		result = append(result, representatives...)
 	}
 	
 	d.logger.Info("Similar removal complete",
@@ -220,22 +374,101 @@ func (d *Deduplicator) RemoveSimilar(ctx context.Context, subdomains []*types.Su
 	return result
 }
 
+// collapseSameContent keeps the highest confidence host of each group
+// serving the same page, returning the hosts left in their original order
+// and how many were collapsed. Hosts without a body hash are kept.
+func collapseSameContent(subdomains []*types.Subdomain) ([]*types.Subdomain, int) {
+	ranked := make([]*types.Subdomain, 0, len(subdomains))
+	for _, sub := range subdomains {
+		if sub.HTTP != nil && sub.HTTP.BodyHash != "" {
+			ranked = append(ranked, sub)
+		}
+	}
+	if len(ranked) < 2 {
+		return subdomains, 0
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Confidence > ranked[j].Confidence
+	})
+	
+	collapsed := make(map[*types.Subdomain]bool)
+	var representatives []*types.Subdomain
+	for _, sub := range ranked {
+		var rep *types.Subdomain
+		for _, candidate := range representatives {
+			if sameContent(candidate.HTTP, sub.HTTP) {
+				rep = candidate
+				break
+			}
+		}
+		
+		if rep == nil {
+			representatives = append(representatives, sub)
+			continue
+		}
+		addSimilar(rep, sub)
+		collapsed[sub] = true
+	}
+	if len(collapsed) == 0 {
+		return subdomains, 0
+	}
+	
+	kept := make([]*types.Subdomain, 0, len(subdomains)-len(collapsed))
+	for _, sub := range subdomains {
+		if !collapsed[sub] {
+			kept = append(kept, sub)
+		}
+	}
+	return kept, len(collapsed)
+}
+
+// sameContent reports whether two HTTP results carry the same page
+func sameContent(a, b *types.HTTPInfo) bool {
+	if a.StatusCode != b.StatusCode {
+		return false
+	}
+	if a.BodyHash == b.BodyHash {
+		return true
+	}
+	return lengthBucket(a.BodyLength) == lengthBucket(b.BodyLength) &&
+		bits.OnesCount64(a.BodySimhash^b.BodySimhash) <= maxSimhashDistance
+}
+
+// lengthBucket groups body lengths by power of two, so pages of very
+// different sizes never count as near duplicates
+func lengthBucket(length int) int {
+	return bits.Len(uint(length))
+}
+
+// addSimilar records a collapsed host on its representative
+func addSimilar(rep, sub *types.Subdomain) {
+	if rep.Metadata == nil {
+		rep.Metadata = make(map[string]interface{})
+	}
+	similar, _ := rep.Metadata["similar_hosts"].([]string)
+	rep.Metadata["similar_hosts"] = append(similar, sub.Domain)
+}
+
 // fingerprint creates a fingerprint for similarity detection
 func (d *Deduplicator) fingerprint(domain string) string {
-	// Extract subdomain part
-	parts := strings.Split(domain, ".")
+	// Extract subdomain part; the parent is kept so that hosts under
+	// different branches never collapse together
+	parts := strings.SplitN(domain, ".", 2)
 	if len(parts) == 0 {
 		return domain
 	}
 	
 	subdomain := parts[0]
+	parent := ""
+	if len(parts) == 2 {
+		parent = strings.ToLower(parts[1])
+	}
 	
 	// Normalize
 	normalized := strings.ToLower(subdomain)
 	
 	// Remove common suffixes/prefixes for grouping
-	normalized = strings.TrimPrefix(normalized, "www")
-	normalized = strings.TrimPrefix(normalized, "www-")
+	normalized = trimWWW(normalized)
 	normalized = strings.TrimSuffix(normalized, "-prod")
 	normalized = strings.TrimSuffix(normalized, "-dev")
 	normalized = strings.TrimSuffix(normalized, "-staging")
@@ -248,13 +481,71 @@ func (d *Deduplicator) fingerprint(domain string) string {
 		}
 	}
 	
-	pattern := builder.String()
+	pattern := builder.String() + "." + parent
 	
 	// Create hash of pattern
 	hash := sha256.Sum256([]byte(pattern))
 	return fmt.Sprintf("%x", hash[:8])
 }
 
+// trimWWW strips a www prefix from a label when it is one: "www",
+// "www-api" and "www2" lose it, but "wwwapp" is a different name and
+// keeps it
+func trimWWW(label string) string {
+	rest, ok := strings.CutPrefix(label, "www")
+	if !ok {
+		return label
+	}
+	if rest == "" || rest[0] == '-' || (rest[0] >= '0' && rest[0] <= '9') {
+		return strings.TrimPrefix(rest, "-")
+	}
+	return label
+}
+
+// firstLabel returns the leftmost label of a domain
+func firstLabel(domain string) string {
+	if idx := strings.Index(domain, "."); idx != -1 {
+		return strings.ToLower(domain[:idx])
+	}
+	return strings.ToLower(domain)
+}
+
+// similarity returns 1 - levenshtein(a, b) / max(len(a), len(b))
+func similarity(a, b string) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1.0
+	}
+	return 1.0 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	
+	for j := range prev {
+		prev[j] = j
+	}
+	
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	
+	return prev[len(b)]
+}
+
 // RemoveWildcards filters out wildcard DNS results
 func (d *Deduplicator) RemoveWildcards(ctx context.Context, subdomains []*types.Subdomain, wildcardPatterns []string) []*types.Subdomain {
 	if len(wildcardPatterns) == 0 {
@@ -0,0 +1,211 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/modules/web/prober"
+	"go.uber.org/zap"
+)
+
+func TestBetterHTTP(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *types.HTTPInfo
+		want bool
+	}{
+		{
+			name: "2xx beats 5xx",
+			a:    &types.HTTPInfo{StatusCode: 200},
+			b:    &types.HTTPInfo{StatusCode: 500},
+			want: true,
+		},
+		{
+			name: "5xx loses to 2xx despite a higher code",
+			a:    &types.HTTPInfo{StatusCode: 503, Title: "Service Unavailable"},
+			b:    &types.HTTPInfo{StatusCode: 204},
+			want: false,
+		},
+		{
+			name: "3xx beats 4xx",
+			a:    &types.HTTPInfo{StatusCode: 301},
+			b:    &types.HTTPInfo{StatusCode: 403},
+			want: true,
+		},
+		{
+			name: "4xx beats no status",
+			a:    &types.HTTPInfo{StatusCode: 404},
+			b:    &types.HTTPInfo{},
+			want: true,
+		},
+		{
+			name: "title wins within a class",
+			a:    &types.HTTPInfo{StatusCode: 200, Title: "Dashboard"},
+			b:    &types.HTTPInfo{StatusCode: 200, ResponseTime: time.Millisecond},
+			want: true,
+		},
+		{
+			name: "technologies count as content",
+			a:    &types.HTTPInfo{StatusCode: 200},
+			b:    &types.HTTPInfo{StatusCode: 201, Technologies: []string{"nginx"}},
+			want: false,
+		},
+		{
+			name: "faster wins when otherwise equal",
+			a:    &types.HTTPInfo{StatusCode: 200, Title: "Home", ResponseTime: 80 * time.Millisecond},
+			b:    &types.HTTPInfo{StatusCode: 200, Title: "Home", ResponseTime: 300 * time.Millisecond},
+			want: true,
+		},
+		{
+			name: "slower loses",
+			a:    &types.HTTPInfo{StatusCode: 200, ResponseTime: 300 * time.Millisecond},
+			b:    &types.HTTPInfo{StatusCode: 200, ResponseTime: 80 * time.Millisecond},
+			want: false,
+		},
+		{
+			name: "unknown speed keeps the incumbent",
+			a:    &types.HTTPInfo{StatusCode: 200, ResponseTime: 80 * time.Millisecond},
+			b:    &types.HTTPInfo{StatusCode: 200},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := betterHTTP(tt.a, tt.b); got != tt.want {
+				t.Errorf("betterHTTP(%d, %d) = %v, want %v", tt.a.StatusCode, tt.b.StatusCode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeduplicateMergesHTTPByStrategy(t *testing.T) {
+	entries := func() []*types.Subdomain {
+		return []*types.Subdomain{
+			{Domain: "www.example.com", Sources: []string{"crtsh"}, HTTP: &types.HTTPInfo{StatusCode: 502}},
+			{Domain: "WWW.example.com", Sources: []string{"dns_bruteforce"}, HTTP: &types.HTTPInfo{StatusCode: 200, Title: "Home"}},
+		}
+	}
+
+	tests := []struct {
+		strategy string
+		want     int
+	}{
+		{strategy: "", want: 200}, // best by default
+		{strategy: HTTPMergeBest, want: 200},
+		{strategy: HTTPMergeFirst, want: 502},
+	}
+
+	for _, tt := range tests {
+		d := NewDeduplicator(zap.NewNop())
+		d.SetHTTPMerge(tt.strategy)
+
+		merged := d.Deduplicate(context.Background(), entries())
+		if len(merged) != 1 {
+			t.Fatalf("strategy %q: got %d entries, want 1", tt.strategy, len(merged))
+		}
+		if got := merged[0].HTTP.StatusCode; got != tt.want {
+			t.Errorf("strategy %q kept status %d, want %d", tt.strategy, got, tt.want)
+		}
+		if got := merged[0].Sources; len(got) != 2 {
+			t.Errorf("strategy %q merged sources %v, want both", tt.strategy, got)
+		}
+	}
+}
+
+func TestTrimWWW(t *testing.T) {
+	tests := map[string]string{
+		"www":     "",
+		"www-api": "api",
+		"www2":    "2",
+		"wwwapp":  "wwwapp",
+		"api":     "api",
+		"wwww":    "wwww",
+	}
+
+	for label, want := range tests {
+		if got := trimWWW(label); got != want {
+			t.Errorf("trimWWW(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
+
+func TestCollapseWWWOnlyMergesWWWLabel(t *testing.T) {
+	host := func(name string, ips ...string) *types.Subdomain {
+		return &types.Subdomain{Domain: name, Sources: []string{"crtsh"}, IP: ips}
+	}
+	subdomains := []*types.Subdomain{
+		host("example.com", "192.0.2.1"),
+		host("www.example.com", "192.0.2.1"),
+		host("app.example.com", "192.0.2.2"),
+		host("wwwapp.example.com", "192.0.2.2"), // not www.app
+		host("www2.example.com", "192.0.2.1"),
+		host("shop.example.com", "192.0.2.3"),
+		host("www.shop.example.com", "192.0.2.4"), // another asset
+	}
+
+	collapsed := NewDeduplicator(zap.NewNop()).CollapseWWW(context.Background(), subdomains)
+
+	kept := map[string]*types.Subdomain{}
+	for _, sub := range collapsed {
+		kept[sub.Domain] = sub
+	}
+	if _, ok := kept["www.example.com"]; ok {
+		t.Error("www.example.com kept beside example.com on the same IPs")
+	}
+	if alias := kept["example.com"].Metadata["www_alias"]; alias != "www.example.com" {
+		t.Errorf("www_alias = %v, want www.example.com", alias)
+	}
+	for _, name := range []string{"wwwapp.example.com", "app.example.com", "www2.example.com", "www.shop.example.com"} {
+		if _, ok := kept[name]; !ok {
+			t.Errorf("%s collapsed, want it kept", name)
+		}
+	}
+	if len(collapsed) != len(subdomains)-1 {
+		t.Errorf("got %d hosts, want %d", len(collapsed), len(subdomains)-1)
+	}
+}
+
+func TestRemoveSimilarCollapsesSamePage(t *testing.T) {
+	parked := func(token string) string {
+		return `<html><head><meta name="csrf-token" content="` + token + `"></head>
+<body><h1>This domain is parked</h1><p>Contact the registrar to buy it.</p></body></html>`
+	}
+	host := func(name string, status int, body string, confidence int) *types.Subdomain {
+		hash, simhash := prober.HashBody(body)
+		return &types.Subdomain{
+			Domain:     name,
+			Confidence: confidence,
+			HTTP:       &types.HTTPInfo{StatusCode: status, BodyHash: hash, BodySimhash: simhash, BodyLength: len(body)},
+		}
+	}
+	subdomains := []*types.Subdomain{
+		host("alpha.example.com", 200, parked("q1w2e3r4t5y6u7i8"), 60),
+		host("zulu.example.com", 200, parked("z9x8c7v6b5n4m3l2"), 90),
+		host("shop.example.com", 200, "<html><body><h1>Shop</h1><p>Spring sale on every item in the catalogue.</p></body></html>", 50),
+		host("gone.example.com", 404, parked("a1s2d3f4g5h6j7k8"), 50),
+	}
+
+	kept := map[string]*types.Subdomain{}
+	for _, sub := range NewDeduplicator(zap.NewNop()).RemoveSimilar(context.Background(), subdomains, 0.99) {
+		kept[sub.Domain] = sub
+	}
+
+	if _, ok := kept["alpha.example.com"]; ok {
+		t.Error("alpha.example.com kept although it serves zulu's page")
+	}
+	similar, _ := kept["zulu.example.com"].Metadata["similar_hosts"].([]string)
+	if len(similar) != 1 || similar[0] != "alpha.example.com" {
+		t.Errorf("similar_hosts = %v, want [alpha.example.com]", similar)
+	}
+	for _, name := range []string{"shop.example.com", "gone.example.com"} {
+		if _, ok := kept[name]; !ok {
+			t.Errorf("%s collapsed, want it kept", name)
+		}
+	}
+	if len(kept) != 3 {
+		t.Errorf("got %d hosts, want 3", len(kept))
+	}
+}
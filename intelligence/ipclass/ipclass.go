@@ -0,0 +1,95 @@
+package ipclass
+
+import (
+	"net"
+
+	"github.com/yourusername/usr/internal/iprange"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// Class describes the routability of an IP address
+type Class string
+
+const (
+	ClassPublic   Class = "public"
+	ClassPrivate  Class = "private"  // RFC1918, RFC4193 ULA, CGNAT
+	ClassReserved Class = "reserved" // loopback, link-local, unspecified, multicast, documentation
+	ClassInvalid  Class = "invalid"
+)
+
+// reservedRanges are special-purpose blocks not covered by net.IP helpers
+var reservedRanges = iprange.MustParse(
+	"0.0.0.0/8",       // "this" network
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // TEST-NET-1
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // TEST-NET-2
+	"203.0.113.0/24",  // TEST-NET-3
+	"240.0.0.0/4",     // future use
+	"2001:db8::/32",   // documentation
+)
+
+// cgnatRange is shared address space used behind carrier-grade NAT
+var cgnatRange = iprange.MustParse("100.64.0.0/10")
+
+// Classify returns the class of a textual IP address
+func Classify(raw string) Class {
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return ClassInvalid
+	}
+
+	switch {
+	case ip.IsLoopback(), ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast(),
+		ip.IsInterfaceLocalMulticast(), ip.IsMulticast(), ip.IsUnspecified():
+		return ClassReserved
+	case ip.IsPrivate(), cgnatRange.Contains(ip):
+		return ClassPrivate
+	case reservedRanges.Contains(ip):
+		return ClassReserved
+	}
+
+	return ClassPublic
+}
+
+// Tag classifies every resolved IP of a subdomain, recording the classes in
+// Metadata["ip_classes"] and setting Metadata["private_ip"] when any address
+// is private or reserved. It reports whether such an address was found.
+func Tag(sub *types.Subdomain) bool {
+	if len(sub.IP) == 0 {
+		return false
+	}
+
+	if sub.Metadata == nil {
+		sub.Metadata = make(map[string]interface{})
+	}
+
+	classes := make(map[string]string, len(sub.IP))
+	internal := false
+
+	for _, ip := range sub.IP {
+		class := Classify(ip)
+		classes[ip] = string(class)
+		if class == ClassPrivate || class == ClassReserved {
+			internal = true
+		}
+	}
+
+	sub.Metadata["ip_classes"] = classes
+	if internal {
+		sub.Metadata["private_ip"] = true
+	} else {
+		delete(sub.Metadata, "private_ip")
+	}
+
+	return internal
+}
+
+// IsPrivate reports whether a subdomain was tagged as resolving internally
+func IsPrivate(sub *types.Subdomain) bool {
+	if sub.Metadata == nil {
+		return false
+	}
+	private, _ := sub.Metadata["private_ip"].(bool)
+	return private
+}
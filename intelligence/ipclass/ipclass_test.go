@@ -0,0 +1,141 @@
+package ipclass
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want Class
+	}{
+		// RFC1918, edges included
+		{"10.0.0.0", ClassPrivate},
+		{"10.255.255.255", ClassPrivate},
+		{"9.255.255.255", ClassPublic},
+		{"11.0.0.0", ClassPublic},
+		{"172.16.0.1", ClassPrivate},
+		{"172.31.255.255", ClassPrivate},
+		{"172.15.255.255", ClassPublic},
+		{"172.32.0.0", ClassPublic},
+		{"192.168.0.1", ClassPrivate},
+		{"192.169.0.1", ClassPublic},
+
+		// CGNAT 100.64.0.0/10
+		{"100.64.0.0", ClassPrivate},
+		{"100.127.255.255", ClassPrivate},
+		{"100.63.255.255", ClassPublic},
+		{"100.128.0.0", ClassPublic},
+
+		// IPv6 unique local fc00::/7
+		{"fc00::1", ClassPrivate},
+		{"fdff:ffff::1", ClassPrivate},
+		{"fbff::1", ClassPublic},
+		{"fe00::1", ClassPublic},
+
+		// Documentation and other special-purpose blocks
+		{"192.0.2.1", ClassReserved},
+		{"198.51.100.255", ClassReserved},
+		{"203.0.113.7", ClassReserved},
+		{"203.0.114.1", ClassPublic},
+		{"2001:db8::1", ClassReserved},
+		{"2001:db9::1", ClassPublic},
+		{"198.18.0.1", ClassReserved},
+		{"198.19.255.255", ClassReserved},
+		{"198.20.0.0", ClassPublic},
+		{"192.0.0.8", ClassReserved},
+		{"0.1.2.3", ClassReserved},
+		{"240.0.0.1", ClassReserved},
+		{"255.255.255.255", ClassReserved},
+
+		// Loopback, link-local, multicast, unspecified
+		{"127.0.0.1", ClassReserved},
+		{"::1", ClassReserved},
+		{"169.254.169.254", ClassReserved},
+		{"fe80::1", ClassReserved},
+		{"224.0.0.251", ClassReserved},
+		{"ff02::1", ClassReserved},
+		{"0.0.0.0", ClassReserved},
+		{"::", ClassReserved},
+
+		// IPv4-mapped IPv6 classifies as the IPv4 address
+		{"::ffff:10.0.0.1", ClassPrivate},
+		{"::ffff:8.8.8.8", ClassPublic},
+
+		{"8.8.8.8", ClassPublic},
+		{"2606:4700::6810:84e5", ClassPublic},
+
+		{"", ClassInvalid},
+		{"10.0.0", ClassInvalid},
+		{"256.1.1.1", ClassInvalid},
+		{"example.com", ClassInvalid},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.ip); got != tt.want {
+			t.Errorf("Classify(%q) = %s, want %s", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		ips     []string
+		private bool
+		classes map[string]string
+	}{
+		{name: "no addresses"},
+		{
+			name:    "public only",
+			ips:     []string{"8.8.8.8"},
+			classes: map[string]string{"8.8.8.8": "public"},
+		},
+		{
+			name:    "any internal address marks the host",
+			ips:     []string{"8.8.8.8", "10.0.0.5"},
+			private: true,
+			classes: map[string]string{"8.8.8.8": "public", "10.0.0.5": "private"},
+		},
+		{
+			name:    "reserved counts as internal",
+			ips:     []string{"127.0.0.1"},
+			private: true,
+			classes: map[string]string{"127.0.0.1": "reserved"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &types.Subdomain{Domain: "host.example.com", IP: tt.ips}
+			if got := Tag(sub); got != tt.private {
+				t.Errorf("Tag = %v, want %v", got, tt.private)
+			}
+			if IsPrivate(sub) != tt.private {
+				t.Errorf("IsPrivate = %v, want %v", IsPrivate(sub), tt.private)
+			}
+			if tt.classes == nil {
+				if sub.Metadata != nil {
+					t.Errorf("metadata set without addresses: %v", sub.Metadata)
+				}
+				return
+			}
+			if got := sub.Metadata["ip_classes"]; !reflect.DeepEqual(got, tt.classes) {
+				t.Errorf("ip_classes = %v, want %v", got, tt.classes)
+			}
+		})
+	}
+}
+
+func TestTagClearsStalePrivateFlag(t *testing.T) {
+	sub := &types.Subdomain{IP: []string{"10.0.0.1"}}
+	Tag(sub)
+
+	sub.IP = []string{"8.8.8.8"}
+	if Tag(sub) || IsPrivate(sub) {
+		t.Error("private flag kept after the host moved to a public address")
+	}
+}
@@ -0,0 +1,116 @@
+package known
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// Tag marks hosts that appear in the known-subdomains list
+const Tag = "source:known"
+
+// DefaultWeight is the confidence the scorer adds to a known host
+const DefaultWeight = 20
+
+// List is a set of subdomains the analyst already knows exist, used to
+// boost their confidence and to check the scan's coverage
+type List struct {
+	names map[string]bool
+}
+
+// Load reads a known-subdomains file: one name per line, blank lines and
+// lines starting with # ignored
+func Load(path string) (*List, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open known file: %w", err)
+	}
+	defer file.Close()
+
+	list := &List{names: make(map[string]bool)}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list.names[normalize(line)] = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read known file: %w", err)
+	}
+
+	return list, nil
+}
+
+// Len returns the number of known names
+func (l *List) Len() int {
+	return len(l.names)
+}
+
+// Contains reports whether a domain is on the list
+func (l *List) Contains(domain string) bool {
+	return l.names[normalize(domain)]
+}
+
+// Apply tags every known subdomain with Tag, which the scorer rewards with
+// the known weight. It returns how many subdomains matched.
+func (l *List) Apply(subdomains []*types.Subdomain) int {
+	matched := 0
+	for _, sub := range subdomains {
+		if !l.Contains(sub.Domain) {
+			continue
+		}
+		matched++
+
+		if !hasTag(sub.Tags, Tag) {
+			sub.Tags = append(sub.Tags, Tag)
+			sort.Strings(sub.Tags)
+		}
+	}
+	return matched
+}
+
+// Missing returns the known names accepted by inScope that are absent
+// from subdomains, sorted. A nil inScope accepts every name.
+func (l *List) Missing(subdomains []*types.Subdomain, inScope func(string) bool) []string {
+	found := make(map[string]bool, len(subdomains))
+	for _, sub := range subdomains {
+		found[normalize(sub.Domain)] = true
+	}
+
+	var missing []string
+	for name := range l.names {
+		if found[name] || (inScope != nil && !inScope(name)) {
+			continue
+		}
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// IsKnown reports whether a subdomain carries the known tag
+func IsKnown(sub *types.Subdomain) bool {
+	return hasTag(sub.Tags, Tag)
+}
+
+// normalize lowercases a name and drops the trailing dot
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
+
+// hasTag reports whether tags contains tag
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
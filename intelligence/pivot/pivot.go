@@ -0,0 +1,119 @@
+package pivot
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// Leads are related organizations and out-of-scope domains revealed by
+// certificates. They are reported for the analyst, never scanned.
+type Leads struct {
+	Organizations []*Lead `json:"organizations,omitempty"`
+	Domains       []*Lead `json:"domains,omitempty"`
+}
+
+// Lead is a single pivot candidate and the in-scope hosts that revealed it
+type Lead struct {
+	Value  string   `json:"value"`
+	SeenOn []string `json:"seen_on"`
+}
+
+// multiPartSuffixes are public suffixes with two labels, so the apex keeps
+// three labels (example.co.uk rather than co.uk)
+var multiPartSuffixes = map[string]bool{
+	"co.uk": true, "org.uk": true, "ac.uk": true, "gov.uk": true,
+	"com.au": true, "net.au": true, "org.au": true,
+	"co.jp": true, "co.nz": true, "co.za": true, "co.in": true,
+	"com.br": true, "com.cn": true, "com.mx": true, "com.tr": true,
+}
+
+// Collect gathers certificate Organizations and the apexes of SANs other
+// than the target's apex from probed subdomains. SANs of sibling names
+// under the same apex (www.example.com on a scan of api.example.com) are
+// the same organization, not a pivot.
+func Collect(apex string, subdomains []*types.Subdomain) *Leads {
+	apex = strings.ToLower(strings.TrimSuffix(apex, "."))
+
+	orgs := make(map[string]map[string]bool)
+	domains := make(map[string]map[string]bool)
+
+	for _, sub := range subdomains {
+		if sub.TLS == nil {
+			continue
+		}
+
+		if org := strings.TrimSpace(sub.TLS.Organization); org != "" {
+			addSeen(orgs, org, sub.Domain)
+		}
+
+		for _, san := range sub.TLS.SANs {
+			san = strings.ToLower(strings.TrimPrefix(san, "*."))
+			if sanApex := Apex(san); sanApex != "" && sanApex != apex {
+				addSeen(domains, sanApex, sub.Domain)
+			}
+		}
+	}
+
+	return &Leads{
+		Organizations: toLeads(orgs),
+		Domains:       toLeads(domains),
+	}
+}
+
+// Count returns the total number of leads
+func (l *Leads) Count() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.Organizations) + len(l.Domains)
+}
+
+// Apex returns the registrable domain of a hostname
+func Apex(host string) string {
+	labels := strings.Split(strings.Trim(host, "."), ".")
+	if len(labels) < 2 {
+		return ""
+	}
+
+	keep := 2
+	if len(labels) >= 3 && multiPartSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		keep = 3
+	}
+	if len(labels) < keep {
+		return ""
+	}
+
+	return strings.Join(labels[len(labels)-keep:], ".")
+}
+
+// addSeen records that value was revealed by host
+func addSeen(index map[string]map[string]bool, value, host string) {
+	if index[value] == nil {
+		index[value] = make(map[string]bool)
+	}
+	index[value][host] = true
+}
+
+// toLeads converts an index into leads sorted by how widely they were seen
+func toLeads(index map[string]map[string]bool) []*Lead {
+	leads := make([]*Lead, 0, len(index))
+	for value, hosts := range index {
+		lead := &Lead{Value: value}
+		for host := range hosts {
+			lead.SeenOn = append(lead.SeenOn, host)
+		}
+		sort.Strings(lead.SeenOn)
+		leads = append(leads, lead)
+	}
+
+	sort.Slice(leads, func(i, j int) bool {
+		if len(leads[i].SeenOn) != len(leads[j].SeenOn) {
+			return len(leads[i].SeenOn) > len(leads[j].SeenOn)
+		}
+		return leads[i].Value < leads[j].Value
+	})
+
+	return leads
+}
@@ -0,0 +1,72 @@
+package pivot
+
+import (
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+func TestCollectSkipsSANsUnderTheApex(t *testing.T) {
+	subs := []*types.Subdomain{
+		{
+			Domain: "api.example.com",
+			TLS: &types.TLSInfo{
+				Organization: "Example Inc",
+				SANs: []string{
+					"api.example.com",
+					"www.example.com",   // sibling outside the target api.example.com
+					"*.cdn.example.com", // wildcard sibling
+					"example.com",
+					"shop.example-store.co.uk",
+					"*.partner.net",
+				},
+			},
+		},
+		{
+			Domain: "login.example.com",
+			TLS: &types.TLSInfo{
+				SANs: []string{"login.example.com", "sso.partner.net"},
+			},
+		},
+	}
+
+	leads := Collect("example.com", subs)
+
+	want := map[string]int{"example-store.co.uk": 1, "partner.net": 2}
+	if len(leads.Domains) != len(want) {
+		t.Fatalf("domain leads = %v, want %v", leadValues(leads.Domains), want)
+	}
+	for _, lead := range leads.Domains {
+		if seen, ok := want[lead.Value]; !ok || len(lead.SeenOn) != seen {
+			t.Errorf("lead %s seen on %v, want %d hosts", lead.Value, lead.SeenOn, want[lead.Value])
+		}
+	}
+
+	if len(leads.Organizations) != 1 || leads.Organizations[0].Value != "Example Inc" {
+		t.Errorf("organization leads = %v, want [Example Inc]", leadValues(leads.Organizations))
+	}
+}
+
+func TestApex(t *testing.T) {
+	tests := map[string]string{
+		"example.com":              "example.com",
+		"a.b.example.com":          "example.com",
+		"shop.example-store.co.uk": "example-store.co.uk",
+		"example.com.":             "example.com",
+		"localhost":                "",
+	}
+	for host, want := range tests {
+		if got := Apex(host); got != want {
+			t.Errorf("Apex(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+// leadValues returns the values of leads, for messages
+func leadValues(leads []*Lead) []string {
+	var values []string
+	for _, lead := range leads {
+		values = append(values, lead.Value)
+	}
+	return values
+}
@@ -0,0 +1,106 @@
+package scorer
+
+import (
+	"fmt"
+)
+
+// HistogramBuckets is the number of 10-point confidence buckets
+// (0-9, 10-19, ..., 90-100)
+const HistogramBuckets = 10
+
+// Histogram is the distribution of confidence scores over a result set
+type Histogram struct {
+	Buckets            [HistogramBuckets]int `json:"buckets"`
+	Total              int                   `json:"total"`
+	SuggestedThreshold int                   `json:"suggested_threshold"`
+}
+
+// HistogramBar is a single bucket prepared for display
+type HistogramBar struct {
+	Label   string
+	Count   int
+	Percent float64 // share of the largest bucket, for bar widths
+}
+
+// NewHistogram buckets scores and suggests a min_confidence threshold
+func NewHistogram(scores []int) *Histogram {
+	h := &Histogram{}
+
+	for _, score := range scores {
+		h.Buckets[bucketIndex(score)]++
+		h.Total++
+	}
+
+	h.SuggestedThreshold = h.knee()
+	return h
+}
+
+// bucketIndex maps a score to its bucket; 100 joins the 90-99 bucket
+func bucketIndex(score int) int {
+	switch {
+	case score < 0:
+		return 0
+	case score >= 100:
+		return HistogramBuckets - 1
+	default:
+		return score / 10
+	}
+}
+
+// knee finds where the cumulative distribution, walked from the highest
+// bucket down, stops gaining hosts quickly. Results above the knee hold
+// most hosts; lowering the threshold past it mostly adds noise. The
+// threshold is the lower bound of the knee bucket, or 0 if there is none.
+func (h *Histogram) knee() int {
+	if h.Total == 0 {
+		return 0
+	}
+
+	best, bestDistance := 0, 0.0
+	cumulative := 0
+
+	for i := HistogramBuckets - 1; i >= 0; i-- {
+		cumulative += h.Buckets[i]
+
+		// Distance above the diagonal from (0,0) to (1,1)
+		x := float64(HistogramBuckets-i) / HistogramBuckets
+		y := float64(cumulative) / float64(h.Total)
+		if distance := y - x; distance > bestDistance {
+			best, bestDistance = i*10, distance
+		}
+	}
+
+	return best
+}
+
+// Label returns the score range of a bucket, e.g. "40-49"
+func Label(bucket int) string {
+	if bucket == HistogramBuckets-1 {
+		return "90-100"
+	}
+	return fmt.Sprintf("%d-%d", bucket*10, bucket*10+9)
+}
+
+// Bars returns the buckets highest first, scaled for rendering
+func (h *Histogram) Bars() []HistogramBar {
+	peak := 0
+	for _, count := range h.Buckets {
+		if count > peak {
+			peak = count
+		}
+	}
+
+	bars := make([]HistogramBar, 0, HistogramBuckets)
+	for i := HistogramBuckets - 1; i >= 0; i-- {
+		bar := HistogramBar{
+			Label: Label(i),
+			Count: h.Buckets[i],
+		}
+		if peak > 0 {
+			bar.Percent = float64(h.Buckets[i]) / float64(peak) * 100
+		}
+		bars = append(bars, bar)
+	}
+
+	return bars
+}
@@ -3,25 +3,72 @@ package scorer
 import (
 	"context"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/yourusername/usr/intelligence/classify"
+	"github.com/yourusername/usr/intelligence/known"
+	"github.com/yourusername/usr/internal/config"
 	"github.com/yourusername/usr/internal/types"
 	"go.uber.org/zap"
 )
 
+// Recency scoring: how many stored scans make a host persistent, and how
+// old a single sighting must be to count as stale
+const (
+	persistentScans = 3
+	staleAfter      = 30 * 24 * time.Hour
+	stalePenalty    = 5
+)
+
+// SameAsApexKey is the metadata key marking hosts whose IPs all belong to
+// the apex; such a host is likely the apex's default vhost and loses
+// sameAsApexPenalty points
+const (
+	SameAsApexKey     = "same_as_apex"
+	sameAsApexPenalty = 10
+)
+
+// ScanCounter reports how many stored scans found a subdomain and when
+// they last saw it
+type ScanCounter interface {
+	CountScansContaining(ctx context.Context, subdomain string) (int, error)
+	LastSeenStored(ctx context.Context, subdomain string) (time.Time, error)
+}
+
 // Scorer calculates confidence scores for discovered subdomains
 type Scorer struct {
 	logger *zap.Logger
 	
 	// Source reliability weights
 	sourceWeights map[string]int
+	defaultWeight int
+	
+	// Maximum points of each score component
+	caps config.ScoringComponentsConfig
+	
+	// Boost for hosts on the analyst's known-subdomains list
+	knownWeight int
+	
+	// Stored scans, for the recency component (optional)
+	history ScanCounter
 }
 
-// NewScorer creates a new scoring engine
-func NewScorer(logger *zap.Logger) *Scorer {
-	return &Scorer{
-		logger: logger,
+// NewScorer creates a new scoring engine. Weights and caps from cfg
+// override the built-in ones; negative values are ignored with a warning.
+func NewScorer(cfg *config.ScoringConfig, logger *zap.Logger) *Scorer {
+	s := &Scorer{
+		logger:        logger,
+		knownWeight:   known.DefaultWeight,
+		defaultWeight: 5,
+		caps: config.ScoringComponentsConfig{
+			Source:     40,
+			Validation: 30,
+			Response:   20,
+			Pattern:    10,
+			Recency:    10,
+		},
 		sourceWeights: map[string]int{
 			// Passive sources (high reliability)
 			"crtsh":                 15,
@@ -31,6 +78,7 @@ func NewScorer(logger *zap.Logger) *Scorer {
 			"wayback_machine":       10,
 			"common_crawl":          8,
 			"shodan":                10,
+			"spf_dmarc":             12,
 			"censys":                10,
 			
 			// Active sources (medium reliability - requires validation)
@@ -40,7 +88,11 @@ func NewScorer(logger *zap.Logger) *Scorer {
 			
 			// Web sources (medium-high reliability)
 			"http_probing":          10,
+			"http_headers":          10,
+			"tls_san":               12,
 			"js_parsing":            9,
+			"link_crawling":         9,
+			"sitemap_robots":        9,
 			"cloud_assets":          11,
 			
 			// AI sources (lower weight - needs validation)
@@ -49,30 +101,112 @@ func NewScorer(logger *zap.Logger) *Scorer {
 			"ai_mutations":          4,
 		},
 	}
+	
+	if cfg != nil {
+		s.configure(cfg)
+	}
+	
+	logger.Info("Effective scoring table",
+		zap.Any("source_weights", s.sourceWeights),
+		zap.Int("default_weight", s.defaultWeight),
+		zap.Any("components", s.caps),
+	)
+	
+	return s
+}
+
+// configure applies the configured weights and caps over the defaults
+func (s *Scorer) configure(cfg *config.ScoringConfig) {
+	sources := make([]string, 0, len(cfg.SourceWeights))
+	for source := range cfg.SourceWeights {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	
+	for _, source := range sources {
+		weight := cfg.SourceWeights[source]
+		if weight < 0 {
+			s.logger.Warn("Ignoring negative source weight",
+				zap.String("source", source),
+				zap.Int("weight", weight),
+			)
+			continue
+		}
+		s.sourceWeights[source] = weight
+	}
+	
+	s.defaultWeight = s.nonNegative("default_weight", cfg.DefaultWeight, s.defaultWeight)
+	s.caps.Source = s.nonNegative("components.source", cfg.Components.Source, s.caps.Source)
+	s.caps.Validation = s.nonNegative("components.validation", cfg.Components.Validation, s.caps.Validation)
+	s.caps.Response = s.nonNegative("components.response", cfg.Components.Response, s.caps.Response)
+	s.caps.Pattern = s.nonNegative("components.pattern", cfg.Components.Pattern, s.caps.Pattern)
+	s.caps.Recency = s.nonNegative("components.recency", cfg.Components.Recency, s.caps.Recency)
+}
+
+// nonNegative returns value, or fallback with a warning when it is negative
+func (s *Scorer) nonNegative(key string, value, fallback int) int {
+	if value < 0 {
+		s.logger.Warn("Ignoring negative scoring value",
+			zap.String("key", "scoring."+key),
+			zap.Int("value", value),
+			zap.Int("using", fallback),
+		)
+		return fallback
+	}
+	return value
+}
+
+// SetHistory enables the recency component: hosts found by many stored
+// scans gain confidence, stale one-off sightings lose a little
+func (s *Scorer) SetHistory(history ScanCounter) {
+	s.history = history
+}
+
+// SetKnownWeight sets the boost for hosts tagged as known
+func (s *Scorer) SetKnownWeight(weight int) {
+	s.knownWeight = weight
 }
 
 // Score calculates a comprehensive confidence score for a subdomain
 func (s *Scorer) Score(ctx context.Context, subdomain *types.Subdomain) int {
 	var score float64
 	
-	// Component 1: Source credibility (max 40 points)
-	sourceScore := s.calculateSourceScore(subdomain.Sources)
-	score += math.Min(sourceScore, 40)
+	// Component 1: Source credibility (max 40 points by default)
+	sourceScore := math.Min(s.calculateSourceScore(subdomain.Sources), float64(s.caps.Source))
+	score += sourceScore
 	
 	// Component 2: Validation status (max 30 points)
-	validationScore := s.calculateValidationScore(subdomain)
+	validationScore := math.Min(s.calculateValidationScore(subdomain), float64(s.caps.Validation))
 	score += validationScore
 	
 	// Component 3: Response quality (max 20 points)
-	responseScore := s.calculateResponseScore(subdomain)
+	responseScore := math.Min(s.calculateResponseScore(subdomain), float64(s.caps.Response))
 	score += responseScore
 	
 	// Component 4: Pattern confidence (max 10 points)
-	patternScore := s.calculatePatternScore(subdomain)
+	patternScore := math.Min(s.calculatePatternScore(subdomain), float64(s.caps.Pattern))
 	score += patternScore
 	
+	// Component 5: Persistence across stored scans (max 10 points, may be negative)
+	recencyScore := s.calculateRecencyScore(ctx, subdomain)
+	score += recencyScore
+	
+	// Component 6: Prior knowledge (known-subdomains list)
+	var knownScore float64
+	if known.IsKnown(subdomain) {
+		knownScore = float64(s.knownWeight)
+		score += knownScore
+	}
+	
+	// Component 7: Default vhost penalty
+	var apexPenalty float64
+	if sameAsApex, _ := subdomain.Metadata[SameAsApexKey].(bool); sameAsApex {
+		apexPenalty = sameAsApexPenalty
+		score -= apexPenalty
+	}
+	
 	// Normalize to 0-100
-	finalScore := int(math.Min(score, 100))
+	finalScore := int(math.Max(math.Min(score, 100), 0))
 	
 	s.logger.Debug("Subdomain scored",
 		zap.String("domain", subdomain.Domain),
@@ -81,6 +215,9 @@ func (s *Scorer) Score(ctx context.Context, subdomain *types.Subdomain) int {
 		zap.Float64("validation_score", validationScore),
 		zap.Float64("response_score", responseScore),
 		zap.Float64("pattern_score", patternScore),
+		zap.Float64("recency_score", recencyScore),
+		zap.Float64("known_score", knownScore),
+		zap.Float64("apex_penalty", apexPenalty),
 	)
 	
 	return finalScore
@@ -101,9 +238,9 @@ func (s *Scorer) calculateSourceScore(sources []string) float64 {
 		}
 		seen[source] = true
 		
-		weight := s.sourceWeights[source]
-		if weight == 0 {
-			weight = 5 // Default weight for unknown sources
+		weight, ok := s.sourceWeights[source]
+		if !ok {
+			weight = s.defaultWeight
 		}
 		
 		totalWeight += float64(weight)
@@ -127,6 +264,10 @@ func (s *Scorer) calculateValidationScore(subdomain *types.Subdomain) float64 {
 		if len(subdomain.IP) > 1 {
 			score += 3
 		}
+	} else if _, ok := subdomain.Metadata["record_only"]; ok && subdomain.Validated {
+		// Mail gateways and delegated zones exist without an address, and
+		// can't earn the HTTP points below
+		score += 25
 	}
 	
 	// HTTP validation (10 points)
@@ -183,11 +324,18 @@ func (s *Scorer) calculatePatternScore(subdomain *types.Subdomain) float64 {
 	
 	var score float64
 	
-	// Common patterns are more reliable
-	if hasCommonPattern(domain) {
+	// Common patterns are more reliable, at any label position
+	categories := namingCategories(domain)
+	if len(categories) > 0 {
 		score += 5
 	}
 	
+	// Names combining env, service and geo tokens follow a deliberate
+	// scheme (api.staging.us); one point per extra category, at most 2
+	if len(categories) > 1 {
+		score += float64(min(len(categories)-1, 2))
+	}
+	
 	// Short, simple names are more likely to be real
 	parts := strings.Split(domain, ".")
 	if len(parts) > 0 && len(parts[0]) < 15 {
@@ -207,6 +355,48 @@ func (s *Scorer) calculatePatternScore(subdomain *types.Subdomain) float64 {
 	return score
 }
 
+// calculateRecencyScore rewards hosts found by many stored scans, one
+// point per scan up to the cap, and takes a few points from a host seen
+// by at most one scan whose last stored sighting is over a month old. The
+// host's own LastSeen is the current scan and says nothing about history.
+func (s *Scorer) calculateRecencyScore(ctx context.Context, subdomain *types.Subdomain) float64 {
+	if s.history == nil {
+		return 0
+	}
+	
+	seen, err := s.history.CountScansContaining(ctx, subdomain.Domain)
+	if err != nil {
+		s.logger.Debug("Scan history lookup failed",
+			zap.String("domain", subdomain.Domain),
+			zap.Error(err),
+		)
+		return 0
+	}
+	
+	if seen >= persistentScans {
+		return math.Min(float64(seen), float64(s.caps.Recency))
+	}
+	
+	if seen > 1 {
+		return 0
+	}
+	
+	lastSeen, err := s.history.LastSeenStored(ctx, subdomain.Domain)
+	if err != nil {
+		s.logger.Debug("Scan history lookup failed",
+			zap.String("domain", subdomain.Domain),
+			zap.Error(err),
+		)
+		return 0
+	}
+	
+	if !lastSeen.IsZero() && time.Since(lastSeen) > staleAfter {
+		return -stalePenalty
+	}
+	
+	return 0
+}
+
 // BatchScore scores multiple subdomains efficiently
 func (s *Scorer) BatchScore(ctx context.Context, subdomains []*types.Subdomain) {
 	for _, subdomain := range subdomains {
@@ -223,32 +413,14 @@ func (s *Scorer) BatchScore(ctx context.Context, subdomains []*types.Subdomain)
 	)
 }
 
-// hasCommonPattern checks for common subdomain patterns
-func hasCommonPattern(domain string) bool {
-	commonPatterns := []string{
-		"www", "api", "mail", "ftp", "smtp", "pop", "imap",
-		"dev", "staging", "stage", "test", "qa", "prod", "production",
-		"admin", "portal", "dashboard", "app", "mobile", "m",
-		"blog", "shop", "store", "cdn", "static", "assets",
-		"vpn", "remote", "secure", "login", "auth",
-		"us", "eu", "asia", "uk", "ca",
-	}
-	
-	parts := strings.Split(domain, ".")
-	if len(parts) == 0 {
-		return false
-	}
-	
-	first := parts[0]
-	
-	for _, pattern := range commonPatterns {
-		if first == pattern || strings.HasPrefix(first, pattern+"-") || 
-		   strings.HasSuffix(first, "-"+pattern) {
-			return true
-		}
+// namingCategories returns the tag namespaces (service, environment,
+// geo, ...) implied by the host labels of a domain
+func namingCategories(domain string) map[string]bool {
+	categories := make(map[string]bool)
+	for _, tag := range classify.LabelTags(domain) {
+		categories[classify.Namespace(tag)] = true
 	}
-	
-	return false
+	return categories
 }
 
 // hasSuspiciousPattern checks for suspicious patterns
@@ -0,0 +1,113 @@
+package scorer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// fakeHistory answers scan-history lookups from fixed values
+type fakeHistory struct {
+	scans    int
+	lastSeen time.Time
+}
+
+func (f fakeHistory) CountScansContaining(ctx context.Context, subdomain string) (int, error) {
+	return f.scans, nil
+}
+
+func (f fakeHistory) LastSeenStored(ctx context.Context, subdomain string) (time.Time, error) {
+	return f.lastSeen, nil
+}
+
+func TestRecencyScore(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		history fakeHistory
+		want    float64
+	}{
+		{"never stored", fakeHistory{}, 0},
+		{"persistent", fakeHistory{scans: 4, lastSeen: now}, 4},
+		{"persistent capped", fakeHistory{scans: 25, lastSeen: now}, 10},
+		{"two scans", fakeHistory{scans: 2, lastSeen: now.Add(-90 * 24 * time.Hour)}, 0},
+		{"single recent sighting", fakeHistory{scans: 1, lastSeen: now.Add(-24 * time.Hour)}, 0},
+		{"single stale sighting", fakeHistory{scans: 1, lastSeen: now.Add(-60 * 24 * time.Hour)}, -stalePenalty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScorer(nil, zap.NewNop())
+			s.SetHistory(tt.history)
+
+			// A fresh result is always last seen now; staleness must come
+			// from the stored sighting
+			sub := &types.Subdomain{Domain: "api.example.com", LastSeen: now}
+
+			if got := s.calculateRecencyScore(context.Background(), sub); got != tt.want {
+				t.Errorf("recency score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecencyScoreWithoutHistory(t *testing.T) {
+	s := NewScorer(nil, zap.NewNop())
+	sub := &types.Subdomain{Domain: "api.example.com", LastSeen: time.Now().Add(-365 * 24 * time.Hour)}
+
+	if got := s.calculateRecencyScore(context.Background(), sub); got != 0 {
+		t.Errorf("recency score without history = %v, want 0", got)
+	}
+}
+
+func TestPatternScoreLabelPosition(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   float64
+	}{
+		// One recognizable token scores the same at any depth
+		{"api.acme.com", 8},
+		{"x7f.staging.acme.com", 8},
+		{"node1.rack2.api.acme.com", 8},
+
+		// Each extra category (env, service, geo) adds a point, at most 2
+		{"api-staging.acme.com", 9},
+		{"api.staging.us.acme.com", 10},
+		{"api.staging.us.eu.dev.prod.acme.com", 10},
+
+		// Nothing recognizable: only the short-name point
+		{"qz8k2.acme.com", 3},
+		{"qz8k2.wq7.acme.com", 3},
+
+		// Suspicious names lose their points but never go negative
+		{"random.acme.com", 0},
+		{"random.api.acme.com", 3},
+	}
+
+	s := NewScorer(nil, zap.NewNop())
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			got := s.calculatePatternScore(&types.Subdomain{Domain: tt.domain})
+			if got != tt.want {
+				t.Errorf("pattern score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructuredNameOutscoresRandom(t *testing.T) {
+	s := NewScorer(nil, zap.NewNop())
+	ctx := context.Background()
+
+	structured := &types.Subdomain{Domain: "api.staging.us.acme.com", Sources: []string{"crtsh"}}
+	random := &types.Subdomain{Domain: "k2j9x.q8z.w3.acme.com", Sources: []string{"crtsh"}}
+
+	if s.Score(ctx, structured) <= s.Score(ctx, random) {
+		t.Errorf("structured name scored %d, random name %d; want structured higher",
+			s.Score(ctx, structured), s.Score(ctx, random))
+	}
+}
@@ -0,0 +1,328 @@
+package whois
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// ianaWhoisServer answers every TLD with a referral to its registry
+const ianaWhoisServer = "whois.iana.org:43"
+
+// Client fetches registration data for apex domains via RDAP, falling back
+// to port-43 WHOIS when RDAP is unavailable. Results are cached per apex.
+type Client struct {
+	rdapURL string
+	timeout time.Duration
+	client  *http.Client
+	logger  *zap.Logger
+
+	cache   map[string]*types.Registration
+	cacheMu sync.Mutex
+}
+
+// rdapResponse represents the subset of an RDAP domain object we use
+type rdapResponse struct {
+	LDHName     string       `json:"ldhName"`
+	Events      []rdapEvent  `json:"events"`
+	Entities    []rdapEntity `json:"entities"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+}
+
+type rdapEvent struct {
+	Action string `json:"eventAction"`
+	Date   string `json:"eventDate"`
+}
+
+type rdapEntity struct {
+	Roles      []string        `json:"roles"`
+	VCardArray json.RawMessage `json:"vcardArray"`
+	Entities   []rdapEntity    `json:"entities"`
+}
+
+// NewClient creates a new registration lookup client
+func NewClient(rdapURL string, timeout time.Duration, logger *zap.Logger) *Client {
+	return &Client{
+		rdapURL: strings.TrimSuffix(rdapURL, "/"),
+		timeout: timeout,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		logger: logger,
+		cache:  make(map[string]*types.Registration),
+	}
+}
+
+// Lookup returns registration data for the apex domain
+func (c *Client) Lookup(ctx context.Context, domain string) (*types.Registration, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	c.cacheMu.Lock()
+	if reg, ok := c.cache[domain]; ok {
+		c.cacheMu.Unlock()
+		return reg, nil
+	}
+	c.cacheMu.Unlock()
+
+	reg, err := c.lookupRDAP(ctx, domain)
+	if err != nil {
+		c.logger.Debug("RDAP lookup failed, falling back to WHOIS",
+			zap.String("domain", domain),
+			zap.Error(err),
+		)
+
+		reg, err = c.lookupWhois(ctx, domain)
+		if err != nil {
+			return nil, fmt.Errorf("registration lookup failed for %s: %w", domain, err)
+		}
+	}
+
+	c.cacheMu.Lock()
+	c.cache[domain] = reg
+	c.cacheMu.Unlock()
+
+	return reg, nil
+}
+
+// lookupRDAP queries the RDAP bootstrap service, which redirects to the
+// authoritative registry
+func (c *Client) lookupRDAP(ctx context.Context, domain string) (*types.Registration, error) {
+	url := fmt.Sprintf("%s/domain/%s", c.rdapURL, domain)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/rdap+json")
+	req.Header.Set("User-Agent", "USR/1.0 (Universal Subdomain Reconnaissance)")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RDAP returned status %d", resp.StatusCode)
+	}
+
+	var data rdapResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode RDAP response: %w", err)
+	}
+
+	reg := &types.Registration{
+		Domain: domain,
+		Source: "rdap",
+	}
+
+	for _, event := range data.Events {
+		date, err := time.Parse(time.RFC3339, event.Date)
+		if err != nil {
+			continue
+		}
+		switch event.Action {
+		case "registration":
+			reg.Created = date
+		case "last changed":
+			reg.Updated = date
+		case "expiration":
+			reg.Expires = date
+		}
+	}
+
+	for _, ns := range data.Nameservers {
+		if ns.LDHName != "" {
+			reg.Nameservers = append(reg.Nameservers, strings.ToLower(strings.TrimSuffix(ns.LDHName, ".")))
+		}
+	}
+
+	reg.Registrar = entityName(data.Entities, "registrar")
+	reg.Registrant = entityName(data.Entities, "registrant")
+
+	return reg, nil
+}
+
+// entityName returns the organization (or full name) of the first entity
+// holding the given role, searching nested entities as well
+func entityName(entities []rdapEntity, role string) string {
+	for _, entity := range entities {
+		for _, r := range entity.Roles {
+			if r != role {
+				continue
+			}
+			if name := vcardName(entity.VCardArray); name != "" {
+				return name
+			}
+		}
+		if name := entityName(entity.Entities, role); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// vcardName extracts "org" or "fn" from a jCard array
+// (["vcard", [["fn", {}, "text", "Example Inc"], ...]])
+func vcardName(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var card []interface{}
+	if err := json.Unmarshal(raw, &card); err != nil || len(card) < 2 {
+		return ""
+	}
+
+	props, ok := card[1].([]interface{})
+	if !ok {
+		return ""
+	}
+
+	var fn string
+	for _, p := range props {
+		prop, ok := p.([]interface{})
+		if !ok || len(prop) < 4 {
+			continue
+		}
+		name, _ := prop[0].(string)
+		value, _ := prop[3].(string)
+		switch name {
+		case "org":
+			if value != "" {
+				return value
+			}
+		case "fn":
+			fn = value
+		}
+	}
+
+	return fn
+}
+
+// lookupWhois follows the IANA referral to the registry WHOIS server
+func (c *Client) lookupWhois(ctx context.Context, domain string) (*types.Registration, error) {
+	tld := domain[strings.LastIndex(domain, ".")+1:]
+
+	referral, err := c.queryWhois(ctx, ianaWhoisServer, tld)
+	if err != nil {
+		return nil, err
+	}
+
+	server := whoisField(referral, "refer", "whois")
+	if server == "" {
+		return nil, fmt.Errorf("no WHOIS server found for .%s", tld)
+	}
+
+	response, err := c.queryWhois(ctx, net.JoinHostPort(server, "43"), domain)
+	if err != nil {
+		return nil, err
+	}
+
+	reg := &types.Registration{
+		Domain:     domain,
+		Registrar:  whoisField(response, "Registrar", "registrar"),
+		Registrant: whoisField(response, "Registrant Organization", "Registrant Name", "org"),
+		Source:     "whois",
+	}
+
+	reg.Created = parseWhoisDate(whoisField(response, "Creation Date", "created", "Registered on"))
+	reg.Updated = parseWhoisDate(whoisField(response, "Updated Date", "last-update", "Last updated"))
+	reg.Expires = parseWhoisDate(whoisField(response, "Registry Expiry Date", "Registrar Registration Expiration Date", "Expiry date", "expires"))
+
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(response, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if !strings.EqualFold(key, "Name Server") && !strings.EqualFold(key, "nserver") {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		ns := strings.ToLower(strings.TrimSuffix(fields[0], "."))
+		if !seen[ns] {
+			seen[ns] = true
+			reg.Nameservers = append(reg.Nameservers, ns)
+		}
+	}
+
+	return reg, nil
+}
+
+// queryWhois sends a single WHOIS query and returns the raw response
+func (c *Client) queryWhois(ctx context.Context, server, query string) (string, error) {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(c.timeout))
+
+	if _, err := fmt.Fprintf(conn, "%s\r\n", query); err != nil {
+		return "", fmt.Errorf("failed to send WHOIS query: %w", err)
+	}
+
+	var sb strings.Builder
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		sb.WriteString(scanner.Text())
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), scanner.Err()
+}
+
+// whoisField returns the value of the first matching "Key: value" line
+func whoisField(response string, keys ...string) string {
+	for _, want := range keys {
+		for _, line := range strings.Split(response, "\n") {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+			if ok && strings.EqualFold(strings.TrimSpace(key), want) {
+				if value = strings.TrimSpace(value); value != "" {
+					return value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// parseWhoisDate tries the date layouts commonly used by registries
+func parseWhoisDate(value string) time.Time {
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05Z",
+		"2006-01-02T15:04:05.0Z",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+		"02-Jan-2006",
+		"02.01.2006",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
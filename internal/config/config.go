@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -12,9 +13,19 @@ type Config struct {
 	// Core settings
 	LogLevel    string `mapstructure:"log_level"`
 	LogFile     string `mapstructure:"log_file"`
+	
+	// High-volume debug lines (one per discovered subdomain) are sampled:
+	// per second, the first LogSampleFirst, then every LogSampleThereafter-th
+	// (first 0 = log every line)
+	LogSampleFirst      int `mapstructure:"log_sample_first"`
+	LogSampleThereafter int `mapstructure:"log_sample_thereafter"`
 	ScanMode    string `mapstructure:"scan_mode"`
 	OutputDir   string `mapstructure:"output_dir"`
 	
+	// Offline skips every network source and check, working only from
+	// results stored by earlier scans (re-scoring, re-exporting, diffing)
+	Offline bool `mapstructure:"offline"`
+	
 	// Concurrency
 	MaxThreads      int `mapstructure:"max_threads"`
 	DNSWorkers      int `mapstructure:"dns_workers"`
@@ -29,19 +40,78 @@ type Config struct {
 	// Sources Configuration
 	Sources SourcesConfig `mapstructure:"sources"`
 	
+	// Scope
+	Scope ScopeConfig `mapstructure:"scope"`
+	
 	// Validation
 	Validation ValidationConfig `mapstructure:"validation"`
 	
+	// Confidence scoring
+	Scoring ScoringConfig `mapstructure:"scoring"`
+	
 	// Storage
 	Storage StorageConfig `mapstructure:"storage"`
+	
+	// WHOIS/RDAP enrichment
+	Whois WhoisConfig `mapstructure:"whois"`
+	
+	// Deduplication
+	Dedup DedupConfig `mapstructure:"dedup"`
+	
+	// Stealth mode behavior (scan_mode: stealth)
+	Stealth StealthConfig `mapstructure:"stealth"`
+	
+	// HTTP probing
+	HTTP HTTPConfig `mapstructure:"http"`
+	
+	// Cloud bucket checks
+	Cloud CloudConfig `mapstructure:"cloud"`
+	
+	// Export filtering
+	Output OutputConfig `mapstructure:"output"`
 }
 
 type DNSConfig struct {
-	Resolvers       []string `mapstructure:"resolvers"`
-	Timeout         int      `mapstructure:"timeout"`
-	Retries         int      `mapstructure:"retries"`
-	RateLimit       int      `mapstructure:"rate_limit"`
-	WildcardTests   int      `mapstructure:"wildcard_tests"`
+	Resolvers       []string            `mapstructure:"resolvers"`
+	ResolversFile   string              `mapstructure:"resolvers_file"` // overrides resolvers when set
+	ResolversV4     []string            `mapstructure:"resolvers_v4"`   // A queries; empty uses resolvers
+	ResolversV6     []string            `mapstructure:"resolvers_v6"`   // AAAA queries; empty uses resolvers
+	Protocol        string              `mapstructure:"protocol"`       // udp, tcp, dot, doh
+	Timeout         int                 `mapstructure:"timeout"`
+	Retries         int                 `mapstructure:"retries"`
+	RateLimit       int                 `mapstructure:"rate_limit"`
+	WildcardTests   int                 `mapstructure:"wildcard_tests"`
+	WildcardTTL     int                 `mapstructure:"wildcard_ttl"` // hours a stored detection is reused (0 = always probe)
+	CNAMEDepth      int                 `mapstructure:"cname_depth"`  // CNAME hops followed at most
+	WildcardParents int                 `mapstructure:"wildcard_parents"` // parents below the target tested for nested wildcards (0 = apex only)
+	QueryTypes      DNSQueryTypesConfig `mapstructure:"query_types"`
+	Autotune        DNSAutotuneConfig   `mapstructure:"autotune"`
+	Health          DNSHealthConfig     `mapstructure:"health"`
+}
+
+// DNSHealthConfig takes resolvers that keep failing out of rotation for a
+// while, so a dead resolver doesn't cost every Nth query its full timeout
+type DNSHealthConfig struct {
+	EjectAfter int `mapstructure:"eject_after"` // consecutive failures before ejection (0 = never eject)
+	Cooldown   int `mapstructure:"cooldown"`    // seconds an ejected resolver sits out
+}
+
+// DNSAutotuneConfig adapts batch resolution concurrency to resolver errors.
+// The configured worker count becomes the starting point; concurrency grows
+// while timeouts/SERVFAILs are rare and backs off when they climb.
+type DNSAutotuneConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	MinWorkers int  `mapstructure:"min_workers"`
+	MaxWorkers int  `mapstructure:"max_workers"`
+}
+
+// DNSQueryTypesConfig selects which record types each phase queries.
+// Fewer types make a phase faster; more types collect richer records.
+type DNSQueryTypesConfig struct {
+	Wildcard   []string `mapstructure:"wildcard"`
+	Bruteforce []string `mapstructure:"bruteforce"`
+	Validation []string `mapstructure:"validation"`
+	Enrichment []string `mapstructure:"enrichment"` // extra types for validated hosts, e.g. MX, NS, TXT
 }
 
 type AIConfig struct {
@@ -54,19 +124,33 @@ type AIConfig struct {
 }
 
 type SourcesConfig struct {
+	Timeout  int                   `mapstructure:"timeout"` // seconds a single source may run (0 = no limit)
 	Passive  PassiveSourcesConfig  `mapstructure:"passive"`
 	Active   ActiveSourcesConfig   `mapstructure:"active"`
 	Web      WebSourcesConfig      `mapstructure:"web"`
 }
 
+// ScopeConfig decides which names belong to a scan
+type ScopeConfig struct {
+	// KeyMode is host (the target and names below it, the default) or
+	// registrable (everything under the target's registrable domain, so
+	// scanning app.example.com also reports example.com and api.example.com)
+	KeyMode string `mapstructure:"key_mode"`
+}
+
 type PassiveSourcesConfig struct {
 	CertificateTransparency bool     `mapstructure:"certificate_transparency"`
 	VirusTotal              bool     `mapstructure:"virustotal"`
 	PassiveDNS              bool     `mapstructure:"passive_dns"`
 	WaybackMachine          bool     `mapstructure:"wayback_machine"`
 	CommonCrawl             bool     `mapstructure:"common_crawl"`
+	CommonCrawlIndexes      int      `mapstructure:"common_crawl_indexes"` // most recent crawls queried
+	CommonCrawlTimeout      int      `mapstructure:"common_crawl_timeout"` // seconds for the whole source
 	GitHub                  bool     `mapstructure:"github"`
 	Shodan                  bool     `mapstructure:"shodan"`
+	ShodanAPIKey            string   `mapstructure:"shodan_api_key"`
+	SPFDMARC                bool     `mapstructure:"spf_dmarc"`
+	SPFDepth                int      `mapstructure:"spf_depth"` // levels of included SPF policies followed
 	APIs                    []string `mapstructure:"apis"`
 }
 
@@ -75,6 +159,18 @@ type ActiveSourcesConfig struct {
 	Recursive     bool     `mapstructure:"recursive"`
 	Permutations  bool     `mapstructure:"permutations"`
 	Wordlists     []string `mapstructure:"wordlists"`
+	MaxCandidates int      `mapstructure:"max_candidates"` // cap on generated permutations and bucket names (0 = no cap)
+	Workers       int      `mapstructure:"workers"`        // concurrent resolutions per active source
+	
+	// Generated candidates are dropped before resolution if any label is
+	// shorter than MinLabelLength or uses characters outside AllowedCharset
+	// (ranges allowed, e.g. "a-z0-9-"; empty allows any valid label)
+	MinLabelLength int    `mapstructure:"min_label_length"`
+	AllowedCharset string `mapstructure:"allowed_charset"`
+	
+	// Brute force resolves its wordlist in chunks of ChunkSize, saving the
+	// position under storage.cache_dir after each so --resume can continue
+	ChunkSize int `mapstructure:"chunk_size"`
 }
 
 type WebSourcesConfig struct {
@@ -82,6 +178,22 @@ type WebSourcesConfig struct {
 	JSParsing     bool `mapstructure:"js_parsing"`
 	CloudAssets   bool `mapstructure:"cloud_assets"`
 	LinkCrawling  bool `mapstructure:"link_crawling"`
+	SitemapRobots bool `mapstructure:"sitemap_robots"`
+	
+	// JS files fetched at once per domain, and bytes read across a
+	// domain's JS files before the rest are skipped (0 = no limit)
+	JSWorkers  int   `mapstructure:"js_workers"`
+	JSMaxBytes int64 `mapstructure:"js_max_bytes"`
+	
+	// Link crawling from each alive host: how many links deep to follow,
+	// pages fetched per host, and whether robots.txt Disallow rules are
+	// obeyed
+	CrawlDepth    int  `mapstructure:"crawl_depth"`
+	CrawlMaxPages int  `mapstructure:"crawl_max_pages"`
+	CrawlRobots   bool `mapstructure:"crawl_robots"`
+	
+	// Levels of nested sitemap indexes followed
+	SitemapDepth int `mapstructure:"sitemap_depth"`
 }
 
 type ValidationConfig struct {
@@ -89,6 +201,57 @@ type ValidationConfig struct {
 	HTTPValidation bool `mapstructure:"http_validation"`
 	TLSValidation  bool `mapstructure:"tls_validation"`
 	MinConfidence  int  `mapstructure:"min_confidence"`
+	
+	// Pipelined runs DNS -> HTTP -> TLS per host in one worker (max_threads)
+	// instead of separate batch phases
+	Pipelined bool `mapstructure:"pipelined"`
+	
+	// PrivateIPs filters hosts resolving to private/reserved addresses:
+	// include (default), exclude, only
+	PrivateIPs string `mapstructure:"private_ips"`
+	
+	// KeepUnvalidated reports unresolved and below-threshold hosts, tagged
+	// with a status, instead of dropping them
+	KeepUnvalidated bool `mapstructure:"keep_unvalidated"`
+	
+	// ExcludeParked drops hosts serving parking/placeholder pages
+	ExcludeParked bool `mapstructure:"exclude_parked"`
+	
+	// RecordOnly validates hosts without A/AAAA that have MX, NS or TXT
+	// records (mail gateways, delegated zones, SPF targets), tagged
+	// type:mail, type:ns or type:txt
+	RecordOnly bool `mapstructure:"record_only"`
+	
+	// SANDiscovery reads the certificate of every resolved host (port 443)
+	// and adds in-scope names from its SANs (source tls_san)
+	SANDiscovery bool `mapstructure:"san_discovery"`
+	
+	// KnownFile lists subdomains known to exist (one per line); found
+	// ones get KnownWeight added to their confidence, missing ones are
+	// reported
+	KnownFile   string `mapstructure:"known_file"`
+	KnownWeight int    `mapstructure:"known_weight"`
+}
+
+// ScoringConfig tunes the confidence scorer without recompiling
+type ScoringConfig struct {
+	// SourceWeights overrides or extends the built-in per-source weights
+	SourceWeights map[string]int `mapstructure:"source_weights"`
+	
+	// DefaultWeight applies to sources with no weight of their own
+	DefaultWeight int `mapstructure:"default_weight"`
+	
+	// Components caps each part of the score
+	Components ScoringComponentsConfig `mapstructure:"components"`
+}
+
+// ScoringComponentsConfig holds the maximum points of each score component
+type ScoringComponentsConfig struct {
+	Source     int `mapstructure:"source"`
+	Validation int `mapstructure:"validation"`
+	Response   int `mapstructure:"response"`
+	Pattern    int `mapstructure:"pattern"`
+	Recency    int `mapstructure:"recency"` // boost for hosts found by many stored scans
 }
 
 type StorageConfig struct {
@@ -97,6 +260,115 @@ type StorageConfig struct {
 	CacheDir string `mapstructure:"cache_dir"`
 }
 
+type WhoisConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	RDAPURL string `mapstructure:"rdap_url"` // RDAP bootstrap service
+	Timeout int    `mapstructure:"timeout"`
+}
+
+// DedupConfig controls fuzzy collapsing of near-duplicate hosts.
+// Collapsing shrinks reports of numbered fleets (web01..web50) and of hosts
+// serving one wildcard or parking page to representatives, at the cost of
+// hiding hosts that may differ.
+type DedupConfig struct {
+	RemoveSimilar       bool    `mapstructure:"remove_similar"`
+	SimilarityThreshold float64 `mapstructure:"similarity_threshold"` // 0.0-1.0, 1.0 keeps all
+	HTTPMerge           string  `mapstructure:"http_merge"`           // best, first
+	CollapseWWW         bool    `mapstructure:"collapse_www"`         // merge www.X into X when both resolve to the same IPs
+}
+
+// StealthConfig tunes how quietly stealth mode operates
+type StealthConfig struct {
+	MaxConcurrency int      `mapstructure:"max_concurrency"`
+	JitterMin      int      `mapstructure:"jitter_min"` // milliseconds
+	JitterMax      int      `mapstructure:"jitter_max"` // milliseconds
+	UserAgents     []string `mapstructure:"user_agents"` // empty = built-in browser pool
+}
+
+type HTTPConfig struct {
+	Timeout      int `mapstructure:"timeout"` // seconds, whole request including body
+	MaxRedirects int `mapstructure:"max_redirects"` // redirects followed per probe; the chain is recorded
+	
+	// Per-stage limits so a slow handshake fails fast instead of using the
+	// whole timeout (seconds, 0 = bounded only by timeout)
+	DialTimeout           int `mapstructure:"dial_timeout"`
+	TLSHandshakeTimeout   int `mapstructure:"tls_handshake_timeout"`
+	ResponseHeaderTimeout int `mapstructure:"response_header_timeout"`
+	
+	// HTTP2 negotiates HTTP/2 over TLS where the server supports it
+	HTTP2 bool `mapstructure:"http2"`
+	
+	// UserAgent replaces the default (and stealth mode's rotating)
+	// User-Agent; Headers are added to every probe, a Host entry setting
+	// the virtual host requested
+	UserAgent string            `mapstructure:"user_agent"`
+	Headers   map[string]string `mapstructure:"headers"`
+	
+	RecheckTTL   int `mapstructure:"recheck_ttl"` // seconds; stored results newer than this are reused (0 = always probe)
+	
+	// HeaderDiscovery adds in-scope hosts named in Location, CSP,
+	// Access-Control-Allow-Origin and Link headers (source http_headers)
+	HeaderDiscovery bool `mapstructure:"header_discovery"`
+	
+	// APISpecPaths are checked for an OpenAPI/Swagger spec on hosts that
+	// answer with JSON (empty = don't look)
+	APISpecPaths []string `mapstructure:"api_spec_paths"`
+	
+	// Fingerprints are Wappalyzer-format JSON files of technology rules
+	// added to the built-in ones
+	Fingerprints []string `mapstructure:"fingerprints"`
+	
+	// Favicon fetches /favicon.ico from hosts that answer and records its
+	// Shodan-style hash
+	Favicon bool `mapstructure:"favicon"`
+	
+	// Paths checks alive hosts for exposed dev/admin paths
+	Paths PathsConfig `mapstructure:"paths"`
+	
+	// Screenshots captures hosts answering 200 with a headless browser
+	Screenshots ScreenshotConfig `mapstructure:"screenshots"`
+}
+
+// ScreenshotConfig controls PNG captures of hosts that answered HTTP 200.
+// Each capture starts a headless Chrome/Chromium, so it is off by default.
+type ScreenshotConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Browser string `mapstructure:"browser"` // chrome/chromium binary (empty = look it up in PATH)
+	Dir     string `mapstructure:"dir"`     // empty = <output_dir>/screenshots
+	Timeout int    `mapstructure:"timeout"` // seconds per capture
+	Workers int    `mapstructure:"workers"` // browsers running at once
+	
+	// NoSandbox starts the browser with --no-sandbox. Chrome refuses to
+	// run sandboxed as root and in many containers lacking user
+	// namespaces; only turn it off there, since pages then render without
+	// the sandbox's protection.
+	NoSandbox bool `mapstructure:"no_sandbox"`
+}
+
+// PathsConfig controls probing of high-signal paths (/.git/HEAD, /.env,
+// /actuator, ...) on hosts that answered HTTP
+type PathsConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	List       []string `mapstructure:"list"`
+	Workers    int      `mapstructure:"workers"`      // hosts checked at once
+	MaxPerHost int      `mapstructure:"max_per_host"` // request cap per host, including the soft-404 check
+}
+
+// CloudConfig bounds active checks against cloud storage providers
+type CloudConfig struct {
+	Workers     int `mapstructure:"workers"`      // candidate buckets checked at once
+	Timeout     int `mapstructure:"timeout"`      // seconds per request
+	Retries     int `mapstructure:"retries"`      // attempts per bucket after throttling (503 SlowDown, 429)
+	NegativeTTL int `mapstructure:"negative_ttl"` // hours a missing bucket is remembered across runs (0 = don't cache)
+}
+
+// OutputConfig narrows what is exported; storage always keeps every result
+type OutputConfig struct {
+	// StatusCodes keeps only hosts whose HTTP probe returned one of these
+	// statuses (empty = no filter)
+	StatusCodes []int `mapstructure:"status_codes"`
+}
+
 // Load reads configuration from file or creates default config
 func Load(configFile string) (*Config, error) {
 	v := viper.New()
@@ -104,6 +376,12 @@ func Load(configFile string) (*Config, error) {
 	// Set defaults
 	setDefaults(v)
 	
+	// Environment overrides any setting: USR_ plus the key with dots as
+	// underscores, e.g. USR_SCAN_MODE=active or USR_DNS_TIMEOUT=10
+	v.SetEnvPrefix("USR")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	
 	// Determine config file location
 	if configFile != "" {
 		v.SetConfigFile(configFile)
@@ -155,8 +433,11 @@ func setDefaults(v *viper.Viper) {
 	// Core
 	v.SetDefault("log_level", "info")
 	v.SetDefault("log_file", "")
+	v.SetDefault("log_sample_first", 100)
+	v.SetDefault("log_sample_thereafter", 100)
 	v.SetDefault("scan_mode", "passive")
 	v.SetDefault("output_dir", "./output")
+	v.SetDefault("offline", false)
 	
 	// Concurrency
 	v.SetDefault("max_threads", 50)
@@ -164,10 +445,26 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("http_workers", 50)
 	
 	// DNS
+	v.SetDefault("dns.resolvers_file", "")
+	v.SetDefault("dns.resolvers_v4", []string{})
+	v.SetDefault("dns.resolvers_v6", []string{})
+	v.SetDefault("dns.protocol", "udp")
 	v.SetDefault("dns.timeout", 5)
 	v.SetDefault("dns.retries", 2)
 	v.SetDefault("dns.rate_limit", 100)
 	v.SetDefault("dns.wildcard_tests", 5)
+	v.SetDefault("dns.wildcard_ttl", 24)
+	v.SetDefault("dns.cname_depth", 10)
+	v.SetDefault("dns.wildcard_parents", 50)
+	v.SetDefault("dns.query_types.wildcard", []string{"A", "AAAA"})
+	v.SetDefault("dns.query_types.bruteforce", []string{"A"})
+	v.SetDefault("dns.query_types.validation", []string{"A", "AAAA", "CNAME"})
+	v.SetDefault("dns.query_types.enrichment", []string{})
+	v.SetDefault("dns.autotune.enabled", false)
+	v.SetDefault("dns.autotune.min_workers", 10)
+	v.SetDefault("dns.autotune.max_workers", 500)
+	v.SetDefault("dns.health.eject_after", 5)
+	v.SetDefault("dns.health.cooldown", 30)
 	v.SetDefault("dns.resolvers", []string{
 		"8.8.8.8",
 		"8.8.4.4",
@@ -183,36 +480,126 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("ai.max_tokens", 1000)
 	v.SetDefault("ai.prompt_version", "v1")
 	
+	// Sources
+	v.SetDefault("sources.timeout", 300)
+	
 	// Passive Sources
 	v.SetDefault("sources.passive.certificate_transparency", true)
 	v.SetDefault("sources.passive.virustotal", true)
 	v.SetDefault("sources.passive.passive_dns", true)
 	v.SetDefault("sources.passive.wayback_machine", true)
 	v.SetDefault("sources.passive.common_crawl", false)
+	v.SetDefault("sources.passive.common_crawl_indexes", 3)
+	v.SetDefault("sources.passive.common_crawl_timeout", 120)
 	v.SetDefault("sources.passive.github", false)
 	v.SetDefault("sources.passive.shodan", false)
+	v.SetDefault("sources.passive.shodan_api_key", "")
+	v.SetDefault("sources.passive.spf_dmarc", true)
+	v.SetDefault("sources.passive.spf_depth", 0)
 	
 	// Active Sources
 	v.SetDefault("sources.active.dns_bruteforce", false)
 	v.SetDefault("sources.active.recursive", false)
 	v.SetDefault("sources.active.permutations", false)
+	v.SetDefault("sources.active.max_candidates", 10000)
+	v.SetDefault("sources.active.workers", 20)
+	v.SetDefault("sources.active.min_label_length", 2)
+	v.SetDefault("sources.active.allowed_charset", "a-z0-9-")
+	v.SetDefault("sources.active.chunk_size", 5000)
 	
 	// Web Sources
 	v.SetDefault("sources.web.http_probing", true)
 	v.SetDefault("sources.web.js_parsing", false)
+	v.SetDefault("sources.web.js_workers", 5)
+	v.SetDefault("sources.web.js_max_bytes", 20*1024*1024)
 	v.SetDefault("sources.web.cloud_assets", true)
 	v.SetDefault("sources.web.link_crawling", false)
+	v.SetDefault("sources.web.crawl_depth", 2)
+	v.SetDefault("sources.web.crawl_max_pages", 50)
+	v.SetDefault("sources.web.crawl_robots", false)
+	v.SetDefault("sources.web.sitemap_robots", false)
+	v.SetDefault("sources.web.sitemap_depth", 2)
+	
+	// Scope
+	v.SetDefault("scope.key_mode", "host")
 	
 	// Validation
 	v.SetDefault("validation.dns_validation", true)
 	v.SetDefault("validation.http_validation", true)
 	v.SetDefault("validation.tls_validation", false)
 	v.SetDefault("validation.min_confidence", 50)
+	v.SetDefault("validation.pipelined", false)
+	v.SetDefault("validation.private_ips", "include")
+	v.SetDefault("validation.keep_unvalidated", false)
+	v.SetDefault("validation.exclude_parked", false)
+	v.SetDefault("validation.record_only", true)
+	v.SetDefault("validation.san_discovery", true)
+	v.SetDefault("validation.known_file", "")
+	v.SetDefault("validation.known_weight", 20)
+	
+	// Scoring
+	v.SetDefault("scoring.source_weights", map[string]int{})
+	v.SetDefault("scoring.default_weight", 5)
+	v.SetDefault("scoring.components.source", 40)
+	v.SetDefault("scoring.components.validation", 30)
+	v.SetDefault("scoring.components.response", 20)
+	v.SetDefault("scoring.components.pattern", 10)
+	v.SetDefault("scoring.components.recency", 10)
 	
 	// Storage
 	v.SetDefault("storage.engine", "sqlite")
 	v.SetDefault("storage.path", "./data/usr.db")
 	v.SetDefault("storage.cache_dir", "./cache")
+	
+	// WHOIS/RDAP
+	v.SetDefault("whois.enabled", true)
+	v.SetDefault("whois.rdap_url", "https://rdap.org")
+	v.SetDefault("whois.timeout", 15)
+	
+	// Deduplication
+	v.SetDefault("dedup.remove_similar", false)
+	v.SetDefault("dedup.similarity_threshold", 0.85)
+	v.SetDefault("dedup.http_merge", "best")
+	v.SetDefault("dedup.collapse_www", true)
+	
+	// Stealth
+	v.SetDefault("stealth.max_concurrency", 3)
+	v.SetDefault("stealth.jitter_min", 500)
+	v.SetDefault("stealth.jitter_max", 3000)
+	
+	// HTTP
+	v.SetDefault("http.timeout", 10)
+	v.SetDefault("http.max_redirects", 3)
+	v.SetDefault("http.dial_timeout", 5)
+	v.SetDefault("http.tls_handshake_timeout", 5)
+	v.SetDefault("http.response_header_timeout", 8)
+	v.SetDefault("http.http2", true)
+	v.SetDefault("http.user_agent", "")
+	v.SetDefault("http.headers", map[string]string{})
+	v.SetDefault("http.recheck_ttl", 0)
+	v.SetDefault("http.header_discovery", true)
+	v.SetDefault("http.api_spec_paths", []string{"/swagger.json", "/openapi.json", "/v3/api-docs"})
+	v.SetDefault("http.fingerprints", []string{})
+	v.SetDefault("http.favicon", true)
+	v.SetDefault("http.paths.enabled", false)
+	v.SetDefault("http.paths.list", []string{"/.git/HEAD", "/.env", "/admin", "/swagger.json", "/actuator"})
+	v.SetDefault("http.paths.workers", 10)
+	v.SetDefault("http.paths.max_per_host", 10)
+	v.SetDefault("http.screenshots.enabled", false)
+	v.SetDefault("http.screenshots.browser", "")
+	v.SetDefault("http.screenshots.dir", "")
+	v.SetDefault("http.screenshots.timeout", 20)
+	v.SetDefault("http.screenshots.workers", 4)
+	v.SetDefault("http.screenshots.no_sandbox", false)
+	
+	// Cloud
+	v.SetDefault("cloud.workers", 20)
+	v.SetDefault("cloud.timeout", 10)
+	v.SetDefault("cloud.retries", 3)
+	v.SetDefault("cloud.negative_ttl", 24)
+	
+	// Output
+	v.SetDefault("output.status_codes", []int{})
 }
 
 func createDefaultConfig(path string) error {
@@ -222,8 +609,15 @@ func createDefaultConfig(path string) error {
 # Core Settings
 log_level: info
 log_file: ""
+# At debug level each discovered subdomain is logged; per second the first
+# log_sample_first lines are kept, then every log_sample_thereafter-th
+# (log_sample_first: 0 keeps all)
+log_sample_first: 100
+log_sample_thereafter: 100
 scan_mode: passive
 output_dir: ./output
+# Work only from stored results, without touching the network
+offline: false
 
 # Concurrency
 max_threads: 50
@@ -237,10 +631,52 @@ dns:
     - 8.8.4.4
     - 1.1.1.1
     - 1.0.0.1
+  # One resolver per line, e.g. the output of "usr resolvers benchmark"
+  resolvers_file: ""
+  # Separate pools for A and AAAA queries, for resolvers that answer one
+  # family unreliably. Empty uses the resolvers above.
+  resolvers_v4: []
+  resolvers_v6: []
+  # udp, tcp, dot (DNS-over-TLS on port 853) or doh (DNS-over-HTTPS, for
+  # networks that block port 53). For dot, pin the TLS name per resolver
+  # with "#", e.g. 1.1.1.1:853#cloudflare-dns.com. For doh, list endpoint
+  # URLs, e.g. https://cloudflare-dns.com/dns-query (a bare host gets
+  # /dns-query)
+  protocol: udp
   timeout: 5
   retries: 2
   rate_limit: 100
   wildcard_tests: 5
+  # Hours a stored wildcard detection is reused instead of probing again
+  # (needs storage; redetected when resolvers or apex records change, 0 = always probe)
+  wildcard_ttl: 24
+  # Parents of resolved names (dev.example.com for foo.dev.example.com) also
+  # tested for wildcards such as *.dev.example.com, most populated first
+  # (0 = test the target only)
+  wildcard_parents: 50
+  # CNAME hops followed when a query type includes CNAME; the whole chain
+  # is recorded, ending with the canonical name
+  cname_depth: 10
+  # Record types queried per phase (A, AAAA, CNAME, MX, NS, TXT)
+  query_types:
+    # AAAA too: some CDNs answer wildcard AAAA but not A
+    wildcard: [A, AAAA]
+    bruteforce: [A]
+    validation: [A, AAAA, CNAME]
+    # Looked up for validated hosts only, after validation, e.g. [MX, NS, TXT]
+    # (TXT shows SPF policies naming mail senders); empty skips the queries
+    enrichment: []
+  # Adapt batch concurrency to resolver errors (workers settings become the
+  # starting point)
+  autotune:
+    enabled: false
+    min_workers: 10
+    max_workers: 500
+  # Take a resolver out of rotation after eject_after consecutive failures
+  # (timeouts, SERVFAIL, REFUSED) for cooldown seconds (eject_after: 0 = never)
+  health:
+    eject_after: 5
+    cooldown: 30
 
 # AI Configuration (Local Ollama)
 ai:
@@ -253,28 +689,71 @@ ai:
 
 # Sources Configuration
 sources:
+  # Seconds a single source may run before it is abandoned and recorded as
+  # timed out, so one hung source can't stall the scan (0 = no limit)
+  timeout: 300
   passive:
     certificate_transparency: true
     virustotal: true
     passive_dns: true
     wayback_machine: true
     common_crawl: false
+    # CommonCrawl queries the newest common_crawl_indexes crawls and keeps
+    # whatever it found when common_crawl_timeout (seconds) runs out
+    common_crawl_indexes: 3
+    common_crawl_timeout: 120
     github: false
     shodan: false
+    # Shodan stays disabled without a key; USR_SOURCES_PASSIVE_SHODAN_API_KEY
+    # keeps it out of this file
+    shodan_api_key: ""
+    # Hosts named by the target's SPF include:/redirect= and DMARC rua/ruf
+    # addresses; spf_depth levels of included policies are parsed too
+    spf_dmarc: true
+    spf_depth: 0
     apis: []
   
   active:
     dns_bruteforce: false
     recursive: false
+    # Also guesses bucket names from the apex when sources.web.cloud_assets is on
     permutations: false
+    # Upper bound on generated permutation candidates, subdomains and bucket
+    # names alike (0 = no cap)
+    max_candidates: 10000
+    # Concurrent resolutions per active source, independent of dns_workers
+    # (validation); all queries still share dns.rate_limit
+    workers: 20
+    # Generated candidates with a label shorter than this, or using other
+    # characters than allowed_charset, are dropped before resolution
+    min_label_length: 2
+    allowed_charset: "a-z0-9-"
+    # Brute force saves its wordlist position every chunk_size candidates
+    # (under storage.cache_dir) so --resume continues mid-wordlist
+    chunk_size: 5000
     wordlists:
       - ./assets/wordlists/subdomains-top1million-5000.txt
   
   web:
     http_probing: true
-    js_parsing: false
+    js_parsing: false          # subdomains, endpoints and exposed secrets in pages' JS
+    js_workers: 5              # JS files fetched at once per domain
+    js_max_bytes: 20971520     # bytes read across a domain's JS files (0 = no limit)
     cloud_assets: true
-    link_crawling: false
+    link_crawling: false       # follow same-domain links for hosts only named in navigation
+    crawl_depth: 2             # links followed from each alive host's page
+    crawl_max_pages: 50        # pages fetched per host
+    crawl_robots: false        # obey robots.txt Disallow rules
+    sitemap_robots: false      # hosts in robots.txt and sitemaps; robots paths kept as endpoints
+    sitemap_depth: 2           # levels of nested sitemap indexes followed
+
+# Scope
+scope:
+  # host: the target and names below it (scanning app.example.com reports
+  # x.app.example.com but not example.com)
+  # registrable: everything under the target's registrable domain (scanning
+  # app.example.com also reports example.com and api.example.com)
+  key_mode: host
 
 # Validation
 validation:
@@ -282,12 +761,150 @@ validation:
   http_validation: true
   tls_validation: false
   min_confidence: 50
+  # Validate each host end-to-end (DNS -> HTTP -> TLS) in one worker
+  pipelined: false
+  # Hosts resolving to RFC1918/loopback/link-local addresses: include, exclude, only
+  private_ips: include
+  # Keep unresolved/below-threshold hosts in output, tagged by status
+  keep_unvalidated: false
+  # Drop hosts serving domain parking/placeholder pages (always tagged "parked")
+  exclude_parked: false
+  # Hosts without A/AAAA but with MX, NS or TXT records (mail gateways,
+  # delegated zones, SPF targets) count as validated, tagged type:mail/ns/txt
+  record_only: true
+  # Add in-scope names listed in resolved hosts' TLS certificates (SANs);
+  # hosts without a certificate from HTTPS probing get a handshake on 443
+  san_discovery: true
+  # Subdomains known to exist, one per line: found ones get known_weight
+  # added to their confidence and a "source:known" tag, missing ones are
+  # reported as expected-but-missing
+  known_file: ""
+  known_weight: 20
+
+# Confidence scoring
+scoring:
+  # Per-source weights, merged over the built-in table (e.g. trust an
+  # internal source more: my_inventory: 20). Must not be negative.
+  source_weights: {}
+  # Weight of sources not in the table
+  default_weight: 5
+  # Maximum points of each score component
+  components:
+    source: 40
+    validation: 30
+    response: 20
+    pattern: 10
+    # Boost for hosts found by many stored scans (needs storage); a host
+    # seen once, over a month ago, loses a few points instead
+    recency: 10
 
 # Storage
 storage:
   engine: sqlite
   path: ./data/usr.db
   cache_dir: ./cache
+
+# WHOIS/RDAP enrichment of the apex domain (RDAP first, WHOIS fallback)
+whois:
+  enabled: true
+  rdap_url: https://rdap.org
+  timeout: 15
+
+# Deduplication
+# remove_similar collapses hosts serving the same page (same status and body,
+# ignoring tokens and timestamps) and hosts sharing a naming pattern
+# (web01..web50) into representatives when their labels are at least
+# similarity_threshold alike (0.0-1.0). Smaller reports, but collapsed hosts
+# are only listed in metadata.
+dedup:
+  remove_similar: false
+  similarity_threshold: 0.85
+  # Which HTTP result to keep when a host appears twice: best (2xx > 3xx >
+  # 4xx > 5xx, then one with a title/technologies, then the faster) or first
+  http_merge: best
+  # Merge www.example.com into example.com (any host X and www.X) when both
+  # resolve to exactly the same IPs; the merged name is kept in metadata
+  collapse_www: true
+
+# Stealth mode (scan_mode: stealth): low concurrency, randomized delays
+# between requests (milliseconds), rotating User-Agents and resolvers
+stealth:
+  max_concurrency: 3
+  jitter_min: 500
+  jitter_max: 3000
+  user_agents: []
+
+# HTTP probing (redirect loops are always detected and stopped)
+http:
+  timeout: 10
+  max_redirects: 3   # redirects followed per probe (final URL and chain are recorded)
+  # Per-stage limits (seconds) so slow handshakes fail before the timeout
+  dial_timeout: 5
+  tls_handshake_timeout: 5
+  response_header_timeout: 8
+  http2: true
+  # Sent with every probe, e.g. when a program requires an identifying
+  # header; a user_agent replaces the default and stealth mode's rotation,
+  # and a Host header sets the virtual host requested
+  user_agent: ""
+  headers: {}
+  #   X-Bug-Bounty: your-handle
+  #   Cookie: session=...
+  # Reuse stored HTTP results newer than this many seconds instead of
+  # re-probing (0 = always probe), e.g. 86400 for daily monitoring
+  recheck_ttl: 0
+  # Add in-scope hosts named in redirect, CSP, CORS and Link headers
+  header_discovery: true
+  # Where to look for an OpenAPI/Swagger spec on JSON API hosts ([] = skip)
+  api_spec_paths:
+    - /swagger.json
+    - /openapi.json
+    - /v3/api-docs
+  # Wappalyzer-format technology files (e.g. its technologies/*.json) added
+  # to the built-in fingerprints; rules using regex lookarounds are skipped
+  fingerprints: []
+  # Hash /favicon.ico like Shodan (http.favicon.hash) to find hosts sharing
+  # an icon; one extra request per host that answers
+  favicon: true
+  # Check alive hosts for exposed dev/admin paths and report hits as findings
+  paths:
+    enabled: false
+    list:
+      - /.git/HEAD
+      - /.env
+      - /admin
+      - /swagger.json
+      - /actuator
+    workers: 10
+    # Requests per host at most, including one soft-404 check
+    max_per_host: 10
+  # Capture a PNG of every host answering 200 with headless Chrome/Chromium
+  # (shown as thumbnails in the HTML report). Hosts are skipped, not failed,
+  # when no browser is found or a capture times out.
+  screenshots:
+    enabled: false
+    browser: ""      # empty = chromium, chromium-browser, google-chrome or chrome from PATH
+    dir: ""          # empty = <output_dir>/screenshots
+    timeout: 20
+    workers: 4
+    # Chrome won't start sandboxed as root or in most containers; set this
+    # there only, as it drops the sandbox around the pages being rendered
+    no_sandbox: false
+
+# Cloud bucket checks (throttled providers are backed off per provider)
+cloud:
+  workers: 20
+  timeout: 10
+  retries: 3
+  # Hours to remember buckets that don't exist, so permutations aren't
+  # rechecked on every run (0 = always check)
+  negative_ttl: 24
+
+# Export filtering (stored scans keep every result)
+output:
+  # Only export hosts whose HTTP probe returned one of these statuses,
+  # e.g. [200, 401, 403] (empty = export all)
+  status_codes: []
 `
 	
 	return os.WriteFile(path, []byte(defaultConfig), 0644)
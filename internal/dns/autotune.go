@@ -0,0 +1,173 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/yourusername/usr/internal/pool"
+	"go.uber.org/zap"
+)
+
+// errResolvePanicked marks a resolution that panicked, so its slot is
+// still released and counted as a failure
+var errResolvePanicked = errors.New("resolution panicked")
+
+// autotuneWindow is how many resolutions are observed between adjustments
+const autotuneWindow = 50
+
+// Error-rate thresholds: below growThreshold concurrency increases, above
+// shrinkThreshold it is halved. In between it holds steady.
+const (
+	growThreshold   = 0.02
+	shrinkThreshold = 0.10
+)
+
+// concurrencyController adapts the number of in-flight resolutions to the
+// observed failure rate (AIMD): it grows additively while timeouts and
+// SERVFAILs stay rare and halves when they climb, within [min, max].
+// Overloaded resolvers fail queries that would otherwise resolve, so
+// backing off turns those false negatives back into answers.
+type concurrencyController struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	limit    int
+	min      int
+	max      int
+	inFlight int
+	peak     int
+
+	completed int
+	failed    int
+}
+
+// newConcurrencyController starts at the given limit, clamped to the bounds
+func newConcurrencyController(start, low, high int) *concurrencyController {
+	if low < 1 {
+		low = 1
+	}
+	if high < low {
+		high = low
+	}
+
+	c := &concurrencyController{
+		limit: clamp(start, low, high),
+		min:   low,
+		max:   high,
+	}
+	c.peak = c.limit
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// acquire blocks until a resolution slot is free under the current limit
+func (c *concurrencyController) acquire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.inFlight >= c.limit {
+		c.cond.Wait()
+	}
+	c.inFlight++
+}
+
+// release frees a slot and records whether the resolution failed due to
+// load; every autotuneWindow results the limit is re-evaluated
+func (c *concurrencyController) release(overloaded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight--
+	c.completed++
+	if overloaded {
+		c.failed++
+	}
+
+	if c.completed >= autotuneWindow {
+		rate := float64(c.failed) / float64(c.completed)
+		switch {
+		case rate > shrinkThreshold:
+			c.limit = clamp(c.limit/2, c.min, c.max)
+		case rate < growThreshold:
+			c.limit = clamp(c.limit+max(c.limit/10, 1), c.min, c.max)
+		}
+		if c.limit > c.peak {
+			c.peak = c.limit
+		}
+		c.completed, c.failed = 0, 0
+	}
+
+	c.cond.Broadcast()
+}
+
+// current returns the limit in effect
+func (c *concurrencyController) current() (limit, peak int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limit, c.peak
+}
+
+// isOverload reports whether a resolution error suggests resolver or
+// network overload rather than a genuine negative answer
+func isOverload(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrNXDomain) || errors.Is(err, ErrNoRecords) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// clamp bounds n to [low, high]
+func clamp(n, low, high int) int {
+	if n < low {
+		return low
+	}
+	if n > high {
+		return high
+	}
+	return n
+}
+
+// runBatch resolves each domain with the given function across workers.
+// With dns.autotune enabled, workers is only the starting concurrency.
+func (e *Engine) runBatch(ctx context.Context, domains []string, workers int, resolve func(domain string) error) {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var controller *concurrencyController
+	goroutines := workers
+	if e.config.Autotune.Enabled {
+		controller = newConcurrencyController(workers, e.config.Autotune.MinWorkers, e.config.Autotune.MaxWorkers)
+		// Enough goroutines to reach the ceiling; the controller gates them
+		goroutines = min(controller.max, max(len(domains), 1))
+	}
+
+	err := pool.Run(ctx, domains, goroutines, func(ctx context.Context, domain string) {
+		if controller == nil {
+			resolve(domain)
+			return
+		}
+
+		controller.acquire()
+		err := errResolvePanicked
+		defer func() { controller.release(isOverload(err)) }()
+		err = resolve(domain)
+	})
+	if err != nil {
+		e.logger.Error("DNS resolution panicked", zap.Error(err))
+	}
+
+	if controller != nil {
+		limit, peak := controller.current()
+		e.logger.Info("Adaptive DNS concurrency",
+			zap.Int("domains", len(domains)),
+			zap.Int("start", clamp(workers, controller.min, controller.max)),
+			zap.Int("final", limit),
+			zap.Int("peak", peak),
+		)
+	}
+}
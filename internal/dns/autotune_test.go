@@ -0,0 +1,246 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+)
+
+// runWindow completes one adjustment window of resolutions, the given
+// number of them failing from overload
+func runWindow(c *concurrencyController, failures int) {
+	for i := 0; i < autotuneWindow; i++ {
+		c.acquire()
+		c.release(i < failures)
+	}
+}
+
+func TestNewConcurrencyController(t *testing.T) {
+	tests := []struct {
+		start, low, high int
+		limit, min, max  int
+	}{
+		{start: 10, low: 2, high: 50, limit: 10, min: 2, max: 50},
+		{start: 100, low: 2, high: 50, limit: 50, min: 2, max: 50},
+		{start: 1, low: 4, high: 50, limit: 4, min: 4, max: 50},
+		{start: 10, low: 0, high: 50, limit: 10, min: 1, max: 50}, // floor of one
+		{start: 10, low: 8, high: 4, limit: 8, min: 8, max: 8},    // ceiling below floor
+		{start: 0, low: -3, high: 0, limit: 1, min: 1, max: 1},
+	}
+
+	for _, tt := range tests {
+		c := newConcurrencyController(tt.start, tt.low, tt.high)
+		if c.limit != tt.limit || c.min != tt.min || c.max != tt.max {
+			t.Errorf("newConcurrencyController(%d, %d, %d) = limit %d in [%d, %d], want %d in [%d, %d]",
+				tt.start, tt.low, tt.high, c.limit, c.min, c.max, tt.limit, tt.min, tt.max)
+		}
+	}
+}
+
+func TestConcurrencyAdjustments(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    int
+		low      int
+		high     int
+		failures []int // per window
+		limits   []int // after each window
+	}{
+		{
+			name:  "grows by a tenth while errors are rare",
+			start: 20, low: 2, high: 100,
+			failures: []int{0, 0, 0},
+			limits:   []int{22, 24, 26},
+		},
+		{
+			name:  "grows by at least one",
+			start: 3, low: 1, high: 100,
+			failures: []int{0, 0},
+			limits:   []int{4, 5},
+		},
+		{
+			name:  "holds between the thresholds",
+			start: 20, low: 2, high: 100,
+			failures: []int{1, 3, 5}, // 2%, 6%, 10%
+			limits:   []int{20, 20, 20},
+		},
+		{
+			name:  "halves when errors climb",
+			start: 40, low: 2, high: 100,
+			failures: []int{6, 25, 50},
+			limits:   []int{20, 10, 5},
+		},
+		{
+			name:  "recovers after backing off",
+			start: 40, low: 2, high: 100,
+			failures: []int{10, 0, 0},
+			limits:   []int{20, 22, 24},
+		},
+		{
+			name:  "clamped to the ceiling",
+			start: 48, low: 2, high: 50,
+			failures: []int{0, 0},
+			limits:   []int{50, 50},
+		},
+		{
+			name:  "clamped to the floor",
+			start: 5, low: 4, high: 50,
+			failures: []int{50, 50},
+			limits:   []int{4, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConcurrencyController(tt.start, tt.low, tt.high)
+			for i, failures := range tt.failures {
+				runWindow(c, failures)
+				if limit, _ := c.current(); limit != tt.limits[i] {
+					t.Fatalf("window %d (%d failures): limit %d, want %d", i+1, failures, limit, tt.limits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrencyWaitsForFullWindow(t *testing.T) {
+	c := newConcurrencyController(10, 1, 100)
+	for i := 0; i < autotuneWindow-1; i++ {
+		c.acquire()
+		c.release(true)
+	}
+	if limit, _ := c.current(); limit != 10 {
+		t.Errorf("limit %d adjusted before a full window", limit)
+	}
+
+	// The failures so far still count toward the first window
+	c.acquire()
+	c.release(false)
+	if limit, _ := c.current(); limit != 5 {
+		t.Errorf("limit %d after a failing window, want 5", limit)
+	}
+}
+
+func TestConcurrencyPeak(t *testing.T) {
+	c := newConcurrencyController(10, 1, 100)
+	runWindow(c, 0)  // 11
+	runWindow(c, 0)  // 12
+	runWindow(c, 50) // 6
+
+	limit, peak := c.current()
+	if limit != 6 || peak != 12 {
+		t.Errorf("limit %d, peak %d; want 6 and 12", limit, peak)
+	}
+}
+
+func TestAcquireBlocksAtLimit(t *testing.T) {
+	c := newConcurrencyController(2, 1, 10)
+	c.acquire()
+	c.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		c.acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("third slot granted over a limit of two")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	c.release(false)
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("released slot not handed on")
+	}
+}
+
+func TestIsOverload(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{ErrNXDomain, false},
+		{fmt.Errorf("lookup a.example.com: %w", ErrNXDomain), false},
+		{ErrNoRecords, false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, true},
+		{errors.New("i/o timeout"), true},
+		{errors.New("SERVFAIL"), true},
+		{errResolvePanicked, true},
+	}
+
+	for _, tt := range tests {
+		if got := isOverload(tt.err); got != tt.want {
+			t.Errorf("isOverload(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRunBatchAutotuneBounds(t *testing.T) {
+	e := newTestEngine(nil, func(cfg *config.DNSConfig) {
+		cfg.Autotune = config.DNSAutotuneConfig{Enabled: true, MinWorkers: 2, MaxWorkers: 8}
+	})
+
+	domains := make([]string, 300)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("host%d.example.com", i)
+	}
+
+	var (
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+		resolved = make(map[string]bool)
+	)
+	e.runBatch(context.Background(), domains, 4, func(domain string) error {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		resolved[domain] = true
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return nil
+	})
+
+	if len(resolved) != len(domains) {
+		t.Errorf("resolved %d of %d domains", len(resolved), len(domains))
+	}
+	if peak > 8 {
+		t.Errorf("%d resolutions in flight, over the ceiling of 8", peak)
+	}
+}
+
+func TestRunBatchAutotuneSurvivesPanics(t *testing.T) {
+	e := newTestEngine(nil, func(cfg *config.DNSConfig) {
+		cfg.Autotune = config.DNSAutotuneConfig{Enabled: true, MinWorkers: 1, MaxWorkers: 1}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.runBatch(context.Background(), []string{"a.example.com", "b.example.com", "c.example.com"}, 1, func(string) error {
+			panic("resolver bug")
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a panicking resolution kept its slot")
+	}
+}
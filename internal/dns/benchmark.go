@@ -0,0 +1,195 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mdns "github.com/miekg/dns"
+	"github.com/yourusername/usr/internal/pool"
+	"go.uber.org/zap"
+)
+
+// benchmarkKnownGood are stable names every honest resolver must answer
+var benchmarkKnownGood = []string{
+	"google.com",
+	"cloudflare.com",
+	"example.com",
+	"wikipedia.org",
+	"github.com",
+}
+
+// benchmarkParents host random labels that must come back as NXDOMAIN;
+// resolvers that answer them are hijacking or poisoning responses
+var benchmarkParents = []string{
+	"google.com",
+	"example.com",
+	"wikipedia.org",
+}
+
+// ResolverScore is the benchmark outcome for a single resolver
+type ResolverScore struct {
+	Resolver   string        `json:"resolver"`
+	Latency    time.Duration `json:"latency"`    // mean over successful queries
+	Answered   int           `json:"answered"`   // known-good names resolved
+	NXDomain   int           `json:"nxdomain"`   // garbage names correctly rejected
+	Hijacked   int           `json:"hijacked"`   // garbage names that got an answer
+	Consistent bool          `json:"consistent"` // repeated queries agreed
+	Failures   int           `json:"failures"`   // timeouts and errors
+	Reliable   bool          `json:"reliable"`
+}
+
+// BenchmarkResolvers measures latency and correctness of each resolver by
+// querying known-good and random names, returning them ranked best first
+func (e *Engine) BenchmarkResolvers(ctx context.Context, resolvers []string, workers int) []*ResolverScore {
+	scores := make([]*ResolverScore, 0, len(resolvers))
+	scoresMu := sync.Mutex{}
+
+	err := pool.Run(ctx, resolvers, workers, func(ctx context.Context, resolver string) {
+		score := e.benchmarkResolver(ctx, resolver)
+
+		scoresMu.Lock()
+		scores = append(scores, score)
+		scoresMu.Unlock()
+	})
+	if err != nil {
+		e.logger.Error("Resolver benchmark panicked", zap.Error(err))
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Reliable != scores[j].Reliable {
+			return scores[i].Reliable
+		}
+		return scores[i].Latency < scores[j].Latency
+	})
+
+	return scores
+}
+
+// benchmarkResolver scores a single resolver
+func (e *Engine) benchmarkResolver(ctx context.Context, resolver string) *ResolverScore {
+	score := &ResolverScore{
+		Resolver:   resolver,
+		Consistent: true,
+	}
+
+	var total time.Duration
+	var timed int
+
+	for _, name := range benchmarkKnownGood {
+		first, elapsed, err := e.benchmarkQuery(ctx, name, resolver)
+		if err != nil {
+			score.Failures++
+			continue
+		}
+		total += elapsed
+		timed++
+		score.Answered++
+
+		// A second query must return an overlapping answer set; rotating
+		// pools are fine, but disjoint answers suggest tampering
+		second, elapsed, err := e.benchmarkQuery(ctx, name, resolver)
+		if err != nil {
+			score.Failures++
+			continue
+		}
+		total += elapsed
+		timed++
+
+		if !overlaps(first, second) {
+			score.Consistent = false
+		}
+	}
+
+	for i, parent := range benchmarkParents {
+		name := fmt.Sprintf("usr-benchmark-%d-%d.%s", time.Now().UnixNano(), i, parent)
+		_, elapsed, err := e.benchmarkQuery(ctx, name, resolver)
+		switch {
+		case errors.Is(err, ErrNXDomain):
+			score.NXDomain++
+			total += elapsed
+			timed++
+		case err == nil:
+			score.Hijacked++
+		default:
+			score.Failures++
+		}
+	}
+
+	if timed > 0 {
+		score.Latency = total / time.Duration(timed)
+	}
+
+	score.Reliable = score.Answered == len(benchmarkKnownGood) &&
+		score.Hijacked == 0 &&
+		score.Consistent &&
+		score.Failures == 0
+
+	return score
+}
+
+// benchmarkQuery resolves A records through one resolver, bypassing rotation,
+// retries and rate limiting so each measurement reflects that resolver alone
+func (e *Engine) benchmarkQuery(ctx context.Context, name, resolver string) ([]string, time.Duration, error) {
+	start := time.Now()
+	msg, err := e.exchangeWithResolver(ctx, name, mdns.TypeA, resolver)
+	elapsed := time.Since(start)
+	if err != nil {
+		return nil, elapsed, err
+	}
+
+	var ips []string
+	for _, rr := range msg.Answer {
+		if a, ok := rr.(*mdns.A); ok {
+			ips = append(ips, a.A.String())
+		}
+	}
+
+	if len(ips) == 0 {
+		return nil, elapsed, ErrNoRecords
+	}
+
+	return ips, elapsed, nil
+}
+
+// overlaps reports whether two answer sets share at least one entry
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		if contains(b, x) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadResolvers reads resolvers from a file, one per line; blank lines and
+// # comments are ignored
+func LoadResolvers(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open resolvers file: %w", err)
+	}
+	defer file.Close()
+
+	var resolvers []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		resolvers = append(resolvers, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read resolvers file: %w", err)
+	}
+
+	return resolvers, nil
+}
@@ -0,0 +1,206 @@
+// Package dnstest provides an in-process DNS server for tests, answering
+// from a fixed zone the test builds up
+package dnstest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	mdns "github.com/miekg/dns"
+)
+
+// Server answers queries over UDP from its records. A name with records of
+// other types gets an empty answer, a name with none NXDOMAIN, unless a
+// wildcard covers it.
+type Server struct {
+	// Addr is the host:port to use as a resolver
+	Addr string
+
+	server *mdns.Server
+
+	mu        sync.Mutex
+	records   map[string]map[uint16][]string
+	wildcards map[string]map[uint16][]string
+	rcode     int
+	queries   map[string]int
+}
+
+// NewServer starts a server on a free localhost port, shut down when the
+// test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("dnstest: listen: %v", err)
+	}
+
+	s := &Server{
+		Addr:      conn.LocalAddr().String(),
+		records:   make(map[string]map[uint16][]string),
+		wildcards: make(map[string]map[uint16][]string),
+		rcode:     mdns.RcodeSuccess,
+		queries:   make(map[string]int),
+	}
+
+	started := make(chan struct{})
+	s.server = &mdns.Server{
+		PacketConn:        conn,
+		Handler:           s,
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go s.server.ActivateAndServe()
+	<-started
+
+	t.Cleanup(func() { s.server.Shutdown() })
+	return s
+}
+
+// Add gives name records of qtype, in presentation format: "192.0.2.1",
+// "10 mx.example.com", "target.example.com" for a CNAME
+func (s *Server) Add(name string, qtype uint16, values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	add(s.records, name, qtype, values)
+}
+
+// AddWildcard answers qtype with values for every name below zone that
+// has no records of its own
+func (s *Server) AddWildcard(zone string, qtype uint16, values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	add(s.wildcards, zone, qtype, values)
+}
+
+// SetRcode makes every answer carry rcode, e.g. mdns.RcodeServerFailure
+// to play a broken resolver; mdns.RcodeSuccess restores normal answers
+func (s *Server) SetRcode(rcode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rcode = rcode
+}
+
+// Queries returns how many queries asked for name, of any type
+func (s *Server) Queries(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries[normalize(name)]
+}
+
+// TotalQueries returns how many queries the server answered
+func (s *Server) TotalQueries() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, count := range s.queries {
+		total += count
+	}
+	return total
+}
+
+// ServeDNS implements mdns.Handler
+func (s *Server) ServeDNS(w mdns.ResponseWriter, query *mdns.Msg) {
+	w.WriteMsg(s.Answer(query))
+}
+
+// Answer builds the response to a query, for transports other than UDP
+// (a DoH handler, say)
+func (s *Server) Answer(query *mdns.Msg) *mdns.Msg {
+	resp := new(mdns.Msg)
+	resp.SetReply(query)
+	resp.RecursionAvailable = true
+	if len(query.Question) == 0 {
+		resp.Rcode = mdns.RcodeFormatError
+		return resp
+	}
+
+	question := query.Question[0]
+	name := normalize(question.Name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries[name]++
+
+	if s.rcode != mdns.RcodeSuccess {
+		resp.Rcode = s.rcode
+		return resp
+	}
+
+	byType, exists := s.records[name]
+	if !exists {
+		byType, exists = s.wildcardFor(name)
+	}
+	if !exists {
+		resp.Rcode = mdns.RcodeNameError
+		return resp
+	}
+
+	values, qtype := byType[question.Qtype], question.Qtype
+	if len(values) == 0 && qtype != mdns.TypeCNAME {
+		// An alias answers for every type; resolving the target is the
+		// client's job
+		values, qtype = byType[mdns.TypeCNAME], mdns.TypeCNAME
+	}
+
+	for _, value := range values {
+		rr, err := newRR(question.Name, qtype, value)
+		if err != nil {
+			resp.Rcode = mdns.RcodeServerFailure
+			return resp
+		}
+		resp.Answer = append(resp.Answer, rr)
+	}
+	return resp
+}
+
+// wildcardFor returns the records of the nearest wildcard covering name.
+// A parent with records of its own and no wildcard shadows any wildcard
+// above it, as closest-encloser synthesis does. The caller holds mu.
+func (s *Server) wildcardFor(name string) (map[uint16][]string, bool) {
+	for parent := name; ; {
+		_, rest, found := strings.Cut(parent, ".")
+		if !found {
+			return nil, false
+		}
+		if byType, ok := s.wildcards[rest]; ok {
+			return byType, true
+		}
+		if _, ok := s.records[rest]; ok {
+			return nil, false
+		}
+		parent = rest
+	}
+}
+
+// add appends values to the records of name and qtype
+func add(zone map[string]map[uint16][]string, name string, qtype uint16, values []string) {
+	name = normalize(name)
+	if zone[name] == nil {
+		zone[name] = make(map[uint16][]string)
+	}
+	zone[name][qtype] = append(zone[name][qtype], values...)
+}
+
+// newRR parses a record from its presentation-format value
+func newRR(owner string, qtype uint16, value string) (mdns.RR, error) {
+	switch qtype {
+	case mdns.TypeCNAME, mdns.TypeNS:
+		value = mdns.Fqdn(value)
+	case mdns.TypeMX:
+		if pref, host, ok := strings.Cut(value, " "); ok {
+			value = pref + " " + mdns.Fqdn(host)
+		}
+	case mdns.TypeTXT:
+		value = fmt.Sprintf("%q", value)
+	}
+	return mdns.NewRR(fmt.Sprintf("%s 60 IN %s %s", owner, mdns.TypeToString[qtype], value))
+}
+
+// normalize lowercases a name and drops the trailing dot
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
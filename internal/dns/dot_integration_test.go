@@ -0,0 +1,38 @@
+//go:build integration
+
+package dns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"go.uber.org/zap"
+)
+
+// dotEngine queries resolver over DNS-over-TLS only
+func dotEngine(resolver string) *Engine {
+	return NewEngine(&config.DNSConfig{
+		Resolvers: []string{resolver},
+		Protocol:  ProtocolDoT,
+		Timeout:   5,
+		Retries:   1,
+	}, zap.NewNop())
+}
+
+func TestDoTResolvesAgainstCloudflare(t *testing.T) {
+	ips, err := dotEngine("1.1.1.1:853#cloudflare-dns.com").ResolveType(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("DoT query to 1.1.1.1: %v", err)
+	}
+	if len(ips) == 0 {
+		t.Fatal("DoT query to 1.1.1.1 returned no addresses for example.com")
+	}
+}
+
+func TestDoTRejectsMismatchedServerName(t *testing.T) {
+	_, err := dotEngine("1.1.1.1:853#not-cloudflare.example").ResolveType(context.Background(), "example.com", "A")
+	if err == nil {
+		t.Fatal("DoT query succeeded with a server name the certificate doesn't cover")
+	}
+}
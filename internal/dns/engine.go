@@ -2,28 +2,70 @@ package dns
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	mdns "github.com/miekg/dns"
 	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/stealth"
 	"github.com/yourusername/usr/internal/types"
 	"go.uber.org/zap"
 )
 
+// ErrNXDomain is returned when a resolver authoritatively reports that a name does not exist
+var ErrNXDomain = errors.New("domain does not exist")
+
+// ErrNoRecords is returned when a name exists but has no records of the queried type
+var ErrNoRecords = errors.New("no records of requested type")
+
+// ErrCNAMELoop is returned when a CNAME chain leads back to a name already in it
+var ErrCNAMELoop = errors.New("CNAME loop")
+
+// ErrCNAMEDepth is returned when a CNAME chain is longer than dns.cname_depth
+var ErrCNAMEDepth = errors.New("CNAME chain too long")
+
+// defaultCNAMEDepth is used when dns.cname_depth is not set
+const defaultCNAMEDepth = 10
+
+// defaultQueryTypes is used when a phase has no query types configured
+var defaultQueryTypes = []string{"A", "AAAA"}
+
 // Engine handles high-performance DNS resolution
 type Engine struct {
 	config    *config.DNSConfig
 	resolvers []string
 	logger    *zap.Logger
+	client    *mdns.Client
+	protocol  string
+	
+	// Per-resolver DoT clients, keyed by resolver entry
+	tlsClients map[string]*mdns.Client
+	
+	// Shared HTTP client for DoH (nil unless protocol is doh)
+	doh *http.Client
+	
+	// Per-resolver failure tracking and ejection
+	health *resolverHealth
+	
+	// Optional pools for A and AAAA queries; empty means the general pool
+	resolversV4 []string
+	resolversV6 []string
 	
 	mu            sync.RWMutex
 	resolverIndex int
+	v4Index       int
+	v6Index       int
 	
 	// Rate limiting
 	rateLimiter chan struct{}
 	
+	// Stealth mode: jitter and random resolver selection (optional)
+	stealth *stealth.Profile
+	
 	// Wildcard detection cache
 	wildcardCache map[string]*types.WildcardInfo
 	wildcardMu    sync.RWMutex
@@ -31,11 +73,44 @@ type Engine struct {
 
 // NewEngine creates a new DNS engine
 func NewEngine(cfg *config.DNSConfig, logger *zap.Logger) *Engine {
+	resolvers := cfg.Resolvers
+	if cfg.ResolversFile != "" {
+		loaded, err := LoadResolvers(cfg.ResolversFile)
+		if err != nil || len(loaded) == 0 {
+			logger.Warn("Falling back to configured resolvers",
+				zap.String("resolvers_file", cfg.ResolversFile),
+				zap.Error(err),
+			)
+		} else {
+			resolvers = loaded
+		}
+	}
+	
+	protocol := strings.ToLower(cfg.Protocol)
+	if _, ok := defaultPorts[protocol]; !ok {
+		if protocol != "" {
+			logger.Warn("Unknown DNS protocol, using udp", zap.String("protocol", cfg.Protocol))
+		}
+		protocol = ProtocolUDP
+	}
+	
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	
 	e := &Engine{
 		config:        cfg,
-		resolvers:     cfg.Resolvers,
+		resolvers:     resolvers,
+		resolversV4:   cfg.ResolversV4,
+		resolversV6:   cfg.ResolversV6,
 		logger:        logger,
+		client:        newClient(protocol, resolverEndpoint{}, timeout),
+		protocol:      protocol,
+		tlsClients:    make(map[string]*mdns.Client),
 		wildcardCache: make(map[string]*types.WildcardInfo),
+		health:        newResolverHealth(cfg.Health.EjectAfter, time.Duration(cfg.Health.Cooldown)*time.Second, logger),
+	}
+	
+	if protocol == ProtocolDoH {
+		e.doh = newDoHClient(timeout)
 	}
 	
 	// Initialize rate limiter
@@ -46,8 +121,189 @@ func NewEngine(cfg *config.DNSConfig, logger *zap.Logger) *Engine {
 	return e
 }
 
-// Resolve resolves a domain to IP addresses
+// SetStealth enables stealth behavior: concurrent queries are capped, each
+// query is delayed by a random jitter and resolvers are picked at random
+// to spread queries across the pool
+func (e *Engine) SetStealth(profile *stealth.Profile) {
+	e.stealth = profile
+	e.rateLimiter = make(chan struct{}, profile.Concurrency(max(e.config.RateLimit, 1)))
+}
+
+// Resolve resolves a domain to IP addresses (A and AAAA)
 func (e *Engine) Resolve(ctx context.Context, domain string) ([]string, error) {
+	records, err := e.ResolveRecords(ctx, domain, []string{"A", "AAAA"})
+	if err != nil {
+		return nil, err
+	}
+	
+	return RecordIPs(records), nil
+}
+
+// ResolveIPv6 resolves a domain's AAAA records only. A host may have IPv6
+// addresses and no IPv4 ones.
+func (e *Engine) ResolveIPv6(ctx context.Context, domain string) ([]string, error) {
+	return e.ResolveType(ctx, domain, "AAAA")
+}
+
+// ResolveType queries a single record type (A, AAAA, CNAME, MX, NS, TXT)
+// and returns the answers in presentation format
+func (e *Engine) ResolveType(ctx context.Context, domain, qtype string) ([]string, error) {
+	rrtype, ok := mdns.StringToType[strings.ToUpper(qtype)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported query type %q", qtype)
+	}
+	
+	msg, err := e.exchange(ctx, domain, rrtype)
+	if err != nil {
+		return nil, err
+	}
+	
+	var answers []string
+	for _, rr := range msg.Answer {
+		if rr.Header().Rrtype != rrtype {
+			continue
+		}
+		
+		switch record := rr.(type) {
+		case *mdns.A:
+			answers = append(answers, record.A.String())
+		case *mdns.AAAA:
+			answers = append(answers, record.AAAA.String())
+		case *mdns.CNAME:
+			answers = append(answers, strings.TrimSuffix(record.Target, "."))
+		case *mdns.MX:
+			answers = append(answers, strings.TrimSuffix(record.Mx, "."))
+		case *mdns.NS:
+			answers = append(answers, strings.TrimSuffix(record.Ns, "."))
+		case *mdns.TXT:
+			answers = append(answers, strings.Join(record.Txt, ""))
+		}
+	}
+	
+	if len(answers) == 0 {
+		return nil, ErrNoRecords
+	}
+	
+	return answers, nil
+}
+
+// ResolveRecords queries each of the given record types and collects the answers.
+// It fails only if none of the types returned anything.
+func (e *Engine) ResolveRecords(ctx context.Context, domain string, qtypes []string) (*types.DNSRecords, error) {
+	if len(qtypes) == 0 {
+		qtypes = defaultQueryTypes
+	}
+	
+	records := &types.DNSRecords{}
+	found := false
+	var lastErr error
+	
+	for _, qtype := range qtypes {
+		var answers []string
+		var err error
+		if strings.EqualFold(qtype, "CNAME") {
+			answers, err = e.ResolveCNAME(ctx, domain)
+			if err != nil && len(answers) > 0 {
+				// A looping or overlong chain is still worth recording
+				e.logger.Debug("CNAME chain cut short",
+					zap.String("domain", domain),
+					zap.Strings("chain", answers),
+					zap.Error(err),
+				)
+				err = nil
+			}
+		} else {
+			answers, err = e.ResolveType(ctx, domain, qtype)
+		}
+		if err != nil {
+			lastErr = err
+			// A missing name is missing for every type
+			if errors.Is(err, ErrNXDomain) || ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		
+		found = true
+		switch strings.ToUpper(qtype) {
+		case "A":
+			records.A = answers
+		case "AAAA":
+			records.AAAA = answers
+		case "CNAME":
+			records.CNAME = answers
+		case "MX":
+			records.MX = answers
+		case "NS":
+			records.NS = answers
+		case "TXT":
+			records.TXT = answers
+		}
+	}
+	
+	if !found {
+		if lastErr == nil {
+			lastErr = ErrNoRecords
+		}
+		return nil, lastErr
+	}
+	
+	return records, nil
+}
+
+// ResolveCNAME follows a domain's CNAME chain one hop at a time and returns
+// the names it passes through, in order, ending with the canonical name.
+// It returns ErrNoRecords if the domain is not an alias. A chain that loops
+// or exceeds dns.cname_depth hops returns the names seen so far along with
+// ErrCNAMELoop or ErrCNAMEDepth. A canonical name that does not exist ends
+// the chain normally: a dangling alias is an answer, not a failure.
+func (e *Engine) ResolveCNAME(ctx context.Context, domain string) ([]string, error) {
+	depth := e.config.CNAMEDepth
+	if depth <= 0 {
+		depth = defaultCNAMEDepth
+	}
+	
+	name := strings.ToLower(strings.TrimSuffix(domain, "."))
+	visited := map[string]bool{name: true}
+	var chain []string
+	
+	for {
+		targets, err := e.ResolveType(ctx, name, "CNAME")
+		if err != nil {
+			if len(chain) > 0 && (errors.Is(err, ErrNoRecords) || errors.Is(err, ErrNXDomain)) {
+				return chain, nil
+			}
+			return chain, err
+		}
+		
+		target := strings.ToLower(targets[0])
+		if visited[target] {
+			return chain, fmt.Errorf("%w: %s -> %s", ErrCNAMELoop, name, target)
+		}
+		if len(chain) == depth {
+			return chain, fmt.Errorf("%w: more than %d hops", ErrCNAMEDepth, depth)
+		}
+		
+		visited[target] = true
+		chain = append(chain, target)
+		name = target
+	}
+}
+
+// RecordIPs returns the A and AAAA addresses of a record set
+func RecordIPs(records *types.DNSRecords) []string {
+	if records == nil {
+		return nil
+	}
+	
+	ips := make([]string, 0, len(records.A)+len(records.AAAA))
+	ips = append(ips, records.A...)
+	ips = append(ips, records.AAAA...)
+	return ips
+}
+
+// exchange sends a query with rate limiting, resolver rotation and retries
+func (e *Engine) exchange(ctx context.Context, domain string, qtype uint16) (*mdns.Msg, error) {
 	// Rate limiting
 	if e.rateLimiter != nil {
 		select {
@@ -58,9 +314,14 @@ func (e *Engine) Resolve(ctx context.Context, domain string) ([]string, error) {
 		}
 	}
 	
-	resolver := e.getNextResolver()
+	if e.stealth != nil {
+		if err := e.stealth.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	
+	resolver := e.getNextResolver(qtype)
 	
-	var ips []string
 	var lastErr error
 	
 	// Retry logic
@@ -73,16 +334,29 @@ func (e *Engine) Resolve(ctx context.Context, domain string) ([]string, error) {
 			case <-ctx.Done():
 				return nil, ctx.Err()
 			}
-			resolver = e.getNextResolver()
+			resolver = e.getNextResolver(qtype)
 		}
 		
-		ips, lastErr = e.resolveWithResolver(ctx, domain, resolver)
-		if lastErr == nil {
-			return ips, nil
+		msg, err := e.exchangeWithResolver(ctx, domain, qtype, resolver)
+		
+		// A cancelled scan says nothing about the resolver
+		if ctx.Err() == nil {
+			e.health.record(resolver, err != nil && !errors.Is(err, ErrNXDomain))
+		}
+		
+		if err == nil {
+			return msg, nil
 		}
 		
+		// NXDOMAIN is an answer, not a failure - don't retry
+		if errors.Is(err, ErrNXDomain) {
+			return nil, err
+		}
+		lastErr = err
+		
 		e.logger.Debug("DNS resolution attempt failed",
 			zap.String("domain", domain),
+			zap.String("type", mdns.TypeToString[qtype]),
 			zap.String("resolver", resolver),
 			zap.Int("attempt", attempt+1),
 			zap.Error(lastErr),
@@ -92,27 +366,45 @@ func (e *Engine) Resolve(ctx context.Context, domain string) ([]string, error) {
 	return nil, fmt.Errorf("failed after %d attempts: %w", e.config.Retries+1, lastErr)
 }
 
-// resolveWithResolver performs DNS resolution using a specific resolver
-func (e *Engine) resolveWithResolver(ctx context.Context, domain, resolver string) ([]string, error) {
+// exchangeWithResolver performs a single DNS query against a specific resolver
+func (e *Engine) exchangeWithResolver(ctx context.Context, domain string, qtype uint16, resolver string) (*mdns.Msg, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(e.config.Timeout)*time.Second)
 	defer cancel()
 	
-	r := &net.Resolver{
-		PreferGo: true,
-		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-			d := net.Dialer{
-				Timeout: time.Duration(e.config.Timeout) * time.Second,
-			}
-			return d.DialContext(ctx, network, net.JoinHostPort(resolver, "53"))
-		},
-	}
+	query := new(mdns.Msg)
+	query.SetQuestion(mdns.Fqdn(domain), qtype)
+	query.RecursionDesired = true
+	
+	client, address := e.clientFor(resolver)
 	
-	ips, err := r.LookupHost(timeoutCtx, domain)
+	var msg *mdns.Msg
+	var err error
+	if e.protocol == ProtocolDoH {
+		msg, err = e.exchangeDoH(timeoutCtx, query, resolver)
+	} else {
+		msg, _, err = client.ExchangeContext(timeoutCtx, query, address)
+	}
 	if err != nil {
 		return nil, err
 	}
 	
-	return ips, nil
+	// Retry over TCP when the UDP answer was truncated
+	if msg.Truncated && e.protocol == ProtocolUDP {
+		tcp := &mdns.Client{Net: "tcp", Timeout: e.client.Timeout}
+		msg, _, err = tcp.ExchangeContext(timeoutCtx, query, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+	
+	switch msg.Rcode {
+	case mdns.RcodeSuccess:
+		return msg, nil
+	case mdns.RcodeNameError:
+		return nil, ErrNXDomain
+	default:
+		return nil, fmt.Errorf("resolver returned %s", mdns.RcodeToString[msg.Rcode])
+	}
 }
 
 // ResolveBatch resolves multiple domains concurrently
@@ -120,46 +412,101 @@ func (e *Engine) ResolveBatch(ctx context.Context, domains []string, workers int
 	results := make(map[string][]string)
 	resultsMu := sync.Mutex{}
 	
-	domainChan := make(chan string, len(domains))
-	for _, domain := range domains {
-		domainChan <- domain
-	}
-	close(domainChan)
-	
-	var wg sync.WaitGroup
-	for i := 0; i < workers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for domain := range domainChan {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-					ips, err := e.Resolve(ctx, domain)
-					if err == nil && len(ips) > 0 {
-						resultsMu.Lock()
-						results[domain] = ips
-						resultsMu.Unlock()
-					}
-				}
-			}
-		}()
-	}
+	e.runBatch(ctx, domains, workers, func(domain string) error {
+		ips, err := e.Resolve(ctx, domain)
+		if err == nil && len(ips) > 0 {
+			resultsMu.Lock()
+			results[domain] = ips
+			resultsMu.Unlock()
+		}
+		return err
+	})
+	
+	return results
+}
+
+// ResolveBatchRecords resolves multiple domains concurrently, querying the
+// given record types for each. Domains with no answers are omitted.
+func (e *Engine) ResolveBatchRecords(ctx context.Context, domains []string, workers int, qtypes []string) map[string]*types.DNSRecords {
+	results := make(map[string]*types.DNSRecords)
+	resultsMu := sync.Mutex{}
+	
+	e.runBatch(ctx, domains, workers, func(domain string) error {
+		records, err := e.ResolveRecords(ctx, domain, qtypes)
+		if err == nil {
+			resultsMu.Lock()
+			results[domain] = records
+			resultsMu.Unlock()
+		}
+		return err
+	})
 	
-	wg.Wait()
 	return results
 }
 
-// getNextResolver returns the next resolver in round-robin fashion
-func (e *Engine) getNextResolver() string {
+// getNextResolver returns the next resolver for a query type in
+// round-robin fashion, from the A/AAAA pool when one is configured
+func (e *Engine) getNextResolver(qtype uint16) string {
+	resolvers, index := e.resolverPool(qtype)
+	
+	if e.stealth != nil {
+		start := e.stealth.Intn(len(resolvers))
+		for i := range resolvers {
+			if resolver := resolvers[(start+i)%len(resolvers)]; e.health.available(resolver) {
+				return resolver
+			}
+		}
+		return resolvers[start]
+	}
+	
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	
-	resolver := e.resolvers[e.resolverIndex]
-	e.resolverIndex = (e.resolverIndex + 1) % len(e.resolvers)
+	// Skip ejected resolvers; if every one is ejected, rotate as usual
+	// rather than stall
+	first := *index % len(resolvers)
+	for i := range resolvers {
+		candidate := (first + i) % len(resolvers)
+		if e.health.available(resolvers[candidate]) {
+			*index = (candidate + 1) % len(resolvers)
+			return resolvers[candidate]
+		}
+	}
 	
-	return resolver
+	*index = (first + 1) % len(resolvers)
+	return resolvers[first]
+}
+
+// resolverPool returns the resolvers for a query type and its rotation
+// index. A and AAAA use their own pool when configured; everything else,
+// and either family without a pool, uses the general resolvers.
+func (e *Engine) resolverPool(qtype uint16) ([]string, *int) {
+	switch {
+	case qtype == mdns.TypeA && len(e.resolversV4) > 0:
+		return e.resolversV4, &e.v4Index
+	case qtype == mdns.TypeAAAA && len(e.resolversV6) > 0:
+		return e.resolversV6, &e.v6Index
+	default:
+		return e.resolvers, &e.resolverIndex
+	}
+}
+
+// SetWildcardInfo seeds the wildcard cache for a domain, e.g. with a
+// result stored by an earlier scan, so IsWildcard skips the probes
+func (e *Engine) SetWildcardInfo(domain string, info *types.WildcardInfo) {
+	e.wildcardMu.Lock()
+	defer e.wildcardMu.Unlock()
+	e.wildcardCache[domain] = info
+}
+
+// Resolvers returns every configured resolver: the general pool followed
+// by the A and AAAA pools
+func (e *Engine) Resolvers() []string {
+	resolvers := make([]string, 0, len(e.resolvers)+len(e.resolversV4)+len(e.resolversV6))
+	resolvers = append(resolvers, e.resolvers...)
+	resolvers = append(resolvers, e.resolversV4...)
+	resolvers = append(resolvers, e.resolversV6...)
+	return resolvers
 }
 
 // IsWildcard checks if a domain has wildcard DNS
@@ -197,41 +544,67 @@ func (e *Engine) detectWildcard(ctx context.Context, domain string) (*types.Wild
 	// Generate random subdomains
 	testSubdomains := e.generateRandomSubdomains(domain, e.config.WildcardTests)
 	
-	// Resolve all test subdomains
-	resolvedCount := 0
-	var patterns []string
+	// Resolve all test subdomains, tracking A and AAAA answers separately
+	var v4, v6 wildcardFamily
 	
 	for _, testSub := range testSubdomains {
-		ips, err := e.Resolve(ctx, testSub)
-		if err == nil && len(ips) > 0 {
+		records, err := e.ResolveRecords(ctx, testSub, e.config.QueryTypes.Wildcard)
+		if err != nil {
+			continue
+		}
+		if ips := RecordIPs(records); len(ips) > 0 {
 			info.TestResults[testSub] = ips
-			resolvedCount++
-			
-			// Track IP patterns
-			for _, ip := range ips {
-				if !contains(patterns, ip) {
-					patterns = append(patterns, ip)
-				}
-			}
 		}
+		v4.add(records.A)
+		v6.add(records.AAAA)
 	}
 	
-	// If most random subdomains resolve, it's likely a wildcard
-	if resolvedCount >= e.config.WildcardTests-1 {
+	// A family is a wildcard when all random names but one resolve in it;
+	// one lost UDP answer shouldn't hide a wildcard. Below two tests that
+	// would leave no bar at all, so every name must answer.
+	threshold := max(e.config.WildcardTests-1, 1)
+	if v4.resolved >= threshold {
 		info.IsWildcard = true
-		info.Patterns = patterns
-		
+		info.Patterns = v4.patterns
+	}
+	if v6.resolved >= threshold {
+		info.IsWildcard = true
+		info.PatternsV6 = v6.patterns
+	}
+	
+	if info.IsWildcard {
 		e.logger.Warn("Wildcard DNS detected",
 			zap.String("domain", domain),
 			zap.Int("test_count", e.config.WildcardTests),
-			zap.Int("resolved_count", resolvedCount),
-			zap.Strings("patterns", patterns),
+			zap.Int("resolved_v4", v4.resolved),
+			zap.Int("resolved_v6", v6.resolved),
+			zap.Strings("patterns", info.Patterns),
+			zap.Strings("patterns_v6", info.PatternsV6),
 		)
 	}
 	
 	return info, nil
 }
 
+// wildcardFamily counts the random names answered in one address family
+// and collects the distinct answers
+type wildcardFamily struct {
+	resolved int
+	patterns []string
+}
+
+func (f *wildcardFamily) add(ips []string) {
+	if len(ips) == 0 {
+		return
+	}
+	f.resolved++
+	for _, ip := range ips {
+		if !contains(f.patterns, ip) {
+			f.patterns = append(f.patterns, ip)
+		}
+	}
+}
+
 // generateRandomSubdomains creates random subdomains for wildcard testing
 func (e *Engine) generateRandomSubdomains(domain string, count int) []string {
 	subdomains := make([]string, count)
@@ -266,7 +639,7 @@ func (e *Engine) FilterWildcards(ctx context.Context, domain string, subdomains
 		// Check if IPs match wildcard patterns
 		isWildcardMatch := false
 		for _, ip := range ips {
-			if contains(wildcardInfo.Patterns, ip) {
+			if wildcardInfo.Matches(ip) {
 				isWildcardMatch = true
 				break
 			}
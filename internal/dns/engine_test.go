@@ -0,0 +1,142 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	mdns "github.com/miekg/dns"
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+	"go.uber.org/zap"
+)
+
+// newTestEngine returns an engine resolving through server over UDP, with
+// a quick timeout and no retries, after applying configure to its config.
+// A nil server leaves the resolvers to configure.
+func newTestEngine(server *dnstest.Server, configure func(*config.DNSConfig)) *Engine {
+	cfg := &config.DNSConfig{
+		Protocol: ProtocolUDP,
+		Timeout:  1,
+	}
+	if server != nil {
+		cfg.Resolvers = []string{server.Addr}
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+	return NewEngine(cfg, zap.NewNop())
+}
+
+func TestResolveCNAME(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.Add("www.example.com", mdns.TypeCNAME, "edge.example.net")
+	server.Add("edge.example.net", mdns.TypeCNAME, "lb.cdn.example")
+	server.Add("lb.cdn.example", mdns.TypeA, "192.0.2.1")
+	server.Add("old.example.com", mdns.TypeCNAME, "gone.herokuapp.example")
+	server.Add("ping.example.com", mdns.TypeCNAME, "pong.example.com")
+	server.Add("pong.example.com", mdns.TypeCNAME, "ping.example.com")
+	server.Add("self.example.com", mdns.TypeCNAME, "self.example.com")
+	server.Add("hop0.example.com", mdns.TypeCNAME, "hop1.example.com")
+	server.Add("hop1.example.com", mdns.TypeCNAME, "hop2.example.com")
+	server.Add("hop2.example.com", mdns.TypeCNAME, "hop3.example.com")
+	server.Add("hop3.example.com", mdns.TypeCNAME, "hop4.example.com")
+	server.Add("hop4.example.com", mdns.TypeA, "192.0.2.4")
+	server.Add("plain.example.com", mdns.TypeA, "192.0.2.2")
+
+	tests := []struct {
+		name   string
+		domain string
+		depth  int
+		chain  string
+		err    error
+	}{
+		{name: "chain", domain: "www.example.com", chain: "edge.example.net lb.cdn.example"},
+		{name: "dangling alias", domain: "old.example.com", chain: "gone.herokuapp.example"},
+		{name: "loop", domain: "ping.example.com", chain: "pong.example.com", err: ErrCNAMELoop},
+		{name: "self loop", domain: "self.example.com", err: ErrCNAMELoop},
+		{name: "within depth", domain: "hop0.example.com", depth: 4, chain: "hop1.example.com hop2.example.com hop3.example.com hop4.example.com"},
+		{name: "past depth", domain: "hop0.example.com", depth: 3, chain: "hop1.example.com hop2.example.com hop3.example.com", err: ErrCNAMEDepth},
+		{name: "not an alias", domain: "plain.example.com", err: ErrNoRecords},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(server, func(cfg *config.DNSConfig) { cfg.CNAMEDepth = tt.depth })
+
+			chain, err := e.ResolveCNAME(context.Background(), tt.domain)
+			if got := strings.Join(chain, " "); got != tt.chain {
+				t.Errorf("chain = %q, want %q", got, tt.chain)
+			}
+			if (tt.err == nil && err != nil) || (tt.err != nil && !errors.Is(err, tt.err)) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestResolveCNAMEDefaultDepthStopsLongChains(t *testing.T) {
+	server := dnstest.NewServer(t)
+	names := make([]string, defaultCNAMEDepth+3)
+	for i := range names {
+		names[i] = "hop" + strings.Repeat("x", i) + ".example.com"
+	}
+	for i := 0; i+1 < len(names); i++ {
+		server.Add(names[i], mdns.TypeCNAME, names[i+1])
+	}
+
+	chain, err := newTestEngine(server, nil).ResolveCNAME(context.Background(), names[0])
+	if !errors.Is(err, ErrCNAMEDepth) || len(chain) != defaultCNAMEDepth {
+		t.Errorf("got %d hops and %v, want %d hops and %v", len(chain), err, defaultCNAMEDepth, ErrCNAMEDepth)
+	}
+	// One query per hop followed, and one to find the chain goes on
+	if queries := server.TotalQueries(); queries != defaultCNAMEDepth+1 {
+		t.Errorf("%d queries, want %d", queries, defaultCNAMEDepth+1)
+	}
+}
+
+func TestResolveRecordsCollectsEachType(t *testing.T) {
+	server := dnstest.NewServer(t)
+	server.Add("example.com", mdns.TypeA, "192.0.2.1")
+	server.Add("example.com", mdns.TypeMX, "10 mx1.example.com", "20 mx2.mail.example.net")
+	server.Add("example.com", mdns.TypeNS, "ns1.example.com", "ns2.example.org")
+	server.Add("example.com", mdns.TypeTXT, "v=spf1 include:_spf.example.net ~all")
+	server.Add("mail.example.com", mdns.TypeMX, "10 mx.example.com")
+
+	e := newTestEngine(server, nil)
+	ctx := context.Background()
+
+	records, err := e.ResolveRecords(ctx, "example.com", []string{"A", "MX", "NS", "TXT", "AAAA"})
+	if err != nil {
+		t.Fatalf("ResolveRecords: %v", err)
+	}
+	if strings.Join(records.A, " ") != "192.0.2.1" {
+		t.Errorf("A = %v", records.A)
+	}
+	if strings.Join(records.MX, " ") != "mx1.example.com mx2.mail.example.net" {
+		t.Errorf("MX = %v, want the exchange names", records.MX)
+	}
+	if strings.Join(records.NS, " ") != "ns1.example.com ns2.example.org" {
+		t.Errorf("NS = %v", records.NS)
+	}
+	if strings.Join(records.TXT, " ") != "v=spf1 include:_spf.example.net ~all" {
+		t.Errorf("TXT = %v", records.TXT)
+	}
+	if len(records.AAAA) != 0 {
+		t.Errorf("AAAA = %v, want none", records.AAAA)
+	}
+
+	// Only the requested types are asked for
+	records, err = e.ResolveRecords(ctx, "mail.example.com", []string{"MX"})
+	if err != nil || strings.Join(records.MX, " ") != "mx.example.com" || len(records.A) != 0 {
+		t.Errorf("MX only = %+v, %v", records, err)
+	}
+
+	if _, err := e.ResolveRecords(ctx, "gone.example.com", []string{"MX", "TXT"}); !errors.Is(err, ErrNXDomain) {
+		t.Errorf("missing name err = %v, want ErrNXDomain", err)
+	}
+	if got := server.Queries("gone.example.com"); got != 1 {
+		t.Errorf("missing name queried %d times, want 1: NXDOMAIN holds for every type", got)
+	}
+}
@@ -0,0 +1,114 @@
+package dns
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ResolverStats counts one resolver's query outcomes. Failures are
+// timeouts, network errors and error responses; NXDOMAIN is an answer.
+type ResolverStats struct {
+	Resolver            string    `json:"resolver"`
+	Queries             int       `json:"queries"`
+	Failures            int       `json:"failures"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Ejections           int       `json:"ejections"`
+	EjectedUntil        time.Time `json:"ejected_until"`
+}
+
+// resolverHealth ejects resolvers after a run of consecutive failures and
+// re-admits them once a cooldown has passed, so a dead resolver stops
+// costing every Nth query its full timeout and retries
+type resolverHealth struct {
+	mu         sync.Mutex
+	ejectAfter int // consecutive failures before ejection (0 = never eject)
+	cooldown   time.Duration
+	stats      map[string]*ResolverStats
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+func newResolverHealth(ejectAfter int, cooldown time.Duration, logger *zap.Logger) *resolverHealth {
+	return &resolverHealth{
+		ejectAfter: ejectAfter,
+		cooldown:   cooldown,
+		stats:      make(map[string]*ResolverStats),
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// entry returns a resolver's counters, creating them on first use. The
+// caller holds mu.
+func (h *resolverHealth) entry(resolver string) *ResolverStats {
+	stats, ok := h.stats[resolver]
+	if !ok {
+		stats = &ResolverStats{Resolver: resolver}
+		h.stats[resolver] = stats
+	}
+	return stats
+}
+
+// record counts a query's outcome and ejects the resolver when it reaches
+// the failure threshold
+func (h *resolverHealth) record(resolver string, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := h.entry(resolver)
+	stats.Queries++
+	if !failed {
+		stats.ConsecutiveFailures = 0
+		return
+	}
+
+	stats.Failures++
+	stats.ConsecutiveFailures++
+	if h.ejectAfter <= 0 || stats.ConsecutiveFailures < h.ejectAfter {
+		return
+	}
+
+	// A re-admitted resolver has to fail the full threshold again
+	stats.ConsecutiveFailures = 0
+	stats.Ejections++
+	stats.EjectedUntil = h.now().Add(h.cooldown)
+
+	h.logger.Warn("Ejecting failing resolver",
+		zap.String("resolver", resolver),
+		zap.Int("consecutive_failures", h.ejectAfter),
+		zap.Duration("cooldown", h.cooldown),
+	)
+}
+
+// available reports whether a resolver is not currently ejected
+func (h *resolverHealth) available(resolver string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats, ok := h.stats[resolver]
+	return !ok || !h.now().Before(stats.EjectedUntil)
+}
+
+// snapshot returns a copy of every resolver's counters, sorted by resolver
+func (h *resolverHealth) snapshot() []ResolverStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := make([]ResolverStats, 0, len(h.stats))
+	for _, s := range h.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Resolver < stats[j].Resolver
+	})
+	return stats
+}
+
+// ResolverStats returns per-resolver query and failure counts, showing
+// which resolvers are flaky and which were ejected
+func (e *Engine) ResolverStats() []ResolverStats {
+	return e.health.snapshot()
+}
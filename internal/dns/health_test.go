@@ -0,0 +1,114 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+	"go.uber.org/zap"
+)
+
+// fakeClock is a settable time source
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func TestResolverHealthEjectsAndReadmits(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	h := newResolverHealth(3, 30*time.Second, zap.NewNop())
+	h.now = clock.now
+
+	const resolver = "192.0.2.53:53"
+
+	// A success in between resets the run
+	h.record(resolver, true)
+	h.record(resolver, true)
+	h.record(resolver, false)
+	h.record(resolver, true)
+	h.record(resolver, true)
+	if !h.available(resolver) {
+		t.Fatal("ejected before three consecutive failures")
+	}
+
+	h.record(resolver, true)
+	if h.available(resolver) {
+		t.Fatal("not ejected after three consecutive failures")
+	}
+
+	clock.t = clock.t.Add(29 * time.Second)
+	if h.available(resolver) {
+		t.Error("re-admitted before the cooldown passed")
+	}
+	clock.t = clock.t.Add(time.Second)
+	if !h.available(resolver) {
+		t.Fatal("not re-admitted after the cooldown")
+	}
+
+	// A re-admitted resolver has to fail the full threshold again
+	h.record(resolver, true)
+	if !h.available(resolver) {
+		t.Error("ejected again on a single failure after re-admission")
+	}
+
+	stats := h.snapshot()
+	if len(stats) != 1 {
+		t.Fatalf("got stats for %d resolvers, want 1", len(stats))
+	}
+	if got := stats[0]; got.Queries != 7 || got.Failures != 6 || got.Ejections != 1 || got.ConsecutiveFailures != 1 {
+		t.Errorf("stats = %+v, want 7 queries, 6 failures, 1 ejection, 1 consecutive failure", got)
+	}
+}
+
+func TestResolverHealthNeverEjectsWhenDisabled(t *testing.T) {
+	h := newResolverHealth(0, time.Minute, zap.NewNop())
+	for i := 0; i < 100; i++ {
+		h.record("192.0.2.53:53", true)
+	}
+	if !h.available("192.0.2.53:53") {
+		t.Error("resolver ejected with eject_after 0")
+	}
+}
+
+func TestEngineSkipsEjectedResolver(t *testing.T) {
+	bad := dnstest.NewServer(t)
+	bad.SetRcode(mdns.RcodeServerFailure)
+	good := dnstest.NewServer(t)
+	good.Add("www.example.com", mdns.TypeA, "192.0.2.1")
+
+	e := newTestEngine(nil, func(cfg *config.DNSConfig) {
+		cfg.Resolvers = []string{bad.Addr, good.Addr}
+		cfg.Health = config.DNSHealthConfig{EjectAfter: 2, Cooldown: 60}
+	})
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	e.health.now = clock.now
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		e.exchange(ctx, "www.example.com", mdns.TypeA)
+	}
+
+	// Rotation alternates until the second failure ejects the bad resolver
+	if got := bad.TotalQueries(); got != 2 {
+		t.Errorf("failing resolver got %d queries, want 2 before ejection", got)
+	}
+	if got := good.TotalQueries(); got != 8 {
+		t.Errorf("healthy resolver got %d queries, want the other 8", got)
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		e.exchange(ctx, "www.example.com", mdns.TypeA)
+	}
+	if got := bad.TotalQueries(); got != 3 {
+		t.Errorf("failing resolver got %d queries after the cooldown, want one more", got)
+	}
+
+	for _, stats := range e.ResolverStats() {
+		if stats.Resolver == bad.Addr && stats.Ejections != 1 {
+			t.Errorf("failing resolver ejected %d times, want 1", stats.Ejections)
+		}
+	}
+}
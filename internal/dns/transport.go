@@ -0,0 +1,176 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// Supported dns.protocol values
+const (
+	ProtocolUDP = "udp"
+	ProtocolTCP = "tcp"
+	ProtocolDoT = "dot" // DNS-over-TLS (RFC 7858)
+	ProtocolDoH = "doh" // DNS-over-HTTPS (RFC 8484)
+)
+
+// dohMediaType is the RFC 8484 wire-format content type
+const dohMediaType = "application/dns-message"
+
+// maxDoHResponse bounds a DoH response body; DNS messages fit in 64 KiB
+const maxDoHResponse = 65535
+
+// defaultPorts are used when a resolver has no explicit port
+var defaultPorts = map[string]string{
+	ProtocolUDP: "53",
+	ProtocolTCP: "53",
+	ProtocolDoT: "853",
+	ProtocolDoH: "443",
+}
+
+// resolverEndpoint is a parsed resolver entry. DoT resolvers may pin the
+// TLS server name with a "#" suffix, e.g. "1.1.1.1:853#cloudflare-dns.com";
+// without it the host itself is verified against the certificate.
+type resolverEndpoint struct {
+	address    string
+	serverName string
+}
+
+// parseResolver splits a resolver entry into its dial address and TLS name
+func parseResolver(resolver, protocol string) resolverEndpoint {
+	host, serverName, _ := strings.Cut(resolver, "#")
+
+	port := defaultPorts[protocol]
+	if port == "" {
+		port = defaultPorts[ProtocolUDP]
+	}
+
+	address := host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		address = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(address)
+	}
+
+	return resolverEndpoint{
+		address:    address,
+		serverName: serverName,
+	}
+}
+
+// newClient creates a DNS client for the configured protocol. DoT clients
+// are per resolver since each carries its own TLS server name.
+func newClient(protocol string, endpoint resolverEndpoint, timeout time.Duration) *mdns.Client {
+	switch protocol {
+	case ProtocolTCP:
+		return &mdns.Client{Net: "tcp", Timeout: timeout}
+	case ProtocolDoT:
+		return &mdns.Client{
+			Net:     "tcp-tls",
+			Timeout: timeout,
+			TLSConfig: &tls.Config{
+				ServerName: endpoint.serverName,
+				MinVersion: tls.VersionTLS12,
+			},
+		}
+	default:
+		return &mdns.Client{Net: "udp", Timeout: timeout}
+	}
+}
+
+// clientFor returns the client and dial address for a resolver, caching
+// per-resolver DoT clients
+func (e *Engine) clientFor(resolver string) (*mdns.Client, string) {
+	endpoint := parseResolver(resolver, e.protocol)
+
+	if e.protocol != ProtocolDoT {
+		return e.client, endpoint.address
+	}
+
+	e.mu.RLock()
+	client, ok := e.tlsClients[resolver]
+	e.mu.RUnlock()
+	if ok {
+		return client, endpoint.address
+	}
+
+	client = newClient(ProtocolDoT, endpoint, e.client.Timeout)
+
+	e.mu.Lock()
+	e.tlsClients[resolver] = client
+	e.mu.Unlock()
+
+	return client, endpoint.address
+}
+
+// newDoHClient creates the HTTP client DoH queries share; one keep-alive
+// connection per endpoint is reused across queries
+func newDoHClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	transport.MaxIdleConnsPerHost = 10
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// dohURL returns the endpoint of a DoH resolver entry: a full URL, e.g.
+// "https://cloudflare-dns.com/dns-query", or a bare host that gets the
+// standard /dns-query path
+func dohURL(resolver string) string {
+	if strings.Contains(resolver, "://") {
+		return resolver
+	}
+	return "https://" + resolver + "/dns-query"
+}
+
+// exchangeDoH sends a query to a DoH resolver as an RFC 8484 POST
+func (e *Engine) exchangeDoH(ctx context.Context, query *mdns.Msg, resolver string) (*mdns.Msg, error) {
+	// RFC 8484 asks for ID 0 so responses stay cacheable
+	query.Id = 0
+
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dohURL(resolver), bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dohMediaType)
+	req.Header.Set("Accept", dohMediaType)
+
+	resp, err := e.doh.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH resolver returned HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDoHResponse))
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(mdns.Msg)
+	if err := msg.Unpack(body); err != nil {
+		return nil, fmt.Errorf("invalid DoH response: %w", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,132 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mdns "github.com/miekg/dns"
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/dns/dnstest"
+)
+
+// dohServer serves RFC 8484 POSTs at /dns-query from zone's answers, and
+// fails the test on a malformed request
+func dohServer(t *testing.T, zone *dnstest.Server) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/dns-query" {
+			t.Errorf("DoH request %s %s, want POST /dns-query", r.Method, r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != dohMediaType {
+			t.Errorf("Content-Type %q, want %q", ct, dohMediaType)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		query := new(mdns.Msg)
+		if err := query.Unpack(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if query.Id != 0 {
+			t.Errorf("query ID %d, want 0 for cacheable DoH", query.Id)
+		}
+
+		packed, err := zone.Answer(query).Pack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", dohMediaType)
+		w.Write(packed)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newDoHEngine resolves through a DoH endpoint, trusting its test certificate
+func newDoHEngine(server *httptest.Server, endpoint string) *Engine {
+	e := newTestEngine(nil, func(cfg *config.DNSConfig) {
+		cfg.Resolvers = []string{endpoint}
+		cfg.Protocol = ProtocolDoH
+	})
+	e.doh = server.Client()
+	return e
+}
+
+func TestDoHResolves(t *testing.T) {
+	zone := dnstest.NewServer(t)
+	zone.Add("www.example.com", mdns.TypeA, "192.0.2.1")
+	zone.Add("www.example.com", mdns.TypeAAAA, "2001:db8::1")
+	server := dohServer(t, zone)
+	e := newDoHEngine(server, server.URL+"/dns-query")
+
+	ips, err := e.Resolve(context.Background(), "www.example.com")
+	if err != nil {
+		t.Fatalf("Resolve over DoH: %v", err)
+	}
+	if strings.Join(ips, " ") != "192.0.2.1 2001:db8::1" {
+		t.Errorf("ips = %v, want the A and AAAA records", ips)
+	}
+	if zone.Queries("www.example.com") != 2 {
+		t.Errorf("%d queries reached the zone, want 2", zone.Queries("www.example.com"))
+	}
+
+	if _, err := e.ResolveType(context.Background(), "missing.example.com", "A"); !errors.Is(err, ErrNXDomain) {
+		t.Errorf("missing name over DoH: %v, want %v", err, ErrNXDomain)
+	}
+}
+
+func TestDoHErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{
+			name: "http error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			},
+			want: "HTTP 503",
+		},
+		{
+			name: "not a dns message",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("<html>captive portal</html>"))
+			},
+			want: "invalid DoH response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(tt.handler)
+			defer server.Close()
+
+			_, err := newDoHEngine(server, server.URL+"/dns-query").ResolveType(context.Background(), "www.example.com", "A")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoHURL(t *testing.T) {
+	tests := map[string]string{
+		"cloudflare-dns.com":                   "https://cloudflare-dns.com/dns-query",
+		"dns.google:8443":                      "https://dns.google:8443/dns-query",
+		"https://doh.example.net/custom-path":  "https://doh.example.net/custom-path",
+		"https://cloudflare-dns.com/dns-query": "https://cloudflare-dns.com/dns-query",
+	}
+	for resolver, want := range tests {
+		if got := dohURL(resolver); got != want {
+			t.Errorf("dohURL(%q) = %q, want %q", resolver, got, want)
+		}
+	}
+}
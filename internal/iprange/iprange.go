@@ -0,0 +1,36 @@
+// Package iprange holds compiled-in lists of IP networks, such as CDN edge
+// ranges and special-purpose address blocks
+package iprange
+
+import (
+	"fmt"
+	"net"
+)
+
+// List is a set of IP networks
+type List []*net.IPNet
+
+// MustParse converts CIDR strings into a list. The lists are compiled in,
+// so a malformed CIDR is a programming error and panics rather than
+// silently leaving a hole in the list.
+func MustParse(cidrs ...string) List {
+	networks := make(List, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(fmt.Sprintf("iprange: bad range %q: %v", cidr, err))
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// Contains reports whether ip falls inside any of the networks
+func (l List) Contains(ip net.IP) bool {
+	for _, network := range l {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
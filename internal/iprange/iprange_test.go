@@ -0,0 +1,44 @@
+package iprange
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestContains(t *testing.T) {
+	list := MustParse("10.0.0.0/8", "192.0.2.0/24", "2001:db8::/32")
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.0.0.0", true},
+		{"10.255.255.255", true},
+		{"11.0.0.0", false},
+		{"192.0.2.128", true},
+		{"192.0.3.1", false},
+		{"2001:db8:ffff::1", true},
+		{"2001:db9::1", false},
+		{"::ffff:10.1.2.3", true}, // IPv4-mapped
+	}
+
+	for _, tt := range tests {
+		if got := list.Contains(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+
+	if List(nil).Contains(net.ParseIP("10.0.0.1")) {
+		t.Error("empty list contains an address")
+	}
+}
+
+func TestMustParsePanicsOnBadRange(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "10.0.0.0/33") {
+			t.Errorf("recovered %v, want a panic naming the bad range", r)
+		}
+	}()
+	MustParse("10.0.0.0/8", "10.0.0.0/33")
+}
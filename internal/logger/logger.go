@@ -3,6 +3,7 @@ package logger
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -63,6 +64,18 @@ func New(level, logFile string) (*zap.Logger, error) {
 	return logger, nil
 }
 
+// Sampled returns a logger that, per message and per second, writes the
+// first entries and then every thereafter-th one, for high-volume debug
+// lines such as one per discovered subdomain. first <= 0 disables sampling.
+func Sampled(logger *zap.Logger, first, thereafter int) *zap.Logger {
+	if first <= 0 {
+		return logger
+	}
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, time.Second, first, thereafter)
+	}))
+}
+
 // NewDevelopment creates a development logger (more verbose)
 func NewDevelopment() (*zap.Logger, error) {
 	config := zap.NewDevelopmentConfig()
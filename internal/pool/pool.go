@@ -0,0 +1,112 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// PanicError is a panic recovered from a worker function
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error implements error
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Run calls fn for every item using at most workers goroutines and waits
+// for them to finish. Items not yet started when ctx is canceled are
+// skipped. A panic in fn is recovered and reported in the returned error
+// (one *PanicError per panic, joined) instead of crashing the process; the
+// remaining items still run.
+func Run[T any](ctx context.Context, items []T, workers int, fn func(ctx context.Context, item T)) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	work := make(chan T, len(items))
+	for _, item := range items {
+		work <- item
+	}
+	close(work)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		panics []error
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range work {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := call(ctx, item, fn); err != nil {
+					mu.Lock()
+					panics = append(panics, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(panics...)
+}
+
+// Recover, when deferred, stops a panic in the current goroutine and hands
+// it to handle. Use it where the caller needs to attribute the panic, e.g.
+// to the source that raised it.
+func Recover(handle func(*PanicError)) {
+	if r := recover(); r != nil {
+		handle(&PanicError{Value: r, Stack: debug.Stack()})
+	}
+}
+
+// call invokes fn for one item, converting a panic into an error
+func call[T any](ctx context.Context, item T, fn func(ctx context.Context, item T)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	fn(ctx, item)
+	return nil
+}
+
+// Panics returns the panics reported in an error returned by Run
+func Panics(err error) []*PanicError {
+	if err == nil {
+		return nil
+	}
+
+	var found []*PanicError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			found = append(found, Panics(e)...)
+		}
+		return found
+	}
+
+	var p *PanicError
+	if errors.As(err, &p) {
+		found = append(found, p)
+	}
+	return found
+}
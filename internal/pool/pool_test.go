@@ -0,0 +1,128 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunCallsEveryItemOnce(t *testing.T) {
+	items := make([]int, 200)
+	for i := range items {
+		items[i] = i
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[int]int)
+	)
+	err := Run(context.Background(), items, 8, func(ctx context.Context, item int) {
+		mu.Lock()
+		seen[item]++
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for _, item := range items {
+		if seen[item] != 1 {
+			t.Errorf("item %d ran %d times, want once", item, seen[item])
+		}
+	}
+}
+
+func TestRunSingleWorkerKeepsOrder(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+
+	var order []string
+	// workers <= 0 means one worker
+	if err := Run(context.Background(), items, 0, func(ctx context.Context, item string) {
+		order = append(order, item)
+	}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for i := range items {
+		if i >= len(order) || order[i] != items[i] {
+			t.Fatalf("ran %v, want %v", order, items)
+		}
+	}
+}
+
+func TestRunStopsStartingItemsWhenCanceled(t *testing.T) {
+	items := make([]int, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ran atomic.Int32
+	Run(ctx, items, 2, func(ctx context.Context, item int) {
+		if ran.Add(1) == 10 {
+			cancel()
+		}
+	})
+
+	// Each worker may finish the item it already took
+	if got := ran.Load(); got < 10 || got > 11 {
+		t.Errorf("%d items ran, want the run to stop after the 10th", got)
+	}
+
+	ran.Store(0)
+	Run(ctx, items, 4, func(ctx context.Context, item int) { ran.Add(1) })
+	if got := ran.Load(); got != 0 {
+		t.Errorf("%d items ran with a canceled context, want none", got)
+	}
+}
+
+func TestRunRecoversPanics(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6}
+
+	var ran atomic.Int32
+	err := Run(context.Background(), items, 3, func(ctx context.Context, item int) {
+		ran.Add(1)
+		if item%3 == 0 {
+			panic(item)
+		}
+	})
+
+	if got := ran.Load(); got != int32(len(items)) {
+		t.Errorf("%d items ran, want all %d despite panics", got, len(items))
+	}
+
+	panics := Panics(err)
+	if len(panics) != 2 {
+		t.Fatalf("got %d panics from %v, want 2", len(panics), err)
+	}
+	values := map[interface{}]bool{}
+	for _, p := range panics {
+		values[p.Value] = true
+		if len(p.Stack) == 0 {
+			t.Errorf("panic %v recorded without a stack", p.Value)
+		}
+	}
+	if !values[3] || !values[6] {
+		t.Errorf("panic values %v, want 3 and 6", values)
+	}
+
+	var p *PanicError
+	if !errors.As(err, &p) {
+		t.Errorf("errors.As found no *PanicError in %v", err)
+	}
+}
+
+func TestRecoverHandsOverPanic(t *testing.T) {
+	var recovered *PanicError
+	func() {
+		defer Recover(func(p *PanicError) { recovered = p })
+		panic("boom")
+	}()
+
+	if recovered == nil || recovered.Value != "boom" {
+		t.Errorf("recovered %+v, want the panic value", recovered)
+	}
+	if Panics(nil) != nil {
+		t.Error("Panics(nil) should be empty")
+	}
+}
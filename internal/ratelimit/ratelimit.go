@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket allowing n requests per period: it holds at
+// most n tokens, refilled evenly over the period, and each request takes
+// one. A nil Limiter allows everything.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration // time to refill one token
+	burst    float64
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// New creates a limiter allowing n requests per period, starting full so
+// the first n go out at once. It returns nil (no limit) when n or period is
+// not positive.
+func New(n int, period time.Duration) *Limiter {
+	if n <= 0 || period <= 0 {
+		return nil
+	}
+	return &Limiter{
+		interval: period / time.Duration(n),
+		burst:    float64(n),
+		tokens:   float64(n),
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// Wait blocks until a request may be made or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	delay := l.reserve()
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// The request is not made; its token goes back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token, returning how long to wait until it is available
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
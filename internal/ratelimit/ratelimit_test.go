@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+// newFakeLimiter returns a limiter of n per period reading time from a
+// fake clock
+func newFakeLimiter(n int, period time.Duration) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	l := New(n, period)
+	l.now = clock.now
+	l.last = clock.t
+	return l, clock
+}
+
+func TestReservePacesAfterBurst(t *testing.T) {
+	l, clock := newFakeLimiter(4, time.Second)
+
+	// A full bucket lets the first four through at once
+	for i := 0; i < 4; i++ {
+		if delay := l.reserve(); delay != 0 {
+			t.Fatalf("request %d of the burst delayed %v", i+1, delay)
+		}
+	}
+
+	// Then one every 250ms, queued behind each other
+	for i, want := range []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, 750 * time.Millisecond} {
+		if delay := l.reserve(); delay != want {
+			t.Errorf("request %d delayed %v, want %v", i+5, delay, want)
+		}
+	}
+
+	// Once the queue has drained and another 250ms passed, one goes out
+	clock.t = clock.t.Add(time.Second)
+	if delay := l.reserve(); delay != 0 {
+		t.Errorf("request after the refill delayed %v", delay)
+	}
+	if delay := l.reserve(); delay != 250*time.Millisecond {
+		t.Errorf("next request delayed %v, want 250ms", delay)
+	}
+}
+
+func TestReserveCapsBurstAfterIdle(t *testing.T) {
+	l, clock := newFakeLimiter(2, time.Minute)
+
+	// An hour idle refills the bucket to two, not sixty
+	clock.t = clock.t.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if delay := l.reserve(); delay != 0 {
+			t.Fatalf("request %d delayed %v after idling", i+1, delay)
+		}
+	}
+	if delay := l.reserve(); delay != 30*time.Second {
+		t.Errorf("third request delayed %v, want 30s", delay)
+	}
+}
+
+func TestWaitPaces(t *testing.T) {
+	l := New(1, 50*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+
+	// The first is free, the other three wait 50ms each
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("four requests at 1 per 50ms took %v, want at least 150ms", elapsed)
+	}
+}
+
+func TestWaitCanceledReturnsToken(t *testing.T) {
+	l, clock := newFakeLimiter(1, time.Hour)
+	l.reserve()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Fatalf("Wait = %v, want canceled", err)
+	}
+
+	// The canceled request's token is back: after one refill interval
+	// there is a token to spend rather than a debt
+	clock.t = clock.t.Add(time.Hour)
+	if delay := l.reserve(); delay != 0 {
+		t.Errorf("request after a canceled wait delayed %v", delay)
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	if New(0, time.Second) != nil || New(5, 0) != nil {
+		t.Error("limiter without a rate isn't nil")
+	}
+
+	var l *Limiter
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter Wait = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Errorf("nil limiter Wait on a canceled context = %v", err)
+	}
+}
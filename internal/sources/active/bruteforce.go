@@ -0,0 +1,300 @@
+package active
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// ErrNoResolver is returned when brute force runs without a scan context
+// and no resolver was set
+var ErrNoResolver = errors.New("brute force needs a DNS resolver")
+
+// BruteForce resolves word.domain for every word of the configured
+// wordlists. Words are resolved a chunk at a time and hits are streamed as
+// each chunk finishes, so only the word list is held in memory, never the
+// generated names. Hits whose addresses match the scan's wildcard are
+// dropped.
+type BruteForce struct {
+	config  *config.ActiveSourcesConfig
+	logger  *zap.Logger
+	enabled bool
+
+	cacheDir string
+	resume   bool
+
+	// Used when enumerating without a scan context (optional)
+	resolver types.BatchResolver
+
+	// Record types a candidate is resolved with (dns.query_types.bruteforce)
+	queryTypes []string
+
+	// Drops implausible words before they cost DNS queries
+	filter *sources.CandidateFilter
+
+	// Progress of the current run
+	tried atomic.Int64
+	total atomic.Int64
+	hits  atomic.Int64
+}
+
+// NewBruteForce creates a wordlist brute-force source. Progress is saved
+// under cacheDir after every chunk.
+func NewBruteForce(cfg *config.ActiveSourcesConfig, cacheDir string, logger *zap.Logger) *BruteForce {
+	return &BruteForce{
+		config:   cfg,
+		logger:   logger,
+		enabled:  cfg.DNSBruteforce,
+		cacheDir: cacheDir,
+		filter:   sources.NewCandidateFilter(cfg.MinLabelLength, cfg.AllowedCharset),
+	}
+}
+
+// Name returns the source identifier
+func (b *BruteForce) Name() string {
+	return "dns_bruteforce"
+}
+
+// Type returns the source category
+func (b *BruteForce) Type() sources.SourceType {
+	return sources.TypeActive
+}
+
+// IsEnabled checks if the source is enabled
+func (b *BruteForce) IsEnabled() bool {
+	return b.enabled
+}
+
+// RateLimit returns the rate limit; queries are bounded by the scan's
+// resolver instead
+func (b *BruteForce) RateLimit() int {
+	return 0
+}
+
+// RequiresNetwork reports that candidates are resolved over DNS
+func (b *BruteForce) RequiresNetwork() bool {
+	return true
+}
+
+// SetResolver sets the resolver used by Enumerate and EnumerateStream,
+// which have no scan context to take one from
+func (b *BruteForce) SetResolver(resolver types.BatchResolver) {
+	b.resolver = resolver
+}
+
+// SetQueryTypes sets the record types candidates are queried for
+// (dns.query_types.bruteforce); an answer of any of them is a hit. Empty
+// resolves A and AAAA.
+func (b *BruteForce) SetQueryTypes(qtypes []string) {
+	b.queryTypes = qtypes
+}
+
+// SetResume makes the next run continue from a checkpoint left by an
+// interrupted one for the same domain and wordlists
+func (b *BruteForce) SetResume(resume bool) {
+	b.resume = resume
+}
+
+// Progress reports how many words have been tried and how many resolved
+func (b *BruteForce) Progress() sources.Progress {
+	return sources.Progress{
+		Tried: int(b.tried.Load()),
+		Total: int(b.total.Load()),
+		Hits:  int(b.hits.Load()),
+	}
+}
+
+// Enumerate brute-forces domain and returns every hit at once
+func (b *BruteForce) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	startTime := time.Now()
+
+	result := &types.SourceResult{Source: b.Name()}
+
+	names, errs := b.EnumerateStream(ctx, domain)
+	for name := range names {
+		result.Subdomains = append(result.Subdomains, name)
+	}
+
+	result.Duration = time.Since(startTime)
+	if err := <-errs; err != nil {
+		result.Error = err
+		return result, err
+	}
+	return result, nil
+}
+
+// EnumerateStream brute-forces domain with the resolver set by SetResolver
+func (b *BruteForce) EnumerateStream(ctx context.Context, domain string) (<-chan string, <-chan error) {
+	return b.EnumerateScanStream(ctx, &types.ScanContext{
+		Domain:   domain,
+		Root:     domain,
+		Resolver: b.resolver,
+	})
+}
+
+// EnumerateScanStream brute-forces the scan root with the scan's resolver,
+// skipping names another source already tried and dropping wildcard hits
+func (b *BruteForce) EnumerateScanStream(ctx context.Context, scan *types.ScanContext) (<-chan string, <-chan error) {
+	names := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(names)
+
+		if err := b.run(ctx, scan, names); err != nil {
+			errs <- err
+		}
+	}()
+
+	return names, errs
+}
+
+// run resolves the wordlists against the scan root, sending hits on names
+func (b *BruteForce) run(ctx context.Context, scan *types.ScanContext, names chan<- string) error {
+	if scan.Resolver == nil {
+		return ErrNoResolver
+	}
+
+	domain := strings.ToLower(strings.TrimSuffix(scan.Root, "."))
+	words, err := b.loadWordlists(domain)
+	if err != nil {
+		return err
+	}
+
+	workers := activeWorkers(scan, b.config)
+
+	checkpoint := sources.NewCheckpoint(b.cacheDir, b.Name(), domain, words, b.config.ChunkSize, b.logger)
+	if b.resume {
+		checkpoint.Resume()
+	}
+
+	b.tried.Store(int64(checkpoint.Offset()))
+	b.total.Store(int64(len(words)))
+	b.hits.Store(int64(checkpoint.Hits()))
+
+	b.logger.Info("Starting DNS brute force",
+		zap.String("domain", domain),
+		zap.Int("words", len(words)),
+		zap.Int("workers", workers),
+	)
+
+	wildcardDropped := 0
+	err = checkpoint.Run(ctx, words, func(ctx context.Context, chunk []string) int {
+		candidates := make([]string, 0, len(chunk))
+		for _, word := range chunk {
+			candidate := word + "." + domain
+			if scan.Attempted != nil && !scan.Attempted.Claim(candidate) {
+				continue
+			}
+			candidates = append(candidates, candidate)
+		}
+
+		resolved := b.resolve(ctx, scan.Resolver, candidates, workers)
+
+		hits := 0
+		for _, candidate := range candidates {
+			ips, ok := resolved[candidate]
+			if !ok {
+				continue
+			}
+			if isWildcardHit(scan, ips) {
+				wildcardDropped++
+				continue
+			}
+
+			select {
+			case names <- candidate:
+				hits++
+			case <-ctx.Done():
+				return hits
+			}
+		}
+
+		b.tried.Add(int64(len(chunk)))
+		b.hits.Add(int64(hits))
+		return hits
+	})
+
+	b.logger.Info("DNS brute force complete",
+		zap.String("domain", domain),
+		zap.Int("tried", checkpoint.Offset()),
+		zap.Int("hits", checkpoint.Hits()),
+		zap.Int("wildcard_dropped", wildcardDropped),
+	)
+
+	return err
+}
+
+// resolve resolves candidates with the configured record types when the
+// resolver can query them, else their A/AAAA addresses. Every hit maps to
+// its addresses, which are empty for a hit answering only, say, CNAME.
+func (b *BruteForce) resolve(ctx context.Context, resolver types.BatchResolver, candidates []string, workers int) map[string][]string {
+	records, ok := resolver.(types.RecordBatchResolver)
+	if !ok || len(b.queryTypes) == 0 {
+		return resolver.ResolveBatch(ctx, candidates, workers)
+	}
+
+	resolved := make(map[string][]string)
+	for name, answers := range records.ResolveBatchRecords(ctx, candidates, workers, b.queryTypes) {
+		ips := make([]string, 0, len(answers.A)+len(answers.AAAA))
+		ips = append(ips, answers.A...)
+		resolved[name] = append(ips, answers.AAAA...)
+	}
+	return resolved
+}
+
+// loadWordlists reads the configured wordlists into one list of plausible,
+// distinct words, in file order. Missing files are skipped with a warning;
+// it fails only if no file yields a word.
+func (b *BruteForce) loadWordlists(domain string) ([]string, error) {
+	seen := make(map[string]bool)
+	var words []string
+
+	for _, path := range b.config.Wordlists {
+		file, err := os.Open(path)
+		if err != nil {
+			b.logger.Warn("Skipping unreadable wordlist",
+				zap.String("path", path),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+			word = strings.Trim(word, ".")
+			if word == "" || strings.HasPrefix(word, "#") || seen[word] {
+				continue
+			}
+			seen[word] = true
+
+			// Multi-label words (dev.api) are kept if every label passes
+			if !b.filter.Allow(word+"."+domain, domain) {
+				continue
+			}
+			words = append(words, word)
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read wordlist %s: %w", path, err)
+		}
+	}
+
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no usable words in wordlists %v", b.config.Wordlists)
+	}
+	return words, nil
+}
@@ -0,0 +1,339 @@
+package active
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// stubResolver answers from fixed records and remembers what it was asked
+type stubResolver struct {
+	records map[string]*types.DNSRecords
+
+	mu       sync.Mutex
+	batches  int      // ResolveBatch calls
+	qtypes   []string // record types of the last ResolveBatchRecords call
+	resolved []string // every name asked for
+}
+
+func (s *stubResolver) ResolveBatch(ctx context.Context, domains []string, workers int) map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches++
+	s.resolved = append(s.resolved, domains...)
+
+	results := make(map[string][]string)
+	for _, domain := range domains {
+		if records, ok := s.records[domain]; ok && len(records.A)+len(records.AAAA) > 0 {
+			results[domain] = append(append([]string{}, records.A...), records.AAAA...)
+		}
+	}
+	return results
+}
+
+func (s *stubResolver) ResolveBatchRecords(ctx context.Context, domains []string, workers int, qtypes []string) map[string]*types.DNSRecords {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.qtypes = qtypes
+	s.resolved = append(s.resolved, domains...)
+
+	results := make(map[string]*types.DNSRecords)
+	for _, domain := range domains {
+		records, ok := s.records[domain]
+		if !ok {
+			continue
+		}
+		answered := &types.DNSRecords{}
+		for _, qtype := range qtypes {
+			switch qtype {
+			case "A":
+				answered.A = records.A
+			case "AAAA":
+				answered.AAAA = records.AAAA
+			case "CNAME":
+				answered.CNAME = records.CNAME
+			}
+		}
+		if len(answered.A)+len(answered.AAAA)+len(answered.CNAME) > 0 {
+			results[domain] = answered
+		}
+	}
+	return results
+}
+
+// writeWordlist writes words to a wordlist file in the test's temp dir
+func writeWordlist(t *testing.T, words ...string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(words, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("write wordlist: %v", err)
+	}
+	return path
+}
+
+// bruteForce runs a brute force of example.com with resolver and returns
+// the sorted hits
+func bruteForce(t *testing.T, cfg *config.ActiveSourcesConfig, qtypes []string, resolver types.BatchResolver) []string {
+	t.Helper()
+
+	cfg.DNSBruteforce = true
+	b := NewBruteForce(cfg, t.TempDir(), zap.NewNop())
+	b.SetQueryTypes(qtypes)
+	b.SetResolver(resolver)
+
+	result, err := b.Enumerate(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Enumerate: %v", err)
+	}
+	sort.Strings(result.Subdomains)
+	return result.Subdomains
+}
+
+func TestBruteForceQueriesConfiguredTypes(t *testing.T) {
+	resolver := &stubResolver{records: map[string]*types.DNSRecords{
+		"www.example.com":  {A: []string{"192.0.2.1"}},
+		"shop.example.com": {CNAME: []string{"shops.myshopify.com"}},
+		"ipv6.example.com": {AAAA: []string{"2001:db8::1"}},
+	}}
+	cfg := &config.ActiveSourcesConfig{Wordlists: []string{writeWordlist(t, "www", "shop", "ipv6", "missing")}}
+
+	hits := bruteForce(t, cfg, []string{"A", "CNAME"}, resolver)
+
+	want := []string{"shop.example.com", "www.example.com"}
+	if strings.Join(hits, " ") != strings.Join(want, " ") {
+		t.Errorf("hits = %v, want %v", hits, want)
+	}
+	if strings.Join(resolver.qtypes, " ") != "A CNAME" {
+		t.Errorf("queried types = %v, want [A CNAME]", resolver.qtypes)
+	}
+	if resolver.batches != 0 {
+		t.Errorf("ResolveBatch called %d times, want only typed queries", resolver.batches)
+	}
+}
+
+func TestBruteForceWithoutQueryTypesResolvesAddresses(t *testing.T) {
+	resolver := &stubResolver{records: map[string]*types.DNSRecords{
+		"www.example.com":  {A: []string{"192.0.2.1"}},
+		"shop.example.com": {CNAME: []string{"shops.myshopify.com"}},
+		"ipv6.example.com": {AAAA: []string{"2001:db8::1"}},
+	}}
+	cfg := &config.ActiveSourcesConfig{Wordlists: []string{writeWordlist(t, "www", "shop", "ipv6")}}
+
+	hits := bruteForce(t, cfg, nil, resolver)
+
+	want := []string{"ipv6.example.com", "www.example.com"}
+	if strings.Join(hits, " ") != strings.Join(want, " ") {
+		t.Errorf("hits = %v, want %v", hits, want)
+	}
+	if resolver.batches == 0 || resolver.qtypes != nil {
+		t.Errorf("got %d address batches and typed query %v, want address batches only", resolver.batches, resolver.qtypes)
+	}
+}
+
+func TestBruteForceFiltersImplausibleLabels(t *testing.T) {
+	words := []string{"www", "a", "dev.api", "dev.x", "api2", "mail_01", "-edge", "edge-", "CDN", "münchen"}
+
+	tests := []struct {
+		name      string
+		minLength int
+		charset   string
+		want      []string
+	}{
+		{
+			name:      "defaults",
+			minLength: 2,
+			charset:   "a-z0-9-",
+			want:      []string{"api2", "cdn", "dev.api", "www"},
+		},
+		{
+			name:      "letters only",
+			minLength: 2,
+			charset:   "a-z",
+			want:      []string{"cdn", "dev.api", "www"},
+		},
+		{
+			name:      "single characters allowed",
+			minLength: 1,
+			charset:   "",
+			want:      []string{"a", "api2", "cdn", "dev.api", "dev.x", "www"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &stubResolver{}
+			cfg := &config.ActiveSourcesConfig{
+				Wordlists:      []string{writeWordlist(t, words...)},
+				MinLabelLength: tt.minLength,
+				AllowedCharset: tt.charset,
+			}
+			bruteForce(t, cfg, nil, resolver)
+
+			var asked []string
+			for _, name := range resolver.resolved {
+				asked = append(asked, strings.TrimSuffix(name, ".example.com"))
+			}
+			sort.Strings(asked)
+			if strings.Join(asked, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("resolved %v, want %v", asked, tt.want)
+			}
+		})
+	}
+}
+
+// collect drains a brute force stream and returns the sorted hits and the
+// run's error
+func collect(names <-chan string, errs <-chan error) ([]string, error) {
+	var hits []string
+	for name := range names {
+		hits = append(hits, name)
+	}
+	sort.Strings(hits)
+	return hits, <-errs
+}
+
+func TestBruteForceDropsWildcardHits(t *testing.T) {
+	resolver := &stubResolver{records: map[string]*types.DNSRecords{
+		"www.example.com":   {A: []string{"192.0.2.10"}},
+		"junk.example.com":  {A: []string{"192.0.2.1"}},
+		"noise.example.com": {A: []string{"192.0.2.1"}, AAAA: []string{"2001:db8::1"}},
+		"mixed.example.com": {A: []string{"192.0.2.1", "192.0.2.11"}},
+	}}
+	cfg := &config.ActiveSourcesConfig{
+		DNSBruteforce: true,
+		Wordlists:     []string{writeWordlist(t, "www", "junk", "noise", "mixed")},
+	}
+	scan := &types.ScanContext{
+		Domain:   "example.com",
+		Root:     "example.com",
+		Resolver: resolver,
+		Wildcard: &types.WildcardInfo{
+			IsWildcard: true,
+			Patterns:   []string{"192.0.2.1"},
+			PatternsV6: []string{"2001:db8::1"},
+		},
+	}
+
+	b := NewBruteForce(cfg, t.TempDir(), zap.NewNop())
+	hits, err := collect(b.EnumerateScanStream(context.Background(), scan))
+	if err != nil {
+		t.Fatalf("brute force: %v", err)
+	}
+
+	// A hit is dropped only if every address is a wildcard answer
+	want := []string{"mixed.example.com", "www.example.com"}
+	if strings.Join(hits, " ") != strings.Join(want, " ") {
+		t.Errorf("hits = %v, want %v", hits, want)
+	}
+	if got := b.Progress(); got.Hits != len(want) {
+		t.Errorf("progress counts %d hits, want %d", got.Hits, len(want))
+	}
+}
+
+func TestBruteForceResolvesInChunks(t *testing.T) {
+	resolver := &stubResolver{records: map[string]*types.DNSRecords{
+		"www.example.com":  {A: []string{"192.0.2.1"}},
+		"api.example.com":  {A: []string{"192.0.2.2"}},
+		"mail.example.com": {A: []string{"192.0.2.3"}},
+	}}
+	cfg := &config.ActiveSourcesConfig{
+		ChunkSize: 2,
+		Wordlists: []string{
+			writeWordlist(t, "www", "dev", "api", "# comment", ""),
+			writeWordlist(t, "API", "mail", "vpn", "www."),
+			filepath.Join(t.TempDir(), "missing.txt"),
+		},
+	}
+
+	hits := bruteForce(t, cfg, nil, resolver)
+
+	want := []string{"api.example.com", "mail.example.com", "www.example.com"}
+	if strings.Join(hits, " ") != strings.Join(want, " ") {
+		t.Errorf("hits = %v, want %v", hits, want)
+	}
+
+	// Five distinct words across both lists, two per chunk
+	if resolver.batches != 3 {
+		t.Errorf("resolved in %d batches, want 3 chunks", resolver.batches)
+	}
+	if len(resolver.resolved) != 5 {
+		t.Errorf("resolved %v, want each of the 5 distinct words once", resolver.resolved)
+	}
+}
+
+func TestBruteForceStreamsHits(t *testing.T) {
+	resolver := &stubResolver{records: map[string]*types.DNSRecords{
+		"www.example.com": {A: []string{"192.0.2.1"}},
+		"api.example.com": {A: []string{"192.0.2.2"}},
+	}}
+	cfg := &config.ActiveSourcesConfig{
+		DNSBruteforce: true,
+		ChunkSize:     1,
+		Wordlists:     []string{writeWordlist(t, "www", "dev", "api")},
+	}
+	b := NewBruteForce(cfg, t.TempDir(), zap.NewNop())
+	b.SetResolver(resolver)
+
+	names, errs := b.EnumerateStream(context.Background(), "example.com")
+
+	// The first hit arrives while the last one is still waiting to be read
+	if name := <-names; name != "www.example.com" {
+		t.Fatalf("first hit = %q, want www.example.com", name)
+	}
+	select {
+	case <-errs:
+		t.Fatal("run finished before its hits were read")
+	default:
+	}
+
+	hits, err := collect(names, errs)
+	if err != nil {
+		t.Fatalf("brute force: %v", err)
+	}
+	if strings.Join(hits, " ") != "api.example.com" {
+		t.Errorf("remaining hits = %v, want [api.example.com]", hits)
+	}
+	if got := b.Progress(); got != (sources.Progress{Tried: 3, Total: 3, Hits: 2}) {
+		t.Errorf("progress = %+v, want 3 of 3 tried with 2 hits", got)
+	}
+}
+
+func TestBruteForceSkipsAttemptedNames(t *testing.T) {
+	resolver := &stubResolver{records: map[string]*types.DNSRecords{
+		"www.example.com": {A: []string{"192.0.2.1"}},
+		"api.example.com": {A: []string{"192.0.2.2"}},
+	}}
+	attempted := sources.NewAttempted()
+	attempted.Claim("www.example.com")
+
+	cfg := &config.ActiveSourcesConfig{Wordlists: []string{writeWordlist(t, "www", "api")}}
+	scan := &types.ScanContext{Domain: "example.com", Root: "example.com", Resolver: resolver, Attempted: attempted}
+
+	hits, err := collect(NewBruteForce(cfg, t.TempDir(), zap.NewNop()).EnumerateScanStream(context.Background(), scan))
+	if err != nil {
+		t.Fatalf("brute force: %v", err)
+	}
+	if strings.Join(hits, " ") != "api.example.com" || strings.Join(resolver.resolved, " ") != "api.example.com" {
+		t.Errorf("hits %v after resolving %v, want api.example.com only", hits, resolver.resolved)
+	}
+}
+
+func TestBruteForceNeedsResolver(t *testing.T) {
+	cfg := &config.ActiveSourcesConfig{DNSBruteforce: true, Wordlists: []string{writeWordlist(t, "www")}}
+	b := NewBruteForce(cfg, t.TempDir(), zap.NewNop())
+
+	if _, err := b.Enumerate(context.Background(), "example.com"); !errors.Is(err, ErrNoResolver) {
+		t.Errorf("err = %v, want ErrNoResolver", err)
+	}
+}
@@ -0,0 +1,281 @@
+package active
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// environmentTags are the deployment stages swapped into and out of names
+var environmentTags = []string{"dev", "stage", "staging", "prod", "test", "qa", "uat"}
+
+// Permutations varies the first label of names other sources found:
+// environment tags added, swapped or dropped, numbers stepped, dashes
+// removed or turned into dots. Each name yields a few dozen candidates at
+// most and the total is capped at max_candidates, so large inputs grow the
+// work linearly, never combinatorially.
+type Permutations struct {
+	config  *config.ActiveSourcesConfig
+	logger  *zap.Logger
+	enabled bool
+
+	// Drops implausible candidates before they cost DNS queries
+	filter *sources.CandidateFilter
+}
+
+// NewPermutations creates a permutation source
+func NewPermutations(cfg *config.ActiveSourcesConfig, logger *zap.Logger) *Permutations {
+	return &Permutations{
+		config:  cfg,
+		logger:  logger,
+		enabled: cfg.Permutations,
+		filter:  sources.NewCandidateFilter(cfg.MinLabelLength, cfg.AllowedCharset),
+	}
+}
+
+// Name returns the source identifier
+func (p *Permutations) Name() string {
+	return "permutations"
+}
+
+// Type returns the source category
+func (p *Permutations) Type() sources.SourceType {
+	return sources.TypeActive
+}
+
+// IsEnabled checks if the source is enabled
+func (p *Permutations) IsEnabled() bool {
+	return p.enabled
+}
+
+// RateLimit returns the rate limit; queries are bounded by the scan's
+// resolver instead
+func (p *Permutations) RateLimit() int {
+	return 0
+}
+
+// RequiresNetwork reports that candidates are resolved over DNS
+func (p *Permutations) RequiresNetwork() bool {
+	return true
+}
+
+// ExpandsResults reports that permutations build on other sources' names
+func (p *Permutations) ExpandsResults() bool {
+	return true
+}
+
+// Enumerate has no discovered names to permute without a scan context
+func (p *Permutations) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	return &types.SourceResult{Source: p.Name()}, nil
+}
+
+// EnumerateScan permutes the names discovered so far and returns those
+// that resolve, skipping names already tried and wildcard hits
+func (p *Permutations) EnumerateScan(ctx context.Context, scan *types.ScanContext) (*types.SourceResult, error) {
+	startTime := time.Now()
+
+	result := &types.SourceResult{Source: p.Name()}
+	if scan.Resolver == nil || scan.Results == nil {
+		return result, nil
+	}
+
+	known := make(map[string]bool)
+	var seeds []string
+	for _, sub := range scan.Results.Snapshot() {
+		known[sub.Domain] = true
+		seeds = append(seeds, sub.Domain)
+	}
+
+	var candidates []string
+	for _, candidate := range Permute(seeds, scan.Root, p.config.MaxCandidates) {
+		if known[candidate] || !scan.InScope(candidate) || !p.filter.Allow(candidate, scan.Root) {
+			continue
+		}
+		if scan.Attempted != nil && !scan.Attempted.Claim(candidate) {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	p.logger.Info("Resolving permutations",
+		zap.Int("seeds", len(seeds)),
+		zap.Int("candidates", len(candidates)),
+	)
+
+	resolved := scan.Resolver.ResolveBatch(ctx, candidates, activeWorkers(scan, p.config))
+	for _, candidate := range candidates {
+		if ips, ok := resolved[candidate]; ok && !isWildcardHit(scan, ips) {
+			result.Subdomains = append(result.Subdomains, candidate)
+		}
+	}
+
+	result.Duration = time.Since(startTime)
+	if err := ctx.Err(); err != nil {
+		result.Error = err
+		return result, err
+	}
+	return result, nil
+}
+
+// Permute generates candidates from names under root. Seeds are taken in
+// sorted order and their candidates interleaved, so a cap (max > 0) cuts
+// every seed's list short rather than dropping the last seeds entirely.
+// The same input always yields the same output.
+func Permute(seeds []string, root string, max int) []string {
+	root = strings.ToLower(strings.TrimSuffix(root, "."))
+
+	unique := make(map[string]bool)
+	for _, seed := range seeds {
+		seed = strings.ToLower(strings.TrimSuffix(seed, "."))
+		if strings.HasSuffix(seed, "."+root) {
+			unique[seed] = true
+		}
+	}
+	sorted := make([]string, 0, len(unique))
+	for seed := range unique {
+		sorted = append(sorted, seed)
+	}
+	sort.Strings(sorted)
+
+	lists := make([][]string, len(sorted))
+	for i, seed := range sorted {
+		lists[i] = permuteName(seed)
+	}
+
+	seen := make(map[string]bool)
+	var permutations []string
+	for round := 0; ; round++ {
+		added := false
+		for _, list := range lists {
+			if round >= len(list) {
+				continue
+			}
+			added = true
+
+			candidate := list[round]
+			if seen[candidate] || unique[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			permutations = append(permutations, candidate)
+
+			if max > 0 && len(permutations) >= max {
+				return permutations
+			}
+		}
+		if !added {
+			return permutations
+		}
+	}
+}
+
+// permuteName returns the variations of a name's first label, keeping the
+// rest of the name
+func permuteName(name string) []string {
+	label, parent, found := strings.Cut(name, ".")
+	if !found {
+		return nil
+	}
+
+	var labels []string
+	add := func(variant string) {
+		if variant != "" && variant != label {
+			labels = append(labels, variant)
+		}
+	}
+
+	parts := strings.Split(label, "-")
+
+	// Environment tags already in the label are swapped or dropped
+	envAt := -1
+	for i, part := range parts {
+		if isEnvironmentTag(part) {
+			envAt = i
+			break
+		}
+	}
+	if envAt >= 0 {
+		for _, env := range environmentTags {
+			swapped := append([]string(nil), parts...)
+			swapped[envAt] = env
+			add(strings.Join(swapped, "-"))
+		}
+		dropped := append(append([]string(nil), parts[:envAt]...), parts[envAt+1:]...)
+		add(strings.Join(dropped, "-"))
+	} else {
+		// Otherwise tags are added before, after and as a new label
+		for _, env := range environmentTags {
+			add(env + "-" + label)
+			add(label + "-" + env)
+			add(env + "." + label)
+		}
+	}
+
+	// Numbers are stepped, keeping zero padding (web01 -> web02)
+	for _, variant := range stepNumbers(label) {
+		add(variant)
+	}
+
+	// Dash variations: joined up and split into labels
+	if len(parts) > 1 {
+		add(strings.Join(parts, ""))
+		add(strings.Join(parts, "."))
+	}
+
+	names := make([]string, len(labels))
+	for i, variant := range labels {
+		names[i] = variant + "." + parent
+	}
+	return names
+}
+
+// stepNumbers returns label with its last run of digits moved one and two
+// steps either way, never below zero
+func stepNumbers(label string) []string {
+	end := strings.LastIndexFunc(label, isDigit)
+	if end < 0 {
+		return nil
+	}
+	end++
+	start := end
+	for start > 0 && isDigit(rune(label[start-1])) {
+		start--
+	}
+
+	digits := label[start:end]
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return nil
+	}
+
+	var variants []string
+	for _, step := range []int{1, -1, 2, -2} {
+		if n+step < 0 {
+			continue
+		}
+		number := fmt.Sprintf("%0*d", len(digits), n+step)
+		variants = append(variants, label[:start]+number+label[end:])
+	}
+	return variants
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isEnvironmentTag(s string) bool {
+	for _, env := range environmentTags {
+		if s == env {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package active
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// staticResults is a result store holding fixed names
+type staticResults []string
+
+func (s staticResults) Add(source string, subdomains []string) {}
+
+func (s staticResults) Snapshot() []*types.Subdomain {
+	subs := make([]*types.Subdomain, len(s))
+	for i, name := range s {
+		subs[i] = &types.Subdomain{Domain: name}
+	}
+	return subs
+}
+
+func TestPermuteName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    []string // first labels that must be generated
+		notWant []string // first labels that must not be
+		count   int
+	}{
+		{
+			name:    "api.example.com",
+			want:    []string{"dev-api", "api-dev", "dev.api", "prod-api", "api-uat"},
+			notWant: []string{"api"},
+			count:   21, // three placements of seven tags
+		},
+		{
+			name:    "web-dev.example.com",
+			want:    []string{"web-prod", "web-staging", "web-uat", "web", "webdev", "web.dev"},
+			notWant: []string{"web-dev", "dev-web-dev", "web-dev-prod"},
+			count:   9, // six swaps, one drop, two dash variations
+		},
+		{
+			name:    "web01.example.com",
+			want:    []string{"web02", "web00", "web03", "dev-web01"},
+			notWant: []string{"web2", "web-1"},
+			count:   24,
+		},
+		{
+			name:    "db0.example.com",
+			want:    []string{"db1", "db2"},
+			notWant: []string{"db-1", "db-2"},
+			count:   23,
+		},
+		{
+			name:  "my-app.example.com",
+			want:  []string{"myapp", "my.app", "my-app-qa"},
+			count: 23,
+		},
+		{
+			name:  "localhost",
+			count: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]bool)
+			candidates := permuteName(tt.name)
+			for _, candidate := range candidates {
+				if !strings.HasSuffix(candidate, ".example.com") {
+					t.Errorf("candidate %q lost the parent", candidate)
+				}
+				got[strings.TrimSuffix(candidate, ".example.com")] = true
+			}
+
+			if len(candidates) != tt.count {
+				t.Errorf("got %d candidates, want %d: %v", len(candidates), tt.count, candidates)
+			}
+			for _, label := range tt.want {
+				if !got[label] {
+					t.Errorf("%s not generated", label)
+				}
+			}
+			for _, label := range tt.notWant {
+				if got[label] {
+					t.Errorf("%s generated", label)
+				}
+			}
+		})
+	}
+}
+
+func TestPermuteInterleavesSeedsUpToCap(t *testing.T) {
+	seeds := []string{"b.example.com", "A.example.com.", "x.other.com", "dev-a.example.com"}
+
+	got := Permute(seeds, "example.com", 6)
+
+	// Round robin over a, b and dev-a; dev-a is a seed so never a
+	// candidate, and the out-of-root seed yields nothing
+	want := []string{
+		"dev-b.example.com", "stage-a.example.com",
+		"a-dev.example.com", "b-dev.example.com", "staging-a.example.com",
+		"dev.a.example.com",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Permute = %v, want %v", got, want)
+	}
+
+	// Seed order doesn't matter
+	reversed := []string{"dev-a.example.com", "x.other.com", "A.example.com.", "b.example.com"}
+	if again := Permute(reversed, "example.com", 6); strings.Join(again, " ") != strings.Join(got, " ") {
+		t.Errorf("reordered seeds gave %v, want %v", again, got)
+	}
+}
+
+func TestPermuteCapBoundsLargeInput(t *testing.T) {
+	var seeds []string
+	for i := 0; i < 1000; i++ {
+		seeds = append(seeds, fmt.Sprintf("host%d.example.com", i))
+	}
+
+	capped := Permute(seeds, "example.com", 500)
+	if len(capped) != 500 {
+		t.Fatalf("got %d candidates, want the cap of 500", len(capped))
+	}
+
+	// Every seed gets its first variation before any gets a second
+	for i, candidate := range capped {
+		if !strings.HasPrefix(candidate, "dev-host") {
+			t.Fatalf("candidate %d is %q, want first-round dev- variations only", i, candidate)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, candidate := range Permute(seeds, "example.com", 0) {
+		if seen[candidate] {
+			t.Fatalf("%s generated twice", candidate)
+		}
+		seen[candidate] = true
+	}
+	if len(seen) <= 500 {
+		t.Errorf("uncapped run gave %d candidates, want more than the cap", len(seen))
+	}
+}
+
+func TestPermutationsEnumerateScan(t *testing.T) {
+	resolver := &stubResolver{records: map[string]*types.DNSRecords{
+		"api-dev.example.com": {A: []string{"192.0.2.10"}},
+		"dev-api.example.com": {A: []string{"192.0.2.1"}},
+		"api-qa.example.com":  {A: []string{"192.0.2.11"}},
+		"web02.example.com":   {A: []string{"192.0.2.12"}},
+	}}
+	attempted := sources.NewAttempted()
+	attempted.Claim("api-qa.example.com")
+
+	scan := &types.ScanContext{
+		Domain:    "example.com",
+		Root:      "example.com",
+		Resolver:  resolver,
+		Attempted: attempted,
+		Results:   staticResults{"api.example.com", "web01.example.com", "web02.example.com"},
+		Wildcard:  &types.WildcardInfo{IsWildcard: true, Patterns: []string{"192.0.2.1"}},
+	}
+
+	p := NewPermutations(&config.ActiveSourcesConfig{Permutations: true}, zap.NewNop())
+	result, err := p.EnumerateScan(context.Background(), scan)
+	if err != nil {
+		t.Fatalf("EnumerateScan: %v", err)
+	}
+
+	// dev-api is a wildcard answer, api-qa was already tried and web02 is
+	// already known
+	if strings.Join(result.Subdomains, " ") != "api-dev.example.com" {
+		t.Errorf("found %v, want [api-dev.example.com]", result.Subdomains)
+	}
+	for _, name := range resolver.resolved {
+		if name == "api-qa.example.com" || name == "web02.example.com" {
+			t.Errorf("%s resolved again", name)
+		}
+	}
+}
+
+func TestPermutationsHonorsMaxCandidates(t *testing.T) {
+	resolver := &stubResolver{}
+	scan := &types.ScanContext{
+		Domain:   "example.com",
+		Root:     "example.com",
+		Resolver: resolver,
+		Results:  staticResults{"api.example.com", "web01.example.com", "mail.example.com"},
+	}
+
+	cfg := &config.ActiveSourcesConfig{Permutations: true, MaxCandidates: 7}
+	if _, err := NewPermutations(cfg, zap.NewNop()).EnumerateScan(context.Background(), scan); err != nil {
+		t.Fatalf("EnumerateScan: %v", err)
+	}
+	if len(resolver.resolved) != 7 {
+		t.Errorf("resolved %d candidates, want the cap of 7", len(resolver.resolved))
+	}
+}
@@ -0,0 +1,37 @@
+package active
+
+import (
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// defaultWorkers is used when neither the scan budget nor the config set
+// how many names to resolve at once
+const defaultWorkers = 20
+
+// activeWorkers returns how many names an active source resolves at once:
+// the scan budget, else the config, else defaultWorkers
+func activeWorkers(scan *types.ScanContext, cfg *config.ActiveSourcesConfig) int {
+	if scan.Budget.ActiveWorkers > 0 {
+		return scan.Budget.ActiveWorkers
+	}
+	if cfg.Workers > 0 {
+		return cfg.Workers
+	}
+	return defaultWorkers
+}
+
+// isWildcardHit reports whether every address of a hit is a wildcard
+// answer. Wildcards detected below the root are only known after
+// validation, which filters those hits instead.
+func isWildcardHit(scan *types.ScanContext, ips []string) bool {
+	if scan.Wildcard == nil || !scan.Wildcard.IsWildcard {
+		return false
+	}
+	for _, ip := range ips {
+		if !scan.IsWildcardIP(ip) {
+			return false
+		}
+	}
+	return len(ips) > 0
+}
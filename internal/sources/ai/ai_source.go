@@ -18,6 +18,12 @@ type AISource struct {
 	config  *config.Config
 	logger  *zap.Logger
 	enabled bool
+	
+	// Scan-wide set of already resolved candidates (optional)
+	attempted *sources.Attempted
+	
+	// Drops implausible suggestions before they are resolved
+	filter *sources.CandidateFilter
 }
 
 // NewAISource creates a new AI-powered source
@@ -29,6 +35,7 @@ func NewAISource(cfg *config.Config, logger *zap.Logger) *AISource {
 		config:  cfg,
 		logger:  logger,
 		enabled: cfg.AI.Enabled,
+		filter:  sources.NewCandidateFilter(cfg.Sources.Active.MinLabelLength, cfg.Sources.Active.AllowedCharset),
 	}
 }
 
@@ -52,6 +59,28 @@ func (a *AISource) RateLimit() int {
 	return 0 // No external API calls
 }
 
+// RequiresNetwork reports that generated candidates are resolved over DNS
+func (a *AISource) RequiresNetwork() bool {
+	return true
+}
+
+// SetAttempted shares the scan's attempted set so recursive discovery never
+// resolves a candidate another source already tried
+func (a *AISource) SetAttempted(attempted *sources.Attempted) {
+	a.attempted = attempted
+}
+
+// EnumerateScan performs discovery with the scan context, picking up the
+// scan's attempted set when none was injected
+func (a *AISource) EnumerateScan(ctx context.Context, scan *types.ScanContext) (*types.SourceResult, error) {
+	if a.attempted == nil {
+		if attempted, ok := scan.Attempted.(*sources.Attempted); ok {
+			a.attempted = attempted
+		}
+	}
+	return a.Enumerate(ctx, scan.Root)
+}
+
 // Enumerate performs AI-enhanced subdomain discovery
 func (a *AISource) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
 	startTime := time.Now()
@@ -85,9 +114,12 @@ func (a *AISource) Enumerate(ctx context.Context, domain string) (*types.SourceR
 		a.logger.Info("AI wordlist generated", zap.Int("count", len(wordlist)))
 	}
 	
-	// Convert to full subdomains
+	// Convert to full subdomains, dropping implausible labels
 	var fullSubdomains []string
 	for _, sub := range allSubdomains {
+		if !a.filter.AllowLabel(sub) {
+			continue
+		}
 		fullSubdomains = append(fullSubdomains, fmt.Sprintf("%s.%s", sub, domain))
 	}
 	
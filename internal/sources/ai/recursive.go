@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// Resolver is the subset of the DNS engine used to validate AI suggestions
+type Resolver interface {
+	ResolveBatch(ctx context.Context, domains []string, workers int) map[string][]string
+	IsWildcard(ctx context.Context, domain string) (*types.WildcardInfo, error)
+}
+
+// Discovery is a validated subdomain found through recursive AI discovery
+type Discovery struct {
+	Domain  string   `json:"domain"`
+	IP      []string `json:"ip"`
+	Depth   int      `json:"depth"`
+	Lineage []string `json:"lineage"` // seed first, immediate parent last
+}
+
+// discoveryNode is a validated subdomain waiting to be expanded
+type discoveryNode struct {
+	domain  string
+	depth   int
+	lineage []string
+}
+
+// DiscoverRecursive expands a seed subdomain with AI suggestions, validating
+// each generation via DNS and only recursing on names that resolve to more
+// than their parent's wildcard answers. Depth is tracked per chain, so one
+// deep branch never starves another.
+func (a *AISource) DiscoverRecursive(ctx context.Context, seed string, resolver Resolver, maxDepth, workers int) ([]*Discovery, error) {
+	if !a.engine.IsAvailable(ctx) {
+		return nil, fmt.Errorf("AI engine not available - ensure Ollama is running")
+	}
+
+	if limit := a.engine.MaxRecursionDepth(); maxDepth > limit {
+		a.logger.Warn("Requested depth exceeds engine limit, capping",
+			zap.Int("requested", maxDepth),
+			zap.Int("limit", limit),
+		)
+		maxDepth = limit
+	}
+
+	seed = strings.ToLower(strings.TrimSuffix(seed, "."))
+	seen := map[string]bool{seed: true}
+	queue := []discoveryNode{{domain: seed}}
+	wildcards := make(map[string]*types.WildcardInfo) // by parent
+
+	var discoveries []*Discovery
+
+	for len(queue) > 0 {
+		select {
+		case <-ctx.Done():
+			return discoveries, ctx.Err()
+		default:
+		}
+
+		node := queue[0]
+		queue = queue[1:]
+
+		if node.depth >= maxDepth {
+			continue
+		}
+
+		purpose := a.engine.InferPurpose(node.domain)
+		suggestions, err := a.engine.RecursiveDiscovery(ctx, node.domain, purpose, node.depth)
+		if err != nil {
+			a.logger.Warn("Recursive discovery failed",
+				zap.String("subdomain", node.domain),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		parent := parentDomain(node.domain)
+		var candidates []string
+		for _, label := range suggestions {
+			candidate := fmt.Sprintf("%s.%s", label, parent)
+			if seen[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			
+			if !a.filter.AllowLabel(label) {
+				continue
+			}
+			
+			// Skip names another source already resolved this scan
+			if a.attempted != nil && !a.attempted.Claim(candidate) {
+				continue
+			}
+			candidates = append(candidates, candidate)
+		}
+
+		if len(candidates) == 0 {
+			continue
+		}
+
+		// Under a wildcard parent every suggestion resolves
+		wildcard, checked := wildcards[parent]
+		if !checked {
+			info, err := resolver.IsWildcard(ctx, parent)
+			if err != nil {
+				a.logger.Warn("Wildcard detection failed",
+					zap.String("domain", parent),
+					zap.Error(err),
+				)
+			}
+			wildcard = info
+			wildcards[parent] = info
+		}
+
+		// Only names that resolve are reported and expanded further
+		resolved := resolver.ResolveBatch(ctx, candidates, workers)
+
+		lineage := append(append([]string{}, node.lineage...), node.domain)
+
+		validated := 0
+		for _, candidate := range candidates {
+			ips, ok := resolved[candidate]
+			if !ok || isWildcardHit(wildcard, ips) {
+				continue
+			}
+			validated++
+
+			discoveries = append(discoveries, &Discovery{
+				Domain:  candidate,
+				IP:      ips,
+				Depth:   node.depth + 1,
+				Lineage: lineage,
+			})
+
+			queue = append(queue, discoveryNode{
+				domain:  candidate,
+				depth:   node.depth + 1,
+				lineage: lineage,
+			})
+		}
+
+		a.logger.Info("Recursive generation validated",
+			zap.String("subdomain", node.domain),
+			zap.Int("depth", node.depth),
+			zap.Int("candidates", len(candidates)),
+			zap.Int("validated", validated),
+		)
+	}
+
+	return discoveries, nil
+}
+
+// isWildcardHit reports whether every IP a candidate resolved to is one of
+// its parent's wildcard answers
+func isWildcardHit(wildcard *types.WildcardInfo, ips []string) bool {
+	for _, ip := range ips {
+		if !wildcard.Matches(ip) {
+			return false
+		}
+	}
+	return len(ips) > 0
+}
+
+// parentDomain strips the first label so suggestions become siblings
+func parentDomain(subdomain string) string {
+	if idx := strings.Index(subdomain, "."); idx != -1 && strings.Count(subdomain, ".") > 1 {
+		return subdomain[idx+1:]
+	}
+	return subdomain
+}
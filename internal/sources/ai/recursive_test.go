@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// ollamaServer answers every generation with labels, one per line
+func ollamaServer(t *testing.T, labels string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"test"}]}`))
+		case "/api/generate":
+			json.NewEncoder(w).Encode(map[string]interface{}{"response": labels, "done": true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// stubResolver resolves names from a fixed table and reports wildcards by
+// domain, counting detections
+type stubResolver struct {
+	records   map[string][]string
+	wildcards map[string]*types.WildcardInfo
+
+	mu       sync.Mutex
+	detected map[string]int
+}
+
+func (r *stubResolver) ResolveBatch(ctx context.Context, domains []string, workers int) map[string][]string {
+	resolved := make(map[string][]string)
+	for _, domain := range domains {
+		if ips, ok := r.records[domain]; ok {
+			resolved[domain] = ips
+		}
+	}
+	return resolved
+}
+
+func (r *stubResolver) IsWildcard(ctx context.Context, domain string) (*types.WildcardInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.detected == nil {
+		r.detected = make(map[string]int)
+	}
+	r.detected[domain]++
+	if info, ok := r.wildcards[domain]; ok {
+		return info, nil
+	}
+	return &types.WildcardInfo{}, nil
+}
+
+// newTestSource returns an AI source talking to server
+func newTestSource(server *httptest.Server) *AISource {
+	cfg := &config.Config{AI: config.AIConfig{Enabled: true, OllamaURL: server.URL, Model: "test"}}
+	return NewAISource(cfg, zap.NewNop())
+}
+
+func TestDiscoverRecursiveDropsWildcardHits(t *testing.T) {
+	server := ollamaServer(t, "dev\nstaging\nportal")
+	resolver := &stubResolver{
+		records: map[string][]string{
+			"dev.example.com":     {"192.0.2.99"},
+			"staging.example.com": {"192.0.2.10"},
+			"portal.example.com":  {"192.0.2.99", "192.0.2.11"},
+		},
+		wildcards: map[string]*types.WildcardInfo{
+			"example.com": {IsWildcard: true, Patterns: []string{"192.0.2.99"}},
+		},
+	}
+
+	discoveries, err := newTestSource(server).DiscoverRecursive(context.Background(), "app.example.com", resolver, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found []string
+	for _, d := range discoveries {
+		found = append(found, d.Domain)
+	}
+	sort.Strings(found)
+	if len(found) != 2 || found[0] != "portal.example.com" || found[1] != "staging.example.com" {
+		t.Errorf("discoveries = %v, want portal and staging only", found)
+	}
+	if n := resolver.detected["example.com"]; n != 1 {
+		t.Errorf("wildcard detected %d times for example.com, want once", n)
+	}
+}
+
+func TestDiscoverRecursiveWithoutWildcard(t *testing.T) {
+	server := ollamaServer(t, "dev\nstaging\nportal")
+	resolver := &stubResolver{
+		records: map[string][]string{
+			"dev.example.com":     {"192.0.2.1"},
+			"staging.example.com": {"192.0.2.2"},
+		},
+	}
+
+	discoveries, err := newTestSource(server).DiscoverRecursive(context.Background(), "app.example.com", resolver, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(discoveries) != 2 {
+		t.Fatalf("got %d discoveries, want 2", len(discoveries))
+	}
+	for _, d := range discoveries {
+		if d.Depth != 1 || len(d.Lineage) != 1 || d.Lineage[0] != "app.example.com" {
+			t.Errorf("%s at depth %d from %v, want depth 1 from the seed", d.Domain, d.Depth, d.Lineage)
+		}
+	}
+}
+
+func TestDiscoverRecursiveStopsOnCancel(t *testing.T) {
+	server := ollamaServer(t, "dev")
+	resolver := &stubResolver{records: map[string][]string{"dev.example.com": {"192.0.2.1"}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := newTestSource(server).DiscoverRecursive(ctx, "app.example.com", resolver, 2, 4); err == nil {
+		t.Error("canceled discovery returned no error")
+	}
+}
@@ -0,0 +1,60 @@
+package sources
+
+import (
+	"strings"
+	"sync"
+)
+
+// Attempted is a scan-wide, thread-safe set of candidate names that have
+// already been resolved, hit or miss. Sources that generate candidates
+// (brute force, permutations, AI, recursion) claim names before resolving
+// them so the same candidate reached via different paths is queried once.
+type Attempted struct {
+	mu    sync.Mutex
+	names map[string]struct{}
+}
+
+// AttemptTracker is implemented by sources that resolve their own candidates
+// and want to share the scan's attempted set
+type AttemptTracker interface {
+	SetAttempted(attempted *Attempted)
+}
+
+// NewAttempted creates an empty attempted set
+func NewAttempted() *Attempted {
+	return &Attempted{
+		names: make(map[string]struct{}),
+	}
+}
+
+// Claim marks a name as attempted and reports whether it was new
+func (a *Attempted) Claim(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	if _, exists := a.names[name]; exists {
+		return false
+	}
+	a.names[name] = struct{}{}
+	return true
+}
+
+// Filter claims each name and returns only those not attempted before
+func (a *Attempted) Filter(names []string) []string {
+	fresh := make([]string, 0, len(names))
+	for _, name := range names {
+		if a.Claim(name) {
+			fresh = append(fresh, name)
+		}
+	}
+	return fresh
+}
+
+// Len returns the number of names attempted so far
+func (a *Attempted) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.names)
+}
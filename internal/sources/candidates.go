@@ -0,0 +1,104 @@
+package sources
+
+import (
+	"strings"
+)
+
+// CandidateFilter drops implausible generated names before they cost DNS
+// queries: labels shorter than a minimum length or using characters
+// outside an allowed set. Sources that generate candidates (brute force,
+// permutations, AI) apply it before resolving.
+type CandidateFilter struct {
+	minLength int
+	allowed   map[rune]bool // nil allows every valid label character
+}
+
+// NewCandidateFilter creates a filter. charset lists the allowed label
+// characters, with ranges, e.g. "a-z0-9-"; "" allows any valid label.
+func NewCandidateFilter(minLength int, charset string) *CandidateFilter {
+	return &CandidateFilter{
+		minLength: minLength,
+		allowed:   parseCharset(strings.ToLower(charset)),
+	}
+}
+
+// AllowLabel reports whether a single label is a plausible candidate
+func (f *CandidateFilter) AllowLabel(label string) bool {
+	if !ValidLabel(label) || len(label) < f.minLength {
+		return false
+	}
+	if f.allowed == nil {
+		return true
+	}
+	for _, c := range label {
+		if !f.allowed[c] {
+			return false
+		}
+	}
+	return true
+}
+
+// Allow reports whether every label of name left of domain is plausible
+func (f *CandidateFilter) Allow(name, domain string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	host := strings.TrimSuffix(name, "."+strings.ToLower(domain))
+	if host == name || host == "" {
+		return false
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if !f.AllowLabel(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the names under domain that pass the filter
+func (f *CandidateFilter) Filter(names []string, domain string) []string {
+	kept := make([]string, 0, len(names))
+	for _, name := range names {
+		if f.Allow(name, domain) {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// ValidLabel checks if a string is a valid lowercase DNS label: 1-63
+// letters, digits or hyphens, not starting or ending with a hyphen
+func ValidLabel(s string) bool {
+	if len(s) == 0 || len(s) > 63 {
+		return false
+	}
+
+	for _, c := range s {
+		if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-') {
+			return false
+		}
+	}
+
+	return !strings.HasPrefix(s, "-") && !strings.HasSuffix(s, "-")
+}
+
+// parseCharset expands a character set like "a-z0-9-" into a lookup
+// table. A hyphen at either end, or not between two characters, is literal.
+func parseCharset(charset string) map[rune]bool {
+	if charset == "" {
+		return nil
+	}
+
+	chars := []rune(charset)
+	allowed := make(map[rune]bool)
+	for i := 0; i < len(chars); i++ {
+		if i+2 < len(chars) && chars[i+1] == '-' && chars[i] <= chars[i+2] {
+			for c := chars[i]; c <= chars[i+2]; c++ {
+				allowed[c] = true
+			}
+			i += 2
+			continue
+		}
+		allowed[chars[i]] = true
+	}
+	return allowed
+}
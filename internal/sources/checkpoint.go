@@ -0,0 +1,214 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultChunkSize is how many candidates are resolved between checkpoints
+const DefaultChunkSize = 5000
+
+// Checkpoint walks a large candidate list in chunks, logging progress after
+// each one and persisting how far it got under the cache directory, so an
+// interrupted brute force can continue mid-wordlist instead of starting over
+type Checkpoint struct {
+	path      string
+	chunkSize int
+	logger    *zap.Logger
+
+	state checkpointState
+}
+
+// checkpointState is the on-disk form of a checkpoint
+type checkpointState struct {
+	Domain    string    `json:"domain"`
+	ListHash  string    `json:"list_hash"`
+	Offset    int       `json:"offset"`
+	Total     int       `json:"total"`
+	Hits      int       `json:"hits"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewCheckpoint creates a checkpoint for resolving candidates of domain.
+// name identifies the source (e.g. "dns_bruteforce"). An empty cacheDir
+// keeps progress in memory only.
+func NewCheckpoint(cacheDir, name, domain string, candidates []string, chunkSize int, logger *zap.Logger) *Checkpoint {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	hash := listHash(candidates)
+
+	c := &Checkpoint{
+		chunkSize: chunkSize,
+		logger:    logger,
+		state: checkpointState{
+			Domain:   domain,
+			ListHash: hash,
+			Total:    len(candidates),
+		},
+	}
+
+	if cacheDir != "" {
+		key := sha256.Sum256([]byte(name + "\x00" + strings.ToLower(domain)))
+		c.path = filepath.Join(cacheDir, "checkpoints", name+"-"+hex.EncodeToString(key[:8])+".json")
+	}
+
+	return c
+}
+
+// Resume continues from the stored offset, if one exists for the same
+// domain and candidate list. It returns the number of candidates skipped.
+func (c *Checkpoint) Resume() int {
+	if c.path == "" {
+		return 0
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return 0
+	}
+
+	var stored checkpointState
+	if err := json.Unmarshal(data, &stored); err != nil {
+		c.logger.Debug("Ignoring unreadable checkpoint", zap.String("path", c.path), zap.Error(err))
+		return 0
+	}
+
+	// A changed wordlist invalidates the offset
+	if stored.ListHash != c.state.ListHash || stored.Offset > c.state.Total {
+		c.logger.Info("Checkpoint does not match candidate list, starting over",
+			zap.String("domain", c.state.Domain),
+		)
+		return 0
+	}
+
+	c.state.Offset = stored.Offset
+	c.state.Hits = stored.Hits
+
+	c.logger.Info("Resuming from checkpoint",
+		zap.String("domain", c.state.Domain),
+		zap.Int("offset", stored.Offset),
+		zap.Int("total", c.state.Total),
+	)
+	return stored.Offset
+}
+
+// Run calls resolve for each remaining chunk of candidates, in order.
+// resolve returns how many of the chunk were found. The offset only moves
+// past chunks that finished before ctx was canceled; on completion the
+// stored checkpoint is removed. It returns ctx.Err() when interrupted.
+func (c *Checkpoint) Run(ctx context.Context, candidates []string, resolve func(ctx context.Context, chunk []string) int) error {
+	started := time.Now()
+	startOffset := c.state.Offset
+
+	for c.state.Offset < len(candidates) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := min(c.state.Offset+c.chunkSize, len(candidates))
+		hits := resolve(ctx, candidates[c.state.Offset:end])
+
+		// A canceled chunk may be partial: keep the offset before it
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		c.state.Offset = end
+		c.state.Hits += hits
+
+		if c.state.Offset < len(candidates) {
+			if err := c.save(); err != nil {
+				c.logger.Warn("Failed to save checkpoint", zap.Error(err))
+			}
+		}
+		c.logProgress(started, startOffset)
+	}
+
+	c.clear()
+	return nil
+}
+
+// Offset returns how many candidates have been fully resolved
+func (c *Checkpoint) Offset() int {
+	return c.state.Offset
+}
+
+// Hits returns how many candidates were found so far
+func (c *Checkpoint) Hits() int {
+	return c.state.Hits
+}
+
+// logProgress reports tried/total, hit rate and throughput
+func (c *Checkpoint) logProgress(started time.Time, startOffset int) {
+	var hitRate float64
+	if c.state.Offset > 0 {
+		hitRate = float64(c.state.Hits) / float64(c.state.Offset) * 100
+	}
+
+	var rate float64
+	if elapsed := time.Since(started).Seconds(); elapsed > 0 {
+		rate = float64(c.state.Offset-startOffset) / elapsed
+	}
+
+	c.logger.Info("Brute-force progress",
+		zap.String("domain", c.state.Domain),
+		zap.Int("tried", c.state.Offset),
+		zap.Int("total", c.state.Total),
+		zap.Int("hits", c.state.Hits),
+		zap.Float64("hit_rate_pct", math.Round(hitRate*100)/100),
+		zap.Float64("per_second", math.Round(rate)),
+	)
+}
+
+// save writes the checkpoint atomically
+func (c *Checkpoint) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.state.UpdatedAt = time.Now()
+	data, err := json.Marshal(c.state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// clear removes the stored checkpoint once the list is done
+func (c *Checkpoint) clear() {
+	if c.path == "" {
+		return
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		c.logger.Debug("Failed to remove checkpoint", zap.String("path", c.path), zap.Error(err))
+	}
+}
+
+// listHash identifies a candidate list by content and order
+func listHash(candidates []string) string {
+	h := sha256.New()
+	for _, candidate := range candidates {
+		h.Write([]byte(candidate))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
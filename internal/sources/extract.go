@@ -0,0 +1,75 @@
+package sources
+
+import (
+	"regexp"
+	"strings"
+)
+
+// hostPattern matches domain-like strings in free text: page bodies,
+// scripts, header values
+var hostPattern = regexp.MustCompile(`(?i)(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,}`)
+
+// ExtractHosts returns the distinct, lowercased hostnames found in content,
+// in order of appearance. Wildcards ("*.cdn.example.com") yield the name
+// below the wildcard; file names in URL paths ("/app.js") are skipped.
+func ExtractHosts(content string) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+
+	for _, loc := range hostPattern.FindAllStringIndex(content, -1) {
+		start, end := loc[0], loc[1]
+		if inPath(content, start) {
+			continue
+		}
+
+		host := strings.ToLower(content[start:end])
+		if seen[host] || !validHost(host) {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+
+	return hosts
+}
+
+// ExtractSubdomains returns the hostnames in content that are domain itself
+// or one of its subdomains
+func ExtractSubdomains(content, domain string) []string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	var subdomains []string
+	for _, host := range ExtractHosts(content) {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			subdomains = append(subdomains, host)
+		}
+	}
+	return subdomains
+}
+
+// inPath reports whether the match at start follows a single slash, i.e.
+// is a path segment rather than the host after "//"
+func inPath(content string, start int) bool {
+	if start == 0 || content[start-1] != '/' {
+		return false
+	}
+	return start < 2 || content[start-2] != '/'
+}
+
+// validHost checks overall and per-label length limits
+func validHost(host string) bool {
+	if len(host) > 253 {
+		return false
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+	}
+	return true
+}
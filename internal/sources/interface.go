@@ -2,7 +2,10 @@ package sources
 
 import (
 	"context"
+	"sort"
+	"time"
 
+	"github.com/yourusername/usr/internal/ratelimit"
 	"github.com/yourusername/usr/internal/types"
 )
 
@@ -20,10 +23,144 @@ type Source interface {
 	// IsEnabled checks if source is configured and available
 	IsEnabled() bool
 	
-	// RateLimit returns requests per second limit (0 = unlimited)
+	// RateLimit returns how many requests the source may make per second
+	// (0 = unlimited), or per RatePeriod for a RatePeriodSource
 	RateLimit() int
 }
 
+// ScanAware is implemented by sources that want the full scan context
+// (apex, wildcard info, scope, budgets, shared results) rather than just
+// the target domain. The orchestrator prefers it over Enumerate.
+type ScanAware interface {
+	EnumerateScan(ctx context.Context, scan *types.ScanContext) (*types.SourceResult, error)
+}
+
+// StreamingSource is implemented by sources that report subdomains as they
+// are found (brute force, crawlers) instead of all at once. The names channel
+// is closed when the source finishes; the error channel then yields at most
+// one terminal error and is closed. The orchestrator prefers this interface.
+type StreamingSource interface {
+	EnumerateStream(ctx context.Context, domain string) (<-chan string, <-chan error)
+}
+
+// ScanStreamingSource is a StreamingSource that wants the scan context,
+// as ScanAware does for plain sources. The orchestrator prefers it over
+// EnumerateStream.
+type ScanStreamingSource interface {
+	EnumerateScanStream(ctx context.Context, scan *types.ScanContext) (<-chan string, <-chan error)
+}
+
+// Expander is implemented by sources that build on the names other
+// sources found (permutations). The orchestrator runs them once every
+// other source has finished, so they see the complete first round.
+type Expander interface {
+	ExpandsResults() bool
+}
+
+// Expands reports whether a source builds on other sources' results
+func Expands(source Source) bool {
+	e, ok := source.(Expander)
+	return ok && e.ExpandsResults()
+}
+
+// Progress is how far a source has got through a known amount of work
+type Progress struct {
+	Tried int `json:"tried"`
+	Total int `json:"total"`
+	Hits  int `json:"hits"`
+}
+
+// ProgressReporter is implemented by sources that work through a long
+// candidate list (brute force) and can say how far along they are while
+// still running
+type ProgressReporter interface {
+	Progress() Progress
+}
+
+// Resumable is implemented by sources that checkpoint their own progress
+// (brute force) and can continue an interrupted run instead of starting
+// over when the scan is resumed
+type Resumable interface {
+	SetResume(resume bool)
+}
+
+// RatePeriodSource is implemented by sources whose quota is counted over a
+// longer period than a second, e.g. 4 requests per minute: RateLimit is
+// then the number of requests per RatePeriod
+type RatePeriodSource interface {
+	RatePeriod() time.Duration
+}
+
+// Rate returns a source's request quota as a count per period
+func Rate(source Source) (int, time.Duration) {
+	if periodic, ok := source.(RatePeriodSource); ok {
+		if period := periodic.RatePeriod(); period > 0 {
+			return source.RateLimit(), period
+		}
+	}
+	return source.RateLimit(), time.Second
+}
+
+// RateLimited is implemented by sources making several requests per run
+// (pagination, one query per index). They are handed the limiter enforcing
+// their RateLimit and wait on it before each request; other sources are
+// paced by the orchestrator once per run.
+type RateLimited interface {
+	SetRateLimiter(limiter *ratelimit.Limiter)
+}
+
+// TimeoutHinter is implemented by sources whose normal run takes a known
+// time different from sources.timeout, e.g. one that enforces its own
+// deadline and must not be cut off before it can return what it found
+type TimeoutHinter interface {
+	SourceTimeout() time.Duration
+}
+
+// Timeout returns how long a source may run: its own hint when it gives
+// one, else def (0 = no limit)
+func Timeout(source Source, def time.Duration) time.Duration {
+	if hinter, ok := source.(TimeoutHinter); ok {
+		if timeout := hinter.SourceTimeout(); timeout > 0 {
+			return timeout
+		}
+	}
+	return def
+}
+
+// NetworkSource is implemented by sources that declare whether they need
+// network access. Offline scans skip every source that does; sources not
+// implementing it are assumed to need the network.
+type NetworkSource interface {
+	RequiresNetwork() bool
+}
+
+// RequiresNetwork reports whether a source needs network access
+func RequiresNetwork(source Source) bool {
+	if ns, ok := source.(NetworkSource); ok {
+		return ns.RequiresNetwork()
+	}
+	return true
+}
+
+// Run enumerates a source, handing it the scan context when it accepts one.
+// Plain sources enumerate the scan root, which is the target domain or, in
+// registrable scope, its registrable domain.
+func Run(ctx context.Context, source Source, scan *types.ScanContext) (*types.SourceResult, error) {
+	if aware, ok := source.(ScanAware); ok {
+		return aware.EnumerateScan(ctx, scan)
+	}
+	return source.Enumerate(ctx, scan.Root)
+}
+
+// Stream starts a streaming source, handing it the scan context when it
+// accepts one
+func Stream(ctx context.Context, source StreamingSource, scan *types.ScanContext) (<-chan string, <-chan error) {
+	if aware, ok := source.(ScanStreamingSource); ok {
+		return aware.EnumerateScanStream(ctx, scan)
+	}
+	return source.EnumerateStream(ctx, scan.Root)
+}
+
 // SourceType categorizes enumeration sources
 type SourceType string
 
@@ -79,6 +216,16 @@ func (r *Registry) GetAll() []Source {
 	return result
 }
 
+// Names returns the names of all registered sources, enabled or not, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.sources))
+	for name := range r.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Count returns the number of registered sources
 func (r *Registry) Count() int {
 	return len(r.sources)
@@ -0,0 +1,249 @@
+package passive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/internal/ratelimit"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// commonCrawlGrace is how long past its own deadline the source may take
+// to return what it found before the scan abandons it
+const commonCrawlGrace = 15 * time.Second
+
+// commonCrawlMaxLine bounds a single index line; records are short, but
+// some URLs are not
+const commonCrawlMaxLine = 1 << 20
+
+// CommonCrawl implements subdomain enumeration via the CommonCrawl URL
+// index. Index responses can be very large, so they are read line by line
+// and the whole source runs under a deadline, keeping what it found when
+// the deadline passes.
+type CommonCrawl struct {
+	enabled bool
+	indexes int
+	timeout time.Duration
+	baseURL string
+	client  *http.Client
+	limiter *ratelimit.Limiter
+}
+
+// commonCrawlIndex is one crawl listed in collinfo.json
+type commonCrawlIndex struct {
+	ID     string `json:"id"`
+	CDXAPI string `json:"cdx-api"`
+}
+
+// commonCrawlRecord is one line of an index response
+type commonCrawlRecord struct {
+	URL string `json:"url"`
+}
+
+// NewCommonCrawl creates a CommonCrawl source querying the newest indexes
+// crawls, giving up after timeout
+func NewCommonCrawl(enabled bool, indexes int, timeout time.Duration) *CommonCrawl {
+	return NewCommonCrawlWithClient(enabled, indexes, timeout, "https://index.commoncrawl.org", &http.Client{})
+}
+
+// NewCommonCrawlWithClient creates a CommonCrawl source that lists indexes
+// from baseURL through the given client, e.g. an httptest server
+func NewCommonCrawlWithClient(enabled bool, indexes int, timeout time.Duration, baseURL string, client *http.Client) *CommonCrawl {
+	if indexes <= 0 {
+		indexes = 1
+	}
+	return &CommonCrawl{
+		enabled: enabled,
+		indexes: indexes,
+		timeout: timeout,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+}
+
+// Name returns the source identifier
+func (c *CommonCrawl) Name() string {
+	return "common_crawl"
+}
+
+// Type returns the source category
+func (c *CommonCrawl) Type() sources.SourceType {
+	return sources.TypePassive
+}
+
+// IsEnabled checks if the source is enabled
+func (c *CommonCrawl) IsEnabled() bool {
+	return c.enabled
+}
+
+// RateLimit returns the rate limit (requests per second)
+func (c *CommonCrawl) RateLimit() int {
+	return 1 // The index server is shared and slow
+}
+
+// SetRateLimiter paces the index list and per-crawl queries
+func (c *CommonCrawl) SetRateLimiter(limiter *ratelimit.Limiter) {
+	c.limiter = limiter
+}
+
+// RequiresNetwork reports that the CommonCrawl index is queried over the
+// network
+func (c *CommonCrawl) RequiresNetwork() bool {
+	return true
+}
+
+// SourceTimeout lets the source outlast sources.timeout when its own
+// deadline is longer, so it is never abandoned before keeping its partial
+// results
+func (c *CommonCrawl) SourceTimeout() time.Duration {
+	if c.timeout <= 0 {
+		return 0
+	}
+	return c.timeout + commonCrawlGrace
+}
+
+// Enumerate collects hostnames under domain from the newest indexes
+func (c *CommonCrawl) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	startTime := time.Now()
+
+	result := &types.SourceResult{
+		Source: c.Name(),
+	}
+
+	parent := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	subdomainMap := make(map[string]bool)
+
+	indexes, err := c.listIndexes(ctx)
+	if err == nil {
+		for _, index := range indexes {
+			if err = c.queryIndex(ctx, index, domain, subdomainMap); err != nil {
+				break
+			}
+		}
+	}
+
+	subdomains := make([]string, 0, len(subdomainMap))
+	for subdomain := range subdomainMap {
+		subdomains = append(subdomains, subdomain)
+	}
+	result.Subdomains = subdomains
+	result.Duration = time.Since(startTime)
+
+	// Our own deadline only cuts the scan short; what was read is kept
+	if err != nil && parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = nil
+	}
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	return result, nil
+}
+
+// listIndexes returns the newest crawls' index endpoints
+func (c *CommonCrawl) listIndexes(ctx context.Context) ([]commonCrawlIndex, error) {
+	resp, err := c.get(ctx, c.baseURL+"/collinfo.json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("commoncrawl index list returned status %d", resp.StatusCode)
+	}
+
+	// collinfo.json lists crawls newest first
+	var indexes []commonCrawlIndex
+	if err := json.NewDecoder(resp.Body).Decode(&indexes); err != nil {
+		return nil, err
+	}
+	if len(indexes) > c.indexes {
+		indexes = indexes[:c.indexes]
+	}
+	return indexes, nil
+}
+
+// queryIndex streams one index's records for *.domain, adding the
+// hostnames under domain to found
+func (c *CommonCrawl) queryIndex(ctx context.Context, index commonCrawlIndex, domain string, found map[string]bool) error {
+	query := url.Values{}
+	query.Set("url", "*."+domain)
+	query.Set("output", "json")
+	query.Set("fl", "url")
+
+	resp, err := c.get(ctx, index.CDXAPI+"?"+query.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// No captures for the domain in this crawl
+		return nil
+	default:
+		return fmt.Errorf("commoncrawl index %s returned status %d", index.ID, resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), commonCrawlMaxLine)
+	for scanner.Scan() {
+		var record commonCrawlRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+
+		host := commonCrawlHost(record.URL)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			found[host] = true
+		}
+	}
+	return scanner.Err()
+}
+
+// get sends a GET request with the tool's user agent once the rate limit
+// allows
+func (c *CommonCrawl) get(ctx context.Context, endpoint string) (*http.Response, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "USR/1.0 (Universal Subdomain Reconnaissance)")
+
+	return c.client.Do(req)
+}
+
+// commonCrawlHost returns the lowercased hostname of a captured URL, which
+// may lack a scheme
+func commonCrawlHost(raw string) string {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+}
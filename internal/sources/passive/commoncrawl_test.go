@@ -0,0 +1,179 @@
+package passive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// commonCrawlServer serves collinfo.json listing crawls, newest first,
+// each answering its index queries with handler. It records which crawls
+// were queried and with what.
+type commonCrawlServer struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	queried map[string]string // crawl id -> raw query
+}
+
+func newCommonCrawlServer(t *testing.T, crawls []string, handler func(id string, w http.ResponseWriter, r *http.Request)) *commonCrawlServer {
+	t.Helper()
+
+	c := &commonCrawlServer{queried: make(map[string]string)}
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/collinfo.json" {
+			fmt.Fprint(w, "[")
+			for i, id := range crawls {
+				if i > 0 {
+					fmt.Fprint(w, ",")
+				}
+				fmt.Fprintf(w, `{"id": %q, "name": "crawl", "cdx-api": "%s/%s-index"}`, id, c.server.URL, id)
+			}
+			fmt.Fprint(w, "]")
+			return
+		}
+		for _, id := range crawls {
+			if r.URL.Path == "/"+id+"-index" {
+				c.mu.Lock()
+				c.queried[id] = r.URL.RawQuery
+				c.mu.Unlock()
+				handler(id, w, r)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+// source returns a CommonCrawl source reading the newest indexes crawls
+func (c *commonCrawlServer) source(indexes int, timeout time.Duration) *CommonCrawl {
+	return NewCommonCrawlWithClient(true, indexes, timeout, c.server.URL, c.server.Client())
+}
+
+// queries returns the ids of the crawls queried, sorted
+func (c *commonCrawlServer) queries() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var ids []string
+	for id := range c.queried {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// commonCrawlLines are canned NDJSON index records
+var commonCrawlLines = map[string]string{
+	"CC-MAIN-2024-33": `{"urlkey": "com,example,www)/", "url": "https://www.example.com/", "status": "200"}
+{"url": "http://API.Example.com:8080/v1/users?id=1"}
+{"url": "shop.example.com/cart"}
+not json
+{"url": "https://cdn.example.com./app.js"}
+{"url": "https://notexample.com/"}
+{"url": "https://example.com.evil.net/"}
+{"url": "https://example.com/"}
+`,
+	"CC-MAIN-2024-30": `{"url": "https://www.example.com/about"}
+{"url": "https://old.example.com/"}
+`,
+}
+
+func TestCommonCrawlParsesIndexRecords(t *testing.T) {
+	crawls := []string{"CC-MAIN-2024-33", "CC-MAIN-2024-30", "CC-MAIN-2024-26", "CC-MAIN-2024-22"}
+	server := newCommonCrawlServer(t, crawls, func(id string, w http.ResponseWriter, r *http.Request) {
+		lines, ok := commonCrawlLines[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, lines)
+	})
+
+	result, err := server.source(3, 0).Enumerate(context.Background(), "Example.com")
+	if err != nil {
+		t.Fatalf("Enumerate: %v", err)
+	}
+
+	sort.Strings(result.Subdomains)
+	want := []string{"api.example.com", "cdn.example.com", "example.com", "old.example.com", "shop.example.com", "www.example.com"}
+	if !reflect.DeepEqual(result.Subdomains, want) {
+		t.Errorf("subdomains = %v, want %v", result.Subdomains, want)
+	}
+
+	// The three newest crawls only; the one without captures answers 404
+	if got := server.queries(); !reflect.DeepEqual(got, []string{"CC-MAIN-2024-26", "CC-MAIN-2024-30", "CC-MAIN-2024-33"}) {
+		t.Errorf("queried %v, want the newest three crawls", got)
+	}
+	if query := server.queried["CC-MAIN-2024-33"]; query != "fl=url&output=json&url=%2A.example.com" {
+		t.Errorf("index query = %q", query)
+	}
+}
+
+func TestCommonCrawlIndexError(t *testing.T) {
+	server := newCommonCrawlServer(t, []string{"CC-MAIN-2024-33"}, func(id string, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	if _, err := server.source(1, 0).Enumerate(context.Background(), "example.com"); err == nil {
+		t.Error("503 from the index not reported")
+	}
+}
+
+func TestCommonCrawlDeadlineKeepsPartialResults(t *testing.T) {
+	server := newCommonCrawlServer(t, []string{"CC-MAIN-2024-33", "CC-MAIN-2024-30"}, func(id string, w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"url": "https://www.example.com/"}`)
+		fmt.Fprintln(w, `{"url": "https://api.example.com/"}`)
+		w.(http.Flusher).Flush()
+
+		// A huge index: the rest never arrives before the deadline
+		<-r.Context().Done()
+	})
+
+	start := time.Now()
+	result, err := server.source(2, 200*time.Millisecond).Enumerate(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("own deadline reported as an error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("source ran %v past a 200ms deadline", elapsed)
+	}
+
+	sort.Strings(result.Subdomains)
+	if want := []string{"api.example.com", "www.example.com"}; !reflect.DeepEqual(result.Subdomains, want) {
+		t.Errorf("subdomains = %v, want the %v read before the deadline", result.Subdomains, want)
+	}
+	if got := server.queries(); !reflect.DeepEqual(got, []string{"CC-MAIN-2024-33"}) {
+		t.Errorf("queried %v after the deadline, want the first crawl only", got)
+	}
+}
+
+func TestCommonCrawlCancelledScanIsAnError(t *testing.T) {
+	server := newCommonCrawlServer(t, []string{"CC-MAIN-2024-33"}, func(id string, w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := server.source(1, time.Minute).Enumerate(ctx, "example.com"); err == nil {
+		t.Error("scan cancellation swallowed like the source's own deadline")
+	}
+}
+
+func TestCommonCrawlSourceTimeout(t *testing.T) {
+	if got := NewCommonCrawl(true, 1, time.Minute).SourceTimeout(); got != time.Minute+commonCrawlGrace {
+		t.Errorf("SourceTimeout = %v, want the deadline plus %v grace", got, commonCrawlGrace)
+	}
+	if got := NewCommonCrawl(true, 1, 0).SourceTimeout(); got != 0 {
+		t.Errorf("SourceTimeout without a deadline = %v, want 0", got)
+	}
+}
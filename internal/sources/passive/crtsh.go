@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,16 +21,23 @@ type CrtSh struct {
 
 // crtshResponse represents the JSON response from crt.sh
 type crtshResponse struct {
-	NameValue string `json:"name_value"`
+	NameValue  string `json:"name_value"`
+	IssuerName string `json:"issuer_name"`
 }
 
 // NewCrtSh creates a new crt.sh source
 func NewCrtSh(enabled bool) *CrtSh {
+	return NewCrtShWithClient(enabled, &http.Client{
+		Timeout: 30 * time.Second,
+	})
+}
+
+// NewCrtShWithClient creates a crt.sh source that queries through the given
+// client, e.g. one routed to an httptest server
+func NewCrtShWithClient(enabled bool, client *http.Client) *CrtSh {
 	return &CrtSh{
 		enabled: enabled,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:  client,
 	}
 }
 
@@ -53,6 +61,11 @@ func (c *CrtSh) RateLimit() int {
 	return 10 // Be respectful to crt.sh
 }
 
+// RequiresNetwork reports that crt.sh is queried over the network
+func (c *CrtSh) RequiresNetwork() bool {
+	return true
+}
+
 // Enumerate performs subdomain discovery via Certificate Transparency
 func (c *CrtSh) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
 	startTime := time.Now()
@@ -97,8 +110,9 @@ func (c *CrtSh) Enumerate(ctx context.Context, domain string) (*types.SourceResu
 		return result, err
 	}
 	
-	// Extract unique subdomains
+	// Extract unique subdomains, with the issuers that certified each
 	subdomainMap := make(map[string]bool)
+	issuers := make(map[string]map[string]bool)
 	
 	for _, entry := range entries {
 		// Handle multiple domains in name_value (newline separated)
@@ -113,6 +127,13 @@ func (c *CrtSh) Enumerate(ctx context.Context, domain string) (*types.SourceResu
 			// Only include subdomains of the target domain
 			if strings.HasSuffix(d, "."+domain) || d == domain {
 				subdomainMap[d] = true
+				
+				if entry.IssuerName != "" {
+					if issuers[d] == nil {
+						issuers[d] = make(map[string]bool)
+					}
+					issuers[d][entry.IssuerName] = true
+				}
 			}
 		}
 	}
@@ -123,6 +144,15 @@ func (c *CrtSh) Enumerate(ctx context.Context, domain string) (*types.SourceResu
 		subdomains = append(subdomains, subdomain)
 	}
 	
+	for subdomain, set := range issuers {
+		names := make([]string, 0, len(set))
+		for issuer := range set {
+			names = append(names, issuer)
+		}
+		sort.Strings(names)
+		result.Annotate(subdomain, "crtsh_issuers", names)
+	}
+	
 	result.Subdomains = subdomains
 	result.Duration = time.Since(startTime)
 	
@@ -0,0 +1,85 @@
+package passive
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// crtshFixture is a canned crt.sh answer: names are newline separated,
+// may be wildcards or mixed case, and repeat across certificates
+const crtshFixture = `[
+	{"issuer_name": "C=US, O=Let's Encrypt, CN=R3", "name_value": "example.com\nwww.example.com"},
+	{"issuer_name": "C=US, O=Let's Encrypt, CN=R3", "name_value": "www.example.com"},
+	{"issuer_name": "C=US, O=DigiCert Inc, CN=DigiCert TLS RSA SHA256 2020 CA1", "name_value": "*.API.example.com\nwww.example.com"},
+	{"issuer_name": "", "name_value": "legacy.example.com"},
+	{"issuer_name": "C=US, O=Let's Encrypt, CN=R3", "name_value": "example.com.evil.net\nnotexample.com"}
+]`
+
+// crtshClient returns a client whose requests, whatever their host, reach
+// server
+func crtshClient(server *httptest.Server) *http.Client {
+	addr := server.Listener.Addr().String()
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+}
+
+func TestCrtShRecordsIssuers(t *testing.T) {
+	var query string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.Host + "/?" + r.URL.RawQuery
+		w.Write([]byte(crtshFixture))
+	}))
+	t.Cleanup(server.Close)
+
+	result, err := NewCrtShWithClient(true, crtshClient(server)).Enumerate(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Enumerate: %v", err)
+	}
+	if query != "crt.sh/?q=%25.example.com&output=json" {
+		t.Errorf("requested %q", query)
+	}
+
+	sort.Strings(result.Subdomains)
+	want := []string{"api.example.com", "example.com", "legacy.example.com", "www.example.com"}
+	if !reflect.DeepEqual(result.Subdomains, want) {
+		t.Errorf("subdomains = %v, want %v", result.Subdomains, want)
+	}
+
+	// Issuers are deduplicated and sorted per name; names from
+	// certificates without an issuer get no metadata
+	wantIssuers := map[string][]string{
+		"example.com":     {"C=US, O=Let's Encrypt, CN=R3"},
+		"api.example.com": {"C=US, O=DigiCert Inc, CN=DigiCert TLS RSA SHA256 2020 CA1"},
+		"www.example.com": {"C=US, O=DigiCert Inc, CN=DigiCert TLS RSA SHA256 2020 CA1", "C=US, O=Let's Encrypt, CN=R3"},
+	}
+	if len(result.Metadata) != len(wantIssuers) {
+		t.Errorf("metadata for %d names, want %d: %v", len(result.Metadata), len(wantIssuers), result.Metadata)
+	}
+	for name, issuers := range wantIssuers {
+		if got := result.Metadata[name]["crtsh_issuers"]; !reflect.DeepEqual(got, issuers) {
+			t.Errorf("%s issuers = %v, want %v", name, got, issuers)
+		}
+	}
+}
+
+func TestCrtShErrorStatus(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	result, err := NewCrtShWithClient(true, crtshClient(server)).Enumerate(context.Background(), "example.com")
+	if err == nil || result.Error == nil {
+		t.Errorf("502 from crt.sh not reported: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package passive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/internal/ratelimit"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// shodanMaxPages bounds how many result pages are fetched; each page costs
+// a query credit
+const shodanMaxPages = 10
+
+// Shodan implements subdomain enumeration via the Shodan DNS API
+type Shodan struct {
+	enabled bool
+	apiKey  string
+	baseURL string
+	client  *http.Client
+	limiter *ratelimit.Limiter
+}
+
+// shodanResponse represents one page of /dns/domain/{domain}
+type shodanResponse struct {
+	Subdomains []string       `json:"subdomains"`
+	Data       []shodanRecord `json:"data"`
+	More       bool           `json:"more"`
+}
+
+// shodanRecord is one DNS record Shodan holds for the domain
+type shodanRecord struct {
+	Subdomain string `json:"subdomain"`
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Ports     []int  `json:"ports"`
+}
+
+// NewShodan creates a new Shodan source. It is disabled without an API
+// key.
+func NewShodan(enabled bool, apiKey string) *Shodan {
+	return NewShodanWithClient(enabled, apiKey, "https://api.shodan.io", &http.Client{
+		Timeout: 30 * time.Second,
+	})
+}
+
+// NewShodanWithClient creates a Shodan source that queries baseURL through
+// the given client, e.g. an httptest server
+func NewShodanWithClient(enabled bool, apiKey, baseURL string, client *http.Client) *Shodan {
+	return &Shodan{
+		enabled: enabled && apiKey != "",
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+}
+
+// Name returns the source identifier
+func (s *Shodan) Name() string {
+	return "shodan"
+}
+
+// Type returns the source category
+func (s *Shodan) Type() sources.SourceType {
+	return sources.TypePassive
+}
+
+// IsEnabled checks if the source is enabled and has an API key
+func (s *Shodan) IsEnabled() bool {
+	return s.enabled
+}
+
+// RateLimit returns the rate limit (requests per second)
+func (s *Shodan) RateLimit() int {
+	return 1 // Shodan allows one API request per second
+}
+
+// SetRateLimiter paces the page requests, each of which counts against
+// the API's rate limit
+func (s *Shodan) SetRateLimiter(limiter *ratelimit.Limiter) {
+	s.limiter = limiter
+}
+
+// RequiresNetwork reports that Shodan is queried over the network
+func (s *Shodan) RequiresNetwork() bool {
+	return true
+}
+
+// Enumerate lists the subdomains Shodan has seen, recording the open ports
+// of each as "shodan_ports" metadata
+func (s *Shodan) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	startTime := time.Now()
+
+	result := &types.SourceResult{
+		Source: s.Name(),
+	}
+
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	names := make(map[string]bool)
+	ports := make(map[string]map[int]bool)
+
+	for page := 1; page <= shodanMaxPages; page++ {
+		resp, err := s.fetch(ctx, domain, page)
+		if err != nil {
+			result.Error = err
+			result.Duration = time.Since(startTime)
+			return result, err
+		}
+
+		for _, label := range resp.Subdomains {
+			if name := shodanHost(label, domain); name != "" {
+				names[name] = true
+			}
+		}
+
+		for _, record := range resp.Data {
+			name := shodanHost(record.Subdomain, domain)
+			if name == "" || len(record.Ports) == 0 {
+				continue
+			}
+			if ports[name] == nil {
+				ports[name] = make(map[int]bool)
+			}
+			for _, port := range record.Ports {
+				ports[name][port] = true
+			}
+		}
+
+		if !resp.More {
+			break
+		}
+	}
+
+	subdomains := make([]string, 0, len(names))
+	for name := range names {
+		subdomains = append(subdomains, name)
+	}
+
+	for name, set := range ports {
+		if !names[name] {
+			continue
+		}
+		open := make([]int, 0, len(set))
+		for port := range set {
+			open = append(open, port)
+		}
+		sort.Ints(open)
+		result.Annotate(name, "shodan_ports", open)
+	}
+
+	result.Subdomains = subdomains
+	result.Duration = time.Since(startTime)
+
+	return result, nil
+}
+
+// fetch requests one page of Shodan's DNS data for a domain
+func (s *Shodan) fetch(ctx context.Context, domain string, page int) (*shodanResponse, error) {
+	query := url.Values{}
+	query.Set("key", s.apiKey)
+	query.Set("page", fmt.Sprint(page))
+	endpoint := fmt.Sprintf("%s/dns/domain/%s?%s", s.baseURL, url.PathEscape(domain), query.Encode())
+
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "USR/1.0 (Universal Subdomain Reconnaissance)")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The request URL carries the key; keep it out of errors and logs
+		return nil, fmt.Errorf("shodan request failed: %w", errorWithoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("shodan rejected the API key (status %d)", resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("shodan returned status %d", resp.StatusCode)
+	}
+
+	var parsed shodanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// shodanHost turns a Shodan subdomain label into a full hostname under
+// domain; an empty label is the domain itself
+func shodanHost(label, domain string) string {
+	label = strings.ToLower(strings.Trim(strings.TrimSpace(label), "."))
+	label = strings.TrimPrefix(label, "*.")
+	if label == "" || label == "*" {
+		return domain
+	}
+	return label + "." + domain
+}
+
+// errorWithoutURL unwraps a *url.Error so the request URL is not reported
+func errorWithoutURL(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package passive
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// shodanPages are the fixture pages of /dns/domain/example.com
+var shodanPages = map[string]string{
+	"1": `{
+		"domain": "example.com",
+		"subdomains": ["www", "api", "", "*.dev"],
+		"data": [
+			{"subdomain": "www", "type": "A", "value": "192.0.2.1", "ports": [443, 80]},
+			{"subdomain": "api", "type": "A", "value": "192.0.2.2", "ports": [8443]},
+			{"subdomain": "", "type": "MX", "value": "mail.example.com"}
+		],
+		"more": true
+	}`,
+	"2": `{
+		"subdomains": ["mail", "WWW"],
+		"data": [
+			{"subdomain": "www", "type": "AAAA", "value": "2001:db8::1", "ports": [80, 8080]},
+			{"subdomain": "gone", "type": "A", "value": "192.0.2.9", "ports": [22]}
+		],
+		"more": false
+	}`,
+}
+
+// shodanServer serves the fixture pages to requests carrying the key
+func shodanServer(t *testing.T, key string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != key {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		page, ok := shodanPages[r.URL.Query().Get("page")]
+		if r.URL.Path != "/dns/domain/example.com" || !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestShodanCollectsSubdomainsAndPorts(t *testing.T) {
+	server := shodanServer(t, "secret")
+	s := NewShodanWithClient(true, "secret", server.URL, server.Client())
+
+	result, err := s.Enumerate(context.Background(), "Example.com.")
+	if err != nil {
+		t.Fatalf("Enumerate: %v", err)
+	}
+
+	sort.Strings(result.Subdomains)
+	want := []string{"api.example.com", "dev.example.com", "example.com", "mail.example.com", "www.example.com"}
+	if !reflect.DeepEqual(result.Subdomains, want) {
+		t.Errorf("subdomains = %v, want %v", result.Subdomains, want)
+	}
+
+	// Ports are merged across records and pages, sorted and deduplicated;
+	// records of names Shodan doesn't list as subdomains are dropped
+	wantPorts := map[string][]int{
+		"www.example.com": {80, 443, 8080},
+		"api.example.com": {8443},
+	}
+	if len(result.Metadata) != len(wantPorts) {
+		t.Errorf("metadata for %d hosts, want %d: %v", len(result.Metadata), len(wantPorts), result.Metadata)
+	}
+	for name, ports := range wantPorts {
+		if got := result.Metadata[name]["shodan_ports"]; !reflect.DeepEqual(got, ports) {
+			t.Errorf("%s ports = %v, want %v", name, got, ports)
+		}
+	}
+}
+
+func TestShodanRejectedKey(t *testing.T) {
+	server := shodanServer(t, "secret")
+	s := NewShodanWithClient(true, "wrong", server.URL, server.Client())
+
+	_, err := s.Enumerate(context.Background(), "example.com")
+	if err == nil || !strings.Contains(err.Error(), "rejected the API key") {
+		t.Errorf("err = %v, want the key rejected", err)
+	}
+}
+
+func TestShodanErrorsHideKey(t *testing.T) {
+	server := shodanServer(t, "secret")
+	server.Close()
+
+	s := NewShodanWithClient(true, "secret", server.URL, server.Client())
+	_, err := s.Enumerate(context.Background(), "example.com")
+	if err == nil {
+		t.Fatal("request to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error %q carries the API key", err)
+	}
+}
+
+func TestShodanNeedsKey(t *testing.T) {
+	if NewShodan(true, "").IsEnabled() {
+		t.Error("enabled without an API key")
+	}
+}
@@ -0,0 +1,255 @@
+package passive
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/types"
+)
+
+// ErrNoRecordResolver is returned when the SPF/DMARC source runs without a
+// scan context and no resolver was set
+var ErrNoRecordResolver = errors.New("spf_dmarc needs a DNS record resolver")
+
+// SPFDMARC finds hosts named in the target's mail policies: SPF include:
+// and redirect= targets and the domains of DMARC rua/ruf report addresses.
+// These often reveal mail infrastructure and third-party senders. Records
+// are only parsed, never evaluated; included SPF policies are followed up
+// to depth levels.
+type SPFDMARC struct {
+	enabled bool
+	depth   int
+
+	// Used when enumerating without a scan context (optional)
+	resolver types.RecordResolver
+}
+
+// NewSPFDMARC creates an SPF/DMARC source following depth levels of
+// included SPF policies (0 = the target's own policy only)
+func NewSPFDMARC(enabled bool, depth int) *SPFDMARC {
+	if depth < 0 {
+		depth = 0
+	}
+	return &SPFDMARC{
+		enabled: enabled,
+		depth:   depth,
+	}
+}
+
+// Name returns the source identifier
+func (s *SPFDMARC) Name() string {
+	return "spf_dmarc"
+}
+
+// Type returns the source category
+func (s *SPFDMARC) Type() sources.SourceType {
+	return sources.TypePassive
+}
+
+// IsEnabled checks if the source is enabled
+func (s *SPFDMARC) IsEnabled() bool {
+	return s.enabled
+}
+
+// RateLimit returns the rate limit; its few queries are bounded by the
+// scan's resolver instead
+func (s *SPFDMARC) RateLimit() int {
+	return 0
+}
+
+// RequiresNetwork reports that the records are looked up over DNS
+func (s *SPFDMARC) RequiresNetwork() bool {
+	return true
+}
+
+// SetResolver sets the resolver used by Enumerate, which has no scan
+// context to take one from
+func (s *SPFDMARC) SetResolver(resolver types.RecordResolver) {
+	s.resolver = resolver
+}
+
+// EnumerateScan parses the mail policies of the scan root using the scan's
+// resolver
+func (s *SPFDMARC) EnumerateScan(ctx context.Context, scan *types.ScanContext) (*types.SourceResult, error) {
+	if resolver, ok := scan.Resolver.(types.RecordResolver); ok {
+		return s.enumerate(ctx, resolver, scan.Root)
+	}
+	return s.Enumerate(ctx, scan.Root)
+}
+
+// Enumerate parses the mail policies of domain, recording the TXT records
+// naming each host as "spf_dmarc_records" metadata
+func (s *SPFDMARC) Enumerate(ctx context.Context, domain string) (*types.SourceResult, error) {
+	if s.resolver == nil {
+		return &types.SourceResult{Source: s.Name(), Error: ErrNoRecordResolver}, ErrNoRecordResolver
+	}
+	return s.enumerate(ctx, s.resolver, domain)
+}
+
+// enumerate collects the hosts named by domain's SPF and DMARC records
+func (s *SPFDMARC) enumerate(ctx context.Context, resolver types.RecordResolver, domain string) (*types.SourceResult, error) {
+	startTime := time.Now()
+
+	result := &types.SourceResult{
+		Source: s.Name(),
+	}
+
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	named := make(map[string]map[string]bool)
+	add := func(host, record string) {
+		if named[host] == nil {
+			named[host] = make(map[string]bool)
+		}
+		named[host][record] = true
+	}
+
+	// SPF, following included policies while depth allows
+	visited := map[string]bool{domain: true}
+	level := []string{domain}
+	for depth := 0; depth <= s.depth && len(level) > 0; depth++ {
+		var next []string
+		for _, name := range level {
+			for _, record := range lookupTXT(ctx, resolver, name) {
+				if !IsSPF(record) {
+					continue
+				}
+				for _, host := range ParseSPF(record) {
+					add(host, record)
+					if !visited[host] {
+						visited[host] = true
+						next = append(next, host)
+					}
+				}
+			}
+		}
+		level = next
+	}
+
+	// DMARC report addresses
+	for _, record := range lookupTXT(ctx, resolver, "_dmarc."+domain) {
+		if !IsDMARC(record) {
+			continue
+		}
+		for _, host := range ParseDMARC(record) {
+			add(host, record)
+		}
+	}
+
+	result.Duration = time.Since(startTime)
+	if err := ctx.Err(); err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	subdomains := make([]string, 0, len(named))
+	for host, records := range named {
+		subdomains = append(subdomains, host)
+
+		raw := make([]string, 0, len(records))
+		for record := range records {
+			raw = append(raw, record)
+		}
+		sort.Strings(raw)
+		result.Annotate(host, "spf_dmarc_records", raw)
+	}
+	sort.Strings(subdomains)
+	result.Subdomains = subdomains
+
+	return result, nil
+}
+
+// lookupTXT returns a name's TXT records; a name without any is not an
+// error for this source
+func lookupTXT(ctx context.Context, resolver types.RecordResolver, name string) []string {
+	records, err := resolver.ResolveRecords(ctx, name, []string{"TXT"})
+	if err != nil || records == nil {
+		return nil
+	}
+	return records.TXT
+}
+
+// IsSPF reports whether a TXT record is an SPF policy
+func IsSPF(record string) bool {
+	record = strings.ToLower(strings.TrimSpace(record))
+	return record == "v=spf1" || strings.HasPrefix(record, "v=spf1 ")
+}
+
+// IsDMARC reports whether a TXT record is a DMARC policy
+func IsDMARC(record string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(record)), "v=dmarc1")
+}
+
+// ParseSPF returns the hosts an SPF policy delegates to through include:
+// mechanisms and the redirect= modifier. Targets using macros (%{...})
+// can't be resolved syntactically and are skipped.
+func ParseSPF(record string) []string {
+	var hosts []string
+	for _, term := range strings.Fields(record) {
+		term = strings.ToLower(term)
+
+		// Qualifiers (+ - ~ ?) may prefix mechanisms
+		term = strings.TrimLeft(term, "+-~?")
+
+		var target string
+		switch {
+		case strings.HasPrefix(term, "include:"):
+			target = strings.TrimPrefix(term, "include:")
+		case strings.HasPrefix(term, "redirect="):
+			target = strings.TrimPrefix(term, "redirect=")
+		default:
+			continue
+		}
+
+		if host := policyHost(target); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// ParseDMARC returns the domains of a DMARC policy's aggregate (rua) and
+// forensic (ruf) report addresses
+func ParseDMARC(record string) []string {
+	var hosts []string
+	for _, tag := range strings.Split(record, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(tag), "=")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "rua" && name != "ruf" {
+			continue
+		}
+
+		for _, uri := range strings.Split(value, ",") {
+			uri = strings.TrimSpace(uri)
+			if !strings.HasPrefix(strings.ToLower(uri), "mailto:") {
+				continue
+			}
+			// mailto:reports@example.com!10m: the size limit follows "!"
+			address, _, _ := strings.Cut(uri[len("mailto:"):], "!")
+			_, host, ok := strings.Cut(address, "@")
+			if !ok {
+				continue
+			}
+			if host = policyHost(host); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts
+}
+
+// policyHost normalizes a hostname taken from a policy, returning "" for
+// anything that isn't a plain hostname
+func policyHost(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+	if host == "" || !strings.Contains(host, ".") || strings.ContainsAny(host, "%{}/ @") {
+		return ""
+	}
+	return host
+}
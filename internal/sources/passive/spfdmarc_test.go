@@ -0,0 +1,193 @@
+package passive
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// txtResolver answers TXT lookups from fixed records and counts them
+type txtResolver struct {
+	txt map[string][]string
+
+	mu      sync.Mutex
+	queries map[string]int
+}
+
+func (r *txtResolver) ResolveRecords(ctx context.Context, domain string, qtypes []string) (*types.DNSRecords, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.queries == nil {
+		r.queries = make(map[string]int)
+	}
+	r.queries[domain]++
+
+	records, ok := r.txt[domain]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return &types.DNSRecords{TXT: records}, nil
+}
+
+func TestParseSPF(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   []string
+	}{
+		{"include", "v=spf1 include:_spf.google.com ~all", []string{"_spf.google.com"}},
+		{"several", "v=spf1 ip4:192.0.2.0/24 include:spf.protection.outlook.com include:mail.zendesk.com -all", []string{"spf.protection.outlook.com", "mail.zendesk.com"}},
+		{"redirect", "v=spf1 redirect=_spf.example.com", []string{"_spf.example.com"}},
+		{"qualified", "v=spf1 ?include:a.example.net +include:b.example.net -all", []string{"a.example.net", "b.example.net"}},
+		{"case and trailing dot", "V=SPF1 INCLUDE:SPF.Example.NET. ~ALL", []string{"spf.example.net"}},
+		{"macro", "v=spf1 include:%{ir}.%{v}._spf.example.com ~all", nil},
+		{"single label", "v=spf1 include:localhost ~all", nil},
+		{"other mechanisms", "v=spf1 a mx a:web.example.com exists:x.example.com ~all", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseSPF(tt.record); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSPF(%q) = %v, want %v", tt.record, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDMARC(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   []string
+	}{
+		{"rua", "v=DMARC1; p=reject; rua=mailto:dmarc@example.com", []string{"example.com"}},
+		{"rua and ruf", "v=DMARC1; p=none; rua=mailto:agg@reports.example.net; ruf=mailto:forensic@example.org", []string{"reports.example.net", "example.org"}},
+		{"several addresses", "v=DMARC1; rua=mailto:a@one.example.com,mailto:b@two.example.com", []string{"one.example.com", "two.example.com"}},
+		{"size limit", "v=DMARC1; rua=mailto:a@dmarc.example.com!10m", []string{"dmarc.example.com"}},
+		{"spacing and case", "v=DMARC1 ;  RUA = MAILTO:A@Upper.Example.COM ", []string{"upper.example.com"}},
+		{"not mailto", "v=DMARC1; rua=https://reports.example.com/dmarc", nil},
+		{"no report tags", "v=DMARC1; p=quarantine; pct=100", nil},
+		{"no domain", "v=DMARC1; rua=mailto:postmaster", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseDMARC(tt.record); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDMARC(%q) = %v, want %v", tt.record, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyKinds(t *testing.T) {
+	tests := []struct {
+		record     string
+		spf, dmarc bool
+	}{
+		{record: "v=spf1 -all", spf: true},
+		{record: "v=spf1", spf: true},
+		{record: "  V=SPF1 include:x.example.com", spf: true},
+		{record: "v=spf10 -all"},
+		{record: "v=DMARC1; p=none", dmarc: true},
+		{record: "google-site-verification=abc"},
+	}
+
+	for _, tt := range tests {
+		if got := IsSPF(tt.record); got != tt.spf {
+			t.Errorf("IsSPF(%q) = %v", tt.record, got)
+		}
+		if got := IsDMARC(tt.record); got != tt.dmarc {
+			t.Errorf("IsDMARC(%q) = %v", tt.record, got)
+		}
+	}
+}
+
+// mailPolicies is a chain of SPF includes with a loop, plus a DMARC policy
+var mailPolicies = map[string][]string{
+	"example.com": {
+		"v=spf1 include:_spf.example.com include:mail.vendor.example ~all",
+		"google-site-verification=abc",
+	},
+	"_spf.example.com":        {"v=spf1 include:_spf2.example.com -all"},
+	"_spf2.example.com":       {"v=spf1 include:example.com redirect=_deep.example.com"},
+	"_deep.example.com":       {"v=spf1 include:too.deep.example -all"},
+	"mail.vendor.example":     {"v=spf1 ip4:198.51.100.0/24 -all"},
+	"_dmarc.example.com":      {"v=DMARC1; p=reject; rua=mailto:dmarc@reports.example.com; ruf=mailto:f@example.net"},
+	"_dmarc._spf.example.com": {"v=DMARC1; rua=mailto:never@followed.example"},
+}
+
+func TestSPFDMARCFollowsIncludes(t *testing.T) {
+	tests := []struct {
+		depth int
+		want  []string
+	}{
+		{
+			depth: 0,
+			want:  []string{"_spf.example.com", "example.net", "mail.vendor.example", "reports.example.com"},
+		},
+		{
+			depth: 1,
+			want:  []string{"_spf.example.com", "_spf2.example.com", "example.net", "mail.vendor.example", "reports.example.com"},
+		},
+		{
+			// The include back to example.com is named but not followed again
+			depth: 3,
+			want:  []string{"_deep.example.com", "_spf.example.com", "_spf2.example.com", "example.com", "example.net", "mail.vendor.example", "reports.example.com", "too.deep.example"},
+		},
+	}
+
+	for _, tt := range tests {
+		resolver := &txtResolver{txt: mailPolicies}
+		s := NewSPFDMARC(true, tt.depth)
+		s.SetResolver(resolver)
+
+		result, err := s.Enumerate(context.Background(), "Example.com.")
+		if err != nil {
+			t.Fatalf("depth %d: %v", tt.depth, err)
+		}
+		if strings.Join(result.Subdomains, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("depth %d: hosts = %v, want %v", tt.depth, result.Subdomains, tt.want)
+		}
+		for name, count := range resolver.queries {
+			if count > 1 {
+				t.Errorf("depth %d: %s looked up %d times", tt.depth, name, count)
+			}
+		}
+
+		// DMARC is the target's own; included policies' aren't looked up
+		if resolver.queries["_dmarc._spf.example.com"] > 0 {
+			t.Errorf("depth %d: DMARC of an included policy looked up", tt.depth)
+		}
+	}
+}
+
+func TestSPFDMARCRecordsNamingEachHost(t *testing.T) {
+	s := NewSPFDMARC(true, 0)
+	s.SetResolver(&txtResolver{txt: mailPolicies})
+
+	result, err := s.Enumerate(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Enumerate: %v", err)
+	}
+
+	want := map[string][]string{
+		"_spf.example.com":    {mailPolicies["example.com"][0]},
+		"reports.example.com": mailPolicies["_dmarc.example.com"],
+	}
+	for host, records := range want {
+		if got := result.Metadata[host]["spf_dmarc_records"]; !reflect.DeepEqual(got, records) {
+			t.Errorf("%s records = %v, want %v", host, got, records)
+		}
+	}
+}
+
+func TestSPFDMARCNeedsResolver(t *testing.T) {
+	if _, err := NewSPFDMARC(true, 1).Enumerate(context.Background(), "example.com"); !errors.Is(err, ErrNoRecordResolver) {
+		t.Errorf("err = %v, want ErrNoRecordResolver", err)
+	}
+}
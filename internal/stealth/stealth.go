@@ -0,0 +1,123 @@
+package stealth
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+)
+
+// defaultUserAgents are common browser User-Agents rotated in stealth mode
+var defaultUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+	"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+}
+
+// Profile applies stealth behavior: low concurrency, randomized delays
+// between requests and rotating User-Agents
+type Profile struct {
+	maxConcurrency int
+	jitterMin      time.Duration
+	jitterMax      time.Duration
+	userAgents     []string
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewProfile creates a stealth profile from configuration
+func NewProfile(cfg *config.StealthConfig) *Profile {
+	userAgents := cfg.UserAgents
+	if len(userAgents) == 0 {
+		userAgents = defaultUserAgents
+	}
+
+	jitterMin := time.Duration(cfg.JitterMin) * time.Millisecond
+	jitterMax := time.Duration(cfg.JitterMax) * time.Millisecond
+	if jitterMax < jitterMin {
+		jitterMax = jitterMin
+	}
+
+	return &Profile{
+		maxConcurrency: cfg.MaxConcurrency,
+		jitterMin:      jitterMin,
+		jitterMax:      jitterMax,
+		userAgents:     userAgents,
+		rng:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Wait sleeps for a random duration within the jitter range
+func (p *Profile) Wait(ctx context.Context) error {
+	delay := p.jitterMin
+	if spread := p.jitterMax - p.jitterMin; spread > 0 {
+		delay += time.Duration(p.Intn(int(spread)))
+	}
+
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Transport wraps next so every request, redirects included, waits out the
+// jitter first. Clients shared between probers, crawlers and checkers are
+// paced wherever their requests are built. A nil next uses
+// http.DefaultTransport.
+func (p *Profile) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &jitterTransport{profile: p, next: next}
+}
+
+// jitterTransport delays each request by the profile's jitter
+type jitterTransport struct {
+	profile *Profile
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *jitterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.profile.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// UserAgent returns a random User-Agent from the pool
+func (p *Profile) UserAgent() string {
+	return p.userAgents[p.Intn(len(p.userAgents))]
+}
+
+// Concurrency caps a worker count to the stealth maximum
+func (p *Profile) Concurrency(workers int) int {
+	if p.maxConcurrency > 0 && workers > p.maxConcurrency {
+		return p.maxConcurrency
+	}
+	return workers
+}
+
+// Intn returns a random number in [0, n), safe for concurrent use
+func (p *Profile) Intn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rng.Intn(n)
+}
@@ -0,0 +1,75 @@
+package stealth
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransportWaitsBeforeEachRequest(t *testing.T) {
+	const jitter = 40 * time.Millisecond
+	profile := NewProfile(&config.StealthConfig{JitterMin: 40, JitterMax: 40})
+
+	var sent atomic.Int32
+	transport := profile.Transport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent.Add(1)
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: req}, nil
+	}))
+
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatalf("RoundTrip: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 2*jitter {
+		t.Errorf("two requests took %v, want at least %v", elapsed, 2*jitter)
+	}
+	if sent.Load() != 2 {
+		t.Errorf("requests sent = %d, want 2", sent.Load())
+	}
+}
+
+func TestTransportStopsOnCancel(t *testing.T) {
+	profile := NewProfile(&config.StealthConfig{JitterMin: 10000, JitterMax: 10000})
+
+	var sent atomic.Int32
+	transport := profile.Transport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent.Add(1)
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com/", nil)
+
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Error("canceled request sent")
+	}
+	if sent.Load() != 0 {
+		t.Errorf("requests sent = %d, want 0", sent.Load())
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	profile := NewProfile(&config.StealthConfig{MaxConcurrency: 3})
+
+	for workers, want := range map[int]int{50: 3, 3: 3, 1: 1} {
+		if got := profile.Concurrency(workers); got != want {
+			t.Errorf("Concurrency(%d) = %d, want %d", workers, got, want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"context"
+	"strings"
 	"time"
 )
 
@@ -16,11 +18,25 @@ type Subdomain struct {
 	HTTP        *HTTPInfo              `json:"http,omitempty"`
 	TLS         *TLSInfo               `json:"tls,omitempty"`
 	DNSRecords  *DNSRecords            `json:"dns_records,omitempty"`
+	Findings    []Finding              `json:"findings,omitempty"`
+	Tags        []string               `json:"tags,omitempty"` // namespace:value, e.g. environment:staging
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	
+	// Provisional marks a host streamed before the scan finished; its
+	// confidence only reflects what was known at the time
+	Provisional bool `json:"provisional,omitempty"`
+}
+
+// Technology is a technology detected on a host
+type Technology struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	Confidence int    `json:"confidence"` // 0-100
 }
 
 // HTTPInfo contains HTTP probe results
 type HTTPInfo struct {
+	URL          string            `json:"url,omitempty"` // scheme and host that answered
 	StatusCode   int               `json:"status_code"`
 	Title        string            `json:"title,omitempty"`
 	Server       string            `json:"server,omitempty"`
@@ -28,6 +44,56 @@ type HTTPInfo struct {
 	ResponseTime time.Duration     `json:"response_time"`
 	Headers      map[string]string `json:"headers,omitempty"`
 	Technologies []string          `json:"technologies,omitempty"`
+	TechDetails  []Technology      `json:"tech_details,omitempty"` // version and confidence of each technology
+	RedirectLoop bool              `json:"redirect_loop,omitempty"`
+	Parked       bool              `json:"parked,omitempty"`
+	API          bool              `json:"api,omitempty"`
+	APIType      string            `json:"api_type,omitempty"` // rest, graphql, openapi, jsonapi, hal
+	APISpec      string            `json:"api_spec,omitempty"` // URL of a published OpenAPI/Swagger spec
+	Snippet      string            `json:"snippet,omitempty"`  // start of a JSON body, in place of a title
+	
+	// HeaderHosts are hostnames named by the response and redirect headers
+	// (Location, CSP, Access-Control-Allow-Origin, Link), unfiltered by scope
+	HeaderHosts []string `json:"header_hosts,omitempty"`
+	
+	// FinalURL is the URL of the response kept after following redirects;
+	// RedirectChain lists every URL requested on the way, starting with the
+	// probed one, and is only set when the probe was redirected
+	FinalURL      string   `json:"final_url,omitempty"`
+	RedirectChain []string `json:"redirect_chain,omitempty"`
+	
+	// Body fingerprints for grouping hosts serving the same page: the
+	// SHA-256 and simhash of the body with tokens and timestamps stripped
+	// (empty for an empty body) and the raw body length
+	BodyHash    string `json:"body_hash,omitempty"`
+	BodySimhash uint64 `json:"body_simhash,omitempty"`
+	BodyLength  int    `json:"body_length,omitempty"`
+	
+	// FaviconHash is the Shodan-style mmh3 hash of /favicon.ico, for
+	// finding other hosts with the same icon (0 = no icon)
+	FaviconHash int32 `json:"favicon_hash,omitempty"`
+	
+	// Screenshot is the path of a PNG of the page (http.screenshots)
+	Screenshot string `json:"screenshot,omitempty"`
+}
+
+// Finding severities
+const (
+	SeverityInfo   = "info"
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// Finding is an exposure observed on a host, e.g. an accessible /.git/HEAD
+type Finding struct {
+	Type       string    `json:"type"` // exposed_path, ...
+	Title      string    `json:"title"`
+	Severity   string    `json:"severity"`
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Evidence   string    `json:"evidence,omitempty"`
+	Time       time.Time `json:"time"`
 }
 
 // TLSInfo contains TLS certificate information
@@ -51,23 +117,156 @@ type DNSRecords struct {
 	TXT   []string `json:"txt,omitempty"`
 }
 
-// SourceResult represents raw output from a single source
+// Registration contains WHOIS/RDAP registration data for an apex domain
+type Registration struct {
+	Domain      string    `json:"domain"`
+	Registrar   string    `json:"registrar,omitempty"`
+	Registrant  string    `json:"registrant,omitempty"`
+	Created     time.Time `json:"created,omitempty"`
+	Updated     time.Time `json:"updated,omitempty"`
+	Expires     time.Time `json:"expires,omitempty"`
+	Nameservers []string  `json:"nameservers,omitempty"`
+	Source      string    `json:"source"` // rdap, whois
+}
+
+// SourceResult represents raw output from a single source. Subdomains
+// lists every name found; sources that learn more about a name (open
+// ports, certificate issuer) attach it with Annotate.
 type SourceResult struct {
 	Source    string
 	Subdomains []string
 	Error     error
 	Duration  time.Duration
+	
+	// Extra facts per subdomain, keyed by subdomain then by metadata key;
+	// merged into Subdomain.Metadata, later values replacing earlier ones
+	// under the same key. Keys are prefixed with the source name
+	// (shodan_ports) so sources don't overwrite each other.
+	Metadata  map[string]map[string]interface{}
 }
 
-// ScanContext contains all information needed for a scan
+// Annotate attaches a metadata value to a subdomain of the result
+func (r *SourceResult) Annotate(subdomain, key string, value interface{}) {
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]map[string]interface{})
+	}
+	if r.Metadata[subdomain] == nil {
+		r.Metadata[subdomain] = make(map[string]interface{})
+	}
+	r.Metadata[subdomain][key] = value
+}
+
+// ScanContext carries per-scan state shared by every phase and source.
+// It is built once at the start of a scan and must not be reused.
 type ScanContext struct {
 	Domain      string
+	Apex        string // registrable domain, lowercased, no trailing dot
+	Root        string // name results are scoped to: Domain, or Apex under scope.key_mode registrable
 	Mode        ScanMode
 	Config      interface{} // Will be *config.Config
+	Wildcard    *WildcardInfo
+	Baseline    *DNSRecords
+	
+	// Detections for parents below the root (dev.example.com for
+	// *.dev.example.com), keyed by parent; set after DNS validation
+	NestedWildcards map[string]*WildcardInfo
+	
+	Scope       func(name string) bool
+	Budget      ScanBudget
+	Resolver    BatchResolver
+	Attempted   CandidateSet
+	Results     ResultStore
 	ResultsChan chan *Subdomain
 	ErrorsChan  chan error
 }
 
+// ScanBudget holds the concurrency and rate limits a scan may spend
+type ScanBudget struct {
+	MaxThreads   int
+	DNSWorkers   int
+	HTTPWorkers  int
+	DNSRateLimit int // in-flight DNS queries across the scan (0 = unlimited)
+	
+	// ActiveWorkers is how many resolutions a single active source
+	// (brute force, permutations, recursion) runs at once
+	ActiveWorkers int
+}
+
+// BatchResolver resolves many names concurrently. The scan's resolver is
+// shared by every phase and source so its rate limit bounds the whole scan.
+type BatchResolver interface {
+	ResolveBatch(ctx context.Context, domains []string, workers int) map[string][]string
+}
+
+// RecordBatchResolver resolves many names concurrently, querying only the
+// given record types. The scan's resolver implements it alongside
+// BatchResolver; names with no answers are left out.
+type RecordBatchResolver interface {
+	ResolveBatchRecords(ctx context.Context, domains []string, workers int, qtypes []string) map[string]*DNSRecords
+}
+
+// RecordResolver looks up records of the given types (A, MX, TXT...) for a
+// name. The scan's resolver implements it alongside BatchResolver.
+type RecordResolver interface {
+	ResolveRecords(ctx context.Context, domain string, qtypes []string) (*DNSRecords, error)
+}
+
+// CandidateSet tracks names already resolved during a scan
+type CandidateSet interface {
+	Claim(name string) bool
+	Filter(names []string) []string
+	Len() int
+}
+
+// ResultStore is the scan's shared set of discovered subdomains
+type ResultStore interface {
+	Add(source string, subdomains []string)
+	Snapshot() []*Subdomain
+}
+
+// InScope reports whether a name belongs to the scan target
+func (s *ScanContext) InScope(name string) bool {
+	if s.Scope == nil {
+		return true
+	}
+	return s.Scope(name)
+}
+
+// IsWildcardIP reports whether an IP is one of the detected wildcard answers
+func (s *ScanContext) IsWildcardIP(ip string) bool {
+	return s.Wildcard.Matches(ip)
+}
+
+// WildcardFor returns the detection that applies to a name: that of its
+// nearest tested parent, else the root's. A parent that exists without a
+// wildcard shadows a wildcard higher up, as it does in DNS.
+func (s *ScanContext) WildcardFor(name string) *WildcardInfo {
+	for parent := name; ; {
+		_, rest, found := strings.Cut(parent, ".")
+		if !found || rest == s.Root {
+			break
+		}
+		if info, ok := s.NestedWildcards[rest]; ok {
+			return info
+		}
+		parent = rest
+	}
+	return s.Wildcard
+}
+
+// HasWildcards reports whether wildcard DNS was found at the root or below
+func (s *ScanContext) HasWildcards() bool {
+	if s.Wildcard != nil && s.Wildcard.IsWildcard {
+		return true
+	}
+	for _, info := range s.NestedWildcards {
+		if info.IsWildcard {
+			return true
+		}
+	}
+	return false
+}
+
 // ScanMode defines the type of scan
 type ScanMode string
 
@@ -78,10 +277,31 @@ const (
 	ModeStealth    ScanMode = "stealth"
 )
 
-// WildcardInfo contains wildcard detection information
+// WildcardInfo contains wildcard detection information. A and AAAA are
+// judged separately: many CDNs answer wildcard AAAA but not A.
 type WildcardInfo struct {
 	IsWildcard    bool
-	Patterns      []string
+	Patterns      []string            // wildcard A answers
+	PatternsV6    []string            // wildcard AAAA answers
 	TestResults   map[string][]string // test subdomain -> IPs
 	DetectedAt    time.Time
+}
+
+// Matches reports whether an IP is one of the wildcard answers of its
+// address family
+func (w *WildcardInfo) Matches(ip string) bool {
+	if w == nil || !w.IsWildcard {
+		return false
+	}
+	
+	patterns := w.Patterns
+	if strings.Contains(ip, ":") {
+		patterns = w.PatternsV6
+	}
+	for _, pattern := range patterns {
+		if ip == pattern {
+			return true
+		}
+	}
+	return false
 }
\ No newline at end of file
@@ -0,0 +1,362 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/stealth"
+	"go.uber.org/zap"
+)
+
+// negativeCacheFile holds missing buckets under storage.cache_dir
+const negativeCacheFile = "cloud-negative.json"
+
+// Backoff bounds for a throttled provider
+const (
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+// checkResult is the outcome of a single existence check
+type checkResult int
+
+const (
+	resultUnknown checkResult = iota
+	resultExists
+	resultMissing
+	resultThrottled
+)
+
+// Checker tests whether candidate buckets exist, with a bounded worker
+// pool, per-provider backoff when a provider throttles (S3 503 SlowDown,
+// 429) and a persistent cache of buckets known not to exist
+type Checker struct {
+	client  *http.Client
+	logger  *zap.Logger
+	workers int
+	retries int
+
+	// Negative cache: URL -> when it was found missing
+	negativeTTL   time.Duration
+	cachePath     string
+	negative      map[string]time.Time
+	negativeMu    sync.Mutex
+	negativeDirty bool
+
+	backoffs   map[string]*providerBackoff
+	backoffsMu sync.Mutex
+}
+
+// providerBackoff pauses all requests to one provider after throttling
+type providerBackoff struct {
+	mu    sync.Mutex
+	until time.Time
+	delay time.Duration
+}
+
+// NewChecker creates a bucket checker. cacheDir is where missing buckets
+// are remembered between runs ("" disables the cache).
+func NewChecker(cfg *config.CloudConfig, cacheDir string, logger *zap.Logger) *Checker {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	c := &Checker{
+		client: &http.Client{
+			Timeout: time.Duration(cfg.Timeout) * time.Second,
+			// The bucket's own response is what matters, not where it points
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger:      logger,
+		workers:     workers,
+		retries:     cfg.Retries,
+		negativeTTL: time.Duration(cfg.NegativeTTL) * time.Hour,
+		negative:    make(map[string]time.Time),
+		backoffs:    make(map[string]*providerBackoff),
+	}
+
+	if cacheDir != "" && c.negativeTTL > 0 {
+		c.cachePath = filepath.Join(cacheDir, negativeCacheFile)
+		c.loadNegative()
+	}
+
+	return c
+}
+
+// SetStealth caps the checker's workers and delays each request by the
+// profile's jitter
+func (c *Checker) SetStealth(profile *stealth.Profile) {
+	c.workers = profile.Concurrency(c.workers)
+	c.client.Transport = profile.Transport(c.client.Transport)
+}
+
+// Check tests each asset and returns those that exist, with Exists set.
+// Missing buckets are cached; throttled ones are retried after backoff.
+func (c *Checker) Check(ctx context.Context, assets []CloudAsset) []CloudAsset {
+	if len(assets) == 0 {
+		return nil
+	}
+
+	c.logger.Info("Checking cloud buckets",
+		zap.Int("candidates", len(assets)),
+		zap.Int("workers", c.workers),
+	)
+
+	var (
+		mu      sync.Mutex
+		found   []CloudAsset
+		skipped int
+	)
+
+	err := pool.Run(ctx, assets, c.workers, func(ctx context.Context, asset CloudAsset) {
+		if c.knownMissing(asset.URL) {
+			mu.Lock()
+			skipped++
+			mu.Unlock()
+			return
+		}
+
+		switch c.checkWithBackoff(ctx, asset) {
+		case resultExists:
+			asset.Exists = true
+			mu.Lock()
+			found = append(found, asset)
+			mu.Unlock()
+		case resultMissing:
+			c.markMissing(asset.URL)
+		}
+	})
+	if err != nil {
+		c.logger.Error("Cloud bucket check panicked", zap.Error(err))
+	}
+
+	if err := c.saveNegative(); err != nil {
+		c.logger.Warn("Failed to save cloud negative cache", zap.Error(err))
+	}
+
+	c.logger.Info("Cloud bucket check complete",
+		zap.Int("candidates", len(assets)),
+		zap.Int("found", len(found)),
+		zap.Int("cached_missing", skipped),
+	)
+
+	return found
+}
+
+// checkWithBackoff checks an asset, waiting out and retrying on throttling
+func (c *Checker) checkWithBackoff(ctx context.Context, asset CloudAsset) checkResult {
+	return c.withBackoff(ctx, asset.Provider, func() checkResult {
+		return c.checkOnce(ctx, asset)
+	})
+}
+
+// withBackoff runs a request against a provider, waiting out and retrying
+// it while the provider throttles
+func (c *Checker) withBackoff(ctx context.Context, provider string, request func() checkResult) checkResult {
+	backoff := c.backoffFor(provider)
+
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if err := backoff.wait(ctx); err != nil {
+			return resultUnknown
+		}
+
+		result := request()
+		if result != resultThrottled {
+			backoff.reset()
+			return result
+		}
+
+		delay := backoff.throttled()
+		c.logger.Debug("Cloud provider throttling, backing off",
+			zap.String("provider", provider),
+			zap.Duration("delay", delay),
+		)
+	}
+
+	return resultUnknown
+}
+
+// checkOnce issues a HEAD request and interprets the status code
+func (c *Checker) checkOnce(ctx context.Context, asset CloudAsset) checkResult {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", asset.URL, nil)
+	if err != nil {
+		return resultUnknown
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// Per-account hostnames (Azure, Firebase) don't resolve when missing
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return resultMissing
+		}
+		return resultUnknown
+	}
+	resp.Body.Close()
+
+	switch {
+	case existsStatus(asset.Type, resp.StatusCode):
+		return resultExists
+	case resp.StatusCode == http.StatusNotFound:
+		return resultMissing
+	case resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusTooManyRequests:
+		return resultThrottled
+	default:
+		// A 400 or 5xx says nothing about the bucket
+		return resultUnknown
+	}
+}
+
+// existsStatus reports whether a status only comes from an existing bucket:
+// 200 (public), 403 (private) and, for S3, 301/307 (another region)
+func existsStatus(assetType string, status int) bool {
+	switch status {
+	case http.StatusOK, http.StatusForbidden:
+		return true
+	case http.StatusMovedPermanently, http.StatusTemporaryRedirect:
+		return assetType == "s3"
+	}
+	return false
+}
+
+// backoffFor returns the shared backoff state for a provider
+func (c *Checker) backoffFor(provider string) *providerBackoff {
+	c.backoffsMu.Lock()
+	defer c.backoffsMu.Unlock()
+
+	b, ok := c.backoffs[provider]
+	if !ok {
+		b = &providerBackoff{}
+		c.backoffs[provider] = b
+	}
+	return b
+}
+
+// wait blocks until the provider's backoff has expired
+func (b *providerBackoff) wait(ctx context.Context) error {
+	b.mu.Lock()
+	delay := time.Until(b.until)
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// throttled doubles the provider's delay and pauses it for that long
+func (b *providerBackoff) throttled() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.delay == 0 {
+		b.delay = initialBackoff
+	} else {
+		b.delay = min(b.delay*2, maxBackoff)
+	}
+
+	// Workers hitting the same throttle extend, not stack, the pause
+	until := time.Now().Add(b.delay)
+	if until.After(b.until) {
+		b.until = until
+	}
+	return b.delay
+}
+
+// reset clears the delay after a successful request
+func (b *providerBackoff) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.delay = 0
+}
+
+// knownMissing reports whether a URL was found missing within the TTL
+func (c *Checker) knownMissing(url string) bool {
+	c.negativeMu.Lock()
+	defer c.negativeMu.Unlock()
+
+	seen, ok := c.negative[url]
+	return ok && time.Since(seen) < c.negativeTTL
+}
+
+// markMissing records a URL as missing
+func (c *Checker) markMissing(url string) {
+	if c.negativeTTL <= 0 {
+		return
+	}
+
+	c.negativeMu.Lock()
+	defer c.negativeMu.Unlock()
+	c.negative[url] = time.Now()
+	c.negativeDirty = true
+}
+
+// loadNegative reads the negative cache, dropping expired entries
+func (c *Checker) loadNegative() {
+	data, err := os.ReadFile(c.cachePath)
+	if err != nil {
+		return
+	}
+
+	var entries map[string]time.Time
+	if err := json.Unmarshal(data, &entries); err != nil {
+		c.logger.Debug("Ignoring unreadable cloud negative cache", zap.Error(err))
+		return
+	}
+
+	for url, seen := range entries {
+		if time.Since(seen) < c.negativeTTL {
+			c.negative[url] = seen
+		}
+	}
+}
+
+// saveNegative writes the negative cache if it changed
+func (c *Checker) saveNegative() error {
+	c.negativeMu.Lock()
+	defer c.negativeMu.Unlock()
+
+	if c.cachePath == "" || !c.negativeDirty {
+		return nil
+	}
+
+	data, err := json.Marshal(c.negative)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.cachePath), 0755); err != nil {
+		return err
+	}
+
+	tmp := c.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.cachePath); err != nil {
+		return err
+	}
+
+	c.negativeDirty = false
+	return nil
+}
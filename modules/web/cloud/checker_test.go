@@ -0,0 +1,50 @@
+package cloud
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCheckOnlyTrustsExistenceStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		asset   CloudAsset
+		status  int
+		exists  bool
+		missing bool
+	}{
+		{name: "public", asset: s3Asset("public"), status: http.StatusOK, exists: true},
+		{name: "private", asset: s3Asset("private"), status: http.StatusForbidden, exists: true},
+		{name: "s3 other region", asset: s3Asset("moved"), status: http.StatusMovedPermanently, exists: true},
+		{name: "s3 temporary redirect", asset: s3Asset("redirect"), status: http.StatusTemporaryRedirect, exists: true},
+		{name: "gcs redirect", asset: CloudAsset{Provider: "Google Cloud", Type: "gcs", Bucket: "moved", URL: "https://storage.googleapis.com/moved"}, status: http.StatusMovedPermanently},
+		{name: "missing", asset: s3Asset("missing"), status: http.StatusNotFound, missing: true},
+		{name: "bad request", asset: s3Asset("bad"), status: http.StatusBadRequest},
+		{name: "server error", asset: s3Asset("broken"), status: http.StatusInternalServerError},
+		{name: "bad gateway", asset: s3Asset("gateway"), status: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newProviderServer(t, nil)
+			server.server.Config.Handler = respond(tt.status, "")
+			c := server.checker()
+			c.negativeTTL = time.Hour
+
+			found := c.Check(context.Background(), []CloudAsset{tt.asset})
+			if exists := len(found) == 1; exists != tt.exists {
+				t.Errorf("status %d: found %v, want exists %v", tt.status, found, tt.exists)
+			}
+			if missing := c.knownMissing(tt.asset.URL); missing != tt.missing {
+				t.Errorf("status %d: cached missing %v, want %v", tt.status, missing, tt.missing)
+			}
+		})
+	}
+}
+
+// s3Asset is an S3 bucket candidate named bucket
+func s3Asset(bucket string) CloudAsset {
+	return CloudAsset{Provider: "AWS", Type: "s3", Bucket: bucket, URL: "https://" + bucket + ".s3.amazonaws.com"}
+}
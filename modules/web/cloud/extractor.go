@@ -9,16 +9,24 @@ import (
 	"go.uber.org/zap"
 )
 
+// DefaultMaxCandidates caps generated permutations unless configured otherwise
+const DefaultMaxCandidates = 10000
+
 // Extractor identifies cloud storage buckets and services
 type Extractor struct {
 	logger *zap.Logger
 	
+	// Upper bound on generated permutation candidates
+	maxCandidates int
+	
 	// Patterns for different cloud providers
 	s3Pattern        *regexp.Regexp
 	gcsPattern       *regexp.Regexp
 	azurePattern     *regexp.Regexp
 	firebasePattern  *regexp.Regexp
 	digitalOceanPattern *regexp.Regexp
+	r2Pattern        *regexp.Regexp
+	backblazePattern *regexp.Regexp
 }
 
 // CloudAsset represents a discovered cloud asset
@@ -27,13 +35,20 @@ type CloudAsset struct {
 	Bucket   string
 	Region   string
 	URL      string
-	Type     string // s3, gcs, azure-blob, firebase, etc.
+	Type     string // s3, gcs, azure-blob, firebase, do-spaces, r2, b2
+	Exists   bool   // set by Checker
+	
+	// Set by Checker.Probe: Access is one of the Access* levels, and
+	// Accessible is true when the contents can be read without credentials
+	Access     string
+	Accessible bool
 }
 
 // NewExtractor creates a new cloud asset extractor
 func NewExtractor(logger *zap.Logger) *Extractor {
 	return &Extractor{
-		logger: logger,
+		logger:        logger,
+		maxCandidates: DefaultMaxCandidates,
 		
 		// AWS S3 patterns
 		s3Pattern: regexp.MustCompile(
@@ -47,9 +62,9 @@ func NewExtractor(logger *zap.Logger) *Extractor {
 			`(?i)(?:https?://)?storage\.googleapis\.com/([a-z0-9][a-z0-9._-]*?)`,
 		),
 		
-		// Azure Blob Storage patterns
+		// Azure Blob Storage patterns, with the container when the path names one
 		azurePattern: regexp.MustCompile(
-			`(?i)(?:https?://)?([a-z0-9][a-z0-9-]*?)\.blob\.core\.windows\.net`,
+			`(?i)(?:https?://)?([a-z0-9][a-z0-9-]*?)\.blob\.core\.windows\.net(?:/([a-z0-9][a-z0-9-]{1,62}))?`,
 		),
 		
 		// Firebase patterns
@@ -62,6 +77,20 @@ func NewExtractor(logger *zap.Logger) *Extractor {
 		digitalOceanPattern: regexp.MustCompile(
 			`(?i)(?:https?://)?([a-z0-9][a-z0-9.-]*?)\.([a-z0-9-]+)\.digitaloceanspaces\.com`,
 		),
+		
+		// Cloudflare R2 patterns (bucket.account or account/bucket; the
+		// account ID is 32 hex digits)
+		r2Pattern: regexp.MustCompile(
+			`(?i)(?:https?://)?(?:([a-z0-9][a-z0-9-]*?)\.)?([a-f0-9]{32})\.r2\.cloudflarestorage\.com(?:/([a-z0-9][a-z0-9-]*))?`,
+		),
+		
+		// Backblaze B2 patterns (S3-compatible endpoint, virtual-hosted or
+		// path style, and the fNNN download URLs)
+		backblazePattern: regexp.MustCompile(
+			`(?i)(?:https?://)?([a-z0-9][a-z0-9-]*?)\.s3\.([a-z]+-[a-z]+-\d{3})\.backblazeb2\.com|` +
+			`(?i)(?:https?://)?s3\.([a-z]+-[a-z]+-\d{3})\.backblazeb2\.com/([a-z0-9][a-z0-9-]*)|` +
+			`(?i)(?:https?://)?(f\d{3})\.backblazeb2\.com/file/([a-z0-9][a-z0-9-]*)`,
+		),
 	}
 }
 
@@ -120,6 +149,26 @@ func (e *Extractor) ExtractFromContent(ctx context.Context, content, targetDomai
 		}
 	}
 	
+	// Extract Cloudflare R2
+	r2Assets := e.extractR2(content, targetDomain)
+	for _, asset := range r2Assets {
+		key := fmt.Sprintf("%s:%s", asset.Type, asset.Bucket)
+		if !seen[key] {
+			assets = append(assets, asset)
+			seen[key] = true
+		}
+	}
+	
+	// Extract Backblaze B2
+	b2Assets := e.extractBackblaze(content, targetDomain)
+	for _, asset := range b2Assets {
+		key := fmt.Sprintf("%s:%s", asset.Type, asset.Bucket)
+		if !seen[key] {
+			assets = append(assets, asset)
+			seen[key] = true
+		}
+	}
+	
 	e.logger.Info("Cloud asset extraction complete",
 		zap.Int("assets_found", len(assets)),
 	)
@@ -195,15 +244,18 @@ func (e *Extractor) extractGCS(content, targetDomain string) []CloudAsset {
 	return assets
 }
 
-// extractAzure extracts Azure Blob Storage containers
+// extractAzure extracts Azure Blob Storage containers. Listing is granted
+// per container, so a reference naming one is kept as account/container;
+// a bare account can only be checked for existence.
 func (e *Extractor) extractAzure(content, targetDomain string) []CloudAsset {
 	var assets []CloudAsset
 	
 	matches := e.azurePattern.FindAllStringSubmatch(content, -1)
 	
 	for _, match := range matches {
-		if len(match) > 1 {
-			account := match[1]
+		if len(match) > 2 {
+			account := strings.ToLower(match[1])
+			container := strings.ToLower(match[2])
 			
 			if e.isRelevant(account, targetDomain) {
 				asset := CloudAsset{
@@ -212,6 +264,10 @@ func (e *Extractor) extractAzure(content, targetDomain string) []CloudAsset {
 					Type:     "azure-blob",
 					URL:      fmt.Sprintf("https://%s.blob.core.windows.net", account),
 				}
+				if container != "" {
+					asset.Bucket += "/" + container
+					asset.URL += "/" + container
+				}
 				
 				assets = append(assets, asset)
 			}
@@ -287,6 +343,76 @@ func (e *Extractor) extractDigitalOcean(content, targetDomain string) []CloudAss
 	return assets
 }
 
+// extractR2 extracts Cloudflare R2 buckets
+func (e *Extractor) extractR2(content, targetDomain string) []CloudAsset {
+	var assets []CloudAsset
+	
+	matches := e.r2Pattern.FindAllStringSubmatch(content, -1)
+	
+	for _, match := range matches {
+		account := strings.ToLower(match[2])
+		bucket := match[1]
+		if bucket == "" {
+			bucket = match[3]
+		}
+		
+		if bucket != "" && e.isRelevant(bucket, targetDomain) {
+			asset := CloudAsset{
+				Provider: "Cloudflare",
+				Bucket:   bucket,
+				Type:     "r2",
+				URL:      fmt.Sprintf("https://%s.r2.cloudflarestorage.com/%s", account, bucket),
+			}
+			
+			assets = append(assets, asset)
+		}
+	}
+	
+	return assets
+}
+
+// extractBackblaze extracts Backblaze B2 buckets
+func (e *Extractor) extractBackblaze(content, targetDomain string) []CloudAsset {
+	var assets []CloudAsset
+	
+	matches := e.backblazePattern.FindAllStringSubmatch(content, -1)
+	
+	for _, match := range matches {
+		var bucket, region, url string
+		
+		// Parse different B2 URL formats
+		if match[1] != "" {
+			bucket = match[1]
+			region = match[2]
+		} else if match[4] != "" {
+			bucket = match[4]
+			region = match[3]
+		} else if match[6] != "" {
+			// Download URLs name the cluster, not the region
+			bucket = match[6]
+			url = fmt.Sprintf("https://%s.backblazeb2.com/file/%s", strings.ToLower(match[5]), bucket)
+		}
+		
+		if bucket != "" && e.isRelevant(bucket, targetDomain) {
+			asset := CloudAsset{
+				Provider: "Backblaze",
+				Bucket:   bucket,
+				Region:   strings.ToLower(region),
+				Type:     "b2",
+				URL:      url,
+			}
+			
+			if url == "" {
+				asset.URL = fmt.Sprintf("https://%s.s3.%s.backblazeb2.com", bucket, asset.Region)
+			}
+			
+			assets = append(assets, asset)
+		}
+	}
+	
+	return assets
+}
+
 // isRelevant checks if a bucket name is relevant to the target domain
 func (e *Extractor) isRelevant(bucket, targetDomain string) bool {
 	if bucket == "" || targetDomain == "" {
@@ -325,7 +451,13 @@ func (e *Extractor) isRelevant(bucket, targetDomain string) bool {
 	return false
 }
 
-// GeneratePermutations creates common bucket name permutations
+// SetMaxCandidates caps the number of permutations generated (0 = no cap)
+func (e *Extractor) SetMaxCandidates(max int) {
+	e.maxCandidates = max
+}
+
+// GeneratePermutations creates common bucket name permutations, without
+// duplicates and truncated to the configured maximum
 func (e *Extractor) GeneratePermutations(domain string) []string {
 	parts := strings.Split(domain, ".")
 	if len(parts) == 0 {
@@ -334,26 +466,65 @@ func (e *Extractor) GeneratePermutations(domain string) []string {
 	
 	baseName := parts[0]
 	var permutations []string
+	seen := make(map[string]bool)
+	generated := 0
 	
 	// Common prefixes/suffixes
 	prefixes := []string{"", "prod-", "dev-", "staging-", "test-", "backup-", "static-", "assets-"}
 	suffixes := []string{"", "-prod", "-dev", "-staging", "-test", "-backup", "-static", "-assets", "-data", "-files"}
 	
-	for _, prefix := range prefixes {
-		for _, suffix := range suffixes {
-			permutations = append(permutations, prefix+baseName+suffix)
-		}
-	}
+	// Base name as-is, then without hyphens; both loops emit the same
+	// names when the base has no hyphen, so everything goes through seen
+	bases := []string{baseName, strings.ReplaceAll(baseName, "-", "")}
 	
-	// Without hyphens
-	baseNameNoHyphen := strings.ReplaceAll(baseName, "-", "")
-	for _, prefix := range prefixes {
-		for _, suffix := range suffixes {
-			if prefix != "" || suffix != "" {
-				permutations = append(permutations, prefix+baseNameNoHyphen+suffix)
+	for _, base := range bases {
+		for _, prefix := range prefixes {
+			for _, suffix := range suffixes {
+				candidate := prefix + base + suffix
+				if seen[candidate] {
+					continue
+				}
+				seen[candidate] = true
+				generated++
+				
+				if e.maxCandidates <= 0 || len(permutations) < e.maxCandidates {
+					permutations = append(permutations, candidate)
+				}
 			}
 		}
 	}
 	
+	if generated > len(permutations) {
+		e.logger.Warn("Permutation candidates truncated",
+			zap.String("domain", domain),
+			zap.Int("generated", generated),
+			zap.Int("max_candidates", e.maxCandidates),
+		)
+	}
+	
 	return permutations
+}
+
+// CandidateAssets turns guessed bucket names into S3 and GCS assets to
+// check. Only globally named stores are guessed: Azure, R2 and B2 need an
+// account name as well.
+func (e *Extractor) CandidateAssets(names []string) []CloudAsset {
+	assets := make([]CloudAsset, 0, 2*len(names))
+	for _, name := range names {
+		assets = append(assets,
+			CloudAsset{
+				Provider: "AWS",
+				Bucket:   name,
+				Type:     "s3",
+				URL:      fmt.Sprintf("https://%s.s3.amazonaws.com", name),
+			},
+			CloudAsset{
+				Provider: "Google Cloud",
+				Bucket:   name,
+				Type:     "gcs",
+				URL:      fmt.Sprintf("https://storage.googleapis.com/%s", name),
+			},
+		)
+	}
+	return assets
 }
\ No newline at end of file
@@ -0,0 +1,168 @@
+package cloud
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGeneratePermutationsUniqueAndCapped(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		max    int
+		want   int
+	}{
+		// 8 prefixes x 10 suffixes; without hyphens the base repeats itself
+		{"no cap", "example.com", 0, 80},
+		{"hyphenated base adds its unhyphenated form", "my-site.com", 0, 160},
+		{"capped", "example.com", 25, 25},
+		{"cap above total", "example.com", 1000, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExtractor(zap.NewNop())
+			e.SetMaxCandidates(tt.max)
+
+			permutations := e.GeneratePermutations(tt.domain)
+			if len(permutations) != tt.want {
+				t.Errorf("got %d permutations, want %d", len(permutations), tt.want)
+			}
+
+			seen := make(map[string]bool)
+			for _, name := range permutations {
+				if seen[name] {
+					t.Errorf("duplicate permutation %q", name)
+				}
+				seen[name] = true
+			}
+		})
+	}
+}
+
+func TestGeneratePermutationsKeepsBaseFirst(t *testing.T) {
+	e := NewExtractor(zap.NewNop())
+	e.SetMaxCandidates(3)
+
+	got := e.GeneratePermutations("example.com")
+	want := []string{"example", "example-prod", "example-dev"}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("permutations = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCandidateAssets(t *testing.T) {
+	e := NewExtractor(zap.NewNop())
+
+	assets := e.CandidateAssets([]string{"example-backup"})
+	want := map[string]string{
+		"s3":  "https://example-backup.s3.amazonaws.com",
+		"gcs": "https://storage.googleapis.com/example-backup",
+	}
+	if len(assets) != len(want) {
+		t.Fatalf("got %d assets, want %d: %+v", len(assets), len(want), assets)
+	}
+	for _, asset := range assets {
+		if asset.Bucket != "example-backup" || asset.URL != want[asset.Type] {
+			t.Errorf("asset %+v, want bucket example-backup at %s", asset, want[asset.Type])
+		}
+	}
+}
+
+func TestExtractR2(t *testing.T) {
+	const account = "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name    string
+		content string
+		want    []CloudAsset
+	}{
+		{
+			name:    "virtual-hosted",
+			content: `<img src="https://acme-media.` + account + `.r2.cloudflarestorage.com/img/logo.png">`,
+			want:    []CloudAsset{{Provider: "Cloudflare", Type: "r2", Bucket: "acme-media", URL: "https://" + account + ".r2.cloudflarestorage.com/acme-media"}},
+		},
+		{
+			name:    "path-style",
+			content: `fetch("https://` + account + `.r2.cloudflarestorage.com/acme-backups/db.sql.gz")`,
+			want:    []CloudAsset{{Provider: "Cloudflare", Type: "r2", Bucket: "acme-backups", URL: "https://" + account + ".r2.cloudflarestorage.com/acme-backups"}},
+		},
+		{
+			name:    "account lowercased",
+			content: `//0123456789ABCDEF0123456789ABCDEF.r2.cloudflarestorage.com/acme-static`,
+			want:    []CloudAsset{{Provider: "Cloudflare", Type: "r2", Bucket: "acme-static", URL: "https://" + account + ".r2.cloudflarestorage.com/acme-static"}},
+		},
+		{
+			name:    "bare account endpoint",
+			content: `endpoint: "https://` + account + `.r2.cloudflarestorage.com"`,
+		},
+		{
+			name:    "unrelated bucket",
+			content: `https://` + account + `.r2.cloudflarestorage.com/othercorp-files/a.txt`,
+		},
+		{
+			name:    "account id too short",
+			content: `https://acme-media.0123456789abcdef.r2.cloudflarestorage.com/`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := NewExtractor(zap.NewNop()).ExtractFromContent(context.Background(), tt.content, "acme.com")
+			if !reflect.DeepEqual(assets, tt.want) {
+				t.Errorf("got %+v, want %+v", assets, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractBackblaze(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []CloudAsset
+	}{
+		{
+			name:    "virtual-hosted",
+			content: `<img src="https://acme-assets.s3.us-west-004.backblazeb2.com/logo.png">`,
+			want:    []CloudAsset{{Provider: "Backblaze", Type: "b2", Bucket: "acme-assets", Region: "us-west-004", URL: "https://acme-assets.s3.us-west-004.backblazeb2.com"}},
+		},
+		{
+			name:    "path-style",
+			content: `const logs = "https://s3.eu-central-003.backblazeb2.com/acme-logs/2024/01.log";`,
+			want:    []CloudAsset{{Provider: "Backblaze", Type: "b2", Bucket: "acme-logs", Region: "eu-central-003", URL: "https://acme-logs.s3.eu-central-003.backblazeb2.com"}},
+		},
+		{
+			name:    "download url",
+			content: `<a href="https://F002.backblazeb2.com/file/acme-downloads/setup.exe">`,
+			want:    []CloudAsset{{Provider: "Backblaze", Type: "b2", Bucket: "acme-downloads", URL: "https://f002.backblazeb2.com/file/acme-downloads"}},
+		},
+		{
+			name:    "region lowercased",
+			content: `https://acme-assets.s3.US-WEST-002.backblazeb2.com/`,
+			want:    []CloudAsset{{Provider: "Backblaze", Type: "b2", Bucket: "acme-assets", Region: "us-west-002", URL: "https://acme-assets.s3.us-west-002.backblazeb2.com"}},
+		},
+		{
+			name:    "unrelated bucket",
+			content: `https://f004.backblazeb2.com/file/othercorp-files/a.zip https://othercorp.s3.us-west-004.backblazeb2.com/`,
+		},
+		{
+			name:    "api endpoint without a bucket",
+			content: `https://api.backblazeb2.com/b2api/v2/b2_authorize_account`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := NewExtractor(zap.NewNop()).ExtractFromContent(context.Background(), tt.content, "acme.com")
+			if !reflect.DeepEqual(assets, tt.want) {
+				t.Errorf("got %+v, want %+v", assets, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/usr/internal/pool"
+	"go.uber.org/zap"
+)
+
+// Access levels set by Probe
+const (
+	AccessPublicList = "public_list" // contents can be listed without credentials
+	AccessPublic     = "public"      // answers without credentials, e.g. a readable Firebase database
+	AccessDenied     = "denied"      // exists, credentials required
+	AccessNotFound   = "not_found"
+	AccessUnknown    = "unknown" // no conclusive answer
+)
+
+// maxProbeBody is how much of a probe response is read for classification
+const maxProbeBody = 64 * 1024
+
+// Probe requests each asset's listing (S3-compatible list-objects, GCS and
+// Azure container listings, Firebase's REST root) and records what an
+// anonymous client may do in Access, Accessible and Exists. Every asset is
+// returned, missing ones with AccessNotFound; the input is not modified.
+func (c *Checker) Probe(ctx context.Context, assets []CloudAsset) []CloudAsset {
+	if len(assets) == 0 {
+		return nil
+	}
+
+	c.logger.Info("Probing cloud buckets",
+		zap.Int("assets", len(assets)),
+		zap.Int("workers", c.workers),
+	)
+
+	probed := make([]CloudAsset, len(assets))
+	copy(probed, assets)
+	indexes := make([]int, len(probed))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	var (
+		mu     sync.Mutex
+		counts = make(map[string]int)
+	)
+
+	err := pool.Run(ctx, indexes, c.workers, func(ctx context.Context, i int) {
+		asset := &probed[i]
+
+		access, result := AccessNotFound, resultMissing
+		if !c.knownMissing(asset.URL) {
+			access, result = c.probeWithBackoff(ctx, asset)
+		}
+		if result == resultMissing {
+			c.markMissing(asset.URL)
+		}
+
+		asset.Access = access
+		asset.Exists = result == resultExists
+		asset.Accessible = access == AccessPublicList || access == AccessPublic
+
+		mu.Lock()
+		counts[access]++
+		mu.Unlock()
+	})
+	if err != nil {
+		c.logger.Error("Cloud bucket probe panicked", zap.Error(err))
+	}
+
+	if err := c.saveNegative(); err != nil {
+		c.logger.Warn("Failed to save cloud negative cache", zap.Error(err))
+	}
+
+	c.logger.Info("Cloud bucket probe complete",
+		zap.Int("public_list", counts[AccessPublicList]),
+		zap.Int("public", counts[AccessPublic]),
+		zap.Int("denied", counts[AccessDenied]),
+		zap.Int("not_found", counts[AccessNotFound]),
+	)
+
+	return probed
+}
+
+// probeWithBackoff probes an asset, waiting out and retrying on throttling
+func (c *Checker) probeWithBackoff(ctx context.Context, asset *CloudAsset) (string, checkResult) {
+	access := AccessUnknown
+	result := c.withBackoff(ctx, asset.Provider, func() checkResult {
+		var result checkResult
+		access, result = c.probeOnce(ctx, asset, true)
+		return result
+	})
+	return access, result
+}
+
+// probeOnce requests an asset's listing and classifies the answer. An S3
+// bucket in another region is retried once at its regional endpoint, which
+// is recorded in the asset.
+func (c *Checker) probeOnce(ctx context.Context, asset *CloudAsset, followRegion bool) (string, checkResult) {
+	req, err := http.NewRequestWithContext(ctx, "GET", probeURL(asset), nil)
+	if err != nil {
+		return AccessUnknown, resultUnknown
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return AccessNotFound, resultMissing
+		}
+		return AccessUnknown, resultUnknown
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+
+	// S3 redirects requests for a bucket to the region holding it
+	if resp.StatusCode == http.StatusMovedPermanently || resp.StatusCode == http.StatusTemporaryRedirect {
+		region := resp.Header.Get("X-Amz-Bucket-Region")
+		if asset.Type == "s3" && followRegion && region != "" && region != asset.Region {
+			asset.Region = region
+			asset.URL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", asset.Bucket, region)
+			return c.probeOnce(ctx, asset, false)
+		}
+	}
+
+	return classifyAccess(asset.Type, resp.StatusCode, string(body))
+}
+
+// probeURL is the request that shows whether an asset is open: a listing
+// limited to one entry where the provider has one
+func probeURL(asset *CloudAsset) string {
+	base := strings.TrimSuffix(asset.URL, "/")
+
+	switch asset.Type {
+	case "s3", "do-spaces", "r2":
+		return base + "/?list-type=2&max-keys=1"
+	case "b2":
+		// Download URLs have no listing; the S3-compatible endpoint does
+		if strings.Contains(base, ".s3.") {
+			return base + "/?list-type=2&max-keys=1"
+		}
+	case "gcs":
+		return base + "?max-keys=1"
+	case "azure-blob":
+		// Anonymous listing is a container permission; an account alone
+		// can't be listed
+		if u, err := url.Parse(base); err == nil && strings.Trim(u.Path, "/") != "" {
+			return base + "?restype=container&comp=list&maxresults=1"
+		}
+	case "firebase":
+		if strings.Contains(base, ".firebaseio.com") {
+			return base + "/.json?shallow=true"
+		}
+	}
+	return base
+}
+
+// classifyAccess interprets a probe response for an asset of assetType
+func classifyAccess(assetType string, status int, body string) (string, checkResult) {
+	switch {
+	case status == http.StatusOK:
+		if strings.Contains(body, "<ListBucketResult") || strings.Contains(body, "<EnumerationResults") {
+			return AccessPublicList, resultExists
+		}
+		return AccessPublic, resultExists
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return AccessDenied, resultExists
+	case status == http.StatusNotFound:
+		return AccessNotFound, resultMissing
+	case status == http.StatusServiceUnavailable || status == http.StatusTooManyRequests:
+		return AccessUnknown, resultThrottled
+	case existsStatus(assetType, status):
+		// An S3 redirect to a region the probe didn't follow
+		return AccessUnknown, resultExists
+	default:
+		return AccessUnknown, resultUnknown
+	}
+}
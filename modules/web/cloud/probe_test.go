@@ -0,0 +1,203 @@
+package cloud
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"go.uber.org/zap"
+)
+
+// providerServer plays every storage provider: each request is answered by
+// the handler registered for its host and path, 404 otherwise
+type providerServer struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests map[string]string // host+path -> raw query of the last request
+}
+
+// newProviderServer starts a TLS server answering with handlers, keyed by
+// host+path (e.g. "acme.blob.core.windows.net/backups")
+func newProviderServer(t *testing.T, handlers map[string]http.HandlerFunc) *providerServer {
+	t.Helper()
+
+	p := &providerServer{requests: make(map[string]string)}
+	p.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Host + r.URL.Path
+		p.mu.Lock()
+		p.requests[key] = r.URL.RawQuery
+		p.mu.Unlock()
+
+		if handler, ok := handlers[key]; ok {
+			handler(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// query returns the query string last sent to host+path
+func (p *providerServer) query(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	query, ok := p.requests[key]
+	return query, ok
+}
+
+// checker returns a Checker whose requests, whatever their host, reach
+// the server
+func (p *providerServer) checker() *Checker {
+	c := NewChecker(&config.CloudConfig{Workers: 2, Timeout: 5}, "", zap.NewNop())
+	addr := p.server.Listener.Addr().String()
+	c.client.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	return c
+}
+
+// respond answers with status and body
+func respond(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+func TestProbeByProvider(t *testing.T) {
+	server := newProviderServer(t, map[string]http.HandlerFunc{
+		"example-assets.s3.amazonaws.com/":    respond(http.StatusOK, `<?xml version="1.0"?><ListBucketResult><Name>example-assets</Name></ListBucketResult>`),
+		"storage.googleapis.com/example-logs": respond(http.StatusForbidden, `<Error><Code>AccessDenied</Code></Error>`),
+		"acme.blob.core.windows.net/backups":  respond(http.StatusOK, `<?xml version="1.0"?><EnumerationResults ContainerName="backups"></EnumerationResults>`),
+		"acme.blob.core.windows.net/private":  respond(http.StatusNotFound, `<Error><Code>ResourceNotFound</Code></Error>`),
+		"example-app.firebaseio.com/.json":    respond(http.StatusOK, `{"users":true}`),
+	})
+
+	tests := []struct {
+		name   string
+		asset  CloudAsset
+		key    string // host+path the probe must request
+		query  string
+		access string
+		exists bool
+	}{
+		{
+			name:   "s3 listing",
+			asset:  CloudAsset{Provider: "AWS", Type: "s3", Bucket: "example-assets", URL: "https://example-assets.s3.amazonaws.com"},
+			key:    "example-assets.s3.amazonaws.com/",
+			query:  "list-type=2&max-keys=1",
+			access: AccessPublicList,
+			exists: true,
+		},
+		{
+			name:   "gcs denied",
+			asset:  CloudAsset{Provider: "Google Cloud", Type: "gcs", Bucket: "example-logs", URL: "https://storage.googleapis.com/example-logs"},
+			key:    "storage.googleapis.com/example-logs",
+			query:  "max-keys=1",
+			access: AccessDenied,
+			exists: true,
+		},
+		{
+			name:   "azure container listing",
+			asset:  CloudAsset{Provider: "Azure", Type: "azure-blob", Bucket: "acme/backups", URL: "https://acme.blob.core.windows.net/backups"},
+			key:    "acme.blob.core.windows.net/backups",
+			query:  "restype=container&comp=list&maxresults=1",
+			access: AccessPublicList,
+			exists: true,
+		},
+		{
+			name:   "azure private container",
+			asset:  CloudAsset{Provider: "Azure", Type: "azure-blob", Bucket: "acme/private", URL: "https://acme.blob.core.windows.net/private"},
+			key:    "acme.blob.core.windows.net/private",
+			query:  "restype=container&comp=list&maxresults=1",
+			access: AccessNotFound,
+		},
+		{
+			name:   "firebase database",
+			asset:  CloudAsset{Provider: "Firebase", Type: "firebase", Bucket: "example-app", URL: "https://example-app.firebaseio.com"},
+			key:    "example-app.firebaseio.com/.json",
+			query:  "shallow=true",
+			access: AccessPublic,
+			exists: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probed := server.checker().Probe(context.Background(), []CloudAsset{tt.asset})
+			if len(probed) != 1 {
+				t.Fatalf("got %d assets, want 1", len(probed))
+			}
+
+			got := probed[0]
+			if got.Access != tt.access || got.Exists != tt.exists {
+				t.Errorf("access %q exists %v, want %q %v", got.Access, got.Exists, tt.access, tt.exists)
+			}
+			if query, ok := server.query(tt.key); !ok || query != tt.query {
+				t.Errorf("request to %s with query %q (sent %v), want %q", tt.key, query, ok, tt.query)
+			}
+		})
+	}
+}
+
+func TestProbeAzureAccountIsNotListed(t *testing.T) {
+	server := newProviderServer(t, map[string]http.HandlerFunc{
+		"acme.blob.core.windows.net/": respond(http.StatusBadRequest, `<Error><Code>InvalidQueryParameterValue</Code></Error>`),
+	})
+
+	asset := CloudAsset{Provider: "Azure", Type: "azure-blob", Bucket: "acme", URL: "https://acme.blob.core.windows.net"}
+	server.checker().Probe(context.Background(), []CloudAsset{asset})
+
+	if query, ok := server.query("acme.blob.core.windows.net/"); ok && query != "" {
+		t.Errorf("bare account probed with %q; only containers can be listed", query)
+	}
+}
+
+func TestProbeFollowsS3Region(t *testing.T) {
+	server := newProviderServer(t, map[string]http.HandlerFunc{
+		"example-eu.s3.amazonaws.com/": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Amz-Bucket-Region", "eu-west-1")
+			w.WriteHeader(http.StatusMovedPermanently)
+		},
+		"example-eu.s3.eu-west-1.amazonaws.com/": respond(http.StatusForbidden, `<Error><Code>AccessDenied</Code></Error>`),
+	})
+
+	asset := CloudAsset{Provider: "AWS", Type: "s3", Bucket: "example-eu", URL: "https://example-eu.s3.amazonaws.com"}
+	probed := server.checker().Probe(context.Background(), []CloudAsset{asset})
+
+	got := probed[0]
+	if got.Access != AccessDenied || got.Region != "eu-west-1" || got.URL != "https://example-eu.s3.eu-west-1.amazonaws.com" {
+		t.Errorf("got %+v, want denied at the eu-west-1 endpoint", got)
+	}
+}
+
+func TestExtractAzureContainer(t *testing.T) {
+	e := NewExtractor(zap.NewNop())
+
+	content := `<img src="https://acme.blob.core.windows.net/backups/db.sql.gz">
+		<a href="https://ACME.blob.core.windows.net">`
+	assets := e.ExtractFromContent(context.Background(), content, "acme.com")
+
+	want := map[string]string{
+		"acme/backups": "https://acme.blob.core.windows.net/backups",
+		"acme":         "https://acme.blob.core.windows.net",
+	}
+	if len(assets) != len(want) {
+		t.Fatalf("got %+v, want %d assets", assets, len(want))
+	}
+	for _, asset := range assets {
+		if want[asset.Bucket] != asset.URL {
+			t.Errorf("asset %s at %s, want %q", asset.Bucket, asset.URL, want[asset.Bucket])
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/modules/web/webfetch"
+	"go.uber.org/zap"
+)
+
+// maxPageSize is how much of each page or script is read for links
+const maxPageSize = 2 * 1024 * 1024
+
+var (
+	// Attribute references in HTML: links, scripts, frames, forms
+	attrPattern = regexp.MustCompile(`(?i)\b(?:href|src|action)\s*=\s*["']([^"'<>\s]+)["']`)
+
+	// Absolute and protocol-relative URLs anywhere, e.g. in scripts
+	urlPattern = regexp.MustCompile(`(?i)(?:https?:)?//[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)+(?::\d+)?(?:/[^\s"'\x60<>()\\]*)?`)
+)
+
+// Result is what crawling from one host found
+type Result struct {
+	Hosts []string // in-scope hostnames linked to, in the order seen
+	Pages int      // pages and scripts fetched
+}
+
+// Crawler follows same-domain links breadth-first from a host, bounded by
+// depth and page count
+type Crawler struct {
+	client   *http.Client
+	logger   *zap.Logger
+	prepare  func(*http.Request)
+	maxDepth int
+	maxPages int
+	robots   bool
+}
+
+// NewCrawler creates a crawler bounded by the configured crawl depth and
+// page limit. Pages are requested with webfetch.Get through client and
+// prepare.
+func NewCrawler(client *http.Client, cfg *config.WebSourcesConfig, prepare func(*http.Request), logger *zap.Logger) *Crawler {
+	maxPages := cfg.CrawlMaxPages
+	if maxPages <= 0 {
+		maxPages = 1
+	}
+
+	return &Crawler{
+		client:   client,
+		logger:   logger,
+		prepare:  prepare,
+		maxDepth: cfg.CrawlDepth,
+		maxPages: maxPages,
+		robots:   cfg.CrawlRobots,
+	}
+}
+
+// queued is a URL waiting to be fetched and how many links led to it
+type queued struct {
+	url   string
+	depth int
+}
+
+// Crawl fetches startURL and follows the links it finds to pages on hosts
+// inScope accepts, up to the crawler's depth (the start page is depth 0)
+// and page limits. Each URL is fetched once. It returns the hosts linked
+// to, with ctx.Err() when interrupted.
+func (c *Crawler) Crawl(ctx context.Context, startURL string, inScope func(host string) bool) (*Result, error) {
+	result := &Result{}
+
+	start, err := neturl.Parse(startURL)
+	if err != nil {
+		return result, err
+	}
+
+	seenHosts := make(map[string]bool)
+	visited := map[string]bool{normalize(start): true}
+	disallowed := make(map[string][]string) // robots.txt rules by origin
+	queue := []queued{{url: start.String()}}
+
+	for len(queue) > 0 && result.Pages < c.maxPages {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		next := queue[0]
+		queue = queue[1:]
+
+		page, err := neturl.Parse(next.url)
+		if err != nil {
+			continue
+		}
+		if c.robots && !c.allowed(ctx, page, disallowed) {
+			c.logger.Debug("Disallowed by robots.txt", zap.String("url", next.url))
+			continue
+		}
+
+		body, err := webfetch.Get(ctx, c.client, c.prepare, next.url, maxPageSize)
+		result.Pages++
+		if err != nil {
+			c.logger.Debug("Crawl fetch failed", zap.String("url", next.url), zap.Error(err))
+			continue
+		}
+
+		for _, link := range extractLinks(body, page) {
+			host := strings.ToLower(link.Hostname())
+			if host == "" || !inScope(host) {
+				continue
+			}
+			if !seenHosts[host] {
+				seenHosts[host] = true
+				result.Hosts = append(result.Hosts, host)
+			}
+
+			key := normalize(link)
+			if next.depth >= c.maxDepth || visited[key] || !crawlable(link) {
+				continue
+			}
+			visited[key] = true
+			queue = append(queue, queued{url: link.String(), depth: next.depth + 1})
+		}
+	}
+
+	return result, ctx.Err()
+}
+
+// allowed checks a URL against its origin's robots.txt, fetched once per
+// origin; a missing, failed or unreadable file allows everything
+func (c *Crawler) allowed(ctx context.Context, page *neturl.URL, cache map[string][]string) bool {
+	origin := page.Scheme + "://" + page.Host
+	rules, ok := cache[origin]
+	if !ok {
+		if body, err := webfetch.Get(ctx, c.client, c.prepare, origin+"/robots.txt", maxPageSize); err == nil {
+			rules = webfetch.ParseRobots(body).Disallowed()
+		}
+		cache[origin] = rules
+	}
+
+	path := page.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	for _, prefix := range rules {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// extractLinks returns the HTTP(S) URLs a page or script references,
+// resolved against its URL
+func extractLinks(body string, base *neturl.URL) []*neturl.URL {
+	var refs []string
+	for _, match := range attrPattern.FindAllStringSubmatch(body, -1) {
+		refs = append(refs, match[1])
+	}
+	refs = append(refs, urlPattern.FindAllString(body, -1)...)
+
+	var links []*neturl.URL
+	for _, ref := range refs {
+		parsed, err := neturl.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			continue
+		}
+		link := base.ResolveReference(parsed)
+		if link.Scheme != "http" && link.Scheme != "https" {
+			continue
+		}
+		link.Fragment = ""
+		links = append(links, link)
+	}
+	return links
+}
+
+// crawlable reports whether a link may lead to more links: pages and
+// scripts rather than images, styles, fonts or downloads
+func crawlable(link *neturl.URL) bool {
+	path := strings.ToLower(link.Path)
+	for _, ext := range []string{
+		".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico", ".webp",
+		".css", ".woff", ".woff2", ".ttf", ".eot",
+		".pdf", ".zip", ".gz", ".mp4", ".mp3", ".webm",
+	} {
+		if strings.HasSuffix(path, ext) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize returns the key a URL is visited under: lowercase scheme and
+// host, no fragment, "/" for an empty path
+func normalize(u *neturl.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	key := strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + path
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
@@ -0,0 +1,201 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/modules/web/webtest"
+	"go.uber.org/zap"
+)
+
+// linkGraph is a small site spread over several example.com hosts
+var linkGraph = map[string]string{
+	"www.example.com/": `<a href="/a">A</a><a href="/b#top">B</a>
+		<a href="http://shop.example.com/">Shop</a>
+		<script src="//cdn.example.net/lib.js"></script>
+		<img src="/img/logo.png">`,
+	"www.example.com/a":             `<a href="/">Home</a><a href="/a/deep">Deep</a><script src="/static/app.js"></script>`,
+	"www.example.com/a/deep":        `<a href="http://deep.example.com/">Deeper</a>`,
+	"www.example.com/b":             `<p>No links</p>`,
+	"www.example.com/static/app.js": `fetch("http://api.example.com/v1/users")`,
+	"shop.example.com/":             `<a href="http://blog.example.com/">Blog</a>`,
+	"blog.example.com/":             `<a href="http://far.example.com/">Far</a>`,
+	"deep.example.com/":             `<a href="http://deeper.example.com/">Deeper</a>`,
+	"api.example.com/v1/users":      `{}`,
+	"far.example.com/":              `<a href="http://farther.example.com/">Farther</a>`,
+}
+
+// crawler returns a crawler whose requests reach server
+func crawler(server *webtest.Server, cfg config.WebSourcesConfig) *Crawler {
+	return NewCrawler(server.Client(), &cfg, nil, zap.NewNop())
+}
+
+func TestCrawlFollowsSameDomainLinks(t *testing.T) {
+	server := webtest.NewServer(t, linkGraph)
+
+	result, err := crawler(server, config.WebSourcesConfig{CrawlDepth: 2, CrawlMaxPages: 50}).
+		Crawl(context.Background(), "http://www.example.com/", webtest.InExampleScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hosts are reported as soon as they're linked, even past the depth
+	hosts := append([]string(nil), result.Hosts...)
+	sort.Strings(hosts)
+	wantHosts := []string{"api.example.com", "blog.example.com", "deep.example.com", "far.example.com", "shop.example.com", "www.example.com"}
+	if !reflect.DeepEqual(hosts, wantHosts) {
+		t.Errorf("hosts = %v, want %v", hosts, wantHosts)
+	}
+
+	// Links two deep are fetched; images, other domains and links found
+	// on those pages aren't
+	wantFetched := []string{
+		"blog.example.com/",
+		"shop.example.com/",
+		"www.example.com/",
+		"www.example.com/a",
+		"www.example.com/a/deep",
+		"www.example.com/b",
+		"www.example.com/static/app.js",
+	}
+	if got := server.Requested(); !reflect.DeepEqual(got, wantFetched) {
+		t.Errorf("fetched %v, want %v", got, wantFetched)
+	}
+	if n := server.Count("www.example.com/"); n != 1 {
+		t.Errorf("start page fetched %d times, want once", n)
+	}
+	if result.Pages != len(wantFetched) {
+		t.Errorf("Pages = %d, want %d", result.Pages, len(wantFetched))
+	}
+}
+
+func TestCrawlDepth(t *testing.T) {
+	tests := []struct {
+		depth   int
+		fetched []string
+		hosts   []string
+	}{
+		{
+			depth:   0,
+			fetched: []string{"www.example.com/"},
+			hosts:   []string{"shop.example.com", "www.example.com"},
+		},
+		{
+			depth:   1,
+			fetched: []string{"shop.example.com/", "www.example.com/", "www.example.com/a", "www.example.com/b"},
+			hosts:   []string{"blog.example.com", "shop.example.com", "www.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		server := webtest.NewServer(t, linkGraph)
+
+		result, err := crawler(server, config.WebSourcesConfig{CrawlDepth: tt.depth, CrawlMaxPages: 50}).
+			Crawl(context.Background(), "http://www.example.com/", webtest.InExampleScope)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := server.Requested(); !reflect.DeepEqual(got, tt.fetched) {
+			t.Errorf("depth %d: fetched %v, want %v", tt.depth, got, tt.fetched)
+		}
+		hosts := append([]string(nil), result.Hosts...)
+		sort.Strings(hosts)
+		if !reflect.DeepEqual(hosts, tt.hosts) {
+			t.Errorf("depth %d: hosts = %v, want %v", tt.depth, hosts, tt.hosts)
+		}
+	}
+}
+
+func TestCrawlMaxPages(t *testing.T) {
+	server := webtest.NewServer(t, linkGraph)
+
+	result, err := crawler(server, config.WebSourcesConfig{CrawlDepth: 5, CrawlMaxPages: 3}).
+		Crawl(context.Background(), "http://www.example.com/", webtest.InExampleScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Breadth-first: the start page, then its first two links
+	want := []string{"www.example.com/", "www.example.com/a", "www.example.com/b"}
+	if got := server.Requested(); !reflect.DeepEqual(got, want) {
+		t.Errorf("fetched %v, want %v", got, want)
+	}
+	if result.Pages != 3 {
+		t.Errorf("Pages = %d, want 3", result.Pages)
+	}
+}
+
+func TestCrawlObeysRobots(t *testing.T) {
+	pages := map[string]string{
+		"www.example.com/robots.txt": "User-agent: *\nDisallow: /a\n",
+	}
+	for key, page := range linkGraph {
+		pages[key] = page
+	}
+
+	for _, robots := range []bool{true, false} {
+		server := webtest.NewServer(t, pages)
+
+		_, err := crawler(server, config.WebSourcesConfig{CrawlDepth: 1, CrawlMaxPages: 50, CrawlRobots: robots}).
+			Crawl(context.Background(), "http://www.example.com/", webtest.InExampleScope)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := server.Count("www.example.com/a"); (got == 0) != robots {
+			t.Errorf("robots %v: /a fetched %d times", robots, got)
+		}
+		if server.Count("www.example.com/b") != 1 {
+			t.Errorf("robots %v: allowed page /b not fetched", robots)
+		}
+		if got := server.Count("www.example.com/robots.txt"); robots && got != 1 {
+			t.Errorf("robots.txt fetched %d times, want once per origin", got)
+		}
+	}
+}
+
+func TestCrawlStopsOnCancel(t *testing.T) {
+	server := webtest.NewServer(t, linkGraph)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := crawler(server, config.WebSourcesConfig{CrawlDepth: 2, CrawlMaxPages: 50}).
+		Crawl(ctx, "http://www.example.com/", webtest.InExampleScope)
+	if err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if result.Pages != 0 || len(server.Requested()) != 0 {
+		t.Errorf("fetched %v after cancel", server.Requested())
+	}
+}
+
+func TestCrawlSkipsErrorPages(t *testing.T) {
+	server := webtest.NewServer(t, map[string]string{
+		"www.example.com/":   `<a href="/down">Down</a><a href="/up">Up</a>`,
+		"www.example.com/up": `<a href="http://live.example.com/">Live</a>`,
+	})
+	// A 404 page that reads like robots.txt, and a 500 page with links
+	server.SetPage("www.example.com/robots.txt", webtest.Page{Status: http.StatusNotFound, Body: "User-agent: *\nDisallow: /up\n"})
+	server.SetPage("www.example.com/down", webtest.Page{Status: http.StatusInternalServerError, Body: `<a href="http://error.example.com/">Status</a>`})
+
+	result, err := crawler(server, config.WebSourcesConfig{CrawlDepth: 1, CrawlMaxPages: 50, CrawlRobots: true}).
+		Crawl(context.Background(), "http://www.example.com/", webtest.InExampleScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server.Count("www.example.com/up") != 1 {
+		t.Error("page disallowed by a robots.txt error page")
+	}
+	hosts := append([]string(nil), result.Hosts...)
+	sort.Strings(hosts)
+	if want := []string{"live.example.com", "www.example.com"}; !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts = %v, want %v (none from the error page)", hosts, want)
+	}
+}
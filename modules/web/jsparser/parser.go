@@ -6,18 +6,49 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/yourusername/usr/internal/pool"
 	"go.uber.org/zap"
 )
 
+// maxJSFileSize is how much of a single JS file is read
+const maxJSFileSize = 5 * 1024 * 1024
+
+// maxEndpoints is how many endpoints are kept per JS file
+const maxEndpoints = 100
+
+// Endpoint is a path referenced by a JS file, resolved against the file's
+// URL: root-relative paths belong to the file's origin, absolute and
+// protocol-relative references to the host they name
+type Endpoint struct {
+	Path   string `json:"path"`   // path and query as referenced
+	Host   string `json:"host"`   // host the endpoint belongs to
+	URL    string `json:"url"`    // full URL
+	Source string `json:"source"` // JS file it was found in
+}
+
+// Result is what analyzing a domain's JavaScript found
+type Result struct {
+	Subdomains []string   `json:"subdomains"`
+	Endpoints  []Endpoint `json:"endpoints,omitempty"`
+	Secrets    []Secret   `json:"secrets,omitempty"`
+}
+
 // Parser extracts subdomains and endpoints from JavaScript files
 type Parser struct {
 	client *http.Client
 	logger *zap.Logger
 	
+	// JS files fetched at once, and bytes read across one domain's JS
+	// files (0 = no limit)
+	workers  int
+	maxBytes int64
+	
 	// Regex patterns for extraction
 	domainPattern   *regexp.Regexp
 	urlPattern      *regexp.Regexp
@@ -35,7 +66,8 @@ func NewParser(logger *zap.Logger) *Parser {
 				},
 			},
 		},
-		logger: logger,
+		logger:  logger,
+		workers: 1,
 		
 		// Compile regex patterns
 		domainPattern: regexp.MustCompile(
@@ -44,12 +76,88 @@ func NewParser(logger *zap.Logger) *Parser {
 		urlPattern: regexp.MustCompile(
 			`(?i)https?://[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)*`,
 		),
+		// Quoted absolute, protocol-relative or root-relative references;
+		// \x60 is the backtick of template literals
 		endpointPattern: regexp.MustCompile(
-			`(?i)['"\`](/[a-z0-9_/-]+)['"\`]`,
+			`["'\x60]((?:https?:)?//[^"'\x60\s/]+/[^"'\x60\s]*|/[^"'\x60\s/][^"'\x60\s]*)["'\x60]`,
 		),
 	}
 }
 
+// SetLimits sets how many JS files are fetched at once and how many bytes
+// may be read across the JS files of one domain (0 = no limit); files left
+// when the budget runs out are skipped
+func (p *Parser) SetLimits(workers int, maxBytes int64) {
+	if workers <= 0 {
+		workers = 1
+	}
+	p.workers = workers
+	p.maxBytes = maxBytes
+}
+
+// SetClient replaces the parser's HTTP client, e.g. with the prober's so
+// JS fetches share its transport and timeouts
+func (p *Parser) SetClient(client *http.Client) {
+	p.client = client
+}
+
+// byteBudget bounds the bytes read across concurrent fetches
+type byteBudget struct {
+	mu        sync.Mutex
+	remaining int64
+	limited   bool
+}
+
+// newByteBudget creates a budget of max bytes (0 = unlimited)
+func newByteBudget(max int64) *byteBudget {
+	return &byteBudget{remaining: max, limited: max > 0}
+}
+
+// take charges up to n bytes, returning how many were granted
+func (b *byteBudget) take(n int64) int64 {
+	if b == nil || !b.limited {
+		return n
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n > b.remaining {
+		n = b.remaining
+	}
+	b.remaining -= n
+	return n
+}
+
+// exhausted reports whether no bytes are left
+func (b *byteBudget) exhausted() bool {
+	if b == nil || !b.limited {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.remaining <= 0
+}
+
+// budgetReader reads from r while the budget lasts, then reports EOF so
+// the content read so far is still analyzed
+type budgetReader struct {
+	r      io.Reader
+	budget *byteBudget
+}
+
+// Read charges the budget for the bytes actually read, after the read, so
+// a read blocked on a slow server holds none of the budget other fetches
+// need. A read the budget can't cover in full is cut to what is left.
+func (r *budgetReader) Read(buf []byte) (int, error) {
+	if r.budget.exhausted() {
+		return 0, io.EOF
+	}
+	n, err := r.r.Read(buf)
+	if granted := r.budget.take(int64(n)); granted < int64(n) {
+		return int(granted), io.EOF
+	}
+	return n, err
+}
+
 // ParseHTML extracts JavaScript URLs from HTML content
 func (p *Parser) ParseHTML(ctx context.Context, url string) ([]string, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -80,26 +188,23 @@ func (p *Parser) extractJSURLs(html, baseURL string) []string {
 	var jsURLs []string
 	seen := make(map[string]bool)
 	
+	base, err := neturl.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	
 	// Find <script src="...">
 	scriptRegex := regexp.MustCompile(`<script[^>]+src=["']([^"']+)["']`)
 	matches := scriptRegex.FindAllStringSubmatch(html, -1)
 	
 	for _, match := range matches {
 		if len(match) > 1 {
-			jsURL := match[1]
-			
 			// Convert relative URLs to absolute
-			if !strings.HasPrefix(jsURL, "http") {
-				if strings.HasPrefix(jsURL, "//") {
-					jsURL = "https:" + jsURL
-				} else if strings.HasPrefix(jsURL, "/") {
-					// Extract base domain from URL
-					parts := strings.Split(baseURL, "/")
-					if len(parts) >= 3 {
-						jsURL = parts[0] + "//" + parts[2] + jsURL
-					}
-				}
+			ref, err := neturl.Parse(strings.TrimSpace(match[1]))
+			if err != nil {
+				continue
 			}
+			jsURL := base.ResolveReference(ref).String()
 			
 			// Filter out common CDNs and third-party scripts
 			if !p.isThirdParty(jsURL) && !seen[jsURL] {
@@ -113,31 +218,43 @@ func (p *Parser) extractJSURLs(html, baseURL string) []string {
 }
 
 // ParseJS analyzes JavaScript content and extracts subdomains/endpoints
-func (p *Parser) ParseJS(ctx context.Context, jsURL, targetDomain string) ([]string, []string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", jsURL, nil)
+func (p *Parser) ParseJS(ctx context.Context, jsURL, targetDomain string) ([]string, []Endpoint, error) {
+	result, err := p.parseJS(ctx, jsURL, targetDomain, nil)
 	if err != nil {
 		return nil, nil, err
 	}
+	return result.Subdomains, result.Endpoints, nil
+}
+
+// parseJS fetches and analyzes a JS file, reading no more than budget
+// allows (nil = only the per-file limit)
+func (p *Parser) parseJS(ctx context.Context, jsURL, targetDomain string, budget *byteBudget) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", jsURL, nil)
+	if err != nil {
+		return nil, err
+	}
 	
 	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; USR/1.0)")
 	
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 	
-	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 5*1024*1024)) // 5MB limit
+	body := io.LimitReader(resp.Body, maxJSFileSize)
+	bodyBytes, err := io.ReadAll(&budgetReader{r: body, budget: budget})
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	
 	content := string(bodyBytes)
 	
-	subdomains := p.extractSubdomains(content, targetDomain)
-	endpoints := p.extractEndpoints(content)
-	
-	return subdomains, endpoints, nil
+	return &Result{
+		Subdomains: p.extractSubdomains(content, targetDomain),
+		Endpoints:  p.extractEndpoints(content, jsURL),
+		Secrets:    p.ExtractSecrets(content, jsURL),
+	}, nil
 }
 
 // extractSubdomains finds potential subdomains in JavaScript
@@ -175,28 +292,46 @@ func (p *Parser) extractSubdomains(content, targetDomain string) []string {
 	return subdomains
 }
 
-// extractEndpoints finds API endpoints in JavaScript
-func (p *Parser) extractEndpoints(content string) []string {
-	var endpoints []string
+// extractEndpoints finds API endpoints in JavaScript, resolving them
+// against the URL of the file they were found in
+func (p *Parser) extractEndpoints(content, jsURL string) []Endpoint {
+	base, err := neturl.Parse(jsURL)
+	if err != nil {
+		return nil
+	}
+	
+	var endpoints []Endpoint
 	seen := make(map[string]bool)
 	
 	matches := p.endpointPattern.FindAllStringSubmatch(content, -1)
 	
 	for _, match := range matches {
-		if len(match) > 1 {
-			endpoint := match[1]
-			
-			// Filter out non-API paths
-			if p.looksLikeEndpoint(endpoint) && !seen[endpoint] {
-				endpoints = append(endpoints, endpoint)
-				seen[endpoint] = true
-			}
+		ref, err := neturl.Parse(match[1])
+		if err != nil {
+			continue
+		}
+		resolved := base.ResolveReference(ref)
+		
+		// Filter out non-API paths
+		if resolved.Hostname() == "" || !p.looksLikeEndpoint(resolved.Path) {
+			continue
+		}
+		
+		endpoint := Endpoint{
+			Path:   resolved.RequestURI(),
+			Host:   strings.ToLower(resolved.Hostname()),
+			URL:    resolved.String(),
+			Source: jsURL,
+		}
+		if !seen[endpoint.URL] {
+			endpoints = append(endpoints, endpoint)
+			seen[endpoint.URL] = true
 		}
 	}
 	
 	// Limit results
-	if len(endpoints) > 100 {
-		endpoints = endpoints[:100]
+	if len(endpoints) > maxEndpoints {
+		endpoints = endpoints[:maxEndpoints]
 	}
 	
 	return endpoints
@@ -301,8 +436,16 @@ func (p *Parser) looksLikeEndpoint(path string) bool {
 	return strings.Count(path, "/") >= 2 && strings.Count(path, "/") <= 6
 }
 
-// AnalyzeDomain performs comprehensive JS analysis on a domain
+// AnalyzeDomain performs comprehensive JS analysis on a domain, returning
+// the subdomains found
 func (p *Parser) AnalyzeDomain(ctx context.Context, domain string) ([]string, error) {
+	result, err := p.Analyze(ctx, domain)
+	return result.Subdomains, err
+}
+
+// Analyze fetches a domain's pages and the JS files they load, returning
+// the subdomains and endpoints referenced and the secrets exposed
+func (p *Parser) Analyze(ctx context.Context, domain string) (*Result, error) {
 	p.logger.Info("Analyzing JavaScript for domain", zap.String("domain", domain))
 	
 	// Try common URLs
@@ -311,10 +454,21 @@ func (p *Parser) AnalyzeDomain(ctx context.Context, domain string) ([]string, er
 		fmt.Sprintf("http://%s", domain),
 	}
 	
-	var allSubdomains []string
+	var (
+		mu     sync.Mutex
+		result = &Result{}
+	)
 	seen := make(map[string]bool)
+	seenEndpoints := make(map[string]bool)
+	seenSecrets := make(map[string]bool)
+	fetched := make(map[string]bool)
+	budget := newByteBudget(p.maxBytes)
 	
 	for _, url := range urls {
+		if ctx.Err() != nil || budget.exhausted() {
+			break
+		}
+		
 		// Get JS files from HTML
 		jsURLs, err := p.ParseHTML(ctx, url)
 		if err != nil {
@@ -325,30 +479,71 @@ func (p *Parser) AnalyzeDomain(ctx context.Context, domain string) ([]string, er
 			continue
 		}
 		
-		// Analyze each JS file
+		// The HTTP and HTTPS pages usually load the same files
+		var pending []string
 		for _, jsURL := range jsURLs {
-			subdomains, _, err := p.ParseJS(ctx, jsURL, domain)
+			if !fetched[jsURL] {
+				fetched[jsURL] = true
+				pending = append(pending, jsURL)
+			}
+		}
+		
+		// Analyze the JS files concurrently while the byte budget lasts
+		err = pool.Run(ctx, pending, p.workers, func(ctx context.Context, jsURL string) {
+			if budget.exhausted() {
+				return
+			}
+			
+			file, err := p.parseJS(ctx, jsURL, domain, budget)
 			if err != nil {
 				p.logger.Debug("Failed to parse JS",
 					zap.String("url", jsURL),
 					zap.Error(err),
 				)
-				continue
+				return
 			}
 			
-			for _, sub := range subdomains {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, sub := range file.Subdomains {
 				if !seen[sub] {
-					allSubdomains = append(allSubdomains, sub)
+					result.Subdomains = append(result.Subdomains, sub)
 					seen[sub] = true
 				}
 			}
+			for _, endpoint := range file.Endpoints {
+				if !seenEndpoints[endpoint.URL] {
+					result.Endpoints = append(result.Endpoints, endpoint)
+					seenEndpoints[endpoint.URL] = true
+				}
+			}
+			// The same key bundled into several files is one secret
+			for _, secret := range file.Secrets {
+				key := secret.Type + ":" + secret.Preview
+				if !seenSecrets[key] {
+					result.Secrets = append(result.Secrets, secret)
+					seenSecrets[key] = true
+				}
+			}
+		})
+		if err != nil {
+			return result, err
 		}
 	}
 	
+	if budget.exhausted() {
+		p.logger.Info("JS byte budget reached, remaining files skipped",
+			zap.String("domain", domain),
+			zap.Int64("max_bytes", p.maxBytes),
+		)
+	}
+	
 	p.logger.Info("JS analysis complete",
 		zap.String("domain", domain),
-		zap.Int("subdomains_found", len(allSubdomains)),
+		zap.Int("subdomains_found", len(result.Subdomains)),
+		zap.Int("endpoints_found", len(result.Endpoints)),
+		zap.Int("secrets_found", len(result.Secrets)),
 	)
 	
-	return allSubdomains, nil
+	return result, ctx.Err()
 }
\ No newline at end of file
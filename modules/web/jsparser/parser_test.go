@@ -0,0 +1,383 @@
+package jsparser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// jsSite serves files by path and answers every host, so a parser using
+// its client analyzes the site under any domain. HTTPS fails against it,
+// leaving the HTTP page.
+type jsSite struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests map[string]int
+	inFlight int
+	peak     int
+}
+
+// newJSSite starts a site serving files; onRequest, if set, runs before
+// each response
+func newJSSite(t *testing.T, files map[string]string, onRequest func(path string)) *jsSite {
+	t.Helper()
+
+	s := &jsSite{requests: make(map[string]int)}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		s.inFlight++
+		s.peak = max(s.peak, s.inFlight)
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			s.inFlight--
+			s.mu.Unlock()
+		}()
+
+		if onRequest != nil {
+			onRequest(r.URL.Path)
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// parser returns a parser whose requests, whatever their host, reach the
+// site
+func (s *jsSite) parser(workers int, maxBytes int64) *Parser {
+	p := NewParser(zap.NewNop())
+	p.SetLimits(workers, maxBytes)
+
+	addr := s.server.Listener.Addr().String()
+	p.SetClient(&http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}})
+	return p
+}
+
+// fetched returns how often path was requested
+func (s *jsSite) fetched(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// scriptPage returns a page loading n scripts, /static/1.js onwards
+func scriptPage(n int) string {
+	var page strings.Builder
+	page.WriteString("<html><head>")
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&page, `<script src="/static/%d.js"></script>`, i)
+	}
+	page.WriteString("</head></html>")
+	return page.String()
+}
+
+func TestAnalyzeFetchesScriptsConcurrently(t *testing.T) {
+	files := map[string]string{"/": scriptPage(6)}
+	for i := 1; i <= 6; i++ {
+		files[fmt.Sprintf("/static/%d.js", i)] = fmt.Sprintf(`fetch("https://api%d.example.com/v1/items")`, i)
+	}
+
+	// Hold each script until three are in flight, so the pool's bound is
+	// reached and observed
+	var (
+		arrived sync.WaitGroup
+		once    sync.Once
+		release = make(chan struct{})
+	)
+	arrived.Add(3)
+	go func() {
+		arrived.Wait()
+		once.Do(func() { close(release) })
+	}()
+	t.Cleanup(func() { once.Do(func() { close(release) }) })
+
+	var count sync.Mutex
+	started := 0
+	site := newJSSite(t, files, func(path string) {
+		if !strings.HasPrefix(path, "/static/") {
+			return
+		}
+		count.Lock()
+		started++
+		first := started <= 3
+		count.Unlock()
+		if first {
+			arrived.Done()
+		}
+		<-release
+	})
+
+	result, err := site.parser(3, 0).Analyze(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(result.Subdomains)
+	want := []string{"api1.example.com", "api2.example.com", "api3.example.com", "api4.example.com", "api5.example.com", "api6.example.com"}
+	if strings.Join(result.Subdomains, " ") != strings.Join(want, " ") {
+		t.Errorf("subdomains = %v, want %v", result.Subdomains, want)
+	}
+	for i := 1; i <= 6; i++ {
+		if n := site.fetched(fmt.Sprintf("/static/%d.js", i)); n != 1 {
+			t.Errorf("/static/%d.js fetched %d times, want once", i, n)
+		}
+	}
+	if site.peak != 3 {
+		t.Errorf("%d scripts fetched at once, want 3", site.peak)
+	}
+}
+
+func TestAnalyzeStopsAtByteBudget(t *testing.T) {
+	// Each file names one host at its start and one at its end
+	file := func(i int) string {
+		head := fmt.Sprintf(`"head%d.example.com";`, i)
+		tail := fmt.Sprintf(`"tail%d.example.com";`, i)
+		return head + strings.Repeat(" ", 1000-len(head)-len(tail)) + tail
+	}
+	files := map[string]string{"/": scriptPage(4)}
+	for i := 1; i <= 4; i++ {
+		files[fmt.Sprintf("/static/%d.js", i)] = file(i)
+	}
+	site := newJSSite(t, files, nil)
+
+	// One worker reads the files in order: all of the first, half of the
+	// second, none of the rest
+	result, err := site.parser(1, 1500).Analyze(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"head1.example.com", "tail1.example.com", "head2.example.com"}
+	if strings.Join(result.Subdomains, " ") != strings.Join(want, " ") {
+		t.Errorf("subdomains = %v, want %v", result.Subdomains, want)
+	}
+	for path, want := range map[string]int{"/static/1.js": 1, "/static/2.js": 1, "/static/3.js": 0, "/static/4.js": 0} {
+		if n := site.fetched(path); n != want {
+			t.Errorf("%s fetched %d times, want %d", path, n, want)
+		}
+	}
+}
+
+func TestAnalyzeStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	files := map[string]string{"/": scriptPage(3)}
+	for i := 1; i <= 3; i++ {
+		files[fmt.Sprintf("/static/%d.js", i)] = fmt.Sprintf(`"js%d.example.com"`, i)
+	}
+	site := newJSSite(t, files, func(path string) {
+		if path == "/static/1.js" {
+			cancel()
+		}
+	})
+
+	_, err := site.parser(1, 0).Analyze(ctx, "example.com")
+	if err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if site.fetched("/static/2.js") != 0 || site.fetched("/static/3.js") != 0 {
+		t.Error("scripts fetched after cancel")
+	}
+}
+
+func TestByteBudget(t *testing.T) {
+	budget := newByteBudget(100)
+	if got := budget.take(60); got != 60 {
+		t.Errorf("take(60) = %d", got)
+	}
+	if got := budget.take(60); got != 40 {
+		t.Errorf("take(60) past the budget = %d, want the 40 left", got)
+	}
+	if !budget.exhausted() {
+		t.Error("budget not exhausted")
+	}
+	if got := budget.take(10); got != 0 {
+		t.Errorf("take(10) on an exhausted budget = %d", got)
+	}
+
+	unlimited := newByteBudget(0)
+	if unlimited.take(1<<40) != 1<<40 || unlimited.exhausted() {
+		t.Error("zero budget limited reads")
+	}
+}
+
+func TestBudgetReaderCutsAtBudget(t *testing.T) {
+	budget := newByteBudget(10)
+	data, err := io.ReadAll(&budgetReader{r: strings.NewReader(strings.Repeat("x", 25)), budget: budget})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 10 {
+		t.Errorf("read %d bytes, want the 10 budgeted", len(data))
+	}
+	if !budget.exhausted() {
+		t.Error("budget not exhausted")
+	}
+}
+
+func TestBudgetReaderConcurrentReadsUnderBudget(t *testing.T) {
+	// Together the files fit the budget, so both must be read in full even
+	// while one read is blocked on a slow server
+	budget := newByteBudget(1000)
+	slow := strings.Repeat("s", 300)
+	fast := strings.Repeat("f", 600)
+
+	pipeReader, pipeWriter := io.Pipe()
+	slowDone := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(&budgetReader{r: pipeReader, budget: budget})
+		slowDone <- string(data)
+	}()
+
+	// Let the slow reader block inside Read before the fast one starts
+	time.Sleep(20 * time.Millisecond)
+
+	data, err := io.ReadAll(&budgetReader{r: strings.NewReader(fast), budget: budget})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != fast {
+		t.Errorf("fast file read %d of %d bytes while another read was in flight", len(data), len(fast))
+	}
+
+	pipeWriter.Write([]byte(slow))
+	pipeWriter.Close()
+	if got := <-slowDone; got != slow {
+		t.Errorf("slow file read %d of %d bytes", len(got), len(slow))
+	}
+}
+
+func TestExtractEndpoints(t *testing.T) {
+	p := NewParser(zap.NewNop())
+	const jsURL = "https://www.example.com/static/app.js"
+
+	tests := []struct {
+		name    string
+		content string
+		want    []Endpoint
+	}{
+		{
+			name:    "root-relative",
+			content: `fetch("/api/v2/users?page=1")`,
+			want:    []Endpoint{{Path: "/api/v2/users?page=1", Host: "www.example.com", URL: "https://www.example.com/api/v2/users?page=1"}},
+		},
+		{
+			name:    "absolute",
+			content: `const base = 'https://API.example.com/v1/orders';`,
+			want:    []Endpoint{{Path: "/v1/orders", Host: "api.example.com", URL: "https://API.example.com/v1/orders"}},
+		},
+		{
+			name:    "protocol-relative takes the file's scheme",
+			content: `load("//cdn.example.net/data/feed.json")`,
+			want:    []Endpoint{{Path: "/data/feed.json", Host: "cdn.example.net", URL: "https://cdn.example.net/data/feed.json"}},
+		},
+		{
+			name:    "template literal",
+			content: "axios.get(`/rest/accounts/me`)",
+			want:    []Endpoint{{Path: "/rest/accounts/me", Host: "www.example.com", URL: "https://www.example.com/rest/accounts/me"}},
+		},
+		{
+			name:    "duplicates kept once",
+			content: `get("/graphql"); post("/graphql"); get('https://www.example.com/graphql')`,
+			want:    []Endpoint{{Path: "/graphql", Host: "www.example.com", URL: "https://www.example.com/graphql"}},
+		},
+		{
+			name:    "assets and short paths skipped",
+			content: `img("/img/logo.png"); link("/css/site.css"); go("/"); page("/a")`,
+		},
+		{
+			name: "unquoted and relative references skipped",
+			content: `// see /api/v1/docs for details
+				load("api/v1/relative")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.extractEndpoints(tt.content, jsURL)
+			for i := range tt.want {
+				tt.want[i].Source = jsURL
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpointPattern(t *testing.T) {
+	p := NewParser(zap.NewNop())
+
+	tests := []struct {
+		content string
+		want    string // captured reference, "" = no match
+	}{
+		{`"/api/users"`, "/api/users"},
+		{`'/api/users'`, "/api/users"},
+		{"`/api/users`", "/api/users"},
+		{`"https://api.example.com/v1"`, "https://api.example.com/v1"},
+		{`"http://api.example.com/"`, "http://api.example.com/"},
+		{`"//cdn.example.com/app/main"`, "//cdn.example.com/app/main"},
+		{`"/api/users?id=1&sort=asc"`, "/api/users?id=1&sort=asc"},
+		{`"/"`, ""},
+		{`"//"`, ""},
+		{`"/api/with space"`, ""},
+		{`"https://api.example.com"`, ""}, // no path
+		{`"ftp://files.example.com/pub"`, ""},
+		{`"relative/path"`, ""},
+	}
+
+	for _, tt := range tests {
+		var got string
+		if match := p.endpointPattern.FindStringSubmatch(tt.content); match != nil {
+			got = match[1]
+		}
+		if got != tt.want {
+			t.Errorf("%s captured %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestExtractJSURLs(t *testing.T) {
+	p := NewParser(zap.NewNop())
+
+	html := `<script src="/static/app.js"></script>
+		<script type="module" src="chunks/vendor.js"></script>
+		<script src="//assets.example.com/bundle.js"></script>
+		<script src="https://cdn.jsdelivr.net/npm/vue@3/dist/vue.js"></script>
+		<script src="/static/app.js"></script>
+		<script>inline()</script>`
+
+	got := p.extractJSURLs(html, "https://www.example.com/shop/index.html")
+	want := []string{
+		"https://www.example.com/static/app.js",
+		"https://www.example.com/shop/chunks/vendor.js",
+		"https://assets.example.com/bundle.js",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
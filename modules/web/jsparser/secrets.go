@@ -0,0 +1,169 @@
+package jsparser
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// maxSecrets is how many secrets are kept per JS file
+const maxSecrets = 50
+
+// Generic assignments need values this random (bits per character), so
+// repeated or dictionary-like strings aren't reported
+const minGenericEntropy = 3.5
+
+// placeholders mark example values in docs and config templates
+var placeholders = []string{"your", "example", "placeholder", "changeme", "xxxx", "dummy"}
+
+// Secret is a credential found in a JS file. Only a redacted preview is
+// kept, never the value itself.
+type Secret struct {
+	Type     string `json:"type"` // aws_access_key, google_api_key, ...
+	Title    string `json:"title"`
+	Severity string `json:"severity"`
+	Preview  string `json:"preview"` // first and last characters
+	Source   string `json:"source"`  // JS file it was found in
+}
+
+// secretRule is one kind of credential; the value is the pattern's first
+// group, or the whole match when it has none
+type secretRule struct {
+	kind     string
+	title    string
+	severity string
+	pattern  *regexp.Regexp
+	generic  bool // needs the entropy check
+}
+
+// secretRules are high-signal credential formats. Publishable keys meant to
+// ship to browsers (Stripe pk_live_) are left out.
+var secretRules = []secretRule{
+	{
+		kind:     "aws_access_key",
+		title:    "AWS access key ID",
+		severity: types.SeverityHigh,
+		pattern:  regexp.MustCompile(`\b((?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[0-9A-Z]{16})\b`),
+	},
+	{
+		kind:     "google_api_key",
+		title:    "Google API key",
+		severity: types.SeverityMedium,
+		pattern:  regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`),
+	},
+	{
+		kind:     "stripe_key",
+		title:    "Stripe secret key",
+		severity: types.SeverityHigh,
+		pattern:  regexp.MustCompile(`\b(?:sk|rk)_live_[0-9A-Za-z]{24,99}\b`),
+	},
+	{
+		kind:     "slack_token",
+		title:    "Slack token",
+		severity: types.SeverityHigh,
+		pattern:  regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z-]{10,72}`),
+	},
+	{
+		kind:     "slack_webhook",
+		title:    "Slack webhook URL",
+		severity: types.SeverityMedium,
+		pattern:  regexp.MustCompile(`https://hooks\.slack\.com/services/(T[0-9A-Z]{8,12}/B[0-9A-Z]{8,12}/[0-9A-Za-z]{24})`),
+	},
+	{
+		kind:     "jwt",
+		title:    "JSON Web Token",
+		severity: types.SeverityMedium,
+		pattern:  regexp.MustCompile(`\beyJ[0-9A-Za-z_-]{10,}\.eyJ[0-9A-Za-z_-]{10,}\.[0-9A-Za-z_-]{10,}`),
+	},
+	{
+		kind:     "generic_api_key",
+		title:    "API key assignment",
+		severity: types.SeverityMedium,
+		pattern: regexp.MustCompile(
+			`(?i)\b(?:api[_-]?key|api[_-]?secret|secret[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)["']?\s*[:=]\s*["'\x60]([0-9A-Za-z_+/=.-]{16,128})["'\x60]`,
+		),
+		generic: true,
+	},
+}
+
+// ExtractSecrets scans JavaScript content for credentials, returning each
+// distinct one with a redacted preview. source is recorded as where they
+// were found.
+func (p *Parser) ExtractSecrets(content, source string) []Secret {
+	var secrets []Secret
+	seen := make(map[string]bool)
+
+	for _, rule := range secretRules {
+		for _, match := range rule.pattern.FindAllStringSubmatch(content, -1) {
+			value := match[0]
+			if len(match) > 1 {
+				value = match[1]
+			}
+
+			if seen[value] || (rule.generic && !looksRandom(value)) {
+				continue
+			}
+			seen[value] = true
+
+			secrets = append(secrets, Secret{
+				Type:     rule.kind,
+				Title:    rule.title,
+				Severity: rule.severity,
+				Preview:  redact(value),
+				Source:   source,
+			})
+			if len(secrets) >= maxSecrets {
+				return secrets
+			}
+		}
+	}
+
+	return secrets
+}
+
+// looksRandom reports whether a generic match is random enough to be a key
+// rather than a placeholder or a reference like "process.env.API_KEY"
+func looksRandom(value string) bool {
+	if strings.Contains(value, ".") || !strings.ContainsAny(value, "0123456789") {
+		return false
+	}
+
+	lower := strings.ToLower(value)
+	for _, word := range placeholders {
+		if strings.Contains(lower, word) {
+			return false
+		}
+	}
+	return entropy(value) >= minGenericEntropy
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	var bits float64
+	length := float64(len(s))
+	for _, count := range counts {
+		freq := float64(count) / length
+		bits -= freq * math.Log2(freq)
+	}
+	return bits
+}
+
+// redact keeps the first and last four characters of a value, enough to
+// tell keys apart without reproducing them
+func redact(value string) string {
+	if len(value) <= 12 {
+		return value[:2] + strings.Repeat("*", len(value)-2)
+	}
+	return value[:4] + strings.Repeat("*", 8) + value[len(value)-4:]
+}
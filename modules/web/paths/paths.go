@@ -0,0 +1,330 @@
+package paths
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// FindingType marks findings produced by this prober
+const FindingType = "exposed_path"
+
+// maxBody is how much of each response is read for matching
+const maxBody = 64 * 1024
+
+// evidenceSize is how much of a matching body is kept as evidence
+const evidenceSize = 120
+
+// check describes a known path: how to recognise a real hit and how bad it is.
+// Content matchers keep soft-404 pages and login redirects from counting.
+type check struct {
+	title    string
+	severity string
+	match    func(body, contentType string) bool
+}
+
+var knownChecks = map[string]check{
+	"/.git/HEAD": {
+		title:    "Git repository exposed",
+		severity: types.SeverityHigh,
+		match: func(body, _ string) bool {
+			return strings.HasPrefix(strings.TrimSpace(body), "ref: refs/")
+		},
+	},
+	"/.git/config": {
+		title:    "Git config exposed",
+		severity: types.SeverityHigh,
+		match: func(body, _ string) bool {
+			return strings.Contains(body, "[core]")
+		},
+	},
+	"/.env": {
+		title:    "Environment file exposed",
+		severity: types.SeverityHigh,
+		match:    isEnvFile,
+	},
+	"/swagger.json": {
+		title:    "API specification exposed",
+		severity: types.SeverityLow,
+		match:    isAPISpec,
+	},
+	"/openapi.json": {
+		title:    "API specification exposed",
+		severity: types.SeverityLow,
+		match:    isAPISpec,
+	},
+	"/actuator": {
+		title:    "Spring Boot actuator exposed",
+		severity: types.SeverityMedium,
+		match: func(body, _ string) bool {
+			return strings.Contains(body, `"_links"`)
+		},
+	},
+	"/actuator/env": {
+		title:    "Spring Boot environment exposed",
+		severity: types.SeverityHigh,
+		match: func(body, _ string) bool {
+			return strings.Contains(body, `"propertySources"`)
+		},
+	},
+	"/server-status": {
+		title:    "Apache server-status exposed",
+		severity: types.SeverityMedium,
+		match: func(body, _ string) bool {
+			return strings.Contains(body, "Apache Server Status")
+		},
+	},
+}
+
+// Prober checks alive hosts for a list of high-signal paths
+type Prober struct {
+	client     *http.Client
+	logger     *zap.Logger
+	prepare    func(*http.Request)
+	paths      []string
+	workers    int
+	maxPerHost int
+}
+
+// NewProber creates a path prober that sends requests through the given
+// client, normally the HTTP prober's so settings and connections are
+// shared; prepare (optional) sets the User-Agent and headers of each request
+func NewProber(client *http.Client, cfg *config.PathsConfig, prepare func(*http.Request), logger *zap.Logger) *Prober {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	return &Prober{
+		client:     client,
+		logger:     logger,
+		prepare:    prepare,
+		paths:      cfg.List,
+		workers:    workers,
+		maxPerHost: cfg.MaxPerHost,
+	}
+}
+
+// ProbeBatch checks every host that answered HTTP and appends hits to its
+// Findings. Hosts are checked concurrently; paths on one host sequentially.
+// It returns the number of findings and any panics recovered from checks.
+func (p *Prober) ProbeBatch(ctx context.Context, subdomains []*types.Subdomain) (int, error) {
+	var alive []*types.Subdomain
+	for _, sub := range subdomains {
+		if sub.HTTP != nil && sub.HTTP.StatusCode > 0 {
+			alive = append(alive, sub)
+		}
+	}
+	if len(alive) == 0 || len(p.paths) == 0 {
+		return 0, nil
+	}
+
+	p.logger.Info("Checking exposed paths",
+		zap.Int("hosts", len(alive)),
+		zap.Int("paths", len(p.paths)),
+		zap.Int("workers", p.workers),
+	)
+
+	var (
+		mu    sync.Mutex
+		total int
+	)
+
+	err := pool.Run(ctx, alive, p.workers, func(ctx context.Context, sub *types.Subdomain) {
+		findings := p.probeHost(ctx, sub)
+		if len(findings) == 0 {
+			return
+		}
+
+		mu.Lock()
+		sub.Findings = append(sub.Findings, findings...)
+		total += len(findings)
+		mu.Unlock()
+	})
+
+	p.logger.Info("Path checks complete", zap.Int("findings", total))
+	return total, err
+}
+
+// probeHost checks the configured paths on one host, staying within
+// maxPerHost requests
+func (p *Prober) probeHost(ctx context.Context, sub *types.Subdomain) []types.Finding {
+	baseURL := p.baseURL(sub)
+	budget := p.maxPerHost
+	if budget <= 0 {
+		budget = len(p.paths) + 1
+	}
+
+	// A server answering 200 for anything makes generic paths meaningless;
+	// remember what its not-found page looks like
+	budget--
+	notFound, err := p.fetch(ctx, baseURL+"/"+randomName())
+	if err != nil {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, path := range p.paths {
+		if budget <= 0 || ctx.Err() != nil {
+			break
+		}
+		budget--
+
+		path = "/" + strings.TrimPrefix(path, "/")
+		resp, err := p.fetch(ctx, baseURL+path)
+		if err != nil || resp.status < 200 || resp.status >= 300 {
+			continue
+		}
+
+		finding := types.Finding{
+			Type:       FindingType,
+			Title:      "Accessible path " + path,
+			Severity:   types.SeverityInfo,
+			URL:        baseURL + path,
+			StatusCode: resp.status,
+			Evidence:   evidence(resp.body),
+			Time:       time.Now(),
+		}
+
+		if known, ok := knownChecks[path]; ok {
+			if !known.match(resp.body, resp.contentType) {
+				continue
+			}
+			finding.Title = known.title
+			finding.Severity = known.severity
+		} else if notFound.status >= 200 && notFound.status < 300 && similar(resp.body, notFound.body) {
+			continue
+		}
+
+		p.logger.Debug("Exposed path found",
+			zap.String("url", finding.URL),
+			zap.String("severity", finding.Severity),
+		)
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
+
+// baseURL picks the scheme the host answered on, preferring HTTPS
+func (p *Prober) baseURL(sub *types.Subdomain) string {
+	if sub.TLS != nil {
+		return "https://" + sub.Domain
+	}
+	return "http://" + sub.Domain
+}
+
+// response is the part of an HTTP response the checks look at
+type response struct {
+	status      int
+	contentType string
+	body        string
+}
+
+// fetch GETs a URL without following redirects, so login redirects aren't
+// mistaken for accessible content
+func (p *Prober) fetch(ctx context.Context, url string) (*response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.prepare != nil {
+		p.prepare(req)
+	}
+
+	client := *p.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return nil, err
+	}
+
+	return &response{
+		status:      resp.StatusCode,
+		contentType: resp.Header.Get("Content-Type"),
+		body:        string(body),
+	}, nil
+}
+
+// isEnvFile reports whether a body looks like KEY=value lines rather than HTML
+func isEnvFile(body, contentType string) bool {
+	if strings.Contains(strings.ToLower(contentType), "html") {
+		return false
+	}
+
+	assignments := 0
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, found := strings.Cut(line, "=")
+		if !found || key == "" || strings.ContainsAny(key, " <>\"") {
+			return false
+		}
+		assignments++
+	}
+	return assignments > 0
+}
+
+// isAPISpec reports whether a body is a Swagger/OpenAPI document
+func isAPISpec(body, _ string) bool {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		return false
+	}
+	_, swagger := doc["swagger"]
+	_, openapi := doc["openapi"]
+	return swagger || openapi
+}
+
+// similar reports whether two bodies are the same page, allowing for small
+// differences such as the requested path being echoed back
+func similar(a, b string) bool {
+	if a == b {
+		return true
+	}
+	longer := max(len(a), len(b))
+	if longer == 0 {
+		return true
+	}
+	diff := len(a) - len(b)
+	if diff < 0 {
+		diff = -diff
+	}
+	return float64(diff)/float64(longer) < 0.05
+}
+
+// evidence returns the start of a body, collapsed to one line
+func evidence(body string) string {
+	snippet := strings.Join(strings.Fields(body), " ")
+	if len(snippet) > evidenceSize {
+		snippet = snippet[:evidenceSize] + "..."
+	}
+	return snippet
+}
+
+// randomName returns a path that shouldn't exist on any server
+func randomName() string {
+	return fmt.Sprintf("usr-%d-not-found", rand.Int63())
+}
@@ -0,0 +1,265 @@
+package paths
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+func TestKnownChecks(t *testing.T) {
+	tests := []struct {
+		path        string
+		body        string
+		contentType string
+		want        bool
+	}{
+		{"/.git/HEAD", "ref: refs/heads/main\n", "text/plain", true},
+		{"/.git/HEAD", "  ref: refs/heads/master", "", true},
+		{"/.git/HEAD", "<html>Not found</html>", "text/html", false},
+		{"/.git/config", "[core]\n\trepositoryformatversion = 0\n", "", true},
+		{"/.git/config", "<html>login</html>", "text/html", false},
+		{"/.env", "APP_KEY=base64:abc\nDB_PASSWORD=secret\n", "text/plain", true},
+		{"/.env", "<html><body>Welcome</body></html>", "text/html", false},
+		{"/swagger.json", `{"swagger":"2.0","paths":{}}`, "application/json", true},
+		{"/openapi.json", `{"openapi":"3.0.1"}`, "application/json", true},
+		{"/openapi.json", `{"error":"not found"}`, "application/json", false},
+		{"/actuator", `{"_links":{"self":{"href":"/actuator"}}}`, "application/json", true},
+		{"/actuator", `{"status":"UP"}`, "application/json", false},
+		{"/actuator/env", `{"activeProfiles":[],"propertySources":[]}`, "application/json", true},
+		{"/actuator/env", `{"_links":{}}`, "application/json", false},
+		{"/server-status", "<h1>Apache Server Status for example.com</h1>", "text/html", true},
+		{"/server-status", "<h1>Status</h1>", "text/html", false},
+	}
+
+	for _, tt := range tests {
+		check, ok := knownChecks[tt.path]
+		if !ok {
+			t.Fatalf("no check for %s", tt.path)
+		}
+		if got := check.match(tt.body, tt.contentType); got != tt.want {
+			t.Errorf("%s matching %q = %v, want %v", tt.path, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestIsEnvFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        bool
+	}{
+		{name: "assignments", body: "A=1\nB=two\n", want: true},
+		{name: "comments and blank lines", body: "# settings\n\nA=1\n", want: true},
+		{name: "empty values", body: "SECRET=\n", want: true},
+		{name: "html content type", body: "A=1\n", contentType: "text/html; charset=utf-8"},
+		{name: "html body", body: "<html>\n<body>A=1</body>"},
+		{name: "prose", body: "Page not found"},
+		{name: "key with spaces", body: "not a key=1"},
+		{name: "missing key", body: "=value"},
+		{name: "only comments", body: "# nothing here\n"},
+		{name: "empty"},
+	}
+
+	for _, tt := range tests {
+		if got := isEnvFile(tt.body, tt.contentType); got != tt.want {
+			t.Errorf("%s: isEnvFile = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	page := strings.Repeat("x", 1000)
+
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{name: "identical", a: page, b: page, want: true},
+		{name: "both empty", want: true},
+		{name: "path echoed back", a: page + "/.env", b: page + "/usr-123-not-found", want: true},
+		{name: "different size", a: page, b: page[:900]},
+		{name: "one empty", a: page},
+	}
+
+	for _, tt := range tests {
+		if got := similar(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: similar = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	if got := evidence("ref:   refs/heads/main\n"); got != "ref: refs/heads/main" {
+		t.Errorf("evidence = %q", got)
+	}
+
+	long := evidence(strings.Repeat("a ", 200))
+	if len(long) != evidenceSize+len("...") || !strings.HasSuffix(long, "...") {
+		t.Errorf("long evidence = %q (%d bytes)", long, len(long))
+	}
+}
+
+// pathSite serves fixed responses by path, with fallback for any other,
+// counting requests
+type pathSite struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests int
+}
+
+// pathResponse is what the site answers for a path
+type pathResponse struct {
+	status      int
+	contentType string
+	body        string
+	location    string
+}
+
+func newPathSite(t *testing.T, responses map[string]pathResponse, fallback pathResponse) *pathSite {
+	t.Helper()
+
+	s := &pathSite{}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		s.mu.Unlock()
+
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			resp = fallback
+		}
+		if resp.contentType != "" {
+			w.Header().Set("Content-Type", resp.contentType)
+		}
+		if resp.location != "" {
+			w.Header().Set("Location", resp.location)
+		}
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// probe runs the prober over the site with cfg and returns the host's
+// findings by path
+func (s *pathSite) probe(t *testing.T, cfg config.PathsConfig) map[string]types.Finding {
+	t.Helper()
+
+	sub := &types.Subdomain{
+		Domain: strings.TrimPrefix(s.server.URL, "http://"),
+		HTTP:   &types.HTTPInfo{StatusCode: 200},
+	}
+	p := NewProber(s.server.Client(), &cfg, nil, zap.NewNop())
+	if _, err := p.ProbeBatch(context.Background(), []*types.Subdomain{sub}); err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]types.Finding)
+	for _, finding := range sub.Findings {
+		found[strings.TrimPrefix(finding.URL, s.server.URL)] = finding
+	}
+	return found
+}
+
+func TestProbeBatch(t *testing.T) {
+	notFound := pathResponse{status: http.StatusNotFound, body: "not found"}
+	softNotFound := pathResponse{status: http.StatusOK, contentType: "text/html", body: "<html>" + strings.Repeat("Home page ", 50) + "</html>"}
+
+	responses := map[string]pathResponse{
+		"/.git/HEAD": {status: http.StatusOK, body: "ref: refs/heads/main\n"},
+		"/.env":      {status: http.StatusOK, contentType: "text/html", body: softNotFound.body},
+		"/admin":     {status: http.StatusFound, location: "/login"},
+		"/backup":    {status: http.StatusOK, body: "backup archive index: db.sql, files.tar"},
+		"/old":       {status: http.StatusOK, contentType: "text/html", body: softNotFound.body + "<!-- /old -->"},
+	}
+	list := []string{"/.git/HEAD", ".env", "/admin", "/backup", "/old", "/missing"}
+
+	tests := []struct {
+		name     string
+		fallback pathResponse
+		want     []string
+	}{
+		// Everything that answers 2xx counts, but known paths must match
+		{name: "real not-found page", fallback: notFound, want: []string{"/.git/HEAD", "/backup", "/old"}},
+		// Generic paths answering like the not-found page are dropped
+		{name: "soft 404", fallback: softNotFound, want: []string{"/.git/HEAD", "/backup"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := newPathSite(t, responses, tt.fallback)
+			found := site.probe(t, config.PathsConfig{List: list, Workers: 2})
+
+			var got []string
+			for path := range found {
+				got = append(got, path)
+			}
+			sort.Strings(got)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("found %v, want %v", got, tt.want)
+			}
+
+			head := found["/.git/HEAD"]
+			if head.Title != "Git repository exposed" || head.Severity != types.SeverityHigh || head.Type != FindingType {
+				t.Errorf("git finding = %+v", head)
+			}
+			if backup := found["/backup"]; backup.Severity != types.SeverityInfo || backup.StatusCode != http.StatusOK {
+				t.Errorf("generic finding = %+v", backup)
+			}
+		})
+	}
+}
+
+func TestProbeBatchRequestBudget(t *testing.T) {
+	site := newPathSite(t, nil, pathResponse{status: http.StatusNotFound})
+
+	list := []string{"/a", "/b", "/c", "/d", "/e"}
+	tests := []struct {
+		maxPerHost int
+		requests   int
+	}{
+		{maxPerHost: 3, requests: 3}, // the not-found check and two paths
+		{maxPerHost: 1, requests: 1},
+		{maxPerHost: 0, requests: len(list) + 1},
+		{maxPerHost: 50, requests: len(list) + 1},
+	}
+
+	for _, tt := range tests {
+		site.mu.Lock()
+		site.requests = 0
+		site.mu.Unlock()
+
+		site.probe(t, config.PathsConfig{List: list, MaxPerHost: tt.maxPerHost})
+
+		site.mu.Lock()
+		got := site.requests
+		site.mu.Unlock()
+		if got != tt.requests {
+			t.Errorf("max_per_host %d: %d requests, want %d", tt.maxPerHost, got, tt.requests)
+		}
+	}
+}
+
+func TestProbeBatchSkipsDeadHosts(t *testing.T) {
+	p := NewProber(http.DefaultClient, &config.PathsConfig{List: []string{"/.env"}}, nil, zap.NewNop())
+
+	subdomains := []*types.Subdomain{
+		{Domain: "unprobed.invalid"},
+		{Domain: "refused.invalid", HTTP: &types.HTTPInfo{}},
+	}
+	if n, err := p.ProbeBatch(context.Background(), subdomains); n != 0 || err != nil {
+		t.Errorf("ProbeBatch = %d, %v; want nothing probed", n, err)
+	}
+}
@@ -0,0 +1,129 @@
+package prober
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// API types recorded in HTTPInfo.APIType
+const (
+	APITypeREST    = "rest"
+	APITypeGraphQL = "graphql"
+	APITypeOpenAPI = "openapi"
+	APITypeJSONAPI = "jsonapi"
+	APITypeHAL     = "hal"
+)
+
+// apiSnippetSize is how much of a JSON body is kept in place of a title
+const apiSnippetSize = 200
+
+// isJSONResponse reports whether a response carries JSON, by content type
+// or, for servers that mislabel it, by a body that parses as JSON
+func isJSONResponse(contentType, body string) bool {
+	contentType = strings.ToLower(contentType)
+	if strings.Contains(contentType, "json") {
+		return true
+	}
+
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
+
+// detectAPIType classifies a JSON response by media type and body signatures
+func detectAPIType(contentType, body string) string {
+	contentType = strings.ToLower(contentType)
+	bodyLower := strings.ToLower(body)
+
+	switch {
+	case strings.Contains(contentType, "application/vnd.api+json"):
+		return APITypeJSONAPI
+	case strings.Contains(contentType, "application/hal+json"):
+		return APITypeHAL
+	case strings.Contains(contentType, "graphql"),
+		strings.Contains(bodyLower, "must provide query string"),
+		strings.Contains(bodyLower, "graphql"):
+		return APITypeGraphQL
+	case isOpenAPIDocument(body):
+		return APITypeOpenAPI
+	default:
+		return APITypeREST
+	}
+}
+
+// isOpenAPIDocument reports whether a body is a Swagger/OpenAPI spec
+func isOpenAPIDocument(body string) bool {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		return false
+	}
+	_, swagger := doc["swagger"]
+	_, openapi := doc["openapi"]
+	return swagger || openapi
+}
+
+// apiSnippet returns the start of a JSON body, collapsed to one line
+func apiSnippet(body string) string {
+	snippet := strings.Join(strings.Fields(body), " ")
+	if len(snippet) > apiSnippetSize {
+		snippet = snippet[:apiSnippetSize] + "..."
+	}
+	return snippet
+}
+
+// applyAPIInfo fills API fields for JSON responses and, when configured,
+// looks for a published OpenAPI/Swagger spec on the same origin
+func (p *HTTPProber) applyAPIInfo(ctx context.Context, info *types.HTTPInfo, baseURL, body string) {
+	if !isJSONResponse(info.ContentType, body) {
+		return
+	}
+
+	info.API = true
+	info.APIType = detectAPIType(info.ContentType, body)
+	info.Snippet = apiSnippet(body)
+
+	if info.APIType == APITypeOpenAPI {
+		return
+	}
+
+	for _, path := range p.apiSpecPaths {
+		specURL := strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+		if p.fetchOpenAPISpec(ctx, specURL) {
+			info.APISpec = specURL
+			return
+		}
+	}
+}
+
+// fetchOpenAPISpec reports whether a URL serves an OpenAPI/Swagger document
+func (p *HTTPProber) fetchOpenAPISpec(ctx context.Context, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false
+	}
+	p.PrepareRequest(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return false
+	}
+
+	return isOpenAPIDocument(string(body))
+}
@@ -0,0 +1,90 @@
+package prober
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+// Parts of a page that change on every request, replaced before hashing so
+// identical pages hash alike
+var volatilePatterns = []*regexp.Regexp{
+	// CSRF tokens and nonces in attributes, hidden inputs and meta tags
+	regexp.MustCompile(`(?i)((?:csrf|xsrf|token|nonce|authenticity)[\w-]*["']?\s*(?:=|:|content=|value=)\s*["']?)[\w+/=.:-]{8,}`),
+	regexp.MustCompile(`(?i)(\bnonce=["'])[^"']+`),
+
+	// ISO 8601 and HTTP dates, times of day
+	regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?`),
+	regexp.MustCompile(`(?i)\b(?:mon|tue|wed|thu|fri|sat|sun), \d{2} \w{3} \d{4} \d{2}:\d{2}:\d{2} \w+`),
+	regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}\b`),
+
+	// Unix timestamps (seconds or milliseconds) and request IDs
+	regexp.MustCompile(`\b1\d{9}(?:\d{3})?\b`),
+	regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+}
+
+// normalizeBody strips volatile values from a body and collapses
+// whitespace
+func normalizeBody(body string) string {
+	for _, pattern := range volatilePatterns {
+		if pattern.NumSubexp() > 0 {
+			body = pattern.ReplaceAllString(body, "${1}")
+		} else {
+			body = pattern.ReplaceAllString(body, "")
+		}
+	}
+	return strings.Join(strings.Fields(body), " ")
+}
+
+// HashBody returns the SHA-256 of the normalized body and its 64-bit
+// simhash; both are empty for a body with no content
+func HashBody(body string) (string, uint64) {
+	normalized := normalizeBody(body)
+	if normalized == "" {
+		return "", 0
+	}
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:]), simhash(normalized)
+}
+
+// simhash computes a 64-bit simhash over word 3-shingles, so bodies that
+// differ in a few words get hashes a few bits apart
+func simhash(text string) uint64 {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) == 0 {
+		return 0
+	}
+
+	const shingle = 3
+	var weights [64]int
+	add := func(feature string) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	if len(words) < shingle {
+		add(strings.Join(words, " "))
+	}
+	for i := 0; i+shingle <= len(words); i++ {
+		add(strings.Join(words[i:i+shingle], " "))
+	}
+
+	var hash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash
+}
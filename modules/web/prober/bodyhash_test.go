@@ -0,0 +1,129 @@
+package prober
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestNormalizeBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "whitespace",
+			body: "<html>\n\t<body>  Hello\r\n   world </body>\n</html>\n",
+			want: "<html> <body> Hello world </body> </html>",
+		},
+		{
+			name: "hidden csrf input",
+			body: `<input type="hidden" name="csrf_token" value="a8F3kLmN0pQrStUv">`,
+			want: `<input type="hidden" name="csrf_token" value="">`,
+		},
+		{
+			name: "csrf meta tag",
+			body: `<meta name="csrf-token" content="Zm9vYmFyYmF6cXV4">`,
+			want: `<meta name="csrf-token" content="">`,
+		},
+		{
+			name: "token in script",
+			body: `var token = "eyJhbGciOiJIUzI1NiJ9.e30";`,
+			want: `var token = "";`,
+		},
+		{
+			name: "script nonce",
+			body: `<script nonce="r4nd0m">init()</script>`,
+			want: `<script nonce="">init()</script>`,
+		},
+		{
+			name: "short values kept",
+			body: `<input name="token" value="abc">`,
+			want: `<input name="token" value="abc">`,
+		},
+		{
+			name: "iso timestamp",
+			body: "Generated 2024-05-01T12:34:56.789Z by server",
+			want: "Generated by server",
+		},
+		{
+			name: "http date and time of day",
+			body: "Last modified Wed, 01 May 2024 12:34:56 GMT at 08:15:00",
+			want: "Last modified at",
+		},
+		{
+			name: "unix time and request id",
+			body: "ts=1714566896123 req=123e4567-e89b-12d3-a456-426614174000",
+			want: "ts= req=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeBody(tt.body); got != tt.want {
+				t.Errorf("normalizeBody = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashBodyIgnoresVolatileParts(t *testing.T) {
+	page := func(token, date, requestID string) string {
+		return `<html><head><meta name="csrf-token" content="` + token + `"></head>
+<body><h1>Welcome to Example</h1><p>Rendered ` + date + `</p>
+<!-- request ` + requestID + ` --></body></html>`
+	}
+
+	first := page("q1w2e3r4t5y6u7i8", "2024-05-01T12:00:00Z", "123e4567-e89b-12d3-a456-426614174000")
+	second := page("z9x8c7v6b5n4m3l2", "2024-05-02T08:30:15Z", "00000000-1111-2222-3333-444444444444")
+	hash1, sim1 := HashBody(first)
+	hash2, sim2 := HashBody(second)
+	if hash1 == "" || hash1 != hash2 || sim1 != sim2 {
+		t.Errorf("same page hashed %s/%x and %s/%x", hash1, sim1, hash2, sim2)
+	}
+
+	// Only whitespace differs
+	if hash, _ := HashBody("<p>Welcome</p>\n\n<p>home</p>"); hash != mustHash(t, "<p>Welcome</p> <p>home</p>") {
+		t.Error("reflowed page hashed differently")
+	}
+
+	// Different content does change the hash
+	other := page("q1w2e3r4t5y6u7i8", "2024-05-01T12:00:00Z", "123e4567-e89b-12d3-a456-426614174000")
+	other = other[:len(other)-len("</body></html>")] + "<p>Maintenance tonight</p></body></html>"
+	if hash, _ := HashBody(other); hash == hash1 {
+		t.Error("page with new content hashed like the original")
+	}
+}
+
+func TestHashBodyEmpty(t *testing.T) {
+	for _, body := range []string{"", " \n\t ", "2024-05-01T12:00:00Z"} {
+		if hash, sim := HashBody(body); hash != "" || sim != 0 {
+			t.Errorf("HashBody(%q) = %q, %x; want nothing for a body without content", body, hash, sim)
+		}
+	}
+}
+
+func TestSimhashDistance(t *testing.T) {
+	base := "the quick brown fox jumps over the lazy dog while the cat sleeps on the warm mat near the door of the old house"
+	near := "the quick brown fox jumps over the lazy dog while the cat naps on the warm mat near the door of the old house"
+	far := "quarterly revenue grew in every region as new customers signed annual contracts for the enterprise plan"
+
+	nearDistance := bits.OnesCount64(simhash(base) ^ simhash(near))
+	farDistance := bits.OnesCount64(simhash(base) ^ simhash(far))
+	if nearDistance >= farDistance {
+		t.Errorf("one changed word is %d bits away, unrelated text %d; want nearer", nearDistance, farDistance)
+	}
+	if simhash(base) != simhash(base) {
+		t.Error("simhash not deterministic")
+	}
+}
+
+// mustHash returns the SHA-256 HashBody gives body
+func mustHash(t *testing.T, body string) string {
+	t.Helper()
+	hash, _ := HashBody(body)
+	if hash == "" {
+		t.Fatalf("no hash for %q", body)
+	}
+	return hash
+}
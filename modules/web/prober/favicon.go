@@ -0,0 +1,155 @@
+package prober
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"math/bits"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// maxFaviconSize is how much of a favicon is read; larger bodies aren't
+// icons
+const maxFaviconSize = 1024 * 1024
+
+// faviconCache remembers favicon hashes by URL, so a host probed again in
+// the same scan isn't fetched twice
+type faviconCache struct {
+	mu     sync.Mutex
+	hashes map[string]int32
+}
+
+// lookup returns the cached hash of a favicon URL, if fetched before
+func (c *faviconCache) lookup(url string) (int32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hash, ok := c.hashes[url]
+	return hash, ok
+}
+
+// store caches a favicon URL's hash (0 = none)
+func (c *faviconCache) store(url string, hash int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hashes == nil {
+		c.hashes = make(map[string]int32)
+	}
+	c.hashes[url] = hash
+}
+
+// applyFavicon fetches /favicon.ico from the origin that answered and
+// records its hash. Missing icons and soft-404 pages leave it unset.
+func (p *HTTPProber) applyFavicon(ctx context.Context, info *types.HTTPInfo, baseURL string) {
+	url := strings.TrimSuffix(baseURL, "/") + "/favicon.ico"
+
+	if hash, ok := p.favicons.lookup(url); ok {
+		info.FaviconHash = hash
+		return
+	}
+
+	hash := p.fetchFaviconHash(ctx, url)
+	if ctx.Err() != nil {
+		// Interrupted, not missing: don't cache
+		return
+	}
+	p.favicons.store(url, hash)
+	info.FaviconHash = hash
+}
+
+// fetchFaviconHash returns the hash of the icon at url, or 0 when there is
+// none
+func (p *HTTPProber) fetchFaviconHash(ctx context.Context, url string) int32 {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0
+	}
+	p.PrepareRequest(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+
+	// Servers answering every path with their HTML page have no icon
+	if resp.StatusCode != http.StatusOK || strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/html") {
+		return 0
+	}
+
+	icon, err := io.ReadAll(io.LimitReader(resp.Body, maxFaviconSize))
+	if err != nil || len(icon) == 0 {
+		return 0
+	}
+	return FaviconHash(icon)
+}
+
+// FaviconHash returns the favicon hash Shodan indexes as
+// http.favicon.hash: MurmurHash3 (x86, 32-bit, seed 0) of the icon's
+// base64 encoding, wrapped at 76 characters with a trailing newline
+func FaviconHash(icon []byte) int32 {
+	encoded := base64.StdEncoding.EncodeToString(icon)
+
+	var wrapped strings.Builder
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76])
+		wrapped.WriteByte('\n')
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded)
+	wrapped.WriteByte('\n')
+
+	return int32(murmur3([]byte(wrapped.String()), 0))
+}
+
+// murmur3 computes the 32-bit x86 MurmurHash3 of data
+func murmur3(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+
+	h := seed
+	length := len(data)
+
+	for len(data) >= 4 {
+		k := binary.LittleEndian.Uint32(data)
+		data = data[4:]
+
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	switch len(data) {
+	case 3:
+		k ^= uint32(data[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(data[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(data[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(length)
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
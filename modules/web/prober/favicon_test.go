@@ -0,0 +1,140 @@
+package prober
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/types"
+)
+
+func TestMurmur3(t *testing.T) {
+	tests := []struct {
+		data string
+		seed uint32
+		want uint32
+	}{
+		{"", 0, 0},
+		{"", 1, 0x514e28b7},
+		{"hello", 0, 0x248bfa47},
+		{"The quick brown fox jumps over the lazy dog", 0, 0x2e4ff723},
+	}
+
+	for _, tt := range tests {
+		if got := murmur3([]byte(tt.data), tt.seed); got != tt.want {
+			t.Errorf("murmur3(%q, %d) = %#x, want %#x", tt.data, tt.seed, got, tt.want)
+		}
+	}
+}
+
+// testIcon is bytes 0..99: long enough that its base64 wraps at 76
+// characters, as Shodan's encoding does
+func testIcon() []byte {
+	icon := make([]byte, 100)
+	for i := range icon {
+		icon[i] = byte(i)
+	}
+	return icon
+}
+
+// testIconHash is mmh3.hash(base64.encodebytes(testIcon())) in Python
+const testIconHash = -1165240594
+
+func TestFaviconHash(t *testing.T) {
+	if got := FaviconHash(testIcon()); got != testIconHash {
+		t.Errorf("FaviconHash = %d, want %d", got, testIconHash)
+	}
+}
+
+// faviconServer serves a landing page, and /favicon.ico with favicon,
+// counting favicon requests
+func faviconServer(t *testing.T, favicon http.HandlerFunc) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/favicon.ico" {
+			atomic.AddInt32(&fetches, 1)
+			favicon(w, r)
+			return
+		}
+		w.Write([]byte("<html><title>Landing</title></html>"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+func TestProbeHashesFavicon(t *testing.T) {
+	server, fetches := faviconServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/x-icon")
+		w.Write(testIcon())
+	})
+
+	p := newTestProber(config.HTTPConfig{Favicon: true})
+	for i := 0; i < 2; i++ {
+		info := p.Probe(context.Background(), hostOf(server))
+		if info == nil {
+			t.Fatal("probe failed")
+		}
+		if info.FaviconHash != testIconHash {
+			t.Errorf("probe %d: FaviconHash = %d, want %d", i, info.FaviconHash, testIconHash)
+		}
+	}
+	if n := atomic.LoadInt32(fetches); n != 1 {
+		t.Errorf("favicon fetched %d times, want once", n)
+	}
+
+	sub := &types.Subdomain{Domain: "127.0.0.1"}
+	Apply(sub, p.Probe(context.Background(), hostOf(server)), nil)
+	if got := sub.Metadata["favicon_hash"]; got != int32(testIconHash) {
+		t.Errorf("favicon_hash metadata = %v, want %d", got, testIconHash)
+	}
+}
+
+func TestProbeWithoutFavicon(t *testing.T) {
+	tests := []struct {
+		name    string
+		favicon http.HandlerFunc
+	}{
+		{"not found", http.NotFound},
+		{"soft 404 page", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><title>Landing</title></html>"))
+		}},
+		{"empty icon", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/x-icon")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, fetches := faviconServer(t, tt.favicon)
+
+			p := newTestProber(config.HTTPConfig{Favicon: true})
+			info := p.Probe(context.Background(), hostOf(server))
+			if info == nil {
+				t.Fatal("probe failed")
+			}
+			if info.FaviconHash != 0 {
+				t.Errorf("FaviconHash = %d, want none", info.FaviconHash)
+			}
+			if atomic.LoadInt32(fetches) == 0 {
+				t.Error("favicon never requested")
+			}
+		})
+	}
+}
+
+func TestProbeSkipsFaviconWhenDisabled(t *testing.T) {
+	server, fetches := faviconServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testIcon())
+	})
+
+	info := newTestProber(config.HTTPConfig{}).Probe(context.Background(), hostOf(server))
+	if info == nil || info.FaviconHash != 0 || atomic.LoadInt32(fetches) != 0 {
+		t.Errorf("favicon fetched with favicon hashing off")
+	}
+}
@@ -0,0 +1,102 @@
+package prober
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+)
+
+func TestHeaderHosts(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   []string
+	}{
+		{
+			name: "csp",
+			header: http.Header{"Content-Security-Policy": {
+				"default-src 'self' https://cdn.example.com; img-src *.media.example.com data:; report-uri https://csp.example.net/report",
+			}},
+			want: []string{"cdn.example.com", "csp.example.net", "media.example.com"},
+		},
+		{
+			name:   "csp report-only",
+			header: http.Header{"Content-Security-Policy-Report-Only": {"script-src https://js.example.com"}},
+			want:   []string{"js.example.com"},
+		},
+		{
+			name:   "cors",
+			header: http.Header{"Access-Control-Allow-Origin": {"https://app.example.com"}},
+			want:   []string{"app.example.com"},
+		},
+		{
+			name:   "cors wildcard",
+			header: http.Header{"Access-Control-Allow-Origin": {"*"}},
+		},
+		{
+			name: "link",
+			header: http.Header{"Link": {
+				`<https://assets.example.com/site.css>; rel=preload; as=style`,
+				`<https://api.example.com/>; rel=preconnect`,
+			}},
+			want: []string{"api.example.com", "assets.example.com"},
+		},
+		{
+			name:   "location",
+			header: http.Header{"Location": {"https://sso.example.com/login?next=/"}},
+			want:   []string{"sso.example.com"},
+		},
+		{
+			name:   "other headers ignored",
+			header: http.Header{"Server": {"edge.example.com"}, "Via": {"1.1 proxy.example.com"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := headerHosts(tt.header, nil)
+			sort.Strings(got)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("hosts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeCollectsHeaderHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src https://cdn.example.com")
+		w.Header().Set("Access-Control-Allow-Origin", "https://app.example.com")
+		w.Header().Set("Link", "<https://assets.example.com/app.js>; rel=preload")
+		w.Write([]byte("<title>Home</title>"))
+	}))
+	defer server.Close()
+
+	// The redirect off-host isn't followed; its Location still names a host
+	p := newTestProber(config.HTTPConfig{MaxRedirects: 0})
+
+	info := p.Probe(context.Background(), hostOf(server))
+	if info == nil {
+		t.Fatal("probe failed")
+	}
+	got := append([]string(nil), info.HeaderHosts...)
+	sort.Strings(got)
+	if want := "app.example.com assets.example.com cdn.example.com"; strings.Join(got, " ") != want {
+		t.Errorf("header hosts = %v, want %s", got, want)
+	}
+
+	redirected := httptest.NewServer(http.RedirectHandler("https://sso.example.com/login", http.StatusFound))
+	defer redirected.Close()
+	info = p.Probe(context.Background(), hostOf(redirected))
+	if info == nil {
+		t.Fatal("probe of the redirecting host failed")
+	}
+	if len(info.HeaderHosts) != 1 || info.HeaderHosts[0] != "sso.example.com" {
+		t.Errorf("header hosts = %v, want the redirect target", info.HeaderHosts)
+	}
+}
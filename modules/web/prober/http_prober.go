@@ -3,13 +3,19 @@ package prober
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	neturl "net/url"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/sources"
+	"github.com/yourusername/usr/internal/stealth"
 	"github.com/yourusername/usr/internal/types"
 	"go.uber.org/zap"
 )
@@ -19,60 +25,252 @@ type HTTPProber struct {
 	client     *http.Client
 	logger     *zap.Logger
 	maxWorkers int
+	
+	// Stealth mode: jitter, rotating User-Agents (optional)
+	stealth *stealth.Profile
+	
+	// Paths checked for an OpenAPI/Swagger spec on JSON API hosts
+	apiSpecPaths []string
+	
+	// Configured User-Agent and extra headers (optional)
+	agent   string
+	headers map[string]string
+	
+	// Favicon hashing, cached by favicon URL
+	favicon  bool
+	favicons faviconCache
+	
+	// Technology fingerprints
+	fingerprinter *Fingerprinter
+}
+
+// redirectStateKey carries per-probe redirect tracking through the request context
+type redirectStateKey struct{}
+
+// redirectState records what happened while following redirects
+type redirectState struct {
+	loop bool
+	
+	// Location headers of the redirects followed
+	locations []string
+	
+	// URLs redirected to, in order
+	chain []string
 }
 
+// defaultMaxRedirects bounds the redirects followed by injected clients,
+// as net/http does for clients without a policy
+const defaultMaxRedirects = 10
+
 // NewHTTPProber creates a new HTTP prober
-func NewHTTPProber(logger *zap.Logger, maxWorkers int) *HTTPProber {
+func NewHTTPProber(cfg *config.HTTPConfig, logger *zap.Logger, maxWorkers int) *HTTPProber {
+	p := newHTTPProber(newHTTPClient(cfg), cfg.MaxRedirects, logger, maxWorkers)
+	p.apiSpecPaths = cfg.APISpecPaths
+	p.SetHeaders(cfg.UserAgent, cfg.Headers)
+	p.favicon = cfg.Favicon
+	
+	// Community fingerprints extend the built-in rules
+	if len(cfg.Fingerprints) > 0 {
+		rules := DefaultRules()
+		for _, path := range cfg.Fingerprints {
+			loaded, err := LoadWappalyzer(path)
+			if err != nil {
+				logger.Warn("Failed to load fingerprints", zap.String("path", path), zap.Error(err))
+				continue
+			}
+			rules = append(rules, loaded...)
+		}
+		p.fingerprinter = NewFingerprinter(rules, logger)
+	}
+	return p
+}
+
+// NewHTTPProberWithClient creates an HTTP prober that sends requests through
+// the given client, e.g. one wrapping a stub RoundTripper or an httptest
+// server. The prober uses a copy of the client that records redirect
+// chains and stops on loops; the client's own redirect policy still applies.
+func NewHTTPProberWithClient(client *http.Client, logger *zap.Logger, maxWorkers int) *HTTPProber {
+	return newHTTPProber(client, defaultMaxRedirects, logger, maxWorkers)
+}
+
+// newHTTPProber creates a prober over a copy of client with redirect
+// tracking in front of the client's CheckRedirect
+func newHTTPProber(client *http.Client, maxRedirects int, logger *zap.Logger, maxWorkers int) *HTTPProber {
+	tracked := *client
+	tracked.CheckRedirect = trackRedirects(maxRedirects, client.CheckRedirect)
+	
 	return &HTTPProber{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true, // For reconnaissance purposes
-				},
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     30 * time.Second,
-			},
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				// Allow up to 3 redirects
-				if len(via) >= 3 {
-					return http.ErrUseLastResponse
-				}
-				return nil
+		client:        &tracked,
+		logger:        logger,
+		maxWorkers:    maxWorkers,
+		fingerprinter: NewFingerprinter(DefaultRules(), logger),
+	}
+}
+
+// newHTTPClient builds the default probing client; the prober adds the
+// redirect policy
+func newHTTPClient(cfg *config.HTTPConfig) *http.Client {
+	return &http.Client{
+		Timeout: time.Duration(cfg.Timeout) * time.Second,
+		Transport: &http.Transport{
+			DialContext: newDialer(cfg).DialContext,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true, // For reconnaissance purposes
 			},
+			TLSHandshakeTimeout:   seconds(cfg.TLSHandshakeTimeout),
+			ResponseHeaderTimeout: seconds(cfg.ResponseHeaderTimeout),
+			ExpectContinueTimeout: 1 * time.Second,
+			
+			// A custom TLS config turns off HTTP/2 unless asked for explicitly
+			ForceAttemptHTTP2: cfg.HTTP2,
+			
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     30 * time.Second,
 		},
-		logger:     logger,
-		maxWorkers: maxWorkers,
 	}
 }
 
+// newDialer builds the probing dialer; dial_timeout bounds the TCP connect
+// alone, so a slow handshake or response doesn't eat into it
+func newDialer(cfg *config.HTTPConfig) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   seconds(cfg.DialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+}
+
+// trackRedirects returns a redirect policy following at most maxRedirects
+// redirects, stopping on cycles and recording the Location headers and
+// URLs of the probe's redirectState. A non-nil next is consulted before a
+// redirect is followed.
+func trackRedirects(maxRedirects int, next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		state, _ := req.Context().Value(redirectStateKey{}).(*redirectState)
+		if state != nil && req.Response != nil {
+			state.locations = append(state.locations, req.Response.Header.Values("Location")...)
+		}
+		
+		// Stop on cycles (A -> B -> A) instead of burning the budget
+		target := req.URL.String()
+		for _, prev := range via {
+			if loopKey(prev.URL) == loopKey(req.URL) {
+				if state != nil {
+					state.loop = true
+				}
+				return http.ErrUseLastResponse
+			}
+		}
+		
+		// via holds the original request plus each redirect followed
+		if len(via) > maxRedirects {
+			return http.ErrUseLastResponse
+		}
+		
+		if next != nil {
+			if err := next(req, via); err != nil {
+				return err
+			}
+		}
+		
+		if state != nil {
+			state.chain = append(state.chain, target)
+		}
+		return nil
+	}
+}
+
+// loopKey is a URL as compared for redirect loops: probes start at
+// "https://host", which a redirect back to "/" must match
+func loopKey(u *neturl.URL) string {
+	if u.Path != "" {
+		return u.String()
+	}
+	root := *u
+	root.Path = "/"
+	return root.String()
+}
+
+// Client returns the prober's HTTP client so follow-up checks share its
+// transport, timeouts and redirect handling
+func (p *HTTPProber) Client() *http.Client {
+	return p.client
+}
+
+// SetHeaders sets the User-Agent (empty = default) and the headers sent
+// with every request
+func (p *HTTPProber) SetHeaders(userAgent string, headers map[string]string) {
+	p.agent = userAgent
+	p.headers = headers
+}
+
+// PrepareRequest sets the User-Agent and configured headers on a request.
+// A Host header becomes req.Host, since Go ignores Host in the header map.
+func (p *HTTPProber) PrepareRequest(req *http.Request) {
+	req.Header.Set("User-Agent", p.userAgent())
+	for name, value := range p.headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+}
+
+// seconds converts a config value in seconds to a duration (0 = no limit)
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+// SetStealth enables stealth behavior: fewer workers, a random delay before
+// each request and a rotating browser User-Agent. The delay is applied by
+// the client's transport, so it also paces path checks, crawling and every
+// other user of Client().
+func (p *HTTPProber) SetStealth(profile *stealth.Profile) {
+	p.stealth = profile
+	p.maxWorkers = profile.Concurrency(p.maxWorkers)
+	p.client.Transport = profile.Transport(p.client.Transport)
+}
+
 // Probe performs HTTP/HTTPS probing on a single subdomain
 func (p *HTTPProber) Probe(ctx context.Context, subdomain string) *types.HTTPInfo {
-	// Try HTTPS first, then HTTP
-	if info := p.probeScheme(ctx, "https", subdomain); info != nil {
-		return info
+	info, _ := p.probe(ctx, subdomain)
+	return info
+}
+
+// ProbeWithTLS probes a subdomain and also returns the certificate
+// presented over HTTPS, if any
+func (p *HTTPProber) ProbeWithTLS(ctx context.Context, subdomain string) (*types.HTTPInfo, *types.TLSInfo) {
+	return p.probe(ctx, subdomain)
+}
+
+// probe tries HTTPS first, then HTTP, returning the certificate seen over HTTPS
+func (p *HTTPProber) probe(ctx context.Context, subdomain string) (*types.HTTPInfo, *types.TLSInfo) {
+	if info, tlsInfo := p.probeScheme(ctx, "https", subdomain); info != nil {
+		return info, tlsInfo
 	}
 	
 	return p.probeScheme(ctx, "http", subdomain)
 }
 
 // probeScheme probes a specific scheme (http or https)
-func (p *HTTPProber) probeScheme(ctx context.Context, scheme, subdomain string) *types.HTTPInfo {
+func (p *HTTPProber) probeScheme(ctx context.Context, scheme, subdomain string) (*types.HTTPInfo, *types.TLSInfo) {
 	url := fmt.Sprintf("%s://%s", scheme, subdomain)
 	
 	startTime := time.Now()
 	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	redirects := &redirectState{}
+	
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, redirectStateKey{}, redirects), "GET", url, nil)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 	
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; USR/1.0; +https://github.com/usr)")
+	p.PrepareRequest(req)
 	
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 	defer resp.Body.Close()
 	
@@ -91,9 +289,15 @@ func (p *HTTPProber) probeScheme(ctx context.Context, scheme, subdomain string)
 	body := string(bodyBytes)
 	
 	info := &types.HTTPInfo{
+		URL:          url,
 		StatusCode:   resp.StatusCode,
 		ResponseTime: responseTime,
 		Headers:      make(map[string]string),
+		RedirectLoop: redirects.loop,
+		FinalURL:     resp.Request.URL.String(),
+	}
+	if len(redirects.chain) > 0 {
+		info.RedirectChain = append([]string{url}, redirects.chain...)
 	}
 	
 	// Extract key headers
@@ -104,6 +308,14 @@ func (p *HTTPProber) probeScheme(ctx context.Context, scheme, subdomain string)
 	importantHeaders := []string{
 		"Server", "X-Powered-By", "X-AspNet-Version",
 		"X-Generator", "X-Drupal-Cache", "X-Frame-Options",
+		
+		// CDN/WAF fingerprints
+		"CF-RAY", "X-Amz-Cf-Id", "X-Amz-Cf-Pop", "Via", "X-Served-By",
+		"X-Cache", "X-CDN", "X-Iinfo", "X-Sucuri-ID", "X-Azure-Ref",
+		"X-Akamai-Transformed", "X-Fastly-Request-ID",
+		
+		// Access control (basic auth prompts mark admin interfaces)
+		"WWW-Authenticate",
 	}
 	
 	for _, header := range importantHeaders {
@@ -112,19 +324,106 @@ func (p *HTTPProber) probeScheme(ctx context.Context, scheme, subdomain string)
 		}
 	}
 	
+	// Hostnames leaked by redirects, CSP, CORS and Link headers
+	info.HeaderHosts = headerHosts(resp.Header, redirects.locations)
+	
 	// Extract title
 	info.Title = extractTitle(body)
 	
+	// Fingerprints for spotting hosts serving the same page
+	info.BodyLength = len(bodyBytes)
+	info.BodyHash, info.BodySimhash = HashBody(body)
+	
 	// Detect technologies
-	info.Technologies = detectTechnologies(body, resp.Header)
+	info.TechDetails = p.fingerprinter.Detect(resp.Header, body)
+	info.Technologies = TechnologyNames(info.TechDetails)
+	
+	// Parking/placeholder pages are noise for most users
+	info.Parked = detectParked(body, info.Title, resp.Header, info.FinalURL)
+	
+	// JSON APIs have no title; record what kind of API answered instead
+	p.applyAPIInfo(ctx, info, url, body)
+	
+	if p.favicon {
+		p.applyFavicon(ctx, info, url)
+	}
+	
+	return info, extractTLSInfo(resp.TLS)
+}
+
+// discoveryHeaders name other hosts of the same organization; CSP in
+// particular often lists every first-party domain a page may load from
+var discoveryHeaders = []string{
+	"Location",
+	"Content-Security-Policy",
+	"Content-Security-Policy-Report-Only",
+	"Access-Control-Allow-Origin",
+	"Link",
+}
+
+// headerHosts extracts the hostnames named in discovery headers and in the
+// Location headers of redirects followed on the way
+func headerHosts(header http.Header, locations []string) []string {
+	values := append([]string{}, locations...)
+	for _, name := range discoveryHeaders {
+		values = append(values, header.Values(name)...)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return sources.ExtractHosts(strings.Join(values, "\n"))
+}
+
+// userAgent returns the User-Agent for the next request
+func (p *HTTPProber) userAgent() string {
+	if p.agent != "" {
+		return p.agent
+	}
+	if p.stealth != nil {
+		return p.stealth.UserAgent()
+	}
+	return "Mozilla/5.0 (compatible; USR/1.0; +https://github.com/usr)"
+}
+
+// extractTLSInfo summarizes the leaf certificate of an HTTPS response.
+// Verification happens here because probing skips it to reach every host.
+func extractTLSInfo(state *tls.ConnectionState) *types.TLSInfo {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	
+	leaf := state.PeerCertificates[0]
+	
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       state.ServerName,
+		Intermediates: intermediates,
+	})
+	
+	info := &types.TLSInfo{
+		Valid:     err == nil,
+		Subject:   leaf.Subject.CommonName,
+		Issuer:    leaf.Issuer.CommonName,
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+		SANs:      leaf.DNSNames,
+	}
+	
+	if len(leaf.Subject.Organization) > 0 {
+		info.Organization = leaf.Subject.Organization[0]
+	}
 	
 	return info
 }
 
 // ProbeBatch probes multiple subdomains concurrently
-func (p *HTTPProber) ProbeBatch(ctx context.Context, subdomains []*types.Subdomain) {
+func (p *HTTPProber) ProbeBatch(ctx context.Context, subdomains []*types.Subdomain) error {
 	if len(subdomains) == 0 {
-		return
+		return nil
 	}
 	
 	p.logger.Info("Starting HTTP probing",
@@ -132,36 +431,79 @@ func (p *HTTPProber) ProbeBatch(ctx context.Context, subdomains []*types.Subdoma
 		zap.Int("workers", p.maxWorkers),
 	)
 	
-	workChan := make(chan *types.Subdomain, len(subdomains))
-	for _, sub := range subdomains {
-		workChan <- sub
-	}
-	close(workChan)
-	
-	var wg sync.WaitGroup
-	for i := 0; i < p.maxWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for sub := range workChan {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-					if sub.Validated && len(sub.IP) > 0 {
-						info := p.Probe(ctx, sub.Domain)
-						if info != nil {
-							sub.HTTP = info
-						}
-					}
-				}
-			}
-		}()
-	}
-	
-	wg.Wait()
+	err := pool.Run(ctx, subdomains, p.maxWorkers, func(ctx context.Context, sub *types.Subdomain) {
+		if sub.Validated && len(sub.IP) > 0 {
+			info, tlsInfo := p.probe(ctx, sub.Domain)
+			Apply(sub, info, tlsInfo)
+		}
+	})
 	
 	p.logger.Info("HTTP probing complete")
+	return err
+}
+
+// Apply stores probe results on a subdomain
+func Apply(sub *types.Subdomain, info *types.HTTPInfo, tlsInfo *types.TLSInfo) {
+	if info != nil {
+		sub.HTTP = info
+		
+		if info.RedirectLoop {
+			if sub.Metadata == nil {
+				sub.Metadata = make(map[string]interface{})
+			}
+			sub.Metadata["redirect_loop"] = true
+		}
+		
+		// Hosts that only send visitors elsewhere, often to the apex
+		if host := RedirectHost(info, sub.Domain); host != "" {
+			if sub.Metadata == nil {
+				sub.Metadata = make(map[string]interface{})
+			}
+			sub.Metadata["redirects_to"] = host
+		}
+		
+		if info.FaviconHash != 0 {
+			if sub.Metadata == nil {
+				sub.Metadata = make(map[string]interface{})
+			}
+			sub.Metadata["favicon_hash"] = info.FaviconHash
+		}
+		
+		if info.Parked {
+			if sub.Metadata == nil {
+				sub.Metadata = make(map[string]interface{})
+			}
+			sub.Metadata["parked"] = true
+		}
+		
+		if info.API {
+			if sub.Metadata == nil {
+				sub.Metadata = make(map[string]interface{})
+			}
+			sub.Metadata["api"] = true
+			sub.Metadata["api_type"] = info.APIType
+		}
+	}
+	if tlsInfo != nil {
+		sub.TLS = tlsInfo
+	}
+}
+
+// RedirectHost returns the host a probe was redirected to when it differs
+// from domain, or "" when the response came from domain itself
+func RedirectHost(info *types.HTTPInfo, domain string) string {
+	if info == nil || len(info.RedirectChain) == 0 {
+		return ""
+	}
+	final, err := neturl.Parse(info.FinalURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(final.Hostname())
+	if host == "" || host == strings.ToLower(domain) {
+		return ""
+	}
+	return host
 }
 
 // extractTitle extracts the <title> tag from HTML
@@ -188,80 +530,3 @@ func extractTitle(html string) string {
 	
 	return title
 }
-
-// detectTechnologies identifies technologies used by the web application
-func detectTechnologies(body string, headers http.Header) []string {
-	var technologies []string
-	seen := make(map[string]bool)
-	
-	bodyLower := strings.ToLower(body)
-	
-	// Check headers
-	if server := headers.Get("Server"); server != "" {
-		if strings.Contains(server, "nginx") {
-			technologies = addUnique(technologies, seen, "nginx")
-		}
-		if strings.Contains(server, "Apache") {
-			technologies = addUnique(technologies, seen, "Apache")
-		}
-		if strings.Contains(server, "IIS") {
-			technologies = addUnique(technologies, seen, "IIS")
-		}
-	}
-	
-	if poweredBy := headers.Get("X-Powered-By"); poweredBy != "" {
-		if strings.Contains(poweredBy, "PHP") {
-			technologies = addUnique(technologies, seen, "PHP")
-		}
-		if strings.Contains(poweredBy, "ASP.NET") {
-			technologies = addUnique(technologies, seen, "ASP.NET")
-		}
-	}
-	
-	// Check body for common frameworks
-	frameworks := map[string]string{
-		"wp-content":         "WordPress",
-		"wp-includes":        "WordPress",
-		"joomla":             "Joomla",
-		"drupal":             "Drupal",
-		"__next":             "Next.js",
-		"_next":              "Next.js",
-		"nuxt":               "Nuxt.js",
-		"react":              "React",
-		"angular":            "Angular",
-		"vue.js":             "Vue.js",
-		"jquery":             "jQuery",
-		"bootstrap":          "Bootstrap",
-		"tailwind":           "Tailwind CSS",
-		"django":             "Django",
-		"laravel":            "Laravel",
-		"symfony":            "Symfony",
-		"spring":             "Spring",
-		"express":            "Express",
-		"flask":              "Flask",
-		"rails":              "Ruby on Rails",
-		"gatsby":             "Gatsby",
-		"shopify":            "Shopify",
-		"magento":            "Magento",
-		"wix":                "Wix",
-		"squarespace":        "Squarespace",
-		"cloudflare":         "Cloudflare",
-	}
-	
-	for signature, tech := range frameworks {
-		if strings.Contains(bodyLower, signature) {
-			technologies = addUnique(technologies, seen, tech)
-		}
-	}
-	
-	return technologies
-}
-
-// addUnique adds a technology if not already present
-func addUnique(slice []string, seen map[string]bool, tech string) []string {
-	if !seen[tech] {
-		slice = append(slice, tech)
-		seen[tech] = true
-	}
-	return slice
-}
\ No newline at end of file
@@ -0,0 +1,366 @@
+package prober
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/stealth"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// redirectServer serves a redirect for each path in hops, and 200 with a
+// small page everywhere else
+func redirectServer(t *testing.T, hops map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target, ok := hops[r.URL.Path]; ok {
+			http.Redirect(w, r, target, http.StatusFound)
+			return
+		}
+		w.Write([]byte("<html><title>Landing</title></html>"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// hostOf returns the host:port of a test server, the name the prober probes
+func hostOf(server *httptest.Server) string {
+	return strings.TrimPrefix(strings.TrimPrefix(server.URL, "http://"), "https://")
+}
+
+func TestInjectedClientRecordsRedirectChain(t *testing.T) {
+	server := redirectServer(t, map[string]string{
+		"/":  "/b",
+		"/b": "/c",
+	})
+
+	p := NewHTTPProberWithClient(server.Client(), zap.NewNop(), 1)
+	info := p.Probe(context.Background(), hostOf(server))
+	if info == nil {
+		t.Fatal("probe failed")
+	}
+
+	want := []string{server.URL, server.URL + "/b", server.URL + "/c"}
+	if strings.Join(info.RedirectChain, " ") != strings.Join(want, " ") {
+		t.Errorf("redirect chain = %v, want %v", info.RedirectChain, want)
+	}
+	if info.FinalURL != server.URL+"/c" {
+		t.Errorf("final URL = %q, want %q", info.FinalURL, server.URL+"/c")
+	}
+	if info.StatusCode != http.StatusOK || info.Title != "Landing" {
+		t.Errorf("final response = %d %q, want 200 Landing", info.StatusCode, info.Title)
+	}
+}
+
+func TestInjectedClientKeepsItsRedirectPolicy(t *testing.T) {
+	server := redirectServer(t, map[string]string{"/": "/next"})
+
+	client := server.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	p := NewHTTPProberWithClient(client, zap.NewNop(), 1)
+	info := p.Probe(context.Background(), hostOf(server))
+	if info == nil {
+		t.Fatal("probe failed")
+	}
+
+	if info.StatusCode != http.StatusFound || len(info.RedirectChain) != 0 {
+		t.Errorf("got %d with chain %v, want the unfollowed 302", info.StatusCode, info.RedirectChain)
+	}
+	if info.FinalURL != server.URL {
+		t.Errorf("final URL = %q, want %q", info.FinalURL, server.URL)
+	}
+
+	// The prober works on a copy; the caller's client is untouched
+	if client.CheckRedirect == nil || p.Client() == client {
+		t.Error("injected client was modified")
+	}
+}
+
+// newTestProber builds a prober from cfg, with the defaults tests rely on
+func newTestProber(cfg config.HTTPConfig) *HTTPProber {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5
+	}
+	return NewHTTPProber(&cfg, zap.NewNop(), 1)
+}
+
+func TestRedirectLoopStops(t *testing.T) {
+	server := redirectServer(t, map[string]string{
+		"/":      "/login",
+		"/login": "/sso",
+		"/sso":   "/",
+	})
+
+	p := newTestProber(config.HTTPConfig{MaxRedirects: 10})
+	info := p.Probe(context.Background(), hostOf(server))
+	if info == nil {
+		t.Fatal("probe failed")
+	}
+
+	if !info.RedirectLoop {
+		t.Error("redirect loop not flagged")
+	}
+	if info.StatusCode != http.StatusFound {
+		t.Errorf("status = %d, want the last redirect's 302", info.StatusCode)
+	}
+	want := []string{server.URL, server.URL + "/login", server.URL + "/sso"}
+	if strings.Join(info.RedirectChain, " ") != strings.Join(want, " ") {
+		t.Errorf("redirect chain = %v, want %v", info.RedirectChain, want)
+	}
+}
+
+func TestMaxRedirectsHonoured(t *testing.T) {
+	server := redirectServer(t, map[string]string{
+		"/":  "/1",
+		"/1": "/2",
+		"/2": "/3",
+		"/3": "/4",
+	})
+
+	tests := []struct {
+		maxRedirects int
+		final        string
+		status       int
+	}{
+		{0, "", http.StatusFound},
+		{2, "/2", http.StatusFound},
+		{4, "/4", http.StatusOK},
+		{10, "/4", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		p := newTestProber(config.HTTPConfig{MaxRedirects: tt.maxRedirects})
+		info := p.Probe(context.Background(), hostOf(server))
+		if info == nil {
+			t.Fatalf("max_redirects %d: probe failed", tt.maxRedirects)
+		}
+
+		if info.FinalURL != server.URL+tt.final || info.StatusCode != tt.status {
+			t.Errorf("max_redirects %d: ended at %s with %d, want %s with %d",
+				tt.maxRedirects, info.FinalURL, info.StatusCode, server.URL+tt.final, tt.status)
+		}
+		if info.RedirectLoop {
+			t.Errorf("max_redirects %d: flagged as a loop", tt.maxRedirects)
+		}
+	}
+}
+
+func TestRedirectChainOfThreeHops(t *testing.T) {
+	server := redirectServer(t, map[string]string{
+		"/":  "/a",
+		"/a": "/b",
+		"/b": "/c",
+	})
+
+	p := newTestProber(config.HTTPConfig{MaxRedirects: 10})
+	info := p.Probe(context.Background(), hostOf(server))
+	if info == nil {
+		t.Fatal("probe failed")
+	}
+
+	want := []string{server.URL, server.URL + "/a", server.URL + "/b", server.URL + "/c"}
+	if strings.Join(info.RedirectChain, " ") != strings.Join(want, " ") {
+		t.Errorf("redirect chain = %v, want %v", info.RedirectChain, want)
+	}
+	if info.FinalURL != server.URL+"/c" || info.StatusCode != http.StatusOK {
+		t.Errorf("ended at %s with %d, want %s/c with 200", info.FinalURL, info.StatusCode, server.URL)
+	}
+	if host := RedirectHost(info, "127.0.0.1"); host != "" {
+		t.Errorf("redirects within the host reported as leaving it for %q", host)
+	}
+}
+
+func TestOffHostRedirect(t *testing.T) {
+	landing := redirectServer(t, nil)
+	_, port, _ := strings.Cut(hostOf(landing), ":")
+	elsewhere := "http://localhost:" + port + "/welcome"
+
+	server := redirectServer(t, map[string]string{"/": elsewhere})
+
+	p := newTestProber(config.HTTPConfig{MaxRedirects: 10})
+	info := p.Probe(context.Background(), hostOf(server))
+	if info == nil {
+		t.Fatal("probe failed")
+	}
+
+	if info.FinalURL != elsewhere || info.Title != "Landing" {
+		t.Errorf("ended at %s (%q), want %s", info.FinalURL, info.Title, elsewhere)
+	}
+	if want := []string{server.URL, elsewhere}; strings.Join(info.RedirectChain, " ") != strings.Join(want, " ") {
+		t.Errorf("redirect chain = %v, want %v", info.RedirectChain, want)
+	}
+
+	sub := &types.Subdomain{Domain: "127.0.0.1"}
+	Apply(sub, info, nil)
+	if sub.Metadata["redirects_to"] != "localhost" {
+		t.Errorf("redirects_to = %v, want localhost", sub.Metadata["redirects_to"])
+	}
+}
+
+func TestRedirectHost(t *testing.T) {
+	tests := []struct {
+		name   string
+		info   *types.HTTPInfo
+		domain string
+		want   string
+	}{
+		{
+			name:   "to the apex",
+			info:   &types.HTTPInfo{FinalURL: "https://example.com/", RedirectChain: []string{"https://old.example.com", "https://example.com/"}},
+			domain: "old.example.com",
+			want:   "example.com",
+		},
+		{
+			name:   "off domain",
+			info:   &types.HTTPInfo{FinalURL: "https://login.vendor.example:8443/sso", RedirectChain: []string{"https://sso.example.com", "https://login.vendor.example:8443/sso"}},
+			domain: "sso.example.com",
+			want:   "login.vendor.example",
+		},
+		{
+			name:   "same host, other case",
+			info:   &types.HTTPInfo{FinalURL: "https://WWW.example.com/home", RedirectChain: []string{"http://www.example.com", "https://WWW.example.com/home"}},
+			domain: "www.example.com",
+		},
+		{
+			name:   "no redirect",
+			info:   &types.HTTPInfo{FinalURL: "https://www.example.com"},
+			domain: "www.example.com",
+		},
+		{
+			name:   "no probe",
+			domain: "www.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedirectHost(tt.info, tt.domain); got != tt.want {
+				t.Errorf("RedirectHost = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareRequest(t *testing.T) {
+	browser := stealth.NewProfile(&config.StealthConfig{UserAgents: []string{"Browser/1.0"}})
+
+	tests := []struct {
+		name    string
+		cfg     config.HTTPConfig
+		stealth *stealth.Profile
+		agent   string
+		headers map[string]string
+		host    string
+	}{
+		{
+			name:  "default agent",
+			agent: "Mozilla/5.0 (compatible; USR/1.0; +https://github.com/usr)",
+		},
+		{
+			name: "configured agent and headers",
+			cfg: config.HTTPConfig{
+				UserAgent: "Scanner/2.0",
+				Headers:   map[string]string{"X-Bug-Bounty": "alice", "Authorization": "Bearer abc"},
+			},
+			agent:   "Scanner/2.0",
+			headers: map[string]string{"X-Bug-Bounty": "alice", "Authorization": "Bearer abc"},
+		},
+		{
+			name:  "host header sets the request host",
+			cfg:   config.HTTPConfig{Headers: map[string]string{"host": "internal.example.com"}},
+			agent: "Mozilla/5.0 (compatible; USR/1.0; +https://github.com/usr)",
+			host:  "internal.example.com",
+		},
+		{
+			name:    "stealth rotates browser agents",
+			stealth: browser,
+			agent:   "Browser/1.0",
+		},
+		{
+			name:    "configured agent wins over stealth",
+			cfg:     config.HTTPConfig{UserAgent: "Scanner/2.0"},
+			stealth: browser,
+			agent:   "Scanner/2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProber(tt.cfg)
+			if tt.stealth != nil {
+				p.SetStealth(tt.stealth)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "https://app.example.com/", nil)
+			req.Header.Set("User-Agent", "Go-http-client/1.1")
+			p.PrepareRequest(req)
+
+			if got := req.Header.Get("User-Agent"); got != tt.agent {
+				t.Errorf("User-Agent = %q, want %q", got, tt.agent)
+			}
+			for name, value := range tt.headers {
+				if got := req.Header.Get(name); got != value {
+					t.Errorf("%s = %q, want %q", name, got, value)
+				}
+			}
+			wantHost := tt.host
+			if wantHost == "" {
+				wantHost = "app.example.com"
+			}
+			if req.Host != wantHost {
+				t.Errorf("Host = %q, want %q", req.Host, wantHost)
+			}
+			if _, ok := req.Header["Host"]; ok {
+				t.Error("Host left in the header map, where Go ignores it")
+			}
+		})
+	}
+}
+
+func TestProbeSendsConfiguredHeaders(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		seen  []http.Header
+		hosts []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Clone())
+		hosts = append(hosts, r.Host)
+		mu.Unlock()
+		w.Write([]byte("<html><title>Landing</title></html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	p := newTestProber(config.HTTPConfig{
+		UserAgent: "Scanner/2.0",
+		Headers:   map[string]string{"X-Bug-Bounty": "alice", "Host": "vhost.example.com"},
+	})
+	if info := p.Probe(context.Background(), hostOf(server)); info == nil {
+		t.Fatal("probe failed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) == 0 {
+		t.Fatal("server saw no requests")
+	}
+	for i, header := range seen {
+		if header.Get("User-Agent") != "Scanner/2.0" || header.Get("X-Bug-Bounty") != "alice" {
+			t.Errorf("request sent with User-Agent %q, X-Bug-Bounty %q", header.Get("User-Agent"), header.Get("X-Bug-Bounty"))
+		}
+		if hosts[i] != "vhost.example.com" {
+			t.Errorf("request sent for host %q, want the vhost.example.com override", hosts[i])
+		}
+	}
+}
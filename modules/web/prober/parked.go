@@ -0,0 +1,77 @@
+package prober
+
+import (
+	"net/http"
+	"strings"
+)
+
+// parkingProviders appear in pages, scripts or redirect targets served by
+// domain parking and registrar placeholder services
+var parkingProviders = []string{
+	"sedoparking.com",
+	"sedo.com/search",
+	"parkingcrew.net",
+	"bodis.com",
+	"above.com/marketplace",
+	"parklogic.com",
+	"afternic.com",
+	"hugedomains.com",
+	"undeveloped.com",
+	"domainmarket.com",
+	"parked.godaddy",
+	"img1.wsimg.com/parking",
+	"namecheap.com/domains/registration",
+	"registrar-servers.com/parking",
+	"skenzo.com",
+}
+
+// parkingPhrases are typical parking page wording, matched in the title
+// and body
+var parkingPhrases = []string{
+	"this domain is for sale",
+	"domain is for sale",
+	"buy this domain",
+	"this domain may be for sale",
+	"domain has been registered",
+	"this domain is parked",
+	"parked free",
+	"parked domain",
+	"domain parking",
+	"future home of",
+	"website coming soon",
+	"is registered and parked",
+}
+
+// placeholderBodySize is the size below which a page counts as boilerplate
+const placeholderBodySize = 2048
+
+// detectParked reports whether a response is a parking or placeholder page.
+// A known parking provider in the page or final URL is conclusive; parking
+// wording counts when the title says so or the page is tiny boilerplate.
+func detectParked(body, title string, headers http.Header, finalURL string) bool {
+	bodyLower := strings.ToLower(body)
+	urlLower := strings.ToLower(finalURL)
+
+	for _, provider := range parkingProviders {
+		if strings.Contains(urlLower, provider) || strings.Contains(bodyLower, provider) {
+			return true
+		}
+	}
+
+	// Some parking services only identify themselves in headers
+	if strings.Contains(strings.ToLower(headers.Get("Server")), "parking") {
+		return true
+	}
+
+	titleLower := strings.ToLower(title)
+	for _, phrase := range parkingPhrases {
+		if strings.Contains(titleLower, phrase) {
+			return true
+		}
+		if len(body) < placeholderBodySize && strings.Contains(bodyLower, phrase) {
+			return true
+		}
+	}
+
+	return false
+}
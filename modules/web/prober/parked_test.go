@@ -0,0 +1,99 @@
+package prober
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDetectParked(t *testing.T) {
+	// A real site's page, long enough not to count as boilerplate
+	longPage := "<html><body>" + strings.Repeat("<p>Quarterly results and product news.</p>", 60)
+
+	tests := []struct {
+		name     string
+		body     string
+		title    string
+		server   string
+		finalURL string
+		want     bool
+	}{
+		{
+			name: "provider script in the page",
+			body: `<html><script src="https://www.sedoparking.com/frmpark/js/main.js"></script></html>`,
+			want: true,
+		},
+		{
+			name:     "redirected to a marketplace",
+			finalURL: "https://www.HugeDomains.com/domain_profile.cfm?d=example",
+			want:     true,
+		},
+		{
+			name: "provider in a long page is still conclusive",
+			body: longPage + `<img src="https://img1.wsimg.com/parking/logo.png">`,
+			want: true,
+		},
+		{
+			name:   "parking server header",
+			body:   longPage,
+			server: "Parking/1.0",
+			want:   true,
+		},
+		{
+			name:  "phrase in the title",
+			body:  longPage,
+			title: "example.com - This Domain Is For Sale",
+			want:  true,
+		},
+		{
+			name: "phrase in a tiny page",
+			body: "<html><body><h1>Future home of something quite cool</h1></body></html>",
+			want: true,
+		},
+		{
+			name: "phrase buried in a real page",
+			body: longPage + "<p>Read how we bought this domain is for sale no longer</p>",
+		},
+		{
+			name:  "ordinary small page",
+			body:  "<html><body>Hello</body></html>",
+			title: "Welcome",
+		},
+		{
+			name:   "ordinary server",
+			server: "nginx",
+		},
+		{name: "empty response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := http.Header{}
+			if tt.server != "" {
+				headers.Set("Server", tt.server)
+			}
+			if got := detectParked(tt.body, tt.title, headers, tt.finalURL); got != tt.want {
+				t.Errorf("detectParked = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeMarksParkedPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><title>example.com is parked free, courtesy of the registrar</title></html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	info := NewHTTPProberWithClient(server.Client(), zap.NewNop(), 1).Probe(context.Background(), hostOf(server))
+	if info == nil {
+		t.Fatal("probe failed")
+	}
+	if !info.Parked {
+		t.Error("parking page not marked parked")
+	}
+}
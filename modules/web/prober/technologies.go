@@ -0,0 +1,413 @@
+package prober
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// Rule is a technology fingerprint. Patterns use Wappalyzer's syntax: a
+// case-insensitive regex optionally followed by \;version:<template> (\1
+// for the first group, \1?yes:no for a choice) and \;confidence:<0-100>.
+// An empty header, cookie or meta pattern matches on presence alone.
+type Rule struct {
+	Name      string
+	Headers   map[string][]string // header name -> patterns on its values
+	Cookies   map[string][]string // cookie name -> patterns on its value
+	Meta      map[string][]string // <meta> name -> patterns on its content
+	HTML      []string            // patterns on the body
+	ScriptSrc []string            // patterns on <script src> URLs
+	Implies   []string            // technologies this one implies (\;confidence: allowed)
+}
+
+// builtinRules are the fingerprints used without any configured files
+var builtinRules = []Rule{
+	// Web servers and proxies
+	{Name: "nginx", Headers: map[string][]string{"Server": {`nginx(?:/([\d.]+))?\;version:\1`}}},
+	{Name: "OpenResty", Headers: map[string][]string{"Server": {`openresty(?:/([\d.]+))?\;version:\1`}}, Implies: []string{"nginx"}},
+	{Name: "Apache", Headers: map[string][]string{"Server": {`^apache(?:/([\d.]+))?(?:$|\s)\;version:\1`}}},
+	{Name: "IIS", Headers: map[string][]string{"Server": {`^microsoft-iis(?:/([\d.]+))?\;version:\1`}}},
+	{Name: "LiteSpeed", Headers: map[string][]string{"Server": {`^litespeed`}}},
+	{Name: "Caddy", Headers: map[string][]string{"Server": {`^caddy`}}},
+	{Name: "Envoy", Headers: map[string][]string{"Server": {`^envoy`}, "X-Envoy-Upstream-Service-Time": {``}}},
+	{Name: "Apache Tomcat", Headers: map[string][]string{"Server": {`^apache-coyote`}}, Implies: []string{"Java"}},
+	{Name: "Varnish", Headers: map[string][]string{"Via": {`varnish`}, "X-Varnish": {``}}},
+	{Name: "Cloudflare", Headers: map[string][]string{"Server": {`^cloudflare$`}, "CF-RAY": {``}}},
+	{Name: "Amazon S3", Headers: map[string][]string{"Server": {`^amazons3$`}}},
+
+	// Languages and frameworks
+	{
+		Name:    "PHP",
+		Headers: map[string][]string{"X-Powered-By": {`php(?:/([\d.]+))?\;version:\1`}},
+		Cookies: map[string][]string{"PHPSESSID": {``}},
+	},
+	{
+		Name: "ASP.NET",
+		Headers: map[string][]string{
+			"X-Powered-By":     {`asp\.net`},
+			"X-AspNet-Version": {`([\d.]+)\;version:\1`},
+		},
+		Cookies: map[string][]string{"ASP.NET_SessionId": {``}},
+		HTML:    []string{`<input[^>]+name="__VIEWSTATE"`},
+	},
+	{Name: "Java", Cookies: map[string][]string{"JSESSIONID": {``}}},
+	{Name: "Express", Headers: map[string][]string{"X-Powered-By": {`^express$`}}, Implies: []string{"Node.js"}},
+	{Name: "Next.js", Headers: map[string][]string{"X-Powered-By": {`^next\.js ?([\d.]+)?\;version:\1`}}, HTML: []string{`<script[^>]+id="__NEXT_DATA__"`}, Implies: []string{"React"}},
+	{Name: "Nuxt.js", HTML: []string{`<div[^>]+id="__nuxt"`, `window\.__NUXT__`}, Implies: []string{"Vue.js"}},
+	{Name: "Django", Cookies: map[string][]string{"django_language": {``}}, HTML: []string{`<input[^>]+name="csrfmiddlewaretoken"\;confidence:50`}},
+	{Name: "Laravel", Cookies: map[string][]string{"laravel_session": {``}}, Implies: []string{"PHP"}},
+	{Name: "Symfony", Cookies: map[string][]string{"sf_redirect": {``}}, HTML: []string{`class="sf-toolbar`}, Implies: []string{"PHP"}},
+	{Name: "Spring", Headers: map[string][]string{"X-Application-Context": {``}}, HTML: []string{`<h1>Whitelabel Error Page</h1>`}, Implies: []string{"Java"}},
+	{Name: "Flask", Headers: map[string][]string{"Server": {`werkzeug(?:/([\d.]+))?\;confidence:50`}}, Implies: []string{"Python"}},
+	{
+		Name:    "Ruby on Rails",
+		Cookies: map[string][]string{"_rails_session": {``}},
+		Meta:    map[string][]string{"csrf-param": {`^authenticity_token$\;confidence:50`}},
+		Implies: []string{"Ruby"},
+	},
+
+	// CMSs and site builders
+	{
+		Name:    "WordPress",
+		Meta:    map[string][]string{"generator": {`^wordpress ?([\d.]+)?\;version:\1`}},
+		HTML:    []string{`/wp-(?:content|includes)/`},
+		Implies: []string{"PHP"},
+	},
+	{Name: "Joomla", Meta: map[string][]string{"generator": {`joomla!?(?: ([\d.]+))?\;version:\1`}}, Implies: []string{"PHP"}},
+	{
+		Name:    "Drupal",
+		Headers: map[string][]string{"X-Generator": {`drupal(?: ([\d.]+))?\;version:\1`}, "X-Drupal-Cache": {``}},
+		Meta:    map[string][]string{"generator": {`drupal(?: ([\d.]+))?\;version:\1`}},
+		HTML:    []string{`/sites/(?:default|all)/(?:themes|modules)/`},
+		Implies: []string{"PHP"},
+	},
+	{Name: "Gatsby", Meta: map[string][]string{"generator": {`^gatsby(?: ([\d.]+))?\;version:\1`}}, HTML: []string{`<div[^>]+id="___gatsby"`}, Implies: []string{"React"}},
+	{Name: "Shopify", Headers: map[string][]string{"X-ShopId": {``}}, HTML: []string{`cdn\.shopify\.com`}},
+	{Name: "Magento", Cookies: map[string][]string{"X-Magento-Vary": {``}}, HTML: []string{`/static/version\d+/frontend/`, `Mage\.Cookies`}, Implies: []string{"PHP"}},
+	{Name: "Wix", Headers: map[string][]string{"X-Wix-Request-Id": {``}}, HTML: []string{`static\.wixstatic\.com`}},
+	{Name: "Squarespace", HTML: []string{`static1?\.squarespace\.com`}},
+
+	// JavaScript libraries and CSS frameworks
+	{Name: "React", HTML: []string{`data-reactroot`}, ScriptSrc: []string{`react(?:-dom)?(?:\.production)?(?:\.min)?\.js`, `/react(?:-dom)?@([\d.]+)/\;version:\1`}},
+	{Name: "Angular", HTML: []string{`ng-version="([\d.]+)"\;version:\1`}},
+	{Name: "AngularJS", HTML: []string{`<[^>]+ ng-app`}, ScriptSrc: []string{`angular(?:\.min)?\.js`}},
+	{Name: "Vue.js", HTML: []string{`data-v-[0-9a-f]{8}`}, ScriptSrc: []string{`vue(?:\.runtime)?(?:\.global)?(?:\.prod)?(?:\.min)?\.js`, `/vue@([\d.]+)\;version:\1`}},
+	{Name: "jQuery", ScriptSrc: []string{`jquery(?:-([\d.]+))?(?:\.min)?\.js\;version:\1`, `/jquery/([\d.]+)/\;version:\1`}},
+	{Name: "Bootstrap", HTML: []string{`bootstrap(?:\.min)?\.css`}, ScriptSrc: []string{`bootstrap(?:\.bundle)?(?:\.min)?\.js`, `/bootstrap@([\d.]+)/\;version:\1`}},
+	{Name: "Tailwind CSS", HTML: []string{`tailwindcss`, `--tw-[a-z]`}},
+}
+
+// DefaultRules returns the built-in fingerprints
+func DefaultRules() []Rule {
+	return append([]Rule(nil), builtinRules...)
+}
+
+// pattern is a compiled Wappalyzer pattern
+type pattern struct {
+	re         *regexp.Regexp // nil = presence only
+	version    string
+	confidence int
+}
+
+// compiledRule is a rule with its patterns compiled
+type compiledRule struct {
+	name      string
+	headers   map[string][]pattern
+	cookies   map[string][]pattern
+	meta      map[string][]pattern
+	html      []pattern
+	scriptSrc []pattern
+	implies   []pattern // re unused; version holds the implied name
+}
+
+// Fingerprinter detects technologies in HTTP responses
+type Fingerprinter struct {
+	rules []compiledRule
+}
+
+// NewFingerprinter compiles rules. Patterns that don't compile (Go's
+// regexp has no lookarounds, which some community rules use) are skipped.
+func NewFingerprinter(rules []Rule, logger *zap.Logger) *Fingerprinter {
+	f := &Fingerprinter{}
+	skipped := 0
+
+	compileAll := func(sources []string) []pattern {
+		var patterns []pattern
+		for _, source := range sources {
+			p, err := parsePattern(source)
+			if err != nil {
+				skipped++
+				continue
+			}
+			patterns = append(patterns, p)
+		}
+		return patterns
+	}
+	compileMap := func(sources map[string][]string) map[string][]pattern {
+		if len(sources) == 0 {
+			return nil
+		}
+		patterns := make(map[string][]pattern, len(sources))
+		for name, list := range sources {
+			if compiled := compileAll(list); len(compiled) > 0 {
+				patterns[name] = compiled
+			}
+		}
+		return patterns
+	}
+
+	for _, rule := range rules {
+		compiled := compiledRule{
+			name:      rule.Name,
+			headers:   compileMap(rule.Headers),
+			cookies:   compileMap(rule.Cookies),
+			meta:      compileMap(rule.Meta),
+			html:      compileAll(rule.HTML),
+			scriptSrc: compileAll(rule.ScriptSrc),
+		}
+		for _, implied := range rule.Implies {
+			name, confidence := splitImplied(implied)
+			compiled.implies = append(compiled.implies, pattern{version: name, confidence: confidence})
+		}
+		f.rules = append(f.rules, compiled)
+	}
+
+	if skipped > 0 && logger != nil {
+		logger.Debug("Skipped fingerprint patterns that don't compile", zap.Int("patterns", skipped))
+	}
+	return f
+}
+
+// parsePattern compiles one pattern with its version and confidence tags
+func parsePattern(source string) (pattern, error) {
+	parts := strings.Split(source, `\;`)
+	p := pattern{confidence: 100}
+
+	if parts[0] != "" {
+		re, err := regexp.Compile("(?i)" + parts[0])
+		if err != nil {
+			return p, err
+		}
+		p.re = re
+	}
+
+	for _, tag := range parts[1:] {
+		key, value, _ := strings.Cut(tag, ":")
+		switch key {
+		case "version":
+			p.version = value
+		case "confidence":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return p, fmt.Errorf("invalid confidence %q", value)
+			}
+			p.confidence = n
+		}
+	}
+	return p, nil
+}
+
+// splitImplied separates an implied technology from its confidence tag
+func splitImplied(implied string) (string, int) {
+	name, tags, _ := strings.Cut(implied, `\;`)
+	confidence := 100
+	if value, ok := strings.CutPrefix(tags, "confidence:"); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			confidence = n
+		}
+	}
+	return strings.TrimSpace(name), confidence
+}
+
+// match tests a value, returning the version the pattern extracts
+func (p pattern) match(value string) (bool, string) {
+	if p.re == nil {
+		return true, ""
+	}
+	groups := p.re.FindStringSubmatch(value)
+	if groups == nil {
+		return false, ""
+	}
+	return true, expandVersion(p.version, groups)
+}
+
+// versionChoice is the \1?yes:no form of a version template
+var versionChoice = regexp.MustCompile(`^\\(\d)\?([^:]*):(.*)$`)
+
+// versionGroup is a \N group reference in a version template
+var versionGroup = regexp.MustCompile(`\\(\d)`)
+
+// expandVersion fills a version template from a match's groups
+func expandVersion(template string, groups []string) string {
+	if template == "" {
+		return ""
+	}
+
+	group := func(ref string) string {
+		n, _ := strconv.Atoi(ref)
+		if n < len(groups) {
+			return groups[n]
+		}
+		return ""
+	}
+
+	if choice := versionChoice.FindStringSubmatch(template); choice != nil {
+		if group(choice[1]) != "" {
+			template = choice[2]
+		} else {
+			template = choice[3]
+		}
+	}
+
+	version := versionGroup.ReplaceAllStringFunc(template, func(ref string) string {
+		return group(ref[1:])
+	})
+	return strings.TrimSpace(version)
+}
+
+// detection accumulates the matches of one technology
+type detection struct {
+	version    string
+	confidence int
+}
+
+// Detect returns the technologies a response matches, sorted by name. A
+// technology's confidence is the sum of its matched patterns' (at most
+// 100); implied technologies get the implying one's confidence scaled by
+// the implication's.
+func (f *Fingerprinter) Detect(header http.Header, body string) []types.Technology {
+	cookies := responseCookies(header)
+	meta := metaTags(body)
+	scripts := scriptSources(body)
+
+	found := make(map[string]*detection)
+	add := func(name, version string, confidence int) {
+		d := found[name]
+		if d == nil {
+			d = &detection{}
+			found[name] = d
+		}
+		d.confidence = min(d.confidence+confidence, 100)
+		if len(version) > len(d.version) {
+			d.version = version
+		}
+	}
+	matchAll := func(name string, patterns []pattern, values []string) {
+		for _, p := range patterns {
+			for _, value := range values {
+				if ok, version := p.match(value); ok {
+					add(name, version, p.confidence)
+					break
+				}
+			}
+		}
+	}
+
+	for _, rule := range f.rules {
+		for name, patterns := range rule.headers {
+			matchAll(rule.name, patterns, header.Values(name))
+		}
+		for name, patterns := range rule.cookies {
+			if value, ok := cookies[name]; ok {
+				matchAll(rule.name, patterns, []string{value})
+			}
+		}
+		for name, patterns := range rule.meta {
+			if values, ok := meta[strings.ToLower(name)]; ok {
+				matchAll(rule.name, patterns, values)
+			}
+		}
+		matchAll(rule.name, rule.html, []string{body})
+		matchAll(rule.name, rule.scriptSrc, scripts)
+	}
+
+	// Implications, followed through chains (Next.js -> React)
+	implied := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range f.rules {
+			d := found[rule.name]
+			if d == nil || implied[rule.name] {
+				continue
+			}
+			implied[rule.name] = true
+			changed = true
+			for _, p := range rule.implies {
+				if found[p.version] == nil {
+					add(p.version, "", d.confidence*p.confidence/100)
+				}
+			}
+		}
+	}
+
+	technologies := make([]types.Technology, 0, len(found))
+	for name, d := range found {
+		technologies = append(technologies, types.Technology{
+			Name:       name,
+			Version:    d.version,
+			Confidence: d.confidence,
+		})
+	}
+	sort.Slice(technologies, func(i, j int) bool {
+		return technologies[i].Name < technologies[j].Name
+	})
+	return technologies
+}
+
+// TechnologyNames returns the names of detected technologies
+func TechnologyNames(technologies []types.Technology) []string {
+	if len(technologies) == 0 {
+		return nil
+	}
+	names := make([]string, len(technologies))
+	for i, tech := range technologies {
+		names[i] = tech.Name
+	}
+	return names
+}
+
+// responseCookies returns the cookies a response sets, by name
+func responseCookies(header http.Header) map[string]string {
+	cookies := make(map[string]string)
+	for _, cookie := range (&http.Response{Header: header}).Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+	return cookies
+}
+
+var (
+	metaTag     = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaName    = regexp.MustCompile(`(?is)\b(?:name|property)\s*=\s*["']([^"']+)["']`)
+	metaContent = regexp.MustCompile(`(?is)\bcontent\s*=\s*["']([^"']*)["']`)
+	scriptTag   = regexp.MustCompile(`(?is)<script\s[^>]*\bsrc\s*=\s*["']([^"']+)["']`)
+)
+
+// metaTags returns the content of a page's <meta> tags by lowercased name
+func metaTags(body string) map[string][]string {
+	tags := make(map[string][]string)
+	for _, tag := range metaTag.FindAllString(body, -1) {
+		name := metaName.FindStringSubmatch(tag)
+		content := metaContent.FindStringSubmatch(tag)
+		if name == nil || content == nil {
+			continue
+		}
+		key := strings.ToLower(name[1])
+		tags[key] = append(tags[key], content[1])
+	}
+	return tags
+}
+
+// scriptSources returns the src URLs of a page's <script> tags
+func scriptSources(body string) []string {
+	var sources []string
+	for _, match := range scriptTag.FindAllStringSubmatch(body, -1) {
+		sources = append(sources, match[1])
+	}
+	return sources
+}
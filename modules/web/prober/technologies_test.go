@@ -0,0 +1,261 @@
+package prober
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+func TestDetect(t *testing.T) {
+	f := NewFingerprinter(DefaultRules(), zap.NewNop())
+
+	tests := []struct {
+		name   string
+		header http.Header
+		body   string
+		want   []types.Technology
+	}{
+		{
+			name:   "server header version",
+			header: http.Header{"Server": {"nginx/1.25.3"}},
+			want:   []types.Technology{{Name: "nginx", Version: "1.25.3", Confidence: 100}},
+		},
+		{
+			name:   "server header without version",
+			header: http.Header{"Server": {"nginx"}},
+			want:   []types.Technology{{Name: "nginx", Confidence: 100}},
+		},
+		{
+			name:   "version header",
+			header: http.Header{"X-Powered-By": {"PHP/8.2.12"}},
+			want:   []types.Technology{{Name: "PHP", Version: "8.2.12", Confidence: 100}},
+		},
+		{
+			name:   "cookie",
+			header: http.Header{"Set-Cookie": {"JSESSIONID=4A1B2C; Path=/; HttpOnly"}},
+			want:   []types.Technology{{Name: "Java", Confidence: 100}},
+		},
+		{
+			name:   "cookie implying a language",
+			header: http.Header{"Set-Cookie": {"laravel_session=eyJpdiI6; Path=/"}},
+			want: []types.Technology{
+				{Name: "Laravel", Confidence: 100},
+				{Name: "PHP", Confidence: 100},
+			},
+		},
+		{
+			name: "meta generator version",
+			body: `<html><head><meta name="generator" content="WordPress 6.4.2"></head></html>`,
+			want: []types.Technology{
+				{Name: "PHP", Confidence: 100},
+				{Name: "WordPress", Version: "6.4.2", Confidence: 100},
+			},
+		},
+		{
+			name: "body regex version",
+			body: `<app-root ng-version="17.0.8"></app-root>`,
+			want: []types.Technology{{Name: "Angular", Version: "17.0.8", Confidence: 100}},
+		},
+		{
+			name: "script src version",
+			body: `<script src="https://code.jquery.com/jquery-3.7.1.min.js"></script>`,
+			want: []types.Technology{{Name: "jQuery", Version: "3.7.1", Confidence: 100}},
+		},
+		{
+			name: "partial confidence",
+			body: `<form><input type="hidden" name="csrfmiddlewaretoken" value="x"></form>`,
+			want: []types.Technology{{Name: "Django", Confidence: 50}},
+		},
+		{
+			name:   "confidences add up to 100",
+			header: http.Header{"Set-Cookie": {"django_language=en"}},
+			body:   `<input type="hidden" name="csrfmiddlewaretoken" value="x">`,
+			want:   []types.Technology{{Name: "Django", Confidence: 100}},
+		},
+		{
+			name:   "implications are followed through chains",
+			header: http.Header{"X-Powered-By": {"Next.js 14.1.0"}},
+			want: []types.Technology{
+				{Name: "Next.js", Version: "14.1.0", Confidence: 100},
+				{Name: "React", Confidence: 100},
+			},
+		},
+		{
+			name:   "implied confidence scales with the implying match",
+			header: http.Header{"Server": {"Werkzeug/3.0.1 Python/3.12.1"}},
+			want: []types.Technology{
+				{Name: "Flask", Confidence: 50},
+				{Name: "Python", Confidence: 50},
+			},
+		},
+		{
+			name:   "nothing recognized",
+			header: http.Header{"Server": {"custom"}},
+			body:   "<html></html>",
+			want:   []types.Technology{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			if got := f.Detect(header, tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandVersion(t *testing.T) {
+	tests := []struct {
+		template string
+		groups   []string
+		want     string
+	}{
+		{`\1`, []string{"nginx/1.25.3", "1.25.3"}, "1.25.3"},
+		{`\1.\2`, []string{"v3_2", "3", "2"}, "3.2"},
+		{`\1?legacy:modern`, []string{"x", "y"}, "legacy"},
+		{`\1?legacy:modern`, []string{"x", ""}, "modern"},
+		{`\3`, []string{"x", "1"}, ""},
+		{"", []string{"x", "1"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := expandVersion(tt.template, tt.groups); got != tt.want {
+			t.Errorf("expandVersion(%q, %q) = %q, want %q", tt.template, tt.groups, got, tt.want)
+		}
+	}
+}
+
+// writeFingerprints writes a Wappalyzer fingerprint file
+func writeFingerprints(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "technologies.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const wappalyzerFixture = `{
+	"technologies": {
+		"Acme CMS": {
+			"cats": [1],
+			"headers": {"X-Acme-Version": "^([\\d.]+)$\\;version:\\1"},
+			"cookies": {"acme_sid": ""},
+			"meta": {"generator": ["^Acme CMS"]},
+			"html": "<div id=\"acme-root\"",
+			"implies": ["Acme Runtime\\;confidence:50"]
+		},
+		"Acme Runtime": {
+			"script": "acme-runtime-([\\d.]+)\\.js\\;version:\\1"
+		},
+		"Lookbehind": {
+			"html": "(?<=foo)bar"
+		}
+	}
+}`
+
+func TestLoadWappalyzer(t *testing.T) {
+	rules, err := LoadWappalyzer(writeFingerprints(t, wappalyzerFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 || rules[0].Name != "Acme CMS" || rules[1].Name != "Acme Runtime" {
+		t.Fatalf("got %+v, want the three technologies sorted by name", rules)
+	}
+
+	acme := rules[0]
+	if !reflect.DeepEqual(acme.Headers, map[string][]string{"X-Acme-Version": {`^([\d.]+)$\;version:\1`}}) ||
+		!reflect.DeepEqual(acme.Meta, map[string][]string{"generator": {"^Acme CMS"}}) ||
+		!reflect.DeepEqual(acme.HTML, []string{`<div id="acme-root"`}) {
+		t.Errorf("Acme CMS rule = %+v", acme)
+	}
+	if !reflect.DeepEqual(rules[1].ScriptSrc, []string{`acme-runtime-([\d.]+)\.js\;version:\1`}) {
+		t.Errorf("script not read as scriptSrc: %+v", rules[1])
+	}
+
+	// The lookbehind doesn't compile in Go and is skipped
+	f := NewFingerprinter(rules, zap.NewNop())
+	got := f.Detect(
+		http.Header{"X-Acme-Version": {"4.2.0"}},
+		`<div id="acme-root"></div><p>foobar</p>`,
+	)
+	want := []types.Technology{
+		{Name: "Acme CMS", Version: "4.2.0", Confidence: 100},
+		{Name: "Acme Runtime", Confidence: 50},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadWappalyzerLayouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{name: "technologies directory file", content: `{"Zeta": {"html": "zeta"}, "Alpha": {"html": ["alpha"]}}`, want: []string{"Alpha", "Zeta"}},
+		{name: "apps", content: `{"apps": {"Legacy": {"headers": {"X-Legacy": ""}}}}`, want: []string{"Legacy"}},
+		{name: "not json", content: `technologies:`, wantErr: true},
+		{name: "bad pattern type", content: `{"Broken": {"html": 42}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := LoadWappalyzer(writeFingerprints(t, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			var names []string
+			for _, rule := range rules {
+				names = append(names, rule.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("got %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeUsesConfiguredFingerprints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.25.3")
+		w.Header().Set("X-Acme-Version", "4.2.0")
+		w.Write([]byte(`<html><div id="acme-root"></div></html>`))
+	}))
+	t.Cleanup(server.Close)
+
+	p := newTestProber(config.HTTPConfig{
+		Fingerprints: []string{writeFingerprints(t, wappalyzerFixture), filepath.Join(t.TempDir(), "missing.json")},
+	})
+	info := p.Probe(context.Background(), hostOf(server))
+	if info == nil {
+		t.Fatal("probe failed")
+	}
+
+	// Built-in rules still apply beside the loaded ones
+	want := []string{"Acme CMS", "Acme Runtime", "nginx"}
+	if !reflect.DeepEqual(info.Technologies, want) {
+		t.Errorf("technologies = %v, want %v", info.Technologies, want)
+	}
+	for _, tech := range info.TechDetails {
+		if tech.Name == "nginx" && tech.Version != "1.25.3" {
+			t.Errorf("nginx version %q, want 1.25.3", tech.Version)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package prober
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/stealth"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// defaultTLSTimeout bounds a handshake when no dial or handshake timeout is
+// configured
+const defaultTLSTimeout = 10 * time.Second
+
+// TLSProber reads the certificate a host presents on port 443 with a bare
+// TLS handshake. It covers hosts HTTPS probing didn't reach, or all hosts
+// when HTTP validation is off.
+type TLSProber struct {
+	logger     *zap.Logger
+	maxWorkers int
+	timeout    time.Duration
+	port       string
+
+	// Stealth mode: jitter before each handshake (optional)
+	stealth *stealth.Profile
+}
+
+// NewTLSProber creates a TLS prober using the HTTP dial and handshake
+// timeouts
+func NewTLSProber(cfg *config.HTTPConfig, logger *zap.Logger, maxWorkers int) *TLSProber {
+	timeout := seconds(cfg.DialTimeout) + seconds(cfg.TLSHandshakeTimeout)
+	if timeout <= 0 {
+		timeout = defaultTLSTimeout
+	}
+
+	return &TLSProber{
+		logger:     logger,
+		maxWorkers: maxWorkers,
+		timeout:    timeout,
+		port:       "443",
+	}
+}
+
+// SetStealth enables stealth behavior: fewer workers and a random delay
+// before each handshake
+func (p *TLSProber) SetStealth(profile *stealth.Profile) {
+	p.stealth = profile
+	p.maxWorkers = profile.Concurrency(p.maxWorkers)
+}
+
+// Probe performs a TLS handshake with a host and returns its certificate,
+// or nil if none was presented in time
+func (p *TLSProber) Probe(ctx context.Context, subdomain string) *types.TLSInfo {
+	if p.stealth != nil {
+		if err := p.stealth.Wait(ctx); err != nil {
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName:         subdomain,
+			InsecureSkipVerify: true, // Verified in extractTLSInfo
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(subdomain, p.port))
+	if err != nil {
+		p.logger.Debug("TLS handshake failed",
+			zap.String("subdomain", subdomain),
+			zap.Error(err),
+		)
+		return nil
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	return extractTLSInfo(&state)
+}
+
+// ProbeBatch reads the certificates of resolved hosts that don't have one
+// yet, concurrently
+func (p *TLSProber) ProbeBatch(ctx context.Context, subdomains []*types.Subdomain) error {
+	var pending []*types.Subdomain
+	for _, sub := range subdomains {
+		if sub.Validated && len(sub.IP) > 0 && sub.TLS == nil {
+			pending = append(pending, sub)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	p.logger.Info("Starting TLS certificate checks",
+		zap.Int("count", len(pending)),
+		zap.Int("workers", p.maxWorkers),
+	)
+
+	err := pool.Run(ctx, pending, p.maxWorkers, func(ctx context.Context, sub *types.Subdomain) {
+		if info := p.Probe(ctx, sub.Domain); info != nil {
+			sub.TLS = info
+		}
+	})
+
+	p.logger.Info("TLS certificate checks complete")
+	return err
+}
@@ -0,0 +1,99 @@
+package prober
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// tlsServer starts a TLS server with httptest's certificate. Handshakes
+// the prober drops after reading the certificate aren't logged.
+func tlsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestTLSProber returns a TLS prober that dials server's port instead
+// of 443
+func newTestTLSProber(t *testing.T, server *httptest.Server) *TLSProber {
+	t.Helper()
+
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("server address: %v", err)
+	}
+	p := NewTLSProber(&config.HTTPConfig{DialTimeout: 2}, zap.NewNop(), 2)
+	p.port = port
+	return p
+}
+
+func TestTLSProbeReadsCertificate(t *testing.T) {
+	server := tlsServer(t)
+	info := newTestTLSProber(t, server).Probe(context.Background(), "127.0.0.1")
+	if info == nil {
+		t.Fatal("no certificate read")
+	}
+
+	// httptest's certificate is issued for example.com by an untrusted CA
+	cert := server.Certificate()
+	if strings.Join(info.SANs, " ") != "example.com *.example.com" {
+		t.Errorf("SANs = %v, want %v", info.SANs, cert.DNSNames)
+	}
+	if info.Organization != "Acme Co" {
+		t.Errorf("organization = %q, want Acme Co", info.Organization)
+	}
+	if !info.NotBefore.Equal(cert.NotBefore) || !info.NotAfter.Equal(cert.NotAfter) {
+		t.Errorf("validity %v - %v, want %v - %v", info.NotBefore, info.NotAfter, cert.NotBefore, cert.NotAfter)
+	}
+	if info.Valid {
+		t.Error("self-signed certificate reported valid")
+	}
+}
+
+func TestTLSProbeBatchFillsMissingCertificates(t *testing.T) {
+	server := tlsServer(t)
+	probed := &types.TLSInfo{Subject: "already.example.com"}
+	subs := []*types.Subdomain{
+		{Domain: "127.0.0.1", IP: []string{"127.0.0.1"}, Validated: true},
+		{Domain: "localhost", IP: []string{"127.0.0.1"}, Validated: true, TLS: probed},
+		{Domain: "unresolved.invalid"},
+	}
+
+	if err := newTestTLSProber(t, server).ProbeBatch(context.Background(), subs); err != nil {
+		t.Fatalf("ProbeBatch: %v", err)
+	}
+
+	if subs[0].TLS == nil || len(subs[0].TLS.SANs) == 0 {
+		t.Errorf("resolved host without a certificate got %+v", subs[0].TLS)
+	}
+	if subs[1].TLS != probed {
+		t.Errorf("certificate from HTTPS probing replaced by %+v", subs[1].TLS)
+	}
+	if subs[2].TLS != nil {
+		t.Errorf("unresolved host probed: %+v", subs[2].TLS)
+	}
+}
+
+func TestTLSProbeStopsOnCancel(t *testing.T) {
+	server := tlsServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if info := newTestTLSProber(t, server).Probe(ctx, "127.0.0.1"); info != nil {
+		t.Errorf("cancelled probe read %+v", info)
+	}
+}
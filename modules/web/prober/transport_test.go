@@ -0,0 +1,105 @@
+package prober
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+)
+
+func TestDialTimeoutFromConfig(t *testing.T) {
+	if got := newDialer(&config.HTTPConfig{DialTimeout: 3}).Timeout; got != 3*time.Second {
+		t.Errorf("dial timeout = %v, want 3s", got)
+	}
+	if got := newDialer(&config.HTTPConfig{}).Timeout; got != 0 {
+		t.Errorf("unset dial timeout = %v, want no limit", got)
+	}
+}
+
+func TestTLSHandshakeTimeout(t *testing.T) {
+	// Accepts connections and never says anything
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	// The overall timeout is far off; only the handshake limit can end it
+	p := newTestProber(config.HTTPConfig{Timeout: 30, TLSHandshakeTimeout: 1})
+
+	start := time.Now()
+	info, _ := p.probeScheme(context.Background(), "https", listener.Addr().String())
+	if info != nil {
+		t.Fatalf("probe of a silent TLS server returned %+v", info)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("handshake gave up after %v, want about 1s", elapsed)
+	}
+}
+
+func TestResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	p := newTestProber(config.HTTPConfig{Timeout: 30, ResponseHeaderTimeout: 1})
+
+	start := time.Now()
+	info, _ := p.probeScheme(context.Background(), "http", hostOf(server))
+	if info != nil {
+		t.Fatalf("probe of a stalled server returned %+v", info)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("header wait gave up after %v, want about 1s", elapsed)
+	}
+}
+
+func TestHTTP2Negotiation(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	for _, tt := range []struct {
+		http2 bool
+		proto int
+	}{
+		{true, 2},
+		{false, 1},
+	} {
+		p := newTestProber(config.HTTPConfig{HTTP2: tt.http2})
+
+		resp, err := p.Client().Get(server.URL)
+		if err != nil {
+			t.Fatalf("http2 %v: %v", tt.http2, err)
+		}
+		resp.Body.Close()
+
+		if resp.ProtoMajor != tt.proto {
+			t.Errorf("http2 %v: negotiated %s, want HTTP/%d", tt.http2, resp.Proto, tt.proto)
+		}
+
+		// The probe itself goes through the same negotiation
+		if info := p.Probe(context.Background(), hostOf(server)); info == nil || info.StatusCode != http.StatusOK {
+			t.Errorf("http2 %v: probe failed: %+v", tt.http2, info)
+		}
+	}
+}
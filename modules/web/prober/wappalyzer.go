@@ -0,0 +1,100 @@
+package prober
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// stringList is a Wappalyzer value that may be a string or a list of them
+type stringList []string
+
+// UnmarshalJSON accepts a string or an array of strings
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// wappalyzerTech is the part of a Wappalyzer technology entry used here
+type wappalyzerTech struct {
+	Headers   map[string]stringList `json:"headers"`
+	Cookies   map[string]stringList `json:"cookies"`
+	Meta      map[string]stringList `json:"meta"`
+	HTML      stringList            `json:"html"`
+	ScriptSrc stringList            `json:"scriptSrc"`
+	Script    stringList            `json:"script"` // older name of scriptSrc
+	Implies   stringList            `json:"implies"`
+}
+
+// LoadWappalyzer reads fingerprints in Wappalyzer's JSON format: an object
+// of technologies by name, at the top level (one file of its technologies
+// directory) or under "technologies" or "apps" (older single-file
+// releases). Headers, cookies, meta, html, scriptSrc and implies are used.
+func LoadWappalyzer(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, key := range []string{"technologies", "apps"} {
+		if nested, ok := top[key]; ok {
+			top = nil
+			if err := json.Unmarshal(nested, &top); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+			}
+			break
+		}
+	}
+
+	names := make([]string, 0, len(top))
+	for name := range top {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rules := make([]Rule, 0, len(names))
+	for _, name := range names {
+		var tech wappalyzerTech
+		if err := json.Unmarshal(top[name], &tech); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+
+		rules = append(rules, Rule{
+			Name:      name,
+			Headers:   plainMap(tech.Headers),
+			Cookies:   plainMap(tech.Cookies),
+			Meta:      plainMap(tech.Meta),
+			HTML:      tech.HTML,
+			ScriptSrc: append(tech.ScriptSrc, tech.Script...),
+			Implies:   tech.Implies,
+		})
+	}
+	return rules, nil
+}
+
+// plainMap converts decoded pattern lists to a rule's map type
+func plainMap(m map[string]stringList) map[string][]string {
+	if len(m) == 0 {
+		return nil
+	}
+	plain := make(map[string][]string, len(m))
+	for key, list := range m {
+		plain[key] = list
+	}
+	return plain
+}
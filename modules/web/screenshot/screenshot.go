@@ -0,0 +1,200 @@
+package screenshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/internal/pool"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// ErrNoBrowser is returned when no Chrome/Chromium binary can be found
+var ErrNoBrowser = errors.New("no headless Chrome/Chromium found")
+
+// browsers are looked up in PATH, in order, when none is configured
+var browsers = []string{
+	"chromium",
+	"chromium-browser",
+	"google-chrome",
+	"google-chrome-stable",
+	"chrome",
+}
+
+// CaptureFunc saves a PNG of the page at url to path
+type CaptureFunc func(ctx context.Context, url, path string) error
+
+// Capturer takes screenshots of hosts that answered HTTP 200
+type Capturer struct {
+	capture CaptureFunc
+	dir     string
+	timeout time.Duration
+	workers int
+	logger  *zap.Logger
+}
+
+// NewCapturer creates a capturer driving a headless browser, saving PNGs
+// under dir. It returns ErrNoBrowser when cfg names no browser and none is
+// found in PATH.
+func NewCapturer(cfg *config.ScreenshotConfig, dir string, logger *zap.Logger) (*Capturer, error) {
+	browser, err := findBrowser(cfg.Browser)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	return NewCapturerWithFunc(chromeCapture(browser, cfg.NoSandbox), dir, timeout, cfg.Workers, logger), nil
+}
+
+// NewCapturerWithFunc creates a capturer taking screenshots through
+// capture, e.g. a stub writing placeholder files
+func NewCapturerWithFunc(capture CaptureFunc, dir string, timeout time.Duration, workers int, logger *zap.Logger) *Capturer {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	return &Capturer{
+		capture: capture,
+		dir:     dir,
+		timeout: timeout,
+		workers: workers,
+		logger:  logger,
+	}
+}
+
+// CaptureBatch screenshots every host whose HTTP probe answered 200,
+// storing the file's path in HTTP.Screenshot. A failed capture only skips
+// that host. It returns the number of screenshots taken and any panics
+// recovered from captures.
+func (c *Capturer) CaptureBatch(ctx context.Context, subdomains []*types.Subdomain) (int, error) {
+	var targets []*types.Subdomain
+	for _, sub := range subdomains {
+		if sub.HTTP != nil && sub.HTTP.StatusCode == 200 && sub.HTTP.URL != "" {
+			targets = append(targets, sub)
+		}
+	}
+	if len(targets) == 0 {
+		return 0, nil
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		c.logger.Warn("Screenshots skipped", zap.String("dir", c.dir), zap.Error(err))
+		return 0, nil
+	}
+
+	c.logger.Info("Capturing screenshots",
+		zap.Int("count", len(targets)),
+		zap.Int("workers", c.workers),
+	)
+
+	captured := make(chan struct{}, len(targets))
+	err := pool.Run(ctx, targets, c.workers, func(ctx context.Context, sub *types.Subdomain) {
+		path := filepath.Join(c.dir, fileName(sub.Domain))
+		if err := c.captureOne(ctx, sub.HTTP.URL, path); err != nil {
+			c.logger.Debug("Screenshot failed",
+				zap.String("url", sub.HTTP.URL),
+				zap.Error(err),
+			)
+			return
+		}
+		sub.HTTP.Screenshot = path
+		captured <- struct{}{}
+	})
+
+	c.logger.Info("Screenshots complete", zap.Int("captured", len(captured)))
+	return len(captured), err
+}
+
+// captureOne takes one screenshot within the per-capture timeout, checking
+// the file was actually written
+func (c *Capturer) captureOne(ctx context.Context, url, path string) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	// A stale file from an earlier scan must not pass for this capture
+	os.Remove(path)
+
+	if err := c.capture(ctx, url, path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		return fmt.Errorf("no screenshot written to %s", path)
+	}
+	return nil
+}
+
+// findBrowser resolves the configured browser, or the first known one in
+// PATH
+func findBrowser(configured string) (string, error) {
+	if configured != "" {
+		path, err := exec.LookPath(configured)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrNoBrowser, err)
+		}
+		return path, nil
+	}
+
+	for _, name := range browsers {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", ErrNoBrowser
+}
+
+// chromeCapture screenshots pages with a headless Chrome/Chromium process
+// per capture; certificate errors are ignored like in HTTP probing
+func chromeCapture(browser string, noSandbox bool) CaptureFunc {
+	return func(ctx context.Context, url, path string) error {
+		cmd := exec.CommandContext(ctx, browser, chromeArgs(url, path, noSandbox)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%s: %w: %s", filepath.Base(browser), err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+}
+
+// chromeArgs returns the browser arguments capturing url to path. The
+// sandbox is only disabled when asked (http.screenshots.no_sandbox).
+func chromeArgs(url, path string, noSandbox bool) []string {
+	args := []string{
+		"--headless",
+		"--disable-gpu",
+	}
+	if noSandbox {
+		args = append(args, "--no-sandbox")
+	}
+	return append(args,
+		"--hide-scrollbars",
+		"--ignore-certificate-errors",
+		"--window-size=1280,800",
+		"--screenshot="+path,
+		url,
+	)
+}
+
+// fileName turns a hostname into a screenshot file name
+func fileName(domain string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.ToLower(domain))
+	return name + ".png"
+}
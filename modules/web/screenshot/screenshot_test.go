@@ -0,0 +1,30 @@
+package screenshot
+
+import "testing"
+
+func TestChromeArgsSandbox(t *testing.T) {
+	has := func(args []string, want string) bool {
+		for _, arg := range args {
+			if arg == want {
+				return true
+			}
+		}
+		return false
+	}
+
+	sandboxed := chromeArgs("https://app.example.com", "/tmp/app.png", false)
+	if has(sandboxed, "--no-sandbox") {
+		t.Errorf("sandbox disabled by default: %v", sandboxed)
+	}
+
+	unsandboxed := chromeArgs("https://app.example.com", "/tmp/app.png", true)
+	if !has(unsandboxed, "--no-sandbox") {
+		t.Errorf("no_sandbox not passed on: %v", unsandboxed)
+	}
+
+	for _, args := range [][]string{sandboxed, unsandboxed} {
+		if !has(args, "--screenshot=/tmp/app.png") || args[len(args)-1] != "https://app.example.com" {
+			t.Errorf("capture target missing: %v", args)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/modules/web/webfetch"
+	"go.uber.org/zap"
+)
+
+// maxFileSize is how much of robots.txt or a sitemap is read; the sitemap
+// protocol caps files at 50MB uncompressed, most are far smaller
+const maxFileSize = 10 * 1024 * 1024
+
+// maxSitemaps is how many sitemap files are fetched per host
+const maxSitemaps = 20
+
+// maxPaths is how many robots.txt paths are kept per host
+const maxPaths = 200
+
+// Result is what a host's robots.txt and sitemaps reference
+type Result struct {
+	Hosts    []string // in-scope hostnames, in the order seen
+	Paths    []string // Allow/Disallow paths from robots.txt
+	Sitemaps int      // sitemap files read
+}
+
+// document is a sitemap: a <urlset> of pages or a <sitemapindex> of
+// further sitemaps
+type document struct {
+	XMLName  xml.Name
+	URLs     []location `xml:"url"`
+	Sitemaps []location `xml:"sitemap"`
+}
+
+type location struct {
+	Loc string `xml:"loc"`
+}
+
+// Fetcher reads robots.txt and sitemaps for hostnames and paths
+type Fetcher struct {
+	client   *http.Client
+	logger   *zap.Logger
+	prepare  func(*http.Request)
+	maxDepth int
+}
+
+// NewFetcher creates a fetcher following sitemap indexes down to the
+// configured depth. Files are requested with webfetch.Get through client
+// and prepare.
+func NewFetcher(client *http.Client, cfg *config.WebSourcesConfig, prepare func(*http.Request), logger *zap.Logger) *Fetcher {
+	return &Fetcher{
+		client:   client,
+		logger:   logger,
+		prepare:  prepare,
+		maxDepth: cfg.SitemapDepth,
+	}
+}
+
+// Fetch reads robots.txt at origin (scheme://host) and the sitemaps it
+// lists, or /sitemap.xml when it lists none. Sitemap indexes are followed
+// maxDepth levels down, and only to hosts inScope accepts. It returns the
+// in-scope hosts referenced and robots.txt's paths, with ctx.Err() when
+// interrupted.
+func (f *Fetcher) Fetch(ctx context.Context, origin string, inScope func(host string) bool) (*Result, error) {
+	result := &Result{}
+	seen := make(map[string]bool)
+	addHost := func(rawURL string) *neturl.URL {
+		u, err := neturl.Parse(strings.TrimSpace(rawURL))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil
+		}
+		host := strings.ToLower(u.Hostname())
+		if host == "" || !inScope(host) {
+			return nil
+		}
+		if !seen[host] {
+			seen[host] = true
+			result.Hosts = append(result.Hosts, host)
+		}
+		return u
+	}
+
+	origin = strings.TrimSuffix(origin, "/")
+	sitemaps := []string{origin + "/sitemap.xml"}
+
+	if body, err := webfetch.Get(ctx, f.client, f.prepare, origin+"/robots.txt", maxFileSize); err == nil {
+		robots := webfetch.ParseRobots(body)
+		result.Paths = robotsPaths(robots)
+		if len(robots.Sitemaps) > 0 {
+			sitemaps = robots.Sitemaps
+		}
+	} else {
+		f.logger.Debug("No robots.txt", zap.String("origin", origin), zap.Error(err))
+	}
+
+	// Breadth-first through sitemap indexes, one level per round
+	fetched := make(map[string]bool)
+	for depth := 0; depth <= f.maxDepth && len(sitemaps) > 0; depth++ {
+		var nested []string
+		for _, sitemapURL := range sitemaps {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			if fetched[sitemapURL] || result.Sitemaps >= maxSitemaps || addHost(sitemapURL) == nil {
+				continue
+			}
+			fetched[sitemapURL] = true
+
+			doc, err := f.fetchSitemap(ctx, sitemapURL)
+			if err != nil {
+				f.logger.Debug("Failed to read sitemap", zap.String("url", sitemapURL), zap.Error(err))
+				continue
+			}
+			result.Sitemaps++
+
+			for _, page := range doc.URLs {
+				addHost(page.Loc)
+			}
+			for _, child := range doc.Sitemaps {
+				nested = append(nested, strings.TrimSpace(child.Loc))
+			}
+		}
+		sitemaps = nested
+	}
+
+	if len(sitemaps) > 0 {
+		f.logger.Debug("Sitemap depth reached, nested sitemaps skipped",
+			zap.String("origin", origin),
+			zap.Int("skipped", len(sitemaps)),
+		)
+	}
+
+	return result, ctx.Err()
+}
+
+// fetchSitemap reads and decodes one sitemap, gzipped or not
+func (f *Fetcher) fetchSitemap(ctx context.Context, url string) (*document, error) {
+	body, err := webfetch.Get(ctx, f.client, f.prepare, url, maxFileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []byte(body)
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		if data, err = io.ReadAll(io.LimitReader(reader, maxFileSize)); err != nil {
+			return nil, err
+		}
+	}
+
+	var doc document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// robotsPaths returns the distinct Allow/Disallow paths of every group,
+// up to maxPaths
+func robotsPaths(robots *webfetch.Robots) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, rule := range robots.Rules {
+		if rule.Path == "/" || seen[rule.Path] || len(paths) >= maxPaths {
+			continue
+		}
+		seen[rule.Path] = true
+		paths = append(paths, rule.Path)
+	}
+	return paths
+}
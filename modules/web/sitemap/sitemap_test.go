@@ -0,0 +1,269 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/usr/internal/config"
+	"github.com/yourusername/usr/modules/web/webfetch"
+	"github.com/yourusername/usr/modules/web/webtest"
+	"go.uber.org/zap"
+)
+
+// fetcher returns a fetcher whose requests reach server
+func fetcher(server *webtest.Server, depth int) *Fetcher {
+	return NewFetcher(server.Client(), &config.WebSourcesConfig{SitemapDepth: depth}, nil, zap.NewNop())
+}
+
+// urlset returns a sitemap listing pages
+func urlset(pages ...string) string {
+	var doc strings.Builder
+	doc.WriteString(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	for _, page := range pages {
+		fmt.Fprintf(&doc, "<url><loc>%s</loc></url>", page)
+	}
+	doc.WriteString("</urlset>")
+	return doc.String()
+}
+
+// sitemapIndex returns a sitemap index listing sitemaps
+func sitemapIndex(sitemaps ...string) string {
+	var doc strings.Builder
+	doc.WriteString(`<?xml version="1.0" encoding="UTF-8"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	for _, sitemap := range sitemaps {
+		fmt.Fprintf(&doc, "<sitemap><loc> %s </loc></sitemap>", sitemap)
+	}
+	doc.WriteString("</sitemapindex>")
+	return doc.String()
+}
+
+// gzipped compresses a sitemap as a .xml.gz file
+func gzipped(t *testing.T, content string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestFetchFollowsSitemapIndex(t *testing.T) {
+	server := webtest.NewServer(t, map[string]string{
+		"www.example.com/robots.txt": "User-agent: *\nDisallow: /admin/\nAllow: /admin/public\n" +
+			"Sitemap: http://www.example.com/sitemap_index.xml\n",
+		"www.example.com/sitemap_index.xml": sitemapIndex(
+			"http://www.example.com/sitemap-pages.xml",
+			"http://static.example.com/sitemap-media.xml.gz",
+			"http://sitemaps.example.org/sitemap-partner.xml",
+		),
+		"www.example.com/sitemap-pages.xml": urlset(
+			"http://www.example.com/about",
+			"https://shop.example.com/cart",
+			"https://evil.example.org/phish",
+		),
+		"static.example.com/sitemap-media.xml.gz":  gzipped(t, urlset("https://blog.example.com/2024/launch")),
+		"sitemaps.example.org/sitemap-partner.xml": urlset("https://partner.example.com/"),
+	})
+
+	result, err := fetcher(server, 1).Fetch(context.Background(), "http://www.example.com/", webtest.InExampleScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := append([]string(nil), result.Hosts...)
+	sort.Strings(hosts)
+	wantHosts := []string{"blog.example.com", "shop.example.com", "static.example.com", "www.example.com"}
+	if !reflect.DeepEqual(hosts, wantHosts) {
+		t.Errorf("hosts = %v, want %v", hosts, wantHosts)
+	}
+	if wantPaths := []string{"/admin/", "/admin/public"}; !reflect.DeepEqual(result.Paths, wantPaths) {
+		t.Errorf("paths = %v, want %v", result.Paths, wantPaths)
+	}
+	if result.Sitemaps != 3 {
+		t.Errorf("read %d sitemaps, want 3", result.Sitemaps)
+	}
+
+	// Out-of-scope sitemaps aren't fetched, and with robots.txt listing
+	// sitemaps the default isn't tried
+	if server.Count("sitemaps.example.org/sitemap-partner.xml") != 0 {
+		t.Error("out-of-scope sitemap fetched")
+	}
+	if server.Count("www.example.com/sitemap.xml") != 0 {
+		t.Error("default sitemap fetched although robots.txt lists one")
+	}
+}
+
+func TestFetchBoundsNestedSitemaps(t *testing.T) {
+	// index-0 -> index-1 -> index-2 -> pages, and index-1 also lists itself
+	files := map[string]string{
+		"www.example.com/sitemap.xml": sitemapIndex("http://www.example.com/index-1.xml"),
+		"www.example.com/index-1.xml": sitemapIndex("http://www.example.com/index-2.xml", "http://www.example.com/index-1.xml"),
+		"www.example.com/index-2.xml": sitemapIndex("http://www.example.com/pages.xml"),
+		"www.example.com/pages.xml":   urlset("https://deep.example.com/"),
+	}
+
+	tests := []struct {
+		depth    int
+		sitemaps int
+		deep     bool
+	}{
+		{depth: 0, sitemaps: 1},
+		{depth: 1, sitemaps: 2},
+		{depth: 2, sitemaps: 3},
+		{depth: 3, sitemaps: 4, deep: true},
+		{depth: 10, sitemaps: 4, deep: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("depth %d", tt.depth), func(t *testing.T) {
+			server := webtest.NewServer(t, files)
+
+			result, err := fetcher(server, tt.depth).Fetch(context.Background(), "http://www.example.com", webtest.InExampleScope)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Sitemaps != tt.sitemaps {
+				t.Errorf("read %d sitemaps, want %d", result.Sitemaps, tt.sitemaps)
+			}
+			found := false
+			for _, host := range result.Hosts {
+				found = found || host == "deep.example.com"
+			}
+			if found != tt.deep {
+				t.Errorf("deep.example.com found %v, want %v", found, tt.deep)
+			}
+			if n := server.Count("www.example.com/index-1.xml"); n > 1 {
+				t.Errorf("self-listing index fetched %d times", n)
+			}
+		})
+	}
+}
+
+func TestFetchCapsSitemaps(t *testing.T) {
+	files := map[string]string{}
+	var children []string
+	for i := 0; i < maxSitemaps+10; i++ {
+		child := fmt.Sprintf("http://www.example.com/sitemap-%d.xml", i)
+		children = append(children, child)
+		files[fmt.Sprintf("www.example.com/sitemap-%d.xml", i)] = urlset(fmt.Sprintf("https://host%d.example.com/", i))
+	}
+	files["www.example.com/sitemap.xml"] = sitemapIndex(children...)
+	server := webtest.NewServer(t, files)
+
+	result, err := fetcher(server, 1).Fetch(context.Background(), "http://www.example.com", webtest.InExampleScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Sitemaps != maxSitemaps {
+		t.Errorf("read %d sitemaps, want %d", result.Sitemaps, maxSitemaps)
+	}
+}
+
+func TestFetchWithoutFiles(t *testing.T) {
+	server := webtest.NewServer(t, map[string]string{
+		"www.example.com/sitemap.xml": "<html>not a sitemap",
+	})
+
+	result, err := fetcher(server, 2).Fetch(context.Background(), "http://www.example.com", webtest.InExampleScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Sitemaps != 0 || len(result.Paths) != 0 {
+		t.Errorf("got %+v, want nothing", result)
+	}
+	if server.Count("www.example.com/robots.txt") != 1 || server.Count("www.example.com/sitemap.xml") != 1 {
+		t.Error("robots.txt and the default sitemap not both tried")
+	}
+}
+
+func TestFetchStopsOnCancel(t *testing.T) {
+	server := webtest.NewServer(t, map[string]string{
+		"www.example.com/sitemap.xml": urlset("https://shop.example.com/"),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := fetcher(server, 1).Fetch(ctx, "http://www.example.com", webtest.InExampleScope)
+	if err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if result.Sitemaps != 0 {
+		t.Errorf("read %d sitemaps after cancel", result.Sitemaps)
+	}
+}
+
+func TestRobotsPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "every group",
+			body: "User-agent: Googlebot\nDisallow: /search\n\nUser-agent: *\nDisallow: /admin/ # staff\nAllow: /admin/login\n",
+			want: []string{"/search", "/admin/", "/admin/login"},
+		},
+		{
+			name: "root and empty rules skipped",
+			body: "User-agent: *\nDisallow: /\nDisallow:\nAllow: /\n",
+		},
+		{
+			name: "duplicates kept once",
+			body: "User-agent: a\nDisallow: /private\nUser-agent: b\nDisallow: /private\n",
+			want: []string{"/private"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := robotsPaths(webfetch.ParseRobots(tt.body)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paths = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRobotsPathsCapped(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < maxPaths+50; i++ {
+		fmt.Fprintf(&body, "Disallow: /p%d\n", i)
+	}
+	if paths := robotsPaths(webfetch.ParseRobots(body.String())); len(paths) != maxPaths {
+		t.Errorf("got %d paths, want %d", len(paths), maxPaths)
+	}
+}
+
+func TestFetchIgnoresErrorRobots(t *testing.T) {
+	server := webtest.NewServer(t, map[string]string{
+		"www.example.com/sitemap.xml": urlset("https://shop.example.com/"),
+	})
+	server.SetPage("www.example.com/robots.txt", webtest.Page{
+		Status: http.StatusForbidden,
+		Body:   "Disallow: /secret\nSitemap: http://www.example.com/other.xml\n",
+	})
+
+	result, err := fetcher(server, 1).Fetch(context.Background(), "http://www.example.com", webtest.InExampleScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Paths) != 0 || server.Count("www.example.com/other.xml") != 0 {
+		t.Errorf("read a 403 robots.txt: paths %v", result.Paths)
+	}
+	if result.Sitemaps != 1 {
+		t.Errorf("read %d sitemaps, want the default one", result.Sitemaps)
+	}
+}
@@ -0,0 +1,81 @@
+package webfetch
+
+import (
+	"bufio"
+	"strings"
+)
+
+// Rule is an Allow or Disallow line of robots.txt
+type Rule struct {
+	Path      string
+	Allow     bool
+	AllAgents bool // in a group that applies to every user agent ("*")
+}
+
+// Robots is what a robots.txt file lists
+type Robots struct {
+	Rules    []Rule   // in file order, empty paths left out
+	Sitemaps []string // Sitemap URLs
+}
+
+// ParseRobots reads a robots.txt file. Consecutive User-agent lines start
+// one group; comments, unknown fields and malformed lines are ignored.
+func ParseRobots(body string) *Robots {
+	var (
+		robots   Robots
+		applies  bool // the current group includes "*"
+		inAgents bool // reading a group's User-agent lines
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		field, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		switch field {
+		case "user-agent":
+			if !inAgents {
+				applies = false
+			}
+			inAgents = true
+			if value == "*" {
+				applies = true
+			}
+			continue
+		case "allow", "disallow":
+			if value != "" {
+				robots.Rules = append(robots.Rules, Rule{Path: value, Allow: field == "allow", AllAgents: applies})
+			}
+		case "sitemap":
+			if value != "" {
+				robots.Sitemaps = append(robots.Sitemaps, value)
+			}
+		}
+		inAgents = false
+	}
+	return &robots
+}
+
+// Disallowed returns the Disallow path prefixes that apply to every user
+// agent. Wildcards end the prefix; Allow lines are not supported.
+func (r *Robots) Disallowed() []string {
+	var prefixes []string
+	for _, rule := range r.Rules {
+		if rule.Allow || !rule.AllAgents {
+			continue
+		}
+		prefix := rule.Path
+		if i := strings.IndexAny(prefix, "*$"); i >= 0 {
+			prefix = prefix[:i]
+		}
+		if prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,106 @@
+package webfetch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		rules    []Rule
+		sitemaps []string
+	}{
+		{
+			name: "groups",
+			body: "User-agent: Googlebot\nDisallow: /google\n\nUser-agent: *\nDisallow: /admin/ # staff\nAllow: /admin/login\n",
+			rules: []Rule{
+				{Path: "/google"},
+				{Path: "/admin/", AllAgents: true},
+				{Path: "/admin/login", Allow: true, AllAgents: true},
+			},
+		},
+		{
+			name:  "shared group",
+			body:  "User-agent: Bingbot\nUser-agent: *\nDisallow: /shared\n",
+			rules: []Rule{{Path: "/shared", AllAgents: true}},
+		},
+		{
+			name:  "next group resets agents",
+			body:  "User-agent: *\nDisallow: /all\nUser-agent: Bingbot\nDisallow: /bing\n",
+			rules: []Rule{{Path: "/all", AllAgents: true}, {Path: "/bing"}},
+		},
+		{
+			name:  "rules outside a group",
+			body:  "Disallow: /orphan\n",
+			rules: []Rule{{Path: "/orphan"}},
+		},
+		{
+			name: "empty values skipped",
+			body: "User-agent: *\nDisallow:\nAllow:\nSitemap:\n",
+		},
+		{
+			name:     "sitemaps",
+			body:     "Sitemap: https://www.example.com/sitemap.xml\nsitemap:https://cdn.example.com/sitemap.xml.gz\n",
+			sitemaps: []string{"https://www.example.com/sitemap.xml", "https://cdn.example.com/sitemap.xml.gz"},
+		},
+		{
+			name:  "case, spacing and CRLF",
+			body:  "USER-AGENT : *\r\n  disallow :  /Cgi-Bin  \r\n",
+			rules: []Rule{{Path: "/Cgi-Bin", AllAgents: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			robots := ParseRobots(tt.body)
+			if !reflect.DeepEqual(robots.Rules, tt.rules) {
+				t.Errorf("rules = %+v, want %+v", robots.Rules, tt.rules)
+			}
+			if !reflect.DeepEqual(robots.Sitemaps, tt.sitemaps) {
+				t.Errorf("sitemaps = %q, want %q", robots.Sitemaps, tt.sitemaps)
+			}
+		})
+	}
+}
+
+func TestDisallowed(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "all agents",
+			body: "User-agent: *\nDisallow: /admin\nDisallow: /private/ # staff only\n",
+			want: []string{"/admin", "/private/"},
+		},
+		{
+			name: "other agents and allow lines ignored",
+			body: "User-agent: Googlebot\nDisallow: /google\n\nUser-agent: *\nAllow: /open\nDisallow: /all\n",
+			want: []string{"/all"},
+		},
+		{
+			name: "wildcards end the prefix",
+			body: "User-agent: *\nDisallow: /search*q=\nDisallow: /*.php$\nDisallow: /tmp$\n",
+			want: []string{"/search", "/", "/tmp"},
+		},
+		{
+			name: "leading wildcard drops the rule",
+			body: "User-agent: *\nDisallow: *.gif\n",
+		},
+		{
+			name: "empty disallow allows all",
+			body: "User-agent: *\nDisallow:\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRobots(tt.body).Disallowed(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Disallowed = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
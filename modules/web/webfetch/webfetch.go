@@ -0,0 +1,41 @@
+// Package webfetch holds what the link crawler and the sitemap fetcher
+// share: a bounded GET that only returns successful responses, and a
+// robots.txt parser
+package webfetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Get requests url through client, normally the HTTP prober's, after
+// prepare (optional) has set the User-Agent and headers. Redirects are
+// followed; a final status outside 2xx is an error, so error pages are
+// never mistaken for content. At most limit bytes of the body are read.
+func Get(ctx context.Context, client *http.Client, prepare func(*http.Request), url string, limit int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	if prepare != nil {
+		prepare(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
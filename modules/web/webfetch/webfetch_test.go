@@ -0,0 +1,69 @@
+package webfetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("hello " + r.Header.Get("User-Agent")))
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("made"))
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/large":
+			w.Write([]byte(strings.Repeat("x", 100)))
+		case "/broken":
+			http.Error(w, "<a href=/elsewhere>oops</a>", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	prepare := func(req *http.Request) { req.Header.Set("User-Agent", "tester") }
+
+	tests := []struct {
+		path    string
+		limit   int64
+		want    string
+		wantErr string
+	}{
+		{path: "/ok", want: "hello tester"},
+		{path: "/created", want: "made"},
+		{path: "/moved", want: "hello tester"},
+		{path: "/large", limit: 10, want: strings.Repeat("x", 10)},
+		{path: "/missing", wantErr: "status 404"},
+		{path: "/broken", wantErr: "status 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			limit := tt.limit
+			if limit == 0 {
+				limit = 1024
+			}
+
+			body, err := Get(context.Background(), server.Client(), prepare, server.URL+tt.path, limit)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("err = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package webtest provides an HTTP server for tests that serves pages on
+// any hostname, so code following links across hosts can be exercised
+// without DNS
+package webtest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Page is a response the server gives for a host+path
+type Page struct {
+	Status int // 0 means 200
+	Body   string
+}
+
+// Server serves pages keyed by host+path (e.g. "www.example.com/about"),
+// answering 404 for any other, and counts the requests for each key
+type Server struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	pages    map[string]Page
+	requests map[string]int
+}
+
+// NewServer starts a server answering with the given bodies, shut down
+// when the test ends
+func NewServer(t testing.TB, bodies map[string]string) *Server {
+	t.Helper()
+
+	s := &Server{
+		pages:    make(map[string]Page),
+		requests: make(map[string]int),
+	}
+	for key, body := range bodies {
+		s.pages[key] = Page{Body: body}
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// SetPage serves page for host+path key
+func (s *Server) SetPage(key string, page Page) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[key] = page
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	key := r.Host + r.URL.Path
+
+	s.mu.Lock()
+	s.requests[key]++
+	page, ok := s.pages[key]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if page.Status != 0 {
+		w.WriteHeader(page.Status)
+	}
+	w.Write([]byte(page.Body))
+}
+
+// Client returns a client whose requests, whatever their host, reach the
+// server
+func (s *Server) Client() *http.Client {
+	addr := s.server.Listener.Addr().String()
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+// Count returns how often host+path was requested
+func (s *Server) Count(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[key]
+}
+
+// Requested returns the host+path keys requested, sorted
+func (s *Server) Requested() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.requests {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// InExampleScope accepts example.com and its subdomains, the scope the
+// test pages are written for
+func InExampleScope(host string) bool {
+	return host == "example.com" || strings.HasSuffix(host, ".example.com")
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// atomicFile writes to a temporary sibling and only replaces the destination
+// on Commit, so a failed export never clobbers an existing report
+type atomicFile struct {
+	*os.File
+	path      string
+	committed bool
+}
+
+// createAtomic opens a temporary file next to path for writing
+func createAtomic(path string) (*atomicFile, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &atomicFile{
+		File: file,
+		path: path,
+	}, nil
+}
+
+// Commit flushes the temporary file and renames it over the destination
+func (f *atomicFile) Commit() error {
+	if err := f.File.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := f.File.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	if err := os.Chmod(f.File.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Rename(f.File.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", f.path, err)
+	}
+
+	f.committed = true
+	return nil
+}
+
+// Close discards the temporary file unless it was committed
+func (f *atomicFile) Close() error {
+	if f.committed {
+		return nil
+	}
+
+	f.File.Close()
+	return os.Remove(f.File.Name())
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// tempFiles lists the export temp files left in dir
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	return matches
+}
+
+func TestFailedExportKeepsPreviousFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "results.jsonl")
+	previous := []byte(`{"domain":"old.example.com"}` + "\n")
+	if err := os.WriteFile(path, previous, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second line can't be encoded, after the first was written
+	subdomains := []*types.Subdomain{
+		{Domain: "www.example.com"},
+		{Domain: "bad.example.com", Metadata: map[string]interface{}{"unencodable": make(chan int)}},
+	}
+
+	for _, format := range []string{"jsonl", "json"} {
+		err := NewExporter(zap.NewNop()).Export(context.Background(), subdomains, format, path)
+		if err == nil {
+			t.Fatalf("%s export of an unencodable value succeeded", format)
+		}
+
+		data, readErr := os.ReadFile(path)
+		if readErr != nil || string(data) != string(previous) {
+			t.Errorf("after a failed %s export the file holds %q (%v), want the previous report", format, data, readErr)
+		}
+		if left := tempFiles(t, dir); len(left) > 0 {
+			t.Errorf("failed %s export left %v behind", format, left)
+		}
+	}
+}
+
+func TestExportReplacesPreviousFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "results.txt")
+	if err := os.WriteFile(path, []byte("old.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	subdomains := []*types.Subdomain{{Domain: "www.example.com"}}
+	if err := NewExporter(zap.NewNop()).Export(context.Background(), subdomains, "txt", path); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "www.example.com") || strings.Contains(string(data), "old.example.com") {
+		t.Errorf("export holds %q (%v), want only the new results", data, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat export: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0644 {
+		t.Errorf("export mode = %v, want 0644", mode)
+	}
+	if left := tempFiles(t, dir); len(left) > 0 {
+		t.Errorf("export left %v behind", left)
+	}
+}
@@ -0,0 +1,131 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/modules/web/cloud"
+	"github.com/yourusername/usr/storage/diff"
+	"go.uber.org/zap"
+)
+
+// bundleFormats are the result formats written into every bundle
+var bundleFormats = []string{"json", "jsonl", "csv", "html"}
+
+// HostFinding is a finding together with the host it was seen on
+type HostFinding struct {
+	Domain string `json:"domain"`
+	types.Finding
+}
+
+// SetCloudAssets attaches discovered cloud assets to bundles
+func (e *Exporter) SetCloudAssets(assets []cloud.CloudAsset) {
+	e.cloudAssets = assets
+}
+
+// SetChanges attaches the comparison with the previous scan to bundles
+func (e *Exporter) SetChanges(changes *diff.DiffResult) {
+	e.changes = changes
+}
+
+// BundleDir returns the directory name ExportAll uses for a domain
+func BundleDir(domain string, at time.Time) string {
+	if domain == "" {
+		domain = "scan"
+	}
+	return fmt.Sprintf("usr-%s-%s", strings.ReplaceAll(domain, "/", "_"), at.Format("20060102-150405"))
+}
+
+// ExportAll writes the full deliverable for a scan into a new
+// usr-<domain>-<timestamp> directory under parentDir: every result format,
+// the manifest, findings, errors, expected-but-missing known subdomains
+// and, when attached, cloud assets and the changes since the previous scan. It returns the directory created.
+func (e *Exporter) ExportAll(ctx context.Context, subdomains []*types.Subdomain, domain, parentDir string) (string, error) {
+	if parentDir == "" {
+		parentDir = "."
+	}
+	dir := filepath.Join(parentDir, BundleDir(domain, time.Now()))
+
+	if err := e.ExportMultiple(ctx, subdomains, bundleFormats, dir); err != nil {
+		return "", err
+	}
+
+	var failed []string
+	write := func(name string, value interface{}) {
+		if err := writeJSON(filepath.Join(dir, name), value); err != nil {
+			e.logger.Error("Failed to export bundle file",
+				zap.String("file", name),
+				zap.Error(err),
+			)
+			failed = append(failed, name)
+		}
+	}
+
+	write("findings.json", collectFindings(subdomains))
+
+	if e.stats != nil {
+		if err := e.ExportErrors(ctx, e.stats.Errors, filepath.Join(dir, "errors.json")); err != nil {
+			e.logger.Error("Failed to export errors report", zap.Error(err))
+			failed = append(failed, "errors.json")
+		}
+		if len(e.stats.MissingKnown) > 0 {
+			write("missing_known.json", e.stats.MissingKnown)
+		}
+	}
+
+	if e.cloudAssets != nil {
+		write("cloud_assets.json", e.cloudAssets)
+	}
+
+	if e.changes != nil {
+		write("changes.json", e.changes)
+	}
+
+	e.logger.Info("Bundle export complete",
+		zap.String("dir", dir),
+		zap.Int("count", len(subdomains)),
+	)
+
+	if len(failed) > 0 {
+		return dir, fmt.Errorf("failed to write %s", strings.Join(failed, ", "))
+	}
+	return dir, nil
+}
+
+// collectFindings flattens per-host findings into one list
+func collectFindings(subdomains []*types.Subdomain) []HostFinding {
+	findings := []HostFinding{}
+	for _, sub := range subdomains {
+		for _, finding := range sub.Findings {
+			findings = append(findings, HostFinding{Domain: sub.Domain, Finding: finding})
+		}
+	}
+	return findings
+}
+
+// writeJSON writes a value as indented JSON, replacing path atomically
+func writeJSON(path string, value interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	file, err := createAtomic(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+
+	return file.Commit()
+}
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yourusername/usr/core/orchestrator"
+	"go.uber.org/zap"
+)
+
+// errorsReport is the document written by ExportErrors
+type errorsReport struct {
+	Total  int                      `json:"total"`
+	ByKind map[string]int           `json:"by_kind"`
+	Errors []orchestrator.ScanError `json:"errors"`
+}
+
+// ExportErrors writes the scan's failures, grouped by kind, as JSON
+func (e *Exporter) ExportErrors(ctx context.Context, errs []orchestrator.ScanError, outputPath string) error {
+	file, err := createAtomic(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	report := errorsReport{
+		Total:  len(errs),
+		ByKind: make(map[string]int),
+		Errors: errs,
+	}
+	if report.Errors == nil {
+		report.Errors = []orchestrator.ScanError{}
+	}
+	for _, scanErr := range errs {
+		report.ByKind[scanErr.Kind]++
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode errors report: %w", err)
+	}
+
+	if err := file.Commit(); err != nil {
+		return err
+	}
+
+	e.logger.Info("Errors report export complete",
+		zap.String("path", outputPath),
+		zap.Int("errors", len(errs)),
+	)
+	return nil
+}
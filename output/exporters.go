@@ -8,16 +8,34 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/yourusername/usr/core/orchestrator"
+	"github.com/yourusername/usr/intelligence/classify"
+	"github.com/yourusername/usr/intelligence/pivot"
 	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/modules/web/cloud"
+	"github.com/yourusername/usr/storage/diff"
 	"go.uber.org/zap"
 )
 
 // Exporter handles output formatting and export
 type Exporter struct {
-	logger *zap.Logger
+	logger       *zap.Logger
+	registration *types.Registration
+	baseline     *types.DNSRecords
+	leads        *pivot.Leads
+	manifest     *Manifest
+	stats        *orchestrator.Statistics
+	templatePath string
+	fields       []string
+	tags         []string
+	statusCodes  []int
+	cloudAssets  []cloud.CloudAsset
+	changes      *diff.DiffResult
+	trends       *diff.TrendAnalysis
 }
 
 // NewExporter creates a new exporter
@@ -27,8 +45,84 @@ func NewExporter(logger *zap.Logger) *Exporter {
 	}
 }
 
+// SetRegistration attaches apex registration data to reports
+func (e *Exporter) SetRegistration(reg *types.Registration) {
+	e.registration = reg
+}
+
+// SetBaseline attaches the apex baseline records to reports
+func (e *Exporter) SetBaseline(records *types.DNSRecords) {
+	e.baseline = records
+}
+
+// SetLeads attaches certificate pivot leads to reports
+func (e *Exporter) SetLeads(leads *pivot.Leads) {
+	e.leads = leads
+}
+
+// SetTrends attaches the domain's change history to the HTML report
+func (e *Exporter) SetTrends(trends *diff.TrendAnalysis) {
+	e.trends = trends
+}
+
+// SetTagFilter limits exports to subdomains carrying any of the given tags;
+// a bare namespace such as "environment" matches all tags within it
+func (e *Exporter) SetTagFilter(tags []string) {
+	e.tags = tags
+}
+
+// filterTags applies the tag filter, if any
+func (e *Exporter) filterTags(subdomains []*types.Subdomain) []*types.Subdomain {
+	if len(e.tags) == 0 {
+		return subdomains
+	}
+	
+	var filtered []*types.Subdomain
+	for _, sub := range subdomains {
+		if classify.Match(sub, e.tags) {
+			filtered = append(filtered, sub)
+		}
+	}
+	return filtered
+}
+
+// SetStatusFilter limits exports to subdomains whose HTTP probe returned
+// one of the given status codes; hosts without an HTTP result are dropped
+func (e *Exporter) SetStatusFilter(codes []int) {
+	e.statusCodes = codes
+}
+
+// filterStatus applies the status code filter, if any
+func (e *Exporter) filterStatus(subdomains []*types.Subdomain) []*types.Subdomain {
+	if len(e.statusCodes) == 0 {
+		return subdomains
+	}
+	
+	var filtered []*types.Subdomain
+	for _, sub := range subdomains {
+		if sub.HTTP == nil {
+			continue
+		}
+		for _, code := range e.statusCodes {
+			if sub.HTTP.StatusCode == code {
+				filtered = append(filtered, sub)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// SupportedFormats returns the format names Export accepts
+func SupportedFormats() []string {
+	return []string{"json", "jsonl", "csv", "txt", "html", "nuclei", "burp", "template", "all"}
+}
+
 // Export exports subdomains in the specified format
 func (e *Exporter) Export(ctx context.Context, subdomains []*types.Subdomain, format, outputPath string) error {
+	subdomains = e.filterTags(subdomains)
+	subdomains = e.filterStatus(subdomains)
+	
 	e.logger.Info("Exporting results",
 		zap.String("format", format),
 		zap.String("path", outputPath),
@@ -38,6 +132,8 @@ func (e *Exporter) Export(ctx context.Context, subdomains []*types.Subdomain, fo
 	switch strings.ToLower(format) {
 	case "json":
 		return e.ExportJSON(ctx, subdomains, outputPath)
+	case "jsonl":
+		return e.ExportJSONL(ctx, subdomains, outputPath)
 	case "csv":
 		return e.ExportCSV(ctx, subdomains, outputPath)
 	case "txt", "text":
@@ -48,14 +144,35 @@ func (e *Exporter) Export(ctx context.Context, subdomains []*types.Subdomain, fo
 		return e.ExportNuclei(ctx, subdomains, outputPath)
 	case "burp":
 		return e.ExportBurp(ctx, subdomains, outputPath)
+	case "template", "tmpl":
+		return e.ExportTemplate(ctx, subdomains, e.templatePath, outputPath)
+	case "all":
+		// outputPath is the parent of the bundle directory here
+		domain := ""
+		if e.manifest != nil {
+			domain = e.manifest.Domain
+		}
+		_, err := e.ExportAll(ctx, subdomains, domain, outputPath)
+		return err
 	default:
 		return fmt.Errorf("unsupported format: %s", format)
 	}
 }
 
+// jsonResults is the document ExportJSON writes. Schema("results") is
+// generated from it, so the two cannot drift apart.
+type jsonResults struct {
+	GeneratedAt  time.Time           `json:"generated_at"`
+	TotalCount   int                 `json:"total_count"`
+	Subdomains   interface{}         `json:"subdomains"`
+	Registration *types.Registration `json:"registration,omitempty"`
+	ApexBaseline *types.DNSRecords   `json:"apex_baseline,omitempty"`
+	Related      *pivot.Leads        `json:"related,omitempty"`
+}
+
 // ExportJSON exports subdomains as JSON
 func (e *Exporter) ExportJSON(ctx context.Context, subdomains []*types.Subdomain, outputPath string) error {
-	file, err := os.Create(outputPath)
+	file, err := createAtomic(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -64,35 +181,99 @@ func (e *Exporter) ExportJSON(ctx context.Context, subdomains []*types.Subdomain
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
 	
-	output := map[string]interface{}{
-		"generated_at": time.Now().Format(time.RFC3339),
-		"total_count":  len(subdomains),
-		"subdomains":   subdomains,
+	output := jsonResults{
+		GeneratedAt:  time.Now().Truncate(time.Second),
+		TotalCount:   len(subdomains),
+		Subdomains:   e.projectSubdomains(subdomains),
+		Registration: e.registration,
+		ApexBaseline: e.baseline,
+	}
+	if e.leads.Count() > 0 {
+		output.Related = e.leads
 	}
 	
 	if err := encoder.Encode(output); err != nil {
 		return fmt.Errorf("failed to encode JSON: %w", err)
 	}
 	
+	if err := file.Commit(); err != nil {
+		return err
+	}
+	
 	e.logger.Info("JSON export complete", zap.String("path", outputPath))
 	return nil
 }
 
+// ExportJSONL exports subdomains as JSON Lines (one object per line)
+func (e *Exporter) ExportJSONL(ctx context.Context, subdomains []*types.Subdomain, outputPath string) error {
+	file, err := createAtomic(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+	
+	encoder := json.NewEncoder(file)
+	
+	for _, sub := range subdomains {
+		var record interface{} = sub
+		if len(e.fields) > 0 {
+			record = selectFields(sub, e.fields)
+		}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode JSON line: %w", err)
+		}
+	}
+	
+	if err := file.Commit(); err != nil {
+		return err
+	}
+	
+	e.logger.Info("JSONL export complete", zap.String("path", outputPath))
+	return nil
+}
+
 // ExportCSV exports subdomains as CSV
 func (e *Exporter) ExportCSV(ctx context.Context, subdomains []*types.Subdomain, outputPath string) error {
-	file, err := os.Create(outputPath)
+	file, err := createAtomic(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
 	defer file.Close()
 	
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	
+	// Selected fields replace the default columns
+	if len(e.fields) > 0 {
+		if err := writer.Write(e.fields); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+		for _, sub := range subdomains {
+			record := make([]string, len(e.fields))
+			for i, field := range e.fields {
+				record[i] = formatField(resolveField(sub, field))
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write record: %w", err)
+			}
+		}
+		
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		
+		if err := file.Commit(); err != nil {
+			return err
+		}
+		
+		e.logger.Info("CSV export complete", zap.String("path", outputPath))
+		return nil
+	}
 	
 	// Write header
 	header := []string{
 		"Domain", "IP", "Confidence", "Validated", "Sources",
-		"HTTP_Status", "HTTP_Title", "Technologies", "First_Seen", "Last_Seen",
+		"HTTP_Status", "HTTP_Title", "Technologies", "CDN", "Private_IP", "Parked", "Tags", "First_Seen", "Last_Seen",
 	}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
@@ -119,6 +300,18 @@ func (e *Exporter) ExportCSV(ctx context.Context, subdomains []*types.Subdomain,
 			record = append(record, "", "", "")
 		}
 		
+		// CDN/WAF provider
+		record = append(record, metadataString(sub, "cdn"))
+		
+		// Internal address exposure
+		record = append(record, fmt.Sprintf("%v", sub.Metadata["private_ip"] == true))
+		
+		// Parking/placeholder page
+		record = append(record, fmt.Sprintf("%v", sub.Metadata["parked"] == true))
+		
+		// Classification
+		record = append(record, strings.Join(sub.Tags, ";"))
+		
 		// Timestamps
 		record = append(record,
 			sub.FirstSeen.Format(time.RFC3339),
@@ -130,13 +323,22 @@ func (e *Exporter) ExportCSV(ctx context.Context, subdomains []*types.Subdomain,
 		}
 	}
 	
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	
+	if err := file.Commit(); err != nil {
+		return err
+	}
+	
 	e.logger.Info("CSV export complete", zap.String("path", outputPath))
 	return nil
 }
 
 // ExportText exports subdomains as plain text (one per line)
 func (e *Exporter) ExportText(ctx context.Context, subdomains []*types.Subdomain, outputPath string) error {
-	file, err := os.Create(outputPath)
+	file, err := createAtomic(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -148,6 +350,10 @@ func (e *Exporter) ExportText(ctx context.Context, subdomains []*types.Subdomain
 		}
 	}
 	
+	if err := file.Commit(); err != nil {
+		return err
+	}
+	
 	e.logger.Info("Text export complete", zap.String("path", outputPath))
 	return nil
 }
@@ -180,9 +386,22 @@ func (e *Exporter) ExportHTML(ctx context.Context, subdomains []*types.Subdomain
         .badge { display: inline-block; padding: 3px 8px; background: #2a2f4a; border-radius: 4px; font-size: 0.8em; margin: 2px; }
         .http-ok { color: #00ff88; }
         .http-error { color: #ff4444; }
+        .severity-high { color: #ff4444; }
+        .severity-medium { color: #ffaa00; }
+        .thumb { display: block; width: 160px; margin-top: 6px; border: 1px solid #2a2f4a; border-radius: 4px; }
         .filter { margin: 20px 0; padding: 15px; background: #151932; border-radius: 8px; }
         .filter input { background: #0a0e27; border: 1px solid #2a2f4a; color: #e0e0e0; padding: 10px; border-radius: 4px; width: 300px; font-size: 1em; }
+        .filter select { background: #0a0e27; border: 1px solid #2a2f4a; color: #e0e0e0; padding: 10px; border-radius: 4px; margin-left: 10px; font-size: 1em; }
         .filter input:focus { outline: none; border-color: #00ff88; }
+        .registration { background: #151932; border-radius: 8px; padding: 20px; margin: 20px 0; display: grid; grid-template-columns: repeat(auto-fit, minmax(250px, 1fr)); gap: 10px 20px; }
+        .registration span { color: #888; margin-right: 6px; }
+        .leads { background: #151932; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .leads h2 { color: #00ff88; font-size: 1.2em; margin-bottom: 10px; }
+        .leads li { list-style: none; margin: 6px 0; }
+        .histogram-row { display: flex; align-items: center; margin: 4px 0; }
+        .histogram-label { width: 70px; color: #888; }
+        .histogram-bar { height: 16px; background: #00ff88; border-radius: 3px; margin-right: 8px; min-width: 2px; }
+        .histogram-bar.removed { background: #ff4444; }
     </style>
 </head>
 <body>
@@ -190,6 +409,26 @@ func (e *Exporter) ExportHTML(ctx context.Context, subdomains []*types.Subdomain
         <h1>🔍 USR Reconnaissance Report</h1>
         <p style="color: #888; margin-bottom: 30px;">Generated: {{.GeneratedAt}}</p>
         
+        {{with .Registration}}
+        <div class="registration">
+            <div><span>Domain:</span>{{.Domain}}</div>
+            {{if .Registrar}}<div><span>Registrar:</span>{{.Registrar}}</div>{{end}}
+            {{if .Registrant}}<div><span>Registrant:</span>{{.Registrant}}</div>{{end}}
+            {{if not .Created.IsZero}}<div><span>Created:</span>{{.Created.Format "2006-01-02"}}</div>{{end}}
+            {{if not .Expires.IsZero}}<div><span>Expires:</span>{{.Expires.Format "2006-01-02"}}</div>{{end}}
+            {{if .Nameservers}}<div><span>Nameservers:</span>{{range .Nameservers}}<div class="badge">{{.}}</div>{{end}}</div>{{end}}
+        </div>
+        {{end}}
+        
+        {{with .Baseline}}
+        <div class="registration">
+            {{if .A}}<div><span>Apex A:</span>{{range .A}}<div class="badge">{{.}}</div>{{end}}</div>{{end}}
+            {{if .AAAA}}<div><span>Apex AAAA:</span>{{range .AAAA}}<div class="badge">{{.}}</div>{{end}}</div>{{end}}
+            {{if .MX}}<div><span>Apex MX:</span>{{range .MX}}<div class="badge">{{.}}</div>{{end}}</div>{{end}}
+            {{if .NS}}<div><span>Apex NS:</span>{{range .NS}}<div class="badge">{{.}}</div>{{end}}</div>{{end}}
+        </div>
+        {{end}}
+        
         <div class="stats">
             <div class="stat">
                 <div class="stat-value">{{.TotalCount}}</div>
@@ -205,8 +444,61 @@ func (e *Exporter) ExportHTML(ctx context.Context, subdomains []*types.Subdomain
             </div>
         </div>
         
+        {{with .Histogram}}
+        <div class="leads">
+            <h2>Confidence Distribution</h2>
+            {{range .Bars}}
+            <div class="histogram-row">
+                <div class="histogram-label">{{.Label}}</div>
+                <div class="histogram-bar" style="width: {{printf "%.0f" .Percent}}%;"></div>
+                <span>{{.Count}}</span>
+            </div>
+            {{end}}
+            <p style="color: #888; margin-top: 10px;">Suggested min_confidence: <strong>{{.SuggestedThreshold}}</strong> ({{.Total}} scored hosts)</p>
+        </div>
+        {{end}}
+        
+        {{with .Trends}}
+        <div class="leads">
+            <h2>Change Trend: {{.Trend}}</h2>
+            <p style="color: #888; margin-bottom: 10px;">+{{.AddedCount}} added, -{{.RemovedCount}} removed, by week</p>
+            {{range .Weekly}}
+            <div class="histogram-row">
+                <div class="histogram-label">{{.Start.Format "01-02"}}</div>
+                <div class="histogram-bar" style="width: {{barWidth .Added $.TrendMax}}%;"></div>
+                <span>+{{.Added}}</span>
+            </div>
+            <div class="histogram-row">
+                <div class="histogram-label"></div>
+                <div class="histogram-bar removed" style="width: {{barWidth .Removed $.TrendMax}}%;"></div>
+                <span>-{{.Removed}}</span>
+            </div>
+            {{end}}
+            {{if .Branches}}
+            <p style="color: #888; margin-top: 10px;">Fastest-growing branches:
+            {{range .Branches}}<div class="badge">{{.Branch}} +{{.Net}}</div>{{end}}</p>
+            {{end}}
+        </div>
+        {{end}}
+        
+        {{if .Leads}}
+        <div class="leads">
+            <h2>Related Organizations &amp; Domains (out of scope, not scanned)</h2>
+            <ul>
+            {{range .Leads.Organizations}}<li><div class="badge">org</div> {{.Value}} <span style="color: #888;">seen on {{len .SeenOn}} host(s)</span></li>{{end}}
+            {{range .Leads.Domains}}<li><div class="badge">domain</div> {{.Value}} <span style="color: #888;">seen on {{len .SeenOn}} host(s)</span></li>{{end}}
+            </ul>
+        </div>
+        {{end}}
+        
         <div class="filter">
             <input type="text" id="searchInput" placeholder="Filter subdomains..." onkeyup="filterTable()">
+            {{if .Tags}}
+            <select id="tagFilter" onchange="filterTable()">
+                <option value="">All tags</option>
+                {{range .Tags}}<option value="{{.}}">{{.}}</option>{{end}}
+            </select>
+            {{end}}
         </div>
         
         <table id="subdomainTable">
@@ -217,17 +509,21 @@ func (e *Exporter) ExportHTML(ctx context.Context, subdomains []*types.Subdomain
                     <th>Confidence</th>
                     <th>HTTP</th>
                     <th>Technologies</th>
+                    <th>CDN/WAF</th>
+                    <th>Tags</th>
                     <th>Sources</th>
                 </tr>
             </thead>
             <tbody>
             {{range .Subdomains}}
-                <tr>
-                    <td><strong>{{.Domain}}</strong></td>
-                    <td>{{range .IP}}<div class="badge">{{.}}</div>{{end}}</td>
-                    <td><span class="confidence {{if ge .Confidence 70}}confidence-high{{else if ge .Confidence 40}}confidence-medium{{else}}confidence-low{{end}}">{{.Confidence}}</span></td>
-                    <td>{{if .HTTP}}<span class="{{if and (ge .HTTP.StatusCode 200) (lt .HTTP.StatusCode 400)}}http-ok{{else}}http-error{{end}}">{{.HTTP.StatusCode}}</span>{{end}}</td>
-                    <td>{{if .HTTP}}{{range .HTTP.Technologies}}<div class="badge">{{.}}</div>{{end}}{{end}}</td>
+                <tr data-tags="{{join .Tags " "}}">
+                    <td><strong>{{.Domain}}</strong>{{range .Findings}}<div class="badge severity-{{.Severity}}" title="{{.URL}}{{with .Evidence}} ({{.}}){{end}}">{{.Title}}</div>{{end}}</td>
+                    <td>{{range .IP}}<div class="badge">{{.}}</div>{{end}}{{if index .Metadata "private_ip"}}<div class="badge http-error">internal</div>{{end}}</td>
+                    <td><span class="confidence {{if ge .Confidence 70}}confidence-high{{else if ge .Confidence 40}}confidence-medium{{else}}confidence-low{{end}}">{{.Confidence}}</span>{{with index .Metadata "status"}}<div class="badge http-error">{{.}}</div>{{end}}</td>
+                    <td>{{if .HTTP}}<span class="{{if and (ge .HTTP.StatusCode 200) (lt .HTTP.StatusCode 400)}}http-ok{{else}}http-error{{end}}">{{.HTTP.StatusCode}}</span>{{if .HTTP.Parked}} <div class="badge">parked</div>{{end}}{{with .HTTP.Screenshot}}{{$src := reportPath .}}<a href="{{$src}}" target="_blank"><img class="thumb" src="{{$src}}" loading="lazy" alt="screenshot"></a>{{end}}{{end}}</td>
+                    <td>{{if .HTTP}}{{if .HTTP.TechDetails}}{{range .HTTP.TechDetails}}<div class="badge" title="confidence {{.Confidence}}">{{.Name}}{{with .Version}} {{.}}{{end}}</div>{{end}}{{else}}{{range .HTTP.Technologies}}<div class="badge">{{.}}</div>{{end}}{{end}}{{end}}</td>
+                    <td>{{with index .Metadata "cdn"}}<div class="badge">{{.}}</div>{{end}}</td>
+                    <td>{{range .Tags}}<div class="badge">{{.}}</div>{{end}}</td>
                     <td>{{range .Sources}}<div class="badge">{{.}}</div>{{end}}</td>
                 </tr>
             {{end}}
@@ -239,6 +535,8 @@ func (e *Exporter) ExportHTML(ctx context.Context, subdomains []*types.Subdomain
         function filterTable() {
             const input = document.getElementById('searchInput');
             const filter = input.value.toUpperCase();
+            const tagSelect = document.getElementById('tagFilter');
+            const tag = tagSelect ? tagSelect.value : '';
             const table = document.getElementById('subdomainTable');
             const tr = table.getElementsByTagName('tr');
             
@@ -246,7 +544,9 @@ func (e *Exporter) ExportHTML(ctx context.Context, subdomains []*types.Subdomain
                 const td = tr[i].getElementsByTagName('td')[0];
                 if (td) {
                     const txtValue = td.textContent || td.innerText;
-                    tr[i].style.display = txtValue.toUpperCase().indexOf(filter) > -1 ? '' : 'none';
+                    const tags = (tr[i].dataset.tags || '').split(' ');
+                    const tagMatch = tag === '' || tags.indexOf(tag) > -1;
+                    tr[i].style.display = txtValue.toUpperCase().indexOf(filter) > -1 && tagMatch ? '' : 'none';
                 }
             }
         }
@@ -254,13 +554,19 @@ func (e *Exporter) ExportHTML(ctx context.Context, subdomains []*types.Subdomain
 </body>
 </html>`
 	
-	file, err := os.Create(outputPath)
+	file, err := createAtomic(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
 	defer file.Close()
 	
-	t, err := template.New("report").Parse(tmpl)
+	t, err := template.New("report").Funcs(template.FuncMap{
+		"join":     strings.Join,
+		"barWidth": barWidth,
+		"reportPath": func(path string) string {
+			return relativeTo(filepath.Dir(outputPath), path)
+		},
+	}).Parse(tmpl)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -282,19 +588,36 @@ func (e *Exporter) ExportHTML(ctx context.Context, subdomains []*types.Subdomain
 		"ValidatedCount":  validatedCount,
 		"HTTPActiveCount": httpActiveCount,
 		"Subdomains":      subdomains,
+		"Registration":    e.registration,
+		"Baseline":        e.baseline,
+		"Tags":            distinctTags(subdomains),
+	}
+	if e.leads.Count() > 0 {
+		data["Leads"] = e.leads
+	}
+	if e.stats != nil && e.stats.ConfidenceHistogram != nil {
+		data["Histogram"] = e.stats.ConfidenceHistogram
+	}
+	if e.trends != nil && len(e.trends.Weekly) > 0 {
+		data["Trends"] = e.trends
+		data["TrendMax"] = trendMax(e.trends.Weekly)
 	}
 	
 	if err := t.Execute(file, data); err != nil {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 	
+	if err := file.Commit(); err != nil {
+		return err
+	}
+	
 	e.logger.Info("HTML export complete", zap.String("path", outputPath))
 	return nil
 }
 
 // ExportNuclei exports in Nuclei-compatible format
 func (e *Exporter) ExportNuclei(ctx context.Context, subdomains []*types.Subdomain, outputPath string) error {
-	file, err := os.Create(outputPath)
+	file, err := createAtomic(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -310,6 +633,10 @@ func (e *Exporter) ExportNuclei(ctx context.Context, subdomains []*types.Subdoma
 		}
 	}
 	
+	if err := file.Commit(); err != nil {
+		return err
+	}
+	
 	e.logger.Info("Nuclei export complete", zap.String("path", outputPath))
 	return nil
 }
@@ -337,5 +664,68 @@ func (e *Exporter) ExportMultiple(ctx context.Context, subdomains []*types.Subdo
 		}
 	}
 	
+	if e.manifest != nil {
+		e.manifest.Counts.Exported = len(subdomains)
+		if err := e.ExportManifest(ctx, e.manifest, filepath.Join(outputDir, "manifest.json")); err != nil {
+			e.logger.Error("Failed to export manifest", zap.Error(err))
+		}
+	}
+	
 	return nil
-}
\ No newline at end of file
+}
+
+// trendMax returns the largest weekly count, which scales the trend bars
+func trendMax(buckets []diff.TrendBucket) int {
+	largest := 0
+	for _, bucket := range buckets {
+		largest = max(largest, bucket.Added, bucket.Removed)
+	}
+	return largest
+}
+
+// barWidth converts a count to a bar width percentage of the largest count
+func barWidth(count, largest int) int {
+	if largest == 0 {
+		return 0
+	}
+	return count * 100 / largest
+}
+
+// relativeTo makes a file path usable as a link from a report written to
+// dir, falling back to the absolute path when no relative one exists
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(path)
+}
+
+// distinctTags returns every tag used across subdomains, sorted
+func distinctTags(subdomains []*types.Subdomain) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, sub := range subdomains {
+		for _, tag := range sub.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// metadataString returns a metadata value as a string, or "" if absent
+func metadataString(sub *types.Subdomain, key string) string {
+	if sub.Metadata == nil {
+		return ""
+	}
+	if value, ok := sub.Metadata[key]; ok && value != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+func TestStatusFilter(t *testing.T) {
+	withStatus := func(name string, status int) *types.Subdomain {
+		return &types.Subdomain{Domain: name, HTTP: &types.HTTPInfo{StatusCode: status}}
+	}
+	subdomains := []*types.Subdomain{
+		withStatus("ok.example.com", 200),
+		withStatus("denied.example.com", 403),
+		withStatus("moved.example.com", 301),
+		withStatus("missing.example.com", 404),
+		withStatus("broken.example.com", 500),
+		{Domain: "unprobed.example.com"},
+	}
+
+	tests := []struct {
+		name  string
+		codes []int
+		want  string
+	}{
+		{name: "200 and 403", codes: []int{200, 403}, want: "denied.example.com ok.example.com"},
+		{name: "single code", codes: []int{500}, want: "broken.example.com"},
+		{name: "no match", codes: []int{418}, want: ""},
+		{
+			name: "no filter",
+			want: "broken.example.com denied.example.com missing.example.com moved.example.com ok.example.com unprobed.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "results.txt")
+			e := NewExporter(zap.NewNop())
+			e.SetStatusFilter(tt.codes)
+			if err := e.Export(context.Background(), subdomains, "txt", path); err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read export: %v", err)
+			}
+			names := strings.Fields(string(data))
+			sort.Strings(names)
+			if got := strings.Join(names, " "); got != tt.want {
+				t.Errorf("exported %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
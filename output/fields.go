@@ -0,0 +1,215 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// fieldResolver extracts a single value from a subdomain, or nil if absent
+type fieldResolver func(sub *types.Subdomain) interface{}
+
+// fieldResolvers maps selectable field paths to their values.
+// metadata.<key> is also accepted for arbitrary metadata entries.
+var fieldResolvers = map[string]fieldResolver{
+	"domain":     func(s *types.Subdomain) interface{} { return s.Domain },
+	"ip":         func(s *types.Subdomain) interface{} { return s.IP },
+	"sources":    func(s *types.Subdomain) interface{} { return s.Sources },
+	"confidence": func(s *types.Subdomain) interface{} { return s.Confidence },
+	"validated":  func(s *types.Subdomain) interface{} { return s.Validated },
+	"first_seen": func(s *types.Subdomain) interface{} { return s.FirstSeen },
+	"last_seen":  func(s *types.Subdomain) interface{} { return s.LastSeen },
+	"findings":   func(s *types.Subdomain) interface{} { return s.Findings },
+	"tags":       func(s *types.Subdomain) interface{} { return s.Tags },
+
+	"http.status_code":    httpField(func(h *types.HTTPInfo) interface{} { return h.StatusCode }),
+	"http.title":          httpField(func(h *types.HTTPInfo) interface{} { return h.Title }),
+	"http.server":         httpField(func(h *types.HTTPInfo) interface{} { return h.Server }),
+	"http.content_type":   httpField(func(h *types.HTTPInfo) interface{} { return h.ContentType }),
+	"http.response_time":  httpField(func(h *types.HTTPInfo) interface{} { return h.ResponseTime }),
+	"http.technologies":   httpField(func(h *types.HTTPInfo) interface{} { return h.Technologies }),
+	"http.tech_details":   httpField(func(h *types.HTTPInfo) interface{} { return h.TechDetails }),
+	"http.parked":         httpField(func(h *types.HTTPInfo) interface{} { return h.Parked }),
+	"http.final_url":      httpField(func(h *types.HTTPInfo) interface{} { return h.FinalURL }),
+	"http.redirect_chain": httpField(func(h *types.HTTPInfo) interface{} { return h.RedirectChain }),
+	"http.favicon_hash":   httpField(func(h *types.HTTPInfo) interface{} { return h.FaviconHash }),
+	"http.api":            httpField(func(h *types.HTTPInfo) interface{} { return h.API }),
+	"http.api_type":       httpField(func(h *types.HTTPInfo) interface{} { return h.APIType }),
+	"http.api_spec":       httpField(func(h *types.HTTPInfo) interface{} { return h.APISpec }),
+
+	"tls.valid":        tlsField(func(t *types.TLSInfo) interface{} { return t.Valid }),
+	"tls.subject":      tlsField(func(t *types.TLSInfo) interface{} { return t.Subject }),
+	"tls.issuer":       tlsField(func(t *types.TLSInfo) interface{} { return t.Issuer }),
+	"tls.not_before":   tlsField(func(t *types.TLSInfo) interface{} { return t.NotBefore }),
+	"tls.not_after":    tlsField(func(t *types.TLSInfo) interface{} { return t.NotAfter }),
+	"tls.sans":         tlsField(func(t *types.TLSInfo) interface{} { return t.SANs }),
+	"tls.organization": tlsField(func(t *types.TLSInfo) interface{} { return t.Organization }),
+
+	"dns_records.a":     dnsField(func(d *types.DNSRecords) interface{} { return d.A }),
+	"dns_records.aaaa":  dnsField(func(d *types.DNSRecords) interface{} { return d.AAAA }),
+	"dns_records.cname": dnsField(func(d *types.DNSRecords) interface{} { return d.CNAME }),
+	"dns_records.mx":    dnsField(func(d *types.DNSRecords) interface{} { return d.MX }),
+	"dns_records.ns":    dnsField(func(d *types.DNSRecords) interface{} { return d.NS }),
+	"dns_records.txt":   dnsField(func(d *types.DNSRecords) interface{} { return d.TXT }),
+}
+
+// httpField resolves a field of the HTTP info, if probed
+func httpField(get func(*types.HTTPInfo) interface{}) fieldResolver {
+	return func(s *types.Subdomain) interface{} {
+		if s.HTTP == nil {
+			return nil
+		}
+		return get(s.HTTP)
+	}
+}
+
+// tlsField resolves a field of the TLS info, if collected
+func tlsField(get func(*types.TLSInfo) interface{}) fieldResolver {
+	return func(s *types.Subdomain) interface{} {
+		if s.TLS == nil {
+			return nil
+		}
+		return get(s.TLS)
+	}
+}
+
+// dnsField resolves a record set, if resolved
+func dnsField(get func(*types.DNSRecords) interface{}) fieldResolver {
+	return func(s *types.Subdomain) interface{} {
+		if s.DNSRecords == nil {
+			return nil
+		}
+		return get(s.DNSRecords)
+	}
+}
+
+// fieldAliases are accepted shorthands for common fields
+var fieldAliases = map[string]string{
+	"status": "http.status_code",
+	"title":  "http.title",
+	"tech":   "http.technologies",
+	"cname":  "dns_records.cname",
+}
+
+// ParseFields validates a comma-separated field list such as
+// "domain,ip,http.status_code" and returns the canonical field paths
+func ParseFields(spec string) ([]string, error) {
+	var fields []string
+	seen := make(map[string]bool)
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if alias, ok := fieldAliases[field]; ok {
+			field = alias
+		}
+
+		_, known := fieldResolvers[field]
+		if !known && !(strings.HasPrefix(field, "metadata.") && len(field) > len("metadata.")) {
+			return nil, fmt.Errorf("unknown field %q (available: %s, metadata.<key>)", field, strings.Join(KnownFields(), ", "))
+		}
+
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields selected")
+	}
+
+	return fields, nil
+}
+
+// KnownFields lists the selectable field paths
+func KnownFields() []string {
+	fields := make([]string, 0, len(fieldResolvers))
+	for field := range fieldResolvers {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// SetFields restricts JSON, JSONL and CSV output to the given field paths
+func (e *Exporter) SetFields(fields []string) {
+	e.fields = fields
+}
+
+// resolveField returns the value at a field path
+func resolveField(sub *types.Subdomain, field string) interface{} {
+	if resolver, ok := fieldResolvers[field]; ok {
+		return resolver(sub)
+	}
+	if key := strings.TrimPrefix(field, "metadata."); key != field && sub.Metadata != nil {
+		return sub.Metadata[key]
+	}
+	return nil
+}
+
+// selectFields builds a nested map holding only the selected fields,
+// e.g. http.status_code becomes {"http": {"status_code": 200}}
+func selectFields(sub *types.Subdomain, fields []string) map[string]interface{} {
+	selected := make(map[string]interface{})
+
+	for _, field := range fields {
+		value := resolveField(sub, field)
+		if value == nil {
+			continue
+		}
+
+		node := selected
+		parts := strings.Split(field, ".")
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = value
+	}
+
+	return selected
+}
+
+// formatField renders a field value as a CSV cell
+func formatField(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, ";")
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// projectSubdomains returns subdomains as-is, or reduced to the selected
+// fields. An empty scan is an empty list rather than null.
+func (e *Exporter) projectSubdomains(subdomains []*types.Subdomain) interface{} {
+	if len(e.fields) == 0 {
+		if subdomains == nil {
+			return []*types.Subdomain{}
+		}
+		return subdomains
+	}
+
+	projected := make([]map[string]interface{}, 0, len(subdomains))
+	for _, sub := range subdomains {
+		projected = append(projected, selectFields(sub, e.fields))
+	}
+	return projected
+}
@@ -0,0 +1,177 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/core/orchestrator"
+	"github.com/yourusername/usr/internal/config"
+	"go.uber.org/zap"
+)
+
+// redactedKeys are config key suffixes whose values are never written
+var redactedKeys = []string{"key", "token", "secret", "password", "authorization", "cookie"}
+
+// Manifest summarizes how a scan was run so results are reproducible
+type Manifest struct {
+	Tool        string                    `json:"tool"`
+	Version     string                    `json:"version"`
+	Commit      string                    `json:"commit,omitempty"`
+	BuildDate   string                    `json:"build_date,omitempty"`
+	Domain      string                    `json:"domain"`
+	Mode        string                    `json:"mode"`
+	GeneratedAt time.Time                 `json:"generated_at"`
+	StartedAt   time.Time                 `json:"started_at"`
+	CompletedAt time.Time                 `json:"completed_at"`
+	Duration    string                    `json:"duration"`
+	Sources     []orchestrator.SourceStat `json:"sources"`
+	Counts      ManifestCounts            `json:"counts"`
+	Errors      []orchestrator.ScanError  `json:"errors,omitempty"`
+	Config      map[string]interface{}    `json:"config"`
+}
+
+// ManifestCounts holds the headline numbers of a scan
+type ManifestCounts struct {
+	Sources           int `json:"sources"`
+	CompletedSources  int `json:"completed_sources"`
+	Subdomains        int `json:"subdomains"`
+	Validated         int `json:"validated"`
+	FailedValidations int `json:"failed_validations"`
+	Exported          int `json:"exported"`
+}
+
+// NewManifest builds a manifest from scan statistics and the effective config
+func NewManifest(version, domain string, cfg *config.Config, stats orchestrator.Statistics, exported int) *Manifest {
+	manifest := &Manifest{
+		Tool:        "usr",
+		Version:     version,
+		Domain:      domain,
+		Mode:        cfg.ScanMode,
+		GeneratedAt: time.Now(),
+		StartedAt:   stats.StartTime,
+		CompletedAt: stats.EndTime,
+		Sources:     stats.Sources,
+		Errors:      stats.Errors,
+		Counts: ManifestCounts{
+			Sources:           stats.TotalSources,
+			CompletedSources:  stats.CompletedSources,
+			Subdomains:        stats.TotalSubdomains,
+			Validated:         stats.ValidatedSubdomains,
+			FailedValidations: stats.FailedValidations,
+			Exported:          exported,
+		},
+		Config: redactConfig(cfg),
+	}
+
+	if !stats.EndTime.IsZero() {
+		manifest.Duration = stats.EndTime.Sub(stats.StartTime).String()
+	}
+
+	return manifest
+}
+
+// SetManifest makes ExportMultiple write manifest.json alongside results
+func (e *Exporter) SetManifest(manifest *Manifest) {
+	e.manifest = manifest
+}
+
+// ExportManifest writes the scan manifest as JSON
+func (e *Exporter) ExportManifest(ctx context.Context, manifest *Manifest, outputPath string) error {
+	file, err := createAtomic(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(manifest); err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if err := file.Commit(); err != nil {
+		return err
+	}
+	
+	e.logger.Info("Manifest export complete", zap.String("path", outputPath))
+	return nil
+}
+
+// redactConfig converts the config to a generic map keyed like the config
+// file (dns.query_types, not DNS.QueryTypes), with secrets masked
+func redactConfig(cfg *config.Config) map[string]interface{} {
+	generic, ok := configValue(reflect.ValueOf(cfg)).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	redact(generic)
+	return generic
+}
+
+// configValue converts a config value to maps, slices and scalars, naming
+// struct fields by their mapstructure tags
+func configValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return configValue(v.Elem())
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			fields[name] = configValue(v.Field(i))
+		}
+		return fields
+	case reflect.Map:
+		entries := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries[fmt.Sprint(iter.Key().Interface())] = configValue(iter.Value())
+		}
+		return entries
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = configValue(v.Index(i))
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
+
+// redact masks non-empty values of sensitive keys in place
+func redact(node map[string]interface{}) {
+	for key, value := range node {
+		if child, ok := value.(map[string]interface{}); ok {
+			redact(child)
+			continue
+		}
+
+		lower := strings.ToLower(key)
+		for _, sensitive := range redactedKeys {
+			if strings.HasSuffix(lower, sensitive) && value != nil && value != "" {
+				node[key] = "REDACTED"
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/usr/core/orchestrator"
+	"github.com/yourusername/usr/internal/config"
+)
+
+// loadConfig loads the default configuration with yaml layered over it
+func loadConfig(t *testing.T, yaml string) *config.Config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("log_level: error\n"+yaml), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return cfg
+}
+
+// lookup follows a dotted config key through the manifest's config map
+func lookup(node map[string]interface{}, keys ...string) (interface{}, bool) {
+	var value interface{} = node
+	for _, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func TestManifestConfigUsesConfigKeys(t *testing.T) {
+	cfg := loadConfig(t, `
+sources:
+  passive:
+    shodan_api_key: "s3cr3t"
+http:
+  headers:
+    Authorization: "Bearer abc"
+    X-Team: "red"
+`)
+
+	manifest := NewManifest("1.0.0", "example.com", cfg, orchestrator.Statistics{}, 0)
+
+	// Round-trip through JSON as the manifest file does
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded struct {
+		Config map[string]interface{} `json:"config"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	tests := []struct {
+		key  []string
+		want interface{}
+	}{
+		{[]string{"log_level"}, "error"},
+		{[]string{"dns", "timeout"}, float64(cfg.DNS.Timeout)},
+		{[]string{"sources", "passive", "shodan_api_key"}, "REDACTED"},
+		{[]string{"http", "headers", "authorization"}, "REDACTED"},
+		{[]string{"http", "headers", "x-team"}, "red"},
+	}
+	for _, tt := range tests {
+		got, ok := lookup(decoded.Config, tt.key...)
+		if !ok || got != tt.want {
+			t.Errorf("config %v = %v (present %v), want %v", tt.key, got, ok, tt.want)
+		}
+	}
+
+	// Go field names must not leak into the manifest
+	for _, goName := range []string{"LogLevel", "DNS", "Sources"} {
+		if _, ok := decoded.Config[goName]; ok {
+			t.Errorf("config keyed by Go field name %q", goName)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package output
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/yourusername/usr/internal/types"
+)
+
+// schemaDialect is the JSON Schema draft the generated schemas declare
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// SchemaTypes lists the documents a schema can be generated for
+func SchemaTypes() []string {
+	return []string{"results", "subdomain", "manifest"}
+}
+
+// Schema returns the JSON Schema of an output document. The schema is
+// derived from the Go types and their json tags, so it always matches
+// what the exporters write.
+func Schema(kind string) (map[string]interface{}, error) {
+	g := &schemaGenerator{defs: make(map[string]interface{})}
+
+	var schema map[string]interface{}
+	switch kind {
+	case "results":
+		schema = g.structSchema(reflect.TypeOf(jsonResults{}))
+		schema["title"] = "USR JSON results"
+		// The envelope holds subdomains as interface{} so --fields can
+		// project them; the schema describes the full records
+		schema["properties"].(map[string]interface{})["subdomains"] = map[string]interface{}{
+			"type":  "array",
+			"items": g.typeSchema(reflect.TypeOf(types.Subdomain{})),
+		}
+	case "subdomain":
+		schema = g.typeSchema(reflect.TypeOf(types.Subdomain{}))
+	case "manifest":
+		schema = g.typeSchema(reflect.TypeOf(Manifest{}))
+	default:
+		return nil, fmt.Errorf("unknown schema %q (available: %s)", kind, strings.Join(SchemaTypes(), ", "))
+	}
+
+	schema["$schema"] = schemaDialect
+	schema["$defs"] = g.defs
+	return schema, nil
+}
+
+// schemaGenerator builds schemas by reflection, collecting each named
+// struct once under $defs
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+// typeSchema returns the schema of a Go type
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]interface{}{"type": "integer", "description": "duration in nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.typeSchema(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": g.typeSchema(t.Elem()),
+		}
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		// interface{} values may hold anything
+		return map[string]interface{}{}
+	}
+}
+
+// structRef registers a struct under $defs and returns a reference to it
+func (g *schemaGenerator) structRef(t reflect.Type) map[string]interface{} {
+	name := t.Name()
+	ref := map[string]interface{}{"$ref": "#/$defs/" + name}
+
+	if _, exists := g.defs[name]; exists {
+		return ref
+	}
+
+	// Placeholder first so self-referencing types terminate
+	g.defs[name] = map[string]interface{}{}
+	g.defs[name] = g.structSchema(t)
+
+	return ref
+}
+
+// structSchema returns the object schema of a struct. Fields without
+// omitempty are required; nil slices, maps and pointers encode as null,
+// so those also accept null.
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		schema := g.typeSchema(field.Type)
+		if !omitempty {
+			required = append(required, name)
+			switch field.Type.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Map:
+				schema = nullable(schema)
+			}
+		}
+		properties[name] = schema
+	}
+
+	def := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		def["required"] = required
+	}
+	return def
+}
+
+// nullable widens a schema to also accept null
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if kind, ok := schema["type"].(string); ok {
+		schema["type"] = []string{kind, "null"}
+		return schema
+	}
+	if _, ok := schema["$ref"]; ok {
+		return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+	}
+	return schema
+}
+
+// jsonName reads a field's json tag the way encoding/json does
+func jsonName(field reflect.StructField) (name string, omitempty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+
+	return name, omitempty, false
+}
@@ -0,0 +1,265 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/usr/intelligence/pivot"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// schemaValidator checks a decoded JSON document against the subset of JSON
+// Schema the generator emits. Objects with declared properties are closed:
+// a key the schema does not know is reported, so the schema cannot silently
+// fall behind what the exporters write.
+type schemaValidator struct {
+	defs   map[string]interface{}
+	errors []string
+}
+
+func (v *schemaValidator) validate(schema map[string]interface{}, value interface{}, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		if !ok {
+			v.fail(path, "unresolved $ref %s", ref)
+			return
+		}
+		v.validate(def, value, path)
+		return
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		for _, option := range anyOf {
+			branch := &schemaValidator{defs: v.defs}
+			branch.validate(option.(map[string]interface{}), value, path)
+			if len(branch.errors) == 0 {
+				return
+			}
+		}
+		v.fail(path, "matches no anyOf option")
+		return
+	}
+
+	if declared, ok := schema["type"]; ok && !v.typeMatches(declared, value) {
+		v.fail(path, "got %s, want type %v", jsonType(value), declared)
+		return
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, value, path)
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema map[string]interface{}, value map[string]interface{}, path string) {
+	required, _ := schema["required"].([]interface{})
+	for _, name := range required {
+		if _, ok := value[name.(string)]; !ok {
+			v.fail(path, "missing required %q", name)
+		}
+	}
+
+	properties, hasProperties := schema["properties"].(map[string]interface{})
+	additional, _ := schema["additionalProperties"].(map[string]interface{})
+
+	for key, child := range value {
+		switch {
+		case properties[key] != nil:
+			v.validate(properties[key].(map[string]interface{}), child, path+"."+key)
+		case additional != nil:
+			v.validate(additional, child, path+"."+key)
+		case hasProperties:
+			v.fail(path, "unexpected property %q", key)
+		}
+	}
+}
+
+func (v *schemaValidator) typeMatches(declared interface{}, value interface{}) bool {
+	kinds, ok := declared.([]interface{})
+	if !ok {
+		kinds = []interface{}{declared}
+	}
+
+	actual := jsonType(value)
+	for _, kind := range kinds {
+		if kind == actual || (kind == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *schemaValidator) fail(path, format string, args ...interface{}) {
+	v.errors = append(v.errors, path+": "+fmt.Sprintf(format, args...))
+}
+
+// jsonType names the JSON Schema type of a decoded value
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == float64(int64(value)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// decodedSchema returns a schema as it reads once written to disk
+func decodedSchema(t *testing.T, kind string) map[string]interface{} {
+	t.Helper()
+
+	schema, err := Schema(kind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestExportJSONMatchesSchema(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	full := &types.Subdomain{
+		Domain:     "app.example.com",
+		IP:         []string{"192.0.2.10"},
+		Sources:    []string{"crtsh", "bruteforce"},
+		Confidence: 90,
+		Validated:  true,
+		FirstSeen:  now,
+		LastSeen:   now,
+		HTTP: &types.HTTPInfo{
+			URL:          "https://app.example.com",
+			StatusCode:   200,
+			Title:        "App",
+			ResponseTime: 120 * time.Millisecond,
+			Headers:      map[string]string{"Server": "nginx"},
+			TechDetails:  []types.Technology{{Name: "nginx", Version: "1.25", Confidence: 100}},
+			BodySimhash:  0xfeedface,
+			FaviconHash:  -1165240594,
+		},
+		TLS: &types.TLSInfo{
+			Valid:     true,
+			Subject:   "app.example.com",
+			Issuer:    "Example CA",
+			NotBefore: now,
+			NotAfter:  now.AddDate(1, 0, 0),
+			SANs:      []string{"app.example.com"},
+		},
+		DNSRecords: &types.DNSRecords{A: []string{"192.0.2.10"}, CNAME: []string{"lb.example.net"}},
+		Findings: []types.Finding{
+			{Type: "exposed_path", Title: "Git HEAD", Severity: types.SeverityHigh, URL: "https://app.example.com/.git/HEAD", Time: now},
+		},
+		Tags:     []string{"environment:production"},
+		Metadata: map[string]interface{}{"asn": 64500, "note": "edge", "ports": []int{443}},
+	}
+	// Never resolved or probed: Sources stays nil and encodes as null
+	bare := &types.Subdomain{Domain: "old.example.com"}
+
+	tests := []struct {
+		name       string
+		subdomains []*types.Subdomain
+		setup      func(e *Exporter)
+	}{
+		{name: "empty scan"},
+		{name: "bare host", subdomains: []*types.Subdomain{bare}},
+		{
+			name:       "populated",
+			subdomains: []*types.Subdomain{full, bare},
+			setup: func(e *Exporter) {
+				e.SetRegistration(&types.Registration{Domain: "example.com", Registrar: "Example Registrar", Created: now, Source: "rdap"})
+				e.SetBaseline(&types.DNSRecords{NS: []string{"ns1.example.com"}, MX: []string{"mail.example.com"}})
+				e.SetLeads(&pivot.Leads{Domains: []*pivot.Lead{{Value: "example.net", SeenOn: []string{"app.example.com"}}}})
+			},
+		},
+	}
+
+	schema := decodedSchema(t, "results")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExporter(zap.NewNop())
+			if tt.setup != nil {
+				tt.setup(e)
+			}
+
+			path := filepath.Join(t.TempDir(), "results.json")
+			if err := e.ExportJSON(context.Background(), tt.subdomains, path); err != nil {
+				t.Fatalf("ExportJSON: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var document map[string]interface{}
+			if err := json.Unmarshal(data, &document); err != nil {
+				t.Fatal(err)
+			}
+
+			v := &schemaValidator{defs: schema["$defs"].(map[string]interface{})}
+			v.validate(schema, document, "$")
+			sort.Strings(v.errors)
+			for _, msg := range v.errors {
+				t.Error(msg)
+			}
+		})
+	}
+}
+
+func TestEmptyExportListsNoSubdomains(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := NewExporter(zap.NewNop()).ExportJSON(context.Background(), nil, path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"subdomains": []`) {
+		t.Errorf("empty export does not list an empty array:\n%s", data)
+	}
+}
+
+func TestSchemaAllowsNullForNilableRequiredFields(t *testing.T) {
+	schema := decodedSchema(t, "subdomain")
+	sub := schema["$defs"].(map[string]interface{})["Subdomain"].(map[string]interface{})
+	sources := sub["properties"].(map[string]interface{})["sources"].(map[string]interface{})
+
+	if got := fmt.Sprint(sources["type"]); got != "[array null]" {
+		t.Errorf("sources type = %s, want [array null]", got)
+	}
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yourusername/usr/core/orchestrator"
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// TemplateData is the data passed to user-provided output templates
+type TemplateData struct {
+	GeneratedAt  time.Time
+	Subdomains   []*types.Subdomain
+	Stats        *orchestrator.Statistics
+	Registration *types.Registration
+}
+
+// templateFuncs are the helpers available to user templates
+var templateFuncs = template.FuncMap{
+	"join":      strings.Join,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"trim":      strings.TrimSpace,
+	"replace":   strings.ReplaceAll,
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"split":     strings.Split,
+	"add":       func(a, b int) int { return a + b },
+	"now":       time.Now,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"metadata": metadataString,
+	"default": func(fallback, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// SetStatistics attaches scan statistics to template and HTML output
+func (e *Exporter) SetStatistics(stats orchestrator.Statistics) {
+	e.stats = &stats
+}
+
+// SetTemplate sets the user template used by the "template" format
+func (e *Exporter) SetTemplate(path string) {
+	e.templatePath = path
+}
+
+// ExportTemplate executes a user-provided Go template against the results
+func (e *Exporter) ExportTemplate(ctx context.Context, subdomains []*types.Subdomain, templatePath, outputPath string) error {
+	if templatePath == "" {
+		return fmt.Errorf("no template file specified")
+	}
+
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	t, err := template.New("custom").Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	file, err := createAtomic(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	data := &TemplateData{
+		GeneratedAt:  time.Now(),
+		Subdomains:   subdomains,
+		Stats:        e.stats,
+		Registration: e.registration,
+	}
+
+	if err := t.Execute(file, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	if err := file.Commit(); err != nil {
+		return err
+	}
+	
+	e.logger.Info("Template export complete",
+		zap.String("template", templatePath),
+		zap.String("path", outputPath),
+	)
+	return nil
+}
@@ -74,12 +74,14 @@ CREATE TABLE IF NOT EXISTS http_info (
 	content_type TEXT,
 	response_time INTEGER,
 	screenshot_path TEXT,
+	details TEXT,
 	checked_at TIMESTAMP NOT NULL,
 	FOREIGN KEY (subdomain_id) REFERENCES subdomains(id) ON DELETE CASCADE
 );
 
 CREATE INDEX IF NOT EXISTS idx_http_subdomain ON http_info(subdomain_id);
 CREATE INDEX IF NOT EXISTS idx_http_status ON http_info(status_code);
+CREATE INDEX IF NOT EXISTS idx_http_subdomain_checked ON http_info(subdomain_id, checked_at);
 
 CREATE TABLE IF NOT EXISTS tls_info (
 	id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -96,6 +98,16 @@ CREATE TABLE IF NOT EXISTS tls_info (
 
 CREATE INDEX IF NOT EXISTS idx_tls_subdomain ON tls_info(subdomain_id);
 
+CREATE TABLE IF NOT EXISTS tls_sans (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	subdomain_id INTEGER NOT NULL,
+	san TEXT NOT NULL,
+	FOREIGN KEY (subdomain_id) REFERENCES subdomains(id) ON DELETE CASCADE,
+	UNIQUE(subdomain_id, san)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tls_sans_subdomain ON tls_sans(subdomain_id);
+
 CREATE TABLE IF NOT EXISTS technologies (
 	id INTEGER PRIMARY KEY AUTOINCREMENT,
 	subdomain_id INTEGER NOT NULL,
@@ -119,6 +131,8 @@ CREATE TABLE IF NOT EXISTS cloud_assets (
 	asset_type TEXT NOT NULL,
 	url TEXT NOT NULL,
 	accessible BOOLEAN,
+	access TEXT,
+	bucket_exists BOOLEAN,
 	discovered_at TIMESTAMP NOT NULL,
 	FOREIGN KEY (scan_id) REFERENCES scans(id) ON DELETE CASCADE,
 	UNIQUE(scan_id, provider, bucket)
@@ -157,6 +171,38 @@ CREATE TABLE IF NOT EXISTS metadata (
 
 CREATE INDEX IF NOT EXISTS idx_metadata_subdomain ON metadata(subdomain_id);
 CREATE INDEX IF NOT EXISTS idx_metadata_key ON metadata(key);
+
+CREATE TABLE IF NOT EXISTS source_results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	scan_id INTEGER NOT NULL,
+	source TEXT NOT NULL,
+	subdomain TEXT NOT NULL,
+	FOREIGN KEY (scan_id) REFERENCES scans(id) ON DELETE CASCADE,
+	UNIQUE(scan_id, source, subdomain)
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_results_scan ON source_results(scan_id);
+
+CREATE TABLE IF NOT EXISTS scan_source_status (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	scan_id INTEGER NOT NULL,
+	source TEXT NOT NULL,
+	status TEXT NOT NULL,
+	found INTEGER DEFAULT 0,
+	completed_at TIMESTAMP,
+	FOREIGN KEY (scan_id) REFERENCES scans(id) ON DELETE CASCADE,
+	UNIQUE(scan_id, source)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scan_source_status_scan ON scan_source_status(scan_id);
+
+CREATE TABLE IF NOT EXISTS wildcard_info (
+	domain TEXT PRIMARY KEY,
+	is_wildcard BOOLEAN DEFAULT 0,
+	patterns TEXT,
+	fingerprint TEXT NOT NULL,
+	detected_at TIMESTAMP NOT NULL
+);
 `
 
 // InitDB initializes the database with schema
@@ -193,13 +239,62 @@ func InitDB(dbPath string) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to create schema: %w", err)
 	}
 	
+	if err := MigrateDB(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	
 	return db, nil
 }
 
 // MigrateDB applies any pending migrations
 func MigrateDB(db *sql.DB) error {
-	// Future migrations will be added here
-	// For now, just ensure schema is current
-	
+	migrations := []struct{ table, column, decl string }{
+		// http_info.details: the full HTTP result, for reusing cached probes
+		{"http_info", "details", "TEXT"},
+		// cloud_assets.access and bucket_exists: the probe's verdict
+		{"cloud_assets", "access", "TEXT"},
+		{"cloud_assets", "bucket_exists", "BOOLEAN"},
+	}
+
+	for _, m := range migrations {
+		if err := addColumn(db, m.table, m.column, m.decl); err != nil {
+			return err
+		}
+	}
 	return nil
+}
+
+// addColumn adds a column to a table created by an older schema, unless
+// it is already there
+func addColumn(db *sql.DB, table, column, decl string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl))
+	return err
 }
\ No newline at end of file
@@ -2,12 +2,22 @@ package diff
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/yourusername/usr/internal/types"
 	"github.com/yourusername/usr/storage"
 	"go.uber.org/zap"
 )
 
+// ErrNoPreviousScan is returned when a domain has no earlier completed scan
+var ErrNoPreviousScan = errors.New("no previous scan available")
+
 // Differ compares scan results to detect changes
 type Differ struct {
 	storage *storage.Manager
@@ -24,15 +34,32 @@ func NewDiffer(storage *storage.Manager, logger *zap.Logger) *Differ {
 
 // DiffResult contains the comparison results
 type DiffResult struct {
-	Domain        string
-	OldScanID     int64
-	NewScanID     int64
-	Added         []string
-	Removed       []string
-	Unchanged     []string
-	TotalOld      int
-	TotalNew      int
-	ChangePercent float64
+	Domain        string       `json:"domain"`
+	OldScanID     int64        `json:"old_scan_id"`
+	NewScanID     int64        `json:"new_scan_id"`
+	Added         []string     `json:"added"`
+	Removed       []string     `json:"removed"`
+	Unchanged     []string     `json:"unchanged"`
+	TotalOld      int          `json:"total_old"`
+	TotalNew      int          `json:"total_new"`
+	ChangePercent float64      `json:"change_percent"`
+	HTTPChanges   []HTTPChange `json:"http_changes"`
+}
+
+// HTTPChange is a difference in a subdomain's HTTP response between scans
+type HTTPChange struct {
+	Subdomain string `json:"subdomain"`
+	Field     string `json:"field"` // status_code, title, server
+	Old       string `json:"old"`
+	New       string `json:"new"`
+}
+
+// DiffCounts summarizes a diff for consumers that only need the numbers
+type DiffCounts struct {
+	Added       int `json:"added"`
+	Removed     int `json:"removed"`
+	Unchanged   int `json:"unchanged"`
+	HTTPChanges int `json:"http_changes"`
 }
 
 // Compare compares two scans and returns differences
@@ -94,6 +121,19 @@ func (d *Differ) Compare(ctx context.Context, domain string, oldScanID, newScanI
 		}
 	}
 	
+	// Hosts seen in both scans may still have changed what they serve
+	for _, sub := range result.Unchanged {
+		changes, err := d.compareHTTPScans(ctx, sub, oldScanID, newScanID)
+		if err != nil {
+			d.logger.Debug("HTTP comparison failed",
+				zap.String("subdomain", sub),
+				zap.Error(err),
+			)
+			continue
+		}
+		result.HTTPChanges = append(result.HTTPChanges, changes...)
+	}
+	
 	// Calculate change percentage
 	totalChanges := len(result.Added) + len(result.Removed)
 	totalSubdomains := len(oldSubdomains) + len(newSubdomains)
@@ -105,6 +145,7 @@ func (d *Differ) Compare(ctx context.Context, domain string, oldScanID, newScanI
 		zap.Int("added", len(result.Added)),
 		zap.Int("removed", len(result.Removed)),
 		zap.Int("unchanged", len(result.Unchanged)),
+		zap.Int("http_changes", len(result.HTTPChanges)),
 		zap.Float64("change_percent", result.ChangePercent),
 	)
 	
@@ -121,12 +162,101 @@ func (d *Differ) CompareLatest(ctx context.Context, domain string, currentScanID
 	
 	if previousScanID == 0 || previousScanID == currentScanID {
 		d.logger.Info("No previous scan found for comparison", zap.String("domain", domain))
-		return nil, fmt.Errorf("no previous scan available")
+		return nil, ErrNoPreviousScan
 	}
 	
 	return d.Compare(ctx, domain, previousScanID, currentScanID)
 }
 
+// CompareHTTP compares the two most recent HTTP results of a subdomain,
+// returning nothing if it was probed fewer than two times
+func (d *Differ) CompareHTTP(ctx context.Context, subdomain string) ([]HTTPChange, error) {
+	snapshots, err := d.storage.GetLatestHTTPInfo(ctx, subdomain, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) < 2 {
+		return nil, nil
+	}
+	
+	return httpChanges(subdomain, snapshots[1].HTTP, snapshots[0].HTTP), nil
+}
+
+// compareHTTPScans compares a subdomain's HTTP results from two given scans
+func (d *Differ) compareHTTPScans(ctx context.Context, subdomain string, oldScanID, newScanID int64) ([]HTTPChange, error) {
+	snapshots, err := d.storage.GetLatestHTTPInfo(ctx, subdomain, 0)
+	if err != nil {
+		return nil, err
+	}
+	
+	var oldHTTP, newHTTP *types.HTTPInfo
+	for _, snap := range snapshots {
+		switch snap.ScanID {
+		case oldScanID:
+			oldHTTP = snap.HTTP
+		case newScanID:
+			newHTTP = snap.HTTP
+		}
+	}
+	if oldHTTP == nil || newHTTP == nil {
+		return nil, nil
+	}
+	
+	return httpChanges(subdomain, oldHTTP, newHTTP), nil
+}
+
+// httpChanges lists the fields that differ between two HTTP results
+func httpChanges(subdomain string, oldHTTP, newHTTP *types.HTTPInfo) []HTTPChange {
+	var changes []HTTPChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, HTTPChange{
+				Subdomain: subdomain,
+				Field:     field,
+				Old:       oldValue,
+				New:       newValue,
+			})
+		}
+	}
+	
+	add("status_code", strconv.Itoa(oldHTTP.StatusCode), strconv.Itoa(newHTTP.StatusCode))
+	add("title", oldHTTP.Title, newHTTP.Title)
+	add("server", oldHTTP.Server, newHTTP.Server)
+	
+	return changes
+}
+
+// NewOnly compares the current scan with the previous one and keeps only
+// the subdomains it added. The current scan's subdomains must already be
+// saved, and the scan not yet completed, so it isn't taken as the previous
+// scan. Returns ErrNoPreviousScan when there is nothing to compare with.
+func (d *Differ) NewOnly(ctx context.Context, domain string, currentScanID int64, subdomains []*types.Subdomain) ([]*types.Subdomain, *DiffResult, error) {
+	result, err := d.CompareLatest(ctx, domain, currentScanID)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	added := make(map[string]bool, len(result.Added))
+	for _, name := range result.Added {
+		added[name] = true
+	}
+	
+	var fresh []*types.Subdomain
+	for _, sub := range subdomains {
+		if added[sub.Domain] {
+			fresh = append(fresh, sub)
+		}
+	}
+	
+	d.logger.Info("Filtered to new subdomains",
+		zap.String("domain", domain),
+		zap.Int("total", len(subdomains)),
+		zap.Int("new", len(fresh)),
+	)
+	
+	return fresh, result, nil
+}
+
 // SaveChanges persists detected changes to the database
 func (d *Differ) SaveChanges(ctx context.Context, result *DiffResult) error {
 	d.logger.Info("Saving changes to database",
@@ -158,6 +288,18 @@ func (d *Differ) SaveChanges(ctx context.Context, result *DiffResult) error {
 		}
 	}
 	
+	// Save HTTP changes as http_<field>
+	for _, change := range result.HTTPChanges {
+		err := d.storage.SaveChange(ctx, result.Domain, change.Subdomain, "http_"+change.Field, change.Old, change.New,
+			result.OldScanID, result.NewScanID)
+		if err != nil {
+			d.logger.Error("Failed to save change",
+				zap.String("subdomain", change.Subdomain),
+				zap.Error(err),
+			)
+		}
+	}
+	
 	d.logger.Info("Changes saved successfully")
 	
 	return nil
@@ -190,13 +332,56 @@ func (d *Differ) GenerateReport(result *DiffResult) string {
 		report += "\n"
 	}
 	
-	if len(result.Added) == 0 && len(result.Removed) == 0 {
+	if len(result.HTTPChanges) > 0 {
+		report += fmt.Sprintf("HTTP CHANGES (%d):\n", len(result.HTTPChanges))
+		report += repeatString("-", 50) + "\n"
+		for _, change := range result.HTTPChanges {
+			report += fmt.Sprintf("~ %s %s: %q -> %q\n", change.Subdomain, change.Field, change.Old, change.New)
+		}
+		report += "\n"
+	}
+	
+	if len(result.Added) == 0 && len(result.Removed) == 0 && len(result.HTTPChanges) == 0 {
 		report += "No changes detected.\n"
 	}
 	
 	return report
 }
 
+// GenerateJSON renders a diff for machines (monitoring pipelines,
+// notifications): the result with empty lists as [] rather than null,
+// plus a counts summary
+func (d *Differ) GenerateJSON(result *DiffResult) ([]byte, error) {
+	report := *result
+	report.Added = nonNil(report.Added)
+	report.Removed = nonNil(report.Removed)
+	report.Unchanged = nonNil(report.Unchanged)
+	if report.HTTPChanges == nil {
+		report.HTTPChanges = []HTTPChange{}
+	}
+	
+	return json.MarshalIndent(struct {
+		DiffResult
+		Counts DiffCounts `json:"counts"`
+	}{
+		DiffResult: report,
+		Counts: DiffCounts{
+			Added:       len(report.Added),
+			Removed:     len(report.Removed),
+			Unchanged:   len(report.Unchanged),
+			HTTPChanges: len(report.HTTPChanges),
+		},
+	}, "", "  ")
+}
+
+// nonNil returns an empty slice for nil, so JSON shows [] instead of null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 // DetectTrends analyzes historical changes to identify patterns
 func (d *Differ) DetectTrends(ctx context.Context, domain string, limit int) (*TrendAnalysis, error) {
 	changes, err := d.storage.GetRecentChanges(ctx, domain, limit)
@@ -224,6 +409,9 @@ func (d *Differ) DetectTrends(ctx context.Context, domain string, limit int) (*T
 	
 	analysis.AddedCount = addedCount
 	analysis.RemovedCount = removedCount
+	analysis.Daily = bucketChanges(changes, startOfDay)
+	analysis.Weekly = bucketChanges(changes, startOfWeek)
+	analysis.Branches = growingBranches(domain, changes)
 	
 	// Determine trend
 	if addedCount > removedCount*2 {
@@ -248,13 +436,130 @@ func (d *Differ) DetectTrends(ctx context.Context, domain string, limit int) (*T
 	return analysis, nil
 }
 
+// maxTrendBranches caps how many growing branches a trend analysis lists
+const maxTrendBranches = 5
+
 // TrendAnalysis contains trend information
 type TrendAnalysis struct {
-	Domain       string
-	TotalChanges int
-	AddedCount   int
-	RemovedCount int
-	Trend        string // rapid_growth, growth, stable, decline, rapid_decline
+	Domain       string         `json:"domain"`
+	TotalChanges int            `json:"total_changes"`
+	AddedCount   int            `json:"added"`
+	RemovedCount int            `json:"removed"`
+	Trend        string         `json:"trend"` // rapid_growth, growth, stable, decline, rapid_decline
+	Daily        []TrendBucket  `json:"daily"`
+	Weekly       []TrendBucket  `json:"weekly"`
+	Branches     []BranchGrowth `json:"branches"` // fastest-growing first
+}
+
+// TrendBucket counts the changes detected in one day or week (UTC)
+type TrendBucket struct {
+	Start   time.Time `json:"start"`
+	Added   int       `json:"added"`
+	Removed int       `json:"removed"`
+}
+
+// BranchGrowth is the net growth of the subdomains under one parent,
+// e.g. dev.example.com for api.dev.example.com
+type BranchGrowth struct {
+	Branch  string `json:"branch"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+	Net     int    `json:"net"`
+}
+
+// bucketChanges counts added/removed changes per period, oldest first
+func bucketChanges(changes []*storage.Change, periodStart func(time.Time) time.Time) []TrendBucket {
+	buckets := make(map[time.Time]*TrendBucket)
+	for _, change := range changes {
+		if change.ChangeType != "added" && change.ChangeType != "removed" {
+			continue
+		}
+		
+		start := periodStart(change.DetectedAt)
+		bucket, ok := buckets[start]
+		if !ok {
+			bucket = &TrendBucket{Start: start}
+			buckets[start] = bucket
+		}
+		if change.ChangeType == "added" {
+			bucket.Added++
+		} else {
+			bucket.Removed++
+		}
+	}
+	
+	result := make([]TrendBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		result = append(result, *bucket)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// startOfDay truncates a time to midnight UTC
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// startOfWeek truncates a time to Monday midnight UTC
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// growingBranches groups added/removed subdomains by parent and returns
+// the branches that grew the most, net of removals
+func growingBranches(domain string, changes []*storage.Change) []BranchGrowth {
+	branches := make(map[string]*BranchGrowth)
+	for _, change := range changes {
+		if change.ChangeType != "added" && change.ChangeType != "removed" {
+			continue
+		}
+		
+		branch := parentOf(change.Subdomain, domain)
+		growth, ok := branches[branch]
+		if !ok {
+			growth = &BranchGrowth{Branch: branch}
+			branches[branch] = growth
+		}
+		if change.ChangeType == "added" {
+			growth.Added++
+			growth.Net++
+		} else {
+			growth.Removed++
+			growth.Net--
+		}
+	}
+	
+	var growing []BranchGrowth
+	for _, growth := range branches {
+		if growth.Net > 0 {
+			growing = append(growing, *growth)
+		}
+	}
+	sort.Slice(growing, func(i, j int) bool {
+		if growing[i].Net != growing[j].Net {
+			return growing[i].Net > growing[j].Net
+		}
+		return growing[i].Branch < growing[j].Branch
+	})
+	
+	if len(growing) > maxTrendBranches {
+		growing = growing[:maxTrendBranches]
+	}
+	return growing
+}
+
+// parentOf strips the first label of a subdomain, stopping at the domain
+func parentOf(subdomain, domain string) string {
+	_, parent, ok := strings.Cut(subdomain, ".")
+	if !ok || len(parent) < len(domain) {
+		return domain
+	}
+	return parent
 }
 
 func repeatString(s string, count int) string {
@@ -0,0 +1,127 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/yourusername/usr/internal/types"
+	"github.com/yourusername/usr/storage"
+	"go.uber.org/zap"
+)
+
+// newTestDiffer returns a differ over a fresh database
+func newTestDiffer(t *testing.T) (*Differ, *storage.Manager) {
+	t.Helper()
+
+	m, err := storage.NewManager(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return NewDiffer(m, zap.NewNop()), m
+}
+
+// saveScan stores subs as a completed scan of example.com
+func saveScan(t *testing.T, m *storage.Manager, subs ...*types.Subdomain) int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	scanID, err := m.CreateScan(ctx, "example.com", "passive", []string{"crtsh"})
+	if err != nil {
+		t.Fatalf("CreateScan: %v", err)
+	}
+	for _, sub := range subs {
+		if err := m.SaveSubdomain(ctx, scanID, sub); err != nil {
+			t.Fatalf("SaveSubdomain(%s): %v", sub.Domain, err)
+		}
+	}
+	if err := m.CompleteScan(ctx, scanID, len(subs), len(subs)); err != nil {
+		t.Fatalf("CompleteScan: %v", err)
+	}
+	return scanID
+}
+
+// probed is a validated host with an HTTP result
+func probed(domain string, status int, title string) *types.Subdomain {
+	return &types.Subdomain{
+		Domain:    domain,
+		Validated: true,
+		HTTP: &types.HTTPInfo{
+			URL:        "https://" + domain,
+			StatusCode: status,
+			Title:      title,
+			Server:     "nginx",
+		},
+	}
+}
+
+// changeSet renders changes as sorted "subdomain field old->new" lines
+func changeSet(changes []HTTPChange) []string {
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, fmt.Sprintf("%s %s %s->%s", c.Subdomain, c.Field, c.Old, c.New))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func TestCompareDetectsHTTPChanges(t *testing.T) {
+	d, m := newTestDiffer(t)
+	ctx := context.Background()
+
+	oldScan := saveScan(t, m,
+		probed("www.example.com", 200, "Home"),
+		probed("api.example.com", 401, ""),
+	)
+	newScan := saveScan(t, m,
+		probed("www.example.com", 503, "Maintenance"),
+		probed("api.example.com", 401, ""),
+		probed("new.example.com", 200, "New"),
+	)
+
+	result, err := d.Compare(ctx, "example.com", oldScan, newScan)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+
+	if len(result.Added) != 1 || result.Added[0] != "new.example.com" {
+		t.Errorf("added = %v, want [new.example.com]", result.Added)
+	}
+	want := []string{
+		"www.example.com status_code 200->503",
+		"www.example.com title Home->Maintenance",
+	}
+	if got := changeSet(result.HTTPChanges); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("HTTP changes = %v, want %v", got, want)
+	}
+}
+
+func TestCompareHTTPUsesLatestTwoProbes(t *testing.T) {
+	d, m := newTestDiffer(t)
+	ctx := context.Background()
+
+	saveScan(t, m, probed("www.example.com", 200, "Home"))
+	saveScan(t, m, probed("www.example.com", 301, "Home"), probed("new.example.com", 200, "New"))
+
+	changes, err := d.CompareHTTP(ctx, "www.example.com")
+	if err != nil {
+		t.Fatalf("CompareHTTP: %v", err)
+	}
+	if got := changeSet(changes); len(got) != 1 || got[0] != "www.example.com status_code 200->301" {
+		t.Errorf("changes = %v, want the status change only", got)
+	}
+
+	// A host probed once has nothing to compare
+	if changes, err := d.CompareHTTP(ctx, "new.example.com"); err != nil || changes != nil {
+		t.Errorf("single probe compared: %v, %v", changes, err)
+	}
+
+	// Neither does an unchanged host
+	saveScan(t, m, probed("www.example.com", 301, "Home"))
+	if changes, err := d.CompareHTTP(ctx, "www.example.com"); err != nil || len(changes) != 0 {
+		t.Errorf("unchanged probes reported %v, %v", changeSet(changes), err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/yourusername/usr/internal/types"
@@ -101,47 +102,54 @@ func (m *Manager) SaveSubdomain(ctx context.Context, scanID int64, sub *types.Su
 	
 	// Insert DNS records
 	if sub.DNSRecords != nil {
-		for _, ip := range sub.DNSRecords.A {
-			_, err := tx.ExecContext(ctx,
-				`INSERT INTO dns_records (subdomain_id, record_type, value, discovered_at)
-				 VALUES (?, 'A', ?, ?)`,
-				subdomainID, ip, time.Now(),
-			)
-			if err != nil {
-				return err
-			}
+		records := []struct {
+			recordType string
+			values     []string
+		}{
+			{"A", sub.DNSRecords.A},
+			{"AAAA", sub.DNSRecords.AAAA},
+			{"CNAME", sub.DNSRecords.CNAME},
+			{"MX", sub.DNSRecords.MX},
+			{"NS", sub.DNSRecords.NS},
+			{"TXT", sub.DNSRecords.TXT},
 		}
-		
-		for _, cname := range sub.DNSRecords.CNAME {
-			_, err := tx.ExecContext(ctx,
-				`INSERT INTO dns_records (subdomain_id, record_type, value, discovered_at)
-				 VALUES (?, 'CNAME', ?, ?)`,
-				subdomainID, cname, time.Now(),
-			)
-			if err != nil {
-				return err
+		for _, record := range records {
+			for _, value := range record.values {
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO dns_records (subdomain_id, record_type, value, discovered_at)
+					 VALUES (?, ?, ?, ?)`,
+					subdomainID, record.recordType, value, time.Now(),
+				)
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
 	
-	// Insert HTTP info
+	// Insert HTTP info. The columns are for querying; details holds the
+	// whole result so a cached probe can stand in for a fresh one.
 	if sub.HTTP != nil {
-		_, err := tx.ExecContext(ctx,
-			`INSERT INTO http_info (subdomain_id, status_code, title, server, content_type, response_time, checked_at)
-			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		details, err := json.Marshal(sub.HTTP)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO http_info (subdomain_id, status_code, title, server, content_type, response_time, screenshot_path, details, checked_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			subdomainID, sub.HTTP.StatusCode, sub.HTTP.Title, sub.HTTP.Server,
-			sub.HTTP.ContentType, sub.HTTP.ResponseTime.Milliseconds(), time.Now(),
+			sub.HTTP.ContentType, sub.HTTP.ResponseTime.Milliseconds(), sub.HTTP.Screenshot, string(details), time.Now(),
 		)
 		if err != nil {
 			return err
 		}
 		
-		// Insert technologies
-		for _, tech := range sub.HTTP.Technologies {
+		// Insert technologies, with version and confidence where detected
+		for _, tech := range technologyDetails(sub.HTTP) {
 			_, err := tx.ExecContext(ctx,
-				`INSERT OR IGNORE INTO technologies (subdomain_id, technology, detected_at)
-				 VALUES (?, ?, ?)`,
-				subdomainID, tech, time.Now(),
+				`INSERT OR IGNORE INTO technologies (subdomain_id, technology, version, confidence, detected_at)
+				 VALUES (?, ?, ?, ?, ?)`,
+				subdomainID, tech.Name, tech.Version, tech.Confidence, time.Now(),
 			)
 			if err != nil {
 				return err
@@ -160,6 +168,16 @@ func (m *Manager) SaveSubdomain(ctx context.Context, scanID int64, sub *types.Su
 		if err != nil {
 			return err
 		}
+		
+		for _, san := range sub.TLS.SANs {
+			_, err := tx.ExecContext(ctx,
+				`INSERT OR IGNORE INTO tls_sans (subdomain_id, san) VALUES (?, ?)`,
+				subdomainID, san,
+			)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	
 	// Insert metadata
@@ -194,6 +212,232 @@ func (m *Manager) GetLatestScan(ctx context.Context, domain string) (int64, erro
 	return scanID, err
 }
 
+// GetRunningScan returns the most recent scan of a domain that never
+// completed, e.g. because it was interrupted, or 0 if there is none
+func (m *Manager) GetRunningScan(ctx context.Context, domain string) (int64, error) {
+	var scanID int64
+	err := m.db.QueryRowContext(ctx,
+		`SELECT id FROM scans WHERE domain = ? AND status = 'running'
+		 ORDER BY started_at DESC LIMIT 1`,
+		domain,
+	).Scan(&scanID)
+	
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	
+	return scanID, err
+}
+
+// CompleteSource records that a source finished during a scan, together
+// with the names it reported, in one transaction so a resumed scan never
+// skips a source whose results were lost
+func (m *Manager) CompleteSource(ctx context.Context, scanID int64, source string, subdomains []string) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT OR IGNORE INTO source_results (scan_id, source, subdomain) VALUES (?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	
+	for _, subdomain := range subdomains {
+		if _, err := stmt.ExecContext(ctx, scanID, source, subdomain); err != nil {
+			return fmt.Errorf("failed to save source result: %w", err)
+		}
+	}
+	
+	_, err = tx.ExecContext(ctx,
+		`INSERT OR REPLACE INTO scan_source_status (scan_id, source, status, found, completed_at)
+		 VALUES (?, ?, 'completed', ?, ?)`,
+		scanID, source, len(subdomains), time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save source status: %w", err)
+	}
+	
+	return tx.Commit()
+}
+
+// GetCompletedSources returns the sources that finished during a scan
+func (m *Manager) GetCompletedSources(ctx context.Context, scanID int64) ([]string, error) {
+	return m.queryStrings(ctx,
+		`SELECT source FROM scan_source_status WHERE scan_id = ? AND status = 'completed' ORDER BY source`,
+		scanID,
+	)
+}
+
+// ClearSubdomains deletes the subdomains stored for a scan along with their
+// checks, so a resumed scan can store its full results again. Child rows
+// are deleted explicitly: the foreign_keys pragma only holds on the pooled
+// connection that set it.
+func (m *Manager) ClearSubdomains(ctx context.Context, scanID int64) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	
+	for _, table := range []string{"subdomain_sources", "dns_records", "http_info", "tls_info", "tls_sans", "technologies", "metadata"} {
+		_, err := tx.ExecContext(ctx,
+			`DELETE FROM `+table+` WHERE subdomain_id IN (SELECT id FROM subdomains WHERE scan_id = ?)`,
+			scanID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+	
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subdomains WHERE scan_id = ?`, scanID); err != nil {
+		return fmt.Errorf("failed to clear subdomains: %w", err)
+	}
+	
+	return tx.Commit()
+}
+
+// GetCompletedScans returns the IDs of a domain's completed scans, newest
+// first, at most limit of them (0 = all)
+func (m *Manager) GetCompletedScans(ctx context.Context, domain string, limit int) ([]int64, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT id FROM scans WHERE domain = ? AND status = 'completed'
+		 ORDER BY completed_at DESC LIMIT ?`,
+		domain, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scans: %w", err)
+	}
+	defer rows.Close()
+	
+	var scanIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		scanIDs = append(scanIDs, id)
+	}
+	
+	return scanIDs, rows.Err()
+}
+
+// GetScanDomain returns the target domain of a scan, or "" if there is
+// no such scan
+func (m *Manager) GetScanDomain(ctx context.Context, scanID int64) (string, error) {
+	var domain string
+	err := m.db.QueryRowContext(ctx,
+		`SELECT domain FROM scans WHERE id = ?`,
+		scanID,
+	).Scan(&domain)
+	
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	
+	return domain, err
+}
+
+// SaveSourceResults stores the raw names each source reported during a
+// scan, before validation or filtering, so the scan can be replayed
+func (m *Manager) SaveSourceResults(ctx context.Context, scanID int64, results map[string][]string) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT OR IGNORE INTO source_results (scan_id, source, subdomain) VALUES (?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	
+	for source, subdomains := range results {
+		for _, subdomain := range subdomains {
+			if _, err := stmt.ExecContext(ctx, scanID, source, subdomain); err != nil {
+				return fmt.Errorf("failed to save source result: %w", err)
+			}
+		}
+	}
+	
+	return tx.Commit()
+}
+
+// GetSourceResults returns the raw names each source reported during a
+// scan, keyed by source
+func (m *Manager) GetSourceResults(ctx context.Context, scanID int64) (map[string][]string, error) {
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT source, subdomain FROM source_results WHERE scan_id = ? ORDER BY id`,
+		scanID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source results: %w", err)
+	}
+	defer rows.Close()
+	
+	results := make(map[string][]string)
+	for rows.Next() {
+		var source, subdomain string
+		if err := rows.Scan(&source, &subdomain); err != nil {
+			return nil, err
+		}
+		results[source] = append(results[source], subdomain)
+	}
+	
+	return results, rows.Err()
+}
+
+// CountScansContaining returns how many completed scans found a subdomain
+func (m *Manager) CountScansContaining(ctx context.Context, subdomain string) (int, error) {
+	var count int
+	err := m.db.QueryRowContext(ctx,
+		`SELECT COUNT(DISTINCT s.scan_id)
+		 FROM subdomains s
+		 JOIN scans sc ON sc.id = s.scan_id
+		 WHERE s.domain = ? AND sc.status = 'completed'`,
+		subdomain,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count scans: %w", err)
+	}
+	
+	return count, nil
+}
+
+// LastSeenStored returns when a completed scan last saw a subdomain, or
+// the zero time if none did
+func (m *Manager) LastSeenStored(ctx context.Context, subdomain string) (time.Time, error) {
+	var lastSeen time.Time
+	err := m.db.QueryRowContext(ctx,
+		`SELECT s.last_seen
+		 FROM subdomains s
+		 JOIN scans sc ON sc.id = s.scan_id
+		 WHERE s.domain = ? AND sc.status = 'completed'
+		 ORDER BY s.last_seen DESC
+		 LIMIT 1`,
+		subdomain,
+	).Scan(&lastSeen)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query last sighting: %w", err)
+	}
+	
+	return lastSeen, nil
+}
+
 // GetScanSubdomains retrieves all subdomains from a scan
 func (m *Manager) GetScanSubdomains(ctx context.Context, scanID int64) ([]string, error) {
 	rows, err := m.db.QueryContext(ctx,
@@ -217,6 +461,167 @@ func (m *Manager) GetScanSubdomains(ctx context.Context, scanID int64) ([]string
 	return subdomains, rows.Err()
 }
 
+// GetScanResults rebuilds the subdomains stored for a scan, with their
+// sources, DNS records, latest HTTP/TLS results and metadata
+func (m *Manager) GetScanResults(ctx context.Context, scanID int64) ([]*types.Subdomain, error) {
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT id, domain, first_seen, last_seen, confidence, validated
+		 FROM subdomains WHERE scan_id = ? AND status = 'active'`,
+		scanID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subdomains: %w", err)
+	}
+	
+	var (
+		ids        []int64
+		subdomains []*types.Subdomain
+	)
+	for rows.Next() {
+		var id int64
+		sub := &types.Subdomain{}
+		if err := rows.Scan(&id, &sub.Domain, &sub.FirstSeen, &sub.LastSeen, &sub.Confidence, &sub.Validated); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+		subdomains = append(subdomains, sub)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	
+	for i, sub := range subdomains {
+		if err := m.loadSubdomainDetails(ctx, ids[i], sub); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", sub.Domain, err)
+		}
+	}
+	
+	return subdomains, nil
+}
+
+// loadSubdomainDetails fills in the child rows of a stored subdomain
+func (m *Manager) loadSubdomainDetails(ctx context.Context, id int64, sub *types.Subdomain) error {
+	sources, err := m.queryStrings(ctx, `SELECT source FROM subdomain_sources WHERE subdomain_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	sub.Sources = sources
+	
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT record_type, value FROM dns_records WHERE subdomain_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var recordType, value string
+		if err := rows.Scan(&recordType, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		if sub.DNSRecords == nil {
+			sub.DNSRecords = &types.DNSRecords{}
+		}
+		switch recordType {
+		case "A":
+			sub.DNSRecords.A = append(sub.DNSRecords.A, value)
+			sub.IP = append(sub.IP, value)
+		case "AAAA":
+			sub.DNSRecords.AAAA = append(sub.DNSRecords.AAAA, value)
+			sub.IP = append(sub.IP, value)
+		case "CNAME":
+			sub.DNSRecords.CNAME = append(sub.DNSRecords.CNAME, value)
+		case "MX":
+			sub.DNSRecords.MX = append(sub.DNSRecords.MX, value)
+		case "NS":
+			sub.DNSRecords.NS = append(sub.DNSRecords.NS, value)
+		case "TXT":
+			sub.DNSRecords.TXT = append(sub.DNSRecords.TXT, value)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	
+	if sub.HTTP, err = m.loadHTTPInfo(ctx, id); err != nil {
+		return err
+	}
+	if sub.TLS, err = m.loadTLSInfo(ctx, id); err != nil {
+		return err
+	}
+	
+	rows, err = m.db.QueryContext(ctx,
+		`SELECT key, value FROM metadata WHERE subdomain_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			key string
+			raw sql.NullString
+		)
+		if err := rows.Scan(&key, &raw); err != nil {
+			return err
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw.String), &value); err != nil {
+			value = raw.String
+		}
+		if sub.Metadata == nil {
+			sub.Metadata = make(map[string]interface{})
+		}
+		sub.Metadata[key] = value
+	}
+	
+	return rows.Err()
+}
+
+// queryStrings runs a query returning a single string column
+func (m *Manager) queryStrings(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	
+	return values, rows.Err()
+}
+
+// GetScanConfidences retrieves the confidence score of every subdomain in a scan
+func (m *Manager) GetScanConfidences(ctx context.Context, scanID int64) ([]int, error) {
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT confidence FROM subdomains WHERE scan_id = ? AND status = 'active'`,
+		scanID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query confidences: %w", err)
+	}
+	defer rows.Close()
+	
+	var scores []int
+	for rows.Next() {
+		var score int
+		if err := rows.Scan(&score); err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+	
+	return scores, rows.Err()
+}
+
 // GetSubdomainHistory retrieves historical data for a subdomain
 func (m *Manager) GetSubdomainHistory(ctx context.Context, domain string) ([]*SubdomainSnapshot, error) {
 	rows, err := m.db.QueryContext(ctx,
@@ -246,6 +651,381 @@ func (m *Manager) GetSubdomainHistory(ctx context.Context, domain string) ([]*Su
 	return history, rows.Err()
 }
 
+// GetRecentProbe returns the latest HTTP result for a subdomain if it was
+// checked within the given window, with the certificate stored alongside
+// it, or nil if there is none. Only results stored with their details are
+// returned: older ones lack the URL, body hash and headers later phases
+// need.
+func (m *Manager) GetRecentProbe(ctx context.Context, domain string, within time.Duration) (*types.HTTPInfo, *types.TLSInfo, error) {
+	var subdomainID int64
+	err := m.db.QueryRowContext(ctx,
+		`SELECT h.subdomain_id
+		 FROM http_info h
+		 JOIN subdomains s ON h.subdomain_id = s.id
+		 WHERE s.domain = ? AND h.checked_at >= ? AND h.details IS NOT NULL
+		 ORDER BY h.checked_at DESC
+		 LIMIT 1`,
+		domain, time.Now().Add(-within),
+	).Scan(&subdomainID)
+	
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query http info: %w", err)
+	}
+	
+	httpInfo, err := m.loadHTTPInfo(ctx, subdomainID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load http info: %w", err)
+	}
+	tlsInfo, err := m.loadTLSInfo(ctx, subdomainID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load tls info: %w", err)
+	}
+	
+	return httpInfo, tlsInfo, nil
+}
+
+// loadHTTPInfo returns the latest HTTP result stored for a subdomain row,
+// or nil if there is none. Results stored before details were kept are
+// rebuilt from the columns.
+func (m *Manager) loadHTTPInfo(ctx context.Context, subdomainID int64) (*types.HTTPInfo, error) {
+	var (
+		statusCode   sql.NullInt64
+		title        sql.NullString
+		server       sql.NullString
+		contentType  sql.NullString
+		responseTime sql.NullInt64
+		screenshot   sql.NullString
+		details      sql.NullString
+	)
+	err := m.db.QueryRowContext(ctx,
+		`SELECT status_code, title, server, content_type, response_time, screenshot_path, details
+		 FROM http_info WHERE subdomain_id = ? ORDER BY checked_at DESC LIMIT 1`, subdomainID,
+	).Scan(&statusCode, &title, &server, &contentType, &responseTime, &screenshot, &details)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	if details.Valid {
+		info := &types.HTTPInfo{}
+		if err := json.Unmarshal([]byte(details.String), info); err != nil {
+			return nil, fmt.Errorf("failed to decode http details: %w", err)
+		}
+		return info, nil
+	}
+	
+	info := &types.HTTPInfo{
+		StatusCode:   int(statusCode.Int64),
+		Title:        title.String,
+		Server:       server.String,
+		ContentType:  contentType.String,
+		ResponseTime: time.Duration(responseTime.Int64) * time.Millisecond,
+		Screenshot:   screenshot.String,
+	}
+	if err := m.loadTechnologies(ctx, subdomainID, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// loadTLSInfo returns the latest certificate stored for a subdomain row,
+// or nil if there is none
+func (m *Manager) loadTLSInfo(ctx context.Context, subdomainID int64) (*types.TLSInfo, error) {
+	var (
+		subject      sql.NullString
+		issuer       sql.NullString
+		notBefore    sql.NullTime
+		notAfter     sql.NullTime
+		valid        sql.NullBool
+		organization sql.NullString
+	)
+	err := m.db.QueryRowContext(ctx,
+		`SELECT subject, issuer, not_before, not_after, valid, organization
+		 FROM tls_info WHERE subdomain_id = ? ORDER BY checked_at DESC LIMIT 1`, subdomainID,
+	).Scan(&subject, &issuer, &notBefore, &notAfter, &valid, &organization)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	info := &types.TLSInfo{
+		Valid:        valid.Bool,
+		Subject:      subject.String,
+		Issuer:       issuer.String,
+		NotBefore:    notBefore.Time,
+		NotAfter:     notAfter.Time,
+		Organization: organization.String,
+	}
+	info.SANs, err = m.queryStrings(ctx, `SELECT san FROM tls_sans WHERE subdomain_id = ? ORDER BY id`, subdomainID)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// technologyDetails returns the technologies to store for an HTTP result;
+// results without details (e.g. loaded from older scans) only have names
+func technologyDetails(info *types.HTTPInfo) []types.Technology {
+	if len(info.TechDetails) > 0 {
+		return info.TechDetails
+	}
+	details := make([]types.Technology, len(info.Technologies))
+	for i, name := range info.Technologies {
+		details[i] = types.Technology{Name: name}
+	}
+	return details
+}
+
+// loadTechnologies fills an HTTP result's technologies from storage
+func (m *Manager) loadTechnologies(ctx context.Context, subdomainID int64, info *types.HTTPInfo) error {
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT technology, version, confidence FROM technologies WHERE subdomain_id = ? ORDER BY technology`,
+		subdomainID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var (
+			tech       types.Technology
+			version    sql.NullString
+			confidence sql.NullInt64
+		)
+		if err := rows.Scan(&tech.Name, &version, &confidence); err != nil {
+			return err
+		}
+		tech.Version = version.String
+		tech.Confidence = int(confidence.Int64)
+		
+		info.Technologies = append(info.Technologies, tech.Name)
+		info.TechDetails = append(info.TechDetails, tech)
+	}
+	return rows.Err()
+}
+
+// CloudAsset is a storage bucket recorded for a scan. Access and Exists
+// are the probe's verdict; Access is empty for an asset that wasn't probed.
+type CloudAsset struct {
+	Provider   string
+	Bucket     string
+	Region     string
+	URL        string
+	Type       string // s3, gcs, azure-blob, firebase, do-spaces, r2, b2
+	Access     string // public_list, public, denied, not_found, unknown
+	Exists     bool
+	Accessible bool
+}
+
+// SaveCloudAsset stores a storage bucket found during a scan. A bucket
+// already stored for the scan is kept as is. The probe's columns stay
+// NULL for an asset that wasn't probed.
+func (m *Manager) SaveCloudAsset(ctx context.Context, scanID int64, asset CloudAsset) error {
+	var (
+		access     sql.NullString
+		exists     sql.NullBool
+		accessible sql.NullBool
+	)
+	if asset.Access != "" {
+		access = sql.NullString{String: asset.Access, Valid: true}
+		exists = sql.NullBool{Bool: asset.Exists, Valid: true}
+		accessible = sql.NullBool{Bool: asset.Accessible, Valid: true}
+	}
+	
+	_, err := m.db.ExecContext(ctx,
+		`INSERT OR IGNORE INTO cloud_assets (scan_id, provider, bucket, region, asset_type, url, accessible, access, bucket_exists, discovered_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		scanID, asset.Provider, asset.Bucket, asset.Region, asset.Type, asset.URL, accessible, access, exists, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save cloud asset: %w", err)
+	}
+	
+	return nil
+}
+
+// GetCloudAssets returns the storage buckets stored for a scan
+func (m *Manager) GetCloudAssets(ctx context.Context, scanID int64) ([]CloudAsset, error) {
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT provider, bucket, region, asset_type, url, accessible, access, bucket_exists
+		 FROM cloud_assets WHERE scan_id = ? ORDER BY id`,
+		scanID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cloud assets: %w", err)
+	}
+	defer rows.Close()
+	
+	var assets []CloudAsset
+	for rows.Next() {
+		var (
+			asset      CloudAsset
+			region     sql.NullString
+			accessible sql.NullBool
+			access     sql.NullString
+			exists     sql.NullBool
+		)
+		if err := rows.Scan(&asset.Provider, &asset.Bucket, &region, &asset.Type, &asset.URL, &accessible, &access, &exists); err != nil {
+			return nil, err
+		}
+		asset.Region = region.String
+		asset.Accessible = accessible.Bool
+		asset.Access = access.String
+		asset.Exists = exists.Bool
+		assets = append(assets, asset)
+	}
+	
+	return assets, rows.Err()
+}
+
+// SaveWildcardInfo stores the wildcard detection result for a domain,
+// replacing any earlier one. The fingerprint identifies the conditions it
+// was detected under (resolvers, apex records) so a change invalidates it.
+// A and AAAA patterns share one column and are told apart on load.
+func (m *Manager) SaveWildcardInfo(ctx context.Context, domain, fingerprint string, info *types.WildcardInfo) error {
+	patterns := append(append([]string{}, info.Patterns...), info.PatternsV6...)
+	patternsJSON, err := json.Marshal(patterns)
+	if err != nil {
+		return fmt.Errorf("failed to encode wildcard patterns: %w", err)
+	}
+	
+	detectedAt := info.DetectedAt
+	if detectedAt.IsZero() {
+		detectedAt = time.Now()
+	}
+	
+	_, err = m.db.ExecContext(ctx,
+		`INSERT INTO wildcard_info (domain, is_wildcard, patterns, fingerprint, detected_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(domain) DO UPDATE SET
+		   is_wildcard = excluded.is_wildcard,
+		   patterns = excluded.patterns,
+		   fingerprint = excluded.fingerprint,
+		   detected_at = excluded.detected_at`,
+		domain, info.IsWildcard, string(patternsJSON), fingerprint, detectedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save wildcard info: %w", err)
+	}
+	
+	return nil
+}
+
+// GetWildcardInfo returns the stored wildcard detection result for a
+// domain if it was detected within the given window under the same
+// fingerprint, or nil if there is none
+func (m *Manager) GetWildcardInfo(ctx context.Context, domain, fingerprint string, within time.Duration) (*types.WildcardInfo, error) {
+	var (
+		isWildcard   bool
+		patternsJSON sql.NullString
+		detectedAt   time.Time
+	)
+	
+	err := m.db.QueryRowContext(ctx,
+		`SELECT is_wildcard, patterns, detected_at
+		 FROM wildcard_info
+		 WHERE domain = ? AND fingerprint = ? AND detected_at >= ?`,
+		domain, fingerprint, time.Now().Add(-within),
+	).Scan(&isWildcard, &patternsJSON, &detectedAt)
+	
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wildcard info: %w", err)
+	}
+	
+	info := &types.WildcardInfo{
+		IsWildcard:  isWildcard,
+		TestResults: make(map[string][]string),
+		DetectedAt:  detectedAt,
+	}
+	if patternsJSON.Valid && patternsJSON.String != "" {
+		var patterns []string
+		if err := json.Unmarshal([]byte(patternsJSON.String), &patterns); err != nil {
+			return nil, fmt.Errorf("failed to decode wildcard patterns: %w", err)
+		}
+		for _, ip := range patterns {
+			if strings.Contains(ip, ":") {
+				info.PatternsV6 = append(info.PatternsV6, ip)
+			} else {
+				info.Patterns = append(info.Patterns, ip)
+			}
+		}
+	}
+	
+	return info, nil
+}
+
+// HTTPSnapshot is the HTTP result a scan recorded for a subdomain
+type HTTPSnapshot struct {
+	ScanID    int64
+	CheckedAt time.Time
+	HTTP      *types.HTTPInfo
+}
+
+// GetLatestHTTPInfo returns the latest HTTP result of each scan that
+// probed a subdomain, newest scan first. limit caps how many scans are
+// returned (0 = all); a limit of 2 gives the pair to diff.
+func (m *Manager) GetLatestHTTPInfo(ctx context.Context, domain string, limit int) ([]*HTTPSnapshot, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	
+	// Every scan stores its own subdomains row, so the newest http_info
+	// row per subdomain_id is the latest result of that scan
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT s.scan_id, h.status_code, h.title, h.server, h.content_type, h.response_time, h.checked_at
+		 FROM http_info h
+		 JOIN subdomains s ON h.subdomain_id = s.id
+		 WHERE s.domain = ?
+		   AND h.id = (SELECT h2.id FROM http_info h2
+		               WHERE h2.subdomain_id = h.subdomain_id
+		               ORDER BY h2.checked_at DESC, h2.id DESC
+		               LIMIT 1)
+		 ORDER BY h.checked_at DESC, h.id DESC
+		 LIMIT ?`,
+		domain, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query http history: %w", err)
+	}
+	defer rows.Close()
+	
+	var snapshots []*HTTPSnapshot
+	for rows.Next() {
+		var (
+			snap         HTTPSnapshot
+			statusCode   sql.NullInt64
+			title        sql.NullString
+			server       sql.NullString
+			contentType  sql.NullString
+			responseTime sql.NullInt64
+		)
+		if err := rows.Scan(&snap.ScanID, &statusCode, &title, &server, &contentType, &responseTime, &snap.CheckedAt); err != nil {
+			return nil, err
+		}
+		snap.HTTP = &types.HTTPInfo{
+			StatusCode:   int(statusCode.Int64),
+			Title:        title.String,
+			Server:       server.String,
+			ContentType:  contentType.String,
+			ResponseTime: time.Duration(responseTime.Int64) * time.Millisecond,
+		}
+		snapshots = append(snapshots, &snap)
+	}
+	
+	return snapshots, rows.Err()
+}
+
 // SubdomainSnapshot represents a point-in-time subdomain state
 type SubdomainSnapshot struct {
 	ID         int64
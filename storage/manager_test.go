@@ -0,0 +1,399 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/yourusername/usr/internal/types"
+	"go.uber.org/zap"
+)
+
+// newTestManager opens a fresh database in the test's temp dir
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+
+	m, err := NewManager(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+// storeScan saves subs as a scan of domain, completed when complete is set
+func storeScan(t *testing.T, m *Manager, domain string, complete bool, subs ...*types.Subdomain) int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	scanID, err := m.CreateScan(ctx, domain, "passive", []string{"crtsh"})
+	if err != nil {
+		t.Fatalf("CreateScan: %v", err)
+	}
+	for _, sub := range subs {
+		if err := m.SaveSubdomain(ctx, scanID, sub); err != nil {
+			t.Fatalf("SaveSubdomain(%s): %v", sub.Domain, err)
+		}
+	}
+	if complete {
+		if err := m.CompleteScan(ctx, scanID, len(subs), len(subs)); err != nil {
+			t.Fatalf("CompleteScan: %v", err)
+		}
+	}
+	return scanID
+}
+
+func TestLastSeenStored(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	old := time.Now().Add(-60 * 24 * time.Hour).UTC().Truncate(time.Second)
+	recent := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	storeScan(t, m, "example.com", true, &types.Subdomain{Domain: "api.example.com", FirstSeen: old, LastSeen: old})
+	storeScan(t, m, "example.com", true, &types.Subdomain{Domain: "api.example.com", FirstSeen: old, LastSeen: recent})
+
+	// Scans still running don't count as sightings
+	storeScan(t, m, "example.com", false, &types.Subdomain{Domain: "api.example.com", FirstSeen: old, LastSeen: time.Now()})
+
+	lastSeen, err := m.LastSeenStored(ctx, "api.example.com")
+	if err != nil {
+		t.Fatalf("LastSeenStored: %v", err)
+	}
+	if !lastSeen.Equal(recent) {
+		t.Errorf("last seen = %v, want %v", lastSeen, recent)
+	}
+
+	count, err := m.CountScansContaining(ctx, "api.example.com")
+	if err != nil {
+		t.Fatalf("CountScansContaining: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("scans containing = %d, want 2", count)
+	}
+
+	lastSeen, err = m.LastSeenStored(ctx, "unknown.example.com")
+	if err != nil {
+		t.Fatalf("LastSeenStored(unknown): %v", err)
+	}
+	if !lastSeen.IsZero() {
+		t.Errorf("last seen of an unstored host = %v, want zero", lastSeen)
+	}
+}
+
+func TestGetRecentProbeRestoresFullResult(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	stored := &types.HTTPInfo{
+		URL:           "https://app.example.com",
+		StatusCode:    200,
+		Title:         "App",
+		Headers:       map[string]string{"Content-Security-Policy": "default-src cdn.example.com"},
+		HeaderHosts:   []string{"cdn.example.com"},
+		Technologies:  []string{"nginx"},
+		TechDetails:   []types.Technology{{Name: "nginx", Version: "1.25", Confidence: 100}},
+		Parked:        true,
+		API:           true,
+		APIType:       "rest",
+		FinalURL:      "https://app.example.com/login",
+		RedirectChain: []string{"https://app.example.com", "https://app.example.com/login"},
+		BodyHash:      "abc123",
+		BodySimhash:   42,
+		BodyLength:    512,
+		FaviconHash:   -1165240594,
+	}
+	cert := &types.TLSInfo{Subject: "app.example.com", Issuer: "Test CA", SANs: []string{"app.example.com", "api.example.com"}}
+	storeScan(t, m, "example.com", true, &types.Subdomain{
+		Domain: "app.example.com", FirstSeen: time.Now(), LastSeen: time.Now(), HTTP: stored, TLS: cert,
+	})
+
+	info, tlsInfo, err := m.GetRecentProbe(ctx, "app.example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("GetRecentProbe: %v", err)
+	}
+	if info == nil {
+		t.Fatal("no cached result")
+	}
+	if !reflect.DeepEqual(info, stored) {
+		t.Errorf("restored %+v, want %+v", info, stored)
+	}
+	if tlsInfo == nil || tlsInfo.Issuer != "Test CA" || !reflect.DeepEqual(tlsInfo.SANs, cert.SANs) {
+		t.Errorf("restored certificate %+v, want %+v", tlsInfo, cert)
+	}
+
+	// Outside the window there is nothing to reuse
+	if info, _, _ := m.GetRecentProbe(ctx, "app.example.com", -time.Minute); info != nil {
+		t.Errorf("result outside the window = %+v, want nil", info)
+	}
+}
+
+func TestGetRecentProbeSkipsResultsWithoutDetails(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	scanID := storeScan(t, m, "example.com", true, &types.Subdomain{
+		Domain: "old.example.com", FirstSeen: time.Now(), LastSeen: time.Now(), HTTP: &types.HTTPInfo{StatusCode: 200},
+	})
+
+	// A row stored before details were kept
+	if _, err := m.db.ExecContext(ctx,
+		`UPDATE http_info SET details = NULL WHERE subdomain_id IN (SELECT id FROM subdomains WHERE scan_id = ?)`, scanID,
+	); err != nil {
+		t.Fatalf("clear details: %v", err)
+	}
+
+	info, _, err := m.GetRecentProbe(ctx, "old.example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("GetRecentProbe: %v", err)
+	}
+	if info != nil {
+		t.Errorf("reused a result without details: %+v", info)
+	}
+
+	// It still loads with the scan, from the columns
+	subs, err := m.GetScanResults(ctx, scanID)
+	if err != nil {
+		t.Fatalf("GetScanResults: %v", err)
+	}
+	if len(subs) != 1 || subs[0].HTTP == nil || subs[0].HTTP.StatusCode != 200 {
+		t.Errorf("stored scan lost its HTTP result: %+v", subs)
+	}
+}
+
+func TestMigrateAddsHTTPDetails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := old.Exec(`CREATE TABLE http_info (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		subdomain_id INTEGER NOT NULL,
+		status_code INTEGER,
+		title TEXT,
+		server TEXT,
+		content_type TEXT,
+		response_time INTEGER,
+		screenshot_path TEXT,
+		checked_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		t.Fatalf("create old table: %v", err)
+	}
+	old.Close()
+
+	m, err := NewManager(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewManager on an old database: %v", err)
+	}
+	defer m.Close()
+
+	if _, err := m.db.Exec(`SELECT details FROM http_info`); err != nil {
+		t.Errorf("details column missing after migration: %v", err)
+	}
+}
+
+func TestSaveSubdomainStoresCertificate(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	notBefore := time.Now().Add(-24 * time.Hour).UTC().Truncate(time.Second)
+	notAfter := notBefore.Add(90 * 24 * time.Hour)
+	cert := &types.TLSInfo{
+		Valid:        true,
+		Subject:      "www.example.com",
+		Issuer:       "Test CA",
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		SANs:         []string{"www.example.com", "example.com", "*.cdn.example.com"},
+		Organization: "Example Inc",
+	}
+	scanID := storeScan(t, m, "example.com", true, &types.Subdomain{
+		Domain: "www.example.com", FirstSeen: time.Now(), LastSeen: time.Now(), TLS: cert,
+	})
+
+	results, err := m.GetScanResults(ctx, scanID)
+	if err != nil {
+		t.Fatalf("GetScanResults: %v", err)
+	}
+	if len(results) != 1 || results[0].TLS == nil {
+		t.Fatalf("got %+v, want one host with a certificate", results)
+	}
+
+	got := results[0].TLS
+	if !got.NotBefore.Equal(notBefore) || !got.NotAfter.Equal(notAfter) {
+		t.Errorf("validity %v - %v, want %v - %v", got.NotBefore, got.NotAfter, notBefore, notAfter)
+	}
+	got.NotBefore, got.NotAfter = cert.NotBefore, cert.NotAfter
+	if !reflect.DeepEqual(got, cert) {
+		t.Errorf("restored %+v, want %+v", got, cert)
+	}
+}
+
+func TestSaveSubdomainStoresRecords(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	records := &types.DNSRecords{
+		A:     []string{"192.0.2.1"},
+		CNAME: []string{"edge.example.net"},
+		MX:    []string{"mx1.example.com", "mx2.example.com"},
+		NS:    []string{"ns1.example.net"},
+		TXT:   []string{"v=spf1 include:_spf.example.net ~all", "google-site-verification=abc"},
+	}
+	scanID := storeScan(t, m, "example.com", true, &types.Subdomain{
+		Domain: "example.com", FirstSeen: time.Now(), LastSeen: time.Now(), DNSRecords: records,
+	})
+
+	results, err := m.GetScanResults(ctx, scanID)
+	if err != nil {
+		t.Fatalf("GetScanResults: %v", err)
+	}
+	if len(results) != 1 || results[0].DNSRecords == nil {
+		t.Fatalf("got %+v, want one host with records", results)
+	}
+
+	got := results[0].DNSRecords
+	for _, field := range []struct {
+		name      string
+		got, want []string
+	}{
+		{"A", got.A, records.A},
+		{"CNAME", got.CNAME, records.CNAME},
+		{"MX", got.MX, records.MX},
+		{"NS", got.NS, records.NS},
+		{"TXT", got.TXT, records.TXT},
+	} {
+		g, w := append([]string(nil), field.got...), append([]string(nil), field.want...)
+		sort.Strings(g)
+		sort.Strings(w)
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("%s = %v, want %v", field.name, field.got, field.want)
+		}
+	}
+	if !reflect.DeepEqual(results[0].IP, records.A) {
+		t.Errorf("IP = %v, want the A records", results[0].IP)
+	}
+}
+
+func TestCloudAssetsRoundTrip(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	scanID := storeScan(t, m, "example.com", false)
+	otherScan := storeScan(t, m, "example.org", false)
+
+	assets := []CloudAsset{
+		{Provider: "AWS", Type: "s3", Bucket: "example-assets", Region: "eu-west-1", URL: "https://example-assets.s3.eu-west-1.amazonaws.com", Access: "public_list", Exists: true, Accessible: true},
+		{Provider: "Google Cloud", Type: "gcs", Bucket: "example-logs", URL: "https://storage.googleapis.com/example-logs", Access: "denied", Exists: true},
+		{Provider: "AWS", Type: "s3", Bucket: "example-old", URL: "https://example-old.s3.amazonaws.com", Access: "not_found"},
+		{Provider: "Azure", Type: "azure-blob", Bucket: "acme/backups", URL: "https://acme.blob.core.windows.net/backups"},
+	}
+	for _, asset := range assets {
+		if err := m.SaveCloudAsset(ctx, scanID, asset); err != nil {
+			t.Fatalf("SaveCloudAsset(%s): %v", asset.Bucket, err)
+		}
+	}
+
+	// The same bucket seen again keeps the first row; other scans have
+	// their own
+	again := assets[0]
+	again.URL = "https://s3.amazonaws.com/example-assets"
+	again.Accessible = false
+	if err := m.SaveCloudAsset(ctx, scanID, again); err != nil {
+		t.Fatalf("SaveCloudAsset(duplicate): %v", err)
+	}
+	if err := m.SaveCloudAsset(ctx, otherScan, assets[1]); err != nil {
+		t.Fatalf("SaveCloudAsset(other scan): %v", err)
+	}
+
+	got, err := m.GetCloudAssets(ctx, scanID)
+	if err != nil {
+		t.Fatalf("GetCloudAssets: %v", err)
+	}
+	if !reflect.DeepEqual(got, assets) {
+		t.Errorf("got %+v\nwant %+v", got, assets)
+	}
+
+	// Only probed assets record the probe's verdict
+	rows, err := m.db.QueryContext(ctx, `SELECT bucket, accessible, access, bucket_exists FROM cloud_assets WHERE scan_id = ?`, scanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	probed := make(map[string]bool)
+	for rows.Next() {
+		var (
+			bucket     string
+			accessible sql.NullBool
+			access     sql.NullString
+			exists     sql.NullBool
+		)
+		if err := rows.Scan(&bucket, &accessible, &access, &exists); err != nil {
+			t.Fatal(err)
+		}
+		if accessible.Valid != access.Valid || exists.Valid != access.Valid {
+			t.Errorf("%s: probe columns partly NULL", bucket)
+		}
+		probed[bucket] = access.Valid
+	}
+	wantProbed := map[string]bool{"example-assets": true, "example-logs": true, "example-old": true, "acme/backups": false}
+	if !reflect.DeepEqual(probed, wantProbed) {
+		t.Errorf("probed = %v, want %v", probed, wantProbed)
+	}
+
+	other, err := m.GetCloudAssets(ctx, otherScan)
+	if err != nil || len(other) != 1 || other[0].Bucket != "example-logs" {
+		t.Errorf("other scan assets = %+v, %v", other, err)
+	}
+	if none, err := m.GetCloudAssets(ctx, otherScan+1); err != nil || len(none) != 0 {
+		t.Errorf("unknown scan assets = %+v, %v", none, err)
+	}
+}
+
+func TestCloudAssetsFromOlderDatabase(t *testing.T) {
+	// A database created before the probe's verdict was stored
+	path := filepath.Join(t.TempDir(), "old.db")
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = old.Exec(`CREATE TABLE cloud_assets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		scan_id INTEGER NOT NULL,
+		provider TEXT NOT NULL,
+		bucket TEXT NOT NULL,
+		region TEXT,
+		asset_type TEXT NOT NULL,
+		url TEXT NOT NULL,
+		accessible BOOLEAN,
+		discovered_at TIMESTAMP NOT NULL,
+		UNIQUE(scan_id, provider, bucket)
+	);
+	INSERT INTO cloud_assets (scan_id, provider, bucket, asset_type, url, accessible, discovered_at)
+	VALUES (1, 'AWS', 'legacy', 's3', 'https://legacy.s3.amazonaws.com', 1, CURRENT_TIMESTAMP)`)
+	old.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewManager(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewManager on an older database: %v", err)
+	}
+	defer m.Close()
+
+	got, err := m.GetCloudAssets(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CloudAsset{{Provider: "AWS", Bucket: "legacy", Type: "s3", URL: "https://legacy.s3.amazonaws.com", Accessible: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}